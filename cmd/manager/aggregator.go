@@ -20,11 +20,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"html"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,6 +37,7 @@ import (
 	ocpcfgv1 "github.com/openshift/api/config/v1"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -61,10 +64,9 @@ import (
 )
 
 const (
-	configMapRemediationsProcessed = "compliance-remediations/processed"
-	configMapCompressed            = "openscap-scan-result/compressed"
-	apiserverOperatorName          = "openshift-apiserver"
-	tailoredProfileSuffix          = "-tp"
+	configMapCompressed   = "openscap-scan-result/compressed"
+	apiserverOperatorName = "openshift-apiserver"
+	tailoredProfileSuffix = "-tp"
 )
 
 var AggregatorCmd = &cobra.Command{
@@ -176,7 +178,7 @@ func readCompressedData(compressed string) (*bzip2.Reader, error) {
 func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme, scanName, namespace string, content *xmlquery.Node, cm *v1.ConfigMap) ([]*utils.ParseResult, string, error) {
 	var scanReader io.Reader
 
-	_, ok := cm.Annotations[configMapRemediationsProcessed]
+	_, ok := cm.Annotations[compv1alpha1.CmRemediationsProcessedAnnotation]
 	if ok {
 		cmdLog.Info("ConfigMap already processed", "ConfigMap.Name", cm.Name)
 		return nil, "", nil
@@ -187,6 +189,13 @@ func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme
 		return nil, "", fmt.Errorf("no results in configmap %s", cm.Name)
 	}
 
+	// This would return an empty string for a platform check that is handled later explicitly
+	nodeName := cm.Annotations["openscap-scan-result/node"]
+	if cm.Data["exit-code"] == common.OpenSCAPExitCodeUnchanged {
+		cmdLog.Info("No rules were evaluated, the previous results are kept", "ConfigMap.Name", cm.Name)
+		return []*utils.ParseResult{}, nodeName, nil
+	}
+
 	_, ok = cm.Annotations[configMapCompressed]
 	if ok {
 		cmdLog.Info("Results are compressed\n")
@@ -200,8 +209,6 @@ func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme
 		scanReader = strings.NewReader(cmScanResult)
 	}
 
-	// This would return an empty string for a platform check that is handled later explicitly
-	nodeName := cm.Annotations["openscap-scan-result/node"]
 	manualRules := []string{}
 
 	//get all manual rules from tailored profile
@@ -214,7 +221,7 @@ func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme
 	}
 	// scan has tailored profile CM
 	if scan.Spec.TailoringConfigMap != nil {
-		tailoredProfileName := strings.TrimSuffix(scan.Spec.TailoringConfigMap.Name, tailoredProfileSuffix)
+		tailoredProfileName := getTailoredProfileName(client, namespace, scan.Spec.TailoringConfigMap.Name)
 		tp := &compv1alpha1.TailoredProfile{}
 		err = client.Get(context.TODO(), types.NamespacedName{Name: tailoredProfileName, Namespace: namespace}, tp)
 		if err != nil {
@@ -224,9 +231,52 @@ func parseResultRemediations(client runtimeclient.Client, scheme *runtime.Scheme
 	}
 
 	table, err := utils.ParseResultsFromContentAndXccdf(scheme, scanName, namespace, content, scanReader, manualRules)
+	if evidence, ok := cm.Data[utils.FailureEvidenceKey]; ok {
+		addFailureEvidence(table, evidence, nodeName)
+	}
 	return table, nodeName, nil
 }
 
+// addFailureEvidence sets the evidence the result collector uploaded on the
+// failing checks. The evidence of node checks notes the node it comes from,
+// as a check that fails on several nodes only keeps the evidence of one of
+// them.
+func addFailureEvidence(results []*utils.ParseResult, encoded, nodeName string) {
+	evidence := make(map[string]string)
+	if err := json.Unmarshal([]byte(encoded), &evidence); err != nil {
+		cmdLog.Error(err, "Cannot decode the evidence of the failing checks")
+		return
+	}
+	for _, pr := range results {
+		if pr == nil || pr.CheckResult == nil || pr.CheckResult.Status != compv1alpha1.CheckResultFail {
+			continue
+		}
+		ruleEvidence, ok := evidence[pr.Id]
+		if !ok {
+			continue
+		}
+		if nodeName != "" {
+			ruleEvidence = fmt.Sprintf("Collected on node %s:\n%s", nodeName, ruleEvidence)
+		}
+		pr.CheckResult.Evidence = ruleEvidence
+	}
+}
+
+// getTailoredProfileName gets the name of the TailoredProfile a tailoring
+// ConfigMap was generated from. The ConfigMaps of the rules of other
+// ProfileBundles are named after the ProfileBundle too, so the label is
+// preferred over the name.
+func getTailoredProfileName(client runtimeclient.Client, namespace, cmName string) string {
+	tailoringCM := &v1.ConfigMap{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: cmName, Namespace: namespace}, tailoringCM)
+	if err == nil {
+		if name, ok := tailoringCM.Labels[compv1alpha1.TailoredProfileOutputLabel]; ok {
+			return name
+		}
+	}
+	return strings.TrimSuffix(cmName, tailoredProfileSuffix)
+}
+
 func getScanResult(cm *v1.ConfigMap) (compv1alpha1.ComplianceScanStatusResult, string) {
 	exitcode, ok := cm.Data["exit-code"]
 	if ok {
@@ -235,6 +285,10 @@ func getScanResult(cm *v1.ConfigMap) (compv1alpha1.ComplianceScanStatusResult, s
 			return compv1alpha1.ResultCompliant, ""
 		case common.OpenSCAPExitCodeNonCompliant:
 			return compv1alpha1.ResultNonCompliant, ""
+		case common.OpenSCAPExitCodeUnchanged:
+			// The operator computes the result from the results of
+			// the previous run that were kept
+			return compv1alpha1.ResultCompliant, ""
 		default:
 			errorMsg, ok := cm.Data["error-msg"]
 			if ok {
@@ -280,13 +334,133 @@ func annotateCMWithScanResult(cm *v1.ConfigMap, cmParsedResults []*utils.ParseRe
 	return cm.DeepCopy()
 }
 
+// saveInputHashes stores the hashes of the inputs of the rules of an
+// incremental scan, so that the next run can tell which rules changed
+func saveInputHashes(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, inputHashes string) error {
+	return saveScanConfigMap(crClient, scan, utils.GetInputHashesConfigMapName(scan.Name), utils.InputHashesKey, inputHashes)
+}
+
+// saveResultDiff stores the diff of the results against the previous run, so
+// that the scan controller can report it in the scan status. An empty diff
+// is stored if there was no previous run to compare against.
+func saveResultDiff(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, diff *compv1alpha1.ScanResultDiff) error {
+	var data string
+	if diff != nil {
+		raw, err := json.Marshal(diff)
+		if err != nil {
+			return fmt.Errorf("cannot encode the result diff: %w", err)
+		}
+		data = string(raw)
+	}
+	return saveScanConfigMap(crClient, scan, utils.GetResultDiffConfigMapName(scan.Name), utils.ResultDiffKey, data)
+}
+
+// listScanCheckResults returns all the check results of the scan once they
+// were created or updated, including the ones an incremental scan didn't
+// evaluate
+func listScanCheckResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan) ([]compv1alpha1.ComplianceCheckResult, error) {
+	checkList := compv1alpha1.ComplianceCheckResultList{}
+	lo := runtimeclient.ListOptions{
+		Namespace:     scan.Namespace,
+		LabelSelector: labels.SelectorFromSet(map[string]string{compv1alpha1.ComplianceScanLabel: scan.Name}),
+	}
+	if err := crClient.getClient().List(context.TODO(), &checkList, &lo); err != nil {
+		return nil, fmt.Errorf("cannot list the results of the scan: %w", err)
+	}
+	return checkList.Items, nil
+}
+
+// saveResultCounts stores the amounts of checks of the scan by their result
+// and severity, so that the scan controller can report them in the scan
+// status
+func saveResultCounts(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, checks []compv1alpha1.ComplianceCheckResult) error {
+	counts := &compv1alpha1.ResultCounts{}
+	for i := range checks {
+		counts.Add(checks[i].Status, checks[i].Severity)
+	}
+	raw, err := json.Marshal(counts)
+	if err != nil {
+		return fmt.Errorf("cannot encode the result counts: %w", err)
+	}
+	return saveScanConfigMap(crClient, scan, utils.GetResultCountsConfigMapName(scan.Name), utils.ResultCountsKey, string(raw))
+}
+
+// saveResultSnapshot stores the results of the checks of the current run of
+// the scan, so that they can be compared with those of other runs
+func saveResultSnapshot(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, checks []compv1alpha1.ComplianceCheckResult) error {
+	snapshot := make(map[string]compv1alpha1.ComplianceCheckStatus, len(checks))
+	for _, check := range checks {
+		snapshot[check.Name] = check.Status
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("cannot encode the result snapshot: %w", err)
+	}
+
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetResultSnapshotsConfigMapName(scan.Name),
+			Namespace: common.GetComplianceOperatorNamespace(),
+		},
+	}
+	// NOTE: Just like the other ConfigMaps that keep data across runs, this
+	// one isn't labeled with the scan name
+	exists := getObjectIfFound(crClient, getObjKey(cm.Name, cm.Namespace), cm)
+	cm.Data = utils.AddResultSnapshot(cm.Data, scan.Status.CurrentIndex, string(raw))
+	return createOrUpdateOneResult(crClient, scan, nil, nil, exists, cm)
+}
+
+// saveScanConfigMap creates or updates a ConfigMap owned by the scan that
+// keeps data across runs of the scan
+func saveScanConfigMap(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, name, key, data string) error {
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: common.GetComplianceOperatorNamespace(),
+		},
+	}
+	// NOTE: The configmap is deliberately not labeled with the scan name,
+	// as those get removed when re-running the scan
+	exists := getObjectIfFound(crClient, getObjKey(cm.Name, cm.Namespace), cm)
+	cm.Data = map[string]string{
+		key: data,
+	}
+	return createOrUpdateOneResult(crClient, scan, nil, nil, exists, cm)
+}
+
+// addToResultDiff accounts for the change of the result of a check between
+// the previous and the current run of a scan
+func addToResultDiff(diff *compv1alpha1.ScanResultDiff, name string, previous, current compv1alpha1.ComplianceCheckStatus) {
+	if previous == current {
+		diff.Unchanged++
+		return
+	}
+	switch current {
+	case compv1alpha1.CheckResultFail:
+		diff.NewlyFailing++
+	case compv1alpha1.CheckResultPass:
+		diff.NewlyPassing++
+	}
+	if len(diff.ChangedChecks) < compv1alpha1.MaxResultDiffChangedChecks {
+		diff.ChangedChecks = append(diff.ChangedChecks, name)
+	}
+}
+
 func markConfigMapAsProcessed(crClient aggregatorCrClient, cm *v1.ConfigMap) error {
 	cmCopy := cm.DeepCopy()
 
 	if cmCopy.Annotations == nil {
 		cmCopy.Annotations = make(map[string]string)
 	}
-	cmCopy.Annotations[configMapRemediationsProcessed] = ""
+	cmCopy.Annotations[compv1alpha1.CmRemediationsProcessedAnnotation] = ""
 
 	err := backoff.Retry(func() error {
 		return crClient.getClient().Update(context.TODO(), cmCopy)
@@ -500,6 +674,7 @@ func canCreateRemediationObject(scan *compv1alpha1.ComplianceScan, obj *unstruct
 
 func getRemediationLabels(scan *compv1alpha1.ComplianceScan, obj runtime.Object) map[string]string {
 	labels := make(map[string]string)
+	addResultMetadata(labels, scan.Spec.ResultLabels)
 	labels[compv1alpha1.ComplianceScanLabel] = scan.Name
 	labels[compv1alpha1.SuiteLabel] = scan.Labels[compv1alpha1.SuiteLabel]
 
@@ -508,6 +683,7 @@ func getRemediationLabels(scan *compv1alpha1.ComplianceScan, obj runtime.Object)
 
 func getCheckResultLabels(pr *utils.ParseResult, resultLabels map[string]string, scan *compv1alpha1.ComplianceScan) map[string]string {
 	labels := make(map[string]string)
+	addResultMetadata(labels, scan.Spec.ResultLabels)
 	labels[compv1alpha1.ComplianceScanLabel] = scan.Name
 	labels[compv1alpha1.ProfileGuidLabel] = scan.Labels[compv1alpha1.ProfileGuidLabel]
 	labels[compv1alpha1.SuiteLabel] = scan.Labels[compv1alpha1.SuiteLabel]
@@ -516,6 +692,12 @@ func getCheckResultLabels(pr *utils.ParseResult, resultLabels map[string]string,
 	if len(pr.CheckResult.ValuesUsed) > 0 {
 		labels[compv1alpha1.ComplianceCheckResultValueLabel] = ""
 	}
+	if customRule, ok := pr.CheckResult.Labels[compv1alpha1.CustomRuleLabel]; ok {
+		labels[compv1alpha1.CustomRuleLabel] = customRule
+	}
+	if pr.CheckResult.NotApplicableReason != nil {
+		labels[compv1alpha1.ComplianceCheckResultNotApplicableReasonLabel] = string(pr.CheckResult.NotApplicableReason.Type)
+	}
 
 	if pr.Remediations != nil {
 		labels[compv1alpha1.ComplianceCheckResultHasRemediation] = ""
@@ -528,8 +710,9 @@ func getCheckResultLabels(pr *utils.ParseResult, resultLabels map[string]string,
 	return labels
 }
 
-func getCheckResultAnnotations(cr *compv1alpha1.ComplianceCheckResult, resultAnnotations map[string]string) map[string]string {
+func getCheckResultAnnotations(cr *compv1alpha1.ComplianceCheckResult, resultAnnotations map[string]string, scan *compv1alpha1.ComplianceScan) map[string]string {
 	annotations := make(map[string]string)
+	addResultMetadata(annotations, scan.Spec.ResultAnnotations)
 	annotations[compv1alpha1.ComplianceCheckResultRuleAnnotation] = utils.IDToDNSFriendlyName(cr.ID)
 	for k, v := range resultAnnotations {
 		annotations[k] = v
@@ -538,11 +721,45 @@ func getCheckResultAnnotations(cr *compv1alpha1.ComplianceCheckResult, resultAnn
 	return annotations
 }
 
-func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, consistentResults []*utils.ParseResultContextItem) error {
+// addResultMetadata copies the labels or annotations that the scan sets on
+// its results, leaving out the ones of the operator
+func addResultMetadata(metadata, resultMetadata map[string]string) {
+	for k, v := range resultMetadata {
+		if !compv1alpha1.IsOperatorMetadataKey(k) {
+			metadata[k] = v
+		}
+	}
+}
+
+// applySeverityOverride replaces the severity of the check with the one the
+// scan overrides it with, if any, keeping the original one in an annotation
+func applySeverityOverride(pr *utils.ParseResultContextItem, scan *compv1alpha1.ComplianceScan) {
+	override := scan.Spec.GetSeverityOverride(utils.IDToDNSFriendlyName(pr.CheckResult.ID))
+	if override == nil {
+		return
+	}
+	if pr.Annotations == nil {
+		pr.Annotations = make(map[string]string)
+	}
+	pr.Annotations[compv1alpha1.ComplianceCheckResultOriginalSeverityAnnotation] = string(pr.CheckResult.Severity)
+	if override.Reason != "" {
+		pr.Annotations[compv1alpha1.ComplianceCheckResultSeverityOverrideReasonAnnotation] = override.Reason
+	}
+	pr.CheckResult.Severity = override.Severity
+}
+
+// createResults creates or updates the ComplianceCheckResults and
+// ComplianceRemediations of a scan. It returns how the results changed
+// compared to the previous run, or nil if the scan had no results yet. For
+// incremental scans, inputHashes holds all the rules in the scan, including
+// those that weren't evaluated because their inputs didn't change.
+func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceScan, consistentResults []*utils.ParseResultContextItem, inputHashes map[string]string) (*compv1alpha1.ScanResultDiff, error) {
 	cmdLog.Info("Will create result objects", "objects", len(consistentResults))
-	if len(consistentResults) == 0 {
+	// The results kept by an incremental scan are still updated when no
+	// rules were evaluated
+	if len(consistentResults) == 0 && len(inputHashes) == 0 {
 		cmdLog.Info("Nothing to create")
-		return nil
+		return nil, nil
 	}
 
 	// This feels like an appropriate seam to implementing forwarding since
@@ -577,7 +794,7 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 	}
 	err := crClient.getClient().List(context.TODO(), &complianceCheckResults, &lo)
 	if err != nil {
-		return fmt.Errorf("Unable to fetch existing ComplianceCheckResultList: %w", err)
+		return nil, fmt.Errorf("Unable to fetch existing ComplianceCheckResultList: %w", err)
 	}
 	for _, r := range complianceCheckResults.Items {
 		// Use a map so that we can find specific
@@ -586,6 +803,17 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		staleComplianceCheckResults[r.Name] = r
 	}
 
+	// The history of the checks is recorded with the time the run started
+	runTimestamp := metav1.Now()
+	if scan.Status.StartTimestamp != nil {
+		runTimestamp = *scan.Status.StartTimestamp
+	}
+
+	var diff *compv1alpha1.ScanResultDiff
+	if len(complianceCheckResults.Items) > 0 {
+		diff = &compv1alpha1.ScanResultDiff{}
+	}
+
 	for _, pr := range consistentResults {
 		if pr == nil || pr.CheckResult == nil {
 			cmdLog.Info("nil result or result.check, this shouldn't happen")
@@ -598,8 +826,10 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 			continue
 		}
 
+		applySeverityOverride(pr, scan)
 		checkResultLabels := getCheckResultLabels(&pr.ParseResult, pr.Labels, scan)
-		checkResultAnnotations := getCheckResultAnnotations(pr.CheckResult, pr.Annotations)
+		checkResultAnnotations := getCheckResultAnnotations(pr.CheckResult, pr.Annotations, scan)
+		checkResultAnnotations[compv1alpha1.ComplianceCheckResultLastReportedAnnotation] = runTimestamp.UTC().Format(time.RFC3339)
 
 		crkey := getObjKey(pr.CheckResult.GetName(), pr.CheckResult.GetNamespace())
 		foundCheckResult := &compv1alpha1.ComplianceCheckResult{}
@@ -608,9 +838,23 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		cmdLog.Info("Getting ComplianceCheckResult", "ComplianceCheckResult.Name", crkey.Name,
 			"ComplianceCheckResult.Namespace", crkey.Namespace)
 		checkResultExists := getObjectIfFound(crClient, crkey, foundCheckResult)
+		pr.CheckResult.AddToHistory(foundCheckResult.History, runTimestamp, scan.Spec.CheckResultHistory)
+		// The review of a manual check is made by the reviewer rather than
+		// the scan, so it's kept as long as the check stays manual
+		if checkResultExists && pr.CheckResult.Status == compv1alpha1.CheckResultManual {
+			pr.CheckResult.Review = foundCheckResult.Review
+		}
+		// The remediations of the check are listed again once they're
+		// handled below
+		if checkResultExists && pr.Remediations != nil {
+			pr.CheckResult.Remediations = foundCheckResult.Remediations
+		}
 		if checkResultExists {
 			// Copy resource version and other metadata needed for update
 			foundCheckResult.ObjectMeta.DeepCopyInto(&pr.CheckResult.ObjectMeta)
+			if diff != nil {
+				addToResultDiff(diff, pr.CheckResult.Name, foundCheckResult.Status, pr.CheckResult.Status)
+			}
 		} else if !scan.Spec.ShowNotApplicable && pr.CheckResult.Status == compv1alpha1.CheckResultNotApplicable {
 			// If the result is not applicable we skip creation
 			// Note that updating a not-applicable result should still
@@ -619,7 +863,7 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 		}
 		// check is owned by the scan
 		if err := createOrUpdateOneResult(crClient, scan, checkResultLabels, checkResultAnnotations, checkResultExists, pr.CheckResult); err != nil {
-			return fmt.Errorf("cannot create or update checkResult %s: %v", pr.CheckResult.Name, err)
+			return nil, fmt.Errorf("cannot create or update checkResult %s: %v", pr.CheckResult.Name, err)
 		}
 
 		// Remove the ComplianceCheckResult from the list of stale
@@ -644,10 +888,21 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 			f.SendComplianceRemediation(r)
 		}
 
+		var remediationNames []string
 		for idx := range pr.Remediations {
 			rem := pr.Remediations[idx]
-			if remErr := handleRemediation(crClient, rem, pr.CheckResult, scan); remErr != nil {
-				return remErr
+			remKept, remErr := handleRemediation(crClient, rem, pr.CheckResult, scan)
+			if remErr != nil {
+				return nil, remErr
+			}
+			if remKept {
+				remediationNames = append(remediationNames, rem.Name)
+			}
+		}
+		if !equality.Semantic.DeepEqual(remediationNames, pr.CheckResult.Remediations) {
+			pr.CheckResult.Remediations = remediationNames
+			if err := createOrUpdateOneResult(crClient, scan, checkResultLabels, checkResultAnnotations, true, pr.CheckResult); err != nil {
+				return nil, fmt.Errorf("cannot update the remediations of checkResult %s: %v", pr.CheckResult.Name, err)
 			}
 		}
 	}
@@ -658,29 +913,54 @@ func createResults(crClient aggregatorCrClient, scan *compv1alpha1.ComplianceSca
 	// they've made to their scans, profiles, or settings haven't taken
 	// effect.
 	for _, result := range staleComplianceCheckResults {
+		if _, ok := inputHashes[result.ID]; ok {
+			// The rule wasn't evaluated because its inputs didn't
+			// change, the result from the previous scan still applies.
+			if err := markResultReported(crClient, &result, runTimestamp); err != nil {
+				return nil, fmt.Errorf("Unable to update unchanged ComplianceCheckResult %s: %w", result.Name, err)
+			}
+			continue
+		}
 		err := crClient.getClient().Delete(context.TODO(), &result)
 		if err != nil {
-			return fmt.Errorf("Unable to delete stale ComplianceCheckResult %s: %w", result.Name, err)
+			return nil, fmt.Errorf("Unable to delete stale ComplianceCheckResult %s: %w", result.Name, err)
 		}
 	}
 
-	return nil
+	return diff, nil
 }
 
-func handleRemediation(crClient aggregatorCrClient, rem *compv1alpha1.ComplianceRemediation, cr *compv1alpha1.ComplianceCheckResult, scan *compv1alpha1.ComplianceScan) error {
+// markResultReported records that the result was reported by the run, so
+// that results that are kept from previous runs don't get pruned as old
+func markResultReported(crClient aggregatorCrClient, result *compv1alpha1.ComplianceCheckResult, runTimestamp metav1.Time) error {
+	reported := runTimestamp.UTC().Format(time.RFC3339)
+	if result.Annotations[compv1alpha1.ComplianceCheckResultLastReportedAnnotation] == reported {
+		return nil
+	}
+	patch := runtimeclient.MergeFrom(result.DeepCopy())
+	if result.Annotations == nil {
+		result.Annotations = make(map[string]string)
+	}
+	result.Annotations[compv1alpha1.ComplianceCheckResultLastReportedAnnotation] = reported
+	return crClient.getClient().Patch(context.TODO(), result, patch)
+}
+
+// handleRemediation creates or updates the remediation of a check. It returns
+// whether the remediation exists once it's handled.
+func handleRemediation(crClient aggregatorCrClient, rem *compv1alpha1.ComplianceRemediation, cr *compv1alpha1.ComplianceCheckResult, scan *compv1alpha1.ComplianceScan) (bool, error) {
 	crkey := getObjKey(cr.GetName(), cr.GetNamespace())
 	remTargetObj := rem.Spec.Current.Object
 	// Skipping is harmless
 	if skip, why := shouldSkipRemediation(scan, rem, crClient); skip {
 		cmdLog.Info(why, "Remediation", crkey.Name)
-		return nil
+		return false, nil
 	}
 
 	// this is a validation and should warn the user
 	if canCreate, why := canCreateRemediationObject(scan, remTargetObj); !canCreate {
 		cmdLog.Info(why, "Remediation", crkey.Name)
 		crClient.getRecorder().Event(scan, v1.EventTypeWarning, "CannotRemediate", why+" Remediation:"+crkey.Name)
-		return nil
+		return false, nil
 	}
 
 	remLabels := getRemediationLabels(scan, remTargetObj)
@@ -701,15 +981,16 @@ func handleRemediation(crClient aggregatorCrClient, rem *compv1alpha1.Compliance
 		// the remediation if the payload differs. Let's not create remediations for checks that are passing
 		// needlessly and let's not trigger the remediation controller needlessly
 		if foundRemediation.Status.ApplicationState == compv1alpha1.RemediationApplied ||
+			foundRemediation.Status.ApplicationState == compv1alpha1.RemediationRollingOut ||
 			foundRemediation.Status.ApplicationState == compv1alpha1.RemediationOutdated {
 			if !foundRemediation.RemediationPayloadDiffers(rem) {
 				cmdLog.Info("Not updating passing remediation that was the same between runs", "ComplianceRemediation.Name", foundRemediation.Name)
-				return nil
+				return true, nil
 			}
 
 			// Applied remediation that differs must be updated, let's set the appropriate state
 			stateUpdate = compv1alpha1.RemediationOutdated
-			if foundRemediation.Status.ApplicationState == compv1alpha1.RemediationApplied {
+			if foundRemediation.Status.ApplicationState != compv1alpha1.RemediationOutdated {
 				// For applied remediations, the old state must be kept in the outdated field
 				// so that the admin can switch to the current state at their own pace
 				foundRemediation.Spec.Current.DeepCopyInto(&rem.Spec.Outdated)
@@ -725,24 +1006,95 @@ func handleRemediation(crClient aggregatorCrClient, rem *compv1alpha1.Compliance
 		}
 
 		// Copy resource version and other metadata needed for update
+		rebootRequired := rem.GetAnnotations()[compv1alpha1.RemediationRebootRequiredAnnotation]
 		foundRemediation.ObjectMeta.DeepCopyInto(&rem.ObjectMeta)
+		// The reboot impact follows the current payload
+		if rebootRequired != "" {
+			metav1.SetMetaDataAnnotation(&rem.ObjectMeta, compv1alpha1.RemediationRebootRequiredAnnotation, rebootRequired)
+		}
+		// Keep the settings the admin might have made on the
+		// remediation
+		rem.Spec.DryRun = foundRemediation.Spec.DryRun
+		rem.Spec.DependsOn = foundRemediation.Spec.DependsOn
+		rem.Spec.Batch = foundRemediation.Spec.Batch
+		rem.Spec.ApplyAfter = foundRemediation.Spec.ApplyAfter
+		rem.Spec.ApplyWindow = foundRemediation.Spec.ApplyWindow
+		rem.Spec.ApplyMethod = foundRemediation.Spec.ApplyMethod
+
+		// Prune the remediations whose checks keep passing without them
+		if scan.Spec.RemediationPruning != nil && !foundRemediation.Spec.Apply && !foundRemediation.IsApplied() {
+			if countPassingRuns(rem, cr) >= scan.Spec.RemediationPruning.GetPassingRuns() {
+				if scan.Spec.RemediationPruning.Action == compv1alpha1.RemediationPruningDelete {
+					return false, pruneRemediation(crClient, rem, scan)
+				}
+				remLabels[compv1alpha1.ObsoleteRemediationLabel] = ""
+			}
+		}
 	} else if cr.Status == compv1alpha1.CheckResultPass {
 		// If the remediation was not created earlier (e.g. the check was always passing), don't bother
 		// creating it now
-		return nil
+		return false, nil
+	}
+
+	// The annotations the scan sets on its results are kept on top of the
+	// ones of the remediation
+	if len(scan.Spec.ResultAnnotations) > 0 {
+		annotations := rem.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		addResultMetadata(annotations, scan.Spec.ResultAnnotations)
+		rem.SetAnnotations(annotations)
 	}
 
 	// remediation is owned by the check
 	if err := createOrUpdateOneResult(crClient, cr, remLabels, nil, remExists, rem); err != nil {
-		return fmt.Errorf("cannot create or update remediation %s: %v", rem.Name, err)
+		return false, fmt.Errorf("cannot create or update remediation %s: %v", rem.Name, err)
 	}
 
 	// Update the status as needed
 	if remExists {
 		if err := updateRemediationStatus(crClient, rem, stateUpdate); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// countPassingRuns keeps count of the consecutive runs the check of the
+// remediation passed in its annotations and returns it
+func countPassingRuns(rem *compv1alpha1.ComplianceRemediation, cr *compv1alpha1.ComplianceCheckResult) int {
+	annotations := rem.GetAnnotations()
+	if cr.Status != compv1alpha1.CheckResultPass {
+		delete(annotations, compv1alpha1.RemediationPassingRunsAnnotation)
+		return 0
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	// A missing or mangled count starts over
+	passingRuns, _ := strconv.Atoi(annotations[compv1alpha1.RemediationPassingRunsAnnotation])
+	passingRuns++
+	annotations[compv1alpha1.RemediationPassingRunsAnnotation] = strconv.Itoa(passingRuns)
+	rem.SetAnnotations(annotations)
+	return passingRuns
+}
+
+// pruneRemediation deletes a remediation whose check kept passing without it
+func pruneRemediation(crClient aggregatorCrClient, rem *compv1alpha1.ComplianceRemediation, scan *compv1alpha1.ComplianceScan) error {
+	cmdLog.Info("Pruning remediation of a check that kept passing", "ComplianceRemediation.Name", rem.Name)
+	err := backoff.Retry(func() error {
+		err := crClient.getClient().Delete(context.TODO(), rem)
+		if err != nil && !errors.IsNotFound(err) {
 			return err
 		}
+		return nil
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+	if err != nil {
+		return fmt.Errorf("cannot prune remediation %s: %v", rem.Name, err)
 	}
+	crClient.getRecorder().Event(scan, v1.EventTypeNormal, "RemediationPruned",
+		fmt.Sprintf("The remediation %s was deleted as its check kept passing", rem.Name))
 	return nil
 }
 
@@ -760,6 +1112,7 @@ func updateRemediationStatus(crClient aggregatorCrClient, parsedRemediation *com
 		}
 		foundRemediation.Status.ErrorMessage = ""
 		foundRemediation.Status.ApplicationState = state
+		foundRemediation.Status.CheckResult = foundRemediation.GetCheckResultName()
 		err := crClient.getClient().Status().Update(context.TODO(), foundRemediation)
 		if err != nil {
 			return fmt.Errorf("cannot update remediation status %s: %v", parsedRemediation.Name, err)
@@ -849,12 +1202,29 @@ func aggregator(cmd *cobra.Command, args []string) {
 	}
 
 	prCtx := utils.NewParseResultContext()
+	var inputHashes map[string]string
+	var rawInputHashes string
 
 	// For each configmap, create a list of remediations
 	for i := range configMaps {
 		cm := &configMaps[i]
 		cmdLog.Info("processing ConfigMap", "ConfigMap.Name", cm.Name)
 
+		if raw, ok := cm.Data[utils.InputHashesKey]; ok && scan.Spec.Incremental {
+			inputHashes, err = parseInputHashes([]byte(raw))
+			if err != nil {
+				cmdLog.Error(err, "Cannot parse the input hashes, the results of rules that weren't evaluated will be removed",
+					"ConfigMap.Name", cm.Name)
+			} else {
+				rawInputHashes = raw
+				// Excluded rules aren't evaluated either, but
+				// their old results shouldn't be kept
+				for _, ruleID := range scan.Spec.ExcludeRules {
+					delete(inputHashes, ruleID)
+				}
+			}
+		}
+
 		cmParsedResults, source, err := parseResultRemediations(crclient.getClient(), crclient.getScheme(), aggregatorConf.ScanName, aggregatorConf.Namespace, contentDom, cm)
 		if err != nil {
 			cmdLog.Error(err, "Cannot parse ConfigMap into remediations", "ConfigMap.Name", cm.Name)
@@ -862,6 +1232,14 @@ func aggregator(cmd *cobra.Command, args []string) {
 			cmdLog.Info("Either no parsed results found in result or result already processed")
 			continue
 		}
+		if raw, ok := cm.Data[utils.CustomRuleResultsKey]; ok {
+			customResults, err := parseCustomRuleResults(aggregatorConf.ScanName, aggregatorConf.Namespace, raw)
+			if err != nil {
+				cmdLog.Error(err, "Cannot parse the results of the custom rules", "ConfigMap.Name", cm.Name)
+			} else {
+				cmParsedResults = append(cmParsedResults, customResults...)
+			}
+		}
 		cmdLog.Info("ConfigMap contained parsed results", "ConfigMap.Name", cm.Name, "results", len(cmParsedResults))
 
 		prCtx.AddResults(source, cmParsedResults)
@@ -876,11 +1254,45 @@ func aggregator(cmd *cobra.Command, args []string) {
 	// of remediations for this scan
 	// Create the remediations
 	cmdLog.Info("Creating result objects")
-	if err := createResults(crclient, scan, consistentParsedResults); err != nil {
+	diff, err := createResults(crclient, scan, consistentParsedResults, inputHashes)
+	if err != nil {
 		cmdLog.Error(err, "Could not create remediation objects")
 		os.Exit(1)
 	}
 
+	cmdLog.Info("Saving the result diff")
+	if err := saveResultDiff(crclient, scan, diff); err != nil {
+		cmdLog.Error(err, "Cannot save the result diff")
+		os.Exit(1)
+	}
+
+	checks, err := listScanCheckResults(crclient, scan)
+	if err != nil {
+		cmdLog.Error(err, "Cannot list the check results")
+		os.Exit(1)
+	}
+
+	cmdLog.Info("Saving the result counts")
+	if err := saveResultCounts(crclient, scan, checks); err != nil {
+		cmdLog.Error(err, "Cannot save the result counts")
+		os.Exit(1)
+	}
+
+	cmdLog.Info("Saving the result snapshot", "index", scan.Status.CurrentIndex)
+	if err := saveResultSnapshot(crclient, scan, checks); err != nil {
+		cmdLog.Error(err, "Cannot save the result snapshot")
+		os.Exit(1)
+	}
+
+	// Only keep the hashes once the results they stand for are stored
+	if rawInputHashes != "" {
+		cmdLog.Info("Saving the input hashes for the next run")
+		if err := saveInputHashes(crclient, scan, rawInputHashes); err != nil {
+			cmdLog.Error(err, "Cannot save the input hashes")
+			os.Exit(1)
+		}
+	}
+
 	// Annotate configMaps, so we don't need to re-parse them
 	cmdLog.Info("Annotating ConfigMaps")
 	for idx := range configMaps {
@@ -2,12 +2,15 @@ package manager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	backoff "github.com/cenkalti/backoff/v4"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	ocpcfgv1 "github.com/openshift/api/config/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -21,6 +24,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 )
 
 type aggregatorCrClientFake struct {
@@ -296,4 +301,370 @@ var _ = Describe("Aggregator Tests", func() {
 			})
 		})
 	})
+
+	Context("Pruning remediations", func() {
+		var scan *compv1alpha1.ComplianceScan
+		var checkResult *compv1alpha1.ComplianceCheckResult
+		var crClient *aggregatorCrClientFake
+		var ctx context.Context
+
+		newParsedRemediation := func() *compv1alpha1.ComplianceRemediation {
+			return &compv1alpha1.ComplianceRemediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-rem",
+					Namespace: "bar",
+				},
+				Spec: compv1alpha1.ComplianceRemediationSpec{
+					Current: compv1alpha1.ComplianceRemediationPayload{
+						Object: &unstructured.Unstructured{
+							Object: map[string]interface{}{
+								"apiVersion": "v1",
+								"kind":       "ConfigMap",
+								"metadata": map[string]interface{}{
+									"name":      "foo-cm",
+									"namespace": "bar",
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		runWithResult := func(status compv1alpha1.ComplianceCheckStatus) bool {
+			checkResult.Status = status
+			kept, err := handleRemediation(crClient, newParsedRemediation(), checkResult, scan)
+			Expect(err).To(BeNil())
+			return kept
+		}
+
+		getRemediation := func() (*compv1alpha1.ComplianceRemediation, error) {
+			rem := &compv1alpha1.ComplianceRemediation{}
+			err := crClient.client.Get(ctx, getObjKey("foo-rem", "bar"), rem)
+			return rem, err
+		}
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := getScheme()
+
+			scan = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "bar",
+				},
+			}
+			scan.Spec.RemediationPruning = &compv1alpha1.RemediationPruningPolicy{
+				Action:      compv1alpha1.RemediationPruningMarkObsolete,
+				PassingRuns: 2,
+			}
+			checkResult = &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-check",
+					Namespace: "bar",
+				},
+			}
+			existing := newParsedRemediation()
+			existing.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+
+			client := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithStatusSubresource(existing).
+				WithRuntimeObjects(scan, checkResult, existing).
+				Build()
+
+			crClient = &aggregatorCrClientFake{
+				scheme:      scheme,
+				client:      client,
+				recorder:    fakerec.NewFakeRecorder(10),
+				fakevgetter: &fakeversionget{},
+			}
+		})
+
+		It("Marks the remediation as obsolete once its check kept passing", func() {
+			runWithResult(compv1alpha1.CheckResultPass)
+			rem, err := getRemediation()
+			Expect(err).To(BeNil())
+			Expect(rem.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationPassingRunsAnnotation, "1"))
+			Expect(rem.Labels).ToNot(HaveKey(compv1alpha1.ObsoleteRemediationLabel))
+
+			runWithResult(compv1alpha1.CheckResultPass)
+			rem, err = getRemediation()
+			Expect(err).To(BeNil())
+			Expect(rem.Labels).To(HaveKey(compv1alpha1.ObsoleteRemediationLabel))
+
+			By("failing the check again")
+			runWithResult(compv1alpha1.CheckResultFail)
+			rem, err = getRemediation()
+			Expect(err).To(BeNil())
+			Expect(rem.Annotations).ToNot(HaveKey(compv1alpha1.RemediationPassingRunsAnnotation))
+			Expect(rem.Labels).ToNot(HaveKey(compv1alpha1.ObsoleteRemediationLabel))
+		})
+
+		It("Deletes the remediation once its check kept passing", func() {
+			scan.Spec.RemediationPruning.Action = compv1alpha1.RemediationPruningDelete
+			runWithResult(compv1alpha1.CheckResultPass)
+			runWithResult(compv1alpha1.CheckResultFail)
+			runWithResult(compv1alpha1.CheckResultPass)
+			_, err := getRemediation()
+			Expect(err).To(BeNil())
+
+			Expect(runWithResult(compv1alpha1.CheckResultPass)).To(BeFalse())
+			_, err = getRemediation()
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Links the remediation to its check", func() {
+			Expect(runWithResult(compv1alpha1.CheckResultFail)).To(BeTrue())
+			rem, err := getRemediation()
+			Expect(err).To(BeNil())
+			Expect(rem.Status.CheckResult).To(Equal("foo-check"))
+		})
+
+		It("Keeps the remediations that are applied", func() {
+			scan.Spec.RemediationPruning.Action = compv1alpha1.RemediationPruningDelete
+			rem, err := getRemediation()
+			Expect(err).To(BeNil())
+			rem.Spec.Apply = true
+			Expect(crClient.client.Update(ctx, rem)).To(Succeed())
+
+			runWithResult(compv1alpha1.CheckResultPass)
+			runWithResult(compv1alpha1.CheckResultPass)
+			_, err = getRemediation()
+			Expect(err).To(BeNil())
+		})
+
+		It("Keeps the remediations by default", func() {
+			scan.Spec.RemediationPruning = nil
+			for i := 0; i < compv1alpha1.DefaultRemediationPruningPassingRuns; i++ {
+				runWithResult(compv1alpha1.CheckResultPass)
+			}
+			rem, err := getRemediation()
+			Expect(err).To(BeNil())
+			Expect(rem.Labels).ToNot(HaveKey(compv1alpha1.ObsoleteRemediationLabel))
+		})
+	})
+
+	Context("Severity overrides", func() {
+		var scan *compv1alpha1.ComplianceScan
+
+		newResult := func(id string) *utils.ParseResultContextItem {
+			return &utils.ParseResultContextItem{
+				ParseResult: utils.ParseResult{
+					CheckResult: &compv1alpha1.ComplianceCheckResult{
+						ID:       id,
+						Severity: compv1alpha1.CheckResultSeverityHigh,
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			scan = &compv1alpha1.ComplianceScan{}
+			scan.Spec.SeverityOverrides = []compv1alpha1.SeverityOverride{
+				{
+					Rule:     "no-empty-passwords",
+					Severity: compv1alpha1.CheckResultSeverityLow,
+					Reason:   "Password logins are disabled",
+				},
+			}
+		})
+
+		It("Overrides the severity of the checks of the rule", func() {
+			pr := newResult("xccdf_org.ssgproject.content_rule_no_empty_passwords")
+			applySeverityOverride(pr, scan)
+			Expect(pr.CheckResult.Severity).To(Equal(compv1alpha1.CheckResultSeverityLow))
+			Expect(pr.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultOriginalSeverityAnnotation, "high"))
+			Expect(pr.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultSeverityOverrideReasonAnnotation, "Password logins are disabled"))
+			Expect(getCheckResultLabels(&pr.ParseResult, pr.Labels, scan)).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultSeverityLabel, "low"))
+		})
+
+		It("Keeps the severity of the checks of other rules", func() {
+			pr := newResult("xccdf_org.ssgproject.content_rule_audit_rules_login_events")
+			applySeverityOverride(pr, scan)
+			Expect(pr.CheckResult.Severity).To(Equal(compv1alpha1.CheckResultSeverityHigh))
+			Expect(pr.Annotations).To(BeEmpty())
+		})
+	})
+
+	Context("Result metadata", func() {
+		It("Sets the metadata of the scan on the results without overriding the operator's", func() {
+			scan := &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis"},
+			}
+			scan.Spec.ResultLabels = map[string]string{
+				"team":                           "payments",
+				compv1alpha1.ComplianceScanLabel: "other-scan",
+			}
+			scan.Spec.ResultAnnotations = map[string]string{
+				"example.com/ticket":                             "SEC-1234",
+				compv1alpha1.ComplianceCheckResultRuleAnnotation: "other-rule",
+			}
+			pr := &utils.ParseResult{
+				CheckResult: &compv1alpha1.ComplianceCheckResult{
+					ID:     "xccdf_org.ssgproject.content_rule_audit_rules_login_events",
+					Status: compv1alpha1.CheckResultFail,
+				},
+			}
+
+			checkLabels := getCheckResultLabels(pr, nil, scan)
+			Expect(checkLabels).To(HaveKeyWithValue("team", "payments"))
+			Expect(checkLabels).To(HaveKeyWithValue(compv1alpha1.ComplianceScanLabel, "ocp4-cis"))
+			checkAnnotations := getCheckResultAnnotations(pr.CheckResult, nil, scan)
+			Expect(checkAnnotations).To(HaveKeyWithValue("example.com/ticket", "SEC-1234"))
+			Expect(checkAnnotations).To(HaveKeyWithValue(compv1alpha1.ComplianceCheckResultRuleAnnotation, "audit-rules-login-events"))
+			remLabels := getRemediationLabels(scan, nil)
+			Expect(remLabels).To(HaveKeyWithValue("team", "payments"))
+			Expect(remLabels).To(HaveKeyWithValue(compv1alpha1.ComplianceScanLabel, "ocp4-cis"))
+		})
+	})
+
+	Context("Incremental scans", func() {
+		It("Keeps the previous results if no rules were evaluated", func() {
+			cm := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ocp4-cis-api-checks-pod",
+					Annotations: map[string]string{
+						"openscap-scan-result/node": "",
+					},
+				},
+				Data: map[string]string{
+					"exit-code": common.OpenSCAPExitCodeUnchanged,
+					"results":   "",
+				},
+			}
+			results, _, err := parseResultRemediations(nil, nil, "ocp4-cis", "openshift-compliance", nil, cm)
+			Expect(err).To(BeNil())
+			Expect(results).ToNot(BeNil())
+			Expect(results).To(BeEmpty())
+
+			result, errMsg := getScanResult(cm)
+			Expect(result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(errMsg).To(BeEmpty())
+		})
+	})
+
+	Context("Failure evidence", func() {
+		It("Only sets the evidence of the failing checks", func() {
+			results := []*utils.ParseResult{
+				{
+					Id:          "xccdf_org.ssgproject.content_rule_sshd_disable_root_login",
+					CheckResult: &compv1alpha1.ComplianceCheckResult{Status: compv1alpha1.CheckResultFail},
+				},
+				{
+					Id:          "xccdf_org.ssgproject.content_rule_selinux_state",
+					CheckResult: &compv1alpha1.ComplianceCheckResult{Status: compv1alpha1.CheckResultPass},
+				},
+			}
+			evidence := `{"xccdf_org.ssgproject.content_rule_sshd_disable_root_login": "text: PermitRootLogin yes",
+				"xccdf_org.ssgproject.content_rule_selinux_state": "text: SELINUX=enforcing"}`
+			addFailureEvidence(results, evidence, "worker-0")
+			Expect(results[0].CheckResult.Evidence).To(Equal("Collected on node worker-0:\ntext: PermitRootLogin yes"))
+			Expect(results[1].CheckResult.Evidence).To(BeEmpty())
+		})
+	})
+
+	Context("Result diff", func() {
+		var diff *compv1alpha1.ScanResultDiff
+
+		BeforeEach(func() {
+			diff = &compv1alpha1.ScanResultDiff{}
+		})
+
+		It("Counts the checks that changed and didn't change", func() {
+			addToResultDiff(diff, "check-a", compv1alpha1.CheckResultPass, compv1alpha1.CheckResultFail)
+			addToResultDiff(diff, "check-b", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultPass)
+			addToResultDiff(diff, "check-c", compv1alpha1.CheckResultError, compv1alpha1.CheckResultPass)
+			addToResultDiff(diff, "check-d", compv1alpha1.CheckResultPass, compv1alpha1.CheckResultPass)
+			addToResultDiff(diff, "check-e", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultManual)
+
+			Expect(diff.NewlyFailing).To(Equal(1))
+			Expect(diff.NewlyPassing).To(Equal(2))
+			Expect(diff.Unchanged).To(Equal(1))
+			Expect(diff.ChangedChecks).To(Equal([]string{"check-a", "check-b", "check-c", "check-e"}))
+		})
+
+		It("Caps the number of changed checks that are listed", func() {
+			for i := 0; i < compv1alpha1.MaxResultDiffChangedChecks+10; i++ {
+				addToResultDiff(diff, fmt.Sprintf("check-%d", i), compv1alpha1.CheckResultPass, compv1alpha1.CheckResultFail)
+			}
+
+			Expect(diff.NewlyFailing).To(Equal(compv1alpha1.MaxResultDiffChangedChecks + 10))
+			Expect(diff.ChangedChecks).To(HaveLen(compv1alpha1.MaxResultDiffChangedChecks))
+		})
+	})
+
+	Context("Result snapshots and counts", func() {
+		var (
+			namespace string
+			scan      *compv1alpha1.ComplianceScan
+			crClient  *aggregatorCrClientFake
+		)
+
+		BeforeEach(func() {
+			scheme := getScheme()
+			namespace = common.GetComplianceOperatorNamespace()
+			scan = &compv1alpha1.ComplianceScan{
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: namespace},
+			}
+			scan.Status.CurrentIndex = 3
+			newCheck := func(name string, status compv1alpha1.ComplianceCheckStatus, severity compv1alpha1.ComplianceCheckResultSeverity) *compv1alpha1.ComplianceCheckResult {
+				return &compv1alpha1.ComplianceCheckResult{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+						Labels:    map[string]string{compv1alpha1.ComplianceScanLabel: "foo"},
+					},
+					Status:   status,
+					Severity: severity,
+				}
+			}
+			otherScanCheck := newCheck("other-check", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityHigh)
+			otherScanCheck.Labels[compv1alpha1.ComplianceScanLabel] = "other"
+			crClient = &aggregatorCrClientFake{
+				scheme: scheme,
+				client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(scan,
+					newCheck("foo-check-a", compv1alpha1.CheckResultPass, compv1alpha1.CheckResultSeverityHigh),
+					newCheck("foo-check-b", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityHigh),
+					newCheck("foo-check-c", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityMedium),
+					newCheck("foo-check-d", compv1alpha1.CheckResultManual, compv1alpha1.CheckResultSeverityUnknown),
+					otherScanCheck).Build(),
+			}
+		})
+
+		It("Stores the results of the checks of each run", func() {
+			checks, err := listScanCheckResults(crClient, scan)
+			Expect(err).To(BeNil())
+			Expect(checks).To(HaveLen(4))
+			Expect(saveResultSnapshot(crClient, scan, checks)).To(Succeed())
+			scan.Status.CurrentIndex = 4
+			Expect(saveResultSnapshot(crClient, scan, checks)).To(Succeed())
+
+			cm := &v1.ConfigMap{}
+			key := getObjKey(utils.GetResultSnapshotsConfigMapName("foo"), namespace)
+			Expect(crClient.client.Get(context.TODO(), key, cm)).To(Succeed())
+			Expect(cm.Data).To(HaveLen(2))
+			Expect(cm.Data).To(HaveKeyWithValue("3", `{"foo-check-a":"PASS","foo-check-b":"FAIL","foo-check-c":"FAIL","foo-check-d":"MANUAL"}`))
+			Expect(cm.Data).To(HaveKey("4"))
+		})
+
+		It("Stores the amounts of checks by result and severity", func() {
+			checks, err := listScanCheckResults(crClient, scan)
+			Expect(err).To(BeNil())
+			Expect(saveResultCounts(crClient, scan, checks)).To(Succeed())
+
+			cm := &v1.ConfigMap{}
+			key := getObjKey(utils.GetResultCountsConfigMapName("foo"), namespace)
+			Expect(crClient.client.Get(context.TODO(), key, cm)).To(Succeed())
+			counts := compv1alpha1.ResultCounts{}
+			Expect(json.Unmarshal([]byte(cm.Data[utils.ResultCountsKey]), &counts)).To(Succeed())
+			Expect(counts).To(Equal(compv1alpha1.ResultCounts{
+				Total:   4,
+				Results: compv1alpha1.CheckStatusCounts{Pass: 1, Fail: 2, Manual: 1},
+				High:    compv1alpha1.CheckStatusCounts{Pass: 1, Fail: 1},
+				Medium:  compv1alpha1.CheckStatusCounts{Fail: 1},
+				Other:   compv1alpha1.CheckStatusCounts{Manual: 1},
+			}))
+		})
+	})
 })
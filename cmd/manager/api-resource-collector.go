@@ -16,11 +16,13 @@ limitations under the License.
 package manager
 
 import (
+	"context"
 	"flag"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 
@@ -54,6 +56,9 @@ type ResourceFetcher interface {
 	SaveWarningsIfAny([]string, string) error
 	// Save the resources.
 	SaveResources(to string) error
+	// Save the hashes of the resources each rule needs, and the rules
+	// whose resources changed since the previous run.
+	SaveInputHashes(contentDigest, previousFile, hashesFile, changedRulesFile string) error
 }
 
 type fetcherConfig struct {
@@ -63,6 +68,13 @@ type fetcherConfig struct {
 	Profile            string
 	ExitCodeFile       string
 	WarningsOutputFile string
+	// Only set for incremental scans
+	InputHashesFile         string
+	PreviousInputHashesFile string
+	ChangedRulesFile        string
+	// Only set for scans with custom rules
+	CustomRules           []string
+	CustomRuleResultsFile string
 }
 
 func defineAPIResourceCollectorFlags(cmd *cobra.Command) {
@@ -73,6 +85,11 @@ func defineAPIResourceCollectorFlags(cmd *cobra.Command) {
 	cmd.Flags().String("warnings-output-file", "", "A file containing the warnings output.")
 	cmd.Flags().Bool("debug", false, "Print debug messages.")
 	cmd.Flags().String("platform", "", "The platform flag used by CPE detection.")
+	cmd.Flags().String("input-hashes-file", "", "The file to write the hashes of the inputs of each rule to.")
+	cmd.Flags().String("previous-input-hashes-file", "", "The file containing the hashes of the inputs of each rule from the previous run.")
+	cmd.Flags().String("changed-rules-file", "", "The file to write the rules whose inputs changed since the previous run to.")
+	cmd.Flags().String("custom-rules", "", "The comma-separated names of the custom rules to evaluate.")
+	cmd.Flags().String("custom-rule-results-file", "", "The file to write the results of the custom rules to.")
 
 	flags := cmd.Flags()
 
@@ -89,6 +106,12 @@ func parseAPIResourceCollectorConfig(cmd *cobra.Command) *fetcherConfig {
 	conf.WarningsOutputFile = getValidStringArg(cmd, "warnings-output-file")
 	debugLog, _ = cmd.Flags().GetBool("debug")
 	conf.Tailoring, _ = cmd.Flags().GetString("tailoring")
+	conf.InputHashesFile, _ = cmd.Flags().GetString("input-hashes-file")
+	conf.PreviousInputHashesFile, _ = cmd.Flags().GetString("previous-input-hashes-file")
+	conf.ChangedRulesFile, _ = cmd.Flags().GetString("changed-rules-file")
+	customRules, _ := cmd.Flags().GetString("custom-rules")
+	conf.CustomRules = parseCustomRuleNames(customRules)
+	conf.CustomRuleResultsFile, _ = cmd.Flags().GetString("custom-rule-results-file")
 	return &conf
 }
 
@@ -149,4 +172,25 @@ func runAPIResourceCollector(cmd *cobra.Command, args []string) {
 	if err := fetcher.SaveResources(fetcherConf.ResultDir); err != nil {
 		FATAL("Error saving resources: %v", err)
 	}
+
+	if fetcherConf.InputHashesFile != "" {
+		contentDigest, err := getContentDigest(fetcherConf.Profile, fetcherConf.Content, fetcherConf.Tailoring)
+		if err != nil {
+			FATAL("Error hashing the content: %v", err)
+		}
+		err = fetcher.SaveInputHashes(contentDigest, fetcherConf.PreviousInputHashesFile,
+			fetcherConf.InputHashesFile, fetcherConf.ChangedRulesFile)
+		if err != nil {
+			FATAL("Error saving the input hashes: %v", err)
+		}
+	}
+
+	if fetcherConf.CustomRuleResultsFile != "" {
+		rfClients := resourceFetcherClients{client: client, clientset: kubeClientSet, scheme: scheme}
+		results := evaluateCustomRules(context.Background(), getStreamerFn, rfClients,
+			common.GetComplianceOperatorNamespace(), fetcherConf.CustomRules)
+		if err := saveCustomRuleResults(results, fetcherConf.CustomRuleResultsFile); err != nil {
+			FATAL("Error saving the results of the custom rules: %v", err)
+		}
+	}
 }
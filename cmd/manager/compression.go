@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// rawResultExtensions maps the encodings the result server accepts the raw
+// results in to the extension of the files it stores them in
+var rawResultExtensions = map[string]string{
+	string(compv1alpha1.RawResultCompressionBzip2): ".bzip2",
+	string(compv1alpha1.RawResultCompressionZstd):  ".zst",
+}
+
+// resultCodec compresses the result files before they're uploaded
+type resultCodec struct {
+	// The name of the codec, as given in the Content-Encoding header
+	encoding string
+	// The zstd compression level, bzip2 always compresses the most
+	level int
+}
+
+// bzip2Codec is the codec every version of the result server accepts, and
+// the one the results stored in ConfigMaps are compressed with
+var bzip2Codec = resultCodec{encoding: string(compv1alpha1.RawResultCompressionBzip2)}
+
+// newResultCodec returns the codec of the given encoding, compressing with
+// the given level if the codec has levels
+func newResultCodec(encoding string, level int) (resultCodec, error) {
+	switch compv1alpha1.RawResultCompression(encoding) {
+	case "", compv1alpha1.RawResultCompressionBzip2:
+		return bzip2Codec, nil
+	case compv1alpha1.RawResultCompressionZstd:
+		if level == 0 {
+			level = compv1alpha1.DefaultZstdCompressionLevel
+		}
+		if level < 1 || level > 22 {
+			return resultCodec{}, fmt.Errorf("invalid zstd compression level %d, it needs to be between 1 and 22", level)
+		}
+		return resultCodec{encoding: encoding, level: level}, nil
+	}
+	return resultCodec{}, fmt.Errorf("unknown compression codec %s", encoding)
+}
+
+func (c resultCodec) compress(contents io.Reader) (io.Reader, error) {
+	// The contents are compressed in memory, so they can be stored in a
+	// ConfigMap or uploaded again if the upload is retried
+	var buffer bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	if c.encoding == string(compv1alpha1.RawResultCompressionZstd) {
+		// The encoder runs in a single goroutine, which bounds the memory
+		// it uses within the limits of the log collector
+		w, err = zstd.NewWriter(&buffer,
+			zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(c.level)),
+			zstd.WithEncoderConcurrency(1))
+	} else {
+		w, err = bzip2.NewWriter(&buffer, &bzip2.WriterConfig{Level: bzip2.BestCompression})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, contents); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &buffer, nil
+}
+
+// getRawResultExtension returns the extension of the files the raw results
+// of the given encoding are stored in, and whether the encoding is accepted
+func getRawResultExtension(encoding string) (string, bool) {
+	if encoding == "" {
+		return "", true
+	}
+	extension, ok := rawResultExtensions[encoding]
+	return extension, ok
+}
+
+// getAcceptedRawResultEncodings returns the encodings the result server
+// accepts, as given in the Accept-Encoding header of its responses
+func getAcceptedRawResultEncodings() string {
+	encodings := make([]string, 0, len(rawResultExtensions))
+	for encoding := range rawResultExtensions {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return strings.Join(encodings, ", ")
+}
+
+// negotiateResultCodec returns the codec to fall back to once the result
+// server rejected the encoding of an upload, out of the ones it accepts.
+// Servers that predate the negotiation don't tell which encodings they
+// accept, but all of them accept bzip2.
+func negotiateResultCodec(rejected string, acceptEncoding string) (resultCodec, error) {
+	if rejected == bzip2Codec.encoding {
+		return resultCodec{}, fmt.Errorf("the result server doesn't accept %s-compressed results", rejected)
+	}
+	if acceptEncoding == "" {
+		return bzip2Codec, nil
+	}
+	for _, accepted := range strings.Split(acceptEncoding, ",") {
+		// The preferences of the server, if any, don't matter as
+		// bzip2 is the only codec to fall back to
+		accepted = strings.TrimSpace(strings.SplitN(accepted, ";", 2)[0])
+		if accepted == bzip2Codec.encoding {
+			return bzip2Codec, nil
+		}
+	}
+	return resultCodec{}, fmt.Errorf("the result server only accepts results compressed with %s", acceptEncoding)
+}
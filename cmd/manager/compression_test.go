@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dsnet/compress/bzip2"
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Raw result compression", func() {
+	report := strings.Repeat("<rule-result idref=\"xccdf_org.ssgproject.content_rule_audit\"><result>pass</result></rule-result>\n", 1000)
+
+	decompress := func(codec resultCodec, compressed io.Reader) string {
+		var r io.Reader
+		var err error
+		if codec.encoding == "zstd" {
+			var d *zstd.Decoder
+			d, err = zstd.NewReader(compressed)
+			Expect(err).To(BeNil())
+			defer d.Close()
+			r = d
+		} else {
+			r, err = bzip2.NewReader(compressed, &bzip2.ReaderConfig{})
+			Expect(err).To(BeNil())
+		}
+		raw, err := io.ReadAll(r)
+		Expect(err).To(BeNil())
+		return string(raw)
+	}
+
+	It("compresses with bzip2 by default", func() {
+		codec, err := newResultCodec("", 0)
+		Expect(err).To(BeNil())
+		Expect(codec).To(Equal(bzip2Codec))
+		compressed, err := codec.compress(strings.NewReader(report))
+		Expect(err).To(BeNil())
+		Expect(decompress(codec, compressed)).To(Equal(report))
+	})
+
+	It("compresses with zstd at the given level", func() {
+		for _, level := range []int{0, 1, 19} {
+			codec, err := newResultCodec("zstd", level)
+			Expect(err).To(BeNil())
+			compressed, err := codec.compress(strings.NewReader(report))
+			Expect(err).To(BeNil())
+			raw, err := io.ReadAll(compressed)
+			Expect(err).To(BeNil())
+			Expect(len(raw)).To(BeNumerically("<", len(report)/10))
+			Expect(decompress(codec, bytes.NewReader(raw))).To(Equal(report))
+		}
+	})
+
+	It("rejects unknown codecs and levels", func() {
+		_, err := newResultCodec("gzip", 0)
+		Expect(err).To(MatchError("unknown compression codec gzip"))
+		_, err = newResultCodec("zstd", 23)
+		Expect(err).To(MatchError(ContainSubstring("invalid zstd compression level 23")))
+	})
+
+	It("stores the raw results with the extension of their encoding", func() {
+		Expect(getAcceptedRawResultEncodings()).To(Equal("bzip2, zstd"))
+		for encoding, extension := range map[string]string{"": "", "bzip2": ".bzip2", "zstd": ".zst"} {
+			found, ok := getRawResultExtension(encoding)
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(extension))
+		}
+		_, ok := getRawResultExtension("gzip")
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("negotiating the codec with the result server", func() {
+		It("falls back to bzip2 if the server accepts it", func() {
+			codec, err := negotiateResultCodec("zstd", "bzip2;q=0.5, gzip")
+			Expect(err).To(BeNil())
+			Expect(codec).To(Equal(bzip2Codec))
+		})
+
+		It("falls back to bzip2 with servers that don't tell what they accept", func() {
+			resp := &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}}
+			Expect(resultServerRejectsEncoding(resp, "zstd")).To(BeTrue())
+			codec, err := negotiateResultCodec("zstd", resp.Header.Get("Accept-Encoding"))
+			Expect(err).To(BeNil())
+			Expect(codec).To(Equal(bzip2Codec))
+		})
+
+		It("gives up if there's nothing to fall back to", func() {
+			_, err := negotiateResultCodec("bzip2", "zstd")
+			Expect(err).To(MatchError("the result server doesn't accept bzip2-compressed results"))
+			_, err = negotiateResultCodec("zstd", "gzip")
+			Expect(err).To(MatchError("the result server only accepts results compressed with gzip"))
+		})
+
+		It("only falls back if the server rejected the encoding", func() {
+			Expect(resultServerRejectsEncoding(&http.Response{StatusCode: http.StatusUnsupportedMediaType}, "zstd")).To(BeTrue())
+			Expect(resultServerRejectsEncoding(&http.Response{StatusCode: http.StatusBadRequest}, "bzip2")).To(BeFalse())
+			Expect(resultServerRejectsEncoding(&http.Response{StatusCode: http.StatusOK}, "zstd")).To(BeFalse())
+			Expect(resultServerRejectsEncoding(&http.Response{StatusCode: http.StatusBadRequest}, "")).To(BeFalse())
+		})
+	})
+})
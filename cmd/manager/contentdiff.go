@@ -0,0 +1,151 @@
+package manager
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// parsedContent are the profiles and rules parsed out of the content of a
+// ProfileBundle
+type parsedContent struct {
+	// The names of the rules of each profile
+	profiles map[string][]string
+	rules    map[string]cmpv1alpha1.RulePayload
+}
+
+// getParsedContent returns the profiles and rules that were parsed out of the
+// content of the ProfileBundle in the given epoch, or in any epoch if it's
+// empty. Obsolete objects that weren't garbage collected have an older epoch.
+func getParsedContent(client runtimeclient.Client, pb *cmpv1alpha1.ProfileBundle, epoch string) (*parsedContent, error) {
+	inNs := runtimeclient.InNamespace(pb.Namespace)
+	withPbOwnerLabel := runtimeclient.MatchingLabels{
+		cmpv1alpha1.ProfileBundleOwnerLabel: pb.Name,
+	}
+
+	profileList := &cmpv1alpha1.ProfileList{}
+	if err := client.List(context.TODO(), profileList, inNs, withPbOwnerLabel); err != nil {
+		return nil, err
+	}
+	ruleList := &cmpv1alpha1.RuleList{}
+	if err := client.List(context.TODO(), ruleList, inNs, withPbOwnerLabel); err != nil {
+		return nil, err
+	}
+
+	content := &parsedContent{
+		profiles: make(map[string][]string, len(profileList.Items)),
+		rules:    make(map[string]cmpv1alpha1.RulePayload, len(ruleList.Items)),
+	}
+	for i := range profileList.Items {
+		profile := &profileList.Items[i]
+		if !inEpoch(profile, epoch) {
+			continue
+		}
+		rules := make([]string, 0, len(profile.Rules))
+		for _, rule := range profile.Rules {
+			rules = append(rules, string(rule))
+		}
+		content.profiles[profile.Name] = rules
+	}
+	for i := range ruleList.Items {
+		if !inEpoch(&ruleList.Items[i], epoch) {
+			continue
+		}
+		content.rules[ruleList.Items[i].Name] = ruleList.Items[i].RulePayload
+	}
+	return content, nil
+}
+
+func inEpoch(obj metav1.Object, epoch string) bool {
+	return epoch == "" || obj.GetAnnotations()[cmpv1alpha1.ProfileImageDigestAnnotation] == epoch
+}
+
+// diffParsedContent describes how the profiles changed between the previous
+// and the current content. There's no diff for the first content parsed.
+func diffParsedContent(previous, current *parsedContent) *cmpv1alpha1.ProfileBundleContentDiff {
+	if previous == nil || len(previous.profiles) == 0 {
+		return nil
+	}
+
+	diff := &cmpv1alpha1.ProfileBundleContentDiff{}
+	for _, name := range sortedKeys(current.profiles) {
+		previousRules, ok := previous.profiles[name]
+		if !ok {
+			diff.AddedProfiles = append(diff.AddedProfiles, name)
+			continue
+		}
+		if profileDiff := diffProfileRules(name, previousRules, current.profiles[name], previous, current); profileDiff != nil {
+			diff.ChangedProfiles = append(diff.ChangedProfiles, *profileDiff)
+		}
+	}
+	for _, name := range sortedKeys(previous.profiles) {
+		if _, ok := current.profiles[name]; !ok {
+			diff.RemovedProfiles = append(diff.RemovedProfiles, name)
+		}
+	}
+	return diff
+}
+
+// diffProfileRules returns how the rules of a profile changed, or nil if they
+// didn't
+func diffProfileRules(name string, previousRules, currentRules []string, previous, current *parsedContent) *cmpv1alpha1.ProfileContentDiff {
+	inPrevious := make(map[string]bool, len(previousRules))
+	for _, rule := range previousRules {
+		inPrevious[rule] = true
+	}
+	inCurrent := make(map[string]bool, len(currentRules))
+	for _, rule := range currentRules {
+		inCurrent[rule] = true
+	}
+
+	var added, removed, changed []string
+	for _, rule := range currentRules {
+		if !inPrevious[rule] {
+			added = append(added, rule)
+		} else if !reflect.DeepEqual(previous.rules[rule], current.rules[rule]) {
+			changed = append(changed, rule)
+		}
+	}
+	for _, rule := range previousRules {
+		if !inCurrent[rule] {
+			removed = append(removed, rule)
+		}
+	}
+	if len(added)+len(removed)+len(changed) == 0 {
+		return nil
+	}
+
+	return &cmpv1alpha1.ProfileContentDiff{
+		Name:         name,
+		RulesAdded:   len(added),
+		RulesRemoved: len(removed),
+		RulesChanged: len(changed),
+		AddedRules:   capRuleNames(added),
+		RemovedRules: capRuleNames(removed),
+		ChangedRules: capRuleNames(changed),
+	}
+}
+
+// capRuleNames sorts the rule names and keeps the first MaxContentDiffRules
+// of them
+func capRuleNames(names []string) []string {
+	sort.Strings(names)
+	if len(names) > cmpv1alpha1.MaxContentDiffRules {
+		return names[:cmpv1alpha1.MaxContentDiffRules]
+	}
+	return names
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,113 @@
+package manager
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Comparing parsed contents", func() {
+	var previous *parsedContent
+
+	BeforeEach(func() {
+		previous = &parsedContent{
+			profiles: map[string][]string{
+				"ocp4-cis":      {"ocp4-api-server-encryption", "ocp4-kubeadmin-removed"},
+				"ocp4-moderate": {"ocp4-api-server-encryption"},
+			},
+			rules: map[string]cmpv1alpha1.RulePayload{
+				"ocp4-api-server-encryption": {Title: "Configure the Encryption Provider"},
+				"ocp4-kubeadmin-removed":     {Title: "Remove the kubeadmin user"},
+			},
+		}
+	})
+
+	It("has no diff for the first content", func() {
+		Expect(diffParsedContent(&parsedContent{}, previous)).To(BeNil())
+	})
+
+	It("lists the added, removed and changed profiles", func() {
+		current := &parsedContent{
+			profiles: map[string][]string{
+				"ocp4-cis": {"ocp4-api-server-encryption", "ocp4-audit-profile-set"},
+				"ocp4-e8":  {"ocp4-kubeadmin-removed"},
+			},
+			rules: map[string]cmpv1alpha1.RulePayload{
+				"ocp4-api-server-encryption": {Title: "Configure the Encryption Provider Cipher"},
+				"ocp4-kubeadmin-removed":     {Title: "Remove the kubeadmin user"},
+				"ocp4-audit-profile-set":     {Title: "Ensure the audit profile is set"},
+			},
+		}
+
+		Expect(diffParsedContent(previous, current)).To(Equal(&cmpv1alpha1.ProfileBundleContentDiff{
+			AddedProfiles:   []string{"ocp4-e8"},
+			RemovedProfiles: []string{"ocp4-moderate"},
+			ChangedProfiles: []cmpv1alpha1.ProfileContentDiff{
+				{
+					Name:         "ocp4-cis",
+					RulesAdded:   1,
+					RulesRemoved: 1,
+					RulesChanged: 1,
+					AddedRules:   []string{"ocp4-audit-profile-set"},
+					RemovedRules: []string{"ocp4-kubeadmin-removed"},
+					ChangedRules: []string{"ocp4-api-server-encryption"},
+				},
+			},
+		}))
+	})
+
+	It("has an empty diff for the same content", func() {
+		Expect(diffParsedContent(previous, previous)).To(Equal(&cmpv1alpha1.ProfileBundleContentDiff{}))
+	})
+
+	It("caps the names of the rules, but not their number", func() {
+		current := &parsedContent{profiles: map[string][]string{"ocp4-cis": {}}, rules: previous.rules}
+		for i := 0; i < cmpv1alpha1.MaxContentDiffRules+10; i++ {
+			current.profiles["ocp4-cis"] = append(current.profiles["ocp4-cis"], fmt.Sprintf("ocp4-rule-%03d", i))
+		}
+
+		diff := diffParsedContent(previous, current)
+		Expect(diff.ChangedProfiles).To(HaveLen(1))
+		Expect(diff.ChangedProfiles[0].RulesAdded).To(Equal(cmpv1alpha1.MaxContentDiffRules + 10))
+		Expect(diff.ChangedProfiles[0].AddedRules).To(HaveLen(cmpv1alpha1.MaxContentDiffRules))
+		Expect(diff.ChangedProfiles[0].AddedRules[0]).To(Equal("ocp4-rule-000"))
+	})
+
+	It("gets the profiles and rules of the ProfileBundle", func() {
+		pb := &cmpv1alpha1.ProfileBundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4", Namespace: "openshift-compliance"},
+		}
+		owned := metav1.ObjectMeta{
+			Namespace: "openshift-compliance",
+			Labels:    map[string]string{cmpv1alpha1.ProfileBundleOwnerLabel: "ocp4"},
+		}
+		profile := &cmpv1alpha1.Profile{ObjectMeta: *owned.DeepCopy()}
+		profile.Name = "ocp4-cis"
+		profile.Rules = []cmpv1alpha1.ProfileRule{"ocp4-kubeadmin-removed"}
+		rule := &cmpv1alpha1.Rule{ObjectMeta: *owned.DeepCopy()}
+		rule.Name = "ocp4-kubeadmin-removed"
+		rule.Title = "Remove the kubeadmin user"
+		otherRule := &cmpv1alpha1.Rule{ObjectMeta: metav1.ObjectMeta{
+			Name:      "rhcos4-audit-rules",
+			Namespace: "openshift-compliance",
+			Labels:    map[string]string{cmpv1alpha1.ProfileBundleOwnerLabel: "rhcos4"},
+		}}
+		client := fake.NewClientBuilder().WithScheme(getScheme()).WithObjects(profile, rule, otherRule).Build()
+
+		content, err := getParsedContent(client, pb, "")
+		Expect(err).To(BeNil())
+		Expect(content.profiles).To(Equal(map[string][]string{"ocp4-cis": {"ocp4-kubeadmin-removed"}}))
+		Expect(content.rules).To(HaveLen(1))
+		Expect(content.rules["ocp4-kubeadmin-removed"].Title).To(Equal("Remove the kubeadmin user"))
+
+		content, err = getParsedContent(client, pb, "pb-ocp4-abcde")
+		Expect(err).To(BeNil())
+		Expect(content.profiles).To(BeEmpty())
+		Expect(content.rules).To(BeEmpty())
+	})
+})
@@ -0,0 +1,219 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/spf13/cobra"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var ContentfetcherCmd = &cobra.Command{
+	Use:   "contentfetcher",
+	Short: "Downloads the content of a profile bundle",
+	Long:  `The contentfetcher downloads a data stream file from an HTTPS URL or an OCI artifact and verifies its checksum.`,
+	Run:   runContentFetcher,
+}
+
+func init() {
+	defineContentFetcherFlags(ContentfetcherCmd)
+}
+
+type contentFetcherConfig struct {
+	URL      string
+	Checksum string
+	// The reference by digest to the OCI artifact to download the layer
+	// titled ArtifactFile of, used instead of URL
+	Artifact     string
+	ArtifactFile string
+	// The references to the artifact in its mirrors, which are tried
+	// before the artifact
+	ArtifactMirrors []string
+	// Whether the artifact may only be downloaded from its mirrors
+	NeverContactSource bool
+	Output             string
+	// The directory the Secret of the profile bundle is mounted in, if any
+	AuthDir string
+	Timeout time.Duration
+}
+
+func defineContentFetcherFlags(cmd *cobra.Command) {
+	cmd.Flags().String("url", "", "The HTTPS URL to download the data stream from")
+	cmd.Flags().String("checksum", "", "The sha256:<hex digest> checksum the data stream needs to match")
+	cmd.Flags().String("artifact", "", "The reference by digest to the OCI artifact to download the data stream from, instead of the URL")
+	cmd.Flags().String("artifact-file", "", "The title of the layer of the artifact that contains the data stream")
+	cmd.Flags().StringArray("artifact-mirror", nil, "A reference to the artifact in one of its mirrors, tried in the order they're given before the artifact")
+	cmd.Flags().Bool("never-contact-source", false, "Only download the artifact from its mirrors")
+	cmd.Flags().String("output", "", "The path to save the data stream to")
+	cmd.Flags().String("auth-dir", "", "The directory that contains the ca.crt, token, username and password files, if any")
+	cmd.Flags().Duration("timeout", 5*time.Minute, "How long a download attempt may take")
+
+	flags := cmd.Flags()
+
+	// Add flags registered by imported packages (e.g. glog and
+	// controller-runtime)
+	flags.AddGoFlagSet(flag.CommandLine)
+}
+
+func runContentFetcher(cmd *cobra.Command, args []string) {
+	logf.SetLogger(zap.New())
+
+	conf := &contentFetcherConfig{
+		Output: getValidStringArg(cmd, "output"),
+	}
+	conf.URL, _ = cmd.Flags().GetString("url")
+	conf.Checksum, _ = cmd.Flags().GetString("checksum")
+	conf.Artifact, _ = cmd.Flags().GetString("artifact")
+	conf.ArtifactFile, _ = cmd.Flags().GetString("artifact-file")
+	conf.ArtifactMirrors, _ = cmd.Flags().GetStringArray("artifact-mirror")
+	conf.NeverContactSource, _ = cmd.Flags().GetBool("never-contact-source")
+	conf.AuthDir, _ = cmd.Flags().GetString("auth-dir")
+	conf.Timeout, _ = cmd.Flags().GetDuration("timeout")
+
+	if err := fetchContent(conf); err != nil {
+		cmdLog.Error(err, "Couldn't fetch the content", "url", conf.URL, "artifact", conf.Artifact)
+		os.Exit(1)
+	}
+	cmdLog.Info("Fetched the content", "url", conf.URL, "artifact", conf.Artifact, "output", conf.Output)
+}
+
+// fetchContent downloads the content to the output file, which is only
+// written once the content matches the checksum
+func fetchContent(conf *contentFetcherConfig) error {
+	if conf.Artifact != "" {
+		return fetchArtifact(conf)
+	}
+	contentURL, err := url.Parse(conf.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if contentURL.Scheme != "https" {
+		return fmt.Errorf("the content can only be downloaded over HTTPS")
+	}
+	digest, err := utils.ParseContentChecksum(conf.Checksum)
+	if err != nil {
+		return err
+	}
+	client, err := getContentFetcherClient(conf)
+	if err != nil {
+		return err
+	}
+
+	return saveContent(conf.Output, digest, func(out io.Writer) error {
+		cmdLog.Info("Downloading the content", "url", conf.URL)
+		return downloadContent(client, conf, out)
+	})
+}
+
+// saveContent downloads the content with the download function, retrying
+// on errors that aren't permanent, and only writes the output file once
+// the content matches the digest
+func saveContent(output string, digest []byte, download func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+
+	return backoff.Retry(func() error {
+		tmp, err := os.CreateTemp(filepath.Dir(output), ".content-")
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		hasher := sha256.New()
+		if err := download(io.MultiWriter(tmp, hasher)); err != nil {
+			cmdLog.Error(err, "Failed to download the content")
+			return err
+		}
+		if sum := hasher.Sum(nil); !bytes.Equal(sum, digest) {
+			return backoff.Permanent(fmt.Errorf("the content doesn't match the checksum sha256:%x, its checksum is sha256:%x",
+				digest, sum))
+		}
+		if err := tmp.Close(); err != nil {
+			return backoff.Permanent(err)
+		}
+		return backoff.Permanent(os.Rename(tmp.Name(), output))
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+}
+
+func downloadContent(client *http.Client, conf *contentFetcherConfig, out io.Writer) error {
+	req, err := http.NewRequest(http.MethodGet, conf.URL, nil)
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	if token := readAuthFile(conf, "token"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if username := readAuthFile(conf, "username"); username != "" {
+		req.SetBasicAuth(username, readAuthFile(conf, "password"))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return err
+	}
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+func checkResponseStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	err := fmt.Errorf("the server responded with %s", resp.Status)
+	// Retrying won't help if the URL or the credentials are wrong
+	if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+		return backoff.Permanent(err)
+	}
+	return err
+}
+
+func getContentFetcherClient(conf *contentFetcherConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca := readAuthFile(conf, "ca.crt"); ca != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(ca)) {
+			return nil, fmt.Errorf("the ca.crt of the content secret doesn't contain any PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: conf.Timeout}, nil
+}
+
+// readAuthFile returns the trimmed contents of a key of the mounted Secret,
+// or an empty string if the key isn't set
+func readAuthFile(conf *contentFetcherConfig, name string) string {
+	if conf.AuthDir == "" {
+		return ""
+	}
+	// #nosec G304 -- the directory is the mounted Secret of the bundle
+	contents, err := os.ReadFile(filepath.Join(conf.AuthDir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
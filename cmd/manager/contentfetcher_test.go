@@ -0,0 +1,106 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Contentfetcher", func() {
+	const content = "<ds:data-stream-collection/>"
+
+	var (
+		server        *httptest.Server
+		dir           string
+		conf          *contentFetcherConfig
+		authorization string
+	)
+
+	writeAuthFile := func(name, contents string) {
+		Expect(os.WriteFile(filepath.Join(conf.AuthDir, name), []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			if r.URL.Path != "/ssg-ocp4-ds.xml" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, content)
+		}))
+
+		var err error
+		dir, err = os.MkdirTemp("", "contentfetcher")
+		Expect(err).To(BeNil())
+		authDir := filepath.Join(dir, "auth")
+		Expect(os.Mkdir(authDir, 0700)).To(Succeed())
+
+		conf = &contentFetcherConfig{
+			URL:      server.URL + "/ssg-ocp4-ds.xml",
+			Checksum: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content))),
+			Output:   filepath.Join(dir, "content", "ssg-ocp4-ds.xml"),
+			AuthDir:  authDir,
+			Timeout:  10 * time.Second,
+		}
+		writeAuthFile("ca.crt", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("downloads the content that matches the checksum", func() {
+		writeAuthFile("token", "my-token\n")
+
+		Expect(fetchContent(conf)).To(Succeed())
+		contents, err := os.ReadFile(conf.Output)
+		Expect(err).To(BeNil())
+		Expect(string(contents)).To(Equal(content))
+		Expect(authorization).To(Equal("Bearer my-token"))
+	})
+
+	It("uses basic authentication without a token", func() {
+		writeAuthFile("username", "user")
+		writeAuthFile("password", "secret")
+
+		Expect(fetchContent(conf)).To(Succeed())
+		Expect(authorization).To(HavePrefix("Basic "))
+	})
+
+	It("doesn't save content that doesn't match the checksum", func() {
+		conf.Checksum = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("something else")))
+
+		err := fetchContent(conf)
+		Expect(err).To(MatchError(ContainSubstring("doesn't match the checksum")))
+		Expect(conf.Output).ToNot(BeAnExistingFile())
+	})
+
+	It("fails without retrying if the content doesn't exist", func() {
+		conf.URL = server.URL + "/missing.xml"
+
+		err := fetchContent(conf)
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+
+	It("only downloads over HTTPS", func() {
+		conf.URL = "http://example.com/ssg-ocp4-ds.xml"
+
+		Expect(fetchContent(conf)).ToNot(Succeed())
+	})
+
+	It("rejects invalid checksums", func() {
+		conf.Checksum = "md5:1234"
+
+		Expect(fetchContent(conf)).To(MatchError(ContainSubstring("format")))
+	})
+})
@@ -0,0 +1,200 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// evaluateCustomRules evaluates the assertions of the custom rules against
+// the resources they check. Rules that can't be evaluated have an ERROR
+// result, so that one broken rule doesn't fail the whole scan.
+func evaluateCustomRules(ctx context.Context, streamDispatcher streamerDispatcherFn, rfClients resourceFetcherClients, namespace string, names []string) []utils.CustomRuleResult {
+	results := make([]utils.CustomRuleResult, 0, len(names))
+	for _, name := range names {
+		customRule := &compv1alpha1.CustomRule{}
+		err := rfClients.client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, customRule)
+		if err != nil {
+			results = append(results, utils.CustomRuleResult{
+				Name:     name,
+				Status:   compv1alpha1.CheckResultError,
+				Severity: compv1alpha1.CheckResultSeverityUnknown,
+				Message:  fmt.Sprintf("could not get the custom rule: %v", err),
+			})
+			continue
+		}
+		results = append(results, evaluateCustomRule(ctx, streamDispatcher, rfClients, customRule))
+	}
+	return results
+}
+
+func evaluateCustomRule(ctx context.Context, streamDispatcher streamerDispatcherFn, rfClients resourceFetcherClients, customRule *compv1alpha1.CustomRule) utils.CustomRuleResult {
+	result := utils.CustomRuleResult{
+		Name:         customRule.Name,
+		Severity:     customRule.Spec.Severity,
+		Description:  customRule.Spec.Title,
+		Instructions: customRule.Spec.Instructions,
+	}
+	if result.Severity == "" {
+		result.Severity = compv1alpha1.CheckResultSeverityMedium
+	}
+	if customRule.Spec.Description != "" {
+		result.Description += "\n" + customRule.Spec.Description
+	}
+
+	body, err := fetchCustomRuleResource(ctx, streamDispatcher(customRule.Spec.ResourcePath), rfClients)
+	if err != nil {
+		result.Status = compv1alpha1.CheckResultError
+		result.Message = fmt.Sprintf("could not fetch %s: %v", customRule.Spec.ResourcePath, err)
+		return result
+	}
+
+	var passed bool
+	if customRule.Spec.AssertionLanguage == compv1alpha1.CustomRuleAssertionLanguageCEL {
+		passed, err = evaluateCELAssertion(ctx, body, customRule.Spec.Assertion)
+	} else {
+		passed, err = evaluateAssertion(ctx, body, customRule.Spec.Assertion)
+	}
+	if err != nil {
+		result.Status = compv1alpha1.CheckResultError
+		result.Message = err.Error()
+	} else if passed {
+		result.Status = compv1alpha1.CheckResultPass
+	} else {
+		result.Status = compv1alpha1.CheckResultFail
+	}
+	return result
+}
+
+// fetchCustomRuleResource returns the resource a custom rule checks, or nil
+// if it doesn't exist
+func fetchCustomRuleResource(ctx context.Context, streamer resourceStreamer, rfClients resourceFetcherClients) ([]byte, error) {
+	stream, err := streamer.Stream(ctx, rfClients)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// evaluateAssertion evaluates a jq expression against a JSON document, which
+// is null if it's empty. The expression needs to evaluate to a boolean.
+func evaluateAssertion(ctx context.Context, body []byte, assertion string) (bool, error) {
+	query, err := gojq.Parse(assertion)
+	if err != nil {
+		return false, fmt.Errorf("could not parse the assertion '%s': %w", assertion, err)
+	}
+	var obj interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &obj); err != nil {
+			return false, fmt.Errorf("could not decode the resource: %w", err)
+		}
+	}
+
+	v, ok := query.RunWithContext(ctx, obj).Next()
+	if !ok {
+		return false, fmt.Errorf("the assertion '%s' returned no value", assertion)
+	}
+	switch val := v.(type) {
+	case error:
+		return false, fmt.Errorf("could not evaluate the assertion '%s': %w", assertion, val)
+	case bool:
+		return val, nil
+	default:
+		return false, fmt.Errorf("the assertion '%s' returned %v instead of a boolean", assertion, val)
+	}
+}
+
+// evaluateCELAssertion evaluates a CEL expression with a JSON document as the
+// resource variable, which is null if the document is empty. The expression
+// needs to evaluate to a boolean.
+func evaluateCELAssertion(ctx context.Context, body []byte, assertion string) (bool, error) {
+	program, err := utils.CompileCELAssertion(assertion)
+	if err != nil {
+		return false, fmt.Errorf("could not parse the assertion '%s': %w", assertion, err)
+	}
+
+	var obj interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &obj); err != nil {
+			return false, fmt.Errorf("could not decode the resource: %w", err)
+		}
+	}
+
+	val, _, err := program.ContextEval(ctx, map[string]interface{}{utils.CELResourceVariable: obj})
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate the assertion '%s': %w", assertion, err)
+	}
+	passed, ok := val.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("the assertion '%s' returned %v instead of a boolean", assertion, val.Value())
+	}
+	return passed, nil
+}
+
+// saveCustomRuleResults writes the results of the custom rules for the
+// result collector to upload
+func saveCustomRuleResults(results []utils.CustomRuleResult, outputFile string) error {
+	out, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputFile, out, 0600)
+}
+
+// parseCustomRuleNames parses the comma-separated names of the custom rules
+// of a scan
+func parseCustomRuleNames(in string) []string {
+	var names []string
+	for _, name := range strings.Split(in, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseCustomRuleResults turns the results of the custom rules uploaded by
+// the result collector into the results of the scan
+func parseCustomRuleResults(scanName, namespace, in string) ([]*utils.ParseResult, error) {
+	var customResults []utils.CustomRuleResult
+	if err := json.Unmarshal([]byte(in), &customResults); err != nil {
+		return nil, err
+	}
+
+	results := make([]*utils.ParseResult, 0, len(customResults))
+	for _, res := range customResults {
+		checkResult := &compv1alpha1.ComplianceCheckResult{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s", scanName, res.Name),
+				Namespace: namespace,
+				Labels: map[string]string{
+					compv1alpha1.CustomRuleLabel: res.Name,
+				},
+			},
+			ID:           res.Name,
+			Status:       res.Status,
+			Severity:     res.Severity,
+			Description:  res.Description,
+			Instructions: res.Instructions,
+		}
+		if res.Message != "" {
+			checkResult.Warnings = []string{res.Message}
+		}
+		results = append(results, &utils.ParseResult{Id: res.Name, CheckResult: checkResult})
+	}
+	return results, nil
+}
@@ -0,0 +1,179 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+type staticStreamer struct {
+	body string
+}
+
+func (ss *staticStreamer) Stream(_ context.Context, _ resourceFetcherClients) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(ss.body)), nil
+}
+
+var _ = Describe("Testing custom rules", func() {
+	const apiServer = `{"spec": {"encryption": {"type": "aescbc"}}}`
+
+	Context("Evaluating assertions", func() {
+		It("passes and fails with the value of the assertion", func() {
+			Expect(evaluateAssertion(context.TODO(), []byte(apiServer), `.spec.encryption.type == "aescbc"`)).To(BeTrue())
+			Expect(evaluateAssertion(context.TODO(), []byte(apiServer), `.spec.encryption.type == "identity"`)).To(BeFalse())
+		})
+
+		It("evaluates the assertion against null for missing resources", func() {
+			Expect(evaluateAssertion(context.TODO(), nil, `. == null`)).To(BeTrue())
+		})
+
+		It("requires the assertion to be a boolean", func() {
+			_, err := evaluateAssertion(context.TODO(), []byte(apiServer), `.spec.encryption.type`)
+			Expect(err).To(MatchError(ContainSubstring("instead of a boolean")))
+		})
+
+		It("reports invalid assertions", func() {
+			_, err := evaluateAssertion(context.TODO(), []byte(apiServer), `.spec[`)
+			Expect(err).To(MatchError(ContainSubstring("could not parse the assertion")))
+		})
+	})
+
+	Context("Evaluating CEL assertions", func() {
+		It("passes and fails with the value of the assertion", func() {
+			Expect(evaluateCELAssertion(context.TODO(), []byte(apiServer), `resource.spec.encryption.type in ["aescbc", "aesgcm"]`)).To(BeTrue())
+			Expect(evaluateCELAssertion(context.TODO(), []byte(apiServer), `resource.spec.encryption.type == "identity"`)).To(BeFalse())
+		})
+
+		It("evaluates the assertion against null for missing resources", func() {
+			Expect(evaluateCELAssertion(context.TODO(), nil, `resource == null`)).To(BeTrue())
+		})
+
+		It("requires the assertion to be a boolean", func() {
+			_, err := evaluateCELAssertion(context.TODO(), []byte(apiServer), `resource.spec.encryption.type`)
+			Expect(err).To(MatchError(ContainSubstring("instead of a boolean")))
+			_, err = evaluateCELAssertion(context.TODO(), []byte(apiServer), `"aescbc"`)
+			Expect(err).To(MatchError(ContainSubstring("instead of a boolean")))
+		})
+
+		It("stops evaluating assertions that are too costly or cancelled", func() {
+			items := make([]int, 500)
+			body, err := json.Marshal(map[string]interface{}{"items": items})
+			Expect(err).To(BeNil())
+			costly := `resource.items.all(a, resource.items.all(b, resource.items.all(c, a == b)))`
+			_, err = evaluateCELAssertion(context.TODO(), body, costly)
+			Expect(err).To(MatchError(ContainSubstring("cost limit exceeded")))
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+			_, err = evaluateCELAssertion(ctx, body, `resource.items.all(a, a == 0)`)
+			Expect(err).To(MatchError(ContainSubstring("operation interrupted")))
+		})
+
+		It("reports invalid assertions and missing fields", func() {
+			_, err := evaluateCELAssertion(context.TODO(), []byte(apiServer), `resource.spec[`)
+			Expect(err).To(MatchError(ContainSubstring("could not parse the assertion")))
+			_, err = evaluateCELAssertion(context.TODO(), []byte(apiServer), `resource.status.ready`)
+			Expect(err).To(MatchError(ContainSubstring("could not evaluate the assertion")))
+		})
+	})
+
+	Context("Evaluating custom rules", func() {
+		var rfClients resourceFetcherClients
+
+		BeforeEach(func() {
+			customRule := &compv1alpha1.CustomRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "etcd-encrypted", Namespace: "openshift-compliance"},
+				Spec: compv1alpha1.CustomRuleSpec{
+					Title:        "Etcd is encrypted",
+					Description:  "Our policy requires encrypting etcd.",
+					Instructions: "Set spec.encryption.type of the APIServer.",
+					Severity:     compv1alpha1.CheckResultSeverityHigh,
+					ResourcePath: "/apis/config.openshift.io/v1/apiservers/cluster",
+					Assertion:    `.spec.encryption.type == "aescbc" or .spec.encryption.type == "aesgcm"`,
+				},
+			}
+			scheme := getScheme()
+			rfClients = resourceFetcherClients{
+				client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(customRule).Build(),
+				scheme: scheme,
+			}
+		})
+
+		It("has a result for each rule", func() {
+			dispatcher := func(uri string) resourceStreamer {
+				return &staticStreamer{body: apiServer}
+			}
+			results := evaluateCustomRules(context.TODO(), dispatcher, rfClients, "openshift-compliance",
+				[]string{"etcd-encrypted", "missing"})
+			Expect(results).To(HaveLen(2))
+			Expect(results[0]).To(Equal(utils.CustomRuleResult{
+				Name:         "etcd-encrypted",
+				Status:       compv1alpha1.CheckResultPass,
+				Severity:     compv1alpha1.CheckResultSeverityHigh,
+				Description:  "Etcd is encrypted\nOur policy requires encrypting etcd.",
+				Instructions: "Set spec.encryption.type of the APIServer.",
+			}))
+			Expect(results[1].Status).To(Equal(compv1alpha1.CheckResultError))
+			Expect(results[1].Message).To(ContainSubstring("could not get the custom rule"))
+		})
+
+		It("evaluates missing resources as null", func() {
+			dispatcher := func(uri string) resourceStreamer {
+				return &notFoundFetcher{}
+			}
+			results := evaluateCustomRules(context.TODO(), dispatcher, rfClients, "openshift-compliance",
+				[]string{"etcd-encrypted"})
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Status).To(Equal(compv1alpha1.CheckResultFail))
+		})
+
+		It("evaluates the assertions written in CEL", func() {
+			customRule := &compv1alpha1.CustomRule{}
+			key := types.NamespacedName{Name: "etcd-encrypted", Namespace: "openshift-compliance"}
+			Expect(rfClients.client.Get(context.TODO(), key, customRule)).To(Succeed())
+			customRule.Spec.AssertionLanguage = compv1alpha1.CustomRuleAssertionLanguageCEL
+			customRule.Spec.Assertion = `has(resource.spec.encryption) && resource.spec.encryption.type == "aesgcm"`
+			Expect(rfClients.client.Update(context.TODO(), customRule)).To(Succeed())
+
+			dispatcher := func(uri string) resourceStreamer {
+				return &staticStreamer{body: apiServer}
+			}
+			results := evaluateCustomRules(context.TODO(), dispatcher, rfClients, "openshift-compliance",
+				[]string{"etcd-encrypted"})
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Status).To(Equal(compv1alpha1.CheckResultFail))
+			Expect(results[0].Message).To(BeEmpty())
+		})
+	})
+
+	Context("Parsing the results of custom rules", func() {
+		It("creates the check results of the scan", func() {
+			results, err := parseCustomRuleResults("ocp4-custom", "openshift-compliance",
+				`[{"name": "etcd-encrypted", "status": "ERROR", "severity": "high", "description": "Etcd is encrypted", "message": "could not fetch"}]`)
+			Expect(err).To(BeNil())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].Id).To(Equal("etcd-encrypted"))
+			checkResult := results[0].CheckResult
+			Expect(checkResult.Name).To(Equal("ocp4-custom-etcd-encrypted"))
+			Expect(checkResult.Labels).To(HaveKeyWithValue(compv1alpha1.CustomRuleLabel, "etcd-encrypted"))
+			Expect(checkResult.Status).To(Equal(compv1alpha1.CheckResultError))
+			Expect(checkResult.Severity).To(Equal(compv1alpha1.CheckResultSeverityHigh))
+			Expect(checkResult.Warnings).To(Equal([]string{"could not fetch"}))
+		})
+
+		It("parses the names of the custom rules", func() {
+			Expect(parseCustomRuleNames("etcd-encrypted, no-kubeadmin,")).To(Equal([]string{"etcd-encrypted", "no-kubeadmin"}))
+			Expect(parseCustomRuleNames("")).To(BeNil())
+		})
+	})
+})
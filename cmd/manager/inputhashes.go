@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// getContentDigest hashes the profile and the files that make up the content
+// of a scan. Every rule hash includes it, so that changing the content or the
+// tailoring re-evaluates all the rules.
+func getContentDigest(profile string, files ...string) (string, error) {
+	hasher := sha256.New()
+	io.WriteString(hasher, profile)
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		f, err := os.Open(filepath.Clean(file))
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(hasher, f)
+		// #nosec
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", hasher.Sum(nil)), nil
+}
+
+// getRuleInputHashes hashes the fetched objects each rule needs, keyed by
+// the rule ID
+func getRuleInputHashes(contentDigest string, rules []ruleResourcePaths, found map[string][]byte) map[string]string {
+	hashes := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		dumpPaths := make([]string, 0, len(rule.paths))
+		for _, rpath := range rule.paths {
+			dumpPaths = append(dumpPaths, rpath.DumpPath)
+		}
+		sort.Strings(dumpPaths)
+
+		hasher := sha256.New()
+		io.WriteString(hasher, contentDigest)
+		for _, dumpPath := range dumpPaths {
+			// Objects that couldn't be fetched are hashed as missing
+			io.WriteString(hasher, dumpPath)
+			hasher.Write([]byte{0})
+			hasher.Write(found[dumpPath])
+			hasher.Write([]byte{0})
+		}
+		hashes[rule.ruleID] = fmt.Sprintf("%x", hasher.Sum(nil))
+	}
+	return hashes
+}
+
+// getChangedRules returns the rules whose hash differs from the previous
+// run. If no rule changed, none is returned and the scanner isn't run.
+func getChangedRules(previous, current map[string]string) []string {
+	ruleIDs := make([]string, 0, len(current))
+	for ruleID := range current {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	changed := []string{}
+	for _, ruleID := range ruleIDs {
+		if previous[ruleID] != current[ruleID] {
+			changed = append(changed, ruleID)
+		}
+	}
+	return changed
+}
+
+// parseInputHashes decodes the hashes stored by a previous run
+func parseInputHashes(in []byte) (map[string]string, error) {
+	hashes := map[string]string{}
+	if err := json.Unmarshal(in, &hashes); err != nil {
+		return nil, fmt.Errorf("couldn't parse the input hashes: %w", err)
+	}
+	return hashes, nil
+}
+
+// readInputHashes reads the hashes stored by a previous run. It returns nil
+// if there was no previous run.
+func readInputHashes(filename string) (map[string]string, error) {
+	contents, err := os.ReadFile(filepath.Clean(filename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return parseInputHashes(contents)
+}
+
+func (c *scapContentDataStream) SaveInputHashes(contentDigest, previousFile, hashesFile, changedRulesFile string) error {
+	hashes := getRuleInputHashes(contentDigest, c.rules, c.found)
+	encoded, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(hashesFile, encoded, 0600); err != nil {
+		return err
+	}
+
+	previous, err := readInputHashes(previousFile)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		LOG("No input hashes from a previous run found, evaluating all the rules")
+		return nil
+	}
+
+	changed := getChangedRules(previous, hashes)
+	if len(changed) == 0 {
+		// An empty file tells the scanner that there's nothing to evaluate
+		LOG("No rule inputs changed since the previous run, keeping all of its results")
+		return os.WriteFile(changedRulesFile, []byte{}, 0600)
+	}
+	LOG("Evaluating %d out of %d rules whose inputs changed since the previous run", len(changed), len(hashes))
+	return os.WriteFile(changedRulesFile, []byte(strings.Join(changed, "\n")+"\n"), 0600)
+}
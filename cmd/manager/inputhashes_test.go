@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing the input hashes of incremental scans", func() {
+	var rules []ruleResourcePaths
+	var found map[string][]byte
+
+	BeforeEach(func() {
+		rules = []ruleResourcePaths{
+			{
+				ruleID: "xccdf_org.ssgproject.content_rule_oauth_or_oauthclient_token_maxage",
+				paths: []utils.ResourcePath{
+					{
+						ObjPath:  "/apis/config.openshift.io/v1/oauths/cluster",
+						DumpPath: "/apis/config.openshift.io/v1/oauths/cluster",
+					},
+				},
+			},
+			{
+				ruleID: "xccdf_org.ssgproject.content_rule_api_server_tls_cipher_suites",
+				paths: []utils.ResourcePath{
+					{
+						ObjPath:  "/api/v1/namespaces/openshift-kube-apiserver/configmaps/config",
+						DumpPath: "/api/v1/namespaces/openshift-kube-apiserver/configmaps/config",
+					},
+				},
+			},
+			{
+				ruleID: "xccdf_org.ssgproject.content_rule_general_network_separation",
+			},
+		}
+		found = map[string][]byte{
+			"/apis/config.openshift.io/v1/oauths/cluster":                   []byte(`{"spec": {}}`),
+			"/api/v1/namespaces/openshift-kube-apiserver/configmaps/config": []byte(`{"data": {}}`),
+		}
+	})
+
+	It("hashes every rule", func() {
+		hashes := getRuleInputHashes("digest", rules, found)
+		Expect(hashes).To(HaveLen(3))
+	})
+
+	It("only reports the rules whose inputs changed", func() {
+		previous := getRuleInputHashes("digest", rules, found)
+		found["/apis/config.openshift.io/v1/oauths/cluster"] = []byte(`{"spec": {"tokenConfig": {}}}`)
+		current := getRuleInputHashes("digest", rules, found)
+
+		changed := getChangedRules(previous, current)
+		Expect(changed).To(Equal([]string{"xccdf_org.ssgproject.content_rule_oauth_or_oauthclient_token_maxage"}))
+	})
+
+	It("reports all the rules if the content changed", func() {
+		previous := getRuleInputHashes("digest", rules, found)
+		current := getRuleInputHashes("other-digest", rules, found)
+
+		changed := getChangedRules(previous, current)
+		Expect(changed).To(HaveLen(3))
+	})
+
+	It("reports no rule if nothing changed", func() {
+		previous := getRuleInputHashes("digest", rules, found)
+		current := getRuleInputHashes("digest", rules, found)
+
+		changed := getChangedRules(previous, current)
+		Expect(changed).To(BeEmpty())
+	})
+})
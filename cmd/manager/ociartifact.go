@@ -0,0 +1,355 @@
+package manager
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// The annotation oras sets to the file name of the layers it pushes
+	ociTitleAnnotation = "org.opencontainers.image.title"
+	// Manifests are small, so anything bigger isn't read
+	maxManifestSize = 4 * 1024 * 1024
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType    string          `json:"mediaType"`
+	ArtifactType string          `json:"artifactType,omitempty"`
+	Config       ociDescriptor   `json:"config"`
+	Layers       []ociDescriptor `json:"layers"`
+}
+
+// registryClient pulls from the OCI distribution API of a registry,
+// authenticating as the registry asks for when it first refuses a request
+type registryClient struct {
+	client     *http.Client
+	conf       *contentFetcherConfig
+	registry   string
+	repository string
+	token      string
+	basicAuth  bool
+}
+
+// fetchArtifact downloads the layer of the artifact that contains the
+// content, trying the mirrors of the artifact before the artifact itself.
+// Both the manifest and the layer are verified against their digests.
+func fetchArtifact(conf *contentFetcherConfig) error {
+	artifacts := append([]string{}, conf.ArtifactMirrors...)
+	if !conf.NeverContactSource || len(artifacts) == 0 {
+		artifacts = append(artifacts, conf.Artifact)
+	}
+	if len(artifacts) == 1 {
+		return fetchArtifactFrom(conf, artifacts[0])
+	}
+	var errs []string
+	for _, artifact := range artifacts {
+		err := fetchArtifactFrom(conf, artifact)
+		if err == nil {
+			return nil
+		}
+		cmdLog.Error(err, "Couldn't fetch the artifact", "artifact", artifact)
+		errs = append(errs, fmt.Sprintf("%s: %s", artifact, err))
+	}
+	return fmt.Errorf("couldn't fetch the artifact from any of its mirrors: %s", strings.Join(errs, "; "))
+}
+
+func fetchArtifactFrom(conf *contentFetcherConfig, artifact string) error {
+	ref, err := reference.Parse(artifact)
+	if err != nil {
+		return fmt.Errorf("invalid artifact reference: %w", err)
+	}
+	manifestDigest, err := utils.ParseContentChecksum(ref.ID)
+	if err != nil {
+		return fmt.Errorf("the artifact needs to be referred to by its sha256 digest: %w", err)
+	}
+	client, err := getContentFetcherClient(conf)
+	if err != nil {
+		return err
+	}
+	ref = ref.DockerClientDefaults().AsV2()
+	rc := &registryClient{
+		client:     client,
+		conf:       conf,
+		registry:   ref.Registry,
+		repository: ref.RepositoryName(),
+		token:      readAuthFile(conf, "token"),
+	}
+
+	var manifest *ociManifest
+	err = backoff.Retry(func() error {
+		var err error
+		manifest, err = rc.getManifest(ref.ID, manifestDigest)
+		return err
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+	if err != nil {
+		return err
+	}
+	layer, err := findContentLayer(manifest, conf.ArtifactFile)
+	if err != nil {
+		return err
+	}
+	layerDigest, err := utils.ParseContentChecksum(layer.Digest)
+	if err != nil {
+		return fmt.Errorf("the layer %s of the artifact has an unsupported digest: %w", conf.ArtifactFile, err)
+	}
+
+	return saveContent(conf.Output, layerDigest, func(out io.Writer) error {
+		cmdLog.Info("Downloading the content", "artifact", artifact, "layer", layer.Digest)
+		resp, err := rc.get("/blobs/"+layer.Digest, layer.MediaType)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = io.Copy(out, resp.Body)
+		return err
+	})
+}
+
+func (rc *registryClient) getManifest(ref string, digest []byte) (*ociManifest, error) {
+	resp, err := rc.get("/manifests/"+ref, ociManifestMediaType)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(body); !bytes.Equal(sum[:], digest) {
+		return nil, backoff.Permanent(fmt.Errorf("the manifest of the artifact doesn't match its digest %s", ref))
+	}
+
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(body, manifest); err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("couldn't parse the manifest of the artifact: %w", err))
+	}
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType = resp.Header.Get("Content-Type")
+	}
+	if mediaType != ociManifestMediaType {
+		return nil, backoff.Permanent(fmt.Errorf("the artifact has the unsupported manifest media type %q, expected %s",
+			mediaType, ociManifestMediaType))
+	}
+	return manifest, nil
+}
+
+// findContentLayer returns the layer of the artifact with the given title,
+// which needs to have an XML media type
+func findContentLayer(manifest *ociManifest, title string) (*ociDescriptor, error) {
+	var titles []string
+	for i := range manifest.Layers {
+		layer := &manifest.Layers[i]
+		layerTitle := layer.Annotations[ociTitleAnnotation]
+		if layerTitle != title {
+			titles = append(titles, layerTitle)
+			continue
+		}
+		if !isXMLMediaType(layer.MediaType) {
+			return nil, fmt.Errorf("the layer %s of the artifact has the media type %q instead of an XML media type, "+
+				"e.g. push it with 'oras push <artifact> %s:application/xml'", title, layer.MediaType, title)
+		}
+		return layer, nil
+	}
+	return nil, fmt.Errorf("the artifact has no layer titled %s, its layers are titled %s", title, strings.Join(titles, ", "))
+}
+
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// get requests a path of the repository, authenticating once if the
+// registry asks for it
+func (rc *registryClient) get(path, accept string) (*http.Response, error) {
+	resp, err := rc.do(path, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && rc.token == "" && !rc.basicAuth {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := rc.authenticate(challenge); err != nil {
+			return nil, err
+		}
+		resp, err = rc.do(path, accept)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := checkResponseStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (rc *registryClient) do(path, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://"+rc.registry+"/v2/"+rc.repository+path, nil)
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+	req.Header.Set("Accept", accept)
+	if rc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+rc.token)
+	} else if rc.basicAuth {
+		username, password := rc.credentials()
+		req.SetBasicAuth(username, password)
+	}
+	return rc.client.Do(req)
+}
+
+// authenticate answers the challenge of the registry, either by using basic
+// authentication or by getting a bearer token from the token service
+func (rc *registryClient) authenticate(challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	username, password := rc.credentials()
+	switch scheme {
+	case "basic":
+		if username == "" {
+			return backoff.Permanent(fmt.Errorf("the registry %s requires credentials", rc.registry))
+		}
+		rc.basicAuth = true
+		return nil
+	case "bearer":
+	default:
+		return backoff.Permanent(fmt.Errorf("the registry %s asks for the unsupported authentication %q", rc.registry, challenge))
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return backoff.Permanent(fmt.Errorf("the registry %s asks for a token without a valid realm", rc.registry))
+	}
+	query := tokenURL.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	scope, ok := params["scope"]
+	if !ok {
+		scope = "repository:" + rc.repository + ":pull"
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponseStatus(resp); err != nil {
+		return err
+	}
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return fmt.Errorf("couldn't parse the token of the registry %s: %w", rc.registry, err)
+	}
+	rc.token = tokenResponse.Token
+	if rc.token == "" {
+		rc.token = tokenResponse.AccessToken
+	}
+	if rc.token == "" {
+		return backoff.Permanent(fmt.Errorf("the registry %s didn't issue a token", rc.registry))
+	}
+	return nil
+}
+
+// credentials returns the username and password from the mounted Secret,
+// either from its username and password keys, or from the entry for the
+// registry in its .dockerconfigjson key
+func (rc *registryClient) credentials() (string, string) {
+	if username := readAuthFile(rc.conf, "username"); username != "" {
+		return username, readAuthFile(rc.conf, "password")
+	}
+	dockerConfig := readAuthFile(rc.conf, ".dockerconfigjson")
+	if dockerConfig == "" {
+		return "", ""
+	}
+	parsed := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.Unmarshal([]byte(dockerConfig), &parsed); err != nil {
+		cmdLog.Error(err, "Couldn't parse the .dockerconfigjson of the content secret")
+		return "", ""
+	}
+	registries := []string{rc.registry, "https://" + rc.registry}
+	if reference.IsRegistryDockerHub(rc.registry) {
+		registries = append(registries, reference.DockerDefaultRegistry, "https://index.docker.io/v1/")
+	}
+	for _, registry := range registries {
+		entry, ok := parsed.Auths[registry]
+		if !ok {
+			continue
+		}
+		if entry.Username != "" {
+			return entry.Username, entry.Password
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			continue
+		}
+		if username, password, found := strings.Cut(string(decoded), ":"); found {
+			return username, password
+		}
+	}
+	return "", ""
+}
+
+// parseChallenge parses a WWW-Authenticate header into its lowercased
+// scheme and its parameters
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for {
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(value, ",")
+			params[key] = strings.TrimSpace(value)
+		}
+	}
+	return strings.ToLower(scheme), params
+}
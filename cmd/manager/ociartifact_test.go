@@ -0,0 +1,173 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fetching OCI artifacts", func() {
+	const content = "<ds:data-stream-collection/>"
+
+	var (
+		server         *httptest.Server
+		dir            string
+		conf           *contentFetcherConfig
+		layerMediaType string
+		manifestDigest string
+		manifestSuffix string
+		tokenRequests  int
+	)
+
+	contentDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+
+	getManifest := func() []byte {
+		manifest, err := json.Marshal(&ociManifest{
+			MediaType:    ociManifestMediaType,
+			ArtifactType: "application/vnd.unknown.artifact.v1",
+			Config: ociDescriptor{
+				MediaType: "application/vnd.oci.empty.v1+json",
+				Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("{}"))),
+				Size:      2,
+			},
+			Layers: []ociDescriptor{
+				{
+					MediaType:   layerMediaType,
+					Digest:      contentDigest,
+					Size:        int64(len(content)),
+					Annotations: map[string]string{ociTitleAnnotation: "ssg-ocp4-ds.xml"},
+				},
+			},
+		})
+		Expect(err).To(BeNil())
+		return manifest
+	}
+
+	BeforeEach(func() {
+		layerMediaType = "application/xml"
+		manifestSuffix = ""
+		tokenRequests = 0
+
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				tokenRequests++
+				if r.URL.Query().Get("scope") != "repository:compliance/content:pull" {
+					http.Error(w, "wrong scope", http.StatusForbidden)
+					return
+				}
+				fmt.Fprint(w, `{"token": "registry-token"}`)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("WWW-Authenticate",
+					fmt.Sprintf(`Bearer realm="https://%s/token",service="registry"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch r.URL.Path {
+			case "/v2/compliance/content/manifests/" + manifestDigest:
+				w.Header().Set("Content-Type", ociManifestMediaType)
+				w.Write(append(getManifest(), manifestSuffix...))
+			case "/v2/compliance/content/blobs/" + contentDigest:
+				fmt.Fprint(w, content)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		manifestDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(getManifest()))
+
+		var err error
+		dir, err = os.MkdirTemp("", "ociartifact")
+		Expect(err).To(BeNil())
+		authDir := filepath.Join(dir, "auth")
+		Expect(os.Mkdir(authDir, 0700)).To(Succeed())
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(filepath.Join(authDir, "ca.crt"), ca, 0600)).To(Succeed())
+
+		conf = &contentFetcherConfig{
+			Artifact:     strings.TrimPrefix(server.URL, "https://") + "/compliance/content@" + manifestDigest,
+			ArtifactFile: "ssg-ocp4-ds.xml",
+			Output:       filepath.Join(dir, "content", "ssg-ocp4-ds.xml"),
+			AuthDir:      authDir,
+			Timeout:      10 * time.Second,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(dir)
+	})
+
+	It("downloads the content layer with a token from the registry", func() {
+		Expect(fetchContent(conf)).To(Succeed())
+		contents, err := os.ReadFile(conf.Output)
+		Expect(err).To(BeNil())
+		Expect(string(contents)).To(Equal(content))
+		Expect(tokenRequests).To(Equal(1))
+	})
+
+	It("rejects a content layer that isn't XML", func() {
+		layerMediaType = "application/vnd.oci.image.layer.v1.tar"
+		manifestDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(getManifest()))
+		conf.Artifact = strings.TrimPrefix(server.URL, "https://") + "/compliance/content@" + manifestDigest
+
+		Expect(fetchContent(conf)).To(MatchError(ContainSubstring("instead of an XML media type")))
+		Expect(conf.Output).ToNot(BeAnExistingFile())
+	})
+
+	It("rejects a manifest that doesn't match the digest", func() {
+		manifestSuffix = "\n"
+
+		Expect(fetchContent(conf)).To(MatchError(ContainSubstring("doesn't match its digest")))
+		Expect(conf.Output).ToNot(BeAnExistingFile())
+	})
+
+	It("reports a missing content layer", func() {
+		conf.ArtifactFile = "ssg-rhcos4-ds.xml"
+
+		Expect(fetchContent(conf)).To(MatchError(ContainSubstring("no layer titled ssg-rhcos4-ds.xml")))
+	})
+
+	It("falls back to the artifact if its mirrors don't have it", func() {
+		conf.ArtifactMirrors = []string{strings.TrimPrefix(server.URL, "https://") + "/mirror/content@" + manifestDigest}
+
+		Expect(fetchContent(conf)).To(Succeed())
+		Expect(conf.Output).To(BeAnExistingFile())
+	})
+
+	It("only tries the mirrors if the artifact may not be downloaded from its source", func() {
+		conf.ArtifactMirrors = []string{strings.TrimPrefix(server.URL, "https://") + "/mirror/content@" + manifestDigest}
+		conf.NeverContactSource = true
+
+		Expect(fetchContent(conf)).ToNot(Succeed())
+		Expect(conf.Output).ToNot(BeAnExistingFile())
+	})
+
+	It("requires a reference by digest", func() {
+		conf.Artifact = strings.TrimPrefix(server.URL, "https://") + "/compliance/content:latest"
+
+		Expect(fetchContent(conf)).To(MatchError(ContainSubstring("sha256 digest")))
+	})
+})
+
+var _ = Describe("Parsing authentication challenges", func() {
+	It("parses quoted and unquoted parameters", func() {
+		scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service=registry,scope="repository:a/b:pull,push"`)
+		Expect(scheme).To(Equal("bearer"))
+		Expect(params).To(Equal(map[string]string{
+			"realm":   "https://auth.example.com/token",
+			"service": "registry",
+			"scope":   "repository:a/b:pull,push",
+		}))
+	})
+})
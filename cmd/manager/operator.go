@@ -7,6 +7,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	goruntime "runtime"
 	"strings"
@@ -24,7 +25,12 @@ import (
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/complianceremediation"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/compliancesuite"
 	ctrlMetrics "github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/scanrun"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/scansettingbinding"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/tailoredprofile"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
 	"github.com/ComplianceAsCode/compliance-operator/version"
@@ -265,7 +271,9 @@ func RunOperator(cmd *cobra.Command, args []string) {
 		c.NextProtos = []string{"http/1.1"}
 	}
 	webhookServerOptions := webhook.Options{
-		Port:    9443,
+		Port: 9443,
+		// OLM mounts the certificates of the webhooks of the CSV here
+		CertDir: filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
 		TLSOpts: []func(config *tls.Config){disableHTTP2},
 	}
 
@@ -339,6 +347,33 @@ func RunOperator(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// The webhook server can't start without certificates, so the webhooks
+	// are only served when they're deployed, e.g. by OLM
+	if webhookCertsExist(webhookServerOptions) {
+		if err := tailoredprofile.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Error setting up the TailoredProfile webhook")
+			os.Exit(1)
+		}
+		if err := scansettingbinding.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Error setting up the ScanSetting webhook")
+			os.Exit(1)
+		}
+		if err := scanrun.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Error setting up the ScanRun webhook")
+			os.Exit(1)
+		}
+		if err := compliancesuite.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Error setting up the ComplianceSuite webhook")
+			os.Exit(1)
+		}
+		if err := complianceremediation.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Error setting up the ComplianceRemediation webhooks")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("No webhook certificates, not serving the webhooks", "CertDir", webhookServerOptions.CertDir)
+	}
+
 	infra := &configv1.Infrastructure{}
 	if err := kubeClient.RESTClient().Get().RequestURI("/apis/config.openshift.io/v1/infrastructures/cluster").Do(ctx).Into(infra); err != nil {
 		setupLog.Info("Couldn't get Infrastructure. This is not fatal though.")
@@ -397,6 +432,15 @@ func RunOperator(cmd *cobra.Command, args []string) {
 	}
 }
 
+func webhookCertsExist(opts webhook.Options) bool {
+	for _, file := range []string{"tls.crt", "tls.key"} {
+		if _, err := os.Stat(filepath.Join(opts.CertDir, file)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func getValidPlatform(p string) PlatformType {
 	arch := goruntime.GOARCH
 	switch {
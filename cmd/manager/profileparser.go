@@ -1,14 +1,16 @@
 package manager
 
 import (
-	"bufio"
 	"context"
+	"crypto/sha256"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
-	"github.com/antchfx/xmlquery"
 	"github.com/spf13/cobra"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -17,6 +19,8 @@ import (
 
 	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/profileparser"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/version"
 )
 
 var ProfileparserCmd = &cobra.Command{
@@ -32,8 +36,10 @@ func init() {
 
 func defineProfileParserFlags(cmd *cobra.Command) {
 	cmd.Flags().String("ds-path", "/content/ssg-ocp4-ds.xml", "Path to the datastream xml file")
+	cmd.Flags().String("content-dir", "", "Directory that contains the datastream xml files of a bundle with several of them, instead of the ds-path")
 	cmd.Flags().String("name", "", "Name of the ProfileBundle object")
 	cmd.Flags().String("namespace", "", "Namespace of the ProfileBundle object")
+	cmd.Flags().Bool("dry-run-garbage-collection", false, "Only report the objects the content no longer has instead of deleting them")
 
 	flags := cmd.Flags()
 
@@ -49,8 +55,10 @@ func newParserConfig(cmd *cobra.Command) *profileparser.ParserConfig {
 	flags.AddGoFlagSet(flag.CommandLine)
 
 	pcfg.DataStreamPath = getValidStringArg(cmd, "ds-path")
+	pcfg.ContentDir, _ = cmd.Flags().GetString("content-dir")
 	pcfg.ProfileBundleKey.Name = getValidStringArg(cmd, "name")
 	pcfg.ProfileBundleKey.Namespace = getValidStringArg(cmd, "namespace")
+	pcfg.DryRunGarbageCollection, _ = cmd.Flags().GetBool("dry-run-garbage-collection")
 
 	logf.SetLogger(zap.New())
 
@@ -87,13 +95,22 @@ func getProfileBundle(pcfg *profileparser.ParserConfig) (*cmpv1alpha1.ProfileBun
 }
 
 // updateProfileBundleStatus updates the status of the given ProfileBundle. If
-// the given error is nil, the status will be valid, else it'll be invalid
-func updateProfileBundleStatus(pcfg *profileparser.ParserConfig, pb *cmpv1alpha1.ProfileBundle, err error) {
+// the given error is nil, the status will be valid and the digest of the
+// parsed content recorded, else it'll be invalid. The summary of the parsed
+// content and the garbage collection status are only updated if they're given.
+func updateProfileBundleStatus(pcfg *profileparser.ParserConfig, pb *cmpv1alpha1.ProfileBundle, digest string, summary *contentSummary, gc *cmpv1alpha1.ProfileBundleGarbageCollectionStatus, err error) {
 	if err != nil {
 		// Never update a fetched object, always just a copy
 		pbCopy := pb.DeepCopy()
 		pbCopy.Status.DataStreamStatus = cmpv1alpha1.DataStreamInvalid
 		pbCopy.Status.ErrorMessage = err.Error()
+		pbCopy.Status.ParsedContentDigest = ""
+		pbCopy.Status.ContentVersion = ""
+		pbCopy.Status.Contents = nil
+		pbCopy.Status.ParseDuration = ""
+		pbCopy.Status.ContentDiff = nil
+		pbCopy.Status.ContentEpoch = ""
+		pbCopy.Status.GarbageCollection = nil
 		pbCopy.Status.SetConditionInvalid()
 		err = pcfg.Client.Status().Update(context.TODO(), pbCopy)
 		if err != nil {
@@ -104,6 +121,20 @@ func updateProfileBundleStatus(pcfg *profileparser.ParserConfig, pb *cmpv1alpha1
 		// Never update a fetched object, always just a copy
 		pbCopy := pb.DeepCopy()
 		pbCopy.Status.DataStreamStatus = cmpv1alpha1.DataStreamValid
+		pbCopy.Status.ParsedContentDigest = digest
+		if summary != nil {
+			pbCopy.Status.ContentVersion = summary.version
+			pbCopy.Status.Contents = summary.contents
+			pbCopy.Status.ParseDuration = summary.duration.String()
+			pbCopy.Status.ContentDiff = summary.diff
+			if summary.diff != nil {
+				summary.diff.PreviousContentVersion = pb.Status.ContentVersion
+			}
+			pbCopy.Status.ContentEpoch = summary.epoch
+		}
+		if gc != nil {
+			pbCopy.Status.GarbageCollection = gc
+		}
 		pbCopy.Status.SetConditionReady()
 		err = pcfg.Client.Status().Update(context.TODO(), pbCopy)
 		if err != nil {
@@ -113,6 +144,18 @@ func updateProfileBundleStatus(pcfg *profileparser.ParserConfig, pb *cmpv1alpha1
 	}
 }
 
+// contentSummary describes the content that was parsed for the status of
+// the ProfileBundle
+type contentSummary struct {
+	version  string
+	contents []cmpv1alpha1.ProfileBundleContentStatus
+	duration time.Duration
+	// How the parsed content changed the profiles, if it's known
+	diff *cmpv1alpha1.ProfileBundleContentDiff
+	// The epoch the content was parsed in
+	epoch string
+}
+
 func runProfileParser(cmd *cobra.Command, args []string) {
 	pcfg := newParserConfig(cmd)
 
@@ -123,35 +166,128 @@ func runProfileParser(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	contentFile, err := readContent(pcfg.DataStreamPath)
+	files := pb.GetContentFiles()
+	dsPaths := make([]string, 0, len(files))
+	for _, file := range files {
+		if pcfg.ContentDir != "" {
+			dsPaths = append(dsPaths, filepath.Join(pcfg.ContentDir, file.File))
+		} else {
+			dsPaths = append(dsPaths, pcfg.DataStreamPath)
+		}
+	}
+
+	digest, err := getParsedContentDigest(files, dsPaths)
 	if err != nil {
 		cmdLog.Error(err, "Couldn't read the content")
-		updateProfileBundleStatus(pcfg, pb, fmt.Errorf("Couldn't read content file: %s", err))
+		updateProfileBundleStatus(pcfg, pb, "", nil, nil, err)
 		os.Exit(1)
 	}
-	bufContentFile := bufio.NewReader(contentFile)
-	contentDom, err := xmlquery.Parse(bufContentFile)
-	if err != nil {
-		cmdLog.Error(err, "Couldn't read the content XML")
-		updateProfileBundleStatus(pcfg, pb, fmt.Errorf("Couldn't read content XML: %s", err))
-		if closeErr := contentFile.Close(); closeErr != nil {
-			cmdLog.Error(err, "Couldn't close the content file")
+	// The objects parsed out of the same content are still there, so
+	// parsing it again would only result in the same objects
+	if digest == pb.Status.ParsedContentDigest {
+		cmdLog.Info("The content was already parsed, skipping", "digest", digest)
+		// Collect the garbage of the content again, in case the previous
+		// run was a dry run and this one isn't
+		var gc *cmpv1alpha1.ProfileBundleGarbageCollectionStatus
+		if pb.Status.ContentEpoch != "" {
+			gc, err = profileparser.CollectGarbage(pcfg.Client, pb, pb.Status.ContentEpoch, pcfg.DryRunGarbageCollection)
+			if err != nil {
+				cmdLog.Error(err, "Couldn't collect the garbage of the content")
+			}
 		}
-		os.Exit(1)
+		updateProfileBundleStatus(pcfg, pb, digest, nil, gc, nil)
+		return
+	}
+
+	// Keep what the previous content parsed into, to tell how the profiles
+	// changed with the new content
+	previous, err := getParsedContent(pcfg.Client, pb, pb.Status.ContentEpoch)
+	if err != nil {
+		cmdLog.Error(err, "Couldn't get the previously parsed content, won't compare the contents")
+	}
+
+	start := time.Now()
+	contents := []profileparser.BundleContent{}
+	for i, file := range files {
+		dsPath := dsPaths[i]
+		contents = append(contents, profileparser.BundleContent{
+			// The data streams are streamed rather than read into memory
+			Open: func() (io.ReadCloser, error) {
+				return readContent(dsPath)
+			},
+			File:   file.File,
+			Prefix: file.Prefix,
+		})
 	}
 
-	err = profileparser.ParseBundle(contentDom, pb, pcfg)
+	epoch := profileparser.NewContentEpoch(pb)
+	err = profileparser.ParseBundleContentsInEpoch(contents, pb, epoch, pcfg)
+	var gc *cmpv1alpha1.ProfileBundleGarbageCollectionStatus
+	if err == nil {
+		gc, err = profileparser.CollectGarbage(pcfg.Client, pb, epoch, pcfg.DryRunGarbageCollection)
+	}
+	summary := summarizeContents(contents, time.Since(start))
+	summary.epoch = epoch
+	if err == nil && previous != nil {
+		current, getErr := getParsedContent(pcfg.Client, pb, epoch)
+		if getErr != nil {
+			cmdLog.Error(getErr, "Couldn't get the parsed content, won't compare the contents")
+		} else {
+			summary.diff = diffParsedContent(previous, current)
+		}
+	}
 
 	// The err variable might be nil, this is fine, it'll just update the status
 	// to valid
-	updateProfileBundleStatus(pcfg, pb, err)
+	updateProfileBundleStatus(pcfg, pb, digest, summary, gc, err)
 
 	if err != nil {
 		cmdLog.Error(err, "Parsing the bundle failed, will restart the container")
 		os.Exit(1)
 	}
+}
+
+// summarizeContents summarizes the parsed content files, the version of the
+// content being the version of the first benchmark of the first file
+func summarizeContents(contents []profileparser.BundleContent, duration time.Duration) *contentSummary {
+	summary := &contentSummary{duration: duration.Round(time.Millisecond)}
+	for i := range contents {
+		content := &contents[i]
+		contentStatus := cmpv1alpha1.ProfileBundleContentStatus{
+			File:       content.File,
+			Benchmarks: content.Benchmarks,
+			Profiles:   content.Profiles,
+			Rules:      content.Rules,
+		}
+		if summary.version == "" && len(contentStatus.Benchmarks) > 0 {
+			summary.version = contentStatus.Benchmarks[0].Version
+		}
+		summary.contents = append(summary.contents, contentStatus)
+	}
+	return summary
+}
 
-	if closeErr := contentFile.Close(); closeErr != nil {
-		cmdLog.Error(err, "Couldn't close the content file")
+// getParsedContentDigest returns the digest the parsed content is cached by,
+// which changes along with the content files, their prefixes, the version of
+// the parser and the platform, which decides what profiles are parsed
+func getParsedContentDigest(files []cmpv1alpha1.ProfileBundleContentFile, dsPaths []string) (string, error) {
+	hasher := sha256.New()
+	fmt.Fprintf(hasher, "%s\n%s\n%s\n", version.Version, utils.GetPlatform(), utils.GetControlPlaneTopology())
+	for i, file := range files {
+		fmt.Fprintf(hasher, "%s\n%s\n", file.File, file.Prefix)
+		if err := hashContentFile(hasher, dsPaths[i]); err != nil {
+			return "", fmt.Errorf("Couldn't read content file: %s", err)
+		}
+	}
+	return fmt.Sprintf("sha256:%x", hasher.Sum(nil)), nil
+}
+
+func hashContentFile(hasher io.Writer, dsPath string) error {
+	contentFile, err := readContent(dsPath)
+	if err != nil {
+		return err
 	}
+	defer contentFile.Close()
+	_, err = io.Copy(hasher, contentFile)
+	return err
 }
@@ -0,0 +1,101 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/profileparser"
+)
+
+var _ = Describe("Profileparser", func() {
+	Context("Getting the digest of the parsed content", func() {
+		var (
+			dir     string
+			files   []cmpv1alpha1.ProfileBundleContentFile
+			dsPaths []string
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "profileparser")
+			Expect(err).To(BeNil())
+			files = []cmpv1alpha1.ProfileBundleContentFile{
+				{File: "ssg-ocp4-ds.xml", Prefix: "ocp4"},
+				{File: "ssg-rhcos4-ds.xml", Prefix: "rhcos4"},
+			}
+			dsPaths = []string{}
+			for _, file := range files {
+				dsPath := filepath.Join(dir, file.File)
+				Expect(os.WriteFile(dsPath, []byte("<"+file.Prefix+"/>"), 0600)).To(Succeed())
+				dsPaths = append(dsPaths, dsPath)
+			}
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("is the same for the same content", func() {
+			digest, err := getParsedContentDigest(files, dsPaths)
+			Expect(err).To(BeNil())
+			Expect(digest).To(HavePrefix("sha256:"))
+			Expect(getParsedContentDigest(files, dsPaths)).To(Equal(digest))
+		})
+
+		It("changes with the content", func() {
+			digest, err := getParsedContentDigest(files, dsPaths)
+			Expect(err).To(BeNil())
+			Expect(os.WriteFile(dsPaths[1], []byte("<updated/>"), 0600)).To(Succeed())
+			Expect(getParsedContentDigest(files, dsPaths)).ToNot(Equal(digest))
+		})
+
+		It("changes with the prefixes", func() {
+			digest, err := getParsedContentDigest(files, dsPaths)
+			Expect(err).To(BeNil())
+			files[0].Prefix = "platform"
+			Expect(getParsedContentDigest(files, dsPaths)).ToNot(Equal(digest))
+		})
+
+		It("fails for missing files", func() {
+			_, err := getParsedContentDigest(files, []string{dsPaths[0], filepath.Join(dir, "missing.xml")})
+			Expect(err).To(MatchError(ContainSubstring("Couldn't read content file")))
+		})
+	})
+
+	Context("Summarizing the parsed content", func() {
+		It("has the benchmarks and the counts of each file", func() {
+			contents := []profileparser.BundleContent{
+				{
+					File:   "ssg-ocp4-ds.xml",
+					Prefix: "ocp4",
+					Benchmarks: []cmpv1alpha1.ProfileBundleBenchmark{
+						{ID: "xccdf_org.ssgproject.content_benchmark_OCP-4", Version: "0.1.75"},
+					},
+					Profiles: 1,
+					Rules:    10,
+				},
+				{File: "empty.xml", Prefix: "empty", Benchmarks: []cmpv1alpha1.ProfileBundleBenchmark{}},
+			}
+
+			summary := summarizeContents(contents, 1234567*time.Microsecond)
+			Expect(summary.version).To(Equal("0.1.75"))
+			Expect(summary.duration.String()).To(Equal("1.235s"))
+			Expect(summary.contents).To(Equal([]cmpv1alpha1.ProfileBundleContentStatus{
+				{
+					File: "ssg-ocp4-ds.xml",
+					Benchmarks: []cmpv1alpha1.ProfileBundleBenchmark{
+						{ID: "xccdf_org.ssgproject.content_benchmark_OCP-4", Version: "0.1.75"},
+					},
+					Profiles: 1,
+					Rules:    10,
+				},
+				{File: "empty.xml", Benchmarks: []cmpv1alpha1.ProfileBundleBenchmark{}},
+			}))
+		})
+	})
+})
@@ -0,0 +1,298 @@
+package manager
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// rawResultsSubresource is the subresource of the scans that the bearer
+// tokens need to be allowed to get to download their raw results
+const rawResultsSubresource = "rawresults"
+
+// errUnauthenticatedToken is returned when the API server doesn't
+// authenticate a bearer token
+var errUnauthenticatedToken = errors.New("the bearer token isn't authenticated")
+
+// rawResultRun is a scan run whose raw results are stored by the result server
+type rawResultRun struct {
+	Index        string          `json:"index"`
+	CreationTime time.Time       `json:"creationTime"`
+	Files        []rawResultFile `json:"files"`
+}
+
+// rawResultFile is a raw result file of a scan run
+type rawResultFile struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// tokenAuthorizer tells whether a bearer token is allowed to download the
+// raw results
+type tokenAuthorizer interface {
+	authorize(ctx context.Context, token string) (bool, error)
+}
+
+// kubeTokenAuthorizer authenticates the bearer tokens with TokenReviews and
+// checks with SubjectAccessReviews that they're allowed to get the raw
+// results of the scan
+type kubeTokenAuthorizer struct {
+	client    kubernetes.Interface
+	scanName  string
+	namespace string
+}
+
+func newKubeTokenAuthorizer(scanName, namespace string) (*kubeTokenAuthorizer, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeTokenAuthorizer{client: client, scanName: scanName, namespace: namespace}, nil
+}
+
+func (a *kubeTokenAuthorizer) authorize(ctx context.Context, token string) (bool, error) {
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	if !review.Status.Authenticated {
+		return false, errUnauthenticatedToken
+	}
+	user := review.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	access, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   a.namespace,
+				Verb:        "get",
+				Group:       compv1alpha1.SchemeGroupVersion.Group,
+				Resource:    "compliancescans",
+				Subresource: rawResultsSubresource,
+				Name:        a.scanName,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return access.Status.Allowed, nil
+}
+
+// rawResultAPI lists and downloads the raw results of the scan runs the
+// result server stores
+type rawResultAPI struct {
+	basePath string
+	scanName string
+	// Authorizes the clients that don't present a certificate of the scan
+	// CA, if any
+	authorizer tokenAuthorizer
+}
+
+func (a *rawResultAPI) register(mux *http.ServeMux) {
+	mux.Handle("GET /runs", a.authenticate(http.HandlerFunc(a.listRuns)))
+	mux.Handle("GET /runs/{index}", a.authenticate(http.HandlerFunc(a.downloadRun)))
+	mux.Handle("GET /runs/{index}/{file}", a.authenticate(http.HandlerFunc(a.downloadFile)))
+}
+
+// authenticate only lets the requests through that either present a
+// certificate of the scan CA or a bearer token that's allowed to get the raw
+// results of the scan
+func (a *rawResultAPI) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" || a.authorizer == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		allowed, err := a.authorizer.authorize(r.Context(), token)
+		if errors.Is(err, errUnauthenticatedToken) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			cmdLog.Error(err, "Error authorizing the download of raw results")
+			http.Error(w, "Error authorizing the request", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getRunPath returns the directory of the scan run of the given index, and
+// whether it exists
+func (a *rawResultAPI) getRunPath(index string) (string, bool) {
+	// Scan run indexes are numbers, which also keeps the requests from
+	// reaching out of the base path
+	if _, err := strconv.ParseUint(index, 10, 64); err != nil {
+		return "", false
+	}
+	runPath := filepath.Join(a.basePath, index)
+	info, err := os.Stat(runPath)
+	return runPath, err == nil && info.IsDir()
+}
+
+// listRunFiles lists the raw result files of a scan run
+func listRunFiles(runPath string) ([]rawResultFile, error) {
+	entries, err := os.ReadDir(runPath)
+	if err != nil {
+		return nil, err
+	}
+	files := []rawResultFile{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rawResultFile{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime().UTC()})
+	}
+	return files, nil
+}
+
+func (a *rawResultAPI) listRuns(w http.ResponseWriter, r *http.Request) {
+	dirs, err := listResultDirectories(a.basePath)
+	if err != nil {
+		http.Error(w, "Error listing the scan runs", http.StatusInternalServerError)
+		return
+	}
+	runs := []rawResultRun{}
+	for _, dir := range dirs {
+		index := filepath.Base(dir.Path)
+		if _, ok := a.getRunPath(index); !ok {
+			continue
+		}
+		files, err := listRunFiles(dir.Path)
+		if err != nil {
+			cmdLog.Error(err, "Error listing the raw results of a scan run", "directory", dir.Path)
+			http.Error(w, "Error listing the raw results", http.StatusInternalServerError)
+			return
+		}
+		runs = append(runs, rawResultRun{Index: index, CreationTime: dir.CreationTime.UTC(), Files: files})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		cmdLog.Error(err, "Error writing the list of scan runs")
+	}
+}
+
+// downloadRun streams a tar archive of the raw results of a scan run
+func (a *rawResultAPI) downloadRun(w http.ResponseWriter, r *http.Request) {
+	index := r.PathValue("index")
+	runPath, ok := a.getRunPath(index)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	files, err := listRunFiles(runPath)
+	if err != nil {
+		cmdLog.Error(err, "Error listing the raw results of a scan run", "directory", runPath)
+		http.Error(w, "Error listing the raw results", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", a.scanName+"-"+index+".tar"))
+	// The status is sent with the first file, so errors past this point
+	// can only cut the archive short
+	tw := tar.NewWriter(w)
+	for _, file := range files {
+		if err := writeTarFile(tw, filepath.Join(runPath, file.Name), index+"/"+file.Name); err != nil {
+			cmdLog.Error(err, "Error archiving a raw result file", "directory", runPath, "file", file.Name)
+			return
+		}
+	}
+	if err := tw.Close(); err != nil {
+		cmdLog.Error(err, "Error archiving the raw results of a scan run", "directory", runPath)
+	}
+}
+
+func writeTarFile(tw *tar.Writer, filePath, name string) error {
+	f, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return err
+	}
+	// #nosec
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// downloadFile sends a single raw result file of a scan run
+func (a *rawResultAPI) downloadFile(w http.ResponseWriter, r *http.Request) {
+	runPath, ok := a.getRunPath(r.PathValue("index"))
+	name := r.PathValue("file")
+	if !ok || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(filepath.Join(runPath, name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	// #nosec
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	// The files are compressed, so their type isn't sniffed
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
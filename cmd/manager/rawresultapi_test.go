@@ -0,0 +1,227 @@
+package manager
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// fakeTokenAuthorizer allows the tokens it's given, and authenticates the
+// tokens it's told to deny
+type fakeTokenAuthorizer struct {
+	allowed map[string]bool
+}
+
+func (a *fakeTokenAuthorizer) authorize(_ context.Context, token string) (bool, error) {
+	allowed, ok := a.allowed[token]
+	if !ok {
+		return false, errUnauthenticatedToken
+	}
+	return allowed, nil
+}
+
+var _ = Describe("Raw result API", func() {
+	var (
+		basePath string
+		handler  http.Handler
+	)
+
+	withClientCertificate := func(req *http.Request) *http.Request {
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+		return req
+	}
+	withToken := func(req *http.Request, token string) *http.Request {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+	get := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	newGet := func(target string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, target, nil)
+	}
+
+	BeforeEach(func() {
+		var err error
+		basePath, err = os.MkdirTemp("", "rawresults")
+		Expect(err).To(BeNil())
+		for _, run := range []string{"0", "1"} {
+			Expect(os.Mkdir(filepath.Join(basePath, run), 0750)).To(Succeed())
+			for _, node := range []string{"worker-0", "worker-1"} {
+				contents := []byte("<arf run=\"" + run + "\" node=\"" + node + "\"/>")
+				Expect(os.WriteFile(filepath.Join(basePath, run, node+".xml.bzip2"), contents, 0600)).To(Succeed())
+			}
+		}
+		Expect(os.Mkdir(filepath.Join(basePath, "lost+found"), 0750)).To(Succeed())
+		c := &resultServerConfig{BasePath: basePath, Path: filepath.Join(basePath, "1")}
+		handler = newResultServerHandler(c, nil, &rawResultAPI{
+			basePath:   basePath,
+			scanName:   "ocp4-cis",
+			authorizer: &fakeTokenAuthorizer{allowed: map[string]bool{"reader": true, "other": false}},
+		})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(basePath)
+	})
+
+	It("lists the scan runs and their files", func() {
+		rec := get(withClientCertificate(newGet("/runs")))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+		var runs []rawResultRun
+		Expect(json.Unmarshal(rec.Body.Bytes(), &runs)).To(Succeed())
+		Expect(runs).To(HaveLen(2))
+		for _, run := range runs {
+			Expect(run.Files).To(HaveLen(2))
+			Expect(run.Files[0].Name).To(Equal("worker-0.xml.bzip2"))
+			Expect(run.Files[0].Size).To(BeNumerically(">", 0))
+		}
+	})
+
+	It("downloads the archive of a scan run", func() {
+		rec := get(withToken(newGet("/runs/1"), "reader"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="ocp4-cis-1.tar"`))
+		tr := tar.NewReader(rec.Body)
+		var names []string
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).To(BeNil())
+			names = append(names, header.Name)
+			contents, err := io.ReadAll(tr)
+			Expect(err).To(BeNil())
+			Expect(string(contents)).To(ContainSubstring(`run="1"`))
+		}
+		Expect(names).To(Equal([]string{"1/worker-0.xml.bzip2", "1/worker-1.xml.bzip2"}))
+	})
+
+	It("downloads a single raw result file", func() {
+		rec := get(withToken(newGet("/runs/0/worker-1.xml.bzip2"), "reader"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/octet-stream"))
+		Expect(rec.Body.String()).To(Equal(`<arf run="0" node="worker-1"/>`))
+	})
+
+	It("doesn't serve anything outside of the scan runs", func() {
+		for _, target := range []string{
+			"/runs/2",
+			"/runs/lost+found",
+			"/runs/..",
+			"/runs/0/worker-2.xml.bzip2",
+			"/runs/0/..%2F1%2Fworker-0.xml.bzip2",
+			"/runs/0/%2E%2E",
+		} {
+			rec := get(withToken(newGet(target), "reader"))
+			Expect(rec.Code).ToNot(Equal(http.StatusOK), target)
+			Expect(rec.Body.String()).ToNot(ContainSubstring("<arf"), target)
+		}
+	})
+
+	It("only serves authorized clients", func() {
+		rec := get(newGet("/runs"))
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rec.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+		Expect(get(withToken(newGet("/runs"), "forged")).Code).To(Equal(http.StatusUnauthorized))
+		Expect(get(withToken(newGet("/runs"), "other")).Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("only takes uploads from clients with a certificate", func() {
+		upload := func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<arf/>"))
+			req.Header.Set("X-Report-Name", "worker-2")
+			return req
+		}
+		Expect(get(withToken(upload(), "reader")).Code).To(Equal(http.StatusUnauthorized))
+		Expect(get(withClientCertificate(upload())).Code).To(Equal(http.StatusOK))
+		Expect(filepath.Join(basePath, "1", "worker-2.xml")).To(BeAnExistingFile())
+	})
+
+	It("stores the HTML reports along with the ARF reports", func() {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("<html/>"))
+		req.Header.Set("X-Report-Name", "worker-0")
+		req.Header.Set("Content-Type", "text/html")
+		Expect(get(withClientCertificate(req)).Code).To(Equal(http.StatusOK))
+		Expect(filepath.Join(basePath, "1", "worker-0.html")).To(BeAnExistingFile())
+
+		rec := get(withToken(newGet("/runs/1/worker-0.html"), "reader"))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("<html/>"))
+	})
+
+	Context("authorizing the bearer tokens", func() {
+		var (
+			server   *httptest.Server
+			reviewed *authorizationv1.SubjectAccessReview
+		)
+
+		BeforeEach(func() {
+			reviewed = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/apis/authentication.k8s.io/v1/tokenreviews":
+					review := &authenticationv1.TokenReview{}
+					Expect(json.NewDecoder(r.Body).Decode(review)).To(Succeed())
+					review.Status.Authenticated = review.Spec.Token == "reader"
+					review.Status.User = authenticationv1.UserInfo{Username: "system:serviceaccount:ci:reader", Groups: []string{"system:serviceaccounts"}}
+					json.NewEncoder(w).Encode(review)
+				case "/apis/authorization.k8s.io/v1/subjectaccessreviews":
+					reviewed = &authorizationv1.SubjectAccessReview{}
+					Expect(json.NewDecoder(r.Body).Decode(reviewed)).To(Succeed())
+					reviewed.Status.Allowed = true
+					json.NewEncoder(w).Encode(reviewed)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("checks that the token's user can get the raw results of the scan", func() {
+			client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			Expect(err).To(BeNil())
+			authorizer := &kubeTokenAuthorizer{client: client, scanName: "ocp4-cis", namespace: "openshift-compliance"}
+
+			allowed, err := authorizer.authorize(context.TODO(), "reader")
+			Expect(err).To(BeNil())
+			Expect(allowed).To(BeTrue())
+			Expect(reviewed.Spec.User).To(Equal("system:serviceaccount:ci:reader"))
+			Expect(reviewed.Spec.Groups).To(ConsistOf("system:serviceaccounts"))
+			Expect(*reviewed.Spec.ResourceAttributes).To(Equal(authorizationv1.ResourceAttributes{
+				Namespace:   "openshift-compliance",
+				Verb:        "get",
+				Group:       "compliance.openshift.io",
+				Resource:    "compliancescans",
+				Subresource: "rawresults",
+				Name:        "ocp4-cis",
+			}))
+
+			_, err = authorizer.authorize(context.TODO(), "forged")
+			Expect(err).To(Equal(errUnauthenticatedToken))
+		})
+	})
+})
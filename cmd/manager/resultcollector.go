@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	goerrors "errors"
 	"flag"
 	"fmt"
@@ -29,12 +30,12 @@ import (
 	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
-	"github.com/dsnet/compress/bzip2"
 	libgocrypto "github.com/openshift/library-go/pkg/crypto"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -59,25 +60,63 @@ var (
 	timeoutErr = goerrors.New("Timed out waiting for results file")
 )
 
+// maxScannerOutputSize is the maximum amount of the scanner's output that is
+// kept for debugging, so that it fits in a ConfigMap
+const maxScannerOutputSize = 512 * 1024
+
+// maxFailureEvidenceSize is the maximum size of the evidence of all the
+// failing checks of a scan, so that it fits in the result ConfigMap along
+// with the results
+const maxFailureEvidenceSize = 256 * 1024
+
 func init() {
 	defineResultcollectorFlags(ResultcollectorCmd)
 }
 
 type scapresultsConfig struct {
-	ArfFile            string
-	XccdfFile          string
-	ExitCodeFile       string
-	CmdOutputFile      string
-	WarningsOutputFile string
-	ScanName           string
-	ConfigMapName      string
-	NodeName           string
-	Namespace          string
-	ResultServerURI    string
-	Timeout            int64
-	Cert               string
-	Key                string
-	CA                 string
+	ArfFile               string
+	XccdfFile             string
+	ExitCodeFile          string
+	CmdOutputFile         string
+	WarningsOutputFile    string
+	InputHashesFile       string
+	CustomRuleResultsFile string
+	ScannerOutputCM       string
+	ScanName              string
+	ConfigMapName         string
+	NodeName              string
+	Namespace             string
+	ResultServerURI       string
+	Timeout               int64
+	Cert                  string
+	Key                   string
+	CA                    string
+
+	// The evidence of the failing checks is only uploaded if the maximum
+	// size of the evidence of a check is set
+	FailureEvidenceMaxSize      int
+	FailureEvidenceRedactFields []string
+
+	// The codec the ARF report is compressed with before it's uploaded
+	// to the result server
+	Compression resultCodec
+	// Whether the ARF report is discarded, as there's no result server
+	// to upload it to
+	DiscardArf bool
+	// The HTML report to upload along with the ARF report, if any
+	HTMLReportFile string
+}
+
+// resultUpload is a raw result file the log collector uploads to the result
+// server
+type resultUpload struct {
+	// The file the contents were read from, to read them again if the
+	// result server doesn't accept their codec
+	fileName string
+	// The name the result server stores the file with, along with the
+	// extension of its content type
+	reportName  string
+	contentType string
 }
 
 func defineResultcollectorFlags(cmd *cobra.Command) {
@@ -86,6 +125,11 @@ func defineResultcollectorFlags(cmd *cobra.Command) {
 	cmd.Flags().String("exit-code-file", "", "A file containing the oscap command's exit code.")
 	cmd.Flags().String("oscap-output-file", "", "A file containing the oscap command's output.")
 	cmd.Flags().String("warnings-output-file", "", "A file containing the warnings to output.")
+	cmd.Flags().String("input-hashes-file", "", "A file containing the hashes of the inputs of each rule.")
+	cmd.Flags().String("custom-rule-results-file", "", "A file containing the results of the custom rules.")
+	cmd.Flags().String("scanner-output-config-map", "", "The configMap to keep the scanner's output in, for debugging.")
+	cmd.Flags().Int("failure-evidence-max-size", 0, "The maximum size of the evidence uploaded for each failing check. No evidence is uploaded if unset.")
+	cmd.Flags().String("failure-evidence-redact-fields", "", "A comma-separated list of the fields of the collected items to redact in the evidence.")
 	cmd.Flags().String("owner", "", "The compliance scan that owns the configMap objects.")
 	cmd.Flags().String("config-map-name", "", "The configMap to upload to, typically the podname.")
 	cmd.Flags().String("node-name", "", "The node that was scanned.")
@@ -95,6 +139,10 @@ func defineResultcollectorFlags(cmd *cobra.Command) {
 	cmd.Flags().String("tls-client-cert", "", "The path to the client and CA PEM cert bundle.")
 	cmd.Flags().String("tls-client-key", "", "The path to the client PEM key.")
 	cmd.Flags().String("tls-ca", "", "The path to the CA certificate.")
+	cmd.Flags().String("compression", "bzip2", "The codec the ARF report is compressed with, either bzip2 or zstd.")
+	cmd.Flags().Int("compression-level", 0, "The zstd compression level, from 1 to 22.")
+	cmd.Flags().Bool("discard-arf", false, "Don't upload the ARF report to the result server, as there's none.")
+	cmd.Flags().String("html-report-file", "", "The HTML report to upload along with the ARF report, if it was generated.")
 
 	flags := cmd.Flags()
 
@@ -122,12 +170,30 @@ func parseConfig(cmd *cobra.Command) *scapresultsConfig {
 		conf.ResultServerURI = "http://" + conf.ScanName + "-rs:8080/"
 	}
 	conf.WarningsOutputFile, _ = cmd.Flags().GetString("warnings-output-file")
+	conf.InputHashesFile, _ = cmd.Flags().GetString("input-hashes-file")
+	conf.CustomRuleResultsFile, _ = cmd.Flags().GetString("custom-rule-results-file")
+	conf.ScannerOutputCM, _ = cmd.Flags().GetString("scanner-output-config-map")
+	conf.FailureEvidenceMaxSize, _ = cmd.Flags().GetInt("failure-evidence-max-size")
+	if redactFields, _ := cmd.Flags().GetString("failure-evidence-redact-fields"); redactFields != "" {
+		conf.FailureEvidenceRedactFields = strings.Split(redactFields, ",")
+	}
 
 	// platform scans have no node name
 	conf.NodeName, _ = cmd.Flags().GetString("node-name")
 
 	logf.SetLogger(zap.New())
 
+	compression, _ := cmd.Flags().GetString("compression")
+	compressionLevel, _ := cmd.Flags().GetInt("compression-level")
+	codec, err := newResultCodec(compression, compressionLevel)
+	if err != nil {
+		cmdLog.Error(err, "Invalid compression")
+		os.Exit(1)
+	}
+	conf.Compression = codec
+	conf.DiscardArf, _ = cmd.Flags().GetBool("discard-arf")
+	conf.HTMLReportFile, _ = cmd.Flags().GetString("html-report-file")
+
 	return &conf
 }
 
@@ -209,29 +275,15 @@ func resultNeedsCompression(size int64) bool {
 	return size > 1048570
 }
 
-func compressResults(contents io.Reader) (io.Reader, error) {
-	// Encode the contents ascii, compress it with gzip, b64encode it so it
-	// can be stored in the configmap.
-	var buffer bytes.Buffer
-	w, err := bzip2.NewWriter(&buffer, &bzip2.WriterConfig{Level: bzip2.BestCompression})
-	if err != nil {
-		return nil, err
-	}
-	defer w.Close()
-	_, err = io.Copy(w, contents)
-	if err != nil {
-		return nil, err
-	}
-	return &buffer, nil
-}
-
 type resultFileContents struct {
 	contents   io.Reader
 	compressed bool
-	close      func() error
+	// The codec the contents were compressed with, if they were
+	encoding string
+	close    func() error
 }
 
-func readResultsFile(filename string, timeout int64) (*resultFileContents, error) {
+func readResultsFile(filename string, timeout int64, codec resultCodec) (*resultFileContents, error) {
 	var err error
 	var rfContents resultFileContents
 
@@ -261,13 +313,14 @@ func readResultsFile(filename string, timeout int64) (*resultFileContents, error
 		rfContents.close = func() error {
 			return nil
 		}
-		rfContents.contents, err = compressResults(rfContents.contents)
-		cmdLog.Info("File needs compression", "results-file", filename)
+		rfContents.contents, err = codec.compress(rfContents.contents)
+		cmdLog.Info("File needs compression", "results-file", filename, "codec", codec.encoding)
 		if err != nil {
 			cmdLog.Error(err, "Error: Compression failed")
 			return nil, err
 		}
 		rfContents.compressed = true
+		rfContents.encoding = codec.encoding
 		cmdLog.Info("Compressed results")
 	} else {
 		rfContents.close = func() error {
@@ -295,7 +348,13 @@ func readWarningsFile(filename string) string {
 	return strings.Trim(string(contents), "\n")
 }
 
-func uploadToResultServer(arfContents *resultFileContents, scapresultsconf *scapresultsConfig) error {
+func uploadToResultServer(contents *resultFileContents, upload resultUpload, scapresultsconf *scapresultsConfig) error {
+	// The report is kept in memory so that it can be uploaded again
+	body, err := io.ReadAll(contents.contents)
+	if err != nil {
+		return err
+	}
+	encoding := contents.encoding
 	return backoff.Retry(func() error {
 		url := scapresultsconf.ResultServerURI
 		cmdLog.Info("Trying to upload to resultserver", "url", url)
@@ -305,11 +364,11 @@ func uploadToResultServer(arfContents *resultFileContents, scapresultsconf *scap
 			return err
 		}
 		client := &http.Client{Transport: transport}
-		req, _ := http.NewRequest("POST", url, arfContents.contents)
-		req.Header.Add("Content-Type", "application/xml")
-		req.Header.Add("X-Report-Name", scapresultsconf.ConfigMapName)
-		if arfContents.compressed {
-			req.Header.Add("Content-Encoding", "bzip2")
+		req, _ := http.NewRequest("POST", url, bytes.NewReader(body))
+		req.Header.Add("Content-Type", upload.contentType)
+		req.Header.Add("X-Report-Name", upload.reportName)
+		if encoding != "" {
+			req.Header.Add("Content-Encoding", encoding)
 		}
 		resp, err := client.Do(req)
 		if err != nil {
@@ -323,13 +382,49 @@ func uploadToResultServer(arfContents *resultFileContents, scapresultsconf *scap
 			return err
 		}
 		cmdLog.Info(string(bytesresp))
+		if resultServerRejectsEncoding(resp, encoding) {
+			codec, err := negotiateResultCodec(encoding, resp.Header.Get("Accept-Encoding"))
+			if err != nil {
+				return backoff.Permanent(err)
+			}
+			cmdLog.Info("The result server doesn't accept the compression codec, falling back",
+				"rejected", encoding, "codec", codec.encoding)
+			fallback, err := readResultsFile(upload.fileName, scapresultsconf.Timeout, codec)
+			if err != nil {
+				return backoff.Permanent(err)
+			}
+			defer fallback.close()
+			if body, err = io.ReadAll(fallback.contents); err != nil {
+				return backoff.Permanent(err)
+			}
+			encoding = fallback.encoding
+			return fmt.Errorf("the result server rejected the %s-compressed results", codec.encoding)
+		}
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("the result server failed to store the results: %s", resp.Status)
+		}
 		return nil
 	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
 }
 
+// resultServerRejectsEncoding tells whether the result server rejected the
+// upload because of its encoding. Servers that predate the negotiation of
+// the encoding reject the ones they don't accept as bad requests.
+func resultServerRejectsEncoding(resp *http.Response, encoding string) bool {
+	if encoding == "" {
+		return false
+	}
+	return resp.StatusCode == http.StatusUnsupportedMediaType ||
+		(resp.StatusCode == http.StatusBadRequest && encoding != bzip2Codec.encoding)
+}
+
 func uploadResultConfigMap(xccdfContents *resultFileContents, exitcode string,
 	scapresultsconf *scapresultsConfig, client *complianceCrClient) error {
 	warnings := readWarningsFile(scapresultsconf.WarningsOutputFile)
+	// Like the warnings, the hashes are only there for incremental scans
+	inputHashes := readWarningsFile(scapresultsconf.InputHashesFile)
+	customRuleResults := readWarningsFile(scapresultsconf.CustomRuleResultsFile)
+	failureEvidence := readFailureEvidence(scapresultsconf)
 
 	return backoff.Retry(func() error {
 		cmdLog.Info("Trying to upload results ConfigMap")
@@ -339,6 +434,15 @@ func uploadResultConfigMap(xccdfContents *resultFileContents, exitcode string,
 		}
 		confMap := utils.GetResultConfigMap(openscapScan, scapresultsconf.ConfigMapName, "results",
 			scapresultsconf.NodeName, xccdfContents.contents, xccdfContents.compressed, exitcode, warnings)
+		if inputHashes != "" {
+			confMap.Data[utils.InputHashesKey] = inputHashes
+		}
+		if customRuleResults != "" {
+			confMap.Data[utils.CustomRuleResultsKey] = customRuleResults
+		}
+		if failureEvidence != "" {
+			confMap.Data[utils.FailureEvidenceKey] = failureEvidence
+		}
 		err = client.client.Create(context.TODO(), confMap)
 
 		if errors.IsAlreadyExists(err) {
@@ -369,15 +473,99 @@ func uploadErrorConfigMap(errorMsg *resultFileContents, exitcode string,
 	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
 }
 
-func handleCompleteSCAPResults(exitcode string, scapresultsconf *scapresultsConfig, client *complianceCrClient) {
-	arfContents, err := readResultsFile(scapresultsconf.ArfFile, scapresultsconf.Timeout)
+// readFailureEvidence returns the JSON-encoded evidence of the failing checks
+// from the ARF report, if the scan collects it. The evidence is only there to
+// help reviewing the results, so failing to extract it doesn't fail the
+// upload of the results.
+func readFailureEvidence(scapresultsconf *scapresultsConfig) string {
+	if scapresultsconf.FailureEvidenceMaxSize <= 0 {
+		return ""
+	}
+	// #nosec
+	arf, err := os.Open(filepath.Clean(scapresultsconf.ArfFile))
 	if err != nil {
-		cmdLog.Error(err, "Failed to read ARF file")
-		os.Exit(1)
+		cmdLog.Error(err, "Failed to open the ARF file to read the evidence of the failing checks")
+		return ""
+	}
+	defer arf.Close()
+
+	evidence, err := utils.ParseFailureEvidence(bufio.NewReader(arf), utils.FailureEvidenceOptions{
+		MaxSize:      scapresultsconf.FailureEvidenceMaxSize,
+		RedactFields: scapresultsconf.FailureEvidenceRedactFields,
+	})
+	if err != nil {
+		cmdLog.Error(err, "Failed to read the evidence of the failing checks")
+		return ""
+	}
+	capped := capFailureEvidence(evidence, maxFailureEvidenceSize)
+	if len(capped) < len(evidence) {
+		cmdLog.Info("Dropped the evidence of some failing checks, as it doesn't fit in the results",
+			"dropped", len(evidence)-len(capped))
+	}
+	encoded, err := json.Marshal(capped)
+	if err != nil {
+		cmdLog.Error(err, "Failed to encode the evidence of the failing checks")
+		return ""
+	}
+	return string(encoded)
+}
+
+// capFailureEvidence keeps the evidence of as many checks as fits in the
+// maximum size, going through the checks in the order of their rule IDs
+func capFailureEvidence(evidence map[string]string, maxSize int) map[string]string {
+	ruleIDs := make([]string, 0, len(evidence))
+	for ruleID := range evidence {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	capped := make(map[string]string)
+	size := 0
+	for _, ruleID := range ruleIDs {
+		size += len(ruleID) + len(evidence[ruleID])
+		if size > maxSize {
+			break
+		}
+		capped[ruleID] = evidence[ruleID]
+	}
+	return capped
+}
+
+// getScannerOutputTail returns the end of the scanner's output, which is
+// where the errors of the scan typically are, if it's bigger than the size
+// that can be kept
+func getScannerOutputTail(output []byte) string {
+	if len(output) <= maxScannerOutputSize {
+		return string(output)
+	}
+	return string(output[len(output)-maxScannerOutputSize:])
+}
+
+func uploadScannerOutputConfigMap(scapresultsconf *scapresultsConfig, client *complianceCrClient) error {
+	output, err := os.ReadFile(filepath.Clean(scapresultsconf.CmdOutputFile))
+	if err != nil {
+		return err
 	}
-	defer arfContents.close()
 
-	xccdfContents, err := readResultsFile(scapresultsconf.XccdfFile, scapresultsconf.Timeout)
+	return backoff.Retry(func() error {
+		cmdLog.Info("Trying to upload scanner output ConfigMap")
+		openscapScan, err := getOpenSCAPScanInstance(scapresultsconf.ScanName, scapresultsconf.Namespace, client)
+		if err != nil {
+			return err
+		}
+		confMap := utils.GetScannerOutputConfigMap(openscapScan, scapresultsconf.ScannerOutputCM,
+			scapresultsconf.NodeName, getScannerOutputTail(output))
+		err = client.client.Create(context.TODO(), confMap)
+
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+}
+
+func handleCompleteSCAPResults(exitcode string, scapresultsconf *scapresultsConfig, client *complianceCrClient) {
+	xccdfContents, err := readResultsFile(scapresultsconf.XccdfFile, scapresultsconf.Timeout, bzip2Codec)
 	if err != nil {
 		cmdLog.Error(err, "Failed to read XCCDF file")
 		os.Exit(1)
@@ -385,17 +573,38 @@ func handleCompleteSCAPResults(exitcode string, scapresultsconf *scapresultsConf
 	defer xccdfContents.close()
 
 	var wg sync.WaitGroup
-	wg.Add(2)
-	go func() {
-		serverUploadErr := uploadToResultServer(arfContents, scapresultsconf)
-		if serverUploadErr != nil {
-			cmdLog.Error(serverUploadErr, "Failed to upload results to server")
+	if scapresultsconf.DiscardArf {
+		cmdLog.Info("Discarding the ARF report")
+	} else {
+		arfContents, err := readResultsFile(scapresultsconf.ArfFile, scapresultsconf.Timeout, scapresultsconf.Compression)
+		if err != nil {
+			cmdLog.Error(err, "Failed to read ARF file")
 			os.Exit(1)
 		}
-		cmdLog.Info("Uploaded to resultserver")
-		wg.Done()
-	}()
+		defer arfContents.close()
+
+		wg.Add(1)
+		go func() {
+			upload := resultUpload{
+				fileName:    scapresultsconf.ArfFile,
+				reportName:  scapresultsconf.ConfigMapName,
+				contentType: "application/xml",
+			}
+			serverUploadErr := uploadToResultServer(arfContents, upload, scapresultsconf)
+			if serverUploadErr != nil {
+				cmdLog.Error(serverUploadErr, "Failed to upload results to server")
+				os.Exit(1)
+			}
+			cmdLog.Info("Uploaded to resultserver")
+			wg.Done()
+		}()
+
+		if scapresultsconf.HTMLReportFile != "" {
+			uploadHTMLReport(&wg, scapresultsconf)
+		}
+	}
 
+	wg.Add(1)
 	go func() {
 		cmUploadErr := uploadResultConfigMap(xccdfContents, exitcode, scapresultsconf, client)
 		if cmUploadErr != nil {
@@ -408,8 +617,51 @@ func handleCompleteSCAPResults(exitcode string, scapresultsconf *scapresultsConf
 	wg.Wait()
 }
 
+// uploadHTMLReport uploads the HTML report to the result server, if it was
+// generated. The report is written before the exit code, so if it's not
+// there by now, it failed to generate and there's nothing to wait for.
+func uploadHTMLReport(wg *sync.WaitGroup, scapresultsconf *scapresultsConfig) {
+	if _, err := os.Stat(filepath.Clean(scapresultsconf.HTMLReportFile)); err != nil {
+		cmdLog.Info("No HTML report to upload", "reason", err.Error())
+		return
+	}
+	reportContents, err := readResultsFile(scapresultsconf.HTMLReportFile, scapresultsconf.Timeout, scapresultsconf.Compression)
+	if err != nil {
+		cmdLog.Error(err, "Failed to read HTML report")
+		os.Exit(1)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer reportContents.close()
+		upload := resultUpload{
+			fileName:    scapresultsconf.HTMLReportFile,
+			reportName:  scapresultsconf.ConfigMapName,
+			contentType: "text/html",
+		}
+		if err := uploadToResultServer(reportContents, upload, scapresultsconf); err != nil {
+			cmdLog.Error(err, "Failed to upload HTML report to server")
+			os.Exit(1)
+		}
+		cmdLog.Info("Uploaded HTML report to resultserver")
+		wg.Done()
+	}()
+}
+
+// handleUnchangedSCAPResults uploads the results ConfigMap of an incremental
+// scan that didn't run the scanner because no rule inputs changed. There are
+// no reports, the ConfigMap only carries the input hashes for the aggregator.
+func handleUnchangedSCAPResults(exitcode string, scapresultsconf *scapresultsConfig, client *complianceCrClient) {
+	noResults := &resultFileContents{contents: strings.NewReader("")}
+	if err := uploadResultConfigMap(noResults, exitcode, scapresultsconf, client); err != nil {
+		cmdLog.Error(err, "Failed to upload ConfigMap")
+		os.Exit(1)
+	}
+	cmdLog.Info("Uploaded ConfigMap")
+}
+
 func handleErrorInOscapRun(exitcode string, scapresultsconf *scapresultsConfig, client *complianceCrClient) {
-	errorMsg, err := readResultsFile(scapresultsconf.CmdOutputFile, scapresultsconf.Timeout)
+	errorMsg, err := readResultsFile(scapresultsconf.CmdOutputFile, scapresultsconf.Timeout, bzip2Codec)
 	if err != nil {
 		cmdLog.Error(err, "Failed to read error message output from oscap run")
 		os.Exit(1)
@@ -425,7 +677,7 @@ func handleErrorInOscapRun(exitcode string, scapresultsconf *scapresultsConfig,
 }
 
 func getOscapExitCode(scapresultsconf *scapresultsConfig) string {
-	exitcodeContent, err := readResultsFile(scapresultsconf.ExitCodeFile, scapresultsconf.Timeout)
+	exitcodeContent, err := readResultsFile(scapresultsconf.ExitCodeFile, scapresultsconf.Timeout, bzip2Codec)
 	if err != nil {
 		cmdLog.Error(err, "Failed to read oscap error code")
 		os.Exit(1)
@@ -486,6 +738,20 @@ func resultCollectorMain(cmd *cobra.Command, args []string) {
 	exitcode := getOscapExitCode(scapresultsconf)
 	cmdLog.Info("Got exit-code from file", "exit-code", exitcode)
 
+	if scapresultsconf.ScannerOutputCM != "" {
+		// Not being able to keep the output for debugging shouldn't
+		// fail the scan
+		if err := uploadScannerOutputConfigMap(scapresultsconf, crclient); err != nil {
+			cmdLog.Error(err, "Failed to upload scanner output ConfigMap")
+		} else {
+			cmdLog.Info("Uploaded scanner output ConfigMap")
+		}
+	}
+
+	if exitcode == common.OpenSCAPExitCodeUnchanged {
+		handleUnchangedSCAPResults(exitcode, scapresultsconf, crclient)
+		return
+	}
 	if exitCodeIsError(exitcode) {
 		handleErrorInOscapRun(exitcode, scapresultsconf, crclient)
 		return
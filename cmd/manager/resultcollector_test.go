@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"bytes"
 	"os"
 	"time"
 
@@ -57,4 +58,32 @@ var _ = Describe("Resultcollector", func() {
 			Expect(err).To(BeEquivalentTo(timeoutErr))
 		})
 	})
+
+	Context("Testing the scanner output is kept", func() {
+		It("keeps the whole output if it fits", func() {
+			Expect(getScannerOutputTail([]byte("E: oscap: error"))).To(Equal("E: oscap: error"))
+		})
+
+		It("keeps the end of the output if it's too big", func() {
+			output := append(bytes.Repeat([]byte("a"), maxScannerOutputSize), []byte("E: oscap: error")...)
+			tail := getScannerOutputTail(output)
+			Expect(tail).To(HaveLen(maxScannerOutputSize))
+			Expect(tail).To(HaveSuffix("E: oscap: error"))
+		})
+	})
+
+	Context("Testing the evidence of failing checks is capped", func() {
+		It("keeps the evidence of the checks that fit, in the order of their IDs", func() {
+			evidence := map[string]string{
+				"rule_c": "cccccccccc",
+				"rule_a": "aaaaaaaaaa",
+				"rule_b": "bbbbbbbbbb",
+			}
+			Expect(capFailureEvidence(evidence, 40)).To(Equal(map[string]string{
+				"rule_a": "aaaaaaaaaa",
+				"rule_b": "bbbbbbbbbb",
+			}))
+			Expect(capFailureEvidence(evidence, 1024)).To(Equal(evidence))
+		})
+	})
 })
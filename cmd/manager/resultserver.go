@@ -31,6 +31,7 @@ import (
 	"syscall"
 	"time"
 
+	backoff "github.com/cenkalti/backoff/v4"
 	"github.com/spf13/cobra"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -63,6 +64,16 @@ func defineResultServerFlags(cmd *cobra.Command) {
 	cmd.Flags().String("tls-server-key", "", "Path to the server key")
 	cmd.Flags().String("tls-ca", "", "Path to the CA certificate")
 	cmd.Flags().Uint16("rotation", 3, "Amount of raw result directories to keep")
+	cmd.Flags().Duration("max-age", 0, "Maximum age of the raw result directories to keep. The latest one is always kept")
+	cmd.Flags().String("object-storage-endpoint", "", "URL of the S3-compatible object storage to upload the raw results to")
+	cmd.Flags().String("object-storage-region", "", "Region of the object storage bucket")
+	cmd.Flags().String("object-storage-bucket", "", "Object storage bucket to upload the raw results to")
+	cmd.Flags().String("object-storage-prefix", "", "Prefix of the keys of the uploaded raw results")
+	cmd.Flags().String("object-storage-sse", "", "Server-side encryption of the uploaded raw results")
+	cmd.Flags().String("object-storage-kms-key-id", "", "Key management service key the uploaded raw results are encrypted with")
+	cmd.Flags().Bool("serve-results", false, "Serve the stored raw results to authenticated clients")
+	cmd.Flags().String("scan-name", "", "Name of the scan whose raw results are served")
+	cmd.Flags().String("scan-namespace", "", "Namespace of the scan whose raw results are served")
 
 	flags := cmd.Flags()
 
@@ -80,12 +91,24 @@ type resultServerConfig struct {
 	Key      string
 	CA       string
 	Rotation uint16
+	MaxAge   time.Duration
+	// The index of the scan run, used in the keys of the uploaded results
+	Index string
+	// Where the raw results are uploaded to, if anywhere
+	ObjectStorage       *utils.ObjectStorageConfig
+	ObjectStoragePrefix string
+	// Whether the raw results are served, and the scan that's allowed to
+	// get them
+	ServeResults  bool
+	ScanName      string
+	ScanNamespace string
 }
 
 func parseResultServerConfig(cmd *cobra.Command) *resultServerConfig {
 	basePath := getValidStringArg(cmd, "path")
 	index := getValidStringArg(cmd, "scan-index")
 	rotation, _ := cmd.Flags().GetUint16("rotation")
+	maxAge, _ := cmd.Flags().GetDuration("max-age")
 	conf := &resultServerConfig{
 		Address:  getValidStringArg(cmd, "address"),
 		Port:     getValidStringArg(cmd, "port"),
@@ -95,6 +118,28 @@ func parseResultServerConfig(cmd *cobra.Command) *resultServerConfig {
 		Key:      getValidStringArg(cmd, "tls-server-key"),
 		CA:       getValidStringArg(cmd, "tls-ca"),
 		Rotation: rotation,
+		MaxAge:   maxAge,
+		Index:    index,
+	}
+	if endpoint, _ := cmd.Flags().GetString("object-storage-endpoint"); endpoint != "" {
+		sse, _ := cmd.Flags().GetString("object-storage-sse")
+		kmsKeyID, _ := cmd.Flags().GetString("object-storage-kms-key-id")
+		prefix, _ := cmd.Flags().GetString("object-storage-prefix")
+		conf.ObjectStorage = &utils.ObjectStorageConfig{
+			Endpoint:             endpoint,
+			Region:               getValidStringArg(cmd, "object-storage-region"),
+			Bucket:               getValidStringArg(cmd, "object-storage-bucket"),
+			AccessKeyID:          os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey:      os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			ServerSideEncryption: sse,
+			KMSKeyID:             kmsKeyID,
+		}
+		conf.ObjectStoragePrefix = prefix
+	}
+	if serve, _ := cmd.Flags().GetBool("serve-results"); serve {
+		conf.ServeResults = true
+		conf.ScanName = getValidStringArg(cmd, "scan-name")
+		conf.ScanNamespace = getValidStringArg(cmd, "scan-namespace")
 	}
 
 	logf.SetLogger(zap.New())
@@ -118,6 +163,53 @@ func rotateResultDirectories(rootPath string, rotation uint16) error {
 		cmdLog.Info("Rotation policy set to '0'. No need to rotate.")
 		return nil
 	}
+	dirs, err := listResultDirectories(rootPath)
+	if err != nil {
+		cmdLog.Error(err, "Couldn't rotate directories")
+		return err
+	}
+	var lastError error
+	// No need to rotate, we're whithin the policy
+	if len(dirs) <= int(rotation) {
+		return nil
+	}
+	for _, dir := range dirs[rotation:] {
+		cmdLog.Info("Removing directory because of rotation policy", "directory", dir.Path)
+		err := os.RemoveAll(dir.Path)
+		if err != nil {
+			lastError = err
+		}
+	}
+	return lastError
+}
+
+// pruneResultDirectories removes the raw result directories that are older
+// than the maximum age, except for the latest one
+func pruneResultDirectories(rootPath string, maxAge time.Duration, now time.Time) error {
+	if maxAge <= 0 {
+		return nil
+	}
+	dirs, err := listResultDirectories(rootPath)
+	if err != nil {
+		cmdLog.Error(err, "Couldn't prune directories")
+		return err
+	}
+	var lastError error
+	for i, dir := range dirs {
+		if i == 0 || now.Sub(dir.CreationTime) <= maxAge {
+			continue
+		}
+		cmdLog.Info("Removing directory because of its age", "directory", dir.Path, "max-age", maxAge)
+		err := os.RemoveAll(dir.Path)
+		if err != nil {
+			lastError = err
+		}
+	}
+	return lastError
+}
+
+// listResultDirectories lists the raw result directories, newest first
+func listResultDirectories(rootPath string) ([]utils.Directory, error) {
 	dirs := []utils.Directory{}
 	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -140,23 +232,112 @@ func rotateResultDirectories(rootPath string, rotation uint16) error {
 		return nil
 	})
 	if err != nil {
-		cmdLog.Error(err, "Couldn't rotate directories")
-		return err
+		return nil, err
 	}
 	sort.Slice(dirs, func(i, j int) bool { return dirs[i].CreationTime.After(dirs[j].CreationTime) })
-	var lastError error
-	// No need to rotate, we're whithin the policy
-	if len(dirs) <= int(rotation) {
-		return nil
+	return dirs, nil
+}
+
+// getObjectKey returns the key a raw result file of the run is uploaded as
+func getObjectKey(prefix, index, filename string) string {
+	return prefix + index + "/" + filename
+}
+
+// uploadResult uploads a raw result file to the object storage, retrying
+// with a backoff
+func uploadResult(client *utils.ObjectStorageClient, key, filePath string) error {
+	return backoff.Retry(func() error {
+		err := client.UploadFile(context.TODO(), key, filePath, "application/xml")
+		if err != nil {
+			cmdLog.Error(err, "Retrying with a backoff because of an error while uploading a raw result", "key", key)
+		}
+		return err
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+}
+
+// newResultServerHandler returns the handler of the result server, which
+// also serves the raw results if there's an API to serve them with
+func newResultServerHandler(c *resultServerConfig, storageClient *utils.ObjectStorageClient, api *rawResultAPI) http.Handler {
+	mux := http.NewServeMux()
+	if api == nil {
+		mux.Handle("/", receiveResult(c, storageClient))
+		return mux
 	}
-	for _, dir := range dirs[rotation:] {
-		cmdLog.Info("Removing directory because of rotation policy", "directory", dir.Path)
-		err := os.RemoveAll(dir.Path)
+	// The clients downloading the raw results don't need a certificate, but
+	// the ones uploading them still do
+	mux.Handle("/", requireClientCertificate(receiveResult(c, storageClient)))
+	api.register(mux)
+	return mux
+}
+
+// requireClientCertificate only lets the requests through that present a
+// certificate of the scan CA
+func requireClientCertificate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			cmdLog.Info("Rejecting. No client certificate given.")
+			http.Error(w, "Missing client certificate", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// receiveResult stores the raw result files uploaded by the log collectors,
+// and uploads them to the object storage if there's any
+func receiveResult(c *resultServerConfig, storageClient *utils.ObjectStorageClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filename := r.Header.Get("X-Report-Name")
+		if filename == "" {
+			cmdLog.Info("Rejecting. No \"X-Report-Name\" header given.")
+			http.Error(w, "Missing report name header", 400)
+			return
+		}
+		encoding := r.Header.Get("Content-Encoding")
+		extraExtension, ok := getRawResultExtension(encoding)
+		if !ok {
+			// Tell the log collector which encodings to fall back to
+			cmdLog.Info("Rejecting. Unsupported \"Content-Encoding\" header given.", "encoding", encoding)
+			w.Header().Set("Accept-Encoding", getAcceptedRawResultEncodings())
+			http.Error(w, "unsupported content encoding", http.StatusUnsupportedMediaType)
+			return
+		}
+		// TODO(jaosorior): Check that content-type is application/xml
+		fileExtension := ".xml"
+		if r.Header.Get("Content-Type") == "text/html" {
+			// The HTML reports are stored along with the ARF reports
+			fileExtension = ".html"
+		}
+		filePath := path.Join(c.Path, filename+fileExtension+extraExtension)
+		cleanPath := filepath.Clean(filePath)
+		f, err := os.Create(cleanPath)
 		if err != nil {
-			lastError = err
+			cmdLog.Info("Error creating file", "file-path", cleanPath)
+			http.Error(w, "Error creating file", 500)
+			return
 		}
+		// #nosec
+		defer f.Close()
+
+		_, err = io.Copy(f, r.Body)
+		if err != nil {
+			cmdLog.Info("Error writing file", "file-path", cleanPath)
+			http.Error(w, "Error writing file", 500)
+			return
+		}
+		cmdLog.Info("Received file", "file-path", cleanPath)
+
+		if storageClient == nil {
+			return
+		}
+		key := getObjectKey(c.ObjectStoragePrefix, c.Index, filepath.Base(cleanPath))
+		if err := uploadResult(storageClient, key, cleanPath); err != nil {
+			cmdLog.Error(err, "Error uploading file", "file-path", cleanPath, "key", key)
+			http.Error(w, "Error uploading file", 500)
+			return
+		}
+		cmdLog.Info("Uploaded file", "file-path", cleanPath, "key", key)
 	}
-	return lastError
 }
 
 func server(c *resultServerConfig) {
@@ -170,6 +351,19 @@ func server(c *resultServerConfig) {
 	}
 
 	rotateResultDirectories(c.BasePath, c.Rotation)
+	pruneResultDirectories(c.BasePath, c.MaxAge, time.Now())
+
+	var storageClient *utils.ObjectStorageClient
+	if c.ObjectStorage != nil {
+		storageClient, err = utils.NewObjectStorageClient(*c.ObjectStorage, &http.Client{
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+			Timeout:   5 * time.Minute,
+		})
+		if err != nil {
+			cmdLog.Error(err, "Error configuring the object storage")
+			os.Exit(1)
+		}
+	}
 
 	caCert, err := os.ReadFile(c.CA)
 	if err != nil {
@@ -187,49 +381,25 @@ func server(c *resultServerConfig) {
 	tlsConfig = libgocrypto.SecureTLSConfig(tlsConfig)
 	tlsConfig.ClientCAs = caCertPool
 	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	var api *rawResultAPI
+	if c.ServeResults {
+		authorizer, err := newKubeTokenAuthorizer(c.ScanName, c.ScanNamespace)
+		if err != nil {
+			cmdLog.Error(err, "Error configuring the authorization of the raw result downloads")
+			os.Exit(1)
+		}
+		api = &rawResultAPI{basePath: c.BasePath, scanName: c.ScanName, authorizer: authorizer}
+		// The clients downloading the raw results may authenticate
+		// with a bearer token instead
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
 	tlsConfig.BuildNameToCertificate()
 	server := &http.Server{
 		Addr:      c.Address + ":" + c.Port,
 		TLSConfig: tlsConfig,
+		Handler:   newResultServerHandler(c, storageClient, api),
 	}
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		filename := r.Header.Get("X-Report-Name")
-		if filename == "" {
-			cmdLog.Info("Rejecting. No \"X-Report-Name\" header given.")
-			http.Error(w, "Missing report name header", 400)
-			return
-		}
-		encoding := r.Header.Get("Content-Encoding")
-		extraExtension := encoding
-		if encoding != "" && encoding != "bzip2" {
-			cmdLog.Info("Rejecting. Invalid \"Content-Encoding\" header given.")
-			http.Error(w, "invalid content encoding header", 400)
-			return
-		} else if encoding == "bzip2" {
-			extraExtension = "." + extraExtension
-		}
-		// TODO(jaosorior): Check that content-type is application/xml
-		filePath := path.Join(c.Path, filename+".xml"+extraExtension)
-		cleanPath := filepath.Clean(filePath)
-		f, err := os.Create(cleanPath)
-		if err != nil {
-			cmdLog.Info("Error creating file", "file-path", cleanPath)
-			http.Error(w, "Error creating file", 500)
-			return
-		}
-		// #nosec
-		defer f.Close()
-
-		_, err = io.Copy(f, r.Body)
-		if err != nil {
-			cmdLog.Info("Error writing file", "file-path", cleanPath)
-			http.Error(w, "Error writing file", 500)
-			return
-		}
-		cmdLog.Info("Received file", "file-path", cleanPath)
-	})
-
 	cmdLog.Info("Listening...")
 
 	go func() {
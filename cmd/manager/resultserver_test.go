@@ -174,6 +174,26 @@ var _ = Describe("Resultserver testing", func() {
 				Expect(lostFoundDir).To(BeADirectory())
 
 			})
+
+			It("Prunes the directories older than the maximum age, except for the latest one", func() {
+				err := pruneResultDirectories(rootDir, time.Minute, time.Now().Add(time.Hour))
+				Expect(err).To(BeNil())
+
+				files := _readDirNames(rootDir)
+
+				By("Verifying that only the latest directory is left")
+				Expect(len(files)).To(Equal(2))
+				Expect(path.Base(dir3)).To(BeElementOf(files))
+				Expect(path.Base(lostFoundDir)).To(BeElementOf(files))
+			})
 		}
+
+		It("Doesn't prune the directories within the maximum age", func() {
+			err := pruneResultDirectories(rootDir, time.Hour, time.Now())
+			Expect(err).To(BeNil())
+
+			files := _readDirNames(rootDir)
+			Expect(len(files)).To(Equal(4))
+		})
 	})
 })
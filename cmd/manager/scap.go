@@ -72,9 +72,17 @@ type scapContentDataStream struct {
 	dataStream *xmlquery.Node
 	tailoring  *xmlquery.Node
 	resources  []utils.ResourcePath
+	rules      []ruleResourcePaths
 	found      map[string][]byte
 }
 
+// ruleResourcePaths are the resource paths that a rule needs in order to be
+// evaluated
+type ruleResourcePaths struct {
+	ruleID string
+	paths  []utils.ResourcePath
+}
+
 func NewDataStreamResourceFetcher(scheme *runtime.Scheme, client runtimeclient.Client, clientSet *kubernetes.Clientset) ResourceFetcher {
 	return &scapContentDataStream{
 		resourceFetcherClients: resourceFetcherClients{
@@ -193,12 +201,14 @@ func (c *scapContentDataStream) FigureResources(profile string) error {
 	var valuesList map[string]string
 
 	if c.tailoring != nil {
-		var selected []utils.ResourcePath
-		selected, valuesList = getResourcePaths(c.tailoring, c.dataStream, profile, nil)
+		var rules []ruleResourcePaths
+		rules, valuesList = getRuleResourcePaths(c.tailoring, c.dataStream, profile, nil)
+		selected := flattenRuleResourcePaths(rules)
 		if len(selected) == 0 {
 			fmt.Printf("no valid checks found in tailoring\n")
 		}
 		found = append(found, selected...)
+		c.rules = append(c.rules, rules...)
 		// Overwrite profile so the next search uses the extended profile
 		effectiveProfile = c.getExtendedProfileFromTailoring(c.tailoring, profile)
 		// No profile is being extended
@@ -208,11 +218,13 @@ func (c *scapContentDataStream) FigureResources(profile string) error {
 		}
 	}
 
-	selected, _ := getResourcePaths(c.dataStream, c.dataStream, effectiveProfile, valuesList)
+	rules, _ := getRuleResourcePaths(c.dataStream, c.dataStream, effectiveProfile, valuesList)
+	selected := flattenRuleResourcePaths(rules)
 	if len(selected) == 0 {
 		fmt.Printf("no valid checks found in profile\n")
 	}
 	found = append(found, selected...)
+	c.rules = append(c.rules, rules...)
 	c.resources = found
 	DBG("c.resources: %v\n", c.resources)
 	return nil
@@ -235,7 +247,23 @@ func getPathFromWarningXML(in *xmlquery.Node, valueList map[string]string) []uti
 // Collect the resource paths for objects that this scan needs to obtain.
 // The profile will have a series of "selected" checks that we grab all of the path info from.
 func getResourcePaths(profileDefs *xmlquery.Node, ruleDefs *xmlquery.Node, profile string, overrideValueList map[string]string) ([]utils.ResourcePath, map[string]string) {
+	rules, valuesList := getRuleResourcePaths(profileDefs, ruleDefs, profile, overrideValueList)
+	return flattenRuleResourcePaths(rules), valuesList
+}
+
+func flattenRuleResourcePaths(rules []ruleResourcePaths) []utils.ResourcePath {
 	out := []utils.ResourcePath{}
+	for _, rule := range rules {
+		out = append(out, rule.paths...)
+	}
+	return out
+}
+
+// getRuleResourcePaths collects the resource paths for objects that this scan
+// needs to obtain, grouped by the selected check that needs them. Checks that
+// don't need any objects are returned too, with no paths.
+func getRuleResourcePaths(profileDefs *xmlquery.Node, ruleDefs *xmlquery.Node, profile string, overrideValueList map[string]string) ([]ruleResourcePaths, map[string]string) {
+	out := []ruleResourcePaths{}
 	selectedChecks := []string{}
 
 	// Before staring process, collect all of the variables in definitions.
@@ -333,13 +361,14 @@ func getResourcePaths(profileDefs *xmlquery.Node, ruleDefs *xmlquery.Node, profi
 				continue
 			}
 			// We only care for the first occurrence that works
-			out = append(out, apiPaths...)
+			out = append(out, ruleResourcePaths{ruleID: checkID, paths: apiPaths})
 			warningFound = true
 			break
 		}
 
 		if !warningFound {
 			DBG("Couldn't find 'warning' child of check %s", checkID)
+			out = append(out, ruleResourcePaths{ruleID: checkID})
 			continue
 		}
 
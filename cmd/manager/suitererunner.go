@@ -4,7 +4,10 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"math/rand"
 	"os"
+	"path"
+	"time"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	backoff "github.com/cenkalti/backoff/v4"
@@ -32,12 +35,19 @@ func init() {
 type rerunnerconfig struct {
 	Name      string
 	Namespace string
-	client    *complianceCrClient
+	// The glob patterns of the names of the scans to re-run, all of them
+	// if empty
+	Scans []string
+	// The upper bound of the random delay before re-running the scans
+	Jitter time.Duration
+	client *complianceCrClient
 }
 
 func defineRerunnerFlags(cmd *cobra.Command) {
 	cmd.Flags().String("name", "", "The name of the ComplianceSuite to be re-run")
 	cmd.Flags().String("namespace", "", "The namespace of the ComplianceSuite to be re-run")
+	cmd.Flags().StringSlice("scans", nil, "The glob patterns of the names of the scans to re-run. Defaults to all the scans of the ComplianceSuite")
+	cmd.Flags().Duration("jitter", 0, "Delays the re-run by a random duration of up to this")
 
 	flags := cmd.Flags()
 
@@ -50,6 +60,18 @@ func getRerunnerConfig(cmd *cobra.Command) *rerunnerconfig {
 	var conf rerunnerconfig
 	conf.Name = getValidStringArg(cmd, "name")
 	conf.Namespace = getValidStringArg(cmd, "namespace")
+	scans, err := cmd.Flags().GetStringSlice("scans")
+	if err != nil {
+		cmdLog.Error(err, "")
+		os.Exit(1)
+	}
+	conf.Scans = scans
+	jitter, err := cmd.Flags().GetDuration("jitter")
+	if err != nil {
+		cmdLog.Error(err, "")
+		os.Exit(1)
+	}
+	conf.Jitter = jitter
 
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -69,6 +91,25 @@ func getRerunnerConfig(cmd *cobra.Command) *rerunnerconfig {
 func RerunSuite(cmd *cobra.Command, args []string) {
 	conf := getRerunnerConfig(cmd)
 
+	if delay := getJitterDelay(conf.Jitter); delay > 0 {
+		fmt.Printf("Delaying the re-run of ComplianceSuite '%s' by %s\n", conf.Name, delay)
+		time.Sleep(delay)
+	}
+
+	suite := &compv1alpha1.ComplianceSuite{}
+	err := conf.client.client.Get(context.TODO(), types.NamespacedName{Name: conf.Name, Namespace: conf.Namespace}, suite)
+	if err != nil {
+		fmt.Printf("Error while getting ComplianceSuite '%s', err: %s\n", conf.Name, err)
+		os.Exit(1)
+	}
+
+	// Scans of serially executed suites are only marked for re-running,
+	// the suite controller re-runs them one after another
+	rescanAnnotation := compv1alpha1.ComplianceScanRescanAnnotation
+	if suite.RunsScansSerially() {
+		rescanAnnotation = compv1alpha1.ComplianceScanPendingRescanAnnotation
+	}
+
 	scans := &compv1alpha1.ComplianceScanList{}
 	scanSuiteSelector := make(map[string]string)
 	scanSuiteSelector[compv1alpha1.SuiteLabel] = conf.Name
@@ -76,7 +117,7 @@ func RerunSuite(cmd *cobra.Command, args []string) {
 		LabelSelector: labels.SelectorFromSet(scanSuiteSelector),
 		Namespace:     conf.Namespace,
 	}
-	err := conf.client.client.List(context.TODO(), scans, listOpts)
+	err = conf.client.client.List(context.TODO(), scans, listOpts)
 	if err != nil {
 		fmt.Printf("Error while getting scans for ComplianceSuite '%s', err: %s\n", conf.Name, err)
 		os.Exit(1)
@@ -86,6 +127,10 @@ func RerunSuite(cmd *cobra.Command, args []string) {
 
 	for idx := range scans.Items {
 		currentScan := &scans.Items[idx]
+		if !isScanToRerun(currentScan.GetName(), conf.Scans) {
+			fmt.Printf("Skipping ComplianceScan '%s' since it's not scheduled to re-run\n", currentScan.GetName())
+			continue
+		}
 		key := types.NamespacedName{Name: currentScan.GetName(), Namespace: currentScan.GetNamespace()}
 		err := backoff.Retry(func() error {
 			var scanCopy *compv1alpha1.ComplianceScan
@@ -102,7 +147,7 @@ func RerunSuite(cmd *cobra.Command, args []string) {
 			if scanCopy.Annotations == nil {
 				scanCopy.Annotations = make(map[string]string)
 			}
-			scanCopy.Annotations[compv1alpha1.ComplianceScanRescanAnnotation] = ""
+			scanCopy.Annotations[rescanAnnotation] = ""
 
 			fmt.Printf("Re-running ComplianceScan '%s'\n", scanCopy.Name)
 			err := conf.client.client.Update(context.TODO(), scanCopy)
@@ -123,3 +168,26 @@ func RerunSuite(cmd *cobra.Command, args []string) {
 		}
 	}
 }
+
+// isScanToRerun tells whether the scan matches any of the patterns, if there
+// are any
+func isScanToRerun(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// getJitterDelay returns a random delay of less than the jitter, which spreads
+// out the scans of the clusters sharing a schedule
+func getJitterDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
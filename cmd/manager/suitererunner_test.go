@@ -0,0 +1,25 @@
+package manager
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Suite rerunner testing", func() {
+	It("Only re-runs the scans matching the patterns", func() {
+		Expect(isScanToRerun("ocp4-cis", nil)).To(BeTrue())
+		Expect(isScanToRerun("ocp4-cis-node-worker", []string{"ocp4-cis-node-*"})).To(BeTrue())
+		Expect(isScanToRerun("ocp4-cis", []string{"ocp4-cis-node-*"})).To(BeFalse())
+	})
+
+	It("Delays the re-run by less than the jitter", func() {
+		Expect(getJitterDelay(0)).To(BeZero())
+		for i := 0; i < 100; i++ {
+			delay := getJitterDelay(30 * time.Minute)
+			Expect(delay).To(BeNumerically(">=", 0))
+			Expect(delay).To(BeNumerically("<", 30*time.Minute))
+		}
+	})
+})
@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,6 +15,11 @@ const ComplianceCheckResultStatusLabel = "compliance.openshift.io/check-status"
 const ComplianceCheckResultSeverityLabel = "compliance.openshift.io/check-severity"
 const ComplianceCheckResultValueLabel = "compliance.openshift.io/check-has-value"
 
+// ComplianceCheckResultNotApplicableReasonLabel exposes the type of the
+// reason of a NOT-APPLICABLE check, so that the checks that don't apply for
+// a given reason can be found easily
+const ComplianceCheckResultNotApplicableReasonLabel = "compliance.openshift.io/not-applicable-reason"
+
 // ComplianceCheckResultLabel defines a label that will be included in the
 // ComplianceCheckResult objects. It indicates whether the result has an automated
 // remediation or not.
@@ -38,6 +45,19 @@ const ComplianceCheckResultInconsistentSourceAnnotation = "compliance.openshift.
 const ComplianceCheckResultMostCommonAnnotation = "compliance.openshift.io/most-common-status"
 const ComplianceCheckResultErrorAnnotation = "compliance.openshift.io/error-msg"
 
+// ComplianceCheckResultOriginalSeverityAnnotation stores the severity the
+// content sets for the rule of a check whose severity was overridden through
+// the severityOverrides of its scan
+const ComplianceCheckResultOriginalSeverityAnnotation = "compliance.openshift.io/original-severity"
+
+// ComplianceCheckResultSeverityOverrideReasonAnnotation stores why the
+// severity of a check was overridden
+const ComplianceCheckResultSeverityOverrideReasonAnnotation = "compliance.openshift.io/severity-override-reason"
+
+// ComplianceCheckResultLastReportedAnnotation stores when the run of the scan
+// that last reported the result started, in RFC 3339 format
+const ComplianceCheckResultLastReportedAnnotation = "compliance.openshift.io/last-reported"
+
 const (
 	// The check ran to completion and passed
 	CheckResultPass ComplianceCheckStatus = "PASS"
@@ -67,12 +87,66 @@ const (
 	CheckResultSeverityHigh    ComplianceCheckResultSeverity = "high"
 )
 
+// NotApplicableReasonType tells why a check is NOT-APPLICABLE
+type NotApplicableReasonType string
+
+const (
+	// The rule only applies to platforms, as given by CPE applicability
+	// language platforms in the content, that the scanned target isn't
+	NotApplicablePlatformMismatch NotApplicableReasonType = "PlatformMismatch"
+	// The rule only applies to targets that match CPE names the scanned
+	// target doesn't match
+	NotApplicableCPENotMatched NotApplicableReasonType = "CPENotMatched"
+	// The rule requires other rules that weren't selected or didn't apply
+	// in the scan
+	NotApplicablePrerequisiteNotMet NotApplicableReasonType = "PrerequisiteNotMet"
+	// The check of the rule itself found that it doesn't apply to the
+	// scanned target
+	NotApplicableCheckNotApplicable NotApplicableReasonType = "CheckNotApplicable"
+)
+
+// NotApplicableReason records why a check is NOT-APPLICABLE
+type NotApplicableReason struct {
+	// The type of the reason
+	// +kubebuilder:validation:Enum=PlatformMismatch;CPENotMatched;PrerequisiteNotMet;CheckNotApplicable
+	Type NotApplicableReasonType `json:"type"`
+	// What the check isn't applicable for: the platforms or CPE names the
+	// rule applies to, or the IDs of the rules it requires that weren't met
+	// +listType=atomic
+	// +optional
+	Refs []string `json:"refs,omitempty"`
+}
+
+// CheckResultHistoryEntry is the status of a check in a run of its scan
+type CheckResultHistoryEntry struct {
+	// The result of the check in the run
+	Status ComplianceCheckStatus `json:"status"`
+	// When the run of the scan started
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// ManualCheckReview records the outcome of the manual review of a MANUAL
+// check
+type ManualCheckReview struct {
+	// Who reviewed the check
+	Reviewer string `json:"reviewer"`
+	// When the check was reviewed
+	Timestamp metav1.Time `json:"timestamp"`
+	// Whether the check passed or failed the review
+	// +kubebuilder:validation:Enum=PASS;FAIL
+	Outcome ComplianceCheckStatus `json:"outcome"`
+	// Notes on the review, e.g. the evidence that was inspected
+	// +optional
+	Notes string `json:"notes,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // ComplianceCheckResult represent a result of a single compliance "test"
 // +kubebuilder:resource:path=compliancecheckresults,scope=Namespaced,shortName=ccr;checkresults;checkresult
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.status`
 // +kubebuilder:printcolumn:name="Severity",type="string",JSONPath=`.severity`
+// +kubebuilder:printcolumn:name="Remediations",type="string",JSONPath=`.remediations`,priority=1
 type ComplianceCheckResult struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -95,6 +169,61 @@ type ComplianceCheckResult struct {
 	Warnings []string `json:"warnings,omitempty"`
 	// It stores a list of values used by the check
 	ValuesUsed []string `json:"valuesUsed,omitempty"`
+	// The statuses of the check in the latest runs of its scan, newest
+	// first, as many as the checkResultHistory of the scan keeps
+	// +optional
+	History []CheckResultHistoryEntry `json:"history,omitempty"`
+	// An excerpt of what the scanner collected for a failing check, showing
+	// why it failed. It's only kept if the failureEvidence of the scan is
+	// set.
+	// +optional
+	Evidence string `json:"evidence,omitempty"`
+	// The manual review of a MANUAL check, which is set by the reviewer.
+	// It's kept across the runs of the scan for as long as the check stays
+	// MANUAL.
+	// +optional
+	Review *ManualCheckReview `json:"review,omitempty"`
+	// The names of the ComplianceRemediations that fix the check, in the
+	// namespace of the check
+	// +listType=atomic
+	// +optional
+	Remediations []string `json:"remediations,omitempty"`
+	// Why the check is NOT-APPLICABLE, so that checks that genuinely don't
+	// apply can be told apart from checks that are selected for a scan
+	// that doesn't target what they apply to
+	// +optional
+	NotApplicableReason *NotApplicableReason `json:"notApplicableReason,omitempty"`
+}
+
+// AddToHistory records the status of the check in the current run of its
+// scan on top of the history of the previous runs, keeping at most
+// maxEntries entries
+func (r *ComplianceCheckResult) AddToHistory(previous []CheckResultHistoryEntry, timestamp metav1.Time, maxEntries int) {
+	if maxEntries <= 0 {
+		r.History = nil
+		return
+	}
+	history := make([]CheckResultHistoryEntry, 0, maxEntries)
+	history = append(history, CheckResultHistoryEntry{Status: r.Status, Timestamp: timestamp})
+	for _, entry := range previous {
+		if len(history) == maxEntries {
+			break
+		}
+		history = append(history, entry)
+	}
+	r.History = history
+}
+
+// GetLastReported returns when the run of the scan that last reported the
+// result started, falling back to when the result was created for results
+// that predate the annotation
+func (r *ComplianceCheckResult) GetLastReported() time.Time {
+	if raw, ok := r.Annotations[ComplianceCheckResultLastReportedAnnotation]; ok {
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t
+		}
+	}
+	return r.CreationTimestamp.Time
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing ComplianceCheckResult API", func() {
+	When("recording the history of a check", func() {
+		var (
+			result   *ComplianceCheckResult
+			previous []CheckResultHistoryEntry
+			now      metav1.Time
+		)
+
+		BeforeEach(func() {
+			now = metav1.NewTime(time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC))
+			result = &ComplianceCheckResult{Status: CheckResultFail}
+			previous = []CheckResultHistoryEntry{
+				{Status: CheckResultPass, Timestamp: metav1.NewTime(now.Add(-24 * time.Hour))},
+				{Status: CheckResultFail, Timestamp: metav1.NewTime(now.Add(-48 * time.Hour))},
+				{Status: CheckResultPass, Timestamp: metav1.NewTime(now.Add(-72 * time.Hour))},
+			}
+		})
+
+		It("puts the current run first", func() {
+			result.AddToHistory(previous, now, 10)
+			Expect(result.History).To(HaveLen(4))
+			Expect(result.History[0]).To(Equal(CheckResultHistoryEntry{Status: CheckResultFail, Timestamp: now}))
+			Expect(result.History[1:]).To(Equal(previous))
+		})
+
+		It("drops the oldest runs", func() {
+			result.AddToHistory(previous, now, 2)
+			Expect(result.History).To(HaveLen(2))
+			Expect(result.History[1]).To(Equal(previous[0]))
+		})
+
+		It("starts the history of new checks", func() {
+			result.AddToHistory(nil, now, 3)
+			Expect(result.History).To(ConsistOf(CheckResultHistoryEntry{Status: CheckResultFail, Timestamp: now}))
+		})
+
+		It("keeps no history when disabled", func() {
+			result.AddToHistory(previous, now, 0)
+			Expect(result.History).To(BeNil())
+		})
+	})
+
+	When("getting when a check was last reported", func() {
+		created := metav1.NewTime(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+
+		It("uses the run that last reported it", func() {
+			result := &ComplianceCheckResult{ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: created,
+				Annotations: map[string]string{
+					ComplianceCheckResultLastReportedAnnotation: "2026-10-14T01:00:00Z",
+				},
+			}}
+			Expect(result.GetLastReported()).To(Equal(time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC)))
+		})
+
+		It("falls back to when the result was created", func() {
+			result := &ComplianceCheckResult{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}
+			Expect(result.GetLastReported()).To(Equal(created.Time))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package v1alpha1
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComplianceCheckResultDiffPhase is the phase of a ComplianceCheckResultDiff
+type ComplianceCheckResultDiffPhase string
+
+const (
+	// CheckResultDiffPending means that the scan isn't done with one of the
+	// runs that are compared yet
+	CheckResultDiffPending ComplianceCheckResultDiffPhase = "Pending"
+	// CheckResultDiffDone means that the results of the runs were compared
+	CheckResultDiffDone ComplianceCheckResultDiffPhase = "Done"
+	// CheckResultDiffError means that the runs can't be compared, e.g.
+	// because the results of one of them weren't kept
+	CheckResultDiffError ComplianceCheckResultDiffPhase = "Error"
+)
+
+// ComplianceCheckResultDiffSpec defines which runs of a scan are compared
+// +k8s:openapi-gen=true
+type ComplianceCheckResultDiffSpec struct {
+	// The name of the ComplianceScan, in the namespace of the diff, whose
+	// runs are compared
+	ScanName string `json:"scanName"`
+	// The index of the run the results are compared against, as reported
+	// in the currentIndex of the scan status while the run was going on
+	// +kubebuilder:validation:Minimum=0
+	BaseIndex int64 `json:"baseIndex"`
+	// The index of the run whose results are compared
+	// +kubebuilder:validation:Minimum=0
+	TargetIndex int64 `json:"targetIndex"`
+}
+
+// CheckResultChange is the change of the result of a check between two runs
+// of a scan
+// +k8s:openapi-gen=true
+type CheckResultChange struct {
+	// The name of the ComplianceCheckResult
+	Name string `json:"name"`
+	// The result of the check in the base run, empty if it wasn't checked
+	// +optional
+	BaseStatus ComplianceCheckStatus `json:"baseStatus,omitempty"`
+	// The result of the check in the target run
+	TargetStatus ComplianceCheckStatus `json:"targetStatus"`
+}
+
+// ComplianceCheckResultDiffStatus defines the observed state of
+// ComplianceCheckResultDiff
+// +k8s:openapi-gen=true
+type ComplianceCheckResultDiffStatus struct {
+	// +optional
+	Phase ComplianceCheckResultDiffPhase `json:"phase,omitempty"`
+	// The checks that fail in the target run and weren't checked in the
+	// base run
+	// +listType=atomic
+	// +optional
+	NewFailures []CheckResultChange `json:"newFailures,omitempty"`
+	// The checks that didn't fail in the base run and fail in the target run
+	// +listType=atomic
+	// +optional
+	Regressions []CheckResultChange `json:"regressions,omitempty"`
+	// The checks that failed in the base run and pass in the target run
+	// +listType=atomic
+	// +optional
+	Fixes []CheckResultChange `json:"fixes,omitempty"`
+	// The amount of checks of the target run whose result didn't change
+	// +optional
+	Unchanged int `json:"unchanged,omitempty"`
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceCheckResultDiff compares the results of the checks of two runs of
+// a ComplianceScan, e.g. to gate changes to the cluster on them not failing
+// any new checks
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=compliancecheckresultdiffs,scope=Namespaced,shortName=ccrdiff
+// +kubebuilder:printcolumn:name="Scan",type="string",JSONPath=`.spec.scanName`
+// +kubebuilder:printcolumn:name="Base",type="integer",JSONPath=`.spec.baseIndex`
+// +kubebuilder:printcolumn:name="Target",type="integer",JSONPath=`.spec.targetIndex`
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+type ComplianceCheckResultDiff struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ComplianceCheckResultDiffSpec `json:"spec,omitempty"`
+	// +optional
+	Status ComplianceCheckResultDiffStatus `json:"status,omitempty"`
+}
+
+// IsFinished tells whether the runs were compared, successfully or not
+func (d *ComplianceCheckResultDiff) IsFinished() bool {
+	return d.Status.Phase == CheckResultDiffDone || d.Status.Phase == CheckResultDiffError
+}
+
+// CompareResults fills in the status with the changes of the results of the
+// checks from the base to the target run, as given by check name. Checks
+// that the target run didn't check, e.g. because they were removed from the
+// profile, aren't accounted for.
+func (s *ComplianceCheckResultDiffStatus) CompareResults(base, target map[string]ComplianceCheckStatus) {
+	s.NewFailures = nil
+	s.Regressions = nil
+	s.Fixes = nil
+	s.Unchanged = 0
+
+	names := make([]string, 0, len(target))
+	for name := range target {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		current := target[name]
+		previous, checked := base[name]
+		change := CheckResultChange{Name: name, BaseStatus: previous, TargetStatus: current}
+		switch {
+		case previous == current:
+			s.Unchanged++
+		case current == CheckResultFail && !checked:
+			s.NewFailures = append(s.NewFailures, change)
+		case current == CheckResultFail:
+			s.Regressions = append(s.Regressions, change)
+		case previous == CheckResultFail && current == CheckResultPass:
+			s.Fixes = append(s.Fixes, change)
+		}
+	}
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceCheckResultDiffList contains a list of ComplianceCheckResultDiff
+type ComplianceCheckResultDiffList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ComplianceCheckResultDiff `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ComplianceCheckResultDiff{}, &ComplianceCheckResultDiffList{})
+}
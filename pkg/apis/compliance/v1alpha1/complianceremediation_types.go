@@ -21,6 +21,10 @@ const (
 	RemediationError               RemediationApplicationState = "Error"
 	RemediationMissingDependencies RemediationApplicationState = "MissingDependencies"
 	RemediationNeedsReview         RemediationApplicationState = "NeedsReview"
+	RemediationScheduled           RemediationApplicationState = "Scheduled"
+	RemediationConflicting         RemediationApplicationState = "Conflicting"
+	RemediationPendingApproval     RemediationApplicationState = "PendingApproval"
+	RemediationRollingOut          RemediationApplicationState = "RollingOut"
 )
 
 // +kubebuilder:validation:Enum=Configuration;Enforcement
@@ -35,6 +39,9 @@ const (
 	RemediationEnforcementEmpty string = ""
 	RemediationEnforcementOff   string = "off"
 	RemediationEnforcementAll   string = "all"
+	// The enforcement type of the remediations that prevent non-compliant
+	// objects from being admitted through ValidatingAdmissionPolicies
+	RemediationEnforcementAdmission string = "admission"
 )
 
 const (
@@ -45,6 +52,9 @@ const (
 const (
 	// OutdatedRemediationLabel specifies that the remediation has been superseded by a newer version.
 	OutdatedRemediationLabel = "complianceoperator.openshift.io/outdated-remediation"
+	// ObsoleteRemediationLabel specifies that the check of the remediation
+	// kept passing without the remediation, so that it can be pruned
+	ObsoleteRemediationLabel = "compliance.openshift.io/obsolete-remediation"
 	// RemediationHasUnmetDependenciesLabel specifies that a remediation has unmet dependencies
 	// and thus cannot be applied.
 	RemediationHasUnmetDependenciesLabel = "compliance.openshift.io/has-unmet-dependencies"
@@ -90,6 +100,18 @@ const (
 	// K8SVersionDependencyAnnotation specifies that the k8s cluster needs to fall
 	// into a range in order to be applied
 	K8SVersionDependencyAnnotation = "compliance.openshift.io/k8s-version"
+	// RemediationBatchedAnnotation lists the remediations that a composite
+	// MachineConfig or a managed KubeletConfig was built from
+	RemediationBatchedAnnotation = "compliance.openshift.io/batched-remediations"
+	// RemediationPassingRunsAnnotation counts the consecutive runs the check
+	// of a remediation that isn't applied passed, for pruning the remediation
+	RemediationPassingRunsAnnotation = "compliance.openshift.io/passing-runs"
+	// RemediationRebootRequiredAnnotation tells whether applying the
+	// remediation reboots the nodes of its pool, either "true" or "false"
+	RemediationRebootRequiredAnnotation = "compliance.openshift.io/reboot-required"
+	// RemediationApplyRequesterAnnotation is set by the operator's webhook
+	// to the user that set the remediation to be applied, as long as it is
+	RemediationApplyRequesterAnnotation = "compliance.openshift.io/apply-requested-by"
 )
 
 var (
@@ -112,8 +134,59 @@ type ComplianceRemediationSpecMeta struct {
 	// stays in compliance via means of authorization.
 	// +kubebuilder:default="Configuration"
 	Type RemediationType `json:"type,omitempty"`
+	// Whether the operator should preview the remediation while it's not
+	// applied. The object the remediation would apply is rendered and
+	// validated through a server-side dry-run, and the result is put in
+	// the status without applying anything.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Contains the names of the remediations in the same namespace that
+	// need to be applied before this one, e.g. a remediation that enables
+	// a feature gate the object of this remediation relies on. The
+	// remediation stays Pending until they are applied.
+	// +listType=atomic
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Whether the MachineConfig of the remediation is applied as part of a
+	// single composite MachineConfig per pool, along with the other
+	// remediations of the pool that are batched, so that the pool only
+	// rolls out once. This is ignored for other kinds of remediations.
+	// +optional
+	Batch bool `json:"batch,omitempty"`
+	// The time before which the remediation isn't applied, even if apply
+	// is set. The remediation is Scheduled until then.
+	// +optional
+	ApplyAfter *metav1.Time `json:"applyAfter,omitempty"`
+	// Restricts when the object of the remediation is created or updated
+	// once apply is set. The remediation is Scheduled until the window
+	// opens. Un-applying the remediation isn't restricted.
+	// +optional
+	ApplyWindow *RemediationApplyWindow `json:"applyWindow,omitempty"`
+	// Defines how the object of the remediation is applied. Merge creates
+	// the object or merges it into the existing one. ServerSideApply
+	// applies the object through server-side apply with a dedicated field
+	// manager, reports the fields that are managed by others as conflicts
+	// and un-applies the remediation by only removing the fields it
+	// manages. Batched MachineConfigs and managed KubeletConfigs are always
+	// merged.
+	// +kubebuilder:default=Merge
+	// +optional
+	ApplyMethod RemediationApplyMethod `json:"applyMethod,omitempty"`
 }
 
+// RemediationApplyMethod defines how the object of a remediation is applied
+// +kubebuilder:validation:Enum=Merge;ServerSideApply
+type RemediationApplyMethod string
+
+const (
+	// RemediationApplyMerge creates the object of the remediation or
+	// merges it into the existing object
+	RemediationApplyMerge RemediationApplyMethod = "Merge"
+	// RemediationApplyServerSide applies the object of the remediation
+	// through server-side apply
+	RemediationApplyServerSide RemediationApplyMethod = "ServerSideApply"
+)
+
 type ComplianceRemediationPayload struct {
 	// The remediation payload. This would normally be a full Kubernetes
 	// object.
@@ -154,6 +227,116 @@ type ComplianceRemediationStatus struct {
 	// +kubebuilder:default="NotApplied"
 	ApplicationState RemediationApplicationState `json:"applicationState,omitempty"`
 	ErrorMessage     string                      `json:"errorMessage,omitempty"`
+	// Whether applying the remediation reboots the nodes of its pool, as
+	// MachineConfigs generally do
+	// +optional
+	RebootRequired bool `json:"rebootRequired"`
+	// The name of the ComplianceCheckResult the remediation fixes, in the
+	// namespace of the remediation
+	// +optional
+	CheckResult string `json:"checkResult,omitempty"`
+	// Contains the preview of the remediation if dryRun is set
+	// +optional
+	DryRun *ComplianceRemediationDryRunStatus `json:"dryRun,omitempty"`
+	// Lists the fields of the existing object the remediation targets that
+	// applying the remediation would change, while it isn't applied
+	// +listType=atomic
+	// +optional
+	Diff []ComplianceRemediationFieldDiff `json:"diff,omitempty"`
+	// Contains the object of the remediation as it was before the
+	// remediation was applied, if the object already existed. The fields
+	// the remediation changed are restored from it when the remediation is
+	// un-applied.
+	// +optional
+	PriorState ComplianceRemediationPayload `json:"priorState,omitempty"`
+	// Describes how the object of the remediation was rolled back the last
+	// time the remediation was un-applied
+	// +optional
+	LastRollback *ComplianceRemediationRollbackStatus `json:"lastRollback,omitempty"`
+	// Tracks how far the MachineConfig of an applied remediation is rolled
+	// out to the nodes of its pool
+	// +optional
+	Rollout *ComplianceRemediationRolloutStatus `json:"rollout,omitempty"`
+	// Lists the attempts to reconcile the object of the remediation that
+	// failed in a row with a transient error, e.g. a conflict or a webhook
+	// timeout. The list is cleared once reconciling the object succeeds.
+	// +listType=atomic
+	// +optional
+	FailedAttempts []ComplianceRemediationAttempt `json:"failedAttempts,omitempty"`
+}
+
+// ComplianceRemediationAttempt describes a failed attempt to reconcile the
+// object of a remediation
+// +k8s:openapi-gen=true
+type ComplianceRemediationAttempt struct {
+	// When the attempt was made
+	Time metav1.Time `json:"time"`
+	// The error the attempt failed with
+	Error string `json:"error"`
+}
+
+// ComplianceRemediationRolloutStatus describes the rollout of the
+// MachineConfig of a remediation to the nodes of its MachineConfigPool
+// +k8s:openapi-gen=true
+type ComplianceRemediationRolloutStatus struct {
+	// The MachineConfigPool the MachineConfig is rolled out to
+	Pool string `json:"pool"`
+	// The number of nodes of the pool that run a configuration that
+	// contains the MachineConfig
+	UpdatedNodes int32 `json:"updatedNodes"`
+	// The number of nodes of the pool
+	TotalNodes int32 `json:"totalNodes"`
+}
+
+// IsDone tells whether all the nodes of the pool run the MachineConfig
+func (r *ComplianceRemediationRolloutStatus) IsDone() bool {
+	return r.UpdatedNodes >= r.TotalNodes
+}
+
+// RemediationRollbackAction describes how the object of a remediation was
+// rolled back
+type RemediationRollbackAction string
+
+const (
+	// RemediationRollbackDeleted means that the object was deleted, as
+	// the operator had created it
+	RemediationRollbackDeleted RemediationRollbackAction = "Deleted"
+	// RemediationRollbackRestored means that the fields the remediation
+	// changed were restored to their prior state
+	RemediationRollbackRestored RemediationRollbackAction = "Restored"
+)
+
+// ComplianceRemediationRollbackStatus describes the rollback of the object
+// of a remediation that was un-applied
+// +k8s:openapi-gen=true
+type ComplianceRemediationRollbackStatus struct {
+	// How the object was rolled back
+	Action RemediationRollbackAction `json:"action"`
+	// When the object was rolled back
+	Time metav1.Time `json:"time"`
+}
+
+// ComplianceRemediationFieldDiff describes a field of an existing object that
+// applying a remediation would change
+// +k8s:openapi-gen=true
+type ComplianceRemediationFieldDiff struct {
+	// The path of the field, e.g. '.data.key'
+	Path string `json:"path"`
+	// The current value of the field in JSON, empty if the field isn't set
+	// +optional
+	Current string `json:"current,omitempty"`
+	// The value the remediation sets the field to in JSON
+	Desired string `json:"desired"`
+}
+
+// ComplianceRemediationDryRunStatus contains the preview of a remediation
+// that isn't applied
+// +k8s:openapi-gen=true
+type ComplianceRemediationDryRunStatus struct {
+	// The object the remediation would apply, in YAML
+	RenderedObject string `json:"renderedObject,omitempty"`
+	// The error the server-side dry-run of the object returned, if any
+	ValidationError string `json:"validationError,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -164,6 +347,8 @@ type ComplianceRemediationStatus struct {
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=complianceremediations,scope=Namespaced,shortName=cr;remediations;remediation;rems
 // +kubebuilder:printcolumn:name="State",type="string",JSONPath=`.status.applicationState`
+// +kubebuilder:printcolumn:name="Reboot",type="boolean",JSONPath=`.status.rebootRequired`
+// +kubebuilder:printcolumn:name="Check",type="string",JSONPath=`.status.checkResult`,priority=1
 type ComplianceRemediation struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -186,6 +371,16 @@ func (r *ComplianceRemediation) GetScan() string {
 	return r.Labels[ComplianceScanLabel]
 }
 
+// GetCheckResultName returns the name of the ComplianceCheckResult the
+// remediation fixes, which owns it, or an empty string if it has none
+func (r *ComplianceRemediation) GetCheckResultName() string {
+	owner := metav1.GetControllerOf(r)
+	if owner == nil || owner.Kind != "ComplianceCheckResult" {
+		return ""
+	}
+	return owner.Name
+}
+
 func (r *ComplianceRemediation) GetMcName() string {
 	if r.GetScan() == "" {
 		return ""
@@ -220,8 +415,9 @@ func (r *ComplianceRemediation) IsApplied() bool {
 	applied := r.Status.ApplicationState == RemediationApplied
 	outDatedButApplied := r.Spec.Apply && r.Status.ApplicationState == RemediationOutdated
 	appliedButUnmet := r.Spec.Apply && r.Status.ApplicationState == RemediationMissingDependencies
+	appliedButRollingOut := r.Spec.Apply && r.Status.ApplicationState == RemediationRollingOut
 
-	return applied || outDatedButApplied || appliedButUnmet
+	return applied || outDatedButApplied || appliedButUnmet || appliedButRollingOut
 }
 
 func (r *ComplianceRemediation) HasUnmetDependencies() bool {
@@ -105,4 +105,23 @@ var _ = Describe("Testing ComplianceRemediation API", func() {
 			Expect(err).To(MatchError(KubeDepsNotFound))
 		})
 	})
+
+	When("getting the check of a remediation", func() {
+		It("returns the name of the check that owns it", func() {
+			isController := true
+			rem := &ComplianceRemediation{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "ComplianceScan", Name: "ocp4-cis"},
+						{Kind: "ComplianceCheckResult", Name: "ocp4-cis-audit", Controller: &isController},
+					},
+				},
+			}
+			Expect(rem.GetCheckResultName()).To(Equal("ocp4-cis-audit"))
+		})
+		It("returns an empty string if no check owns it", func() {
+			rem := &ComplianceRemediation{}
+			Expect(rem.GetCheckResultName()).To(BeEmpty())
+		})
+	})
 })
@@ -16,6 +16,26 @@ import (
 // should be re-run
 const ComplianceScanRescanAnnotation = "compliance.openshift.io/rescan"
 
+// ComplianceScanRescanNodeAnnotation indicates that a single node of a
+// ComplianceScan should be re-run. The value is the name of the node.
+const ComplianceScanRescanNodeAnnotation = "compliance.openshift.io/rescan-node"
+
+// ComplianceScanPendingRescanAnnotation indicates that a ComplianceScan of a
+// suite running its scans serially should be re-run once the scans ahead
+// of it are done
+const ComplianceScanPendingRescanAnnotation = "compliance.openshift.io/pending-rescan"
+
+// ComplianceScanMachineConfigAnnotation keeps the rendered MachineConfig
+// the MachineConfigPool of the nodes of a ComplianceScan had when the nodes
+// were last scanned
+const ComplianceScanMachineConfigAnnotation = "compliance.openshift.io/scanned-machineconfig"
+
+// ComplianceScanRawResultsPrunedAnnotation indicates that the raw results of
+// the nodes of a ComplianceScan were pruned according to the retention policy
+// of its suite, so that its next run is a full scan rather than a rescan of a
+// single node or an incremental scan
+const ComplianceScanRawResultsPrunedAnnotation = "compliance.openshift.io/raw-results-pruned"
+
 // ComplianceScanTimeoutAnnotation indicates that a ComplianceScan
 // got a timeout, we will put the timeout node name in the annotation
 // if the scan is a node scan. If it's a platform scan, we will put
@@ -44,6 +64,10 @@ const ScanFinalizer = "scan.finalizers.compliance.openshift.io"
 const DefaultRawStorageSize = "1Gi"
 const DefaultStorageRotation = 3
 
+// DefaultObjectStorageRegion is the region the requests to the object
+// storage of the raw results are signed for if it doesn't set one
+const DefaultObjectStorageRegion = "us-east-1"
+
 var ErrUnkownScanType = errors.New("Unknown scan type")
 
 // Represents the status of the compliance scan run.
@@ -82,6 +106,10 @@ type ComplianceScanStatusResult string
 // CmScanResultAnnotation holds the processed scanner result
 const CmScanResultAnnotation = "compliance.openshift.io/scan-result"
 
+// CmRemediationsProcessedAnnotation indicates that the aggregator already
+// created the results and remediations out of a result ConfigMap
+const CmRemediationsProcessedAnnotation = "compliance-remediations/processed"
+
 // CmScanResultErrMsg holds the processed scanner error message
 const CmScanResultErrMsg = "compliance.openshift.io/scan-error-msg"
 
@@ -117,6 +145,12 @@ func resultCompare(lowResult ComplianceScanStatusResult, scanResult ComplianceSc
 	return lowResult
 }
 
+// WorseResult returns the worse of the two results, in the order the results
+// of the scans are aggregated in
+func WorseResult(result ComplianceScanStatusResult, other ComplianceScanStatusResult) ComplianceScanStatusResult {
+	return resultCompare(result, other)
+}
+
 // TailoringConfigMapRef is a reference to a ConfigMap that contains the
 // tailoring file. It assumes a key called `tailoring.xml` which will
 // have the tailoring contents.
@@ -143,6 +177,18 @@ type RawResultStorageSettings struct {
 	// policy of '0' disables rotation entirely. Defaults to 3.
 	// +kubebuilder:default=3
 	Rotation uint16 `json:"rotation,omitempty"`
+	// Specifies the amount of scan runs for which the raw results are kept.
+	// Takes precedence over rotation when set, and a value of '0' keeps the
+	// results of all the runs, e.g. to only prune them by their age.
+	// +optional
+	// +nullable
+	MaxRetainedScanRuns *uint16 `json:"maxRetainedScanRuns,omitempty"`
+	// Specifies for how long the raw results of a scan run are kept, e.g.
+	// '720h'. The results of the latest run are always kept. By default,
+	// the results aren't pruned by their age.
+	// +optional
+	// +nullable
+	MaxScanRunAge *metav1.Duration `json:"maxScanRunAge,omitempty"`
 	// Specifies the StorageClassName to use when creating the PersistentVolumeClaim
 	// to hold the raw results. By default this is null, which will attempt to use the
 	// default storage class configured in the cluster. If there is no default class specified
@@ -161,6 +207,159 @@ type RawResultStorageSettings struct {
 	// in case the target set of nodes have custom taints that don't allow certain
 	// workloads to run. Defaults to allowing scheduling on master nodes.
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Specifies an S3-compatible object storage bucket the raw results are
+	// uploaded to as the result server receives them, e.g. for long-term
+	// retention outside of the cluster.
+	// +optional
+	// +nullable
+	ObjectStorage *RawResultObjectStorage `json:"objectStorage,omitempty"`
+	// Doesn't create a PersistentVolumeClaim for the raw results. If there's
+	// an object storage, the raw results are only uploaded to it, as with
+	// its disablePersistentVolume. Otherwise, the raw results are discarded
+	// and there's no result server at all, the scans only keep the results
+	// of the checks.
+	// +optional
+	DisablePersistentVolume bool `json:"disablePersistentVolume,omitempty"`
+	// Specifies the codec the raw results are compressed with before
+	// they're uploaded to the result server, either 'bzip2' or 'zstd'.
+	// zstd compresses much faster and into smaller files. The upload falls
+	// back to bzip2 if the result server doesn't accept the codec. Defaults
+	// to bzip2.
+	// +kubebuilder:validation:Enum=bzip2;zstd
+	// +optional
+	Compression RawResultCompression `json:"compression,omitempty"`
+	// Specifies the zstd compression level, from '1' (the fastest) to '22'
+	// (the smallest). Defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=22
+	// +optional
+	CompressionLevel int `json:"compressionLevel,omitempty"`
+	// Specifies whether the result server keeps running once the scan is
+	// done, to serve the raw results of the stored scan runs over HTTPS.
+	// They can be downloaded with a client certificate of the scan, or with
+	// a bearer token that's allowed to get the 'compliancescans/rawresults'
+	// subresource of the scan.
+	// +optional
+	ServeRawResults bool `json:"serveRawResults,omitempty"`
+	// Specifies whether the HTML report of each scanned node, or of the
+	// platform, is generated from its ARF report and stored along with
+	// the raw results, so that it can be read without extracting the ARF
+	// report. Doesn't apply if the raw results are discarded.
+	// +optional
+	HTMLReport bool `json:"htmlReport,omitempty"`
+}
+
+// RawResultCompression is the codec the raw results are compressed with
+type RawResultCompression string
+
+const (
+	RawResultCompressionBzip2 RawResultCompression = "bzip2"
+	RawResultCompressionZstd  RawResultCompression = "zstd"
+)
+
+// DefaultZstdCompressionLevel is the level the raw results are compressed
+// with by zstd if none is given
+const DefaultZstdCompressionLevel = 3
+
+// ObjectStorageEncryption is the server-side encryption the objects are
+// stored with
+type ObjectStorageEncryption string
+
+const (
+	// The objects are encrypted with keys managed by the object storage
+	ObjectStorageEncryptionAES256 ObjectStorageEncryption = "AES256"
+	// The objects are encrypted with a key of the key management service
+	ObjectStorageEncryptionKMS ObjectStorageEncryption = "aws:kms"
+)
+
+const (
+	// ObjectStorageAccessKeyIDKey is the key of the credentials Secret of
+	// the object storage that holds the access key ID
+	ObjectStorageAccessKeyIDKey = "aws_access_key_id"
+	// ObjectStorageSecretAccessKeyKey is the key of the credentials Secret
+	// of the object storage that holds the secret access key
+	ObjectStorageSecretAccessKeyKey = "aws_secret_access_key"
+)
+
+// RawResultObjectStorage defines the S3-compatible object storage the raw
+// results are uploaded to
+type RawResultObjectStorage struct {
+	// The URL of the S3-compatible endpoint, e.g.
+	// 'https://s3.eu-west-1.amazonaws.com'. The buckets are addressed by
+	// path rather than by host name.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+	// The region of the bucket, which the requests are signed for
+	// +kubebuilder:default="us-east-1"
+	// +optional
+	Region string `json:"region,omitempty"`
+	// The name of the bucket
+	Bucket string `json:"bucket"`
+	// The prefix of the keys of the uploaded results. The results are
+	// uploaded as '<prefix><scan>/<index>/<file>', where index is the index
+	// of the run of the scan.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// The name of the Secret, in the namespace of the operator, that holds
+	// the credentials to upload the results with, in its
+	// 'aws_access_key_id' and 'aws_secret_access_key' keys
+	CredentialsSecretName string `json:"credentialsSecretName"`
+	// The server-side encryption the results are stored with, either
+	// 'AES256' or 'aws:kms'. By default, the default encryption of the
+	// bucket applies.
+	// +kubebuilder:validation:Enum=AES256;"aws:kms"
+	// +optional
+	ServerSideEncryption ObjectStorageEncryption `json:"serverSideEncryption,omitempty"`
+	// The ID of the key management service key the results are encrypted
+	// with if the serverSideEncryption is 'aws:kms'. By default, the default
+	// key of the bucket is used.
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+	// Only uploads the raw results instead of also storing them in a
+	// PersistentVolume. The result server then keeps the results of the
+	// current run in an emptyDir volume, so they're lost if the upload
+	// fails.
+	// +optional
+	DisablePersistentVolume bool `json:"disablePersistentVolume,omitempty"`
+}
+
+// GetRegion returns the region the requests to the object storage are
+// signed for
+func (s *RawResultObjectStorage) GetRegion() string {
+	if s.Region == "" {
+		return DefaultObjectStorageRegion
+	}
+	return s.Region
+}
+
+// UsesPersistentVolume tells whether the raw results are stored in a
+// PersistentVolume
+func (s *RawResultStorageSettings) UsesPersistentVolume() bool {
+	if s.DisablePersistentVolume {
+		return false
+	}
+	return s.ObjectStorage == nil || !s.ObjectStorage.DisablePersistentVolume
+}
+
+// GeneratesHTMLReports tells whether the HTML reports are generated and
+// stored along with the raw results
+func (s *RawResultStorageSettings) GeneratesHTMLReports() bool {
+	return s.HTMLReport && !s.DiscardsRawResults()
+}
+
+// DiscardsRawResults tells whether the raw results aren't stored anywhere,
+// in which case there's no result server to upload them to
+func (s *RawResultStorageSettings) DiscardsRawResults() bool {
+	return !s.UsesPersistentVolume() && s.ObjectStorage == nil
+}
+
+// GetRetainedScanRuns returns the amount of scan runs for which the raw
+// results are kept, where '0' keeps them all
+func (s *RawResultStorageSettings) GetRetainedScanRuns() uint16 {
+	if s.MaxRetainedScanRuns != nil {
+		return *s.MaxRetainedScanRuns
+	}
+	return s.Rotation
 }
 
 // ComplianceScanSettings groups together settings of a ComplianceScan
@@ -190,20 +389,61 @@ type ComplianceScanSettings struct {
 	// +kubebuilder:default=true
 	StrictNodeScan *bool `json:"strictNodeScan,omitempty"`
 
+	// MaxErroredNodesPercentage is the percentage of the scanned nodes whose
+	// scan may error out, e.g. because of a single flaky node, without the
+	// result of the scan being ERROR. The result is then computed from the
+	// nodes that were scanned successfully, the errored nodes are listed in
+	// the status and the scan is reported as Degraded. Defaults to '0',
+	// i.e. the scan errors out as soon as any of its nodes did.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxErroredNodesPercentage int `json:"maxErroredNodesPercentage,omitempty"`
+
 	// Specifies what to do with remediations of Enforcement type. If left empty,
 	// this defaults to "off" which doesn't create nor apply any enforcement remediations.
 	// If set to "all" this creates any enforcement remediations it encounters.
 	// Subsequently, this can also be set to a specific type. e.g. setting it to
 	// "gatekeeper" will apply any enforcement remediations relevant to the
-	// Gatekeeper OPA system.
+	// Gatekeeper OPA system, while "admission" applies the
+	// ValidatingAdmissionPolicies that are enforced by the API server itself.
 	// These objects will annotated in the content itself with:
 	//     complianceascode.io/enforcement-type: <type>
 	RemediationEnforcement string `json:"remediationEnforcement,omitempty"`
 
+	// RemediationPruning opts into cleaning up the remediations of checks
+	// that keep passing without the remediation being applied, e.g. because
+	// the issue was fixed manually. By default, such remediations are kept.
+	// +optional
+	RemediationPruning *RemediationPruningPolicy `json:"remediationPruning,omitempty"`
+
 	// Determines whether to hide or show results that are not applicable.
 	// +kubebuilder:default=false
 	ShowNotApplicable bool `json:"showNotApplicable,omitempty"`
 
+	// SeverityOverrides replaces the severity the content sets for some of
+	// the rules, e.g. to downgrade a rule that doesn't apply to the threat
+	// model of the environment. The severity of the checks of these rules
+	// is overridden, and the original severity is kept in the
+	// compliance.openshift.io/original-severity annotation.
+	// +listType=atomic
+	// +optional
+	SeverityOverrides []SeverityOverride `json:"severityOverrides,omitempty"`
+
+	// CheckResultHistory is the number of runs of each check whose status
+	// is kept in the history of its ComplianceCheckResult, e.g. to find the
+	// checks whose result keeps flapping. A value of '0' keeps no history.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CheckResultHistory int `json:"checkResultHistory,omitempty"`
+
+	// FailureEvidence has the failing checks of the scan include an excerpt
+	// of what the scanner collected, showing why they failed. No evidence
+	// is collected if it isn't set.
+	// +optional
+	FailureEvidence *FailureEvidenceSettings `json:"failureEvidence,omitempty"`
+
 	// Defines the PriorityClass to use for launching scan related pods,
 	// the Name of a desired PriorityClass should be set here, this is an
 	// optional field, if PriorityClass is invalid or not found, it will be ignored.
@@ -215,6 +455,45 @@ type ComplianceScanSettings struct {
 	// container).
 	ScanLimits map[corev1.ResourceName]resource.Quantity `json:"scanLimits,omitempty"`
 
+	// ScannerResources allows to set the resource requests and limits of the
+	// container that runs OpenSCAP in the scan pods. Limits set here take
+	// precedence over the ones set in ScanLimits. By default, the scanner
+	// container requests 50Mi memory and 10m CPU.
+	// +optional
+	ScannerResources *corev1.ResourceRequirements `json:"scannerResources,omitempty"`
+
+	// ScannerSecurityContext allows to set the pod-level security context of
+	// the scan pods, e.g. the user to run as, the seccomp profile or the
+	// SELinux options, so the pods are admitted on clusters with custom
+	// security constraints. Only the attributes that are set override the
+	// defaults. By default, platform scan pods run as a non-root user, and
+	// no seccomp profile is set. Note that node scans need to be able to
+	// read the file system of the host.
+	// +optional
+	ScannerSecurityContext *corev1.PodSecurityContext `json:"scannerSecurityContext,omitempty"`
+
+	// ScannerArgs is a list of additional arguments to pass to the
+	// `oscap xccdf eval` command run by the scanner, e.g. `--skip-valid`
+	// or `--verbose DEVEL`. Each item is passed as a single argument. Only
+	// the arguments that don't change the nodes or the results are allowed:
+	// --skip-valid, --skip-validation, --skip-signature-validation,
+	// --enforce-signature, --fetch-remote-resources, --progress,
+	// --progress-full, --thin-results, --without-syschar and --verbose.
+	// +optional
+	// +listType=atomic
+	ScannerArgs []string `json:"scannerArgs,omitempty"`
+
+	// ScannerImage overrides the image of the container that runs OpenSCAP
+	// in the scan pods of this scan only, e.g. to try out a patched build
+	// of the scanner. Pinning the image by digest, i.e.
+	// `registry/scanner@sha256:<digest>`, makes sure the exact same build
+	// is used every time. Since the scanner runs privileged on the nodes,
+	// only the images listed in the ALLOWED_SCANNER_IMAGES environment
+	// variable of the operator are accepted. Defaults to the scanner image
+	// of the operator.
+	// +optional
+	ScannerImage string `json:"scannerImage,omitempty"`
+
 	// Timeout is the maximum amount of time the scan can run. If the scan
 	// hasn't finished by then, it will be aborted.
 	// +kubebuilder:default="30m"
@@ -223,6 +502,211 @@ type ComplianceScanSettings struct {
 	// MaxRetryOnTimeout is the maximum number of times the scan will be retried if it times out.
 	// +kubebuilder:default=3
 	MaxRetryOnTimeout int `json:"maxRetryOnTimeout,omitempty"`
+
+	// MaxRetries is the maximum number of times scan pods that failed due to
+	// a transient issue, such as an image pull error or the node rebooting
+	// mid-scan, will be re-created during a scan. A value of '0' disables
+	// retries. Once there are no retries left, the scan of the node errors
+	// out with the failure of the pod.
+	// +kubebuilder:default=3
+	MaxRetries int `json:"maxRetries,omitempty"`
+
+	// RetryBackoff is the amount of time to wait between consecutive
+	// re-creations of failed scan pods. The wait time doubles with every
+	// retry, up to a maximum of 10 minutes.
+	// +kubebuilder:default="30s"
+	RetryBackoff string `json:"retryBackoff,omitempty"`
+
+	// MaxConcurrentNodes is the maximum number of nodes that a node scan
+	// will scan at the same time. Once a node is done, the scan moves on to
+	// the next one. A value of '0' means that all the nodes are scanned at
+	// the same time.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentNodes int `json:"maxConcurrentNodes,omitempty"`
+
+	// ScanWindow restricts when the scan is allowed to run, e.g. to the
+	// maintenance periods of the cluster. Runs of the scan that are
+	// requested outside of the window, whether they're the first run,
+	// scheduled or re-run manually, are queued up until it opens. A run
+	// that started within the window isn't stopped when the window closes.
+	// By default, the scan runs right away.
+	// +optional
+	ScanWindow *ScanWindow `json:"scanWindow,omitempty"`
+
+	// Incremental enables only evaluating the rules whose inputs changed
+	// since the last run of the scan. The results of the rest of the rules
+	// are kept from the previous run. This is only supported for Platform
+	// scans, Node scans always evaluate all the rules.
+	// +optional
+	Incremental bool `json:"incremental,omitempty"`
+
+	// ScannerScheduling specifies where the platform scan pods are
+	// scheduled. Node scan pods always run on the node they scan, so only
+	// the tolerations are used for them, and they take precedence over
+	// ScanTolerations.
+	// +optional
+	ScannerScheduling *WorkloadScheduling `json:"scannerScheduling,omitempty"`
+
+	// AggregatorScheduling specifies where the aggregator pods, which
+	// process the results of the scan, are scheduled. By default, these run
+	// on the control plane nodes.
+	// +optional
+	AggregatorScheduling *WorkloadScheduling `json:"aggregatorScheduling,omitempty"`
+
+	// ResultServerScheduling specifies where the result server pods, which
+	// store the raw results, are scheduled. The node selector and
+	// tolerations set here take precedence over the ones set in
+	// RawResultStorage.
+	// +optional
+	ResultServerScheduling *WorkloadScheduling `json:"resultServerScheduling,omitempty"`
+}
+
+// SeverityOverride sets the severity of the checks of a rule
+type SeverityOverride struct {
+	// The rule whose severity is overridden, referred to by the value of
+	// the compliance.openshift.io/rule annotation of its checks
+	Rule string `json:"rule"`
+	// The severity of the checks of the rule
+	// +kubebuilder:validation:Enum=unknown;info;low;medium;high
+	Severity ComplianceCheckResultSeverity `json:"severity"`
+	// Why the severity is overridden, which is kept in the
+	// compliance.openshift.io/severity-override-reason annotation of the
+	// checks
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// GetSeverityOverride returns the override of the severity of the given rule,
+// if there's one
+func (s *ComplianceScanSettings) GetSeverityOverride(rule string) *SeverityOverride {
+	for i := range s.SeverityOverrides {
+		if s.SeverityOverrides[i].Rule == rule {
+			return &s.SeverityOverrides[i]
+		}
+	}
+	return nil
+}
+
+// FailureEvidenceSettings configures the evidence that is kept with the
+// failing checks of a scan
+// +k8s:openapi-gen=true
+type FailureEvidenceSettings struct {
+	// The maximum size, in bytes, of the evidence of each check. Longer
+	// evidence is truncated.
+	// +kubebuilder:default=1024
+	// +kubebuilder:validation:Minimum=128
+	// +kubebuilder:validation:Maximum=16384
+	// +optional
+	MaxSize int `json:"maxSize,omitempty"`
+	// The names of the fields of the collected items whose values are
+	// redacted, in addition to the ones that look like they hold credentials
+	// +listType=atomic
+	// +optional
+	RedactFields []string `json:"redactFields,omitempty"`
+}
+
+// ScanWindow defines a recurring window of time during which scans are
+// allowed to run
+// +k8s:openapi-gen=true
+type ScanWindow struct {
+	// Defines when the window opens, e.g. '0 22 * * 1-5' for the weekdays
+	// at 22:00. This is in cronjob format, and is interpreted in the time
+	// zone of the operator unless it's prefixed with 'CRON_TZ=<zone> '.
+	Schedule string `json:"schedule"`
+	// Defines how long the window stays open, e.g. '4h'
+	Duration metav1.Duration `json:"duration"`
+}
+
+// +kubebuilder:validation:Enum=Delete;MarkObsolete
+type RemediationPruningAction string
+
+const (
+	// RemediationPruningDelete deletes the remediations that are pruned
+	RemediationPruningDelete RemediationPruningAction = "Delete"
+	// RemediationPruningMarkObsolete labels the remediations that are pruned
+	// with ObsoleteRemediationLabel, so that they can be reviewed first
+	RemediationPruningMarkObsolete RemediationPruningAction = "MarkObsolete"
+)
+
+// RemediationPruningPolicy specifies when and how the remediations of
+// passing checks are pruned
+type RemediationPruningPolicy struct {
+	// What to do with the remediations that are pruned
+	// +kubebuilder:default=MarkObsolete
+	Action RemediationPruningAction `json:"action,omitempty"`
+	// The number of consecutive runs the check of a remediation needs to
+	// pass for the remediation to be pruned
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	PassingRuns int `json:"passingRuns,omitempty"`
+}
+
+// DefaultRemediationPruningPassingRuns is the number of consecutive runs the
+// check of a remediation needs to pass by default for it to be pruned
+const DefaultRemediationPruningPassingRuns = 3
+
+// GetPassingRuns returns the number of consecutive runs the check of a
+// remediation needs to pass for the remediation to be pruned
+func (p *RemediationPruningPolicy) GetPassingRuns() int {
+	if p.PassingRuns < 1 {
+		return DefaultRemediationPruningPassingRuns
+	}
+	return p.PassingRuns
+}
+
+// WorkloadScheduling specifies where the pods of a scan workload are
+// scheduled
+type WorkloadScheduling struct {
+	// Is a selector which must match the labels of the nodes the pods run
+	// on. If set, it replaces the default node selector of the workload.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Are the tolerations of the pods. If set, these replace the default
+	// tolerations of the workload.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Are the affinity scheduling rules of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Describes how the pods ought to spread across topology domains.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// GetNodeSelector returns the node selector of the workload, or def if none
+// was set
+func (s *WorkloadScheduling) GetNodeSelector(def map[string]string) map[string]string {
+	if s == nil || len(s.NodeSelector) == 0 {
+		return def
+	}
+	return s.NodeSelector
+}
+
+// GetTolerations returns the tolerations of the workload, or def if none
+// were set
+func (s *WorkloadScheduling) GetTolerations(def []corev1.Toleration) []corev1.Toleration {
+	if s == nil || len(s.Tolerations) == 0 {
+		return def
+	}
+	return s.Tolerations
+}
+
+// GetAffinity returns the affinity of the workload, if any
+func (s *WorkloadScheduling) GetAffinity() *corev1.Affinity {
+	if s == nil {
+		return nil
+	}
+	return s.Affinity
+}
+
+// GetTopologySpreadConstraints returns the topology spread constraints of
+// the workload, if any
+func (s *WorkloadScheduling) GetTopologySpreadConstraints() []corev1.TopologySpreadConstraint {
+	if s == nil {
+		return nil
+	}
+	return s.TopologySpreadConstraints
 }
 
 // ComplianceScanSpec defines the desired state of ComplianceScan
@@ -240,6 +724,16 @@ type ComplianceScanSpec struct {
 	// rule. Note that when leaving this empty, the scan will check for all the
 	// rules for a specific profile.
 	Rule string `json:"rule,omitempty"`
+	// Is a list of rules that the scan should not check for. Like Rule,
+	// these are the IDs of the rules in the data stream. This allows skipping
+	// a handful of rules without having to create a TailoredProfile.
+	// +optional
+	ExcludeRules []string `json:"excludeRules,omitempty"`
+	// Is a list of names of CustomRules in the namespace of the operator
+	// that the scan checks on top of the rules of the profile. This is only
+	// supported for Platform scans, Node scans ignore them.
+	// +optional
+	CustomRules []string `json:"customRules,omitempty"`
 	// Is the path to the file that contains the content (the data stream).
 	// Note that the path needs to be relative to the `/` (root) directory, as
 	// it is in the ContentImage
@@ -249,14 +743,40 @@ type ComplianceScanSpec struct {
 	// scan, this should match the selector of the MachineConfigPool you want
 	// to apply the remediations to.
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Is a list of names of nodes the scan should run on. This is applied on
+	// top of the NodeSelector, so only the listed nodes that match the
+	// selector are scanned. This allows scanning a specific set of nodes
+	// without having to label them.
+	// +optional
+	NodeNames []string `json:"nodeNames,omitempty"`
 	// Is a reference to a ConfigMap that contains the
 	// tailoring file. It assumes a key called `tailoring.xml` which will
 	// have the tailoring contents.
 	TailoringConfigMap *TailoringConfigMapRef `json:"tailoringConfigMap,omitempty"`
+	// Are labels that are set on the ComplianceCheckResults and
+	// ComplianceRemediations of the scan, e.g. to filter them by team. The
+	// labels of the operator can't be overridden this way. The
+	// ScanSettingBinding controller sets these from the labels of the
+	// binding that its resultMetadataPropagation selects.
+	// +optional
+	ResultLabels map[string]string `json:"resultLabels,omitempty"`
+	// Are annotations that are set on the ComplianceCheckResults and
+	// ComplianceRemediations of the scan. Just like with resultLabels, the
+	// annotations of the operator can't be overridden this way.
+	// +optional
+	ResultAnnotations map[string]string `json:"resultAnnotations,omitempty"`
 
 	ComplianceScanSettings `json:",inline"`
 }
 
+// IsOperatorMetadataKey tells whether a label or annotation key belongs to
+// the operator, in which case it's never propagated onto the results of a
+// scan
+func IsOperatorMetadataKey(key string) bool {
+	return strings.HasPrefix(key, "compliance.openshift.io/") ||
+		strings.HasPrefix(key, "complianceoperator.openshift.io/")
+}
+
 // ComplianceScanStatus defines the observed state of ComplianceScan
 type ComplianceScanStatus struct {
 	// Is the phase where the scan is at. Normally, one must wait for the scan
@@ -280,12 +800,221 @@ type ComplianceScanStatus struct {
 	Warnings string `json:"warnings,omitempty"`
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+	// The generation of the scan the status was last updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	//Is the number of retries left for the scan on timeout
 	RemainingRetries int `json:"remainingRetries,omitempty"`
+	// Is the number of times scan pods were re-created due to transient
+	// failures during the current run of the scan
+	ScanPodRetries int `json:"scanPodRetries,omitempty"`
+	// Is the time when a scan pod was last re-created due to a transient
+	// failure
+	LastScanPodRetryTimestamp *metav1.Time `json:"lastScanPodRetryTimestamp,omitempty"`
+	// Is the progress of a node scan, i.e. how many of the nodes it's done
+	// scanning and the phase of the scan in each node
+	// +optional
+	Progress *ScanProgress `json:"progress,omitempty"`
+	// Is the name of the node that was rescanned if only a single node of
+	// the scan was re-run. The results of the rest of the nodes are kept
+	// from the previous run.
+	// +optional
+	RescanNode string `json:"rescanNode,omitempty"`
+	// Contains the nodes whose results were already collected in the
+	// current run of the scan. These nodes are not scanned again if the
+	// scan pods need to be re-created, e.g. after the operator restarted
+	// while the scan was running.
+	// +optional
+	// +listType=atomic
+	CompletedNodes []string `json:"completedNodes,omitempty"`
+	// Contains the nodes whose scan errored out and whose results were
+	// left out of the result of the scan, as allowed by
+	// maxErroredNodesPercentage
+	// +optional
+	// +listType=atomic
+	ErroredNodes []string `json:"erroredNodes,omitempty"`
+	// Describes how the results of the checks changed compared to the
+	// previous run of the scan. This is not set on the first run.
+	// +optional
+	ResultDiff *ScanResultDiff `json:"resultDiff,omitempty"`
+	// Contains the amounts of checks of the last run of the scan by their
+	// result and severity
+	// +optional
+	ResultCounts *ResultCounts `json:"resultCounts,omitempty"`
 	// Is the time when the scan was started
 	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
 	// Is the time when the scan was finished
 	EndTimestamp *metav1.Time `json:"endTimestamp,omitempty"`
+	// Is the time the scan window opens at, if the scan is queued up
+	// until then because it was requested to run outside of its window
+	// +optional
+	QueuedUntil *metav1.Time `json:"queuedUntil,omitempty"`
+}
+
+// NodeScanPhase is the phase of a node scan in a single node
+type NodeScanPhase string
+
+const (
+	// NodeScanPhaseWaiting means the scan pod wasn't launched yet, e.g.
+	// because of maxConcurrentNodes
+	NodeScanPhaseWaiting NodeScanPhase = "Waiting"
+	// NodeScanPhasePending means the scan pod was launched but isn't
+	// running yet
+	NodeScanPhasePending NodeScanPhase = "Pending"
+	// NodeScanPhaseRunning means the scan pod is running
+	NodeScanPhaseRunning NodeScanPhase = "Running"
+	// NodeScanPhaseDone means the scan pod finished
+	NodeScanPhaseDone NodeScanPhase = "Done"
+	// NodeScanPhaseFailed means the scan pod failed
+	NodeScanPhaseFailed NodeScanPhase = "Failed"
+	// NodeScanPhaseUnschedulable means the scan pod couldn't be scheduled
+	NodeScanPhaseUnschedulable NodeScanPhase = "Unschedulable"
+)
+
+// IsFinished returns whether the scan in the node no longer takes up
+// resources, either because it's over or because it couldn't be scheduled
+func (p NodeScanPhase) IsFinished() bool {
+	return p == NodeScanPhaseDone || p == NodeScanPhaseFailed || p == NodeScanPhaseUnschedulable
+}
+
+// NodeScanProgress is the progress of a node scan in a single node
+type NodeScanProgress struct {
+	// Is the name of the node
+	Node string `json:"node"`
+	// Is the phase of the scan in the node
+	Phase NodeScanPhase `json:"phase"`
+}
+
+// ScanProgress is the progress of a node scan
+type ScanProgress struct {
+	// Is the number of nodes that the scan targets
+	NodesTotal int `json:"nodesTotal"`
+	// Is the number of nodes that the scan is done scanning
+	NodesCompleted int `json:"nodesCompleted"`
+	// Is a short summary of the progress, e.g. "3/10"
+	Summary string `json:"summary,omitempty"`
+	// Is the phase of the scan in each of the nodes
+	// +optional
+	Nodes []NodeScanProgress `json:"nodes,omitempty"`
+}
+
+// MaxResultDiffChangedChecks is the maximum number of changed checks that
+// are listed in the result diff of a scan
+const MaxResultDiffChangedChecks = 50
+
+// ScanResultDiff describes how the results of a scan changed compared to the
+// previous run of the scan
+type ScanResultDiff struct {
+	// Is the number of checks that fail now, but didn't fail in the previous
+	// run
+	NewlyFailing int `json:"newlyFailing"`
+	// Is the number of checks that pass now, but didn't pass in the
+	// previous run
+	NewlyPassing int `json:"newlyPassing"`
+	// Is the number of checks whose result didn't change
+	Unchanged int `json:"unchanged"`
+	// Are the names of the ComplianceCheckResults whose result changed. At
+	// most MaxResultDiffChangedChecks names are listed.
+	// +optional
+	ChangedChecks []string `json:"changedChecks,omitempty"`
+}
+
+// CheckStatusCounts are the amounts of checks with each result
+type CheckStatusCounts struct {
+	// +optional
+	Pass int `json:"pass,omitempty"`
+	// +optional
+	Fail int `json:"fail,omitempty"`
+	// +optional
+	Error int `json:"error,omitempty"`
+	// +optional
+	Manual int `json:"manual,omitempty"`
+	// +optional
+	NotApplicable int `json:"notApplicable,omitempty"`
+	// +optional
+	Info int `json:"info,omitempty"`
+	// +optional
+	Inconsistent int `json:"inconsistent,omitempty"`
+}
+
+// add counts a check with the given result
+func (c *CheckStatusCounts) add(status ComplianceCheckStatus) {
+	switch status {
+	case CheckResultPass:
+		c.Pass++
+	case CheckResultFail:
+		c.Fail++
+	case CheckResultError:
+		c.Error++
+	case CheckResultManual:
+		c.Manual++
+	case CheckResultNotApplicable:
+		c.NotApplicable++
+	case CheckResultInfo:
+		c.Info++
+	case CheckResultInconsistent:
+		c.Inconsistent++
+	}
+}
+
+func (c *CheckStatusCounts) merge(other CheckStatusCounts) {
+	c.Pass += other.Pass
+	c.Fail += other.Fail
+	c.Error += other.Error
+	c.Manual += other.Manual
+	c.NotApplicable += other.NotApplicable
+	c.Info += other.Info
+	c.Inconsistent += other.Inconsistent
+}
+
+// ResultCounts are the amounts of checks by their result, in total and
+// for each severity
+type ResultCounts struct {
+	// Is the total amount of checks
+	Total int `json:"total"`
+	// Are the amounts of all the checks by their result
+	Results CheckStatusCounts `json:"results"`
+	// Are the amounts of checks of high severity by their result
+	// +optional
+	High CheckStatusCounts `json:"high"`
+	// Are the amounts of checks of medium severity by their result
+	// +optional
+	Medium CheckStatusCounts `json:"medium"`
+	// Are the amounts of checks of low severity by their result
+	// +optional
+	Low CheckStatusCounts `json:"low"`
+	// Are the amounts of checks of info or unknown severity by their result
+	// +optional
+	Other CheckStatusCounts `json:"other"`
+}
+
+// Add counts a check with the given result and severity
+func (c *ResultCounts) Add(status ComplianceCheckStatus, severity ComplianceCheckResultSeverity) {
+	c.Total++
+	c.Results.add(status)
+	switch severity {
+	case CheckResultSeverityHigh:
+		c.High.add(status)
+	case CheckResultSeverityMedium:
+		c.Medium.add(status)
+	case CheckResultSeverityLow:
+		c.Low.add(status)
+	default:
+		c.Other.add(status)
+	}
+}
+
+// Merge adds the amounts of other counts to these ones
+func (c *ResultCounts) Merge(other *ResultCounts) {
+	if other == nil {
+		return
+	}
+	c.Total += other.Total
+	c.Results.merge(other.Results)
+	c.High.merge(other.High)
+	c.Medium.merge(other.Medium)
+	c.Low.merge(other.Low)
+	c.Other.merge(other.Other)
 }
 
 // StorageReference stores a reference to where certain objects are being stored
@@ -316,6 +1045,7 @@ type StorageReference struct {
 // +kubebuilder:resource:path=compliancescans,scope=Namespaced,shortName=scans;scan
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Result",type="string",JSONPath=`.status.result`
+// +kubebuilder:printcolumn:name="Progress",type="string",JSONPath=`.status.progress.summary`
 type ComplianceScan struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -339,6 +1069,39 @@ func (cs *ComplianceScan) NeedsRescan() bool {
 	return needsRescan
 }
 
+// HasPendingRescan tells whether the scan is waiting for the rest of the
+// scans of a serially executed suite in order to be re-run
+func (cs *ComplianceScan) HasPendingRescan() bool {
+	annotations := cs.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+	_, pending := annotations[ComplianceScanPendingRescanAnnotation]
+	return pending
+}
+
+// NeedsNodeRescan indicates whether a single node of a ComplianceScan
+// needs to rescan or not
+func (cs *ComplianceScan) NeedsNodeRescan() bool {
+	annotations := cs.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+	_, needsRescan := annotations[ComplianceScanRescanNodeAnnotation]
+	return needsRescan
+}
+
+// RawResultsWerePruned indicates whether the raw results of the last run of
+// a ComplianceScan were pruned
+func (cs *ComplianceScan) RawResultsWerePruned() bool {
+	annotations := cs.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+	_, pruned := annotations[ComplianceScanRawResultsPrunedAnnotation]
+	return pruned
+}
+
 // NeedsTimeoutRescan indicates whether a ComplianceScan needs to
 // rescan due to timeout
 func (cs *ComplianceScan) NeedsTimeoutRescan() bool {
@@ -407,6 +1170,13 @@ func init() {
 	SchemeBuilder.Register(&ComplianceScan{}, &ComplianceScanList{})
 }
 
+// SetObservedGeneration records the generation of the scan the status and its
+// conditions are updated for
+func (s *ComplianceScanStatus) SetObservedGeneration(generation int64) {
+	s.ObservedGeneration = generation
+	s.Conditions.SetObservedGeneration(generation)
+}
+
 func (s *ComplianceScanStatus) SetConditionPending() {
 	s.Conditions.SetConditionPending("scan")
 }
@@ -421,8 +1191,17 @@ func (s *ComplianceScanStatus) SetConditionsProcessing() {
 
 func (s *ComplianceScanStatus) SetConditionReady() {
 	s.Conditions.SetConditionReady("scan")
+	if s.Result == ResultError {
+		s.Conditions.SetConditionDegraded("scan", s.ErrorMessage)
+	} else if len(s.ErroredNodes) > 0 {
+		s.Conditions.SetConditionNodesErrored("scan", s.ErroredNodes)
+	}
 }
 
 func (s *ComplianceScanStatus) SetConditionTimeout() {
 	s.Conditions.SetConditionTimeout("scan")
 }
+
+func (s *ComplianceScanStatus) SetConditionQueued() {
+	s.Conditions.SetConditionQueued("scan", s.QueuedUntil)
+}
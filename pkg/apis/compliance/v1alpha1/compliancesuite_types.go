@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"reflect"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -15,6 +16,10 @@ const SuiteLabel = "compliance.openshift.io/suite"
 // compliance suite controller
 const SuiteScriptLabel = "compliance.openshift.io/suite-script"
 
+// SuiteScheduleLabel names the additional schedule of the suite that a
+// rerunner CronJob runs
+const SuiteScheduleLabel = "compliance.openshift.io/suite-schedule"
+
 // SuiteFinalizer is a finalizer for ComplianceSuites. It gets automatically
 // added by the ComplianceSuite controller in order to delete resources.
 const SuiteFinalizer = "suite.finalizers.compliance.openshift.io"
@@ -30,6 +35,22 @@ const ApplyRemediationsAnnotation = "compliance.openshift.io/apply-remediations"
 // been removed.
 const RemoveOutdatedAnnotation = "compliance.openshift.io/remove-outdated"
 
+// RerunAnnotation is an annotation that, when set on a ComplianceSuite, will
+// re-run all of its scans. Scans that are still running are re-run once they
+// are done. The value optionally tells why the re-run was requested. It'll be
+// removed once the re-run was triggered.
+const RerunAnnotation = "compliance.openshift.io/rerun"
+
+// RerunRequesterAnnotation is set by the operator's webhook along with the
+// RerunAnnotation to the user that set the RerunAnnotation
+const RerunRequesterAnnotation = "compliance.openshift.io/rerun-requested-by"
+
+// VerifyRemediationsAnnotation is an annotation that the ComplianceSuite
+// controller sets on a ComplianceSuite when it applies remediations within
+// the remediation apply window of the suite. Once the remediations have been
+// applied, it's replaced by the rerun annotation so the suite verifies them.
+const VerifyRemediationsAnnotation = "compliance.openshift.io/verify-remediations"
+
 // ComplianceScanSpecWrapper provides a ComplianceScanSpec and a Name
 // +k8s:openapi-gen=true
 type ComplianceScanSpecWrapper struct {
@@ -70,11 +91,423 @@ type ComplianceScanStatusWrapper struct {
 	Name string `json:"name,omitempty"`
 }
 
+// ScanExecutionMode defines how the scans of a suite are run
+type ScanExecutionMode string
+
+const (
+	// ScanExecutionModeParallel runs all the scans of a suite at once
+	ScanExecutionModeParallel ScanExecutionMode = "Parallel"
+	// ScanExecutionModeSerial runs the scans of a suite one after another
+	ScanExecutionModeSerial ScanExecutionMode = "Serial"
+)
+
+// RemediationApplyFilter defines which remediations are applied
+// automatically, based on the check they were generated from
+// +k8s:openapi-gen=true
+type RemediationApplyFilter struct {
+	// Only the remediations of checks with one of these severities are
+	// applied automatically. All severities are allowed if empty.
+	// +listType=atomic
+	// +optional
+	Severities []ComplianceCheckResultSeverity `json:"severities,omitempty"`
+	// Only the remediations of these rules are applied automatically. The
+	// rules are referred to by the value of the compliance.openshift.io/rule
+	// annotation of their checks. All rules are allowed if empty.
+	// +listType=atomic
+	// +optional
+	Rules []string `json:"rules,omitempty"`
+	// The remediations of these rules are never applied automatically. This
+	// takes precedence over the other attributes.
+	// +listType=atomic
+	// +optional
+	ExcludedRules []string `json:"excludedRules,omitempty"`
+}
+
+// Allows tells whether the remediation of a check with the given severity
+// and rule passes the filter
+func (f *RemediationApplyFilter) Allows(severity ComplianceCheckResultSeverity, rule string) bool {
+	for _, excluded := range f.ExcludedRules {
+		if excluded == rule {
+			return false
+		}
+	}
+	ruleAllowed := len(f.Rules) == 0
+	for _, allowed := range f.Rules {
+		if allowed == rule {
+			ruleAllowed = true
+			break
+		}
+	}
+	if !ruleAllowed {
+		return false
+	}
+	if len(f.Severities) == 0 {
+		return true
+	}
+	for _, allowed := range f.Severities {
+		if allowed == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// RemediationApplyWindow defines a recurring window of time during which
+// remediations are applied automatically
+// +k8s:openapi-gen=true
+type RemediationApplyWindow struct {
+	// Defines when the window opens. This is in cronjob format.
+	Schedule string `json:"schedule"`
+	// Defines how long the window stays open, e.g. '2h'
+	Duration metav1.Duration `json:"duration"`
+}
+
+// ScanSchedule defines an additional schedule for some or all of the scans of
+// a suite to run on
+type ScanSchedule struct {
+	// The name of the schedule, which is part of the name of the CronJob
+	// that runs it
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+	// Defines how often the scans run. This is in cronjob format.
+	Schedule string `json:"schedule"`
+	// The names of the scans of the suite to run, which can be glob
+	// patterns like 'ocp4-cis-node-*'. Defaults to all the scans of the
+	// suite.
+	// +optional
+	Scans []string `json:"scans,omitempty"`
+}
+
+// RemediationExportFormat defines the layout of the exported remediations
+// +kubebuilder:validation:Enum=Kustomize;Manifests
+type RemediationExportFormat string
+
+const (
+	// RemediationExportKustomize exports the manifests of the remediations
+	// along with a kustomization.yaml that lists them
+	RemediationExportKustomize RemediationExportFormat = "Kustomize"
+	// RemediationExportManifests only exports the manifests of the
+	// remediations
+	RemediationExportManifests RemediationExportFormat = "Manifests"
+)
+
+// RemediationExport defines where the remediations of a suite are exported
+// to, so that a GitOps tool can apply them
+// +k8s:openapi-gen=true
+type RemediationExport struct {
+	// The name of the ConfigMap in the namespace of the suite that the
+	// remediations are exported to, one key per manifest.
+	ConfigMapName string `json:"configMapName"`
+	// Defines whether the manifests are exported as a kustomize base
+	// (Kustomize) or on their own (Manifests).
+	// +kubebuilder:default=Kustomize
+	// +optional
+	Format RemediationExportFormat `json:"format,omitempty"`
+	// Restricts the remediations that are exported. All the remediations
+	// of the suite are exported if unset.
+	// +optional
+	Filter *RemediationApplyFilter `json:"filter,omitempty"`
+}
+
+// ResultExportFormat defines the format of the exported results
+// +kubebuilder:validation:Enum=OSCAL;CSV;JSON
+type ResultExportFormat string
+
+const (
+	// ResultExportOSCAL exports the results as an OSCAL assessment results
+	// document in JSON
+	ResultExportOSCAL ResultExportFormat = "OSCAL"
+	// ResultExportCSV exports the check results as a flat CSV file, one
+	// row per check
+	ResultExportCSV ResultExportFormat = "CSV"
+	// ResultExportJSON exports the check results as a flat JSON array, one
+	// object per check
+	ResultExportJSON ResultExportFormat = "JSON"
+)
+
+// ResultExport defines where the results of a suite are exported to, so
+// that GRC tools can ingest them
+// +k8s:openapi-gen=true
+type ResultExport struct {
+	// The name of the ConfigMap in the namespace of the suite that the
+	// results are exported to.
+	ConfigMapName string `json:"configMapName"`
+	// Defines the format of the exported results.
+	// +kubebuilder:default=OSCAL
+	// +optional
+	Format ResultExportFormat `json:"format,omitempty"`
+	// The reference to the assessment plan that the exported assessment
+	// results import, as the GRC tool knows it. Only applies to the OSCAL
+	// format. Defaults to '#'.
+	// +optional
+	AssessmentPlanHref string `json:"assessmentPlanHref,omitempty"`
+}
+
+// ResultForwarding defines the sinks that the results of a suite are
+// forwarded to
+// +k8s:openapi-gen=true
+type ResultForwarding struct {
+	// Forwards a summary of each scan and the result of each check to a
+	// Splunk HTTP Event Collector.
+	// +optional
+	Splunk *SplunkForwarding `json:"splunk,omitempty"`
+	// Sends the result of each check as a CEF or LEEF message to a syslog
+	// receiver, for SIEMs that don't take HTTP events.
+	// +optional
+	Syslog *SyslogForwarding `json:"syslog,omitempty"`
+	// Publishes the lifecycle of the scans, the summary of each scan and
+	// the state changes of the remediations to a Kafka topic.
+	// +optional
+	Kafka *KafkaForwarding `json:"kafka,omitempty"`
+}
+
+// KafkaForwarding defines the Kafka topic that the events of a suite are
+// published to
+// +k8s:openapi-gen=true
+type KafkaForwarding struct {
+	// The name of the Secret in the namespace of the suite that configures
+	// the topic. It holds the comma-separated bootstrap brokers in its
+	// 'brokers' key and the topic in its 'topic' key. The brokers are
+	// connected to over TLS if the Secret has a 'ca.crt' key to verify
+	// them with or if its 'tls' key is 'true', and the operator
+	// authenticates with the 'username' and 'password' keys if its
+	// 'saslMechanism' key is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512.
+	SecretName string `json:"secretName"`
+}
+
+// SplunkForwarding defines the Splunk HTTP Event Collector that the results
+// of a suite are forwarded to
+// +k8s:openapi-gen=true
+type SplunkForwarding struct {
+	// The URL of the HTTP Event Collector, e.g.
+	// 'https://splunk.example.com:8088'. The events are posted to its
+	// '/services/collector/event' endpoint.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// The name of the Secret in the namespace of the suite that holds the
+	// token of the collector in its 'token' key, and optionally the CA
+	// certificate to verify the collector with in its 'ca.crt' key.
+	TokenSecretName string `json:"tokenSecretName"`
+	// The index the events are stored in. Defaults to the default index of
+	// the token.
+	// +optional
+	Index string `json:"index,omitempty"`
+	// The source of the events. Defaults to 'compliance-operator'.
+	// +optional
+	Source string `json:"source,omitempty"`
+	// The source type of the events. Defaults to '_json'.
+	// +optional
+	SourceType string `json:"sourceType,omitempty"`
+	// The maximum number of events that are posted in a single request.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	// +optional
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+// WebhookTrigger defines when a webhook is called
+// +kubebuilder:validation:Enum=Done;NonCompliant
+type WebhookTrigger string
+
+const (
+	// WebhookOnDone calls the webhook every time the scans of the suite
+	// finish
+	WebhookOnDone WebhookTrigger = "Done"
+	// WebhookOnNonCompliant calls the webhook when the scans of the suite
+	// finish with a NON-COMPLIANT result after they finished with another
+	// result, or for the first time
+	WebhookOnNonCompliant WebhookTrigger = "NonCompliant"
+)
+
+// Webhook defines an HTTP endpoint that's called when the scans of a suite
+// finish
+// +k8s:openapi-gen=true
+type Webhook struct {
+	// The name of the webhook, which its status refers to
+	// +kubebuilder:validation:Pattern=^[a-z0-9]([-a-z0-9]*[a-z0-9])?$
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+	// The URL the payload is posted to.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// Defines when the webhook is called.
+	// +kubebuilder:default=Done
+	// +optional
+	Trigger WebhookTrigger `json:"trigger,omitempty"`
+	// The name of the Secret in the namespace of the suite that holds the
+	// credentials of the webhook. A 'token' key is sent as a bearer token,
+	// and the 'username' and 'password' keys are sent as basic
+	// authentication. The endpoint is verified with its 'ca.crt' key, if
+	// it has one.
+	// +optional
+	AuthSecretName string `json:"authSecretName,omitempty"`
+	// A Go template that renders the payload, e.g. for the format of a chat
+	// tool. It's executed on the same fields as the default JSON payload,
+	// and has a 'json' function that quotes its argument as JSON. Defaults
+	// to the JSON payload.
+	// +optional
+	PayloadTemplate string `json:"payloadTemplate,omitempty"`
+	// The content type of the payload.
+	// +kubebuilder:default="application/json"
+	// +optional
+	ContentType string `json:"contentType,omitempty"`
+	// Defines how the calls that fail are retried.
+	// +optional
+	RetryPolicy *WebhookRetryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// WebhookRetryPolicy defines how the calls of a webhook that fail are
+// retried, with an exponential backoff
+// +k8s:openapi-gen=true
+type WebhookRetryPolicy struct {
+	// How many times a call is retried before the webhook is given up on
+	// for the run of the scans.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=5
+	// +optional
+	MaxRetries *int `json:"maxRetries,omitempty"`
+	// How long to wait before the first retry, the wait doubles with each
+	// retry. Defaults to 1s.
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+	// The longest wait between two retries. Defaults to 30s.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// SyslogProtocol defines how the messages are sent to a syslog receiver
+// +kubebuilder:validation:Enum=TLS;TCP
+type SyslogProtocol string
+
+const (
+	// SyslogTLS sends the messages over TLS, as in RFC 5425
+	SyslogTLS SyslogProtocol = "TLS"
+	// SyslogTCP sends the messages over plain TCP, as in RFC 6587
+	SyslogTCP SyslogProtocol = "TCP"
+)
+
+// SyslogMessageFormat defines the format of the messages sent to a syslog
+// receiver
+// +kubebuilder:validation:Enum=CEF;LEEF
+type SyslogMessageFormat string
+
+const (
+	// SyslogCEF formats the messages in the ArcSight Common Event Format
+	SyslogCEF SyslogMessageFormat = "CEF"
+	// SyslogLEEF formats the messages in the QRadar Log Event Extended
+	// Format
+	SyslogLEEF SyslogMessageFormat = "LEEF"
+)
+
+// SyslogForwarding defines the syslog receiver that the results of a suite
+// are forwarded to
+// +k8s:openapi-gen=true
+type SyslogForwarding struct {
+	// The host and port of the receiver, e.g. 'siem.example.com:6514'.
+	// +kubebuilder:validation:Pattern=`^[^:/]+:[0-9]+$`
+	Address string `json:"address"`
+	// Defines how the messages are sent to the receiver.
+	// +kubebuilder:default=TLS
+	// +optional
+	Protocol SyslogProtocol `json:"protocol,omitempty"`
+	// Defines the format of the messages.
+	// +kubebuilder:default=CEF
+	// +optional
+	Format SyslogMessageFormat `json:"format,omitempty"`
+	// The name of the Secret in the namespace of the suite that holds the
+	// CA certificate to verify the receiver with in its 'ca.crt' key. The
+	// receiver is verified against the system CAs if unset. Only applies to
+	// the TLS protocol.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+	// Defines the keys of the fields of the messages that hold the rule
+	// ID, the severity and the nodes of each check.
+	// +optional
+	FieldMapping *SyslogFieldMapping `json:"fieldMapping,omitempty"`
+}
+
+// SyslogFieldMapping defines the keys of the fields of a CEF or LEEF message
+// that hold the attributes of a check
+// +k8s:openapi-gen=true
+type SyslogFieldMapping struct {
+	// The key of the rule ID. Defaults to 'ruleId'.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	// +optional
+	RuleID string `json:"ruleID,omitempty"`
+	// The key of the severity of the check, e.g. 'high'. Defaults to
+	// 'complianceSeverity'.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// The key of the role of the nodes that node scans checked, or of the
+	// nodes the check is inconsistent on. Defaults to 'node'.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	// +optional
+	Node string `json:"node,omitempty"`
+}
+
 // ComplianceSuiteSettings groups together settings of a ComplianceSuite
 // +k8s:openapi-gen=true
 type ComplianceSuiteSettings struct {
 	// Defines whether or not the remediations should be applied automatically
 	AutoApplyRemediations bool `json:"autoApplyRemediations,omitempty"`
+	// Restricts the remediations that are applied automatically when
+	// autoApplyRemediations is set, e.g. to only apply the remediations of
+	// high severity checks while keeping risky ones manual. Remediations
+	// that are applied through the apply-remediations annotation are not
+	// filtered.
+	// +optional
+	AutoApplyRemediationsFilter *RemediationApplyFilter `json:"autoApplyRemediationsFilter,omitempty"`
+	// Restricts the MachineConfigPools whose remediations are applied
+	// automatically when autoApplyRemediations is set, e.g. to apply the
+	// remediations of the worker pool automatically while keeping the ones
+	// of the master pool manual. Only the MachineConfig and KubeletConfig
+	// remediations of node scans are restricted. The remediations of all the
+	// pools are applied automatically if empty. Remediations that are
+	// applied through the apply-remediations annotation are not restricted.
+	// +listType=atomic
+	// +optional
+	AutoApplyRemediationsPools []string `json:"autoApplyRemediationsPools,omitempty"`
+	// Restricts when the remediations are applied automatically when
+	// autoApplyRemediations is set. Remediations that are generated outside
+	// of the window are queued up until it opens, and the suite is re-run
+	// once the remediations applied within the window have been applied.
+	// Remediations that are applied through the apply-remediations
+	// annotation are applied right away.
+	// +optional
+	RemediationApplyWindow *RemediationApplyWindow `json:"remediationApplyWindow,omitempty"`
+	// Requires a RemediationApproval that refers to a remediation of the
+	// suite before the remediation is applied, so that applying it needs
+	// the approval of an identity that's allowed to create approvals.
+	// This applies to the remediations that are applied automatically
+	// too. Un-applying remediations doesn't require an approval.
+	// +optional
+	RequireRemediationApproval bool `json:"requireRemediationApproval,omitempty"`
+	// Exports the remediations of the suite once its scans are done, so
+	// that a GitOps tool like Argo CD can own applying them.
+	// +optional
+	RemediationExport *RemediationExport `json:"remediationExport,omitempty"`
+	// Exports the results of the suite once its scans are done, e.g. as
+	// OSCAL assessment results, so that GRC tools can ingest them.
+	// +optional
+	ResultExport *ResultExport `json:"resultExport,omitempty"`
+	// Forwards the results of the suite to external sinks once its scans
+	// are done, e.g. a Splunk HTTP Event Collector, so that they reach a
+	// SIEM within minutes of the scan.
+	// +optional
+	ResultForwarding *ResultForwarding `json:"resultForwarding,omitempty"`
+	// Calls HTTP webhooks when the scans of the suite finish or when the
+	// suite becomes NON-COMPLIANT, to plug the suite into existing
+	// automation.
+	// +optional
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Adds a compact summary of the results of the suite, i.e. its result
+	// and the IDs of the high severity rules that failed, to the data that
+	// the Insights Operator uploads, so that the compliance of a fleet of
+	// clusters shows in the hosted console. Only applies to OpenShift.
+	// +optional
+	InsightsSummary bool `json:"insightsSummary,omitempty"`
 	// Defines whether or not the remediations should be updated automatically.
 	// This is done by deleting the "outdated" object from the remediation.
 	AutoUpdateRemediations bool `json:"autoUpdateRemediations,omitempty"`
@@ -82,10 +515,106 @@ type ComplianceSuiteSettings struct {
 	// Note the scan will still be triggered immediately, and the scheduled
 	// scans will start running only after the initial results are ready.
 	Schedule string `json:"schedule,omitempty"`
+	// Defines the time zone the schedule is interpreted in, as an IANA
+	// time zone name, e.g. 'Europe/Berlin'. Defaults to the time zone of
+	// the kube-controller-manager.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+	// Delays each scheduled run by a random duration of up to this, e.g.
+	// '30m', so that the clusters that share a schedule don't all start
+	// scanning at once. Applies to the additional schedules too. By
+	// default, the scans start on schedule.
+	// +optional
+	// +nullable
+	ScheduleJitter *metav1.Duration `json:"scheduleJitter,omitempty"`
+	// Defines more schedules for the scans to run on, e.g. a daily scan of
+	// the critical rules along with a weekly scan of all of them, each of
+	// which can run only some of the scans. They are interpreted in the
+	// same time zone as the schedule.
+	// +optional
+	AdditionalSchedules []ScanSchedule `json:"additionalSchedules,omitempty"`
 	// Defines if a schedule should be suspended and is a boolean value,
 	// defaulting to False.
 	// +kubebuilder:default=false
 	Suspend bool `json:"suspend,omitempty"`
+	// Defines whether the node scans should be re-run once the
+	// MachineConfigPool of the scanned nodes finishes rolling out a new
+	// configuration, e.g. after remediations were applied.
+	// +kubebuilder:default=false
+	RescanOnMachineConfigPoolUpdate bool `json:"rescanOnMachineConfigPoolUpdate,omitempty"`
+	// Defines whether the scans of the suite run all at once (Parallel) or
+	// one after another (Serial). In serial mode the platform scans run
+	// first, followed by the worker node scans and then the master node
+	// scans, which bounds the resources used by the scans at any given
+	// time.
+	// +kubebuilder:validation:Enum=Parallel;Serial
+	// +kubebuilder:default=Parallel
+	ScanExecutionMode ScanExecutionMode `json:"scanExecutionMode,omitempty"`
+	// Defines the percentage of the checks of the suite that need to pass
+	// for a non-compliant suite to be reported as COMPLIANT, for frameworks
+	// that allow a scored threshold rather than all checks passing. Only
+	// the checks that passed or failed are taken into account. A value of
+	// '0' disables the threshold.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ComplianceThreshold int `json:"complianceThreshold,omitempty"`
+	// Defines how long the results of the suite are kept for once they're
+	// no longer current. Nothing is pruned if it's not set.
+	// +optional
+	ResultRetention *ResultRetentionPolicy `json:"resultRetention,omitempty"`
+}
+
+// ResultRetentionPolicy defines which of the results of a suite get pruned
+// +k8s:openapi-gen=true
+type ResultRetentionPolicy struct {
+	// Defines how long a ComplianceCheckResult is kept for after the last
+	// run of its scan that reported it, e.g. because the scan no longer
+	// ran. The results of scans that are no longer part of the suite are
+	// pruned right away. The results whose remediation is applied, or set
+	// to be applied, are kept.
+	// +optional
+	MaxCheckResultAge *metav1.Duration `json:"maxCheckResultAge,omitempty"`
+	// Defines whether the obsolete remediations of the suite that aren't
+	// applied, and the remediations whose check result no longer exists,
+	// are pruned.
+	// +optional
+	PruneObsoleteRemediations bool `json:"pruneObsoleteRemediations,omitempty"`
+	// Defines how long the raw results of the nodes that the aggregator
+	// read the check results from are kept for once the scan is done. A
+	// single node can only be rescanned on its own while they're kept, a
+	// full rescan is run instead otherwise.
+	// +optional
+	MaxRawResultAge *metav1.Duration `json:"maxRawResultAge,omitempty"`
+	// Defines how many of the completed jobs of the rerunners of the suite
+	// are kept. All of them are kept if it's not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCompletedRerunnerJobs *int32 `json:"maxCompletedRerunnerJobs,omitempty"`
+}
+
+// GetMaxCheckResultAge returns how long the check results are kept for, or
+// zero if they're kept indefinitely
+func (p *ResultRetentionPolicy) GetMaxCheckResultAge() time.Duration {
+	if p == nil || p.MaxCheckResultAge == nil {
+		return 0
+	}
+	return p.MaxCheckResultAge.Duration
+}
+
+// GetMaxRawResultAge returns how long the raw results are kept for, or zero
+// if they're kept until the scan is rerun
+func (p *ResultRetentionPolicy) GetMaxRawResultAge() time.Duration {
+	if p == nil || p.MaxRawResultAge == nil {
+		return 0
+	}
+	return p.MaxRawResultAge.Duration
+}
+
+// IsEnabled tells whether anything is pruned according to the policy
+func (p *ResultRetentionPolicy) IsEnabled() bool {
+	return p != nil && (p.MaxCheckResultAge != nil || p.PruneObsoleteRemediations ||
+		p.MaxRawResultAge != nil || p.MaxCompletedRerunnerJobs != nil)
 }
 
 // ComplianceSuiteSpec defines the desired state of ComplianceSuite
@@ -95,6 +624,12 @@ type ComplianceSuiteSpec struct {
 	// Contains a list of the scans to execute on the cluster
 	// +listType=atomic
 	Scans []ComplianceScanSpecWrapper `json:"scans"`
+	// Contains the names of the suites in the same namespace that need to
+	// be done before the scans of this suite are launched, e.g. to only scan
+	// the worker nodes once the platform scan finished.
+	// +listType=atomic
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // ComplianceSuiteStatus defines the observed state of ComplianceSuite
@@ -105,8 +640,136 @@ type ComplianceSuiteStatus struct {
 	Phase        ComplianceScanStatusPhase     `json:"phase,omitempty"`
 	Result       ComplianceScanStatusResult    `json:"result,omitempty"`
 	ErrorMessage string                        `json:"errorMessage,omitempty"`
+	// Contains how the results of the suite compare to the compliance
+	// threshold, if one is set
+	// +optional
+	ComplianceThreshold *ComplianceThresholdStatus `json:"complianceThreshold,omitempty"`
+	// Contains how many of the MANUAL checks of the suite were reviewed,
+	// if it has any
+	// +optional
+	ManualChecks *ManualChecksStatus `json:"manualChecks,omitempty"`
+	// Contains the amounts of checks of the last runs of the scans of the
+	// suite by their result and severity
+	// +optional
+	ResultCounts *ResultCounts `json:"resultCounts,omitempty"`
+	// Contains who requested the last re-run of the suite through the
+	// rerun annotation, and when
+	// +optional
+	LastRerun *ComplianceSuiteRerunStatus `json:"lastRerun,omitempty"`
+	// Contains which runs of the scans of the suite had their results
+	// forwarded to the sinks of its result forwarding
+	// +optional
+	ResultForwarding *ResultForwardingStatus `json:"resultForwarding,omitempty"`
+	// Contains the latest run of the scans that each webhook of the suite
+	// was considered for, and how the call went
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Webhooks []WebhookStatus `json:"webhooks,omitempty"`
+	// Tells whether the summary of the suite is in the ConfigMap that the
+	// Insights Operator gathers
+	// +optional
+	InsightsSummaryShared bool `json:"insightsSummaryShared,omitempty"`
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+	// The generation of the suite the status was last updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ResultForwardingStatus describes the forwarding of the results of a suite
+// to each of its sinks
+// +k8s:openapi-gen=true
+type ResultForwardingStatus struct {
+	// The forwarding of the results to the Splunk HTTP Event Collector
+	// +optional
+	Splunk *SinkForwardingStatus `json:"splunk,omitempty"`
+	// The forwarding of the results to the syslog receiver
+	// +optional
+	Syslog *SinkForwardingStatus `json:"syslog,omitempty"`
+	// The forwarding of the summaries of the scans to the Kafka topic
+	// +optional
+	Kafka *SinkForwardingStatus `json:"kafka,omitempty"`
+}
+
+// WebhookStatus describes the latest run of the scans of a suite that a
+// webhook was considered for
+// +k8s:openapi-gen=true
+type WebhookStatus struct {
+	// The name of the webhook
+	Name string `json:"name"`
+	// When the latest run of the scans started
+	ScanRun metav1.Time `json:"scanRun"`
+	// The result of the suite for the run, which the NonCompliant trigger
+	// compares the next run to
+	Result ComplianceScanStatusResult `json:"result"`
+	// When the webhook was last called successfully
+	// +optional
+	CalledAt *metav1.Time `json:"calledAt,omitempty"`
+	// Why the webhook couldn't be called for the run, once the retries
+	// were exhausted
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SinkForwardingStatus describes the latest results of a suite that were
+// forwarded to a sink
+// +k8s:openapi-gen=true
+type SinkForwardingStatus struct {
+	// When the latest run of the scans whose results were forwarded, or
+	// queued to be forwarded, started
+	ScanRun metav1.Time `json:"scanRun"`
+	// When the results of the run were forwarded, unset while they wait to
+	// be forwarded
+	// +optional
+	ForwardedAt *metav1.Time `json:"forwardedAt,omitempty"`
+	// The number of events that were forwarded
+	Events int `json:"events"`
+	// Why the results of the run couldn't be forwarded, once the retries
+	// were exhausted
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ComplianceSuiteRerunStatus describes a re-run of a suite that was requested
+// through the rerun annotation
+// +k8s:openapi-gen=true
+type ComplianceSuiteRerunStatus struct {
+	// Who requested the re-run, as recorded by the operator's webhook, or
+	// unknown if the webhook isn't served
+	TriggeredBy string `json:"triggeredBy"`
+	// The value of the annotation, which tells why the re-run was requested
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// When the re-run was triggered
+	TriggeredAt metav1.Time `json:"triggeredAt"`
+}
+
+// ComplianceThresholdStatus describes how the results of a suite compare to
+// its compliance threshold
+// +k8s:openapi-gen=true
+type ComplianceThresholdStatus struct {
+	// The number of checks of the suite that passed
+	PassedChecks int `json:"passedChecks"`
+	// The number of checks of the suite that failed
+	FailedChecks int `json:"failedChecks"`
+	// The percentage of the passed and failed checks that passed, rounded
+	// down
+	PassPercentage int `json:"passPercentage"`
+	// The number of percentage points the pass percentage is below the
+	// threshold. This is '0' if the threshold is met.
+	Gap int `json:"gap"`
+}
+
+// ManualChecksStatus describes the reviews of the MANUAL checks of a suite
+// +k8s:openapi-gen=true
+type ManualChecksStatus struct {
+	// The number of MANUAL checks that passed their review
+	Passed int `json:"passed"`
+	// The number of MANUAL checks that failed their review
+	Failed int `json:"failed"`
+	// The number of MANUAL checks that weren't reviewed yet
+	Pending int `json:"pending"`
 }
 
 // +kubebuilder:object:root=true
@@ -168,6 +831,12 @@ func (s *ComplianceSuite) LowestCommonState() ComplianceScanStatusPhase {
 		return PhasePending
 	}
 
+	// Serially executed suites are pending until all of their scans
+	// have been launched
+	if s.RunsScansSerially() && s.hasUnreportedScans() {
+		return PhasePending
+	}
+
 	lowestCommonState := PhaseDone
 
 	for _, scanStatusWrap := range s.Status.ScanStatuses {
@@ -182,6 +851,10 @@ func (s *ComplianceSuite) LowestCommonResult() ComplianceScanStatusResult {
 		return ResultNotAvailable
 	}
 
+	if s.RunsScansSerially() && s.hasUnreportedScans() {
+		return ResultNotAvailable
+	}
+
 	lowestCommonResult := ResultCompliant
 
 	for _, scanStatusWrap := range s.Status.ScanStatuses {
@@ -191,11 +864,49 @@ func (s *ComplianceSuite) LowestCommonResult() ComplianceScanStatusResult {
 	return lowestCommonResult
 }
 
+// AggregateResultCounts returns the sum of the result counts of the scans of
+// the suite, or nil if none of them has any yet
+func (s *ComplianceSuite) AggregateResultCounts() *ResultCounts {
+	var counts *ResultCounts
+	for i := range s.Status.ScanStatuses {
+		scanCounts := s.Status.ScanStatuses[i].ResultCounts
+		if scanCounts == nil {
+			continue
+		}
+		if counts == nil {
+			counts = &ResultCounts{}
+		}
+		counts.Merge(scanCounts)
+	}
+	return counts
+}
+
+// hasUnreportedScans tells whether any of the scans of the spec has no
+// status in the suite yet
+func (s *ComplianceSuite) hasUnreportedScans() bool {
+	reported := make(map[string]bool, len(s.Status.ScanStatuses))
+	for _, scanStatusWrap := range s.Status.ScanStatuses {
+		reported[scanStatusWrap.Name] = true
+	}
+	for _, scanWrap := range s.Spec.Scans {
+		if !reported[scanWrap.Name] {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ComplianceSuite) IsResultAvailable() bool {
 	result := s.LowestCommonResult()
 	return result != "" && result != ResultNotAvailable
 }
 
+// RunsScansSerially tells whether the scans of the suite should run one
+// after another
+func (s *ComplianceSuite) RunsScansSerially() bool {
+	return s.Spec.ScanExecutionMode == ScanExecutionModeSerial
+}
+
 // ShouldApplyRemediations returns whether the ComplianceSuite requires
 // that the CoplianceRemediations that were generated from it be
 // applied.
@@ -232,6 +943,31 @@ func (s *ComplianceSuite) RemoveOutdatedAnnotationSet() bool {
 	return ok
 }
 
+func (s *ComplianceSuite) RerunAnnotationSet() bool {
+	annotations := s.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+	_, ok := annotations[RerunAnnotation]
+	return ok
+}
+
+func (s *ComplianceSuite) VerifyRemediationsAnnotationSet() bool {
+	annotations := s.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+	_, ok := annotations[VerifyRemediationsAnnotation]
+	return ok
+}
+
+// SetObservedGeneration records the generation of the suite the status and
+// its conditions are updated for
+func (s *ComplianceSuiteStatus) SetObservedGeneration(generation int64) {
+	s.ObservedGeneration = generation
+	s.Conditions.SetObservedGeneration(generation)
+}
+
 func (s *ComplianceSuiteStatus) SetConditionPending() {
 	s.Conditions.SetConditionPending("suite")
 }
@@ -246,4 +982,15 @@ func (s *ComplianceSuiteStatus) SetConditionsProcessing() {
 
 func (s *ComplianceSuiteStatus) SetConditionReady() {
 	s.Conditions.SetConditionReady("suite")
+	if s.Result == ResultError {
+		s.Conditions.SetConditionDegraded("suite", s.ErrorMessage)
+		return
+	}
+	var erroredNodes []string
+	for _, scanStatusWrap := range s.ScanStatuses {
+		erroredNodes = append(erroredNodes, scanStatusWrap.ErroredNodes...)
+	}
+	if len(erroredNodes) > 0 {
+		s.Conditions.SetConditionNodesErrored("suite", erroredNodes)
+	}
 }
@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing ComplianceSuite API", func() {
+	When("aggregating the result counts of the scans", func() {
+		It("sums up the counts of the scans that have them", func() {
+			platformCounts := &ResultCounts{}
+			platformCounts.Add(CheckResultPass, CheckResultSeverityHigh)
+			platformCounts.Add(CheckResultFail, CheckResultSeverityMedium)
+			nodeCounts := &ResultCounts{}
+			nodeCounts.Add(CheckResultFail, CheckResultSeverityMedium)
+			nodeCounts.Add(CheckResultNotApplicable, CheckResultSeverityInfo)
+
+			suite := &ComplianceSuite{}
+			suite.Status.ScanStatuses = []ComplianceScanStatusWrapper{
+				{Name: "platform", ComplianceScanStatus: ComplianceScanStatus{ResultCounts: platformCounts}},
+				{Name: "node", ComplianceScanStatus: ComplianceScanStatus{ResultCounts: nodeCounts}},
+				{Name: "pending"},
+			}
+			Expect(suite.AggregateResultCounts()).To(Equal(&ResultCounts{
+				Total:   4,
+				Results: CheckStatusCounts{Pass: 1, Fail: 2, NotApplicable: 1},
+				High:    CheckStatusCounts{Pass: 1},
+				Medium:  CheckStatusCounts{Fail: 2},
+				Other:   CheckStatusCounts{NotApplicable: 1},
+			}))
+		})
+
+		It("has no counts until a scan has them", func() {
+			suite := &ComplianceSuite{}
+			suite.Status.ScanStatuses = []ComplianceScanStatusWrapper{{Name: "pending"}}
+			Expect(suite.AggregateResultCounts()).To(BeNil())
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +41,9 @@ type Condition struct {
 	Reason             ConditionReason        `json:"reason,omitempty"`
 	Message            string                 `json:"message,omitempty"`
 	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	// The generation of the object the condition was set for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // IsTrue Condition whether the condition status is "True".
@@ -133,6 +137,14 @@ func (conditions *Conditions) SetCondition(newCond Condition) bool {
 	return true
 }
 
+// SetObservedGeneration records the generation of the object the set of
+// conditions was set for in each of its conditions.
+func (conditions Conditions) SetObservedGeneration(generation int64) {
+	for i := range conditions {
+		conditions[i].ObservedGeneration = generation
+	}
+}
+
 // GetCondition searches the set of conditions for the condition with the given
 // ConditionType and returns it. If the matching condition is not found,
 // GetCondition returns nil.
@@ -172,62 +184,204 @@ func (conditions Conditions) MarshalJSON() ([]byte, error) {
 	return json.Marshal(conds)
 }
 
+// The condition types set on compliance scans and suites. Besides Ready and
+// Processing, they carry the generic Progressing, Degraded and
+// ResultsAvailable conditions that tools interpreting custom resources
+// generically, such as GitOps tools, understand.
+const (
+	ConditionReady            ConditionType = "Ready"
+	ConditionProcessing       ConditionType = "Processing"
+	ConditionProgressing      ConditionType = "Progressing"
+	ConditionDegraded         ConditionType = "Degraded"
+	ConditionResultsAvailable ConditionType = "ResultsAvailable"
+)
+
 func (conditions *Conditions) SetConditionPending(what string) {
 	conditions.SetCondition(Condition{
-		Type:    "Ready",
+		Type:    ConditionReady,
 		Status:  corev1.ConditionFalse,
 		Reason:  "Pending",
 		Message: fmt.Sprintf("The compliance %s is waiting to be processed", what),
 	})
-	conditions.RemoveCondition("Processing")
+	conditions.RemoveCondition(ConditionProcessing)
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Pending",
+		Message: fmt.Sprintf("The compliance %s is waiting to be processed", what),
+	})
+	conditions.setConditionNotDegraded(what)
+	conditions.setConditionNoResults(what, "Pending")
+}
+
+// SetConditionQueued marks a compliance run that waits for its window to
+// open before being processed
+func (conditions *Conditions) SetConditionQueued(what string, until *metav1.Time) {
+	message := fmt.Sprintf("The compliance %s is queued until its scan window opens", what)
+	if until != nil {
+		message = fmt.Sprintf("%s at %s", message, until.UTC().Format(time.RFC3339))
+	}
+	conditions.SetCondition(Condition{
+		Type:    ConditionReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Queued",
+		Message: message,
+	})
+	conditions.RemoveCondition(ConditionProcessing)
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Queued",
+		Message: message,
+	})
+	conditions.setConditionNotDegraded(what)
+	conditions.setConditionNoResults(what, "Queued")
 }
 
 func (conditions *Conditions) SetConditionInvalid(what string) {
 	conditions.SetCondition(Condition{
-		Type:    "Ready",
+		Type:    ConditionReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Invalid",
+		Message: fmt.Sprintf("%s validation failed", what),
+	})
+	conditions.RemoveCondition(ConditionProcessing)
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
 		Status:  corev1.ConditionFalse,
 		Reason:  "Invalid",
 		Message: fmt.Sprintf("%s validation failed", what),
 	})
-	conditions.RemoveCondition("Processing")
+	conditions.SetCondition(Condition{
+		Type:    ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Invalid",
+		Message: fmt.Sprintf("%s validation failed", what),
+	})
+	conditions.setConditionNoResults(what, "Invalid")
 }
 
 func (conditions *Conditions) SetConditionsProcessing(what string) {
 	conditions.SetCondition(Condition{
-		Type:    "Ready",
+		Type:    ConditionReady,
 		Status:  corev1.ConditionFalse,
 		Reason:  "Processing",
 		Message: fmt.Sprintf("Compliance %s doesn't have results yet", what),
 	})
 	conditions.SetCondition(Condition{
-		Type:    "Processing",
+		Type:    ConditionProcessing,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Running",
+		Message: fmt.Sprintf("Compliance %s run is running the scans", what),
+	})
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
 		Status:  corev1.ConditionTrue,
 		Reason:  "Running",
 		Message: fmt.Sprintf("Compliance %s run is running the scans", what),
 	})
+	conditions.setConditionNotDegraded(what)
+	conditions.setConditionNoResults(what, "Running")
 }
 
 func (conditions *Conditions) SetConditionReady(what string) {
 	conditions.SetCondition(Condition{
-		Type:    "Ready",
+		Type:    ConditionReady,
 		Status:  corev1.ConditionTrue,
 		Reason:  "Done",
 		Message: fmt.Sprintf("Compliance %s run is done and has results", what),
 	})
 	conditions.SetCondition(Condition{
-		Type:    "Processing",
+		Type:    ConditionProcessing,
 		Status:  corev1.ConditionFalse,
 		Reason:  "NotRunning",
 		Message: fmt.Sprintf("Compliance %s run is done running the scans", what),
 	})
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Done",
+		Message: fmt.Sprintf("Compliance %s run is done running the scans", what),
+	})
+	conditions.setConditionNotDegraded(what)
+	conditions.SetCondition(Condition{
+		Type:    ConditionResultsAvailable,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Done",
+		Message: fmt.Sprintf("Compliance %s run is done and has results", what),
+	})
 }
 
 func (conditions *Conditions) SetConditionTimeout(what string) {
 	conditions.SetCondition(Condition{
-		Type:    "Ready",
+		Type:    ConditionReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Timeout",
+		Message: fmt.Sprintf("%s timeout", what),
+	})
+	conditions.RemoveCondition(ConditionProcessing)
+	conditions.SetCondition(Condition{
+		Type:    ConditionProgressing,
 		Status:  corev1.ConditionFalse,
 		Reason:  "Timeout",
 		Message: fmt.Sprintf("%s timeout", what),
 	})
-	conditions.RemoveCondition("Processing")
+	conditions.SetCondition(Condition{
+		Type:    ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Timeout",
+		Message: fmt.Sprintf("%s timeout", what),
+	})
+	conditions.setConditionNoResults(what, "Timeout")
+}
+
+// SetConditionDegraded marks a compliance run that is done, but errored
+// out, as degraded and without results
+func (conditions *Conditions) SetConditionDegraded(what, errorMessage string) {
+	message := fmt.Sprintf("Compliance %s run errored out", what)
+	if errorMessage != "" {
+		message = fmt.Sprintf("%s: %s", message, errorMessage)
+	}
+	conditions.SetCondition(Condition{
+		Type:    ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  "Error",
+		Message: message,
+	})
+	conditions.SetCondition(Condition{
+		Type:    ConditionResultsAvailable,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Error",
+		Message: message,
+	})
+}
+
+// SetConditionNodesErrored marks a compliance run that has results, but left
+// out the results of some of its nodes because their scan errored out, as
+// degraded
+func (conditions *Conditions) SetConditionNodesErrored(what string, nodes []string) {
+	conditions.SetCondition(Condition{
+		Type:    ConditionDegraded,
+		Status:  corev1.ConditionTrue,
+		Reason:  "NodesErrored",
+		Message: fmt.Sprintf("Compliance %s run left out the nodes whose scan errored out: %s", what, strings.Join(nodes, ",")),
+	})
+}
+
+func (conditions *Conditions) setConditionNotDegraded(what string) {
+	conditions.SetCondition(Condition{
+		Type:    ConditionDegraded,
+		Status:  corev1.ConditionFalse,
+		Reason:  "AsExpected",
+		Message: fmt.Sprintf("Compliance %s run is not degraded", what),
+	})
+}
+
+func (conditions *Conditions) setConditionNoResults(what string, reason ConditionReason) {
+	conditions.SetCondition(Condition{
+		Type:    ConditionResultsAvailable,
+		Status:  corev1.ConditionFalse,
+		Reason:  reason,
+		Message: fmt.Sprintf("Compliance %s doesn't have results yet", what),
+	})
 }
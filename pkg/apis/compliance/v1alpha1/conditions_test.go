@@ -0,0 +1,95 @@
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing the conditions of scans and suites", func() {
+	var status *ComplianceScanStatus
+
+	expectCondition := func(t ConditionType, st corev1.ConditionStatus, reason ConditionReason) {
+		cond := status.Conditions.GetCondition(t)
+		Expect(cond).ToNot(BeNil())
+		Expect(cond.Status).To(Equal(st))
+		Expect(cond.Reason).To(Equal(reason))
+		Expect(cond.LastTransitionTime.IsZero()).To(BeFalse())
+	}
+
+	BeforeEach(func() {
+		status = &ComplianceScanStatus{}
+	})
+
+	It("reports a pending scan as progressing", func() {
+		status.SetConditionPending()
+		expectCondition(ConditionReady, corev1.ConditionFalse, "Pending")
+		expectCondition(ConditionProgressing, corev1.ConditionTrue, "Pending")
+		expectCondition(ConditionDegraded, corev1.ConditionFalse, "AsExpected")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionFalse, "Pending")
+	})
+
+	It("reports a queued scan as progressing until its window opens", func() {
+		status.QueuedUntil = &metav1.Time{Time: time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)}
+		status.SetConditionQueued()
+		expectCondition(ConditionReady, corev1.ConditionFalse, "Queued")
+		expectCondition(ConditionProgressing, corev1.ConditionTrue, "Queued")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionFalse, "Queued")
+		Expect(status.Conditions.GetCondition(ConditionReady).Message).To(HaveSuffix("opens at 2024-03-01T22:00:00Z"))
+	})
+
+	It("reports a running scan as progressing", func() {
+		status.SetConditionsProcessing()
+		expectCondition(ConditionProcessing, corev1.ConditionTrue, "Running")
+		expectCondition(ConditionProgressing, corev1.ConditionTrue, "Running")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionFalse, "Running")
+	})
+
+	It("reports a finished scan as having results", func() {
+		status.SetConditionsProcessing()
+		status.Result = ResultNonCompliant
+		status.SetConditionReady()
+		expectCondition(ConditionReady, corev1.ConditionTrue, "Done")
+		expectCondition(ConditionProgressing, corev1.ConditionFalse, "Done")
+		expectCondition(ConditionDegraded, corev1.ConditionFalse, "AsExpected")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionTrue, "Done")
+	})
+
+	It("reports a scan that errored out as degraded", func() {
+		status.Result = ResultError
+		status.ErrorMessage = "the content can't be parsed"
+		status.SetConditionReady()
+		expectCondition(ConditionProgressing, corev1.ConditionFalse, "Done")
+		expectCondition(ConditionDegraded, corev1.ConditionTrue, "Error")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionFalse, "Error")
+		Expect(status.Conditions.GetCondition(ConditionDegraded).Message).To(ContainSubstring("the content can't be parsed"))
+	})
+
+	It("records the generation of the scan in its conditions", func() {
+		status.SetConditionsProcessing()
+		status.SetObservedGeneration(3)
+		Expect(status.ObservedGeneration).To(BeEquivalentTo(3))
+		for _, cond := range status.Conditions {
+			Expect(cond.ObservedGeneration).To(BeEquivalentTo(3), string(cond.Type))
+		}
+
+		// The conditions set afterwards get the generation of the next update
+		status.SetConditionReady()
+		status.SetObservedGeneration(4)
+		Expect(status.Conditions.GetCondition(ConditionReady).ObservedGeneration).To(BeEquivalentTo(4))
+	})
+
+	It("reports invalid and timed out scans as degraded", func() {
+		status.SetConditionInvalid()
+		expectCondition(ConditionProgressing, corev1.ConditionFalse, "Invalid")
+		expectCondition(ConditionDegraded, corev1.ConditionTrue, "Invalid")
+
+		status.SetConditionTimeout()
+		expectCondition(ConditionProgressing, corev1.ConditionFalse, "Timeout")
+		expectCondition(ConditionDegraded, corev1.ConditionTrue, "Timeout")
+		expectCondition(ConditionResultsAvailable, corev1.ConditionFalse, "Timeout")
+	})
+})
@@ -0,0 +1,89 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CustomRuleLabel is set on the ComplianceCheckResults of custom rules, with
+// the name of the rule
+const CustomRuleLabel = "compliance.openshift.io/custom-rule"
+
+// CustomRuleAssertionLanguage is the language the assertion of a custom rule
+// is written in
+type CustomRuleAssertionLanguage string
+
+const (
+	// The assertion is a jq expression
+	CustomRuleAssertionLanguageJQ CustomRuleAssertionLanguage = "JQ"
+	// The assertion is a CEL expression, with the resource as the
+	// "resource" variable
+	CustomRuleAssertionLanguageCEL CustomRuleAssertionLanguage = "CEL"
+)
+
+// CustomRuleSpec defines the check of a custom rule
+// +k8s:openapi-gen=true
+type CustomRuleSpec struct {
+	// The title of the rule
+	// +kubebuilder:validation:Pattern=^.+$
+	Title string `json:"title"`
+	// What the rule checks and why
+	// +optional
+	Description string `json:"description,omitempty"`
+	// How to evaluate the rule manually, or to fix a failing result
+	// +optional
+	Instructions string `json:"instructions,omitempty"`
+	// The severity of a failing result
+	// +kubebuilder:validation:Enum=unknown;info;low;medium;high
+	// +kubebuilder:default=medium
+	// +optional
+	Severity ComplianceCheckResultSeverity `json:"severity,omitempty"`
+	// The API path of the resource the rule checks, e.g.
+	// /apis/config.openshift.io/v1/apiservers/cluster. Only resources of the
+	// API can be checked.
+	// +kubebuilder:validation:Pattern=^/
+	ResourcePath string `json:"resourcePath"`
+	// An expression that is evaluated against the resource, and needs to
+	// evaluate to true for the rule to pass. A jq expression is evaluated
+	// against the resource, a CEL expression gets it as the resource
+	// variable. If the resource doesn't exist, the resource is null.
+	// +kubebuilder:validation:Pattern=^.+$
+	Assertion string `json:"assertion"`
+	// The language of the assertion, JQ or CEL
+	// +kubebuilder:validation:Enum=JQ;CEL
+	// +kubebuilder:default=JQ
+	// +optional
+	AssertionLanguage CustomRuleAssertionLanguage `json:"assertionLanguage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CustomRule is a platform check defined by the user. TailoredProfiles add
+// custom rules to their scans with the customRules attribute, and the results
+// of custom rules are ComplianceCheckResults like those of any other rule.
+// Custom rules only check resources of the API, they can't check the files or
+// the settings of the nodes.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=customrules,scope=Namespaced,shortName=crule;crules
+// +kubebuilder:printcolumn:name="Severity",type="string",JSONPath=`.spec.severity`
+// +kubebuilder:printcolumn:name="Resource",type="string",JSONPath=`.spec.resourcePath`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type CustomRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Contains the check of the rule
+	Spec CustomRuleSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CustomRuleList contains a list of CustomRule
+type CustomRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CustomRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CustomRule{}, &CustomRuleList{})
+}
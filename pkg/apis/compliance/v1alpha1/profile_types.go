@@ -12,6 +12,14 @@ const ProductTypeAnnotation = "compliance.openshift.io/product-type"
 // or TailoredProfile is targetting. Example: ocp4, rhcos4, ...
 const ProductAnnotation = "compliance.openshift.io/product"
 
+// ProfileDeprecatedAnnotation is set to "true" on Profiles that the content
+// marks as deprecated
+const ProfileDeprecatedAnnotation = "compliance.openshift.io/deprecated"
+
+// ProfileReplacedByAnnotation specifies the name of the Profile that
+// replaces a deprecated Profile, e.g. after the Profile was renamed
+const ProfileReplacedByAnnotation = "compliance.openshift.io/replaced-by"
+
 // ProfileGuidLabel specifies the unique identifier of the Profile
 const ProfileGuidLabel = "compliance.openshift.io/profile-guid"
 
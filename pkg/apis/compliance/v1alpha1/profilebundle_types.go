@@ -1,6 +1,9 @@
 package v1alpha1
 
 import (
+	"path"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -13,9 +16,15 @@ const ProfileBundleFinalizer = "profilebundle.finalizers.compliance.openshift.io
 // and helps users filter such objects
 const ProfileBundleOwnerLabel = "compliance.openshift.io/profile-bundle"
 
-// ProfileImageDigestAnnotation is the parsed out digest of the content image
+// ProfileImageDigestAnnotation is the epoch of the parse that a profile, rule
+// or variable was parsed out of the content in, see Status.ContentEpoch.
+// Despite its name, it's not the digest of the content image.
 const ProfileImageDigestAnnotation = "compliance.openshift.io/image-digest"
 
+// ContentFileAnnotation is the data stream file of the profile bundle that
+// a profile, rule or variable was parsed out of
+const ContentFileAnnotation = "compliance.openshift.io/content-file"
+
 // DataStreamStatusType is the type for the data stream status
 type DataStreamStatusType string
 
@@ -32,9 +41,157 @@ const (
 // Defines the desired state of ProfileBundle
 type ProfileBundleSpec struct {
 	// Is the path for the image that contains the content for this bundle.
-	ContentImage string `json:"contentImage"`
+	// Exactly one of this, contentURL and contentArtifact needs to be set.
+	// +optional
+	ContentImage string `json:"contentImage,omitempty"`
 	// Is the path for the file in the image that contains the content for this bundle.
-	ContentFile string `json:"contentFile"`
+	// When the content is downloaded from contentURL, it's the name of the
+	// file the content is saved as. For contentArtifact, it's the title of
+	// the layer of the artifact that contains the content.
+	// Exactly one of this and contentFiles needs to be set.
+	// +optional
+	ContentFile string `json:"contentFile,omitempty"`
+	// Lists the files in the content image when it contains several data
+	// streams that are parsed, e.g. the ocp4 and the rhcos4 ones. Can only
+	// be used with contentImage.
+	// +optional
+	ContentFiles []ProfileBundleContentFile `json:"contentFiles,omitempty"`
+	// Is the HTTPS URL the data stream of this bundle is downloaded from, as
+	// an alternative to contentImage, e.g. for data streams mirrored to an
+	// internal web server.
+	// +optional
+	ContentURL string `json:"contentURL,omitempty"`
+	// Is the checksum the data stream downloaded from contentURL needs to
+	// match, in the sha256:<hex digest> format. Required with contentURL.
+	// +optional
+	ContentChecksum string `json:"contentChecksum,omitempty"`
+	// Is the reference by digest to an OCI artifact that contains the data
+	// stream of this bundle, e.g. pushed with oras, as an alternative to
+	// contentImage. The layer with the contentFile as its title needs to
+	// have an XML media type.
+	// +optional
+	ContentArtifact string `json:"contentArtifact,omitempty"`
+	// Refers to a Secret in the namespace of the operator that is used to
+	// download the data stream from contentURL or contentArtifact. The
+	// ca.crt key of the Secret contains the CA bundle the certificate of the
+	// server is verified with, the token key a bearer token, the username
+	// and password keys the credentials for basic authentication, and the
+	// .dockerconfigjson key the credentials for the registry of the
+	// artifact. All the keys are optional. With a contentImage, the Secret
+	// needs to be of the kubernetes.io/dockerconfigjson type and is used
+	// to pull the image in addition to the cluster-wide pull secret.
+	// +optional
+	ContentSecret *corev1.LocalObjectReference `json:"contentSecret,omitempty"`
+	// Is how often the contentImage is pulled again and its content
+	// re-parsed, e.g. "24h", for images referred to by a floating tag.
+	// The content isn't refreshed if it's not set.
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+	// Makes the parser only report the Profiles, Rules and Variables the
+	// content no longer has in status.garbageCollection instead of deleting
+	// them, e.g. to review what a new version of the content removes
+	// +optional
+	DryRunGarbageCollection bool `json:"dryRunGarbageCollection,omitempty"`
+}
+
+// Defines a data stream file of a ProfileBundle with several of them
+type ProfileBundleContentFile struct {
+	// Is the path for the file in the image
+	File string `json:"file"`
+	// Is the prefix of the names of the profiles, rules and variables parsed
+	// out of the file. Defaults to the product of the file, e.g. ocp4 for
+	// ssg-ocp4-ds.xml.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// ProfileBundleBenchmark identifies an XCCDF benchmark of a data stream
+type ProfileBundleBenchmark struct {
+	// Is the ID of the benchmark
+	ID string `json:"id"`
+	// Is the version of the benchmark
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// ProfileBundleContentStatus summarizes a content file that was parsed
+type ProfileBundleContentStatus struct {
+	// Is the data stream file
+	File string `json:"file"`
+	// Are the benchmarks of the data stream
+	// +optional
+	Benchmarks []ProfileBundleBenchmark `json:"benchmarks,omitempty"`
+	// Is the number of profiles parsed out of the data stream
+	Profiles int `json:"profiles"`
+	// Is the number of rules parsed out of the data stream
+	Rules int `json:"rules"`
+}
+
+// MaxGarbageCollectionObjects is the maximum number of obsolete objects that
+// are listed in the garbage collection status of a ProfileBundle
+const MaxGarbageCollectionObjects = 50
+
+// ProfileBundleGarbageCollectionStatus describes the Profiles, Rules and
+// Variables of a ProfileBundle that the content that was last parsed no
+// longer has
+type ProfileBundleGarbageCollectionStatus struct {
+	// Whether the obsolete objects were only reported, and weren't deleted
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Is the number of obsolete objects
+	ObsoleteObjectCount int `json:"obsoleteObjectCount"`
+	// Are the obsolete objects, in the <kind>/<name> format. At most
+	// MaxGarbageCollectionObjects objects are listed.
+	// +optional
+	ObsoleteObjects []string `json:"obsoleteObjects,omitempty"`
+}
+
+// MaxContentDiffRules is the maximum number of rules that are listed for
+// each kind of change of a profile in the content diff of a ProfileBundle
+const MaxContentDiffRules = 50
+
+// ProfileBundleContentDiff describes how the parsed content changed compared
+// to the content that was parsed before it
+type ProfileBundleContentDiff struct {
+	// Is the version of the content the diff compares against
+	// +optional
+	PreviousContentVersion string `json:"previousContentVersion,omitempty"`
+	// Are the names of the Profiles that the content added
+	// +optional
+	AddedProfiles []string `json:"addedProfiles,omitempty"`
+	// Are the names of the Profiles that the content removed
+	// +optional
+	RemovedProfiles []string `json:"removedProfiles,omitempty"`
+	// Describes how the rules of the Profiles that are in both contents
+	// changed. Profiles whose rules didn't change aren't listed.
+	// +optional
+	ChangedProfiles []ProfileContentDiff `json:"changedProfiles,omitempty"`
+}
+
+// ProfileContentDiff describes how the rules of a Profile changed between
+// two contents
+type ProfileContentDiff struct {
+	// Is the name of the Profile
+	Name string `json:"name"`
+	// Is the number of rules the profile selects now, but didn't before
+	RulesAdded int `json:"rulesAdded"`
+	// Is the number of rules the profile no longer selects
+	RulesRemoved int `json:"rulesRemoved"`
+	// Is the number of rules the profile still selects, but whose check,
+	// description or any other attribute changed
+	RulesChanged int `json:"rulesChanged"`
+	// Are the names of the added rules. At most MaxContentDiffRules names
+	// are listed.
+	// +optional
+	AddedRules []string `json:"addedRules,omitempty"`
+	// Are the names of the removed rules. At most MaxContentDiffRules names
+	// are listed.
+	// +optional
+	RemovedRules []string `json:"removedRules,omitempty"`
+	// Are the names of the changed rules. At most MaxContentDiffRules names
+	// are listed.
+	// +optional
+	ChangedRules []string `json:"changedRules,omitempty"`
 }
 
 // Defines the observed state of ProfileBundle
@@ -44,6 +201,42 @@ type ProfileBundleStatus struct {
 	DataStreamStatus DataStreamStatusType `json:"dataStreamStatus,omitempty"`
 	// If there's an error in the datastream, it'll be presented here
 	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Is the digest of the content files, their prefixes, the version of
+	// the parser and the platform that were last parsed successfully.
+	// Parsing is skipped when none of them changed.
+	// +optional
+	ParsedContentDigest string `json:"parsedContentDigest,omitempty"`
+	// Is the digest of the content image the content was last parsed from
+	// +optional
+	ContentDigest string `json:"contentDigest,omitempty"`
+	// Is the digest of the content image the content was parsed from
+	// before the current one
+	// +optional
+	PreviousContentDigest string `json:"previousContentDigest,omitempty"`
+	// Is the version of the content that was last parsed successfully,
+	// which is the version of the benchmark of its first content file
+	// +optional
+	ContentVersion string `json:"contentVersion,omitempty"`
+	// Summarizes the content files that were last parsed successfully
+	// +optional
+	Contents []ProfileBundleContentStatus `json:"contents,omitempty"`
+	// Is how long it took to parse the content the last time it was parsed
+	// +optional
+	ParseDuration string `json:"parseDuration,omitempty"`
+	// Describes how the content that was last parsed successfully changed
+	// the profiles and rules of the bundle. It's not set when the content is
+	// parsed for the first time.
+	// +optional
+	ContentDiff *ProfileBundleContentDiff `json:"contentDiff,omitempty"`
+	// Identifies the parse of the content that was last parsed successfully.
+	// The Profiles, Rules and Variables parsed out of it are annotated with
+	// it, and the objects of the bundle annotated otherwise are obsolete.
+	// +optional
+	ContentEpoch string `json:"contentEpoch,omitempty"`
+	// Describes the objects of the bundle that the content that was last
+	// parsed no longer has, and whether they were deleted
+	// +optional
+	GarbageCollection *ProfileBundleGarbageCollectionStatus `json:"garbageCollection,omitempty"`
 	// Defines the conditions for the ProfileBundle. Valid conditions are:
 	//  - Ready: Indicates if the ProfileBundle is Ready parsing or not.
 	// +optional
@@ -75,6 +268,43 @@ type ProfileBundleList struct {
 	Items           []ProfileBundle `json:"items"`
 }
 
+// GetContentFiles returns the data stream files of the bundle, with their
+// prefixes defaulted. The objects parsed out of a single contentFile are
+// prefixed with the name of the bundle.
+func (pb *ProfileBundle) GetContentFiles() []ProfileBundleContentFile {
+	if len(pb.Spec.ContentFiles) == 0 {
+		return []ProfileBundleContentFile{{File: pb.Spec.ContentFile, Prefix: pb.Name}}
+	}
+	files := make([]ProfileBundleContentFile, 0, len(pb.Spec.ContentFiles))
+	for _, file := range pb.Spec.ContentFiles {
+		if file.Prefix == "" {
+			file.Prefix = getContentFileProduct(file.File)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// GetContentFileOf returns the data stream file an object parsed out of the
+// bundle, or a TailoredProfile of it, is in. Objects parsed before the file
+// was annotated are in the first file.
+func (pb *ProfileBundle) GetContentFileOf(obj metav1.Object) string {
+	if file := obj.GetAnnotations()[ContentFileAnnotation]; file != "" {
+		return file
+	}
+	return pb.GetContentFiles()[0].File
+}
+
+// getContentFileProduct returns the product of a data stream file named as
+// ssg-<product>-ds.xml, or its name without the extension otherwise
+func getContentFileProduct(file string) string {
+	name := path.Base(file)
+	if strings.HasPrefix(name, "ssg-") && strings.HasSuffix(name, "-ds.xml") {
+		return strings.TrimSuffix(strings.TrimPrefix(name, "ssg-"), "-ds.xml")
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
 func (s *ProfileBundleStatus) SetConditionPending() {
 	s.Conditions.SetCondition(Condition{
 		Type:    "Ready",
@@ -0,0 +1,55 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Testing profile bundles API", func() {
+	var pb *ProfileBundle
+
+	BeforeEach(func() {
+		pb = &ProfileBundle{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4"},
+			Spec: ProfileBundleSpec{
+				ContentFile: "ssg-ocp4-ds.xml",
+			},
+		}
+	})
+
+	It("prefixes the objects of a single file with the name of the bundle", func() {
+		Expect(pb.GetContentFiles()).To(Equal([]ProfileBundleContentFile{
+			{File: "ssg-ocp4-ds.xml", Prefix: "ocp4"},
+		}))
+	})
+
+	It("defaults the prefixes of several files to their product", func() {
+		pb.Spec.ContentFile = ""
+		pb.Spec.ContentFiles = []ProfileBundleContentFile{
+			{File: "ssg-ocp4-ds.xml"},
+			{File: "/custom/rhcos.xml"},
+			{File: "ssg-rhcos4-ds.xml", Prefix: "node"},
+		}
+		Expect(pb.GetContentFiles()).To(Equal([]ProfileBundleContentFile{
+			{File: "ssg-ocp4-ds.xml", Prefix: "ocp4"},
+			{File: "/custom/rhcos.xml", Prefix: "rhcos"},
+			{File: "ssg-rhcos4-ds.xml", Prefix: "node"},
+		}))
+	})
+
+	It("gets the content file of an object from its annotation", func() {
+		pb.Spec.ContentFile = ""
+		pb.Spec.ContentFiles = []ProfileBundleContentFile{
+			{File: "ssg-ocp4-ds.xml"},
+			{File: "ssg-rhcos4-ds.xml"},
+		}
+		p := &Profile{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{ContentFileAnnotation: "ssg-rhcos4-ds.xml"},
+			},
+		}
+		Expect(pb.GetContentFileOf(p)).To(Equal("ssg-rhcos4-ds.xml"))
+		Expect(pb.GetContentFileOf(&Profile{})).To(Equal("ssg-ocp4-ds.xml"))
+	})
+})
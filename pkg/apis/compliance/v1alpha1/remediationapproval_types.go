@@ -0,0 +1,59 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RemediationApproverAnnotation is set by the operator's webhook to the
+	// user that approved the remediation
+	RemediationApproverAnnotation = "compliance.openshift.io/approved-by"
+	// RemediationApprovedContentAnnotation is set by the operator's webhook
+	// to the hash of the content of the remediation when it was approved,
+	// so that the approval doesn't apply to content that changed since
+	RemediationApprovedContentAnnotation = "compliance.openshift.io/approved-content"
+)
+
+// RemediationApprovalSpec defines the remediation that is approved
+// +k8s:openapi-gen=true
+type RemediationApprovalSpec struct {
+	// The name of the ComplianceRemediation in the same namespace that is
+	// approved to be applied
+	RemediationName string `json:"remediationName"`
+	// Why the remediation was approved, e.g. a reference to a change request
+	// +optional
+	Comment string `json:"comment,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationApproval approves a ComplianceRemediation to be applied when the
+// suite of the remediation requires approvals. As creating approvals is
+// governed by its own RBAC rules, this allows separating who requests that a
+// remediation is applied from who approves it. The approval only applies to
+// the content of the remediation at the time, and the user that set the
+// remediation to be applied can't approve it.
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=remediationapprovals,scope=Namespaced,shortName=rapproval;rapprovals
+// +kubebuilder:printcolumn:name="Remediation",type="string",JSONPath=`.spec.remediationName`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+type RemediationApproval struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Contains the remediation that is approved
+	Spec RemediationApprovalSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationApprovalList contains a list of RemediationApproval
+type RemediationApprovalList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemediationApproval `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RemediationApproval{}, &RemediationApprovalList{})
+}
@@ -0,0 +1,150 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationPlanLabel is set on the remediations a RemediationPlan applied
+// to the name of the plan, so that the plan can un-apply them again
+const RemediationPlanLabel = "compliance.openshift.io/remediation-plan"
+
+// RemediationPlanOrder defines in which order the remediations of a plan are
+// applied
+type RemediationPlanOrder string
+
+const (
+	// RemediationPlanOrderParallel applies all the remediations of the plan
+	// at once
+	RemediationPlanOrderParallel RemediationPlanOrder = "Parallel"
+	// RemediationPlanOrderSeverity applies the remediations of the plan from
+	// the highest to the lowest severity of their checks. The remediations
+	// of a severity are only applied once all the remediations of the
+	// higher severities are.
+	RemediationPlanOrderSeverity RemediationPlanOrder = "Severity"
+)
+
+// RemediationPlanPhase is the phase of a RemediationPlan
+type RemediationPlanPhase string
+
+const (
+	// RemediationPlanPending means that the plan isn't applied
+	RemediationPlanPending RemediationPlanPhase = "Pending"
+	// RemediationPlanApplying means that some remediations of the plan
+	// aren't applied yet
+	RemediationPlanApplying RemediationPlanPhase = "Applying"
+	// RemediationPlanApplied means that all the remediations of the plan
+	// are applied
+	RemediationPlanApplied RemediationPlanPhase = "Applied"
+	// RemediationPlanError means that some remediations of the plan failed
+	// to be applied, or that its pre-apply hook failed
+	RemediationPlanError RemediationPlanPhase = "Error"
+)
+
+// RemediationPlanSpec defines which remediations belong to the plan and how
+// they are applied
+// +k8s:openapi-gen=true
+type RemediationPlanSpec struct {
+	// Selects the ComplianceRemediations in the namespace of the plan that
+	// belong to it by their labels, e.g. by the compliance.openshift.io/suite
+	// label. All the remediations of the namespace are selected if unset.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Only the selected remediations that pass this filter, based on the
+	// check they were generated from, belong to the plan
+	// +optional
+	Filter *RemediationApplyFilter `json:"filter,omitempty"`
+	// Only the MachineConfig and KubeletConfig remediations of these
+	// MachineConfigPools belong to the plan. The other remediations aren't
+	// restricted. Remediations of all pools belong to the plan if empty.
+	// +listType=atomic
+	// +optional
+	Pools []string `json:"pools,omitempty"`
+	// The order in which the remediations of the plan are applied
+	// +kubebuilder:validation:Enum=Parallel;Severity
+	// +kubebuilder:default=Parallel
+	// +optional
+	Order RemediationPlanOrder `json:"order,omitempty"`
+	// Applies the MachineConfig remediations of the plan as one
+	// MachineConfig per pool, so that a pool is only rebooted once for them
+	// +optional
+	Batch bool `json:"batch,omitempty"`
+	// Applies the remediations of the plan. Setting it to false un-applies
+	// the remediations that the plan applied.
+	// +optional
+	Apply bool `json:"apply,omitempty"`
+	// A hook that is run before the remediations of each step of the plan
+	// are applied, e.g. to back up etcd. The remediations of the step are
+	// only applied once the hook succeeded.
+	// +optional
+	PreApplyHook *RemediationPlanHook `json:"preApplyHook,omitempty"`
+}
+
+// RemediationPlanHook defines a hook that a RemediationPlan runs
+// +k8s:openapi-gen=true
+type RemediationPlanHook struct {
+	// The name of a CronJob in the namespace of the plan. The hook runs a
+	// Job created from its job template, and succeeds once the Job
+	// completes. The CronJob is best suspended so that it only runs as a
+	// hook.
+	CronJobName string `json:"cronJobName"`
+}
+
+// RemediationPlanStatus defines the observed state of RemediationPlan
+// +k8s:openapi-gen=true
+type RemediationPlanStatus struct {
+	// +optional
+	Phase RemediationPlanPhase `json:"phase,omitempty"`
+	// The number of remediations that belong to the plan
+	// +optional
+	TotalRemediations int32 `json:"totalRemediations,omitempty"`
+	// The number of remediations of the plan that are applied
+	// +optional
+	AppliedRemediations int32 `json:"appliedRemediations,omitempty"`
+	// The severity whose remediations are being applied when the
+	// remediations are applied by severity
+	// +optional
+	CurrentSeverity ComplianceCheckResultSeverity `json:"currentSeverity,omitempty"`
+	// The remediations of the plan that failed to be applied
+	// +listType=atomic
+	// +optional
+	FailedRemediations []string `json:"failedRemediations,omitempty"`
+	// The Job the pre-apply hook of the plan runs before applying the
+	// current step of the plan
+	// +optional
+	HookJob string `json:"hookJob,omitempty"`
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationPlan groups ComplianceRemediations so that they are applied as
+// a unit, in a defined order, and reports their aggregate status
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=remediationplans,scope=Namespaced,shortName=rplan;rplans
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Applied",type="integer",JSONPath=`.status.appliedRemediations`
+// +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=`.status.totalRemediations`
+type RemediationPlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Contains the definition of the plan
+	Spec RemediationPlanSpec `json:"spec,omitempty"`
+	// +optional
+	Status RemediationPlanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RemediationPlanList contains a list of RemediationPlan
+type RemediationPlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RemediationPlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RemediationPlan{}, &RemediationPlanList{})
+}
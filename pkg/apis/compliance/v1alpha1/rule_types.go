@@ -1,8 +1,12 @@
 package v1alpha1
 
 import (
+	"regexp"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // RuleIDAnnotationKey exposes the DNS-friendly name of a rule as an annotation.
@@ -21,6 +25,12 @@ const RuleVariableAnnotationKey = "compliance.openshift.io/rule-variable"
 // RuleProfileAnnotationKey is the annotation used to store which profiles are using a particular rule
 const RuleProfileAnnotationKey = "compliance.openshift.io/profiles"
 
+// ControlLabelDomain is the domain of the labels that mark the controls of
+// a standard a rule implements, which are in the
+// <standard>.control.compliance.openshift.io/<control> format, e.g.
+// nist-800-53.control.compliance.openshift.io/AC-2
+const ControlLabelDomain = "control.compliance.openshift.io"
+
 const (
 	CheckTypePlatform = "Platform"
 	CheckTypeNode     = "Node"
@@ -50,6 +60,19 @@ type RulePayload struct {
 	// +optional
 	// +listType=atomic
 	AvailableFixes []FixDefinition `json:"availableFixes,omitempty"`
+	// The controls of compliance standards the Rule implements, as the
+	// references of the rule list them
+	// +optional
+	// +listType=atomic
+	Controls []RuleControls `json:"controls,omitempty"`
+}
+
+// RuleControls are the controls of a compliance standard a rule implements
+type RuleControls struct {
+	// The name of the standard, e.g. NIST-800-53
+	Standard string `json:"standard"`
+	// The IDs of the controls, as the standard names them, e.g. AC-2(1)
+	Controls []string `json:"controls"`
 }
 
 // +kubebuilder:object:root=true
@@ -87,6 +110,32 @@ type RuleList struct {
 	Items           []Rule `json:"items"`
 }
 
+var invalidControlLabelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// GetControlLabelKeys returns the keys of the labels that mark a rule
+// implementing a control of a standard. Controls that enhance a base control,
+// like AC-2(1), are labeled with the base control too, so that selecting the
+// base control selects its enhancements. Control IDs that can't be part of a
+// label key have no labels.
+func GetControlLabelKeys(standard, control string) []string {
+	prefix := strings.ToLower(standard) + "." + ControlLabelDomain + "/"
+	ids := []string{control}
+	if base, _, ok := strings.Cut(control, "("); ok && base != "" {
+		ids = append(ids, base)
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		name := strings.Trim(invalidControlLabelChars.ReplaceAllString(strings.TrimSpace(id), "_"), "._-")
+		key := prefix + name
+		if name == "" || len(validation.IsQualifiedName(key)) > 0 {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func init() {
 	SchemeBuilder.Register(&Rule{}, &RuleList{})
 }
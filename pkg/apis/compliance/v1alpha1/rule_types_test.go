@@ -0,0 +1,29 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Testing rules API", func() {
+	It("labels a control with the standard in the label prefix", func() {
+		Expect(GetControlLabelKeys("NIST-800-53", "AC-2")).To(Equal([]string{
+			"nist-800-53.control.compliance.openshift.io/AC-2",
+		}))
+		Expect(GetControlLabelKeys("CIS-OCP", "1.2.35")).To(Equal([]string{
+			"cis-ocp.control.compliance.openshift.io/1.2.35",
+		}))
+	})
+
+	It("labels the enhancements of a control with their base control", func() {
+		Expect(GetControlLabelKeys("NIST-800-53", "IA-5(1)(a)")).To(Equal([]string{
+			"nist-800-53.control.compliance.openshift.io/IA-5_1_a",
+			"nist-800-53.control.compliance.openshift.io/IA-5",
+		}))
+	})
+
+	It("doesn't label controls that can't be part of a label key", func() {
+		Expect(GetControlLabelKeys("NIST-800-53", "()")).To(BeEmpty())
+		Expect(GetControlLabelKeys("Some Standard", "AC-2")).To(BeEmpty())
+	})
+})
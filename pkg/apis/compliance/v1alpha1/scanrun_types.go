@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ScanRunRequesterAnnotation is set on ScanRuns on admission to the name of
+// the user that created them
+const ScanRunRequesterAnnotation = "compliance.openshift.io/requested-by"
+
+// ScanRunPhase is the phase of a ScanRun
+type ScanRunPhase string
+
+const (
+	// ScanRunPending means that the scans of the binding weren't re-run
+	// yet, e.g. because its suite wasn't created yet
+	ScanRunPending ScanRunPhase = "Pending"
+	// ScanRunRunning means that the scans of the binding were re-run and
+	// some of them aren't done yet
+	ScanRunRunning ScanRunPhase = "Running"
+	// ScanRunDone means that all the scans of the binding are done with
+	// the run the ScanRun triggered
+	ScanRunDone ScanRunPhase = "Done"
+	// ScanRunError means that the scans couldn't be re-run, e.g. because
+	// the binding doesn't exist
+	ScanRunError ScanRunPhase = "Error"
+)
+
+// ScanRunSpec defines which scans a ScanRun runs
+// +k8s:openapi-gen=true
+type ScanRunSpec struct {
+	// The name of the ScanSettingBinding, in the namespace of the ScanRun,
+	// whose scans are run
+	ScanSettingBindingName string `json:"scanSettingBindingName"`
+}
+
+// ScanRunScanStatus is the status of a scan a ScanRun re-ran
+// +k8s:openapi-gen=true
+type ScanRunScanStatus struct {
+	// The name of the ComplianceScan
+	Name string `json:"name"`
+	// The index the scan had when it was re-run. The run the ScanRun
+	// triggered is the first one with a higher index.
+	Index int64 `json:"index"`
+	// The result of the run the ScanRun triggered, once it's done
+	// +optional
+	Result ComplianceScanStatusResult `json:"result,omitempty"`
+}
+
+// ScanRunStatus defines the observed state of ScanRun
+// +k8s:openapi-gen=true
+type ScanRunStatus struct {
+	// +optional
+	Phase ScanRunPhase `json:"phase,omitempty"`
+	// Who created the ScanRun
+	// +optional
+	Requester string `json:"requester,omitempty"`
+	// The name of the ComplianceSuite of the binding
+	// +optional
+	SuiteName string `json:"suiteName,omitempty"`
+	// The scans that were re-run
+	// +listType=atomic
+	// +optional
+	Scans []ScanRunScanStatus `json:"scans,omitempty"`
+	// The worst result of the scans, once they're done
+	// +optional
+	Result ComplianceScanStatusResult `json:"result,omitempty"`
+	// When the scans were re-run
+	// +optional
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// When all the scans were done
+	// +optional
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	// +optional
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScanRun runs the scans of a ScanSettingBinding once, right away, and
+// reports who requested the run and when it completed
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=scanruns,scope=Namespaced,shortName=srun
+// +kubebuilder:printcolumn:name="Binding",type="string",JSONPath=`.spec.scanSettingBindingName`
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Result",type="string",JSONPath=`.status.result`
+// +kubebuilder:printcolumn:name="Requester",type="string",JSONPath=`.status.requester`
+type ScanRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ScanRunSpec `json:"spec,omitempty"`
+	// +optional
+	Status ScanRunStatus `json:"status,omitempty"`
+}
+
+// IsFinished tells whether the ScanRun is done with, successfully or not
+func (s *ScanRun) IsFinished() bool {
+	return s.Status.Phase == ScanRunDone || s.Status.Phase == ScanRunError
+}
+
+// AggregateResult returns the worst result of the scans of the ScanRun
+func (s *ScanRunStatus) AggregateResult() ComplianceScanStatusResult {
+	result := ResultCompliant
+	for _, scan := range s.Scans {
+		result = resultCompare(result, scan.Result)
+	}
+	return result
+}
+
+// +kubebuilder:object:root=true
+
+// ScanRunList contains a list of ScanRun
+type ScanRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScanRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ScanRun{}, &ScanRunList{})
+}
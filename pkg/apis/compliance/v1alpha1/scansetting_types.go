@@ -1,13 +1,37 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	AllRoles = "@all"
+	// PlatformRole selects the platform scans in the per-role settings of
+	// a ScanSetting
+	PlatformRole = "@platform"
 )
 
+// RoleRawResultStorageSettings overrides the raw result storage settings for
+// the scans of a role
+type RoleRawResultStorageSettings struct {
+	// The role of the node scans the settings apply to, which is one of the
+	// roles of the ScanSetting, or `@platform` for the platform scans.
+	Role string `json:"role"`
+	// Overrides the amount of storage to ask for storing the raw results.
+	// +optional
+	Size string `json:"size,omitempty"`
+	// Overrides the StorageClassName to use when creating the
+	// PersistentVolumeClaim to hold the raw results.
+	// +optional
+	// +nullable
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Overrides the access modes that the PersistentVolume will be created
+	// with.
+	// +optional
+	PVAccessModes []corev1.PersistentVolumeAccessMode `json:"pvAccessModes,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // ScanSetting is the Schema for the scansettings API
@@ -33,6 +57,33 @@ type ScanSetting struct {
 	// Note that tolerations must still be configured for
 	// the opeartor to appropriately schedule scans.
 	Roles []string `json:"roles,omitempty"`
+	// Overrides the raw result storage settings for the scans of some of
+	// the roles, e.g. to ask for more storage for the scans of the worker
+	// nodes than for the platform scans.
+	// +optional
+	RoleRawResultStorage []RoleRawResultStorageSettings `json:"roleRawResultStorage,omitempty"`
+}
+
+// GetRawResultStorage returns the raw result storage settings for the scans
+// of the role, which is PlatformRole for the platform scans
+func (s *ScanSetting) GetRawResultStorage(role string) RawResultStorageSettings {
+	storage := *s.RawResultStorage.DeepCopy()
+	for _, override := range s.RoleRawResultStorage {
+		if override.Role != role {
+			continue
+		}
+		if override.Size != "" {
+			storage.Size = override.Size
+		}
+		if override.StorageClassName != nil {
+			className := *override.StorageClassName
+			storage.StorageClassName = &className
+		}
+		if len(override.PVAccessModes) > 0 {
+			storage.PVAccessModes = append([]corev1.PersistentVolumeAccessMode{}, override.PVAccessModes...)
+		}
+	}
+	return storage
 }
 
 // +kubebuilder:object:root=true
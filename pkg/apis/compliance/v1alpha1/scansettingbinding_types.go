@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -20,6 +22,30 @@ type NamedObjectReference struct {
 	APIGroup string `json:"apiGroup,omitempty"`
 }
 
+// ProfileSelector selects the Profiles or TailoredProfiles in the namespace
+// of a ScanSettingBinding by their labels
+type ProfileSelector struct {
+	// The kind of the objects to select
+	// +kubebuilder:validation:Enum=Profile;TailoredProfile
+	Kind string `json:"kind"`
+	// Selects the objects by their labels. It can't be empty, as that
+	// would select all the objects of the kind.
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// ResultMetadataPropagation selects the labels and annotations of a
+// ScanSettingBinding that are copied onto the results of its scans. A key
+// that ends with '*' selects all the keys that start with what precedes it,
+// e.g. 'example.com/*'.
+type ResultMetadataPropagation struct {
+	// The keys of the labels to copy
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+	// The keys of the annotations to copy
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 
 // ScanSettingBinding is the Schema for the scansettingbindings API
@@ -32,6 +58,27 @@ type ScanSettingBinding struct {
 
 	Spec     ScanSettingBindingSpec `json:"spec,omitempty"`
 	Profiles []NamedObjectReference `json:"profiles,omitempty"`
+	// Selects profiles to scan for by their labels, on top of the ones in
+	// profiles, e.g. all the TailoredProfiles labeled 'baseline=org'. The
+	// profiles that start or stop matching are added to or removed from
+	// the scans of the binding.
+	// +optional
+	ProfileSelectors []ProfileSelector `json:"profileSelectors,omitempty"`
+	// Is a list of names of Rule objects that the scans should not check
+	// for. Each rule is only excluded from the scans of profiles that come
+	// from the same ProfileBundle as the rule.
+	// +optional
+	ExcludeRules []string `json:"excludeRules,omitempty"`
+	// Is a list of names of ScanSettingBindings in the same namespace whose
+	// suites need to be done before the scans of this binding are launched.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Selects the labels and annotations of the binding, e.g. the team or
+	// the ticket the scans are run for, that are copied onto the
+	// ComplianceCheckResults and ComplianceRemediations of its scans. The
+	// labels and annotations of the operator are never copied.
+	// +optional
+	ResultMetadataPropagation *ResultMetadataPropagation `json:"resultMetadataPropagation,omitempty"`
 	// +kubebuilder:default={"name":"default","kind": "ScanSetting", "apiGroup": "compliance.openshift.io/v1alpha1"}
 	SettingsRef *NamedObjectReference `json:"settingsRef,omitempty"`
 	// +optional
@@ -60,6 +107,51 @@ type ScanSettingBindingList struct {
 	Items           []ScanSettingBinding `json:"items"`
 }
 
+// GetPropagatedResultLabels returns the labels of the binding that are
+// copied onto the results of its scans
+func (s *ScanSettingBinding) GetPropagatedResultLabels() map[string]string {
+	if s.ResultMetadataPropagation == nil {
+		return nil
+	}
+	return selectMetadata(s.Labels, s.ResultMetadataPropagation.Labels)
+}
+
+// GetPropagatedResultAnnotations returns the annotations of the binding that
+// are copied onto the results of its scans
+func (s *ScanSettingBinding) GetPropagatedResultAnnotations() map[string]string {
+	if s.ResultMetadataPropagation == nil {
+		return nil
+	}
+	return selectMetadata(s.Annotations, s.ResultMetadataPropagation.Annotations)
+}
+
+func selectMetadata(metadata map[string]string, keys []string) map[string]string {
+	var selected map[string]string
+	for key, value := range metadata {
+		if IsOperatorMetadataKey(key) || !metadataKeyMatches(key, keys) {
+			continue
+		}
+		if selected == nil {
+			selected = make(map[string]string)
+		}
+		selected[key] = value
+	}
+	return selected
+}
+
+func metadataKeyMatches(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, isPrefix := strings.CutSuffix(pattern, "*"); isPrefix {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if key == pattern {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ScanSettingBindingStatus) SetConditionPending() {
 	s.Conditions.SetCondition(Condition{
 		Type:    "Ready",
@@ -96,6 +188,22 @@ func (s *ScanSettingBindingStatus) SetConditionSuspended() {
 	})
 }
 
+// ScanSettingBindingConditionDeprecatedProfiles is set when the binding
+// refers to Profiles that its content marks as deprecated
+const ScanSettingBindingConditionDeprecatedProfiles ConditionType = "DeprecatedProfiles"
+
+// SetConditionDeprecatedProfiles sets the condition explaining which of the
+// Profiles of the binding are deprecated and what replaces them. It returns
+// whether the condition changed.
+func (s *ScanSettingBindingStatus) SetConditionDeprecatedProfiles(msg string) bool {
+	return s.Conditions.SetCondition(Condition{
+		Type:    ScanSettingBindingConditionDeprecatedProfiles,
+		Status:  corev1.ConditionTrue,
+		Reason:  "ProfilesDeprecated",
+		Message: msg,
+	})
+}
+
 func init() {
 	SchemeBuilder.Register(&ScanSettingBinding{}, &ScanSettingBindingList{})
 }
@@ -18,12 +18,37 @@ const RuleLastCheckTypeChangedAnnotationKey = "compliance.openshift.io/rule-last
 // ExtendedProfileGuidLabel is a label used to store the unique ID of the profile being extends
 const ExtendedProfileGuidLabel = "compliance.openshift.io/extended-profile-unique-id"
 
+// TailoringImportedAnnotation is the annotation key used to store the
+// resource version of the ConfigMap the tailoring file was last imported from
+const TailoringImportedAnnotation = "compliance.openshift.io/imported-tailoring"
+
+// DefaultTailoringImportKey is the key of the ConfigMap with the tailoring
+// file to import, unless another one is set
+const DefaultTailoringImportKey = "tailoring.xml"
+
+// CloneProfileAnnotation is the annotation used to request the profile it
+// names to be cloned into the TailoredProfile
+const CloneProfileAnnotation = "compliance.openshift.io/clone-profile"
+
+// ClonedFromProfileAnnotation is the annotation used to store the name of the
+// profile the TailoredProfile was cloned from
+const ClonedFromProfileAnnotation = "compliance.openshift.io/cloned-from-profile"
+
+// TailoredProfileOutputLabel is the label of the ConfigMaps with the
+// tailoring files of a TailoredProfile, set to its name
+const TailoredProfileOutputLabel = "tailored-profile"
+
 // RuleReferenceSpec specifies a rule to be selected/deselected, as well as the reason why
 type RuleReferenceSpec struct {
 	// Name of the rule that's being referenced
 	Name string `json:"name"`
 	// Rationale of why this rule is being selected/deselected
 	Rationale string `json:"rationale"`
+	// ProfileBundle the rule comes from, if it isn't the ProfileBundle of
+	// the tailored profile. Such rules can only be enabled, and are checked
+	// by additional scans of the content of their ProfileBundle.
+	// +optional
+	ProfileBundle string `json:"profileBundle,omitempty"`
 }
 
 // ValueReferenceSpec specifies a value to be set for a variable with a reason why
@@ -36,11 +61,54 @@ type VariableValueSpec struct {
 	Value string `json:"value"`
 }
 
+// TailoringImportSpec references an XCCDF tailoring file to import into the
+// TailoredProfile
+type TailoringImportSpec struct {
+	// Is the name of the ConfigMap, in the namespace of the TailoredProfile,
+	// with the tailoring file
+	ConfigMapName string `json:"configMapName"`
+	// Is the key of the ConfigMap with the tailoring file. Defaults to
+	// tailoring.xml.
+	// +optional
+	Key string `json:"key,omitempty"`
+	// Is the name of the ProfileBundle with the content the tailoring file
+	// tailors. Defaults to the bundle with the data stream file the benchmark
+	// of the tailoring file refers to.
+	// +optional
+	ProfileBundle string `json:"profileBundle,omitempty"`
+	// Is the XCCDF ID of the profile to import. Only needed if the tailoring
+	// file has several profiles.
+	// +optional
+	ProfileID string `json:"profileID,omitempty"`
+}
+
+// GetKey returns the key of the ConfigMap with the tailoring file
+func (t *TailoringImportSpec) GetKey() string {
+	if t.Key == "" {
+		return DefaultTailoringImportKey
+	}
+	return t.Key
+}
+
 // TailoredProfileSpec defines the desired state of TailoredProfile
 type TailoredProfileSpec struct {
+	// Imports an XCCDF tailoring file, e.g. one used with oscap, from a
+	// ConfigMap. The profile the tailoring file extends and the rules and
+	// values it tailors replace those of the TailoredProfile, and so do its
+	// title and description if it has them. The tailoring file is imported
+	// again whenever the ConfigMap changes.
+	// +optional
+	// +nullable
+	ImportFrom *TailoringImportSpec `json:"importFrom,omitempty"`
 	// +optional
 	// Points to the name of the profile to extend
 	Extends string `json:"extends,omitempty"`
+	// +optional
+	// Points to the name of a TailoredProfile in the same namespace to
+	// extend. The selections of this TailoredProfile are applied on top of
+	// the ones of the TailoredProfiles it extends. Can't be set along with
+	// extends.
+	ExtendsTailoredProfile string `json:"extendsTailoredProfile,omitempty"`
 	// Title for the tailored profile. It can't be empty.
 	// +kubebuilder:validation:Pattern=^.+$
 	Title string `json:"title"`
@@ -59,6 +127,32 @@ type TailoredProfileSpec struct {
 	// +optional
 	// +nullable
 	ManualRules []RuleReferenceSpec `json:"manualRules,omitempty"`
+	// Enables the rules of the ProfileBundle with any of these severities,
+	// e.g. high
+	// +optional
+	// +nullable
+	EnableRulesBySeverity []string `json:"enableRulesBySeverity,omitempty"`
+	// Disables the rules of the extended profile with any of these
+	// severities, e.g. low
+	// +optional
+	// +nullable
+	DisableRulesBySeverity []string `json:"disableRulesBySeverity,omitempty"`
+	// Enables the rules of the ProfileBundle implementing a control that
+	// matches any of these references, in the <standard>:<control> format,
+	// e.g. CIS-OCP:1.2.* The control can be a shell pattern.
+	// +optional
+	// +nullable
+	EnableRulesByReference []string `json:"enableRulesByReference,omitempty"`
+	// Disables the rules of the extended profile implementing a control
+	// that matches any of these references
+	// +optional
+	// +nullable
+	DisableRulesByReference []string `json:"disableRulesByReference,omitempty"`
+	// Adds the referenced CustomRules to the scans of the profile. Custom
+	// rules can only be added to platform profiles.
+	// +optional
+	// +nullable
+	CustomRules []RuleReferenceSpec `json:"customRules,omitempty"`
 	// Sets the referenced variables to selected values
 	// +optional
 	// +nullable
@@ -87,6 +181,59 @@ type TailoredProfileStatus struct {
 	State        TailoredProfileState `json:"state,omitempty"`
 	ErrorMessage string               `json:"errorMessage,omitempty"`
 	Warnings     string               `json:"warnings,omitempty"`
+	// The tailoring files of the rules of other ProfileBundles, one per
+	// ProfileBundle
+	// +optional
+	AdditionalOutputs []TailoredProfileOutput `json:"additionalOutputs,omitempty"`
+	// The rules the severities and references of the spec select, on top of
+	// the rules the spec lists
+	// +optional
+	SelectedRules *SelectedRules `json:"selectedRules,omitempty"`
+	// What the rules and variables the tailored profile references resolve
+	// to
+	// +optional
+	Validation *TailoredProfileValidation `json:"validation,omitempty"`
+}
+
+// TailoredProfileValidation details what the selections of a tailored
+// profile resolve to, to check whether the tailoring does what was intended
+type TailoredProfileValidation struct {
+	// The number of rules the tailored profile enables, including those of
+	// the extended profile and the manual rules
+	EnabledRuleCount int `json:"enabledRuleCount"`
+	// The rules and variables the tailored profile references that don't
+	// exist
+	// +optional
+	UnknownReferences []string `json:"unknownReferences,omitempty"`
+	// The selections that don't change the extended profile, e.g. enabling
+	// a rule it already enables
+	// +optional
+	NoOpSelections []string `json:"noOpSelections,omitempty"`
+}
+
+// SelectedRules are the names of the rules selected by their severity or by
+// the controls they implement
+type SelectedRules struct {
+	// +optional
+	EnableRules []string `json:"enableRules,omitempty"`
+	// +optional
+	DisableRules []string `json:"disableRules,omitempty"`
+}
+
+// TailoredProfileOutput is a tailoring file of the rules a tailored profile
+// selects from a ProfileBundle other than its own
+type TailoredProfileOutput struct {
+	// The ProfileBundle the rules come from
+	ProfileBundle string `json:"profileBundle"`
+	// The data stream file of the ProfileBundle the rules are in
+	// +optional
+	ContentFile string `json:"contentFile,omitempty"`
+	// The type of scan that checks the rules
+	ScanType ComplianceScanType `json:"scanType"`
+	// The XCCDF ID of the profile of the tailoring file
+	ID string `json:"id"`
+	// Points to the generated resource
+	OutputRef OutputRef `json:"outputRef"`
 }
 
 // OutputRef is a reference to the object created from the tailored profile
@@ -147,4 +147,55 @@ var _ = Describe("Testing variables API", func() {
 			Expect(v.Value).To(BeEquivalentTo("123"))
 		})
 	})
+
+	Context("variable value constraints", func() {
+		BeforeEach(func() {
+			lowerBound := 1
+			upperBound := 10
+			v = &Variable{
+				VariablePayload: VariablePayload{
+					ID:    "constrained_number",
+					Type:  "number",
+					Value: "5",
+					Constraints: &ValueConstraints{
+						LowerBound: &lowerBound,
+						UpperBound: &upperBound,
+					},
+				},
+			}
+		})
+
+		It("accepts values within the bounds", func() {
+			Expect(v.ValidateValue("1")).To(Succeed())
+			Expect(v.ValidateValue("10")).To(Succeed())
+		})
+
+		It("denies values out of the bounds", func() {
+			Expect(v.ValidateValue("0")).ToNot(Succeed())
+			Expect(v.ValidateValue("11")).ToNot(Succeed())
+		})
+
+		It("denies values of the wrong type", func() {
+			Expect(v.ValidateValue("five")).ToNot(Succeed())
+		})
+
+		It("denies values that don't match the whole pattern", func() {
+			v.Type = "string"
+			v.Constraints = &ValueConstraints{Match: "[0-9]+s"}
+			Expect(v.ValidateValue("30s")).To(Succeed())
+			Expect(v.ValidateValue("30s or so")).ToNot(Succeed())
+		})
+
+		It("denies values that aren't one of the enforced choices", func() {
+			v.Type = "string"
+			v.Constraints = &ValueConstraints{Choices: []string{"yes", "no"}}
+			Expect(v.ValidateValue("no")).To(Succeed())
+			Expect(v.ValidateValue("maybe")).ToNot(Succeed())
+		})
+
+		It("still sets values that don't meet the constraints", func() {
+			Expect(v.SetValue("42")).To(Succeed())
+			Expect(v.Value).To(BeEquivalentTo("42"))
+		})
+	})
 })
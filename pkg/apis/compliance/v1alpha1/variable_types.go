@@ -2,7 +2,10 @@ package v1alpha1
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -23,6 +26,25 @@ type ValueSelection struct {
 	Value string `json:"value,omitempty"`
 }
 
+// ValueConstraints restricts the values a variable can be set to beyond its
+// type, as defined by the XCCDF content
+type ValueConstraints struct {
+	// The lowest value a number variable can be set to
+	// +optional
+	LowerBound *int `json:"lowerBound,omitempty"`
+	// The highest value a number variable can be set to
+	// +optional
+	UpperBound *int `json:"upperBound,omitempty"`
+	// A regular expression the whole value of the variable needs to match
+	// +optional
+	Match string `json:"match,omitempty"`
+	// The only values the variable can be set to. Unlike the selections,
+	// these are enforced.
+	// +optional
+	// +listType=atomic
+	Choices []string `json:"choices,omitempty"`
+}
+
 type VariablePayload struct {
 	// FIXME: several values are shared with Rule object, maybe create a shared
 	// struct? The shared values are documented in table 5 of the XCCDF spec
@@ -43,6 +65,9 @@ type VariablePayload struct {
 	// +nullable
 	// +listType=atomic
 	Selections []ValueSelection `json:"selections,omitempty"`
+	// The constraints the content puts on the value of the variable, if any
+	// +optional
+	Constraints *ValueConstraints `json:"constraints,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -64,6 +89,46 @@ func (v *Variable) SetValue(val string) error {
 	return nil
 }
 
+// ValidateValue checks that the value both has the type of the variable and
+// meets its constraints
+func (v *Variable) ValidateValue(val string) error {
+	if err := v.validateType(val); err != nil {
+		return err
+	}
+	c := v.Constraints
+	if c == nil {
+		return nil
+	}
+	if v.Type == VarTypeNumber {
+		// The type was validated above
+		number, _ := strconv.Atoi(val)
+		if c.LowerBound != nil && number < *c.LowerBound {
+			return fmt.Errorf("value %d is lower than the lower bound %d", number, *c.LowerBound)
+		}
+		if c.UpperBound != nil && number > *c.UpperBound {
+			return fmt.Errorf("value %d is higher than the upper bound %d", number, *c.UpperBound)
+		}
+	}
+	if c.Match != "" {
+		re, err := regexp.Compile("^(?:" + c.Match + ")$")
+		if err != nil {
+			return fmt.Errorf("the value can't be matched against %q: %w", c.Match, err)
+		}
+		if !re.MatchString(val) {
+			return fmt.Errorf("value %q doesn't match %q", val, c.Match)
+		}
+	}
+	if len(c.Choices) > 0 {
+		for _, choice := range c.Choices {
+			if val == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not one of %s", val, strings.Join(c.Choices, ","))
+	}
+	return nil
+}
+
 func (v *Variable) validateType(val string) error {
 	var err error
 	switch v.Type {
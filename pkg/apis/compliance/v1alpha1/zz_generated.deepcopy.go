@@ -21,11 +21,58 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckResultChange) DeepCopyInto(out *CheckResultChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckResultChange.
+func (in *CheckResultChange) DeepCopy() *CheckResultChange {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckResultChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckResultHistoryEntry) DeepCopyInto(out *CheckResultHistoryEntry) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckResultHistoryEntry.
+func (in *CheckResultHistoryEntry) DeepCopy() *CheckResultHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckResultHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckStatusCounts) DeepCopyInto(out *CheckStatusCounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckStatusCounts.
+func (in *CheckStatusCounts) DeepCopy() *CheckStatusCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckStatusCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceCheckResult) DeepCopyInto(out *ComplianceCheckResult) {
 	*out = *in
@@ -41,6 +88,28 @@ func (in *ComplianceCheckResult) DeepCopyInto(out *ComplianceCheckResult) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]CheckResultHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Review != nil {
+		in, out := &in.Review, &out.Review
+		*out = new(ManualCheckReview)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Remediations != nil {
+		in, out := &in.Remediations, &out.Remediations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotApplicableReason != nil {
+		in, out := &in.NotApplicableReason, &out.NotApplicableReason
+		*out = new(NotApplicableReason)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheckResult.
@@ -61,6 +130,110 @@ func (in *ComplianceCheckResult) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceCheckResultDiff) DeepCopyInto(out *ComplianceCheckResultDiff) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheckResultDiff.
+func (in *ComplianceCheckResultDiff) DeepCopy() *ComplianceCheckResultDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceCheckResultDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceCheckResultDiff) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceCheckResultDiffList) DeepCopyInto(out *ComplianceCheckResultDiffList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ComplianceCheckResultDiff, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheckResultDiffList.
+func (in *ComplianceCheckResultDiffList) DeepCopy() *ComplianceCheckResultDiffList {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceCheckResultDiffList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceCheckResultDiffList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceCheckResultDiffSpec) DeepCopyInto(out *ComplianceCheckResultDiffSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheckResultDiffSpec.
+func (in *ComplianceCheckResultDiffSpec) DeepCopy() *ComplianceCheckResultDiffSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceCheckResultDiffSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceCheckResultDiffStatus) DeepCopyInto(out *ComplianceCheckResultDiffStatus) {
+	*out = *in
+	if in.NewFailures != nil {
+		in, out := &in.NewFailures, &out.NewFailures
+		*out = make([]CheckResultChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regressions != nil {
+		in, out := &in.Regressions, &out.Regressions
+		*out = make([]CheckResultChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fixes != nil {
+		in, out := &in.Fixes, &out.Fixes
+		*out = make([]CheckResultChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceCheckResultDiffStatus.
+func (in *ComplianceCheckResultDiffStatus) DeepCopy() *ComplianceCheckResultDiffStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceCheckResultDiffStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceCheckResultList) DeepCopyInto(out *ComplianceCheckResultList) {
 	*out = *in
@@ -99,7 +272,7 @@ func (in *ComplianceRemediation) DeepCopyInto(out *ComplianceRemediation) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediation.
@@ -120,6 +293,52 @@ func (in *ComplianceRemediation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationAttempt) DeepCopyInto(out *ComplianceRemediationAttempt) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationAttempt.
+func (in *ComplianceRemediationAttempt) DeepCopy() *ComplianceRemediationAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationDryRunStatus) DeepCopyInto(out *ComplianceRemediationDryRunStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationDryRunStatus.
+func (in *ComplianceRemediationDryRunStatus) DeepCopy() *ComplianceRemediationDryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationDryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationFieldDiff) DeepCopyInto(out *ComplianceRemediationFieldDiff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationFieldDiff.
+func (in *ComplianceRemediationFieldDiff) DeepCopy() *ComplianceRemediationFieldDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationFieldDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationList) DeepCopyInto(out *ComplianceRemediationList) {
 	*out = *in
@@ -171,10 +390,41 @@ func (in *ComplianceRemediationPayload) DeepCopy() *ComplianceRemediationPayload
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationRollbackStatus) DeepCopyInto(out *ComplianceRemediationRollbackStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationRollbackStatus.
+func (in *ComplianceRemediationRollbackStatus) DeepCopy() *ComplianceRemediationRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceRemediationRolloutStatus) DeepCopyInto(out *ComplianceRemediationRolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationRolloutStatus.
+func (in *ComplianceRemediationRolloutStatus) DeepCopy() *ComplianceRemediationRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceRemediationRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationSpec) DeepCopyInto(out *ComplianceRemediationSpec) {
 	*out = *in
-	out.ComplianceRemediationSpecMeta = in.ComplianceRemediationSpecMeta
+	in.ComplianceRemediationSpecMeta.DeepCopyInto(&out.ComplianceRemediationSpecMeta)
 	in.Current.DeepCopyInto(&out.Current)
 	in.Outdated.DeepCopyInto(&out.Outdated)
 }
@@ -192,6 +442,20 @@ func (in *ComplianceRemediationSpec) DeepCopy() *ComplianceRemediationSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationSpecMeta) DeepCopyInto(out *ComplianceRemediationSpecMeta) {
 	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApplyAfter != nil {
+		in, out := &in.ApplyAfter, &out.ApplyAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.ApplyWindow != nil {
+		in, out := &in.ApplyWindow, &out.ApplyWindow
+		*out = new(RemediationApplyWindow)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationSpecMeta.
@@ -207,6 +471,34 @@ func (in *ComplianceRemediationSpecMeta) DeepCopy() *ComplianceRemediationSpecMe
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceRemediationStatus) DeepCopyInto(out *ComplianceRemediationStatus) {
 	*out = *in
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(ComplianceRemediationDryRunStatus)
+		**out = **in
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]ComplianceRemediationFieldDiff, len(*in))
+		copy(*out, *in)
+	}
+	in.PriorState.DeepCopyInto(&out.PriorState)
+	if in.LastRollback != nil {
+		in, out := &in.LastRollback, &out.LastRollback
+		*out = new(ComplianceRemediationRollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(ComplianceRemediationRolloutStatus)
+		**out = **in
+	}
+	if in.FailedAttempts != nil {
+		in, out := &in.FailedAttempts, &out.FailedAttempts
+		*out = make([]ComplianceRemediationAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceRemediationStatus.
@@ -284,7 +576,7 @@ func (in *ComplianceScanSettings) DeepCopyInto(out *ComplianceScanSettings) {
 	in.RawResultStorage.DeepCopyInto(&out.RawResultStorage)
 	if in.ScanTolerations != nil {
 		in, out := &in.ScanTolerations, &out.ScanTolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -294,13 +586,63 @@ func (in *ComplianceScanSettings) DeepCopyInto(out *ComplianceScanSettings) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.RemediationPruning != nil {
+		in, out := &in.RemediationPruning, &out.RemediationPruning
+		*out = new(RemediationPruningPolicy)
+		**out = **in
+	}
+	if in.SeverityOverrides != nil {
+		in, out := &in.SeverityOverrides, &out.SeverityOverrides
+		*out = make([]SeverityOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailureEvidence != nil {
+		in, out := &in.FailureEvidence, &out.FailureEvidence
+		*out = new(FailureEvidenceSettings)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ScanLimits != nil {
 		in, out := &in.ScanLimits, &out.ScanLimits
-		*out = make(map[v1.ResourceName]resource.Quantity, len(*in))
+		*out = make(map[corev1.ResourceName]resource.Quantity, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.ScannerResources != nil {
+		in, out := &in.ScannerResources, &out.ScannerResources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScannerSecurityContext != nil {
+		in, out := &in.ScannerSecurityContext, &out.ScannerSecurityContext
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScannerArgs != nil {
+		in, out := &in.ScannerArgs, &out.ScannerArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScanWindow != nil {
+		in, out := &in.ScanWindow, &out.ScanWindow
+		*out = new(ScanWindow)
+		**out = **in
+	}
+	if in.ScannerScheduling != nil {
+		in, out := &in.ScannerScheduling, &out.ScannerScheduling
+		*out = new(WorkloadScheduling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AggregatorScheduling != nil {
+		in, out := &in.AggregatorScheduling, &out.AggregatorScheduling
+		*out = new(WorkloadScheduling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResultServerScheduling != nil {
+		in, out := &in.ResultServerScheduling, &out.ResultServerScheduling
+		*out = new(WorkloadScheduling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanSettings.
@@ -316,6 +658,16 @@ func (in *ComplianceScanSettings) DeepCopy() *ComplianceScanSettings {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComplianceScanSpec) DeepCopyInto(out *ComplianceScanSpec) {
 	*out = *in
+	if in.ExcludeRules != nil {
+		in, out := &in.ExcludeRules, &out.ExcludeRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomRules != nil {
+		in, out := &in.CustomRules, &out.CustomRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -323,11 +675,30 @@ func (in *ComplianceScanSpec) DeepCopyInto(out *ComplianceScanSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.NodeNames != nil {
+		in, out := &in.NodeNames, &out.NodeNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.TailoringConfigMap != nil {
 		in, out := &in.TailoringConfigMap, &out.TailoringConfigMap
 		*out = new(TailoringConfigMapRef)
 		**out = **in
 	}
+	if in.ResultLabels != nil {
+		in, out := &in.ResultLabels, &out.ResultLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResultAnnotations != nil {
+		in, out := &in.ResultAnnotations, &out.ResultAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.ComplianceScanSettings.DeepCopyInto(&out.ComplianceScanSettings)
 }
 
@@ -368,6 +739,35 @@ func (in *ComplianceScanStatus) DeepCopyInto(out *ComplianceScanStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastScanPodRetryTimestamp != nil {
+		in, out := &in.LastScanPodRetryTimestamp, &out.LastScanPodRetryTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ScanProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CompletedNodes != nil {
+		in, out := &in.CompletedNodes, &out.CompletedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ErroredNodes != nil {
+		in, out := &in.ErroredNodes, &out.ErroredNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResultDiff != nil {
+		in, out := &in.ResultDiff, &out.ResultDiff
+		*out = new(ScanResultDiff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResultCounts != nil {
+		in, out := &in.ResultCounts, &out.ResultCounts
+		*out = new(ResultCounts)
+		**out = **in
+	}
 	if in.StartTimestamp != nil {
 		in, out := &in.StartTimestamp, &out.StartTimestamp
 		*out = (*in).DeepCopy()
@@ -376,6 +776,10 @@ func (in *ComplianceScanStatus) DeepCopyInto(out *ComplianceScanStatus) {
 		in, out := &in.EndTimestamp, &out.EndTimestamp
 		*out = (*in).DeepCopy()
 	}
+	if in.QueuedUntil != nil {
+		in, out := &in.QueuedUntil, &out.QueuedUntil
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceScanStatus.
@@ -464,24 +868,94 @@ func (in *ComplianceSuiteList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComplianceSuiteSettings) DeepCopyInto(out *ComplianceSuiteSettings) {
+func (in *ComplianceSuiteRerunStatus) DeepCopyInto(out *ComplianceSuiteRerunStatus) {
 	*out = *in
+	in.TriggeredAt.DeepCopyInto(&out.TriggeredAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteSettings.
-func (in *ComplianceSuiteSettings) DeepCopy() *ComplianceSuiteSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteRerunStatus.
+func (in *ComplianceSuiteRerunStatus) DeepCopy() *ComplianceSuiteRerunStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ComplianceSuiteSettings)
+	out := new(ComplianceSuiteRerunStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComplianceSuiteSpec) DeepCopyInto(out *ComplianceSuiteSpec) {
+func (in *ComplianceSuiteSettings) DeepCopyInto(out *ComplianceSuiteSettings) {
+	*out = *in
+	if in.AutoApplyRemediationsFilter != nil {
+		in, out := &in.AutoApplyRemediationsFilter, &out.AutoApplyRemediationsFilter
+		*out = new(RemediationApplyFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutoApplyRemediationsPools != nil {
+		in, out := &in.AutoApplyRemediationsPools, &out.AutoApplyRemediationsPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemediationApplyWindow != nil {
+		in, out := &in.RemediationApplyWindow, &out.RemediationApplyWindow
+		*out = new(RemediationApplyWindow)
+		**out = **in
+	}
+	if in.RemediationExport != nil {
+		in, out := &in.RemediationExport, &out.RemediationExport
+		*out = new(RemediationExport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResultExport != nil {
+		in, out := &in.ResultExport, &out.ResultExport
+		*out = new(ResultExport)
+		**out = **in
+	}
+	if in.ResultForwarding != nil {
+		in, out := &in.ResultForwarding, &out.ResultForwarding
+		*out = new(ResultForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]Webhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScheduleJitter != nil {
+		in, out := &in.ScheduleJitter, &out.ScheduleJitter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AdditionalSchedules != nil {
+		in, out := &in.AdditionalSchedules, &out.AdditionalSchedules
+		*out = make([]ScanSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResultRetention != nil {
+		in, out := &in.ResultRetention, &out.ResultRetention
+		*out = new(ResultRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteSettings.
+func (in *ComplianceSuiteSettings) DeepCopy() *ComplianceSuiteSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceSuiteSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceSuiteSpec) DeepCopyInto(out *ComplianceSuiteSpec) {
 	*out = *in
-	out.ComplianceSuiteSettings = in.ComplianceSuiteSettings
+	in.ComplianceSuiteSettings.DeepCopyInto(&out.ComplianceSuiteSettings)
 	if in.Scans != nil {
 		in, out := &in.Scans, &out.Scans
 		*out = make([]ComplianceScanSpecWrapper, len(*in))
@@ -489,6 +963,11 @@ func (in *ComplianceSuiteSpec) DeepCopyInto(out *ComplianceSuiteSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSuiteSpec.
@@ -511,6 +990,38 @@ func (in *ComplianceSuiteStatus) DeepCopyInto(out *ComplianceSuiteStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ComplianceThreshold != nil {
+		in, out := &in.ComplianceThreshold, &out.ComplianceThreshold
+		*out = new(ComplianceThresholdStatus)
+		**out = **in
+	}
+	if in.ManualChecks != nil {
+		in, out := &in.ManualChecks, &out.ManualChecks
+		*out = new(ManualChecksStatus)
+		**out = **in
+	}
+	if in.ResultCounts != nil {
+		in, out := &in.ResultCounts, &out.ResultCounts
+		*out = new(ResultCounts)
+		**out = **in
+	}
+	if in.LastRerun != nil {
+		in, out := &in.LastRerun, &out.LastRerun
+		*out = new(ComplianceSuiteRerunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResultForwarding != nil {
+		in, out := &in.ResultForwarding, &out.ResultForwarding
+		*out = new(ResultForwardingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]WebhookStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(Conditions, len(*in))
@@ -530,6 +1041,21 @@ func (in *ComplianceSuiteStatus) DeepCopy() *ComplianceSuiteStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceThresholdStatus) DeepCopyInto(out *ComplianceThresholdStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceThresholdStatus.
+func (in *ComplianceThresholdStatus) DeepCopy() *ComplianceThresholdStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceThresholdStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
 func (in *Condition) DeepCopy() *Condition {
 	if in == nil {
@@ -561,75 +1087,1146 @@ func (in Conditions) DeepCopy() Conditions {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomRule) DeepCopyInto(out *CustomRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomRule.
+func (in *CustomRule) DeepCopy() *CustomRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CustomRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomRuleList) DeepCopyInto(out *CustomRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CustomRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomRuleList.
+func (in *CustomRuleList) DeepCopy() *CustomRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CustomRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomRuleSpec) DeepCopyInto(out *CustomRuleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomRuleSpec.
+func (in *CustomRuleSpec) DeepCopy() *CustomRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureEvidenceSettings) DeepCopyInto(out *FailureEvidenceSettings) {
+	*out = *in
+	if in.RedactFields != nil {
+		in, out := &in.RedactFields, &out.RedactFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureEvidenceSettings.
+func (in *FailureEvidenceSettings) DeepCopy() *FailureEvidenceSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureEvidenceSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FixDefinition) DeepCopyInto(out *FixDefinition) {
 	*out = *in
-	if in.FixObject != nil {
-		in, out := &in.FixObject, &out.FixObject
-		*out = (*in).DeepCopy()
+	if in.FixObject != nil {
+		in, out := &in.FixObject, &out.FixObject
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FixDefinition.
+func (in *FixDefinition) DeepCopy() *FixDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(FixDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaForwarding) DeepCopyInto(out *KafkaForwarding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaForwarding.
+func (in *KafkaForwarding) DeepCopy() *KafkaForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManualCheckReview) DeepCopyInto(out *ManualCheckReview) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManualCheckReview.
+func (in *ManualCheckReview) DeepCopy() *ManualCheckReview {
+	if in == nil {
+		return nil
+	}
+	out := new(ManualCheckReview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManualChecksStatus) DeepCopyInto(out *ManualChecksStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManualChecksStatus.
+func (in *ManualChecksStatus) DeepCopy() *ManualChecksStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManualChecksStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedObjectReference) DeepCopyInto(out *NamedObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedObjectReference.
+func (in *NamedObjectReference) DeepCopy() *NamedObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScanProgress) DeepCopyInto(out *NodeScanProgress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScanProgress.
+func (in *NodeScanProgress) DeepCopy() *NodeScanProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScanProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotApplicableReason) DeepCopyInto(out *NotApplicableReason) {
+	*out = *in
+	if in.Refs != nil {
+		in, out := &in.Refs, &out.Refs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotApplicableReason.
+func (in *NotApplicableReason) DeepCopy() *NotApplicableReason {
+	if in == nil {
+		return nil
+	}
+	out := new(NotApplicableReason)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputRef) DeepCopyInto(out *OutputRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputRef.
+func (in *OutputRef) DeepCopy() *OutputRef {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Profile) DeepCopyInto(out *Profile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.ProfilePayload.DeepCopyInto(&out.ProfilePayload)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
+func (in *Profile) DeepCopy() *Profile {
+	if in == nil {
+		return nil
+	}
+	out := new(Profile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Profile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundle) DeepCopyInto(out *ProfileBundle) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundle.
+func (in *ProfileBundle) DeepCopy() *ProfileBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProfileBundle) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleBenchmark) DeepCopyInto(out *ProfileBundleBenchmark) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleBenchmark.
+func (in *ProfileBundleBenchmark) DeepCopy() *ProfileBundleBenchmark {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleBenchmark)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleContentDiff) DeepCopyInto(out *ProfileBundleContentDiff) {
+	*out = *in
+	if in.AddedProfiles != nil {
+		in, out := &in.AddedProfiles, &out.AddedProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovedProfiles != nil {
+		in, out := &in.RemovedProfiles, &out.RemovedProfiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChangedProfiles != nil {
+		in, out := &in.ChangedProfiles, &out.ChangedProfiles
+		*out = make([]ProfileContentDiff, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleContentDiff.
+func (in *ProfileBundleContentDiff) DeepCopy() *ProfileBundleContentDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleContentDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleContentFile) DeepCopyInto(out *ProfileBundleContentFile) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleContentFile.
+func (in *ProfileBundleContentFile) DeepCopy() *ProfileBundleContentFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleContentFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleContentStatus) DeepCopyInto(out *ProfileBundleContentStatus) {
+	*out = *in
+	if in.Benchmarks != nil {
+		in, out := &in.Benchmarks, &out.Benchmarks
+		*out = make([]ProfileBundleBenchmark, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleContentStatus.
+func (in *ProfileBundleContentStatus) DeepCopy() *ProfileBundleContentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleContentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleGarbageCollectionStatus) DeepCopyInto(out *ProfileBundleGarbageCollectionStatus) {
+	*out = *in
+	if in.ObsoleteObjects != nil {
+		in, out := &in.ObsoleteObjects, &out.ObsoleteObjects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleGarbageCollectionStatus.
+func (in *ProfileBundleGarbageCollectionStatus) DeepCopy() *ProfileBundleGarbageCollectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleGarbageCollectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleList) DeepCopyInto(out *ProfileBundleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProfileBundle, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleList.
+func (in *ProfileBundleList) DeepCopy() *ProfileBundleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProfileBundleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleSpec) DeepCopyInto(out *ProfileBundleSpec) {
+	*out = *in
+	if in.ContentFiles != nil {
+		in, out := &in.ContentFiles, &out.ContentFiles
+		*out = make([]ProfileBundleContentFile, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentSecret != nil {
+		in, out := &in.ContentSecret, &out.ContentSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleSpec.
+func (in *ProfileBundleSpec) DeepCopy() *ProfileBundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileBundleStatus) DeepCopyInto(out *ProfileBundleStatus) {
+	*out = *in
+	if in.Contents != nil {
+		in, out := &in.Contents, &out.Contents
+		*out = make([]ProfileBundleContentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ContentDiff != nil {
+		in, out := &in.ContentDiff, &out.ContentDiff
+		*out = new(ProfileBundleContentDiff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(ProfileBundleGarbageCollectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleStatus.
+func (in *ProfileBundleStatus) DeepCopy() *ProfileBundleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileBundleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileContentDiff) DeepCopyInto(out *ProfileContentDiff) {
+	*out = *in
+	if in.AddedRules != nil {
+		in, out := &in.AddedRules, &out.AddedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovedRules != nil {
+		in, out := &in.RemovedRules, &out.RemovedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ChangedRules != nil {
+		in, out := &in.ChangedRules, &out.ChangedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileContentDiff.
+func (in *ProfileContentDiff) DeepCopy() *ProfileContentDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileContentDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileList) DeepCopyInto(out *ProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Profile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileList.
+func (in *ProfileList) DeepCopy() *ProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfilePayload) DeepCopyInto(out *ProfilePayload) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ProfileRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]ProfileValue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfilePayload.
+func (in *ProfilePayload) DeepCopy() *ProfilePayload {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfilePayload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileSelector) DeepCopyInto(out *ProfileSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileSelector.
+func (in *ProfileSelector) DeepCopy() *ProfileSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawResultObjectStorage) DeepCopyInto(out *RawResultObjectStorage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawResultObjectStorage.
+func (in *RawResultObjectStorage) DeepCopy() *RawResultObjectStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(RawResultObjectStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RawResultStorageSettings) DeepCopyInto(out *RawResultStorageSettings) {
+	*out = *in
+	if in.MaxRetainedScanRuns != nil {
+		in, out := &in.MaxRetainedScanRuns, &out.MaxRetainedScanRuns
+		*out = new(uint16)
+		**out = **in
+	}
+	if in.MaxScanRunAge != nil {
+		in, out := &in.MaxScanRunAge, &out.MaxScanRunAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PVAccessModes != nil {
+		in, out := &in.PVAccessModes, &out.PVAccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(RawResultObjectStorage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawResultStorageSettings.
+func (in *RawResultStorageSettings) DeepCopy() *RawResultStorageSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(RawResultStorageSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApplyFilter) DeepCopyInto(out *RemediationApplyFilter) {
+	*out = *in
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make([]ComplianceCheckResultSeverity, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedRules != nil {
+		in, out := &in.ExcludedRules, &out.ExcludedRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApplyFilter.
+func (in *RemediationApplyFilter) DeepCopy() *RemediationApplyFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApplyFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApplyWindow) DeepCopyInto(out *RemediationApplyWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApplyWindow.
+func (in *RemediationApplyWindow) DeepCopy() *RemediationApplyWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApplyWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApproval) DeepCopyInto(out *RemediationApproval) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApproval.
+func (in *RemediationApproval) DeepCopy() *RemediationApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationApproval) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApprovalList) DeepCopyInto(out *RemediationApprovalList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RemediationApproval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApprovalList.
+func (in *RemediationApprovalList) DeepCopy() *RemediationApprovalList {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApprovalList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationApprovalList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationApprovalSpec) DeepCopyInto(out *RemediationApprovalSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationApprovalSpec.
+func (in *RemediationApprovalSpec) DeepCopy() *RemediationApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationExport) DeepCopyInto(out *RemediationExport) {
+	*out = *in
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(RemediationApplyFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationExport.
+func (in *RemediationExport) DeepCopy() *RemediationExport {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationObjectDependencyReference) DeepCopyInto(out *RemediationObjectDependencyReference) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationObjectDependencyReference.
+func (in *RemediationObjectDependencyReference) DeepCopy() *RemediationObjectDependencyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationObjectDependencyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPlan) DeepCopyInto(out *RemediationPlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPlan.
+func (in *RemediationPlan) DeepCopy() *RemediationPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationPlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPlanHook) DeepCopyInto(out *RemediationPlanHook) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPlanHook.
+func (in *RemediationPlanHook) DeepCopy() *RemediationPlanHook {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPlanHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPlanList) DeepCopyInto(out *RemediationPlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RemediationPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPlanList.
+func (in *RemediationPlanList) DeepCopy() *RemediationPlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RemediationPlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPlanSpec) DeepCopyInto(out *RemediationPlanSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(RemediationApplyFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreApplyHook != nil {
+		in, out := &in.PreApplyHook, &out.PreApplyHook
+		*out = new(RemediationPlanHook)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPlanSpec.
+func (in *RemediationPlanSpec) DeepCopy() *RemediationPlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPlanStatus) DeepCopyInto(out *RemediationPlanStatus) {
+	*out = *in
+	if in.FailedRemediations != nil {
+		in, out := &in.FailedRemediations, &out.FailedRemediations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPlanStatus.
+func (in *RemediationPlanStatus) DeepCopy() *RemediationPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationPruningPolicy) DeepCopyInto(out *RemediationPruningPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationPruningPolicy.
+func (in *RemediationPruningPolicy) DeepCopy() *RemediationPruningPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationPruningPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultCounts) DeepCopyInto(out *ResultCounts) {
+	*out = *in
+	out.Results = in.Results
+	out.High = in.High
+	out.Medium = in.Medium
+	out.Low = in.Low
+	out.Other = in.Other
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultCounts.
+func (in *ResultCounts) DeepCopy() *ResultCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultExport) DeepCopyInto(out *ResultExport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultExport.
+func (in *ResultExport) DeepCopy() *ResultExport {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultForwarding) DeepCopyInto(out *ResultForwarding) {
+	*out = *in
+	if in.Splunk != nil {
+		in, out := &in.Splunk, &out.Splunk
+		*out = new(SplunkForwarding)
+		**out = **in
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(SyslogForwarding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaForwarding)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultForwarding.
+func (in *ResultForwarding) DeepCopy() *ResultForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultForwardingStatus) DeepCopyInto(out *ResultForwardingStatus) {
+	*out = *in
+	if in.Splunk != nil {
+		in, out := &in.Splunk, &out.Splunk
+		*out = new(SinkForwardingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(SinkForwardingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(SinkForwardingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultForwardingStatus.
+func (in *ResultForwardingStatus) DeepCopy() *ResultForwardingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResultForwardingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResultMetadataPropagation) DeepCopyInto(out *ResultMetadataPropagation) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FixDefinition.
-func (in *FixDefinition) DeepCopy() *FixDefinition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultMetadataPropagation.
+func (in *ResultMetadataPropagation) DeepCopy() *ResultMetadataPropagation {
 	if in == nil {
 		return nil
 	}
-	out := new(FixDefinition)
+	out := new(ResultMetadataPropagation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamedObjectReference) DeepCopyInto(out *NamedObjectReference) {
+func (in *ResultRetentionPolicy) DeepCopyInto(out *ResultRetentionPolicy) {
 	*out = *in
+	if in.MaxCheckResultAge != nil {
+		in, out := &in.MaxCheckResultAge, &out.MaxCheckResultAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxRawResultAge != nil {
+		in, out := &in.MaxRawResultAge, &out.MaxRawResultAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxCompletedRerunnerJobs != nil {
+		in, out := &in.MaxCompletedRerunnerJobs, &out.MaxCompletedRerunnerJobs
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedObjectReference.
-func (in *NamedObjectReference) DeepCopy() *NamedObjectReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResultRetentionPolicy.
+func (in *ResultRetentionPolicy) DeepCopy() *ResultRetentionPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(NamedObjectReference)
+	out := new(ResultRetentionPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OutputRef) DeepCopyInto(out *OutputRef) {
+func (in *RoleRawResultStorageSettings) DeepCopyInto(out *RoleRawResultStorageSettings) {
 	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.PVAccessModes != nil {
+		in, out := &in.PVAccessModes, &out.PVAccessModes
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputRef.
-func (in *OutputRef) DeepCopy() *OutputRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleRawResultStorageSettings.
+func (in *RoleRawResultStorageSettings) DeepCopy() *RoleRawResultStorageSettings {
 	if in == nil {
 		return nil
 	}
-	out := new(OutputRef)
+	out := new(RoleRawResultStorageSettings)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Profile) DeepCopyInto(out *Profile) {
+func (in *Rule) DeepCopyInto(out *Rule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.ProfilePayload.DeepCopyInto(&out.ProfilePayload)
+	in.RulePayload.DeepCopyInto(&out.RulePayload)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Profile.
-func (in *Profile) DeepCopy() *Profile {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rule.
+func (in *Rule) DeepCopy() *Rule {
 	if in == nil {
 		return nil
 	}
-	out := new(Profile)
+	out := new(Rule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Profile) DeepCopyObject() runtime.Object {
+func (in *Rule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -637,58 +2234,51 @@ func (in *Profile) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileBundle) DeepCopyInto(out *ProfileBundle) {
+func (in *RuleControls) DeepCopyInto(out *RuleControls) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Controls != nil {
+		in, out := &in.Controls, &out.Controls
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundle.
-func (in *ProfileBundle) DeepCopy() *ProfileBundle {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleControls.
+func (in *RuleControls) DeepCopy() *RuleControls {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfileBundle)
+	out := new(RuleControls)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProfileBundle) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileBundleList) DeepCopyInto(out *ProfileBundleList) {
+func (in *RuleList) DeepCopyInto(out *RuleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ProfileBundle, len(*in))
+		*out = make([]Rule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleList.
-func (in *ProfileBundleList) DeepCopy() *ProfileBundleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleList.
+func (in *RuleList) DeepCopy() *RuleList {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfileBundleList)
+	out := new(RuleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProfileBundleList) DeepCopyObject() runtime.Object {
+func (in *RuleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -696,174 +2286,110 @@ func (in *ProfileBundleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileBundleSpec) DeepCopyInto(out *ProfileBundleSpec) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleSpec.
-func (in *ProfileBundleSpec) DeepCopy() *ProfileBundleSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(ProfileBundleSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileBundleStatus) DeepCopyInto(out *ProfileBundleStatus) {
+func (in *RulePayload) DeepCopyInto(out *RulePayload) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make(Conditions, len(*in))
+	if in.AvailableFixes != nil {
+		in, out := &in.AvailableFixes, &out.AvailableFixes
+		*out = make([]FixDefinition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileBundleStatus.
-func (in *ProfileBundleStatus) DeepCopy() *ProfileBundleStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(ProfileBundleStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfileList) DeepCopyInto(out *ProfileList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Profile, len(*in))
+	if in.Controls != nil {
+		in, out := &in.Controls, &out.Controls
+		*out = make([]RuleControls, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileList.
-func (in *ProfileList) DeepCopy() *ProfileList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RulePayload.
+func (in *RulePayload) DeepCopy() *RulePayload {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfileList)
+	out := new(RulePayload)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ProfileList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ProfilePayload) DeepCopyInto(out *ProfilePayload) {
+func (in *RuleReferenceSpec) DeepCopyInto(out *RuleReferenceSpec) {
 	*out = *in
-	if in.Rules != nil {
-		in, out := &in.Rules, &out.Rules
-		*out = make([]ProfileRule, len(*in))
-		copy(*out, *in)
-	}
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = make([]ProfileValue, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfilePayload.
-func (in *ProfilePayload) DeepCopy() *ProfilePayload {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleReferenceSpec.
+func (in *RuleReferenceSpec) DeepCopy() *RuleReferenceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ProfilePayload)
+	out := new(RuleReferenceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RawResultStorageSettings) DeepCopyInto(out *RawResultStorageSettings) {
+func (in *ScanProgress) DeepCopyInto(out *ScanProgress) {
 	*out = *in
-	if in.StorageClassName != nil {
-		in, out := &in.StorageClassName, &out.StorageClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.PVAccessModes != nil {
-		in, out := &in.PVAccessModes, &out.PVAccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeScanProgress, len(*in))
 		copy(*out, *in)
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RawResultStorageSettings.
-func (in *RawResultStorageSettings) DeepCopy() *RawResultStorageSettings {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanProgress.
+func (in *ScanProgress) DeepCopy() *ScanProgress {
 	if in == nil {
 		return nil
 	}
-	out := new(RawResultStorageSettings)
+	out := new(ScanProgress)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RemediationObjectDependencyReference) DeepCopyInto(out *RemediationObjectDependencyReference) {
+func (in *ScanResultDiff) DeepCopyInto(out *ScanResultDiff) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
+	if in.ChangedChecks != nil {
+		in, out := &in.ChangedChecks, &out.ChangedChecks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationObjectDependencyReference.
-func (in *RemediationObjectDependencyReference) DeepCopy() *RemediationObjectDependencyReference {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanResultDiff.
+func (in *ScanResultDiff) DeepCopy() *ScanResultDiff {
 	if in == nil {
 		return nil
 	}
-	out := new(RemediationObjectDependencyReference)
+	out := new(ScanResultDiff)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Rule) DeepCopyInto(out *Rule) {
+func (in *ScanRun) DeepCopyInto(out *ScanRun) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.RulePayload.DeepCopyInto(&out.RulePayload)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rule.
-func (in *Rule) DeepCopy() *Rule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanRun.
+func (in *ScanRun) DeepCopy() *ScanRun {
 	if in == nil {
 		return nil
 	}
-	out := new(Rule)
+	out := new(ScanRun)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Rule) DeepCopyObject() runtime.Object {
+func (in *ScanRun) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -871,31 +2397,31 @@ func (in *Rule) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RuleList) DeepCopyInto(out *RuleList) {
+func (in *ScanRunList) DeepCopyInto(out *ScanRunList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Rule, len(*in))
+		*out = make([]ScanRun, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleList.
-func (in *RuleList) DeepCopy() *RuleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanRunList.
+func (in *ScanRunList) DeepCopy() *ScanRunList {
 	if in == nil {
 		return nil
 	}
-	out := new(RuleList)
+	out := new(ScanRunList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RuleList) DeepCopyObject() runtime.Object {
+func (in *ScanRunList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -903,38 +2429,79 @@ func (in *RuleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RulePayload) DeepCopyInto(out *RulePayload) {
+func (in *ScanRunScanStatus) DeepCopyInto(out *ScanRunScanStatus) {
 	*out = *in
-	if in.AvailableFixes != nil {
-		in, out := &in.AvailableFixes, &out.AvailableFixes
-		*out = make([]FixDefinition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanRunScanStatus.
+func (in *ScanRunScanStatus) DeepCopy() *ScanRunScanStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(ScanRunScanStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RulePayload.
-func (in *RulePayload) DeepCopy() *RulePayload {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanRunSpec) DeepCopyInto(out *ScanRunSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanRunSpec.
+func (in *ScanRunSpec) DeepCopy() *ScanRunSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RulePayload)
+	out := new(ScanRunSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RuleReferenceSpec) DeepCopyInto(out *RuleReferenceSpec) {
+func (in *ScanRunStatus) DeepCopyInto(out *ScanRunStatus) {
+	*out = *in
+	if in.Scans != nil {
+		in, out := &in.Scans, &out.Scans
+		*out = make([]ScanRunScanStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanRunStatus.
+func (in *ScanRunStatus) DeepCopy() *ScanRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanSchedule) DeepCopyInto(out *ScanSchedule) {
 	*out = *in
+	if in.Scans != nil {
+		in, out := &in.Scans, &out.Scans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleReferenceSpec.
-func (in *RuleReferenceSpec) DeepCopy() *RuleReferenceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanSchedule.
+func (in *ScanSchedule) DeepCopy() *ScanSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(RuleReferenceSpec)
+	out := new(ScanSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -944,13 +2511,20 @@ func (in *ScanSetting) DeepCopyInto(out *ScanSetting) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.ComplianceSuiteSettings = in.ComplianceSuiteSettings
+	in.ComplianceSuiteSettings.DeepCopyInto(&out.ComplianceSuiteSettings)
 	in.ComplianceScanSettings.DeepCopyInto(&out.ComplianceScanSettings)
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RoleRawResultStorage != nil {
+		in, out := &in.RoleRawResultStorage, &out.RoleRawResultStorage
+		*out = make([]RoleRawResultStorageSettings, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanSetting.
@@ -982,6 +2556,28 @@ func (in *ScanSettingBinding) DeepCopyInto(out *ScanSettingBinding) {
 		*out = make([]NamedObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.ProfileSelectors != nil {
+		in, out := &in.ProfileSelectors, &out.ProfileSelectors
+		*out = make([]ProfileSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExcludeRules != nil {
+		in, out := &in.ExcludeRules, &out.ExcludeRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResultMetadataPropagation != nil {
+		in, out := &in.ResultMetadataPropagation, &out.ResultMetadataPropagation
+		*out = new(ResultMetadataPropagation)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SettingsRef != nil {
 		in, out := &in.SettingsRef, &out.SettingsRef
 		*out = new(NamedObjectReference)
@@ -1067,7 +2663,7 @@ func (in *ScanSettingBindingStatus) DeepCopyInto(out *ScanSettingBindingStatus)
 	}
 	if in.OutputRef != nil {
 		in, out := &in.OutputRef, &out.OutputRef
-		*out = new(v1.TypedLocalObjectReference)
+		*out = new(corev1.TypedLocalObjectReference)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -1114,6 +2710,97 @@ func (in *ScanSettingList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScanWindow) DeepCopyInto(out *ScanWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScanWindow.
+func (in *ScanWindow) DeepCopy() *ScanWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(ScanWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectedRules) DeepCopyInto(out *SelectedRules) {
+	*out = *in
+	if in.EnableRules != nil {
+		in, out := &in.EnableRules, &out.EnableRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableRules != nil {
+		in, out := &in.DisableRules, &out.DisableRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectedRules.
+func (in *SelectedRules) DeepCopy() *SelectedRules {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectedRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeverityOverride) DeepCopyInto(out *SeverityOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeverityOverride.
+func (in *SeverityOverride) DeepCopy() *SeverityOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(SeverityOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SinkForwardingStatus) DeepCopyInto(out *SinkForwardingStatus) {
+	*out = *in
+	in.ScanRun.DeepCopyInto(&out.ScanRun)
+	if in.ForwardedAt != nil {
+		in, out := &in.ForwardedAt, &out.ForwardedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SinkForwardingStatus.
+func (in *SinkForwardingStatus) DeepCopy() *SinkForwardingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SinkForwardingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkForwarding) DeepCopyInto(out *SplunkForwarding) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkForwarding.
+func (in *SplunkForwarding) DeepCopy() *SplunkForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageReference) DeepCopyInto(out *StorageReference) {
 	*out = *in
@@ -1129,13 +2816,48 @@ func (in *StorageReference) DeepCopy() *StorageReference {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogFieldMapping) DeepCopyInto(out *SyslogFieldMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogFieldMapping.
+func (in *SyslogFieldMapping) DeepCopy() *SyslogFieldMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogFieldMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogForwarding) DeepCopyInto(out *SyslogForwarding) {
+	*out = *in
+	if in.FieldMapping != nil {
+		in, out := &in.FieldMapping, &out.FieldMapping
+		*out = new(SyslogFieldMapping)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogForwarding.
+func (in *SyslogForwarding) DeepCopy() *SyslogForwarding {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogForwarding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TailoredProfile) DeepCopyInto(out *TailoredProfile) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TailoredProfile.
@@ -1188,9 +2910,30 @@ func (in *TailoredProfileList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TailoredProfileOutput) DeepCopyInto(out *TailoredProfileOutput) {
+	*out = *in
+	out.OutputRef = in.OutputRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TailoredProfileOutput.
+func (in *TailoredProfileOutput) DeepCopy() *TailoredProfileOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(TailoredProfileOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TailoredProfileSpec) DeepCopyInto(out *TailoredProfileSpec) {
 	*out = *in
+	if in.ImportFrom != nil {
+		in, out := &in.ImportFrom, &out.ImportFrom
+		*out = new(TailoringImportSpec)
+		**out = **in
+	}
 	if in.EnableRules != nil {
 		in, out := &in.EnableRules, &out.EnableRules
 		*out = make([]RuleReferenceSpec, len(*in))
@@ -1206,6 +2949,31 @@ func (in *TailoredProfileSpec) DeepCopyInto(out *TailoredProfileSpec) {
 		*out = make([]RuleReferenceSpec, len(*in))
 		copy(*out, *in)
 	}
+	if in.EnableRulesBySeverity != nil {
+		in, out := &in.EnableRulesBySeverity, &out.EnableRulesBySeverity
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableRulesBySeverity != nil {
+		in, out := &in.DisableRulesBySeverity, &out.DisableRulesBySeverity
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnableRulesByReference != nil {
+		in, out := &in.EnableRulesByReference, &out.EnableRulesByReference
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableRulesByReference != nil {
+		in, out := &in.DisableRulesByReference, &out.DisableRulesByReference
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomRules != nil {
+		in, out := &in.CustomRules, &out.CustomRules
+		*out = make([]RuleReferenceSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.SetValues != nil {
 		in, out := &in.SetValues, &out.SetValues
 		*out = make([]VariableValueSpec, len(*in))
@@ -1227,6 +2995,21 @@ func (in *TailoredProfileSpec) DeepCopy() *TailoredProfileSpec {
 func (in *TailoredProfileStatus) DeepCopyInto(out *TailoredProfileStatus) {
 	*out = *in
 	out.OutputRef = in.OutputRef
+	if in.AdditionalOutputs != nil {
+		in, out := &in.AdditionalOutputs, &out.AdditionalOutputs
+		*out = make([]TailoredProfileOutput, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectedRules != nil {
+		in, out := &in.SelectedRules, &out.SelectedRules
+		*out = new(SelectedRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(TailoredProfileValidation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TailoredProfileStatus.
@@ -1239,6 +3022,31 @@ func (in *TailoredProfileStatus) DeepCopy() *TailoredProfileStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TailoredProfileValidation) DeepCopyInto(out *TailoredProfileValidation) {
+	*out = *in
+	if in.UnknownReferences != nil {
+		in, out := &in.UnknownReferences, &out.UnknownReferences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NoOpSelections != nil {
+		in, out := &in.NoOpSelections, &out.NoOpSelections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TailoredProfileValidation.
+func (in *TailoredProfileValidation) DeepCopy() *TailoredProfileValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(TailoredProfileValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TailoringConfigMapRef) DeepCopyInto(out *TailoringConfigMapRef) {
 	*out = *in
@@ -1254,6 +3062,51 @@ func (in *TailoringConfigMapRef) DeepCopy() *TailoringConfigMapRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TailoringImportSpec) DeepCopyInto(out *TailoringImportSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TailoringImportSpec.
+func (in *TailoringImportSpec) DeepCopy() *TailoringImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TailoringImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValueConstraints) DeepCopyInto(out *ValueConstraints) {
+	*out = *in
+	if in.LowerBound != nil {
+		in, out := &in.LowerBound, &out.LowerBound
+		*out = new(int)
+		**out = **in
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = new(int)
+		**out = **in
+	}
+	if in.Choices != nil {
+		in, out := &in.Choices, &out.Choices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValueConstraints.
+func (in *ValueConstraints) DeepCopy() *ValueConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(ValueConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValueSelection) DeepCopyInto(out *ValueSelection) {
 	*out = *in
@@ -1335,6 +3188,11 @@ func (in *VariablePayload) DeepCopyInto(out *VariablePayload) {
 		*out = make([]ValueSelection, len(*in))
 		copy(*out, *in)
 	}
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = new(ValueConstraints)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VariablePayload.
@@ -1361,3 +3219,114 @@ func (in *VariableValueSpec) DeepCopy() *VariableValueSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Webhook) DeepCopyInto(out *Webhook) {
+	*out = *in
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(WebhookRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
+func (in *Webhook) DeepCopy() *Webhook {
+	if in == nil {
+		return nil
+	}
+	out := new(Webhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookRetryPolicy) DeepCopyInto(out *WebhookRetryPolicy) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookRetryPolicy.
+func (in *WebhookRetryPolicy) DeepCopy() *WebhookRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookStatus) DeepCopyInto(out *WebhookStatus) {
+	*out = *in
+	in.ScanRun.DeepCopyInto(&out.ScanRun)
+	if in.CalledAt != nil {
+		in, out := &in.CalledAt, &out.CalledAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookStatus.
+func (in *WebhookStatus) DeepCopy() *WebhookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadScheduling) DeepCopyInto(out *WorkloadScheduling) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadScheduling.
+func (in *WorkloadScheduling) DeepCopy() *WorkloadScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
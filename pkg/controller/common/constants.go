@@ -20,6 +20,12 @@ const (
 	OpenSCAPExitCodeNonCompliant string = "2"
 	// PodUnschedulableExitCode is a custom error that indicates that we couldn't schedule the pod
 	PodUnschedulableExitCode string = "unschedulable"
+	// PodFailedExitCode is a custom error that indicates that the scan pod
+	// kept failing until it ran out of retries
+	PodFailedExitCode string = "failed"
+	// OpenSCAPExitCodeUnchanged is a custom exit code that indicates that an
+	// incremental scan didn't run OpenSCAP, since no rule inputs changed
+	OpenSCAPExitCodeUnchanged string = "unchanged"
 
 	// taken from k8sutil
 	ForceRunModeEnv             = "OSDK_FORCE_RUN_MODE"
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+)
+
+// The number of notifications that can wait to be sent, the ones that don't
+// fit are dropped
+const notificationQueueSize = 256
+
+// Notification is a notification that's sent in the background, e.g. a
+// message published to a Kafka topic
+type Notification struct {
+	// The kind of endpoint the notification is sent to, in the metrics
+	Sink string
+	// Send sends the notification
+	Send func(ctx context.Context) error
+	// Done, if set, is called with the outcome once the notification was
+	// sent or failed
+	Done func(err error)
+}
+
+// NotificationQueue sends the notifications of a controller one after
+// another in the background, so that an endpoint that's slow or down doesn't
+// hold up the reconciles. The notifications that couldn't be sent, or that
+// were dropped because the queue was full, are counted in the metrics.
+type NotificationQueue struct {
+	notifications chan Notification
+	metrics       *metrics.Metrics
+	log           logr.Logger
+}
+
+// NewNotificationQueue returns a queue that sends the notifications once it's
+// started, which the manager does since the queue is a Runnable
+func NewNotificationQueue(name string, met *metrics.Metrics) *NotificationQueue {
+	return &NotificationQueue{
+		notifications: make(chan Notification, notificationQueueSize),
+		metrics:       met,
+		log:           ctrllog.Log.WithName(name).WithName("notifications"),
+	}
+}
+
+// Enqueue queues the notification to be sent, without waiting. It returns
+// false if the queue is full, in which case the notification is dropped.
+func (q *NotificationQueue) Enqueue(notification Notification) bool {
+	select {
+	case q.notifications <- notification:
+		return true
+	default:
+		q.log.Info("Too many notifications are waiting to be sent, dropping one", "sink", notification.Sink)
+		if q.metrics != nil {
+			q.metrics.IncNotificationDropped(notification.Sink)
+		}
+		return false
+	}
+}
+
+// Start sends the queued notifications until the context is done
+func (q *NotificationQueue) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-q.notifications:
+			err := notification.Send(ctx)
+			if err != nil && q.metrics != nil {
+				q.metrics.IncNotificationError(notification.Sink)
+			}
+			if notification.Done != nil {
+				notification.Done(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+)
+
+var _ = Describe("Testing the notification queue", func() {
+	var queue *NotificationQueue
+
+	BeforeEach(func() {
+		queue = NewNotificationQueue("test", metrics.NewMetrics(&metricsfakes.FakeImpl{}))
+	})
+
+	It("sends the notifications in the background, in order", func() {
+		ctx, stop := context.WithCancel(context.TODO())
+		defer stop()
+		go queue.Start(ctx)
+
+		outcomes := make(chan error, 2)
+		var sent []string
+		for _, name := range []string{"first", "second"} {
+			name := name
+			Expect(queue.Enqueue(Notification{
+				Sink: "test",
+				Send: func(context.Context) error {
+					sent = append(sent, name)
+					if name == "second" {
+						return errors.New("the endpoint is down")
+					}
+					return nil
+				},
+				Done: func(err error) { outcomes <- err },
+			})).To(BeTrue())
+		}
+		Eventually(outcomes).Should(Receive(BeNil()))
+		Eventually(outcomes).Should(Receive(MatchError("the endpoint is down")))
+		Expect(sent).To(Equal([]string{"first", "second"}))
+	})
+
+	It("drops the notifications once the queue is full", func() {
+		notification := Notification{Sink: "test", Send: func(context.Context) error { return nil }}
+		for queue.Enqueue(notification) {
+		}
+		Expect(queue.Enqueue(notification)).To(BeFalse())
+	})
+})
@@ -0,0 +1,182 @@
+package compliancecheckresultdiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("checkresultdiffctrl")
+
+// Add creates a new ComplianceCheckResultDiff Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, _ *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileComplianceCheckResultDiff{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	mapper := &scanMapper{mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("compliancecheckresultdiff-controller").
+		For(&compv1alpha1.ComplianceCheckResultDiff{}).
+		Watches(&compv1alpha1.ComplianceScan{}, handler.EnqueueRequestsFromMapFunc(mapper.Map)).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileComplianceCheckResultDiff implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileComplianceCheckResultDiff{}
+
+// ReconcileComplianceCheckResultDiff reconciles a ComplianceCheckResultDiff object
+type ReconcileComplianceCheckResultDiff struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile compares the results of the checks of the two runs of the scan
+// of a ComplianceCheckResultDiff once the scan is done with both of them.
+// The results are only compared once, as those of older runs get rotated.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileComplianceCheckResultDiff) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ComplianceCheckResultDiff")
+
+	// Fetch the ComplianceCheckResultDiff instance
+	instance := &compv1alpha1.ComplianceCheckResultDiff{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	if instance.IsFinished() {
+		return reconcile.Result{}, nil
+	}
+
+	status := instance.Status.DeepCopy()
+	scan := &compv1alpha1.ComplianceScan{}
+	scanKey := types.NamespacedName{Name: instance.Spec.ScanName, Namespace: instance.Namespace}
+	if err := r.Client.Get(context.TODO(), scanKey, scan); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		status.Phase = compv1alpha1.CheckResultDiffError
+		status.ErrorMessage = fmt.Sprintf("ComplianceScan '%s' not found", scanKey.Name)
+		return reconcile.Result{}, r.updateDiffStatus(instance, status, reqLogger)
+	}
+
+	// The scan is watched, so the diff gets reconciled again once it's
+	// done with the runs
+	for _, index := range []int64{instance.Spec.BaseIndex, instance.Spec.TargetIndex} {
+		if !scanIsDoneWithRun(scan, index) {
+			reqLogger.Info("Waiting for the scan to be done with the run", "index", index)
+			status.Phase = compv1alpha1.CheckResultDiffPending
+			return reconcile.Result{}, r.updateDiffStatus(instance, status, reqLogger)
+		}
+	}
+
+	snapshots, err := r.getResultSnapshots(scan)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	base, err := getResultSnapshot(snapshots, scan.Name, instance.Spec.BaseIndex)
+	if err != nil {
+		status.Phase = compv1alpha1.CheckResultDiffError
+		status.ErrorMessage = err.Error()
+		return reconcile.Result{}, r.updateDiffStatus(instance, status, reqLogger)
+	}
+	target, err := getResultSnapshot(snapshots, scan.Name, instance.Spec.TargetIndex)
+	if err != nil {
+		status.Phase = compv1alpha1.CheckResultDiffError
+		status.ErrorMessage = err.Error()
+		return reconcile.Result{}, r.updateDiffStatus(instance, status, reqLogger)
+	}
+
+	status.CompareResults(base, target)
+	status.Phase = compv1alpha1.CheckResultDiffDone
+	status.ErrorMessage = ""
+	return reconcile.Result{}, r.updateDiffStatus(instance, status, reqLogger)
+}
+
+// scanIsDoneWithRun tells whether the results of a run of the scan were
+// stored, which happens once the scan moves past the run or is done with it
+func scanIsDoneWithRun(scan *compv1alpha1.ComplianceScan, index int64) bool {
+	if index < scan.Status.CurrentIndex {
+		return true
+	}
+	return index == scan.Status.CurrentIndex && scan.Status.Phase == compv1alpha1.PhaseDone
+}
+
+// getResultSnapshots returns the results of the runs of the scan that the
+// aggregator kept, by the index of the run
+func (r *ReconcileComplianceCheckResultDiff) getResultSnapshots(scan *compv1alpha1.ComplianceScan) (map[string]string, error) {
+	targetCM := types.NamespacedName{
+		Name:      utils.GetResultSnapshotsConfigMapName(scan.Name),
+		Namespace: common.GetComplianceOperatorNamespace(),
+	}
+
+	foundCM := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), targetCM, foundCM)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return foundCM.Data, nil
+}
+
+func getResultSnapshot(snapshots map[string]string, scanName string, index int64) (map[string]compv1alpha1.ComplianceCheckStatus, error) {
+	raw, ok := snapshots[utils.GetResultSnapshotKey(index)]
+	if !ok {
+		return nil, fmt.Errorf("the results of run %d of ComplianceScan '%s' weren't kept, only those of its last %d runs are",
+			index, scanName, utils.MaxResultSnapshots)
+	}
+	snapshot := make(map[string]compv1alpha1.ComplianceCheckStatus)
+	if err := json.Unmarshal([]byte(raw), &snapshot); err != nil {
+		return nil, fmt.Errorf("cannot parse the results of run %d of ComplianceScan '%s': %w", index, scanName, err)
+	}
+	return snapshot, nil
+}
+
+func (r *ReconcileComplianceCheckResultDiff) updateDiffStatus(instance *compv1alpha1.ComplianceCheckResultDiff,
+	status *compv1alpha1.ComplianceCheckResultDiffStatus, logger logr.Logger) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	logger.Info("Updating the status of the ComplianceCheckResultDiff", "phase", status.Phase)
+	instanceCopy := instance.DeepCopy()
+	instanceCopy.Status = *status
+	return r.Client.Status().Update(context.TODO(), instanceCopy)
+}
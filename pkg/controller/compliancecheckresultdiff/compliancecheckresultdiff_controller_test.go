@@ -0,0 +1,120 @@
+package compliancecheckresultdiff
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Testing compliancecheckresultdiff controller", func() {
+	var (
+		namespace  = common.GetComplianceOperatorNamespace()
+		diff       *compv1alpha1.ComplianceCheckResultDiff
+		scan       *compv1alpha1.ComplianceScan
+		snapshots  *corev1.ConfigMap
+		reconciler *ReconcileComplianceCheckResultDiff
+	)
+
+	reconcileDiff := func() *compv1alpha1.ComplianceCheckResultDiff {
+		key := types.NamespacedName{Name: diff.Name, Namespace: namespace}
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		Expect(err).To(BeNil())
+		found := &compv1alpha1.ComplianceCheckResultDiff{}
+		Expect(reconciler.Client.Get(context.TODO(), key, found)).To(Succeed())
+		return found
+	}
+	buildReconciler := func() {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		client := fake.NewClientBuilder().
+			WithScheme(cscheme).
+			WithObjects(diff, scan, snapshots).
+			WithStatusSubresource(diff, scan).
+			Build()
+		reconciler = &ReconcileComplianceCheckResultDiff{Client: client, Scheme: cscheme}
+	}
+
+	BeforeEach(func() {
+		diff = &compv1alpha1.ComplianceCheckResultDiff{
+			ObjectMeta: metav1.ObjectMeta{Name: "upgrade", Namespace: namespace},
+			Spec: compv1alpha1.ComplianceCheckResultDiffSpec{
+				ScanName:    "ocp4-cis",
+				BaseIndex:   3,
+				TargetIndex: 4,
+			},
+		}
+		scan = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis", Namespace: namespace},
+			Status: compv1alpha1.ComplianceScanStatus{
+				Phase:        compv1alpha1.PhaseDone,
+				CurrentIndex: 4,
+			},
+		}
+		snapshots = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      utils.GetResultSnapshotsConfigMapName("ocp4-cis"),
+				Namespace: namespace,
+			},
+			Data: map[string]string{
+				"3": `{"ocp4-cis-audit":"PASS","ocp4-cis-etcd":"FAIL","ocp4-cis-rbac":"FAIL","ocp4-cis-tls":"MANUAL"}`,
+				"4": `{"ocp4-cis-audit":"FAIL","ocp4-cis-etcd":"PASS","ocp4-cis-rbac":"FAIL","ocp4-cis-new":"FAIL"}`,
+			},
+		}
+	})
+
+	It("compares the results of the runs", func() {
+		buildReconciler()
+		found := reconcileDiff()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.CheckResultDiffDone))
+		Expect(found.Status.NewFailures).To(Equal([]compv1alpha1.CheckResultChange{
+			{Name: "ocp4-cis-new", TargetStatus: compv1alpha1.CheckResultFail},
+		}))
+		Expect(found.Status.Regressions).To(Equal([]compv1alpha1.CheckResultChange{
+			{Name: "ocp4-cis-audit", BaseStatus: compv1alpha1.CheckResultPass, TargetStatus: compv1alpha1.CheckResultFail},
+		}))
+		Expect(found.Status.Fixes).To(Equal([]compv1alpha1.CheckResultChange{
+			{Name: "ocp4-cis-etcd", BaseStatus: compv1alpha1.CheckResultFail, TargetStatus: compv1alpha1.CheckResultPass},
+		}))
+		Expect(found.Status.Unchanged).To(Equal(1))
+	})
+
+	It("waits for the scan to be done with the target run", func() {
+		scan.Status.Phase = compv1alpha1.PhaseRunning
+		buildReconciler()
+		found := reconcileDiff()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.CheckResultDiffPending))
+
+		By("finishing the run")
+		scan.Status.Phase = compv1alpha1.PhaseDone
+		Expect(reconciler.Client.Status().Update(context.TODO(), scan)).To(Succeed())
+		found = reconcileDiff()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.CheckResultDiffDone))
+	})
+
+	It("fails if the results of a run weren't kept", func() {
+		diff.Spec.BaseIndex = 1
+		buildReconciler()
+		found := reconcileDiff()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.CheckResultDiffError))
+		Expect(found.Status.ErrorMessage).To(ContainSubstring("the results of run 1 of ComplianceScan 'ocp4-cis' weren't kept"))
+	})
+
+	It("fails if the scan doesn't exist", func() {
+		diff.Spec.ScanName = "ocp4-moderate"
+		buildReconciler()
+		found := reconcileDiff()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.CheckResultDiffError))
+		Expect(found.Status.ErrorMessage).To(Equal("ComplianceScan 'ocp4-moderate' not found"))
+	})
+})
@@ -0,0 +1,13 @@
+package compliancecheckresultdiff
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCompliancecheckresultdiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Compliancecheckresultdiff Suite")
+}
@@ -0,0 +1,40 @@
+package compliancecheckresultdiff
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+type scanMapper struct {
+	client.Client
+}
+
+// Map enqueues the ComplianceCheckResultDiffs that aren't finished of the
+// scan
+func (m *scanMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	diffList := compv1alpha1.ComplianceCheckResultDiffList{}
+	err := m.List(ctx, &diffList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return requests
+	}
+
+	for _, diff := range diffList.Items {
+		if diff.Spec.ScanName != obj.GetName() || diff.IsFinished() {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      diff.GetName(),
+			Namespace: diff.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
@@ -0,0 +1,116 @@
+package complianceremediation
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+const (
+	pendingApprovalMessage = "The remediation needs to be approved through a RemediationApproval before it's applied"
+	// Without the webhooks, who approved the remediations can't be told
+	approvalWebhooksNotServedMessage = "The remediation needs to be approved, but approvals can only be verified " +
+		"when the webhooks of the operator are served"
+	unknownApplyRequesterMessage = "The remediation needs to be approved, but the user that set it to be applied " +
+		"wasn't recorded. Set apply to false and back to true to record it"
+)
+
+// remediationApprovalMapper enqueues the remediation an approval refers to
+type remediationApprovalMapper struct {
+	client.Client
+}
+
+func (m *remediationApprovalMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	approval, ok := obj.(*compv1alpha1.RemediationApproval)
+	if !ok || approval.Spec.RemediationName == "" {
+		return nil
+	}
+	objKey := types.NamespacedName{
+		Name:      approval.Spec.RemediationName,
+		Namespace: approval.GetNamespace(),
+	}
+	return []reconcile.Request{{NamespacedName: objKey}}
+}
+
+// Tells why the remediation can't be applied yet if its suite requires
+// approvals, or returns an empty string if it can. Only the approvals that
+// the webhooks recorded for the current content of the remediation, made by
+// someone else than the user that set the remediation to be applied, count.
+func (r *ReconcileComplianceRemediation) getPendingApprovalReason(rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) (string, error) {
+	suite := &compv1alpha1.ComplianceSuite{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetSuite(), Namespace: rem.Namespace}, suite)
+	if kerrors.IsNotFound(err) {
+		logger.Info("The suite of the remediation doesn't exist, not checking for approvals", "ComplianceSuite.Name", rem.GetSuite())
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if !suite.Spec.RequireRemediationApproval {
+		return "", nil
+	}
+	if !approvalWebhooksServed.Load() {
+		return approvalWebhooksNotServedMessage, nil
+	}
+	requester := rem.Annotations[compv1alpha1.RemediationApplyRequesterAnnotation]
+	if requester == "" {
+		return unknownApplyRequesterMessage, nil
+	}
+	contentHash, err := remediationContentHash(rem)
+	if err != nil {
+		return "", err
+	}
+
+	approvals := &compv1alpha1.RemediationApprovalList{}
+	if err := r.Client.List(context.TODO(), approvals, client.InNamespace(rem.Namespace)); err != nil {
+		return "", err
+	}
+	for i := range approvals.Items {
+		approval := &approvals.Items[i]
+		if approval.Spec.RemediationName != rem.Name {
+			continue
+		}
+		approver := approval.Annotations[compv1alpha1.RemediationApproverAnnotation]
+		if approver == "" || approver == requester {
+			logger.Info("Ignoring the approval of the user that set the remediation to be applied, or of an unknown user",
+				"RemediationApproval.Name", approval.Name, "approver", approver)
+			continue
+		}
+		if approval.Annotations[compv1alpha1.RemediationApprovedContentAnnotation] != contentHash {
+			logger.Info("Ignoring the approval of a previous content of the remediation", "RemediationApproval.Name", approval.Name)
+			continue
+		}
+		logger.Info("The remediation was approved", "RemediationApproval.Name", approval.Name, "approver", approver)
+		return "", nil
+	}
+	return pendingApprovalMessage, nil
+}
+
+// Keeps the remediation from being applied until it's approved. Objects of
+// remediations that are already applied are left alone in the meantime.
+// Approvals are watched, so there's no need to requeue.
+func (r *ReconcileComplianceRemediation) waitForApproval(rem *compv1alpha1.ComplianceRemediation, reason string, logger logr.Logger) error {
+	if rem.Status.ApplicationState == compv1alpha1.RemediationApplied || rem.Status.ApplicationState == compv1alpha1.RemediationRollingOut {
+		logger.Info("Not updating the applied remediation until it's approved")
+		return nil
+	}
+
+	logger.Info("The remediation needs to be approved before it's applied")
+	if rem.Status.ApplicationState == compv1alpha1.RemediationPendingApproval && rem.Status.ErrorMessage == reason {
+		return nil
+	}
+	rCopy := rem.DeepCopy()
+	rCopy.Status.ApplicationState = compv1alpha1.RemediationPendingApproval
+	rCopy.Status.ErrorMessage = reason
+	if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+		return err
+	}
+	r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+	r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	return nil
+}
@@ -0,0 +1,162 @@
+package complianceremediation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// applyRequesterDefaulter records the user that sets a ComplianceRemediation
+// to be applied, which can't be told from the remediation itself. Any
+// requester set by the user is overwritten, and the requester is kept as long
+// as the remediation is set to be applied.
+type applyRequesterDefaulter struct{}
+
+var _ admission.CustomDefaulter = &applyRequesterDefaulter{}
+
+// approverDefaulter records the user that approves a remediation and the
+// content of the remediation that is approved, and refuses the approvals of
+// the user that set the remediation to be applied. Both webhooks fail
+// closed, so that no approval is recorded without them.
+type approverDefaulter struct {
+	reader client.Reader
+}
+
+var _ admission.CustomDefaulter = &approverDefaulter{}
+
+// approvalWebhooksServed tells whether the webhooks are served, the
+// approvers and the requesters are set by hand otherwise and can't be trusted
+var approvalWebhooksServed atomic.Bool
+
+//+kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-complianceremediation,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=complianceremediations,verbs=create;update,versions=v1alpha1,name=mcomplianceremediation.compliance.openshift.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-remediationapproval,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=remediationapprovals,verbs=create;update,versions=v1alpha1,name=mremediationapproval.compliance.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the webhooks recording who set the
+// remediations to be applied and who approved them
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ComplianceRemediation{}).
+		WithDefaulter(&applyRequesterDefaulter{}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	err = ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.RemediationApproval{}).
+		WithDefaulter(&approverDefaulter{reader: mgr.GetAPIReader()}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	approvalWebhooksServed.Store(true)
+	return nil
+}
+
+func (d *applyRequesterDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	rem, ok := obj.(*compv1alpha1.ComplianceRemediation)
+	if !ok {
+		return fmt.Errorf("expected a ComplianceRemediation but got a %T", obj)
+	}
+	if !rem.Spec.Apply {
+		delete(rem.Annotations, compv1alpha1.RemediationApplyRequesterAnnotation)
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	requester := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		old := &compv1alpha1.ComplianceRemediation{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("cannot decode the ComplianceRemediation being updated: %w", err)
+		}
+		// The remediation was set to be applied by whoever set apply
+		if old.Spec.Apply {
+			requester = old.Annotations[compv1alpha1.RemediationApplyRequesterAnnotation]
+		}
+	}
+	if requester == "" {
+		delete(rem.Annotations, compv1alpha1.RemediationApplyRequesterAnnotation)
+		return nil
+	}
+	if rem.Annotations == nil {
+		rem.Annotations = make(map[string]string)
+	}
+	rem.Annotations[compv1alpha1.RemediationApplyRequesterAnnotation] = requester
+	return nil
+}
+
+func (d *approverDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	approval, ok := obj.(*compv1alpha1.RemediationApproval)
+	if !ok {
+		return fmt.Errorf("expected a RemediationApproval but got a %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if approval.Annotations == nil {
+		approval.Annotations = make(map[string]string)
+	}
+
+	if req.Operation == admissionv1.Update {
+		old := &compv1alpha1.RemediationApproval{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("cannot decode the RemediationApproval being updated: %w", err)
+		}
+		// Only approving another remediation is a new approval
+		if old.Spec.RemediationName == approval.Spec.RemediationName {
+			for _, key := range []string{compv1alpha1.RemediationApproverAnnotation, compv1alpha1.RemediationApprovedContentAnnotation} {
+				if value, ok := old.Annotations[key]; ok {
+					approval.Annotations[key] = value
+				} else {
+					delete(approval.Annotations, key)
+				}
+			}
+			return nil
+		}
+	}
+
+	rem := &compv1alpha1.ComplianceRemediation{}
+	key := types.NamespacedName{Name: approval.Spec.RemediationName, Namespace: req.Namespace}
+	if err := d.reader.Get(ctx, key, rem); kerrors.IsNotFound(err) {
+		return fmt.Errorf("the ComplianceRemediation %s doesn't exist", approval.Spec.RemediationName)
+	} else if err != nil {
+		return err
+	}
+	approver := req.UserInfo.Username
+	if rem.Spec.Apply && rem.Annotations[compv1alpha1.RemediationApplyRequesterAnnotation] == approver {
+		return fmt.Errorf("%s set the ComplianceRemediation %s to be applied and can't approve it", approver, rem.Name)
+	}
+	contentHash, err := remediationContentHash(rem)
+	if err != nil {
+		return err
+	}
+	approval.Annotations[compv1alpha1.RemediationApproverAnnotation] = approver
+	approval.Annotations[compv1alpha1.RemediationApprovedContentAnnotation] = contentHash
+	return nil
+}
+
+// remediationContentHash hashes the objects the remediation applies, so that
+// approvals can tell whether the content of the remediation changed since
+func remediationContentHash(rem *compv1alpha1.ComplianceRemediation) (string, error) {
+	content, err := json.Marshal([]interface{}{rem.Spec.Current.Object, rem.Spec.Outdated.Object})
+	if err != nil {
+		return "", fmt.Errorf("cannot hash the content of the remediation: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
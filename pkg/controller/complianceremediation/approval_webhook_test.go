@@ -0,0 +1,138 @@
+package complianceremediation
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+func newAdmissionRequest(operation admissionv1.Operation, username string, old runtime.Object) context.Context {
+	req := admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: operation,
+			Namespace: "test-ns",
+			UserInfo:  authenticationv1.UserInfo{Username: username},
+		},
+	}
+	if old != nil {
+		raw, err := json.Marshal(old)
+		Expect(err).To(BeNil())
+		req.OldObject.Raw = raw
+	}
+	return admission.NewContextWithRequest(context.TODO(), req)
+}
+
+func newWebhookRemediation(apply bool, annotations map[string]string) *compv1alpha1.ComplianceRemediation {
+	return &compv1alpha1.ComplianceRemediation{
+		ObjectMeta: metav1.ObjectMeta{Name: "testRem", Namespace: "test-ns", Annotations: annotations},
+		Spec: compv1alpha1.ComplianceRemediationSpec{
+			ComplianceRemediationSpecMeta: compv1alpha1.ComplianceRemediationSpecMeta{Apply: apply},
+		},
+	}
+}
+
+var _ = Describe("Testing the apply requester webhook", func() {
+	var defaulter *applyRequesterDefaulter
+
+	BeforeEach(func() {
+		defaulter = &applyRequesterDefaulter{}
+	})
+
+	It("records the user that set the remediation to be applied", func() {
+		old := newWebhookRemediation(false, nil)
+		rem := newWebhookRemediation(true, map[string]string{
+			compv1alpha1.RemediationApplyRequesterAnnotation: "someone-else",
+		})
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Update, "alice", old), rem)).To(Succeed())
+		Expect(rem.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationApplyRequesterAnnotation, "alice"))
+	})
+
+	It("keeps the requester while the remediation is set to be applied", func() {
+		old := newWebhookRemediation(true, map[string]string{
+			compv1alpha1.RemediationApplyRequesterAnnotation: "alice",
+		})
+		rem := newWebhookRemediation(true, map[string]string{
+			compv1alpha1.RemediationApplyRequesterAnnotation: "bob",
+		})
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Update, "bob", old), rem)).To(Succeed())
+		Expect(rem.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationApplyRequesterAnnotation, "alice"))
+	})
+
+	It("doesn't let the requester be set without applying the remediation", func() {
+		rem := newWebhookRemediation(false, map[string]string{
+			compv1alpha1.RemediationApplyRequesterAnnotation: "alice",
+		})
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Create, "alice", nil), rem)).To(Succeed())
+		Expect(rem.Annotations).ToNot(HaveKey(compv1alpha1.RemediationApplyRequesterAnnotation))
+	})
+})
+
+var _ = Describe("Testing the approver webhook", func() {
+	var defaulter *approverDefaulter
+	var rem *compv1alpha1.ComplianceRemediation
+
+	newApproval := func(remName string, annotations map[string]string) *compv1alpha1.RemediationApproval {
+		return &compv1alpha1.RemediationApproval{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-approval", Namespace: "test-ns", Annotations: annotations},
+			Spec:       compv1alpha1.RemediationApprovalSpec{RemediationName: remName},
+		}
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).To(Succeed())
+		rem = newWebhookRemediation(true, map[string]string{
+			compv1alpha1.RemediationApplyRequesterAnnotation: "alice",
+		})
+		defaulter = &approverDefaulter{
+			reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(rem).Build(),
+		}
+	})
+
+	It("records the approver and the approved content", func() {
+		approval := newApproval("testRem", map[string]string{
+			compv1alpha1.RemediationApproverAnnotation:        "alice",
+			compv1alpha1.RemediationApprovedContentAnnotation: "forged",
+		})
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Create, "bob", nil), approval)).To(Succeed())
+		contentHash, err := remediationContentHash(rem)
+		Expect(err).To(BeNil())
+		Expect(approval.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationApproverAnnotation, "bob"))
+		Expect(approval.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationApprovedContentAnnotation, contentHash))
+	})
+
+	It("refuses the approval of the user that set the remediation to be applied", func() {
+		approval := newApproval("testRem", nil)
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Create, "alice", nil), approval)).ToNot(Succeed())
+	})
+
+	It("refuses approvals of remediations that don't exist", func() {
+		approval := newApproval("otherRem", nil)
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Create, "bob", nil), approval)).ToNot(Succeed())
+	})
+
+	It("keeps the approver when the approval is updated", func() {
+		recorded := map[string]string{
+			compv1alpha1.RemediationApproverAnnotation:        "bob",
+			compv1alpha1.RemediationApprovedContentAnnotation: "old-content",
+		}
+		old := newApproval("testRem", recorded)
+		approval := newApproval("testRem", map[string]string{
+			compv1alpha1.RemediationApproverAnnotation:        "carol",
+			compv1alpha1.RemediationApprovedContentAnnotation: "new-content",
+		})
+		Expect(defaulter.Default(newAdmissionRequest(admissionv1.Update, "carol", old), approval)).To(Succeed())
+		Expect(approval.Annotations).To(Equal(recorded))
+	})
+})
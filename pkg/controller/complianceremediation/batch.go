@@ -0,0 +1,75 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+const batchedMachineConfigPrefix = "75-compliance-batch-"
+
+// getBatchedMachineConfigName returns the name of the composite MachineConfig
+// that the batched remediations of a node role are applied through
+func getBatchedMachineConfigName(role string) string {
+	return batchedMachineConfigPrefix + role
+}
+
+// Applies or un-applies a batched MachineConfig remediation by rebuilding the
+// composite MachineConfig of its node role from all the batched remediations
+// of the role that are to be applied
+func (r *ReconcileComplianceRemediation) reconcileBatchedRemediation(instance *compv1alpha1.ComplianceRemediation,
+	obj *unstructured.Unstructured, logger logr.Logger) error {
+	role := obj.GetLabels()[mcfgv1.MachineConfigRoleLabelKey]
+	compositeName := getBatchedMachineConfigName(role)
+	objectLogger := logger.WithValues("Object.Name", compositeName, "Object.Kind", obj.GetKind())
+	objectLogger.Info("Reconciling batched remediation object")
+
+	if err := r.setRemediations(instance, objectLogger, instance.Spec.Apply); err != nil {
+		return fmt.Errorf("failed to set related remediations: %w", err)
+	}
+
+	// The remediation might have been applied on its own before
+	if err := r.deleteUnbatchedMachineConfig(obj, objectLogger); err != nil {
+		return err
+	}
+
+	composite := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	composite.SetGroupVersionKind(obj.GroupVersionKind())
+	composite.SetName(compositeName)
+	composite.SetLabels(map[string]string{mcfgv1.MachineConfigRoleLabelKey: role})
+	batched, err := r.getRemediationObjectsToMerge(instance, obj, composite,
+		func(rem *compv1alpha1.ComplianceRemediation, remObj *unstructured.Unstructured) bool {
+			return rem.Spec.Batch && remObj.GetLabels()[mcfgv1.MachineConfigRoleLabelKey] == role
+		}, objectLogger)
+	if err != nil {
+		return err
+	}
+	return r.reconcileMergedObject(composite, batched, objectLogger)
+}
+
+// Deletes the MachineConfig that the operator created for the remediation
+// when it was applied on its own
+func (r *ReconcileComplianceRemediation) deleteUnbatchedMachineConfig(obj *unstructured.Unstructured, logger logr.Logger) error {
+	found := obj.DeepCopy()
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName()}, found)
+	if kerrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !compv1alpha1.RemediationWasCreatedByOperator(found) {
+		return nil
+	}
+	logger.Info("Deleting the MachineConfig of the remediation in favor of the composite one", "MachineConfig.Name", obj.GetName())
+	if err := r.Client.Delete(context.TODO(), found); err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
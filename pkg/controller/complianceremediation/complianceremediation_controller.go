@@ -18,6 +18,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,9 +26,11 @@ import (
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 )
 
 const ctrlName = "remediationctrl"
@@ -49,23 +52,33 @@ func (r *ReconcileComplianceRemediation) SetupWithManager(mgr ctrl.Manager) erro
 // Add creates a new ComplianceRemediation Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, met *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
-	return add(mgr, newReconciler(mgr, met))
+	notifications := common.NewNotificationQueue(ctrlName, met)
+	if err := mgr.Add(notifications); err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, met, notifications))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, met *metrics.Metrics, notifications *common.NotificationQueue) reconcile.Reconciler {
 	return &ReconcileComplianceRemediation{Client: mgr.GetClient(), Scheme: mgr.GetScheme(),
-		Recorder: common.NewSafeRecorder(ctrlName, mgr),
-		Metrics:  met,
+		Recorder:      common.NewSafeRecorder(ctrlName, mgr),
+		Metrics:       met,
+		notifications: notifications,
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
-	// Watch for changes to primary resource ComplianceRemediation
+	approvalMapper := &remediationApprovalMapper{mgr.GetClient()}
+
+	// Watch for changes to primary resource ComplianceRemediation and to the
+	// approvals that refer to them
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("complianceremediation-controller").
-		For(&compv1alpha1.ComplianceRemediation{}).Complete(r)
+		For(&compv1alpha1.ComplianceRemediation{}).
+		Watches(&compv1alpha1.RemediationApproval{}, handler.EnqueueRequestsFromMapFunc(approvalMapper.Map)).
+		Complete(r)
 }
 
 // blank assignment to verify that ReconcileComplianceRemediation implements reconcile.Reconciler
@@ -79,6 +92,11 @@ type ReconcileComplianceRemediation struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Metrics  *metrics.Metrics
+	// sends the state changes of the remediations in the background
+	notifications *common.NotificationQueue
+	// returns the publisher to the Kafka topic of a suite, defaults to
+	// utils.NewKafkaPublisher
+	newKafkaPublisher func(config *utils.KafkaConfig) utils.KafkaPublisher
 }
 
 // Reconcile reads that state of the cluster for a ComplianceRemediation object and makes changes based on the state read
@@ -119,11 +137,14 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 		reqLogger.Info("Updating remediation due to missing application state")
 		rCopy := remediationInstance.DeepCopy()
 		rCopy.Status.ApplicationState = compv1alpha1.RemediationPending
+		rCopy.Status.RebootRequired = utils.RemediationRequiresReboot(rCopy.Spec.Current.Object)
+		rCopy.Status.CheckResult = rCopy.GetCheckResultName()
 		if updErr := r.Client.Status().Update(context.TODO(), rCopy); updErr != nil {
 			// metric remediation error
 			return reconcile.Result{}, fmt.Errorf("updating default remediation application state: %s", updErr)
 		}
 		r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+		r.publishStateChange(remediationInstance.Status.ApplicationState, rCopy, reqLogger)
 		return reconcile.Result{}, nil
 	}
 	if isNoLongerOutdated(remediationInstance) {
@@ -162,11 +183,84 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 			return reconcile.Result{}, valueReqErr
 		}
 	}
+	if remediationInstance.Spec.Apply && remediationInstance.HasAnnotation(compv1alpha1.RemediationValueUsedAnnotation) {
+		reason, valueErr := r.getInvalidValuesReason(remediationInstance, reqLogger)
+		if valueErr != nil {
+			return common.ReturnWithRetriableError(reqLogger, valueErr)
+		}
+		if reason != "" {
+			return reconcile.Result{}, r.flagInvalidValues(remediationInstance, reason, reqLogger)
+		}
+	}
+
+	if remediationInstance.Spec.Apply {
+		pendingReason, approvalErr := r.getPendingApprovalReason(remediationInstance, reqLogger)
+		if approvalErr != nil {
+			return common.ReturnWithRetriableError(reqLogger, approvalErr)
+		}
+		if pendingReason != "" {
+			return reconcile.Result{}, r.waitForApproval(remediationInstance, pendingReason, reqLogger)
+		}
+	}
+
+	if remediationInstance.Spec.Apply && len(remediationInstance.Spec.DependsOn) > 0 {
+		pending, prereqErr := r.getPendingPrerequisites(remediationInstance)
+		if prereqErr != nil && common.IsRetriable(prereqErr) {
+			return common.ReturnWithRetriableError(reqLogger, prereqErr)
+		} else if prereqErr != nil {
+			return reconcile.Result{}, r.reconcileRemediationStatus(remediationInstance, reqLogger, prereqErr)
+		}
+		if len(pending) > 0 {
+			return r.waitForPrerequisites(remediationInstance, pending, reqLogger)
+		}
+	}
+
+	if remediationInstance.Spec.Apply && (remediationInstance.Spec.ApplyAfter != nil || remediationInstance.Spec.ApplyWindow != nil) {
+		now := time.Now()
+		delay, scheduleErr := getScheduledApplyDelay(remediationInstance, now)
+		if scheduleErr != nil {
+			return reconcile.Result{}, r.reconcileRemediationStatus(remediationInstance, reqLogger, scheduleErr)
+		}
+		if delay > 0 {
+			return r.waitForSchedule(remediationInstance, delay, now, reqLogger)
+		}
+	}
+
+	if remediationInstance.Spec.Apply {
+		conflicts, conflictErr := r.getMachineConfigConflicts(remediationInstance, reqLogger)
+		if conflictErr != nil {
+			return common.ReturnWithRetriableError(reqLogger, conflictErr)
+		}
+		if len(conflicts) > 0 {
+			return r.flagConflicts(remediationInstance, conflicts, reqLogger)
+		}
+	}
 
 	//if no UnmetDependencies, UnsetValue, ValueRequired
 	if !(remediationInstance.HasUnmetDependencies() || remediationInstance.HasAnnotation(compv1alpha1.RemediationUnsetValueAnnotation) || remediationInstance.HasAnnotation(compv1alpha1.RemediationValueRequiredAnnotation)) {
 		reconcileErr = r.reconcileRemediation(remediationInstance, reqLogger)
 	}
+	var conflictErr *applyConflictError
+	if errors.As(reconcileErr, &conflictErr) {
+		return r.flagApplyConflict(remediationInstance, conflictErr, reqLogger)
+	}
+	if isTransientError(reconcileErr) {
+		return r.retryRemediation(remediationInstance, reconcileErr, reqLogger)
+	}
+
+	// Remediations that aren't applied can be previewed
+	if remediationInstance.Spec.DryRun && !remediationInstance.Spec.Apply {
+		r.dryRunRemediation(remediationInstance, reqLogger)
+	} else {
+		remediationInstance.Status.DryRun = nil
+	}
+	// Reviewers can see what applying the remediation changes in an existing
+	// object before applying it
+	if !remediationInstance.Spec.Apply {
+		r.diffRemediation(remediationInstance, reqLogger)
+	} else {
+		remediationInstance.Status.Diff = nil
+	}
 
 	// this would have been much nicer with go 1.13 using errors.Is()
 	// Only return if the error is retriable. Else, we persist it in the status
@@ -174,6 +268,12 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 		return common.ReturnWithRetriableError(reqLogger, reconcileErr)
 	}
 
+	// MachineConfig remediations are only applied once the nodes of their
+	// pool run them
+	if reconcileErr == nil {
+		r.setRolloutStatus(remediationInstance, reqLogger)
+	}
+
 	// Second, we'll reconcile the status of the Remediation itself
 	statusErr := r.reconcileRemediationStatus(remediationInstance, reqLogger, reconcileErr)
 	// this would have been much nicer with go 1.13 using errors.Is()
@@ -181,6 +281,12 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 		return common.ReturnWithRetriableError(reqLogger, statusErr)
 	}
 
+	if rollout := remediationInstance.Status.Rollout; rollout != nil && !rollout.IsDone() {
+		reqLogger.Info("Remediation is rolling out to the nodes of its pool. Requeuing",
+			"MachineConfigPool.Name", rollout.Pool, "updated", rollout.UpdatedNodes, "total", rollout.TotalNodes)
+		return reconcile.Result{Requeue: true, RequeueAfter: rolloutPollInterval}, nil
+	}
+
 	if remediationInstance.Spec.Apply && remediationInstance.HasUnmetKubeDependencies() {
 		reqLogger.Info("Has unmet kubernetes object dependencies. Requeuing")
 		return reconcile.Result{Requeue: true, RequeueAfter: defaultDependencyRequeueTime}, nil
@@ -194,27 +300,22 @@ func (r *ReconcileComplianceRemediation) Reconcile(ctx context.Context, request
 func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) error {
 	logger.Info("Reconciling remediation")
 
-	obj := getApplicableObject(instance, logger)
-	if obj == nil {
-		return common.NewNonRetriableCtrlError("Invalid Remediation: No object given")
+	obj, err := r.renderRemediationObject(instance, logger)
+	if err != nil {
+		return err
 	}
-	if utils.IsMachineConfig(obj) {
-		if err := r.verifyAndCompleteMC(obj, instance); err != nil {
-			return err
-		}
+	if instance.Spec.Batch && utils.IsMachineConfig(obj) {
+		return r.reconcileBatchedRemediation(instance, obj, logger)
 	}
-	//verify if the remediation is kubeletconfig, and process it
-	if utils.IsKubeletConfig(obj) {
-		if err := r.verifyAndCompleteKC(obj, instance); err != nil {
-			return err
-		}
+	if utils.IsKubeletConfig(obj) && isManagedKubeletConfig(obj) {
+		return r.reconcileKubeletConfigRemediation(instance, obj, logger)
 	}
 
 	objectLogger := logger.WithValues("Object.Name", obj.GetName(), "Object.Namespace", obj.GetNamespace(), "Object.Kind", obj.GetKind())
 	objectLogger.Info("Reconciling remediation object")
 
 	found := obj.DeepCopy()
-	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
 
 	if kerrors.IsForbidden(err) {
 		return common.NewNonRetriableCtrlError(
@@ -234,6 +335,9 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 			if err != nil {
 				return fmt.Errorf("failed to set related remediations to apply: %w", err)
 			}
+			if usesServerSideApply(instance) {
+				return r.applyRemediation(instance, obj, true, objectLogger)
+			}
 			err = r.createRemediation(obj, objectLogger)
 			if err != nil {
 				return fmt.Errorf("failed to create remediation: %w", err)
@@ -241,6 +345,7 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 			return nil
 		}
 		objectLogger.Info("The object wasn't found, so no action is needed to unapply it")
+		instance.Status.PriorState.Object = nil
 		return nil
 	} else if err != nil {
 		return err
@@ -251,13 +356,102 @@ func (r *ReconcileComplianceRemediation) reconcileRemediation(instance *compv1al
 		if err != nil {
 			return fmt.Errorf("failed to set related remediations to apply: %w", err)
 		}
+		if usesServerSideApply(instance) {
+			return r.applyRemediation(instance, obj, compv1alpha1.RemediationWasCreatedByOperator(found), objectLogger)
+		}
+		// Keep the state of the object before the remediation is
+		// applied so that un-applying it can restore it
+		if instance.Status.PriorState.Object == nil && !instance.IsApplied() {
+			objectLogger.Info("Keeping the prior state of the object")
+			instance.Status.PriorState.Object = getPriorState(found)
+		}
 		return r.patchRemediation(obj, objectLogger)
 	}
 	err = r.setRemediations(instance, objectLogger, false)
 	if err != nil {
 		return fmt.Errorf("failed to set related remediations to unapply: %w", err)
 	}
-	return r.deleteRemediation(obj, found, objectLogger)
+	if instance.Status.PriorState.Object != nil {
+		return r.restoreRemediation(instance, obj, found, objectLogger)
+	}
+	if usesServerSideApply(instance) && !compv1alpha1.RemediationWasCreatedByOperator(found) {
+		if !instance.IsApplied() && !managesFields(instance, found) {
+			objectLogger.Info("The remediation was never applied to the object, so no action is needed to unapply it")
+			return nil
+		}
+		return r.unapplyRemediation(instance, obj, objectLogger)
+	}
+	deleted, err := r.deleteRemediation(obj, found, objectLogger)
+	if deleted {
+		instance.Status.LastRollback = &compv1alpha1.ComplianceRemediationRollbackStatus{
+			Action: compv1alpha1.RemediationRollbackDeleted,
+			Time:   metav1.Now(),
+		}
+	}
+	return err
+}
+
+// Gets the object the remediation applies, completed with the details that
+// depend on the cluster
+func (r *ReconcileComplianceRemediation) renderRemediationObject(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) (*unstructured.Unstructured, error) {
+	obj := getApplicableObject(instance, logger)
+	if obj == nil {
+		return nil, common.NewNonRetriableCtrlError("Invalid Remediation: No object given")
+	}
+	if utils.IsMachineConfig(obj) {
+		if err := r.verifyAndCompleteMC(obj, instance); err != nil {
+			return nil, err
+		}
+	}
+	//verify if the remediation is kubeletconfig, and process it
+	if utils.IsKubeletConfig(obj) {
+		if err := r.verifyAndCompleteKC(obj, instance); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// Renders the object of a remediation that isn't applied and validates it
+// through a server-side dry-run. The result is stored in the status of the
+// remediation, which is persisted along with the rest of the status.
+func (r *ReconcileComplianceRemediation) dryRunRemediation(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) {
+	logger.Info("Previewing remediation")
+	instance.Status.DryRun = &compv1alpha1.ComplianceRemediationDryRunStatus{}
+
+	obj, err := r.renderRemediationObject(instance, logger)
+	if err != nil {
+		instance.Status.DryRun.ValidationError = err.Error()
+		return
+	}
+
+	found := obj.DeepCopy()
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	if usesServerSideApply(instance) && (err == nil || kerrors.IsNotFound(err)) {
+		instance.AddOwnershipLabels(obj)
+		if kerrors.IsNotFound(err) {
+			compv1alpha1.AddRemediationAnnotation(obj)
+		}
+		err = r.Client.Patch(context.TODO(), obj, client.Apply, client.FieldOwner(remediationFieldManagerFor(instance)), client.DryRunAll)
+	} else if kerrors.IsNotFound(err) {
+		instance.AddOwnershipLabels(obj)
+		compv1alpha1.AddRemediationAnnotation(obj)
+		err = r.Client.Create(context.TODO(), obj, client.DryRunAll)
+	} else if err == nil {
+		err = r.Client.Patch(context.TODO(), obj, client.Merge, client.DryRunAll)
+	}
+	if err != nil {
+		logger.Info("The dry-run of the remediation failed", "error", err.Error())
+		instance.Status.DryRun.ValidationError = err.Error()
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	rendered, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		instance.Status.DryRun.ValidationError = fmt.Sprintf("couldn't render the remediation: %s", err)
+		return
+	}
+	instance.Status.DryRun.RenderedObject = string(rendered)
 }
 
 // find all the other releated remediation and set the apply to true or false
@@ -325,30 +519,108 @@ func (r *ReconcileComplianceRemediation) patchRemediation(remObj *unstructured.U
 
 }
 
-func (r *ReconcileComplianceRemediation) deleteRemediation(remObj *unstructured.Unstructured, foundObj *unstructured.Unstructured, logger logr.Logger) error {
+// Deletes the object of the remediation. Returns whether the object was
+// deleted.
+func (r *ReconcileComplianceRemediation) deleteRemediation(remObj *unstructured.Unstructured, foundObj *unstructured.Unstructured, logger logr.Logger) (bool, error) {
 
 	if utils.IsKubeletConfig(remObj) {
 		logger.Info("Can't unapply since it is KubeletConfig Remediation")
-		return nil
+		return false, nil
 	}
 
 	logger.Info("Remediation will be deleted")
 
 	if !compv1alpha1.RemediationWasCreatedByOperator(foundObj) {
 		logger.Info("Can't unapply since this object wasn't created by the operator")
-		return nil
+		return false, nil
 	}
 	deleteErr := r.Client.Delete(context.TODO(), remObj)
 
 	if kerrors.IsForbidden(deleteErr) {
-		return common.NewNonRetriableCtrlError(
+		return false, common.NewNonRetriableCtrlError(
 			"Unable to delete fix object from ComplianceRemediation. "+
 				"Please update the compliance-operator's permissions: %s", deleteErr)
 	} else if kerrors.IsNotFound(deleteErr) {
-		return nil
+		return false, nil
+	}
+
+	return deleteErr == nil, deleteErr
+}
+
+// Restores the fields of the object that the remediation changed to the
+// state they had before the remediation was applied. Fields that the
+// remediation added are removed.
+func (r *ReconcileComplianceRemediation) restoreRemediation(instance *compv1alpha1.ComplianceRemediation,
+	remObj *unstructured.Unstructured, foundObj *unstructured.Unstructured, logger logr.Logger) error {
+	logger.Info("Remediation will be rolled back to the prior state of the object")
+
+	restored := foundObj.DeepCopy()
+	for key, value := range remObj.Object {
+		if isObjectMetaField(key) {
+			continue
+		}
+		restoreField(restored.Object, key, value, instance.Status.PriorState.Object.Object)
+	}
+
+	updateErr := r.Client.Update(context.TODO(), restored)
+	if kerrors.IsForbidden(updateErr) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to restore fix object from ComplianceRemediation. "+
+				"Please update the compliance-operator's permissions: %s", updateErr)
+	} else if updateErr != nil {
+		return updateErr
+	}
+
+	instance.Status.PriorState.Object = nil
+	instance.Status.LastRollback = &compv1alpha1.ComplianceRemediationRollbackStatus{
+		Action: compv1alpha1.RemediationRollbackRestored,
+		Time:   metav1.Now(),
+	}
+	return nil
+}
+
+// Sets the field of the object to the value it had in the prior state, or
+// removes it if it wasn't there. Nested objects are restored field by field
+// so that only what the remediation set is reverted.
+func restoreField(current map[string]interface{}, key string, applied interface{}, prior map[string]interface{}) {
+	priorValue, hadPrior := prior[key]
+	appliedMap, appliedIsMap := applied.(map[string]interface{})
+	currentMap, currentIsMap := current[key].(map[string]interface{})
+	priorMap, priorIsMap := priorValue.(map[string]interface{})
+	if appliedIsMap && currentIsMap && (priorIsMap || !hadPrior) {
+		for nestedKey, nestedValue := range appliedMap {
+			restoreField(currentMap, nestedKey, nestedValue, priorMap)
+		}
+		if !hadPrior && len(currentMap) == 0 {
+			delete(current, key)
+		}
+		return
 	}
+	if hadPrior {
+		current[key] = priorValue
+	} else {
+		delete(current, key)
+	}
+}
+
+func isObjectMetaField(key string) bool {
+	return key == "apiVersion" || key == "kind" || key == "metadata" || key == "status"
+}
 
-	return deleteErr
+// Returns the state of the object that un-applying a remediation restores
+func getPriorState(found *unstructured.Unstructured) *unstructured.Unstructured {
+	prior := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	for key, value := range found.Object {
+		if isObjectMetaField(key) {
+			continue
+		}
+		prior.Object[key] = runtime.DeepCopyJSONValue(value)
+	}
+	prior.SetAPIVersion(found.GetAPIVersion())
+	prior.SetKind(found.GetKind())
+	prior.SetName(found.GetName())
+	prior.SetNamespace(found.GetNamespace())
+	return prior
 }
 
 func (r *ReconcileComplianceRemediation) handleUnmetDependencies(rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) (reconcile.Result, error) {
@@ -404,6 +676,66 @@ func (r *ReconcileComplianceRemediation) handleUnmetDependencies(rem *compv1alph
 	return reconcile.Result{Requeue: true, RequeueAfter: defaultDependencyRequeueTime}, nil
 }
 
+// Returns the names of the remediations the remediation depends on that
+// aren't applied yet. Remediations that depend on themselves, directly or
+// through other remediations, are an error.
+func (r *ReconcileComplianceRemediation) getPendingPrerequisites(rem *compv1alpha1.ComplianceRemediation) ([]string, error) {
+	var pending []string
+	for _, name := range rem.Spec.DependsOn {
+		prereq := &compv1alpha1.ComplianceRemediation{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: rem.Namespace}, prereq)
+		if kerrors.IsNotFound(err) {
+			// The remediation might be created by a later scan
+			pending = append(pending, name)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("error getting the remediation %s this remediation depends on: %w", name, err)
+		}
+		if !prereq.IsApplied() {
+			pending = append(pending, name)
+		}
+	}
+
+	// Walk the rest of the prerequisites to catch circular dependencies
+	visited := make(map[string]bool)
+	toVisit := append([]string{}, rem.Spec.DependsOn...)
+	for len(toVisit) > 0 {
+		name := toVisit[0]
+		toVisit = toVisit[1:]
+		if name == rem.Name {
+			return nil, common.NewNonRetriableCtrlError("the remediation depends on itself")
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		prereq := &compv1alpha1.ComplianceRemediation{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: rem.Namespace}, prereq); err != nil {
+			continue
+		}
+		toVisit = append(toVisit, prereq.Spec.DependsOn...)
+	}
+	return pending, nil
+}
+
+// Keeps the remediation pending until the remediations it depends on are
+// applied
+func (r *ReconcileComplianceRemediation) waitForPrerequisites(rem *compv1alpha1.ComplianceRemediation, pending []string, logger logr.Logger) (reconcile.Result, error) {
+	logger.Info("Waiting for the remediations this remediation depends on to be applied", "pending", pending)
+	message := fmt.Sprintf("Waiting for the remediations this remediation depends on to be applied: %s", strings.Join(pending, ","))
+	if rem.Status.ApplicationState != compv1alpha1.RemediationPending || rem.Status.ErrorMessage != message {
+		rCopy := rem.DeepCopy()
+		rCopy.Status.ApplicationState = compv1alpha1.RemediationPending
+		rCopy.Status.ErrorMessage = message
+		if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+		r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: defaultDependencyRequeueTime}, nil
+}
+
 func (r *ReconcileComplianceRemediation) countXCCDFUnmetDependencies(rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) (int, error) {
 	var nMissingDeps int
 	deps := rem.Annotations[compv1alpha1.RemediationDependencyAnnotation]
@@ -642,6 +974,7 @@ func (r *ReconcileComplianceRemediation) reconcileRemediationStatus(instance *co
 		return err
 	}
 	r.Metrics.IncComplianceRemediationStatus(instanceCopy.Name, instanceCopy.Status)
+	r.publishStateChange(instance.Status.ApplicationState, instanceCopy, logger)
 
 	return nil
 }
@@ -721,7 +1054,7 @@ func (r *ReconcileComplianceRemediation) verifyAndCompleteKC(obj *unstructured.U
 	}
 
 	// We will need to create a kubelet config if there is no custom KC
-	kubeletName := "compliance-operator-kubelet-" + pool.GetName()
+	kubeletName := utils.GetManagedKubeletConfigName(pool.GetName())
 
 	// Set kubelet config name
 	obj.SetName(kubeletName)
@@ -764,6 +1097,8 @@ func isNoLongerOutdated(r *compv1alpha1.ComplianceRemediation) bool {
 }
 
 func (r *ReconcileComplianceRemediation) setRemediationStatus(rem *compv1alpha1.ComplianceRemediation, errorApplying error, logger logr.Logger) {
+	rem.Status.RebootRequired = utils.RemediationRequiresReboot(rem.Spec.Current.Object)
+	rem.Status.CheckResult = rem.GetCheckResultName()
 	if errorApplying != nil {
 		if wasErrorOnOptionalRemediation(rem, errorApplying) {
 			logger.Info("Optional remediation couldn't be applied")
@@ -777,6 +1112,10 @@ func (r *ReconcileComplianceRemediation) setRemediationStatus(rem *compv1alpha1.
 		rem.Status.ErrorMessage = errorApplying.Error()
 		return
 	}
+	// The remediation is neither in error nor waiting for the
+	// remediations it depends on anymore
+	rem.Status.ErrorMessage = ""
+	rem.Status.FailedAttempts = nil
 
 	if !rem.Spec.Apply {
 		logger.Info("Remediation will now be unapplied")
@@ -802,6 +1141,12 @@ func (r *ReconcileComplianceRemediation) setRemediationStatus(rem *compv1alpha1.
 		return
 	}
 
+	if rem.Status.Rollout != nil && !rem.Status.Rollout.IsDone() {
+		logger.Info("Remediation is rolling out to the nodes of its pool")
+		rem.Status.ApplicationState = compv1alpha1.RemediationRollingOut
+		return
+	}
+
 	logger.Info("Remediation will now be applied")
 	rem.Status.ApplicationState = compv1alpha1.RemediationApplied
 }
@@ -2,12 +2,17 @@ package complianceremediation
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/clarketm/json"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -23,10 +28,37 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// fakeKafkaPublisher keeps the messages it's given instead of publishing them
+type fakeKafkaPublisher struct {
+	published *publishedMessages
+}
+
+func (p *fakeKafkaPublisher) Publish(_ context.Context, messages []utils.KafkaMessage) error {
+	p.published.Lock()
+	defer p.published.Unlock()
+	p.published.messages = append(p.published.messages, messages...)
+	return nil
+}
+
+// publishedMessages are the messages given to the fake publishers, which are
+// given the messages in the background
+type publishedMessages struct {
+	sync.Mutex
+	messages []utils.KafkaMessage
+}
+
+func (p *publishedMessages) get() []utils.KafkaMessage {
+	p.Lock()
+	defer p.Unlock()
+	return append([]utils.KafkaMessage{}, p.messages...)
+}
+
 var _ = Describe("Testing complianceremediation controller", func() {
 
 	var (
@@ -128,7 +160,8 @@ var _ = Describe("Testing complianceremediation controller", func() {
 		err = mockMetrics.Register()
 		Expect(err).To(BeNil())
 
-		reconciler = &ReconcileComplianceRemediation{Client: client, Scheme: cscheme, Metrics: mockMetrics}
+		reconciler = &ReconcileComplianceRemediation{Client: client, Scheme: cscheme, Metrics: mockMetrics,
+			notifications: common.NewNotificationQueue("test", mockMetrics)}
 		zaplog, _ := zap.NewDevelopment()
 		logger = zapr.NewLogger(zaplog)
 	})
@@ -156,27 +189,875 @@ var _ = Describe("Testing complianceremediation controller", func() {
 						"key": "val",
 					},
 				}
-				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
-				Expect(err).ToNot(HaveOccurred())
-				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
-					Object: unstructuredCM,
-				}
-				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the current remediation", func() {
+				By("running a reconcile loop")
+
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the remediation should be applied")
+				foundCM := &corev1.ConfigMap{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundCM.GetName()).To(Equal("my-cm"))
+				Expect(foundCM.Data["key"]).To(Equal("val"))
+			})
+		})
+
+		Context("with remediations it depends on", func() {
+			var prereq *compv1alpha1.ComplianceRemediation
+			remKey := types.NamespacedName{Name: "testRem"}
+
+			reconcileAndGetRemediation := func() (reconcile.Result, *compv1alpha1.ComplianceRemediation) {
+				res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return res, rem
+			}
+
+			BeforeEach(func() {
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				remediationinstance.Spec.DependsOn = []string{"prereqRem"}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				prereq = &compv1alpha1.ComplianceRemediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "prereqRem",
+					},
+				}
+				err = reconciler.Client.Create(context.TODO(), prereq)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should stay pending until the remediations it depends on are applied", func() {
+				By("reconciling while the other remediation isn't applied")
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.Requeue).To(BeTrue())
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPending))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("prereqRem"))
+				err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("reconciling once the other remediation is applied")
+				prereq.Status.ApplicationState = compv1alpha1.RemediationApplied
+				err = reconciler.Client.Status().Update(context.TODO(), prereq)
+				Expect(err).NotTo(HaveOccurred())
+				_, rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should error out if the remediations depend on each other", func() {
+				prereq.Spec.DependsOn = []string{remediationinstance.Name}
+				err := reconciler.Client.Update(context.TODO(), prereq)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationError))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("depends on itself"))
+			})
+		})
+
+		Context("with a scheduled remediation", func() {
+			remKey := types.NamespacedName{Name: "testRem"}
+
+			reconcileAndGetRemediation := func() (reconcile.Result, *compv1alpha1.ComplianceRemediation) {
+				res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return res, rem
+			}
+
+			BeforeEach(func() {
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				remediationinstance.Spec.ApplyAfter = &metav1.Time{Time: time.Now().Add(time.Hour)}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should only be applied once it's scheduled to", func() {
+				By("reconciling before the remediation is scheduled to be applied")
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationScheduled))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("scheduled to be applied at"))
+				err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("reconciling once the remediation is scheduled to be applied")
+				rem.Spec.ApplyAfter = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+				err = reconciler.Client.Update(context.TODO(), rem)
+				Expect(err).NotTo(HaveOccurred())
+				_, rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should wait for its apply window to open", func() {
+				remediationinstance.Spec.ApplyAfter = nil
+				// The window opened a minute ago and closed right away
+				opened := time.Now().Add(-time.Minute)
+				remediationinstance.Spec.ApplyWindow = &compv1alpha1.RemediationApplyWindow{
+					Schedule: fmt.Sprintf("%d %d * * *", opened.Minute(), opened.Hour()),
+					Duration: metav1.Duration{Duration: time.Second},
+				}
+				err := reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.RequeueAfter).To(BeNumerically(">", 23*time.Hour))
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationScheduled))
+			})
+
+			It("should error out if the apply window is wrongly formatted", func() {
+				remediationinstance.Spec.ApplyWindow = &compv1alpha1.RemediationApplyWindow{
+					Schedule: "not a schedule",
+					Duration: metav1.Duration{Duration: time.Hour},
+				}
+				err := reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationError))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("wrongly formatted"))
+			})
+		})
+
+		Context("with a suite that requires approvals", func() {
+			remKey := types.NamespacedName{Name: "testRem"}
+
+			reconcileAndGetRemediation := func() *compv1alpha1.ComplianceRemediation {
+				_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return rem
+			}
+			// newApproval returns an approval as recorded by the webhooks
+			// for the current content of the remediation
+			newApproval := func(name, remName, approver string) *compv1alpha1.RemediationApproval {
+				rem := &compv1alpha1.ComplianceRemediation{}
+				Expect(reconciler.Client.Get(context.TODO(), remKey, rem)).To(Succeed())
+				contentHash, err := remediationContentHash(rem)
+				Expect(err).To(BeNil())
+				return &compv1alpha1.RemediationApproval{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: name,
+						Annotations: map[string]string{
+							compv1alpha1.RemediationApproverAnnotation:        approver,
+							compv1alpha1.RemediationApprovedContentAnnotation: contentHash,
+						},
+					},
+					Spec: compv1alpha1.RemediationApprovalSpec{
+						RemediationName: remName,
+					},
+				}
+			}
+
+			AfterEach(func() {
+				approvalWebhooksServed.Store(false)
+			})
+
+			BeforeEach(func() {
+				approvalWebhooksServed.Store(true)
+				suite := &compv1alpha1.ComplianceSuite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "mySuite",
+					},
+				}
+				suite.Spec.RequireRemediationApproval = true
+				err := reconciler.Client.Create(context.TODO(), suite)
+				Expect(err).NotTo(HaveOccurred())
+
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = map[string]string{
+					compv1alpha1.RemediationApplyRequesterAnnotation: "alice",
+				}
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should only be applied once it's approved", func() {
+				By("reconciling before the remediation is approved")
+				rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+				err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("approving another remediation")
+				otherApproval := newApproval("other-approval", "otherRem", "bob")
+				err = reconciler.Client.Create(context.TODO(), otherApproval)
+				Expect(err).NotTo(HaveOccurred())
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+
+				By("approving the remediation")
+				approval := newApproval("my-approval", "testRem", "bob")
+				approval.Spec.Comment = "CHG-1234"
+				err = reconciler.Client.Create(context.TODO(), approval)
+				Expect(err).NotTo(HaveOccurred())
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should publish its state changes to the Kafka topic of the suite", func() {
+				queueCtx, stopQueue := context.WithCancel(context.TODO())
+				defer stopQueue()
+				go reconciler.notifications.Start(queueCtx)
+				published := &publishedMessages{}
+				reconciler.newKafkaPublisher = func(config *utils.KafkaConfig) utils.KafkaPublisher {
+					defer GinkgoRecover()
+					Expect(config.Topic).To(Equal("compliance-events"))
+					return &fakeKafkaPublisher{published: published}
+				}
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "kafka"},
+					Data: map[string][]byte{
+						utils.KafkaBrokersKey: []byte("kafka-0:9092"),
+						utils.KafkaTopicKey:   []byte("compliance-events"),
+					},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), secret)).To(Succeed())
+				suite := &compv1alpha1.ComplianceSuite{}
+				Expect(reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "mySuite"}, suite)).To(Succeed())
+				suite.Spec.ResultForwarding = &compv1alpha1.ResultForwarding{
+					Kafka: &compv1alpha1.KafkaForwarding{SecretName: "kafka"},
+				}
+				Expect(reconciler.Client.Update(context.TODO(), suite)).To(Succeed())
+
+				reconcileAndGetRemediation()
+				reconcileAndGetRemediation()
+				Eventually(published.get).Should(HaveLen(1))
+				Consistently(published.get, "100ms").Should(HaveLen(1))
+				messages := published.get()
+				Expect(messages[0].Key).To(Equal("mySuite"))
+				change := messages[0].Value.(remediationStateChange)
+				Expect(change.Remediation).To(Equal("testRem"))
+				Expect(change.Scan).To(Equal("myScan"))
+				Expect(change.PreviousState).To(Equal(string(compv1alpha1.RemediationNotApplied)))
+				Expect(change.State).To(Equal(string(compv1alpha1.RemediationPendingApproval)))
+
+				approval := newApproval("my-approval", "testRem", "bob")
+				Expect(reconciler.Client.Create(context.TODO(), approval)).To(Succeed())
+				reconcileAndGetRemediation()
+				Eventually(published.get).Should(HaveLen(2))
+				Expect(published.get()[1].Value).To(HaveField("State", string(compv1alpha1.RemediationApplied)))
+			})
+
+			It("should not count the approval of the user that set it to be applied", func() {
+				Expect(reconciler.Client.Create(context.TODO(), newApproval("my-approval", "testRem", "alice"))).To(Succeed())
+				rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+				Expect(rem.Status.ErrorMessage).To(Equal(pendingApprovalMessage))
+			})
+
+			It("should not count approvals that weren't recorded by the webhooks", func() {
+				approval := newApproval("my-approval", "testRem", "bob")
+				approval.Annotations = nil
+				Expect(reconciler.Client.Create(context.TODO(), approval)).To(Succeed())
+				rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+
+				By("not serving the webhooks")
+				approvalWebhooksServed.Store(false)
+				Expect(reconciler.Client.Create(context.TODO(), newApproval("other-approval", "testRem", "bob"))).To(Succeed())
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+				Expect(rem.Status.ErrorMessage).To(Equal(approvalWebhooksNotServedMessage))
+			})
+
+			It("should need a new approval once its content changes", func() {
+				Expect(reconciler.Client.Create(context.TODO(), newApproval("my-approval", "testRem", "bob"))).To(Succeed())
+
+				rem := &compv1alpha1.ComplianceRemediation{}
+				Expect(reconciler.Client.Get(context.TODO(), remKey, rem)).To(Succeed())
+				Expect(unstructured.SetNestedStringMap(rem.Spec.Current.Object.Object, map[string]string{"key": "new"}, "data")).To(Succeed())
+				Expect(reconciler.Client.Update(context.TODO(), rem)).To(Succeed())
+
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+				err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				Expect(reconciler.Client.Create(context.TODO(), newApproval("new-approval", "testRem", "bob"))).To(Succeed())
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+			})
+
+			It("should need the user that set it to be applied to be recorded", func() {
+				rem := &compv1alpha1.ComplianceRemediation{}
+				Expect(reconciler.Client.Get(context.TODO(), remKey, rem)).To(Succeed())
+				rem.Annotations = nil
+				Expect(reconciler.Client.Update(context.TODO(), rem)).To(Succeed())
+				Expect(reconciler.Client.Create(context.TODO(), newApproval("my-approval", "testRem", "bob"))).To(Succeed())
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationPendingApproval))
+				Expect(rem.Status.ErrorMessage).To(Equal(unknownApplyRequesterMessage))
+			})
+
+			It("should map approvals to the remediation they refer to", func() {
+				mapper := &remediationApprovalMapper{reconciler.Client}
+				approval := &compv1alpha1.RemediationApproval{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-approval",
+						Namespace: "test-ns",
+					},
+					Spec: compv1alpha1.RemediationApprovalSpec{
+						RemediationName: "testRem",
+					},
+				}
+				requests := mapper.Map(context.TODO(), approval)
+				Expect(requests).To(ConsistOf(reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "testRem", Namespace: "test-ns"},
+				}))
+			})
+		})
+
+		Context("with a remediation that's server-side applied", func() {
+			var applied []*unstructured.Unstructured
+			var fieldManagers []string
+			var applyErr error
+
+			BeforeEach(func() {
+				applied = nil
+				fieldManagers = nil
+				applyErr = nil
+				reconciler.Client = interceptor.NewClient(reconciler.Client.(client.WithWatch), interceptor.Funcs{
+					// The fake client doesn't support server-side apply
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if patch.Type() != types.ApplyPatchType {
+							return c.Patch(ctx, obj, patch, opts...)
+						}
+						patchOpts := &client.PatchOptions{}
+						patchOpts.ApplyOptions(opts)
+						applied = append(applied, obj.(*unstructured.Unstructured).DeepCopy())
+						fieldManagers = append(fieldManagers, patchOpts.FieldManager)
+						return applyErr
+					},
+				})
+
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+					Data: map[string]string{
+						"key": "val",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.ApplyMethod = compv1alpha1.RemediationApplyServerSide
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			createExistingCM := func() {
+				existing := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+					Data: map[string]string{
+						"other": "val",
+					},
+				}
+				err := reconciler.Client.Create(context.TODO(), existing)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			It("should apply the object with the field manager of the remediations", func() {
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(applied).To(HaveLen(1))
+				Expect(fieldManagers).To(ConsistOf(remediationFieldManagerFor(remediationinstance)))
+				Expect(applied[0].GetName()).To(Equal("my-cm"))
+				Expect(compv1alpha1.RemediationWasCreatedByOperator(applied[0])).To(BeTrue())
+				Expect(applied[0].GetLabels()).To(HaveKeyWithValue(compv1alpha1.SuiteLabel, "mySuite"))
+				data, _, _ := unstructured.NestedStringMap(applied[0].Object, "data")
+				Expect(data).To(HaveKeyWithValue("key", "val"))
+			})
+
+			It("should not mark existing objects as created by the operator", func() {
+				createExistingCM()
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(applied).To(HaveLen(1))
+				Expect(compv1alpha1.RemediationWasCreatedByOperator(applied[0])).To(BeFalse())
+				Expect(remediationinstance.Status.PriorState.Object).To(BeNil())
+			})
+
+			It("should report conflicts with fields managed by someone else", func() {
+				createExistingCM()
+				applyErr = kerrors.NewApplyConflict([]metav1.StatusCause{{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl-edit"`,
+					Field:   ".data.key",
+				}}, `Apply failed with 1 conflict: conflict with "kubectl-edit": .data.key`)
+
+				remKey := types.NamespacedName{Name: "testRem"}
+				res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				Expect(res.RequeueAfter).To(Equal(defaultDependencyRequeueTime))
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationConflicting))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring(`conflict with "kubectl-edit": .data.key`))
+			})
+
+			It("should only remove the fields it manages when un-applied", func() {
+				createExistingCM()
+				remediationinstance.Spec.Apply = false
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationApplied
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(applied).To(HaveLen(1))
+				Expect(fieldManagers).To(ConsistOf(remediationFieldManagerFor(remediationinstance)))
+				Expect(applied[0].GetName()).To(Equal("my-cm"))
+				Expect(applied[0].GetKind()).To(Equal("ConfigMap"))
+				_, hasData, _ := unstructured.NestedFieldNoCopy(applied[0].Object, "data")
+				Expect(hasData).To(BeFalse())
+				Expect(remediationinstance.Status.LastRollback).ToNot(BeNil())
+				Expect(remediationinstance.Status.LastRollback.Action).To(Equal(compv1alpha1.RemediationRollbackRestored))
+
+				By("the object is kept")
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should un-apply the objects whose fields it manages", func() {
+				existing := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+						ManagedFields: []metav1.ManagedFieldsEntry{{
+							Manager:    remediationFieldManagerFor(remediationinstance),
+							Operation:  metav1.ManagedFieldsOperationApply,
+							APIVersion: "v1",
+						}},
+					},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), existing)).To(Succeed())
+				remediationinstance.Spec.Apply = false
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(applied).To(HaveLen(1))
+				Expect(remediationinstance.Status.LastRollback).ToNot(BeNil())
+			})
+
+			It("should not un-apply a remediation that was never applied", func() {
+				createExistingCM()
+				remediationinstance.Spec.Apply = false
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(applied).To(BeEmpty())
+				Expect(remediationinstance.Status.LastRollback).To(BeNil())
+			})
+		})
+
+		Context("with two remediations server-side applied to the same object", func() {
+			var otherRem *compv1alpha1.ComplianceRemediation
+			cmKey := types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}
+
+			cmRemediationObject := func(data map[string]string) *unstructured.Unstructured {
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      cmKey.Name,
+						Namespace: cmKey.Namespace,
+					},
+					Data: data,
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				return &unstructured.Unstructured{Object: unstructuredCM}
+			}
+
+			BeforeEach(func() {
+				// The fake client doesn't support server-side apply, so
+				// the data applied by each field manager is kept and the
+				// object gets the data of all of them, like the API server
+				// would do
+				appliedData := map[string]map[string]string{}
+				reconciler.Client = interceptor.NewClient(reconciler.Client.(client.WithWatch), interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if patch.Type() != types.ApplyPatchType {
+							return c.Patch(ctx, obj, patch, opts...)
+						}
+						patchOpts := &client.PatchOptions{}
+						patchOpts.ApplyOptions(opts)
+						data, _, _ := unstructured.NestedStringMap(obj.(*unstructured.Unstructured).Object, "data")
+						appliedData[patchOpts.FieldManager] = data
+
+						cm := &corev1.ConfigMap{}
+						if err := c.Get(ctx, cmKey, cm); err != nil {
+							return err
+						}
+						cm.Data = map[string]string{}
+						cm.ManagedFields = nil
+						for manager, managerData := range appliedData {
+							for key, val := range managerData {
+								cm.Data[key] = val
+							}
+							if len(managerData) > 0 {
+								cm.ManagedFields = append(cm.ManagedFields, metav1.ManagedFieldsEntry{
+									Manager:    manager,
+									Operation:  metav1.ManagedFieldsOperationApply,
+									APIVersion: "v1",
+								})
+							}
+						}
+						return c.Update(ctx, cm)
+					},
+				})
+
+				existing := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      cmKey.Name,
+						Namespace: cmKey.Namespace,
+					},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), existing)).To(Succeed())
+
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.Apply = true
+				remediationinstance.Spec.ApplyMethod = compv1alpha1.RemediationApplyServerSide
+				remediationinstance.Spec.Current.Object = cmRemediationObject(map[string]string{"first": "val"})
+				Expect(reconciler.Client.Update(context.TODO(), remediationinstance)).To(Succeed())
+
+				otherRem = remediationinstance.DeepCopy()
+				otherRem.Name = "otherRem"
+				otherRem.ResourceVersion = ""
+				otherRem.Spec.Current.Object = cmRemediationObject(map[string]string{"second": "val"})
+				Expect(reconciler.Client.Create(context.TODO(), otherRem)).To(Succeed())
+			})
+
+			It("should apply each remediation with its own field manager", func() {
+				Expect(remediationFieldManagerFor(remediationinstance)).ToNot(Equal(remediationFieldManagerFor(otherRem)))
+				longRem := &compv1alpha1.ComplianceRemediation{
+					ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 200)},
+				}
+				Expect(len(remediationFieldManagerFor(longRem))).To(BeNumerically("<=", maxFieldManagerLength))
+			})
+
+			It("should keep the fields of the other remediation when one is un-applied", func() {
+				Expect(reconciler.reconcileRemediation(remediationinstance, logger)).To(Succeed())
+				Expect(reconciler.reconcileRemediation(otherRem, logger)).To(Succeed())
+
+				cm := &corev1.ConfigMap{}
+				Expect(reconciler.Client.Get(context.TODO(), cmKey, cm)).To(Succeed())
+				Expect(cm.Data).To(Equal(map[string]string{"first": "val", "second": "val"}))
+
+				remediationinstance.Spec.Apply = false
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationApplied
+				Expect(reconciler.reconcileRemediation(remediationinstance, logger)).To(Succeed())
+
+				Expect(reconciler.Client.Get(context.TODO(), cmKey, cm)).To(Succeed())
+				Expect(cm.Data).To(Equal(map[string]string{"second": "val"}))
+				found, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				foundObj := &unstructured.Unstructured{Object: found}
+				Expect(managesFields(remediationinstance, foundObj)).To(BeFalse())
+				Expect(managesFields(otherRem, foundObj)).To(BeTrue())
+			})
+		})
+
+		Context("with transient errors applying the remediation", func() {
+			remKey := types.NamespacedName{Name: "testRem"}
+			var failures int
+
+			BeforeEach(func() {
+				failures = 0
+				reconciler.Client = interceptor.NewClient(reconciler.Client.(client.WithWatch), interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if failures > 0 {
+							failures--
+							return kerrors.NewInternalError(fmt.Errorf("failed calling webhook: context deadline exceeded"))
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				})
+
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.Apply = true
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			reconcileAndGetRemediation := func() (reconcile.Result, *compv1alpha1.ComplianceRemediation) {
+				res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return res, rem
+			}
+
+			It("should retry with a backoff and record the failed attempts", func() {
+				failures = 2
+
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.RequeueAfter).To(Equal(baseRetryDelay))
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationNotApplied))
+				Expect(rem.Status.FailedAttempts).To(HaveLen(1))
+				Expect(rem.Status.FailedAttempts[0].Error).To(ContainSubstring("context deadline exceeded"))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("Attempt 1 of 5 failed"))
+
+				res, rem = reconcileAndGetRemediation()
+				Expect(res.RequeueAfter).To(Equal(2 * baseRetryDelay))
+				Expect(rem.Status.FailedAttempts).To(HaveLen(2))
+
+				By("clearing the failed attempts once applying succeeds")
+				_, rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.FailedAttempts).To(BeEmpty())
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+			})
+
+			It("should give up after too many failed attempts", func() {
+				failures = maxFailedAttempts + 1
+				for i := 0; i < maxFailedAttempts; i++ {
+					reconcileAndGetRemediation()
+				}
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.Requeue).To(BeFalse())
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationError))
+				Expect(rem.Status.FailedAttempts).To(HaveLen(maxFailedAttempts))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("5 times in a row"))
+			})
+
+			It("should cap the backoff", func() {
+				Expect(getRetryDelay(1)).To(Equal(baseRetryDelay))
+				Expect(getRetryDelay(3)).To(Equal(4 * baseRetryDelay))
+				Expect(getRetryDelay(100)).To(Equal(maxRetryDelay))
+			})
+		})
+
+		Context("with values set through a tailored profile", func() {
+			remKey := types.NamespacedName{Name: "testRem"}
+			tailoringKey := types.NamespacedName{Name: "my-tailoring"}
+
+			setTailoredValue := func(value string) {
+				tailoring := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<xccdf-1.2:Tailoring xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2" id="xccdf_compliance.openshift.io_tailoring_my-tp">
+  <xccdf-1.2:Profile id="xccdf_compliance.openshift.io_profile_my-tp">
+    <xccdf-1.2:set-value idref="xccdf_org.ssgproject.content_value_var_sshd_max_sessions">%s</xccdf-1.2:set-value>
+  </xccdf-1.2:Profile>
+</xccdf-1.2:Tailoring>`, value)
+				cm := &corev1.ConfigMap{}
+				err := reconciler.Client.Get(context.TODO(), tailoringKey, cm)
+				if kerrors.IsNotFound(err) {
+					cm.Name = tailoringKey.Name
+					cm.Data = map[string]string{"tailoring.xml": tailoring}
+					err = reconciler.Client.Create(context.TODO(), cm)
+				} else {
+					Expect(err).To(BeNil())
+					cm.Data["tailoring.xml"] = tailoring
+					err = reconciler.Client.Update(context.TODO(), cm)
+				}
+				Expect(err).To(BeNil())
+			}
+
+			reconcileAndGetRemediation := func() *compv1alpha1.ComplianceRemediation {
+				_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return rem
+			}
+
+			BeforeEach(func() {
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = map[string]string{
+					compv1alpha1.RemediationValueUsedAnnotation: "var-sshd-max-sessions",
+				}
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				scanInstance.Spec.Content = "ssg-ocp4-ds.xml"
+				scanInstance.Spec.ContentImage = "quay.io/content:latest"
+				scanInstance.Spec.TailoringConfigMap = &compv1alpha1.TailoringConfigMapRef{Name: tailoringKey.Name}
+				err = reconciler.Client.Update(context.TODO(), scanInstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				pb := &compv1alpha1.ProfileBundle{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "ocp4",
+					},
+					Spec: compv1alpha1.ProfileBundleSpec{
+						ContentImage: "quay.io/content:latest",
+						ContentFile:  "ssg-ocp4-ds.xml",
+					},
+				}
+				err = reconciler.Client.Create(context.TODO(), pb)
+				Expect(err).NotTo(HaveOccurred())
+
+				lowerBound := 1
+				variable := &compv1alpha1.Variable{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "ocp4-var-sshd-max-sessions",
+					},
+					VariablePayload: compv1alpha1.VariablePayload{
+						ID:    "xccdf_org.ssgproject.content_value_var_sshd_max_sessions",
+						Type:  compv1alpha1.VarTypeNumber,
+						Value: "10",
+						Constraints: &compv1alpha1.ValueConstraints{
+							LowerBound: &lowerBound,
+						},
+					},
+				}
+				err = reconciler.Client.Create(context.TODO(), variable)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
-			It("should reconcile the current remediation", func() {
-				By("running a reconcile loop")
-
-				err := reconciler.reconcileRemediation(remediationinstance, logger)
-				Expect(err).To(BeNil())
-
-				By("the remediation should be applied")
-				foundCM := &corev1.ConfigMap{}
-				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
-				Expect(err).ToNot(HaveOccurred())
-				Expect(foundCM.GetName()).To(Equal("my-cm"))
-				Expect(foundCM.Data["key"]).To(Equal("val"))
+			It("should need review until the values pass the validation of their variables", func() {
+				By("reconciling a remediation with a value that's out of bounds")
+				setTailoredValue("0")
+				rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationNeedsReview))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("var-sshd-max-sessions: value 0 is lower than the lower bound 1"))
+				err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("reconciling a remediation with a value of the wrong type")
+				setTailoredValue("ten")
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationNeedsReview))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("invalid syntax"))
+
+				By("reconciling a remediation with a valid value")
+				setTailoredValue("5")
+				rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, &corev1.ConfigMap{})
+				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
@@ -306,6 +1187,287 @@ var _ = Describe("Testing complianceremediation controller", func() {
 				err = reconciler.Client.Get(context.TODO(), mcKey, foundMC)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("should tell that applying the remediation reboots the nodes", func() {
+				err := reconciler.reconcileRemediationStatus(remediationinstance, logger, nil)
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "testRem"}, rem)
+				Expect(err).To(BeNil())
+				Expect(rem.Status.RebootRequired).To(BeTrue())
+			})
+
+			It("should track the rollout of the remediation to the nodes of the pool", func() {
+				newNode := func(name, currentConfig string) *corev1.Node {
+					return &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   name,
+							Labels: map[string]string{mcfgv1.MachineConfigRoleLabelKey: "myRole"},
+							Annotations: map[string]string{
+								"machineconfiguration.openshift.io/currentConfig": currentConfig,
+								"machineconfiguration.openshift.io/state":         "Done",
+							},
+						},
+					}
+				}
+				updatedNode := newNode("node-1", "rendered-myRole-2")
+				outdatedNode := newNode("node-2", "rendered-myRole-1")
+				for _, node := range []*corev1.Node{updatedNode, outdatedNode} {
+					err := reconciler.Client.Create(context.TODO(), node)
+					Expect(err).NotTo(HaveOccurred())
+				}
+				remediationinstance.Spec.Apply = true
+				// The values of the remediation are all set
+				remediationinstance.Annotations = nil
+
+				By("not counting any node until the pool renders the MachineConfig")
+				reconciler.setRolloutStatus(remediationinstance, logger)
+				Expect(remediationinstance.Status.Rollout).To(Equal(&compv1alpha1.ComplianceRemediationRolloutStatus{
+					Pool:         "my-pool",
+					UpdatedNodes: 0,
+					TotalNodes:   2,
+				}))
+				reconciler.setRemediationStatus(remediationinstance, nil, logger)
+				Expect(remediationinstance.Status.ApplicationState).To(Equal(compv1alpha1.RemediationRollingOut))
+				Expect(remediationinstance.IsApplied()).To(BeTrue())
+
+				By("counting the nodes that run the rendered MachineConfig")
+				mcp.Spec.Configuration.Name = "rendered-myRole-2"
+				mcp.Spec.Configuration.Source = append(mcp.Spec.Configuration.Source, corev1.ObjectReference{
+					APIVersion: "machineconfiguration.openshift.io/v1",
+					Kind:       "MachineConfig",
+					Name:       remediationinstance.GetMcName(),
+				})
+				err := reconciler.Client.Update(context.TODO(), mcp)
+				Expect(err).NotTo(HaveOccurred())
+				reconciler.setRolloutStatus(remediationinstance, logger)
+				Expect(remediationinstance.Status.Rollout.UpdatedNodes).To(BeEquivalentTo(1))
+				reconciler.setRemediationStatus(remediationinstance, nil, logger)
+				Expect(remediationinstance.Status.ApplicationState).To(Equal(compv1alpha1.RemediationRollingOut))
+
+				By("being applied once all the nodes run it")
+				outdatedNode.Annotations["machineconfiguration.openshift.io/currentConfig"] = "rendered-myRole-2"
+				err = reconciler.Client.Update(context.TODO(), outdatedNode)
+				Expect(err).NotTo(HaveOccurred())
+				reconciler.setRolloutStatus(remediationinstance, logger)
+				Expect(remediationinstance.Status.Rollout.UpdatedNodes).To(BeEquivalentTo(2))
+				reconciler.setRemediationStatus(remediationinstance, nil, logger)
+				Expect(remediationinstance.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+			})
+		})
+
+		Context("with MachineConfigs that aren't managed by the operator", func() {
+			remKey := types.NamespacedName{Name: "testRem"}
+
+			newUserMC := func(name string, kernelArguments ...string) *mcfgv1.MachineConfig {
+				return &mcfgv1.MachineConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   name,
+						Labels: map[string]string{mcfgv1.MachineConfigRoleLabelKey: "worker"},
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						KernelArguments: kernelArguments,
+					},
+				}
+			}
+
+			reconcileAndGetRemediation := func() (reconcile.Result, *compv1alpha1.ComplianceRemediation) {
+				res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: remKey})
+				Expect(err).To(BeNil())
+				rem := &compv1alpha1.ComplianceRemediation{}
+				err = reconciler.Client.Get(context.TODO(), remKey, rem)
+				Expect(err).To(BeNil())
+				return res, rem
+			}
+
+			BeforeEach(func() {
+				workerLabels := map[string]string{"node-role.kubernetes.io/worker": ""}
+				scanInstance.Spec.NodeSelector = workerLabels
+				err := reconciler.Client.Update(context.TODO(), scanInstance)
+				Expect(err).NotTo(HaveOccurred())
+				mcp.Spec.NodeSelector.MatchLabels = workerLabels
+				err = reconciler.Client.Update(context.TODO(), mcp)
+				Expect(err).NotTo(HaveOccurred())
+
+				mc := &mcfgv1.MachineConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MachineConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						KernelArguments: []string{"audit=1"},
+					},
+				}
+				unstructuredMC, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mc)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Annotations = nil
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredMC,
+				}
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationNotApplied
+				err = reconciler.Client.Status().Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				// Rendered by the Machine Config Operator, so it doesn't conflict
+				generatedMC := newUserMC("00-worker", "audit=1")
+				generatedMC.Annotations = map[string]string{mcoGeneratedByAnnotation: "4.16.0"}
+				err = reconciler.Client.Create(context.TODO(), generatedMC)
+				Expect(err).NotTo(HaveOccurred())
+				// Applies to other nodes, so it doesn't conflict either
+				masterMC := newUserMC("50-master-audit", "audit=0")
+				masterMC.Labels[mcfgv1.MachineConfigRoleLabelKey] = "master"
+				err = reconciler.Client.Create(context.TODO(), masterMC)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should be applied when no MachineConfig sets the same settings", func() {
+				err := reconciler.Client.Create(context.TODO(), newUserMC("50-worker-slub", "slub_debug=P"))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, rem := reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetMcName()}, &mcfgv1.MachineConfig{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should be flagged as conflicting when a MachineConfig sets the same settings", func() {
+				By("reconciling while a user MachineConfig sets the same kernel argument")
+				userMC := newUserMC("50-worker-audit", "audit=0")
+				err := reconciler.Client.Create(context.TODO(), userMC)
+				Expect(err).NotTo(HaveOccurred())
+
+				res, rem := reconcileAndGetRemediation()
+				Expect(res.Requeue).To(BeTrue())
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationConflicting))
+				Expect(rem.Status.ErrorMessage).To(ContainSubstring("kernel argument audit in MachineConfig 50-worker-audit"))
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetMcName()}, &mcfgv1.MachineConfig{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("reconciling once the user MachineConfig is gone")
+				err = reconciler.Client.Delete(context.TODO(), userMC)
+				Expect(err).NotTo(HaveOccurred())
+				_, rem = reconcileAndGetRemediation()
+				Expect(rem.Status.ApplicationState).To(Equal(compv1alpha1.RemediationApplied))
+				Expect(rem.Status.ErrorMessage).To(BeEmpty())
+			})
+		})
+
+		Context("with batched MachineConfig remediation objects", func() {
+			var otherRemediation *compv1alpha1.ComplianceRemediation
+
+			newMCObject := func(kernelArgument string) *unstructured.Unstructured {
+				mc := &mcfgv1.MachineConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "MachineConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.MachineConfigSpec{
+						KernelArguments: []string{kernelArgument},
+					},
+				}
+				unstructuredMC, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mc)
+				Expect(err).ToNot(HaveOccurred())
+				return &unstructured.Unstructured{Object: unstructuredMC}
+			}
+
+			BeforeEach(func() {
+				// The composite MachineConfig is named after the role of the pool
+				workerLabels := map[string]string{"node-role.kubernetes.io/worker": ""}
+				scanInstance.Spec.NodeSelector = workerLabels
+				err := reconciler.Client.Update(context.TODO(), scanInstance)
+				Expect(err).NotTo(HaveOccurred())
+				mcp.Spec.NodeSelector.MatchLabels = workerLabels
+				err = reconciler.Client.Update(context.TODO(), mcp)
+				Expect(err).NotTo(HaveOccurred())
+
+				remediationinstance.Spec.Batch = true
+				remediationinstance.Spec.Current.Object = newMCObject("audit=1")
+				err = reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				otherRemediation = &compv1alpha1.ComplianceRemediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "otherRem",
+						Labels: testRemLabels,
+					},
+					Spec: compv1alpha1.ComplianceRemediationSpec{
+						ComplianceRemediationSpecMeta: compv1alpha1.ComplianceRemediationSpecMeta{
+							Apply: true,
+							Batch: true,
+							Type:  compv1alpha1.ConfigurationRemediation,
+						},
+						Current: compv1alpha1.ComplianceRemediationPayload{
+							Object: newMCObject("slub_debug=P"),
+						},
+					},
+				}
+				err = reconciler.Client.Create(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+				otherRemediation.Status.ApplicationState = compv1alpha1.RemediationApplied
+				err = reconciler.Client.Status().Update(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should apply the remediations through a single MachineConfig", func() {
+				By("running a reconcile loop")
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the remediations should be applied through the composite MachineConfig")
+				foundMC := &mcfgv1.MachineConfig{}
+				mcKey := types.NamespacedName{Name: getBatchedMachineConfigName("worker")}
+				err = reconciler.Client.Get(context.TODO(), mcKey, foundMC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundMC.Spec.KernelArguments).To(ConsistOf("audit=1", "slub_debug=P"))
+				Expect(foundMC.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationBatchedAnnotation, "otherRem,testRem"))
+				Expect(compv1alpha1.RemediationWasCreatedByOperator(foundMC)).To(BeTrue())
+
+				By("the remediation shouldn't get its own MachineConfig")
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: remediationinstance.GetMcName()}, &mcfgv1.MachineConfig{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+
+				By("un-applying one of the remediations")
+				remediationinstance.Spec.Apply = false
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				err = reconciler.Client.Get(context.TODO(), mcKey, foundMC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundMC.Spec.KernelArguments).To(ConsistOf("slub_debug=P"))
+				Expect(foundMC.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationBatchedAnnotation, "otherRem"))
+			})
+
+			It("should keep the remediations it was built from when their status is stale", func() {
+				By("building the composite MachineConfig from both remediations")
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the status of the other remediation lagging behind, e.g. while it's re-applied")
+				otherRemediation.Status.ApplicationState = compv1alpha1.RemediationPending
+				err = reconciler.Client.Status().Update(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				foundMC := &mcfgv1.MachineConfig{}
+				mcKey := types.NamespacedName{Name: getBatchedMachineConfigName("worker")}
+				err = reconciler.Client.Get(context.TODO(), mcKey, foundMC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundMC.Spec.KernelArguments).To(ConsistOf("audit=1", "slub_debug=P"))
+				Expect(foundMC.Annotations).To(HaveKeyWithValue(compv1alpha1.RemediationBatchedAnnotation, "otherRem,testRem"))
+
+				By("no longer keeping it once it's no longer to be applied")
+				otherRemediation.Spec.Apply = false
+				err = reconciler.Client.Update(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				err = reconciler.Client.Get(context.TODO(), mcKey, foundMC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundMC.Spec.KernelArguments).To(ConsistOf("audit=1"))
+			})
 		})
 
 		Context("with current KubeletConfig remediation object and default no custom kubelet config", func() {
@@ -415,6 +1577,97 @@ var _ = Describe("Testing complianceremediation controller", func() {
 			})
 		})
 
+		Context("with several KubeletConfig remediation objects and default no custom kubelet config", func() {
+			var otherRemediation *compv1alpha1.ComplianceRemediation
+			kcKey := types.NamespacedName{}
+
+			newKCObject := func(config map[string]interface{}) *unstructured.Unstructured {
+				rawConfig, err := json.Marshal(config)
+				Expect(err).ToNot(HaveOccurred())
+				kc := &mcfgv1.KubeletConfig{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "KubeletConfig",
+						APIVersion: mcfgapi.GroupName + "/v1",
+					},
+					Spec: mcfgv1.KubeletConfigSpec{
+						KubeletConfig: &runtime.RawExtension{
+							Raw: rawConfig,
+						},
+					},
+				}
+				unstructuredKC, err := runtime.DefaultUnstructuredConverter.ToUnstructured(kc)
+				Expect(err).ToNot(HaveOccurred())
+				return &unstructured.Unstructured{Object: unstructuredKC}
+			}
+
+			getKubeletConfig := func() map[string]interface{} {
+				foundKC := &unstructured.Unstructured{}
+				foundKC.SetGroupVersionKind(mcfgv1.SchemeGroupVersion.WithKind("KubeletConfig"))
+				err := reconciler.Client.Get(context.TODO(), kcKey, foundKC)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(foundKC.GetAnnotations()).To(HaveKey(compv1alpha1.RemediationBatchedAnnotation))
+				config, _, err := unstructured.NestedMap(foundKC.Object, "spec", "kubeletConfig")
+				Expect(err).ToNot(HaveOccurred())
+				return config
+			}
+
+			BeforeEach(func() {
+				kcKey.Name = utils.GetManagedKubeletConfigName(mcp.GetName())
+				remediationinstance.Spec.Current.Object = newKCObject(map[string]interface{}{"maxPods": 1123})
+				err := reconciler.Client.Update(context.TODO(), remediationinstance)
+				Expect(err).NotTo(HaveOccurred())
+
+				otherRemediation = &compv1alpha1.ComplianceRemediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "otherRem",
+						Labels: testRemLabels,
+					},
+					Spec: compv1alpha1.ComplianceRemediationSpec{
+						ComplianceRemediationSpecMeta: compv1alpha1.ComplianceRemediationSpecMeta{
+							Apply: true,
+							Type:  compv1alpha1.ConfigurationRemediation,
+						},
+						Current: compv1alpha1.ComplianceRemediationPayload{
+							Object: newKCObject(map[string]interface{}{"streamingConnectionIdleTimeout": "5m0s"}),
+						},
+					},
+				}
+				err = reconciler.Client.Create(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+				otherRemediation.Status.ApplicationState = compv1alpha1.RemediationApplied
+				err = reconciler.Client.Status().Update(context.TODO(), otherRemediation)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should merge the remediations into a single KubeletConfig", func() {
+				By("running a reconcile loop")
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the KubeletConfig should contain the settings of both remediations")
+				config := getKubeletConfig()
+				Expect(config).To(HaveKey("maxPods"))
+				Expect(config).To(HaveKey("streamingConnectionIdleTimeout"))
+
+				By("un-applying one of the remediations")
+				remediationinstance.Spec.Apply = false
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				config = getKubeletConfig()
+				Expect(config).ToNot(HaveKey("maxPods"))
+				Expect(config).To(HaveKey("streamingConnectionIdleTimeout"))
+
+				By("un-applying the other remediation")
+				otherRemediation.Spec.Apply = false
+				err = reconciler.reconcileRemediation(otherRemediation, logger)
+				Expect(err).To(BeNil())
+
+				err = reconciler.Client.Get(context.TODO(), kcKey, &mcfgv1.KubeletConfig{})
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
 		Context("with current KubeletConfig remediation object and many custom kubelet configs", func() {
 			BeforeEach(func() {
 				//Setting environment with mutiple custom kubelet config
@@ -829,6 +2082,20 @@ var _ = Describe("Testing complianceremediation controller", func() {
 					By("should return a NotFound error")
 					Expect(kerrors.IsNotFound(err)).To(BeTrue())
 				})
+
+				It("should only preview the remediation in dry-run mode", func() {
+					By("running a dry-run")
+					reconciler.dryRunRemediation(remediationinstance, logger)
+					Expect(remediationinstance.Status.DryRun).ToNot(BeNil())
+					Expect(remediationinstance.Status.DryRun.ValidationError).To(BeEmpty())
+					Expect(remediationinstance.Status.DryRun.RenderedObject).To(ContainSubstring("name: my-cm"))
+					Expect(remediationinstance.Status.DryRun.RenderedObject).To(ContainSubstring(compv1alpha1.RemediationCreatedByOperatorAnnotation))
+
+					By("the remediation should not be applied")
+					foundCM := &corev1.ConfigMap{}
+					err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
+					Expect(kerrors.IsNotFound(err)).To(BeTrue())
+				})
 			})
 		})
 
@@ -881,5 +2148,90 @@ var _ = Describe("Testing complianceremediation controller", func() {
 				})
 			})
 		})
+
+		Context("with an object that existed before the remediation was applied", func() {
+			BeforeEach(func() {
+				cm := &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "ConfigMap",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "my-cm",
+						Namespace: "test-ns",
+					},
+					Data: map[string]string{
+						"key":   "val",
+						"added": "by-remediation",
+					},
+				}
+				unstructuredCM, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+				Expect(err).ToNot(HaveOccurred())
+				remediationinstance.Spec.Current.Object = &unstructured.Unstructured{
+					Object: unstructuredCM,
+				}
+
+				cm.Data = map[string]string{
+					"key":   "original",
+					"other": "untouched",
+				}
+				err = reconciler.Client.Create(context.TODO(), cm)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should restore the prior state of the object", func() {
+				By("applying the remediation")
+				remediationinstance.Spec.Apply = true
+				err := reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+				Expect(remediationinstance.Status.PriorState.Object).ToNot(BeNil())
+
+				foundCM := &corev1.ConfigMap{}
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(foundCM.Data).To(HaveKeyWithValue("key", "val"))
+				Expect(foundCM.Data).To(HaveKeyWithValue("added", "by-remediation"))
+
+				By("un-applying the remediation")
+				remediationinstance.Status.ApplicationState = compv1alpha1.RemediationApplied
+				remediationinstance.Spec.Apply = false
+				err = reconciler.reconcileRemediation(remediationinstance, logger)
+				Expect(err).To(BeNil())
+
+				By("the object should be back to its prior state")
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: "my-cm", Namespace: "test-ns"}, foundCM)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(foundCM.Data).To(Equal(map[string]string{
+					"key":   "original",
+					"other": "untouched",
+				}))
+				Expect(remediationinstance.Status.PriorState.Object).To(BeNil())
+				Expect(remediationinstance.Status.LastRollback).ToNot(BeNil())
+				Expect(remediationinstance.Status.LastRollback.Action).To(Equal(compv1alpha1.RemediationRollbackRestored))
+			})
+
+			It("should list the fields applying the remediation changes", func() {
+				reconciler.diffRemediation(remediationinstance, logger)
+				Expect(remediationinstance.Status.Diff).To(Equal([]compv1alpha1.ComplianceRemediationFieldDiff{
+					{
+						Path:    ".data.added",
+						Desired: `"by-remediation"`,
+					},
+					{
+						Path:    ".data.key",
+						Current: `"original"`,
+						Desired: `"val"`,
+					},
+				}))
+
+				By("not listing any fields once the object is gone")
+				err := reconciler.Client.Delete(context.TODO(), &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "my-cm", Namespace: "test-ns"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				reconciler.diffRemediation(remediationinstance, logger)
+				Expect(remediationinstance.Status.Diff).To(BeEmpty())
+			})
+		})
 	})
 })
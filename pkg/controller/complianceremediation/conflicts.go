@@ -0,0 +1,114 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// The Machine Config Operator annotates the MachineConfigs it renders itself,
+// e.g. the base configuration of a role or the ones of KubeletConfigs
+const mcoGeneratedByAnnotation = "machineconfiguration.openshift.io/generated-by-controller-version"
+
+// Returns what the MachineConfig of the remediation sets that is also set by
+// MachineConfigs of the same role that were created by users rather than by
+// this operator or the Machine Config Operator. The Machine Config Operator
+// would merge both, letting one of them win silently.
+func (r *ReconcileComplianceRemediation) getMachineConfigConflicts(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) ([]string, error) {
+	// Avoid rendering the remediations that can't conflict anyway
+	if !utils.IsMachineConfig(instance.Spec.Current.Object) && !utils.IsMachineConfig(instance.Spec.Outdated.Object) {
+		return nil, nil
+	}
+	obj, err := r.renderRemediationObject(instance, logger)
+	if err != nil && !common.IsRetriable(err) {
+		// Reconciling the remediation surfaces the error
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !utils.IsMachineConfig(obj) {
+		return nil, nil
+	}
+
+	mcList := &unstructured.UnstructuredList{}
+	mcList.SetGroupVersionKind(mcfgv1.SchemeGroupVersion.WithKind("MachineConfigList"))
+	if err := r.Client.List(context.TODO(), mcList); err != nil {
+		return nil, fmt.Errorf("couldn't list the MachineConfigs: %w", err)
+	}
+
+	role := obj.GetLabels()[mcfgv1.MachineConfigRoleLabelKey]
+	settings := getMachineConfigSettings(obj)
+	var conflicts []string
+	for i := range mcList.Items {
+		mc := &mcList.Items[i]
+		if mc.GetName() == obj.GetName() || mc.GetLabels()[mcfgv1.MachineConfigRoleLabelKey] != role ||
+			compv1alpha1.RemediationWasCreatedByOperator(mc) {
+			continue
+		}
+		if _, ok := mc.GetAnnotations()[mcoGeneratedByAnnotation]; ok {
+			continue
+		}
+		for setting := range getMachineConfigSettings(mc) {
+			if settings[setting] {
+				conflicts = append(conflicts, fmt.Sprintf("%s in MachineConfig %s", setting, mc.GetName()))
+			}
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// Returns the files, systemd units and kernel arguments a MachineConfig sets.
+// Kernel arguments are keyed without their value, so that setting the same
+// argument to different values conflicts.
+func getMachineConfigSettings(mc *unstructured.Unstructured) map[string]bool {
+	settings := make(map[string]bool)
+	files, _, _ := unstructured.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+	for _, file := range files {
+		if path, ok := file.(map[string]interface{})["path"].(string); ok {
+			settings["file "+path] = true
+		}
+	}
+	units, _, _ := unstructured.NestedSlice(mc.Object, "spec", "config", "systemd", "units")
+	for _, unit := range units {
+		if name, ok := unit.(map[string]interface{})["name"].(string); ok {
+			settings["unit "+name] = true
+		}
+	}
+	kargs, _, _ := unstructured.NestedStringSlice(mc.Object, "spec", "kernelArguments")
+	for _, karg := range kargs {
+		settings["kernel argument "+strings.SplitN(karg, "=", 2)[0]] = true
+	}
+	return settings
+}
+
+// Keeps the remediation from being applied while it conflicts with
+// MachineConfigs that aren't managed by the operator. Objects of remediations
+// that were applied before the conflict came up are left alone.
+func (r *ReconcileComplianceRemediation) flagConflicts(rem *compv1alpha1.ComplianceRemediation, conflicts []string,
+	logger logr.Logger) (reconcile.Result, error) {
+	logger.Info("Not applying remediation that conflicts with MachineConfigs that aren't managed by the operator", "conflicts", conflicts)
+	message := fmt.Sprintf("The remediation conflicts with MachineConfigs that aren't managed by the operator: %s", strings.Join(conflicts, ", "))
+	if rem.Status.ApplicationState != compv1alpha1.RemediationConflicting || rem.Status.ErrorMessage != message {
+		rCopy := rem.DeepCopy()
+		rCopy.Status.ApplicationState = compv1alpha1.RemediationConflicting
+		rCopy.Status.ErrorMessage = message
+		if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+		r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	}
+	// The conflicting MachineConfigs aren't watched
+	return reconcile.Result{Requeue: true, RequeueAfter: defaultDependencyRequeueTime}, nil
+}
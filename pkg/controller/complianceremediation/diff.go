@@ -0,0 +1,91 @@
+package complianceremediation
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// Values longer than this are cut in the diff so that the status of the
+// remediation stays small, e.g. for the file contents of MachineConfigs
+const maxDiffValueLength = 1024
+
+// Lists the fields of the existing object the remediation targets that
+// applying the remediation would change. Nothing is listed if the object
+// doesn't exist yet.
+func (r *ReconcileComplianceRemediation) diffRemediation(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) {
+	instance.Status.Diff = nil
+
+	obj, err := r.renderRemediationObject(instance, logger)
+	if err != nil {
+		logger.Info("Couldn't render the remediation to diff it", "error", err.Error())
+		return
+	}
+	found := obj.DeepCopy()
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
+	if kerrors.IsNotFound(err) {
+		return
+	} else if err != nil {
+		logger.Info("Couldn't get the object of the remediation to diff it", "error", err.Error())
+		return
+	}
+
+	var diffs []compv1alpha1.ComplianceRemediationFieldDiff
+	for key, desired := range obj.Object {
+		if isObjectMetaField(key) {
+			continue
+		}
+		current, hasCurrent := found.Object[key]
+		diffs = diffField(diffs, "."+key, current, hasCurrent, desired)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	instance.Status.Diff = diffs
+}
+
+// Appends the differences between the current and the desired value of a
+// field. Maps are compared key by key the way a merge patch would apply them,
+// other values are compared as a whole.
+func diffField(diffs []compv1alpha1.ComplianceRemediationFieldDiff, path string, current interface{}, hasCurrent bool,
+	desired interface{}) []compv1alpha1.ComplianceRemediationFieldDiff {
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+	if desiredIsMap && (currentIsMap || !hasCurrent) {
+		for key, nestedDesired := range desiredMap {
+			nestedCurrent, hasNestedCurrent := currentMap[key]
+			diffs = diffField(diffs, path+"."+key, nestedCurrent, hasNestedCurrent, nestedDesired)
+		}
+		return diffs
+	}
+	if hasCurrent && reflect.DeepEqual(current, desired) {
+		return diffs
+	}
+
+	diff := compv1alpha1.ComplianceRemediationFieldDiff{
+		Path:    path,
+		Desired: diffValue(desired),
+	}
+	if hasCurrent {
+		diff.Current = diffValue(current)
+	}
+	return append(diffs, diff)
+}
+
+func diffValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "<invalid value>"
+	}
+	if len(encoded) > maxDiffValueLength {
+		return string(encoded[:maxDiffValueLength]) + "..."
+	}
+	return string(encoded)
+}
@@ -0,0 +1,44 @@
+package complianceremediation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// isManagedKubeletConfig tells whether the KubeletConfig object of a
+// remediation is applied through the KubeletConfig the operator manages
+func isManagedKubeletConfig(obj *unstructured.Unstructured) bool {
+	return strings.HasPrefix(obj.GetName(), utils.ManagedKubeletConfigPrefix)
+}
+
+// Applies or un-applies a KubeletConfig remediation by rebuilding the managed
+// KubeletConfig of its pool from all the KubeletConfig remediations of the
+// pool that are to be applied
+func (r *ReconcileComplianceRemediation) reconcileKubeletConfigRemediation(instance *compv1alpha1.ComplianceRemediation,
+	obj *unstructured.Unstructured, logger logr.Logger) error {
+	objectLogger := logger.WithValues("Object.Name", obj.GetName(), "Object.Kind", obj.GetKind())
+	objectLogger.Info("Reconciling KubeletConfig remediation object")
+
+	if err := r.setRemediations(instance, objectLogger, instance.Spec.Apply); err != nil {
+		return fmt.Errorf("failed to set related remediations: %w", err)
+	}
+
+	merged := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	merged.SetGroupVersionKind(obj.GroupVersionKind())
+	merged.SetName(obj.GetName())
+	merged.SetLabels(obj.GetLabels())
+	members, err := r.getRemediationObjectsToMerge(instance, obj, merged,
+		func(_ *compv1alpha1.ComplianceRemediation, remObj *unstructured.Unstructured) bool {
+			return remObj.GetName() == obj.GetName()
+		}, objectLogger)
+	if err != nil {
+		return err
+	}
+	return r.reconcileMergedObject(merged, members, objectLogger)
+}
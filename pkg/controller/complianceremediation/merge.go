@@ -0,0 +1,169 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// Returns the objects of the remediations in the namespace of the instance
+// that are to be applied and that make up the merged object along with obj,
+// keyed by the name of their remediation
+func (r *ReconcileComplianceRemediation) getRemediationObjectsToMerge(instance *compv1alpha1.ComplianceRemediation,
+	obj, merged *unstructured.Unstructured, matches func(*compv1alpha1.ComplianceRemediation, *unstructured.Unstructured) bool,
+	logger logr.Logger) (map[string]*unstructured.Unstructured, error) {
+	remediations := &compv1alpha1.ComplianceRemediationList{}
+	if err := r.Client.List(context.TODO(), remediations, client.InNamespace(instance.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("couldn't list remediations: %w", err)
+	}
+	mergedFrom, err := r.getMergedRemediationNames(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]*unstructured.Unstructured)
+	if instance.Spec.Apply {
+		members[instance.Name] = obj
+	}
+	kind := obj.GetKind()
+	for i := range remediations.Items {
+		rem := &remediations.Items[i]
+		// The remediation being reconciled might be stale in the list. The
+		// others are only merged once they're applied, so that the ones
+		// that are still waiting, e.g. for their apply window, are left out.
+		// The ones the merged object was built from are kept even if their
+		// status is stale, e.g. while they're being applied.
+		if rem.Name == instance.Name || !rem.Spec.Apply || (!rem.IsApplied() && !mergedFrom[rem.Name]) {
+			continue
+		}
+		// Avoid rendering the remediations that can't be merged anyway
+		if !utils.IsKind(rem.Spec.Current.Object, kind) && !utils.IsKind(rem.Spec.Outdated.Object, kind) {
+			continue
+		}
+		remObj, err := r.renderRemediationObject(rem, logger)
+		if err != nil {
+			logger.Info("Leaving out a remediation that can't be rendered", "ComplianceRemediation.Name", rem.Name, "error", err.Error())
+			continue
+		}
+		if !utils.IsKind(remObj, kind) || !matches(rem, remObj) {
+			continue
+		}
+		members[rem.Name] = remObj
+	}
+	return members, nil
+}
+
+// Returns the names of the remediations that the existing merged object was
+// built from, as listed in its annotation
+func (r *ReconcileComplianceRemediation) getMergedRemediationNames(merged *unstructured.Unstructured) (map[string]bool, error) {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(merged.GroupVersionKind())
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: merged.GetName(), Namespace: merged.GetNamespace()}, found)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, name := range strings.Split(found.GetAnnotations()[compv1alpha1.RemediationBatchedAnnotation], ",") {
+		if name != "" {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// Creates, updates or deletes the object that merges the objects of several
+// remediations, so that it matches the remediations that are to be applied
+func (r *ReconcileComplianceRemediation) reconcileMergedObject(merged *unstructured.Unstructured,
+	members map[string]*unstructured.Unstructured, logger logr.Logger) error {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(merged.GroupVersionKind())
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: merged.GetName(), Namespace: merged.GetNamespace()}, found)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if len(members) == 0 {
+		if !exists || !compv1alpha1.RemediationWasCreatedByOperator(found) {
+			return nil
+		}
+		logger.Info("No remediations left to merge, deleting the merged object")
+		if err := r.Client.Delete(context.TODO(), found); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	mergeRemediationObjects(merged, members)
+	if !exists {
+		logger.Info("Creating the merged object", "remediations", len(members))
+		return r.createRemediation(merged, logger)
+	}
+
+	mergedFrom := merged.GetAnnotations()[compv1alpha1.RemediationBatchedAnnotation]
+	if reflect.DeepEqual(found.Object["spec"], merged.Object["spec"]) &&
+		found.GetAnnotations()[compv1alpha1.RemediationBatchedAnnotation] == mergedFrom {
+		logger.Info("The merged object is up to date")
+		return nil
+	}
+	logger.Info("Updating the merged object", "remediations", len(members))
+	updated := found.DeepCopy()
+	updated.Object["spec"] = merged.Object["spec"]
+	annotations := updated.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[compv1alpha1.RemediationBatchedAnnotation] = mergedFrom
+	updated.SetAnnotations(annotations)
+	updateErr := r.Client.Update(context.TODO(), updated)
+	if kerrors.IsForbidden(updateErr) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to update the merged fix object from ComplianceRemediation. "+
+				"Please update the compliance-operator's permissions: %s", updateErr)
+	}
+	return updateErr
+}
+
+// Sets the spec of the merged object to the merge of the specs of the objects
+// of the remediations, and annotates it with the remediations it was built
+// from. The remediations are merged in the order of their names, and the
+// first one wins for fields that can't be merged, e.g. two files with the
+// same path.
+func mergeRemediationObjects(merged *unstructured.Unstructured, members map[string]*unstructured.Unstructured) {
+	remNames := make([]string, 0, len(members))
+	for remName := range members {
+		remNames = append(remNames, remName)
+	}
+	sort.Strings(remNames)
+
+	spec := map[string]interface{}{}
+	for _, remName := range remNames {
+		remSpec, ok := members[remName].Object["spec"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		utils.MergeRemediationFields(spec, remSpec)
+	}
+	merged.Object["spec"] = spec
+
+	annotations := merged.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[compv1alpha1.RemediationBatchedAnnotation] = strings.Join(remNames, ",")
+	merged.SetAnnotations(annotations)
+}
@@ -0,0 +1,79 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+const (
+	// How many times in a row reconciling the object of a remediation may
+	// fail with a transient error before the remediation is marked as Error
+	maxFailedAttempts = 5
+	// How long to wait before the first retry. The delay is doubled for
+	// every retry after it, up to maxRetryDelay.
+	baseRetryDelay = 10 * time.Second
+	maxRetryDelay  = 5 * time.Minute
+)
+
+// Tells whether the error is likely to go away on its own, e.g. a conflict
+// with another update or a webhook that timed out
+func isTransientError(err error) bool {
+	return kerrors.IsConflict(err) || kerrors.IsServerTimeout(err) || kerrors.IsTimeout(err) ||
+		kerrors.IsTooManyRequests(err) || kerrors.IsInternalError(err) ||
+		kerrors.IsServiceUnavailable(err) || kerrors.IsUnexpectedServerError(err)
+}
+
+// Returns how long to wait before retrying after the given number of failed
+// attempts
+func getRetryDelay(failedAttempts int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < failedAttempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Records the failed attempt to reconcile the object of the remediation and
+// retries it with an exponential backoff. Once maxFailedAttempts attempts
+// failed in a row, the remediation is marked as Error and isn't retried
+// anymore until reconciling it succeeds, e.g. after setting apply to false.
+func (r *ReconcileComplianceRemediation) retryRemediation(rem *compv1alpha1.ComplianceRemediation, reconcileErr error,
+	logger logr.Logger) (reconcile.Result, error) {
+	if rem.Status.ApplicationState == compv1alpha1.RemediationError && len(rem.Status.FailedAttempts) >= maxFailedAttempts {
+		logger.Info("Not retrying the remediation anymore", "error", reconcileErr.Error())
+		return reconcile.Result{}, nil
+	}
+
+	rem.Status.FailedAttempts = append(rem.Status.FailedAttempts, compv1alpha1.ComplianceRemediationAttempt{
+		Time:  metav1.Now(),
+		Error: reconcileErr.Error(),
+	})
+	attempts := len(rem.Status.FailedAttempts)
+	if attempts >= maxFailedAttempts {
+		logger.Info("Giving up on the remediation", "attempts", attempts, "error", reconcileErr.Error())
+		giveUpErr := common.NewNonRetriableCtrlError("failed to reconcile the remediation %d times in a row: %s", attempts, reconcileErr)
+		return reconcile.Result{}, r.reconcileRemediationStatus(rem, logger, giveUpErr)
+	}
+
+	delay := getRetryDelay(attempts)
+	logger.Info("Retrying the remediation after a transient error", "attempts", attempts, "delay", delay, "error", reconcileErr.Error())
+	rCopy := rem.DeepCopy()
+	rCopy.Status.ErrorMessage = fmt.Sprintf("Attempt %d of %d failed, retrying in %s: %s", attempts, maxFailedAttempts, delay, reconcileErr)
+	if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+		return reconcile.Result{}, err
+	}
+	r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}, nil
+}
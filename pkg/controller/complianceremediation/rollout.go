@@ -0,0 +1,100 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	mcfgconst "github.com/openshift/machine-config-operator/pkg/daemon/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// How often the rollout of an applied MachineConfig remediation is checked.
+// The pools and their nodes are polled rather than watched, as the operator
+// might run on clusters without MachineConfigPools.
+const rolloutPollInterval = 30 * time.Second
+
+// Tracks how far the MachineConfig of the remediation is rolled out to the
+// nodes of its pool. Only the MachineConfig remediations that are applied
+// are tracked.
+func (r *ReconcileComplianceRemediation) setRolloutStatus(instance *compv1alpha1.ComplianceRemediation, logger logr.Logger) {
+	if !instance.Spec.Apply || !utils.IsMachineConfig(instance.Spec.Current.Object) {
+		instance.Status.Rollout = nil
+		return
+	}
+
+	rollout, err := r.getRolloutStatus(instance, logger)
+	if err != nil {
+		logger.Info("Couldn't track the rollout of the remediation", "error", err.Error())
+		instance.Status.Rollout = nil
+		return
+	}
+	instance.Status.Rollout = rollout
+}
+
+func (r *ReconcileComplianceRemediation) getRolloutStatus(instance *compv1alpha1.ComplianceRemediation,
+	logger logr.Logger) (*compv1alpha1.ComplianceRemediationRolloutStatus, error) {
+	obj, err := r.renderRemediationObject(instance, logger)
+	if err != nil {
+		return nil, err
+	}
+	mcName := obj.GetName()
+	if instance.Spec.Batch {
+		mcName = getBatchedMachineConfigName(obj.GetLabels()[mcfgv1.MachineConfigRoleLabelKey])
+	}
+
+	scan := &compv1alpha1.ComplianceScan{}
+	scanKey := types.NamespacedName{Name: instance.GetScan(), Namespace: instance.Namespace}
+	if err := r.Client.Get(context.TODO(), scanKey, scan); err != nil {
+		return nil, fmt.Errorf("couldn't get scan for MC remediation: %w", err)
+	}
+	mcfgpools := &mcfgv1.MachineConfigPoolList{}
+	if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
+		return nil, fmt.Errorf("couldn't list the pools for the remediation: %w", err)
+	}
+	ok, pool := utils.AnyMcfgPoolLabelMatches(scan.Spec.NodeSelector, mcfgpools)
+	if !ok {
+		return nil, fmt.Errorf("no MachineConfigPool matches the nodes of scan %s", scan.Name)
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, client.MatchingLabels(pool.Spec.NodeSelector.MatchLabels)); err != nil {
+		return nil, fmt.Errorf("couldn't list the nodes of pool %s: %w", pool.Name, err)
+	}
+	rollout := &compv1alpha1.ComplianceRemediationRolloutStatus{
+		Pool:       pool.Name,
+		TotalNodes: int32(len(nodes.Items)),
+	}
+
+	// None of the nodes run the MachineConfig until the rendered
+	// configuration of the pool contains it
+	if !renderedConfigContains(pool, mcName) {
+		return rollout, nil
+	}
+	for i := range nodes.Items {
+		annotations := nodes.Items[i].GetAnnotations()
+		if annotations[mcfgconst.CurrentMachineConfigAnnotationKey] == pool.Spec.Configuration.Name &&
+			annotations[mcfgconst.MachineConfigDaemonStateAnnotationKey] == mcfgconst.MachineConfigDaemonStateDone {
+			rollout.UpdatedNodes++
+		}
+	}
+	return rollout, nil
+}
+
+// Tells whether the rendered configuration the pool is updating to was
+// generated from the MachineConfig
+func renderedConfigContains(pool *mcfgv1.MachineConfigPool, mcName string) bool {
+	for _, source := range pool.Spec.Configuration.Source {
+		if source.Name == mcName {
+			return true
+		}
+	}
+	return false
+}
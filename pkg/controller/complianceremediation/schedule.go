@@ -0,0 +1,61 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// Returns how long it takes until the remediation can be applied according
+// to its applyAfter time and apply window, or zero if it can be applied
+// right away
+func getScheduledApplyDelay(rem *compv1alpha1.ComplianceRemediation, now time.Time) (time.Duration, error) {
+	applyAt := now
+	if rem.Spec.ApplyAfter != nil && rem.Spec.ApplyAfter.Time.After(now) {
+		applyAt = rem.Spec.ApplyAfter.Time
+	}
+	if rem.Spec.ApplyWindow != nil {
+		if rem.Spec.ApplyWindow.Duration.Duration <= 0 {
+			return 0, common.NewNonRetriableCtrlError("the remediation apply window duration must be positive")
+		}
+		open, wait, err := utils.ApplyWindowOpen(rem.Spec.ApplyWindow, applyAt)
+		if err != nil {
+			return 0, common.NewNonRetriableCtrlError("the remediation apply window schedule is wrongly formatted: %s", err)
+		}
+		if !open {
+			applyAt = applyAt.Add(wait)
+		}
+	}
+	return applyAt.Sub(now), nil
+}
+
+// Keeps the remediation from being applied until it's scheduled to. Objects
+// of remediations that are already applied are left alone in the meantime.
+func (r *ReconcileComplianceRemediation) waitForSchedule(rem *compv1alpha1.ComplianceRemediation, delay time.Duration,
+	now time.Time, logger logr.Logger) (reconcile.Result, error) {
+	if rem.Status.ApplicationState == compv1alpha1.RemediationApplied || rem.Status.ApplicationState == compv1alpha1.RemediationRollingOut {
+		logger.Info("Not updating the applied remediation until it's scheduled to", "delay", delay)
+		return reconcile.Result{Requeue: true, RequeueAfter: delay}, nil
+	}
+
+	logger.Info("The remediation is scheduled to be applied later", "delay", delay)
+	message := fmt.Sprintf("The remediation is scheduled to be applied at %s", now.Add(delay).UTC().Format(time.RFC3339))
+	if rem.Status.ApplicationState != compv1alpha1.RemediationScheduled || rem.Status.ErrorMessage != message {
+		rCopy := rem.DeepCopy()
+		rCopy.Status.ApplicationState = compv1alpha1.RemediationScheduled
+		rCopy.Status.ErrorMessage = message
+		if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+		r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: delay}, nil
+}
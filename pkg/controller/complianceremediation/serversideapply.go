@@ -0,0 +1,138 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	// The prefix of the field managers the objects of the remediations are
+	// server-side applied with
+	remediationFieldManager = "compliance-operator-remediation"
+	// The API server refuses longer field manager names
+	maxFieldManagerLength = 128
+)
+
+// remediationFieldManagerFor returns the field manager the object of the
+// remediation is server-side applied with. Each remediation has its own, since
+// several remediations may set different fields of the same object, and
+// applying or un-applying one of them must not drop the fields of the others.
+func remediationFieldManagerFor(instance *compv1alpha1.ComplianceRemediation) string {
+	// The hashed name is always short enough
+	manager, _ := utils.LengthName(maxFieldManagerLength+1, remediationFieldManager+"-",
+		"%s-%s", remediationFieldManager, instance.Name)
+	return manager
+}
+
+// applyConflictError is returned when server-side applying the object of a
+// remediation conflicts with fields that are managed by someone else
+type applyConflictError struct {
+	err error
+}
+
+func (e *applyConflictError) Error() string {
+	return e.err.Error()
+}
+
+func usesServerSideApply(instance *compv1alpha1.ComplianceRemediation) bool {
+	return instance.Spec.ApplyMethod == compv1alpha1.RemediationApplyServerSide
+}
+
+// Server-side applies the object of the remediation. The ownership labels
+// and, for objects the operator creates, the annotation that marks them as
+// such are part of the applied configuration so that they're kept across
+// applies.
+func (r *ReconcileComplianceRemediation) applyRemediation(instance *compv1alpha1.ComplianceRemediation,
+	remObj *unstructured.Unstructured, createdByOperator bool, logger logr.Logger) error {
+	logger.Info("Remediation will be server-side applied")
+	remObj.SetResourceVersion("")
+	remObj.SetManagedFields(nil)
+	instance.AddOwnershipLabels(remObj)
+	if createdByOperator {
+		compv1alpha1.AddRemediationAnnotation(remObj)
+	}
+
+	applyErr := r.Client.Patch(context.TODO(), remObj, client.Apply, client.FieldOwner(remediationFieldManagerFor(instance)))
+	if kerrors.IsConflict(applyErr) {
+		return &applyConflictError{err: applyErr}
+	} else if kerrors.IsForbidden(applyErr) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to apply fix object from ComplianceRemediation. "+
+				"Please update the compliance-operator's permissions: %s", applyErr)
+	}
+	return applyErr
+}
+
+// Un-applies a server-side applied remediation from an object the operator
+// didn't create by applying an empty configuration with the field manager of
+// the remediation. This removes the fields only the remediation manages and
+// leaves the fields that are managed by others, including the other
+// remediations, alone.
+func (r *ReconcileComplianceRemediation) unapplyRemediation(instance *compv1alpha1.ComplianceRemediation,
+	remObj *unstructured.Unstructured, logger logr.Logger) error {
+	logger.Info("Remediation will be removed from the object")
+	empty := &unstructured.Unstructured{}
+	empty.SetGroupVersionKind(remObj.GroupVersionKind())
+	empty.SetName(remObj.GetName())
+	empty.SetNamespace(remObj.GetNamespace())
+
+	applyErr := r.Client.Patch(context.TODO(), empty, client.Apply, client.FieldOwner(remediationFieldManagerFor(instance)))
+	if kerrors.IsForbidden(applyErr) {
+		return common.NewNonRetriableCtrlError(
+			"Unable to remove fix object from ComplianceRemediation. "+
+				"Please update the compliance-operator's permissions: %s", applyErr)
+	} else if kerrors.IsNotFound(applyErr) {
+		return nil
+	} else if applyErr != nil {
+		return applyErr
+	}
+
+	instance.Status.LastRollback = &compv1alpha1.ComplianceRemediationRollbackStatus{
+		Action: compv1alpha1.RemediationRollbackRestored,
+		Time:   metav1.Now(),
+	}
+	return nil
+}
+
+// Tells whether the field manager of the remediation manages fields of the
+// object, i.e. whether the remediation was server-side applied to it
+func managesFields(instance *compv1alpha1.ComplianceRemediation, obj *unstructured.Unstructured) bool {
+	manager := remediationFieldManagerFor(instance)
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == manager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// Keeps the remediation from being applied while its object conflicts with
+// fields that are managed by someone else
+func (r *ReconcileComplianceRemediation) flagApplyConflict(rem *compv1alpha1.ComplianceRemediation, conflictErr *applyConflictError,
+	logger logr.Logger) (reconcile.Result, error) {
+	logger.Info("Not applying remediation that conflicts with fields managed by someone else", "conflict", conflictErr.Error())
+	message := fmt.Sprintf("The remediation conflicts with fields that are managed by someone else: %s", conflictErr)
+	if rem.Status.ApplicationState != compv1alpha1.RemediationConflicting || rem.Status.ErrorMessage != message {
+		rCopy := rem.DeepCopy()
+		rCopy.Status.ApplicationState = compv1alpha1.RemediationConflicting
+		rCopy.Status.ErrorMessage = message
+		if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+		r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	}
+	// The objects of the remediations aren't watched
+	return reconcile.Result{Requeue: true, RequeueAfter: defaultDependencyRequeueTime}, nil
+}
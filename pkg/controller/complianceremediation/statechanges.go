@@ -0,0 +1,113 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	remediationStateChangedType = "remediationStateChanged"
+	// The sink of the notifications published to Kafka, in the metrics
+	kafkaNotificationSink = "kafka"
+	// How long publishing a state change may take, retries included
+	stateChangePublishingTimeout = time.Minute
+)
+
+// remediationStateChange is the event of a remediation whose application
+// state changed
+type remediationStateChange struct {
+	Type          string    `json:"type"`
+	Time          time.Time `json:"time"`
+	Suite         string    `json:"suite"`
+	Namespace     string    `json:"namespace"`
+	Scan          string    `json:"scan"`
+	Remediation   string    `json:"remediation"`
+	CheckResult   string    `json:"checkResult,omitempty"`
+	PreviousState string    `json:"previousState,omitempty"`
+	State         string    `json:"state"`
+	ErrorMessage  string    `json:"errorMessage,omitempty"`
+}
+
+// publishStateChange publishes the change of the application state of the
+// remediation to the Kafka topic of its suite, if the suite has one. The
+// change is published in the background, and since the status of the
+// remediation is already updated, failures are only reported.
+func (r *ReconcileComplianceRemediation) publishStateChange(previous compv1alpha1.RemediationApplicationState,
+	rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) {
+	if previous == rem.Status.ApplicationState {
+		return
+	}
+	suiteName, ok := rem.Labels[compv1alpha1.SuiteLabel]
+	if !ok {
+		return
+	}
+	suite := &compv1alpha1.ComplianceSuite{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: suiteName, Namespace: rem.Namespace}, suite); err != nil {
+		logger.Info("Cannot get the suite of the remediation, not publishing its state change", "ComplianceSuite.Name", suiteName, "error", err.Error())
+		return
+	}
+	if suite.Spec.ResultForwarding == nil || suite.Spec.ResultForwarding.Kafka == nil {
+		return
+	}
+
+	event := remediationStateChange{
+		Type:          remediationStateChangedType,
+		Time:          time.Now().UTC(),
+		Suite:         suite.Name,
+		Namespace:     rem.Namespace,
+		Scan:          rem.Labels[compv1alpha1.ComplianceScanLabel],
+		Remediation:   rem.Name,
+		CheckResult:   rem.Status.CheckResult,
+		PreviousState: string(previous),
+		State:         string(rem.Status.ApplicationState),
+		ErrorMessage:  rem.Status.ErrorMessage,
+	}
+	message := utils.KafkaMessage{Key: suite.Name, Time: event.Time, Value: event}
+	rem = rem.DeepCopy()
+	r.notifications.Enqueue(common.Notification{
+		Sink: kafkaNotificationSink,
+		Send: func(ctx context.Context) error {
+			return r.publishToKafka(ctx, suite, []utils.KafkaMessage{message})
+		},
+		Done: func(err error) {
+			if err == nil {
+				return
+			}
+			logger.Error(err, "Cannot publish the state change of the remediation", "state", event.State)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(rem, corev1.EventTypeWarning, "EventPublishingFailed",
+					"The state change of the remediation couldn't be published to the Kafka topic: %s", err)
+			}
+		},
+	})
+}
+
+// publishToKafka publishes the messages to the Kafka topic that the Secret of
+// the suite configures
+func (r *ReconcileComplianceRemediation) publishToKafka(ctx context.Context, suite *compv1alpha1.ComplianceSuite, messages []utils.KafkaMessage) error {
+	secretName := suite.Spec.ResultForwarding.Kafka.SecretName
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: suite.Namespace}, secret); err != nil {
+		return fmt.Errorf("cannot get the Secret %s: %w", secretName, err)
+	}
+	config, err := utils.NewKafkaConfigFromSecret(secret)
+	if err != nil {
+		return err
+	}
+	newPublisher := r.newKafkaPublisher
+	if newPublisher == nil {
+		newPublisher = utils.NewKafkaPublisher
+	}
+	ctx, cancel := context.WithTimeout(ctx, stateChangePublishingTimeout)
+	defer cancel()
+	return newPublisher(config).Publish(ctx, messages)
+}
@@ -0,0 +1,151 @@
+package complianceremediation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+// Returns why the values the remediation was rendered with can't be used,
+// or an empty string if all of them pass the type and the constraints of
+// their variables. Only the values that were set through the tailoring of
+// the scan are validated, the defaults of the content are trusted.
+func (r *ReconcileComplianceRemediation) getInvalidValuesReason(rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) (string, error) {
+	scan := &compv1alpha1.ComplianceScan{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetScan(), Namespace: rem.Namespace}, scan); err != nil {
+		return "", fmt.Errorf("couldn't get the scan of the remediation: %w", err)
+	}
+	if scan.Spec.TailoringConfigMap == nil {
+		return "", nil
+	}
+
+	setValues, err := r.getTailoredValues(scan)
+	if err != nil {
+		return "", err
+	}
+	if len(setValues) == 0 {
+		return "", nil
+	}
+
+	pb, prefix, err := r.getScanProfileBundle(scan)
+	if err != nil {
+		return "", err
+	}
+	if pb == nil {
+		logger.Info("Couldn't find the ProfileBundle of the scan, not validating the values of the remediation")
+		return "", nil
+	}
+
+	var reasons []string
+	usedValues := removeEmptyStrings(strings.Split(rem.Annotations[compv1alpha1.RemediationValueUsedAnnotation], ","))
+	for _, name := range usedValues {
+		value, ok := setValues[name]
+		if !ok {
+			continue
+		}
+		variable := &compv1alpha1.Variable{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: prefix + "-" + name, Namespace: rem.Namespace}, variable)
+		if kerrors.IsNotFound(err) {
+			logger.Info("The variable of a value used by the remediation doesn't exist", "Variable.Name", prefix+"-"+name)
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		if err := variable.ValidateValue(value); err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	if len(reasons) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("The values set for the remediation are invalid: %s", strings.Join(reasons, "; ")), nil
+}
+
+// Returns the values set by the tailoring of the scan, keyed by the name of
+// their variable
+func (r *ReconcileComplianceRemediation) getTailoredValues(scan *compv1alpha1.ComplianceScan) (map[string]string, error) {
+	tpcm := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: scan.Spec.TailoringConfigMap.Name, Namespace: scan.Namespace}, tpcm)
+	if err != nil {
+		return nil, err
+	}
+	tpContent, ok := tpcm.Data["tailoring.xml"]
+	if !ok {
+		return nil, fmt.Errorf("the tailoring ConfigMap %s has no tailoring.xml", tpcm.Name)
+	}
+	tpDom, err := xmlquery.Parse(strings.NewReader(tpContent))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the tailoring of the scan: %w", err)
+	}
+
+	values := make(map[string]string)
+	for _, node := range xmlquery.Find(tpDom, "//xccdf-1.2:set-value") {
+		values[xccdf.GetVariableNameFromID(node.SelectAttr("idref"))] = node.InnerText()
+	}
+	return values, nil
+}
+
+// Returns the ProfileBundle the content of the scan comes from, and the
+// prefix of the objects parsed out of the content, preferring the one with
+// the same image when several bundles use the same file
+func (r *ReconcileComplianceRemediation) getScanProfileBundle(scan *compv1alpha1.ComplianceScan) (*compv1alpha1.ProfileBundle, string, error) {
+	pbList := &compv1alpha1.ProfileBundleList{}
+	if err := r.Client.List(context.TODO(), pbList, client.InNamespace(scan.Namespace)); err != nil {
+		return nil, "", fmt.Errorf("couldn't list the profile bundles: %w", err)
+	}
+	var found *compv1alpha1.ProfileBundle
+	var foundPrefix string
+	for i := range pbList.Items {
+		pb := &pbList.Items[i]
+		prefix, ok := getContentFilePrefix(pb, scan.Spec.Content)
+		if !ok {
+			continue
+		}
+		if pb.Spec.ContentImage == scan.Spec.ContentImage {
+			return pb, prefix, nil
+		}
+		if found == nil {
+			found, foundPrefix = pb, prefix
+		}
+	}
+	return found, foundPrefix, nil
+}
+
+// Returns the prefix of the objects parsed out of the data stream file of
+// the bundle, if the bundle has the file
+func getContentFilePrefix(pb *compv1alpha1.ProfileBundle, contentFile string) (string, bool) {
+	for _, file := range pb.GetContentFiles() {
+		if file.File == contentFile {
+			return file.Prefix, true
+		}
+	}
+	return "", false
+}
+
+// Keeps the remediation from being applied with values that don't pass the
+// validation of their variables
+func (r *ReconcileComplianceRemediation) flagInvalidValues(rem *compv1alpha1.ComplianceRemediation, reason string, logger logr.Logger) error {
+	logger.Info("Not applying remediation with invalid values", "reason", reason)
+	if rem.Status.ApplicationState == compv1alpha1.RemediationNeedsReview && rem.Status.ErrorMessage == reason {
+		return nil
+	}
+	rCopy := rem.DeepCopy()
+	rCopy.Status.ApplicationState = compv1alpha1.RemediationNeedsReview
+	rCopy.Status.ErrorMessage = reason
+	if err := r.Client.Status().Update(context.TODO(), rCopy); err != nil {
+		return err
+	}
+	r.Metrics.IncComplianceRemediationStatus(rCopy.Name, rCopy.Status)
+	r.publishStateChange(rem.Status.ApplicationState, rCopy, logger)
+	return nil
+}
@@ -2,6 +2,7 @@ package compliancescan
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"path"
 
@@ -42,10 +43,12 @@ func (r *ReconcileComplianceScan) newAggregatorPod(scanInstance *compv1alpha1.Co
 			},
 		},
 		Spec: corev1.PodSpec{
-			NodeSelector:       r.schedulingInfo.Selector,
-			Tolerations:        r.schedulingInfo.Tolerations,
-			ServiceAccountName: aggregatorSA,
-			PriorityClassName:  scanInstance.Spec.PriorityClass,
+			NodeSelector:              scanInstance.Spec.AggregatorScheduling.GetNodeSelector(r.schedulingInfo.Selector),
+			Tolerations:               scanInstance.Spec.AggregatorScheduling.GetTolerations(r.schedulingInfo.Tolerations),
+			Affinity:                  scanInstance.Spec.AggregatorScheduling.GetAffinity(),
+			TopologySpreadConstraints: scanInstance.Spec.AggregatorScheduling.GetTopologySpreadConstraints(),
+			ServiceAccountName:        aggregatorSA,
+			PriorityClassName:         scanInstance.Spec.PriorityClass,
 			SecurityContext: &corev1.PodSecurityContext{
 				RunAsNonRoot: &trueP,
 			},
@@ -140,5 +143,11 @@ func (r *ReconcileComplianceScan) deleteAggregator(instance *compv1alpha1.Compli
 func isAggregatorRunning(r *ReconcileComplianceScan, scanInstance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
 	logger.Info("Checking aggregator pod for scan", "ComplianceScan.Name", scanInstance.Name)
 	podName := getAggregatorPodName(scanInstance.Name)
-	return isPodRunning(r, podName, common.GetComplianceOperatorNamespace(), podTimeoutDisable, logger)
+	running, err := isPodRunning(r, podName, common.GetComplianceOperatorNamespace(), podTimeoutDisable, logger)
+	// Scan pod retries don't apply to the aggregator, just keep waiting for it
+	var transientErr *podTransientFailureError
+	if goerrors.As(err, &transientErr) {
+		return running, nil
+	}
+	return running, err
 }
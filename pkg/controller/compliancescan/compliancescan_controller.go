@@ -2,6 +2,7 @@ package compliancescan
 
 import (
 	"context"
+	"encoding/json"
 	goerrors "errors"
 	"fmt"
 	"io/ioutil"
@@ -14,6 +15,8 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/go-logr/logr"
+	"github.com/openshift/library-go/pkg/image/reference"
+	cron "github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -52,6 +55,10 @@ const (
 	OpenSCAPScanContainerName = "scanner"
 	// The default time we should wait before requeuing
 	requeueAfterDefault = 10 * time.Second
+	// The default time to wait between re-creations of failed scan pods
+	defaultScanPodRetryBackoff = 30 * time.Second
+	// The maximum time to wait between re-creations of failed scan pods
+	maxScanPodRetryBackoff = 10 * time.Minute
 )
 
 func (r *ReconcileComplianceScan) SetupWithManager(mgr ctrl.Manager) error {
@@ -213,7 +220,7 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 		instanceCopy.Status.Phase = compv1alpha1.PhasePending
 		instanceCopy.Status.StartTimestamp = &metav1.Time{Time: time.Now()}
 		instanceCopy.Status.SetConditionPending()
-		updateErr := r.Client.Status().Update(context.TODO(), instanceCopy)
+		updateErr := r.updateStatus(instanceCopy)
 		if updateErr != nil {
 			return false, updateErr
 		}
@@ -239,7 +246,7 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
 		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
 		instanceCopy.Status.SetConditionInvalid()
-		updateErr := r.Client.Status().Update(context.TODO(), instanceCopy)
+		updateErr := r.updateStatus(instanceCopy)
 		if updateErr != nil {
 			return false, updateErr
 		}
@@ -270,7 +277,76 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
 		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
 		instanceCopy.Status.SetConditionInvalid()
-		err := r.Client.Status().Update(context.TODO(), instanceCopy)
+		err := r.updateStatus(instanceCopy)
+		if err != nil {
+			return false, err
+		}
+		r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+		return false, nil
+	}
+
+	// validate the retry backoff
+	if _, err := getScanPodRetryBackoff(instance); err != nil {
+		instanceCopy := instance.DeepCopy()
+		instanceCopy.Status.ErrorMessage = fmt.Sprintf("Error parsing RetryBackoff: %s", err)
+		instanceCopy.Status.Result = compv1alpha1.ResultError
+		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+		instanceCopy.Status.SetConditionInvalid()
+		err := r.updateStatus(instanceCopy)
+		if err != nil {
+			return false, err
+		}
+		r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+		return false, nil
+	}
+
+	// validate the scan window
+	if err := validateScanWindow(instance.Spec.ScanWindow); err != nil {
+		instanceCopy := instance.DeepCopy()
+		instanceCopy.Status.ErrorMessage = fmt.Sprintf("Invalid ScanWindow: %s", err)
+		instanceCopy.Status.Result = compv1alpha1.ResultError
+		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+		instanceCopy.Status.QueuedUntil = nil
+		instanceCopy.Status.SetConditionInvalid()
+		err := r.updateStatus(instanceCopy)
+		if err != nil {
+			return false, err
+		}
+		r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+		return false, nil
+	}
+
+	if instance.Spec.ScannerImage != "" {
+		_, err := reference.Parse(instance.Spec.ScannerImage)
+		if err == nil && !utils.ScannerImageAllowed(instance.Spec.ScannerImage) {
+			err = fmt.Errorf("the image %s isn't allowed by the operator, see %s", instance.Spec.ScannerImage, utils.AllowedScannerImagesEnv)
+		}
+		if err != nil {
+			instanceCopy := instance.DeepCopy()
+			instanceCopy.Status.ErrorMessage = fmt.Sprintf("Invalid ScannerImage: %s", err)
+			instanceCopy.Status.Result = compv1alpha1.ResultError
+			instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+			instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+			instanceCopy.Status.SetConditionInvalid()
+			err := r.updateStatus(instanceCopy)
+			if err != nil {
+				return false, err
+			}
+			r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
+			return false, nil
+		}
+	}
+
+	if err := validateScannerArgs(instance.Spec.ScannerArgs); err != nil {
+		instanceCopy := instance.DeepCopy()
+		instanceCopy.Status.ErrorMessage = fmt.Sprintf("Invalid ScannerArgs: %s", err)
+		instanceCopy.Status.Result = compv1alpha1.ResultError
+		instanceCopy.Status.Phase = compv1alpha1.PhaseDone
+		instanceCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
+		instanceCopy.Status.SetConditionInvalid()
+		err := r.updateStatus(instanceCopy)
 		if err != nil {
 			return false, err
 		}
@@ -284,9 +360,11 @@ func (r *ReconcileComplianceScan) validate(instance *compv1alpha1.ComplianceScan
 func (r *ReconcileComplianceScan) phasePendingHandler(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (reconcile.Result, error) {
 	logger.Info("Phase: Pending")
 	// Remove annotation if needed
-	if instance.NeedsRescan() {
+	if instance.NeedsRescan() || instance.NeedsNodeRescan() || instance.RawResultsWerePruned() {
 		instanceCopy := instance.DeepCopy()
 		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanAnnotation)
+		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanNodeAnnotation)
+		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRawResultsPrunedAnnotation)
 		delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanTimeoutAnnotation)
 		err := r.Client.Update(context.TODO(), instanceCopy)
 		return reconcile.Result{}, err
@@ -300,12 +378,35 @@ func (r *ReconcileComplianceScan) phasePendingHandler(instance *compv1alpha1.Com
 		return reconcile.Result{}, err
 	}
 
+	// Runs that are requested outside of the scan window, however they
+	// were requested, wait for it to open
+	now := time.Now()
+	if open, wait := scanWindowOpen(instance, now); !open {
+		queuedUntil := metav1.NewTime(now.Add(wait).Truncate(time.Second))
+		if instance.Status.QueuedUntil == nil || !instance.Status.QueuedUntil.Equal(&queuedUntil) {
+			logger.Info("Queueing the scan until its scan window opens", "QueuedUntil", queuedUntil)
+			instance.Status.QueuedUntil = &queuedUntil
+			instance.Status.SetConditionQueued()
+			if err := r.updateStatus(instance); err != nil {
+				logger.Error(err, "Cannot update the status")
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{RequeueAfter: wait}, nil
+	}
+
 	// Update the scan instance, the next phase is running
 	instance.Status.Phase = compv1alpha1.PhaseLaunching
+	instance.Status.QueuedUntil = nil
 	instance.Status.Result = compv1alpha1.ResultNotAvailable
 	instance.Status.StartTimestamp = &metav1.Time{Time: time.Now()}
 	instance.Status.EndTimestamp = nil
-	err := r.Client.Status().Update(context.TODO(), instance)
+	instance.Status.ScanPodRetries = 0
+	instance.Status.LastScanPodRetryTimestamp = nil
+	instance.Status.Progress = nil
+	instance.Status.CompletedNodes = nil
+	instance.Status.ErroredNodes = nil
+	err := r.updateStatus(instance)
 	if err != nil {
 		logger.Error(err, "Cannot update the status")
 		return reconcile.Result{}, err
@@ -372,23 +473,30 @@ func (r *ReconcileComplianceScan) phaseLaunchingHandler(h scanTypeHandler, logge
 			scanCopy.Status.Phase = compv1alpha1.PhaseDone
 			scanCopy.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
 			scanCopy.Status.SetConditionInvalid()
-			if updateerr := r.Client.Status().Update(context.TODO(), scanCopy); updateerr != nil {
+			if updateerr := r.updateStatus(scanCopy); updateerr != nil {
 				logger.Error(updateerr, "Failed to update a scan")
 				return reconcile.Result{}, updateerr
 			}
 			r.Metrics.IncComplianceScanStatus(scanCopy.Name, scanCopy.Status)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(scanCopy, corev1.EventTypeWarning, "ScanError",
+					"The scan pods couldn't be launched: %s", scanCopy.Status.ErrorMessage)
+			}
 		}
 		return common.ReturnWithRetriableError(logger, err)
 	}
 	// if we got here, there are no new pods to be created, move to the next phase
 	scan.Status.Phase = compv1alpha1.PhaseRunning
 	scan.Status.SetConditionsProcessing()
-	err = r.Client.Status().Update(context.TODO(), scan)
+	err = r.updateStatus(scan)
 	if err != nil {
 		// metric status update error
 		return reconcile.Result{}, err
 	}
 	r.Metrics.IncComplianceScanStatus(scan.Name, scan.Status)
+	if r.Recorder != nil {
+		r.Recorder.Event(scan, corev1.EventTypeNormal, "Launched", "The scan pods were launched")
+	}
 	return reconcile.Result{}, nil
 }
 
@@ -452,9 +560,29 @@ func (r *ReconcileComplianceScan) getNodesForScan(instance *compv1alpha1.Complia
 	if err := r.Client.List(context.TODO(), &nodes, &listOpts); err != nil {
 		return nodes, err
 	}
+	nodes.Items = filterNodesByName(nodes.Items, instance.Spec.NodeNames)
 	return nodes, nil
 }
 
+// filterNodesByName returns the nodes whose name is in names. If names is
+// empty, all the nodes are returned.
+func filterNodesByName(nodes []corev1.Node, names []string) []corev1.Node {
+	if len(names) == 0 {
+		return nodes
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	filtered := []corev1.Node{}
+	for _, node := range nodes {
+		if wanted[node.Name] {
+			filtered = append(filtered, node)
+		}
+	}
+	return filtered
+}
+
 func (r *ReconcileComplianceScan) handleRuntimeKubeletConfig(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
 	// only handle node scans
 	if instance.Spec.ScanType != compv1alpha1.ScanTypeNode {
@@ -517,7 +645,7 @@ func (r *ReconcileComplianceScan) phaseRunningHandler(h scanTypeHandler, logger
 	scan := h.getScan()
 	// if we got here, there are no pods running, move to the Aggregating phase
 	scan.Status.Phase = compv1alpha1.PhaseAggregating
-	err = r.Client.Status().Update(context.TODO(), scan)
+	err = r.updateStatus(scan)
 	if err != nil {
 		// metric status update error
 		return reconcile.Result{}, err
@@ -540,7 +668,7 @@ func (r *ReconcileComplianceScan) updateScanStatusOnTimeout(scan *compv1alpha1.C
 		logger.Info("Retrying scan", "compliancescan", scan.ObjectMeta.Name, "node", strings.Join(timeoutNodes, ","))
 		r.Recorder.Eventf(scan, corev1.EventTypeWarning, "Retrying", "Retrying scan %s due to timeout on %s", scan.ObjectMeta.Name, strings.Join(timeoutNodes, ","))
 		scan.Status.RemainingRetries = remRetries - 1
-		err = r.Client.Status().Update(context.TODO(), scan)
+		err = r.updateStatus(scan)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
@@ -548,7 +676,7 @@ func (r *ReconcileComplianceScan) updateScanStatusOnTimeout(scan *compv1alpha1.C
 		logger.Info("No retries left, marking scan as failed", "compliancescan", scan.ObjectMeta.Name, "node", strings.Join(timeoutNodes, ","))
 		scan.Status.RemainingRetries = scan.Spec.MaxRetryOnTimeout
 		r.generateResultEventForScan(scan, logger)
-		err = r.Client.Status().Update(context.TODO(), scan)
+		err = r.updateStatus(scan)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
@@ -571,6 +699,102 @@ func (r *ReconcileComplianceScan) setAnnotationOnTimeout(scan *compv1alpha1.Comp
 	return reconcile.Result{}, nil
 }
 
+// retryScanPod deletes a scan pod that hit a transient failure so that it gets
+// launched again once the scan goes back to the LAUNCHING phase. The amount of
+// retries is bounded by the scan's maxRetries and consecutive retries are spaced
+// out with an exponential backoff. Once there are no retries left, the failure
+// is stored in the result ConfigMap of the pod, so that the scan errors out
+// with it once it's aggregated. Returns whether the pod is being retried, i.e.
+// whether the scan still waits for it.
+func (r *ReconcileComplianceScan) retryScanPod(scan *compv1alpha1.ComplianceScan, podErr *podTransientFailureError,
+	cmName, nodeName string, logger logr.Logger) (bool, error) {
+	if scan.Status.ScanPodRetries >= scan.Spec.MaxRetries {
+		logger.Info("No retries left for failed scan pod", "Pod.Name", podErr.pod, "Reason", podErr.msg)
+		cm := utils.GetResultConfigMap(scan, cmName, "error-msg", nodeName,
+			strings.NewReader(podErr.Error()), false, common.PodFailedExitCode, "")
+		if err := r.Client.Create(context.TODO(), cm); err != nil && !errors.IsAlreadyExists(err) {
+			return true, err
+		}
+		return false, nil
+	}
+
+	backoff, err := getScanPodRetryBackoff(scan)
+	if err != nil {
+		return false, err
+	}
+	// Double the wait time for every retry that already happened
+	for i := 1; i < scan.Status.ScanPodRetries && backoff < maxScanPodRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxScanPodRetryBackoff {
+		backoff = maxScanPodRetryBackoff
+	}
+	if scan.Status.LastScanPodRetryTimestamp != nil && time.Since(scan.Status.LastScanPodRetryTimestamp.Time) < backoff {
+		logger.Info("Waiting before retrying failed scan pod", "Pod.Name", podErr.pod, "Backoff", backoff.String())
+		return true, nil
+	}
+
+	logger.Info("Deleting failed scan pod so it's re-created", "Pod.Name", podErr.pod, "Reason", podErr.msg)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podErr.pod,
+			Namespace: common.GetComplianceOperatorNamespace(),
+		},
+	}
+	if err := r.Client.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+
+	scan.Status.ScanPodRetries++
+	scan.Status.LastScanPodRetryTimestamp = &metav1.Time{Time: time.Now()}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(scan, corev1.EventTypeWarning, "ScanPodRetry",
+			"Re-creating scan pod %s (retry %d of %d): %s", podErr.pod, scan.Status.ScanPodRetries, scan.Spec.MaxRetries, podErr.msg)
+	}
+	if err := r.updateStatus(scan); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// validateScanWindow makes sure the window can be evaluated, as a window that
+// never opens would queue up the scan forever
+func validateScanWindow(window *compv1alpha1.ScanWindow) error {
+	if window == nil {
+		return nil
+	}
+	if _, err := cron.ParseStandard(window.Schedule); err != nil {
+		return fmt.Errorf("the schedule is wrongly formatted: %w", err)
+	}
+	if window.Duration.Duration <= 0 {
+		return fmt.Errorf("the duration must be positive")
+	}
+	return nil
+}
+
+// scanWindowOpen tells whether the scan is allowed to run at the given time.
+// If not, it also returns how long it takes until its window opens.
+func scanWindowOpen(scan *compv1alpha1.ComplianceScan, now time.Time) (bool, time.Duration) {
+	if scan.Spec.ScanWindow == nil {
+		return true, 0
+	}
+	open, wait, err := utils.ScanWindowOpen(scan.Spec.ScanWindow, now)
+	if err != nil {
+		// The scan is validated earlier, so this isn't expected
+		return true, 0
+	}
+	return open, wait
+}
+
+// getScanPodRetryBackoff returns the initial amount of time to wait between
+// re-creations of failed scan pods
+func getScanPodRetryBackoff(scan *compv1alpha1.ComplianceScan) (time.Duration, error) {
+	if scan.Spec.RetryBackoff == "" {
+		return defaultScanPodRetryBackoff, nil
+	}
+	return time.ParseDuration(scan.Spec.RetryBackoff)
+}
+
 func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, logger logr.Logger) (reconcile.Result, error) {
 	logger.Info("Phase: Aggregating")
 	instance := h.getScan()
@@ -605,7 +829,9 @@ func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, log
 	aggregator := r.newAggregatorPod(instance, logger)
 	if priorityClassExist, why := utils.ValidatePriorityClassExist(aggregator.Spec.PriorityClassName, r.Client); !priorityClassExist {
 		log.Info(why, "aggregator", aggregator.Name)
-		r.Recorder.Eventf(aggregator, corev1.EventTypeWarning, "PriorityClass", why+" aggregator:"+aggregator.Name)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(aggregator, corev1.EventTypeWarning, "PriorityClass", why+" aggregator:"+aggregator.Name)
+		}
 		aggregator.Spec.PriorityClassName = ""
 	}
 	err = r.launchAggregatorPod(instance, aggregator, logger)
@@ -625,7 +851,7 @@ func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, log
 	if running {
 		logger.Info("Remaining in the aggregating phase")
 		instance.Status.Phase = compv1alpha1.PhaseAggregating
-		err = r.Client.Status().Update(context.TODO(), instance)
+		err = r.updateStatus(instance)
 		if err != nil {
 			logger.Error(err, "Cannot update the status, requeueing")
 			return reconcile.Result{Requeue: true, RequeueAfter: requeueAfterDefault}, nil
@@ -644,11 +870,29 @@ func (r *ReconcileComplianceScan) phaseAggregatingHandler(h scanTypeHandler, log
 		return reconcile.Result{Requeue: true, RequeueAfter: requeueAfterDefault}, nil
 	}
 
+	if r.Recorder != nil {
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "AggregationDone", "The results of the scan were aggregated")
+	}
+
 	instance.Status.Result = result
 	if err != nil {
 		instance.Status.ErrorMessage = err.Error()
 	}
 
+	diff, diffErr := getResultDiff(r, instance)
+	if diffErr != nil {
+		// The diff is informational only, so don't hold the scan back
+		logger.Error(diffErr, "Cannot get the diff of the results against the previous run")
+	}
+	instance.Status.ResultDiff = diff
+
+	counts, countsErr := getResultCounts(r, instance)
+	if countsErr != nil {
+		// Just like the diff, the counts are informational only
+		logger.Error(countsErr, "Cannot get the amounts of checks by result")
+	}
+	instance.Status.ResultCounts = counts
+
 	instance.Status.Phase = compv1alpha1.PhaseDone
 	instance.Status.EndTimestamp = &metav1.Time{Time: time.Now()}
 	instance.Status.SetConditionReady()
@@ -665,10 +909,53 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 	var err error
 	logger.Info("Phase: Done")
 
+	// A full rescan takes precedence over rescanning a single node
+	var rescanNode string
+	var rescanAllNodes bool
+	if instance.NeedsNodeRescan() && !instance.NeedsRescan() && !doDelete {
+		var valid bool
+		rescanNode = instance.Annotations[compv1alpha1.ComplianceScanRescanNodeAnnotation]
+		if valid, err = r.isValidRescanNode(instance, rescanNode); err != nil {
+			return reconcile.Result{}, err
+		} else if !valid {
+			warning := fmt.Sprintf("Not rescanning node %q: The node is not scanned by the scan", rescanNode)
+			logger.Info(warning)
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "InvalidRescanNode", warning)
+			instanceCopy := instance.DeepCopy()
+			delete(instanceCopy.Annotations, compv1alpha1.ComplianceScanRescanNodeAnnotation)
+			err = r.Client.Update(context.TODO(), instanceCopy)
+			return reconcile.Result{}, err
+		}
+		// The results of the node are aggregated together with the
+		// raw results of the rest of the nodes, which might have been
+		// pruned according to the retention policy of the suite, or
+		// be missing for nodes that weren't scanned by the last run
+		var warning string
+		if instance.RawResultsWerePruned() {
+			warning = fmt.Sprintf("Rescanning all the nodes instead of node %q: The raw results of the scan were pruned", rescanNode)
+		} else {
+			var missingNode string
+			if missingNode, err = r.getNodeWithoutResults(instance, rescanNode); err != nil {
+				return reconcile.Result{}, err
+			} else if missingNode != "" {
+				warning = fmt.Sprintf("Rescanning all the nodes instead of node %q: There are no raw results for node %q", rescanNode, missingNode)
+			}
+		}
+		if warning != "" {
+			logger.Info(warning)
+			if r.Recorder != nil {
+				r.Recorder.Event(instance, corev1.EventTypeWarning, "RawResultsMissing", warning)
+			}
+			rescanNode = ""
+			rescanAllNodes = true
+		}
+	}
+	needsRescan := instance.NeedsRescan() || rescanNode != "" || rescanAllNodes
+
 	// the scan pods and the aggregator are done at this point and can be cleaned up
 	// unless we are running in debug mode and thus requested them to stay
 	// around for later inspection
-	if doDelete == true || instance.Spec.Debug == false || instance.NeedsRescan() {
+	if doDelete == true || instance.Spec.Debug == false || needsRescan {
 		// Don't try to clean up scan-type specific resources
 		// if it was an unknown scan type
 		if h != nil {
@@ -686,7 +973,7 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 	}
 
 	// We need to remove resources before doing a re-scan
-	if doDelete || instance.NeedsRescan() {
+	if doDelete || needsRescan {
 		logger.Info("Cleaning up scan's resources")
 		if err := r.deleteResultServer(instance, logger); err != nil {
 			logger.Error(err, "Cannot delete result server")
@@ -718,24 +1005,45 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 			return reconcile.Result{}, err
 		}
 
-		if instance.NeedsRescan() {
-			if err = r.deleteResultConfigMaps(instance, logger); err != nil {
+		if needsRescan {
+			// When rescanning a single node, the results of the rest
+			// of the nodes are kept and aggregated together with the
+			// new results of the node
+			if rescanNode != "" {
+				err = r.deleteNodeResultConfigMap(instance, rescanNode, logger)
+				if err == nil {
+					err = r.reprocessResultConfigMaps(instance, logger)
+				}
+			} else {
+				err = r.deleteResultConfigMaps(instance, logger)
+			}
+			if err != nil {
 				logger.Error(err, "Cannot delete result ConfigMaps")
 				return reconcile.Result{}, err
 			}
 
+			// Once the raw results were pruned, the next run evaluates
+			// all the rules instead of those whose inputs changed
+			if instance.RawResultsWerePruned() {
+				if err = r.deleteInputHashesConfigMap(instance, logger); err != nil {
+					logger.Error(err, "Cannot delete the input hashes ConfigMap")
+					return reconcile.Result{}, err
+				}
+			}
+
 			// reset phase
-			logger.Info("Resetting scan")
+			logger.Info("Resetting scan", "rescanNode", rescanNode)
 			instanceCopy := instance.DeepCopy()
 			instanceCopy.Status.Phase = compv1alpha1.PhasePending
 			instanceCopy.Status.Result = compv1alpha1.ResultNotAvailable
 			instanceCopy.Status.StartTimestamp = &metav1.Time{Time: time.Now()}
+			instanceCopy.Status.RescanNode = rescanNode
 			if instance.Status.CurrentIndex == math.MaxInt64 {
 				instanceCopy.Status.CurrentIndex = 0
 			} else {
 				instanceCopy.Status.CurrentIndex = instance.Status.CurrentIndex + 1
 			}
-			err = r.Client.Status().Update(context.TODO(), instanceCopy)
+			err = r.updateStatus(instanceCopy)
 			if err != nil {
 				// metric status update error
 				return reconcile.Result{}, err
@@ -743,10 +1051,11 @@ func (r *ReconcileComplianceScan) phaseDoneHandler(h scanTypeHandler, instance *
 			r.Metrics.IncComplianceScanStatus(instanceCopy.Name, instanceCopy.Status)
 			return reconcile.Result{}, nil
 		}
-	} else {
+	} else if !instance.Spec.RawResultStorage.ServeRawResults {
 		// If we're done with the scan but we're not cleaning up just yet.
 
-		// scale down resultserver so it's not still listening for requests.
+		// scale down resultserver so it's not still listening for requests,
+		// unless it keeps serving the raw results.
 		if err := r.scaleDownResultServer(instance, logger); err != nil {
 			logger.Error(err, "Cannot scale down result server")
 			return reconcile.Result{}, err
@@ -800,7 +1109,7 @@ func (r *ReconcileComplianceScan) scanDeleteHandler(instance *compv1alpha1.Compl
 }
 
 func (r *ReconcileComplianceScan) updateStatusWithEvent(scan *compv1alpha1.ComplianceScan, logger logr.Logger) error {
-	err := r.Client.Status().Update(context.TODO(), scan)
+	err := r.updateStatus(scan)
 	if err != nil {
 		return err
 	}
@@ -819,7 +1128,11 @@ func (r *ReconcileComplianceScan) generateResultEventForScan(scan *compv1alpha1.
 		"ComplianceScan's result is: %s", scan.Status.Result,
 	)
 
-	if scan.Status.Result == compv1alpha1.ResultNotApplicable {
+	if scan.Status.Result == compv1alpha1.ResultError {
+		r.Recorder.Eventf(
+			scan, corev1.EventTypeWarning, "ScanError",
+			"The scan errored out: %s", scan.Status.ErrorMessage)
+	} else if scan.Status.Result == compv1alpha1.ResultNotApplicable {
 		r.Recorder.Eventf(
 			scan, corev1.EventTypeWarning, "ScanNotApplicable",
 			"The scan result is not applicable, please check if you're using the correct platform or if the nodeSelector matches nodes.")
@@ -865,6 +1178,110 @@ func (r *ReconcileComplianceScan) deleteResultConfigMaps(instance *compv1alpha1.
 	return nil
 }
 
+// getNodeWithoutResults returns a node of the scan, other than the given
+// one, whose raw results from the last run of the scan aren't kept, or an
+// empty string if the raw results of all of them are
+func (r *ReconcileComplianceScan) getNodeWithoutResults(instance *compv1alpha1.ComplianceScan, nodeName string) (string, error) {
+	nodes, err := r.getNodesForScan(instance)
+	if err != nil {
+		return "", err
+	}
+	for _, node := range nodes.Items {
+		if node.Name == nodeName {
+			continue
+		}
+		if _, err := getNodeScanCM(r, instance, node.Name); errors.IsNotFound(err) {
+			return node.Name, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// reprocessResultConfigMaps has the aggregator process the raw results that
+// are kept from the last run of the scan again, so that the results of a
+// rescanned node are checked for consistency with those of the rest of the
+// nodes
+func (r *ReconcileComplianceScan) reprocessResultConfigMaps(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	cms := &corev1.ConfigMapList{}
+	err := r.Client.List(context.TODO(), cms,
+		client.InNamespace(common.GetComplianceOperatorNamespace()),
+		client.MatchingLabels{
+			compv1alpha1.ComplianceScanLabel: instance.Name,
+			compv1alpha1.ResultLabel:         "",
+		})
+	if err != nil {
+		return err
+	}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		if _, ok := cm.Annotations[compv1alpha1.CmRemediationsProcessedAnnotation]; !ok {
+			continue
+		}
+		logger.Info("Reprocessing the raw results", "ConfigMap.Name", cm.Name)
+		cmCopy := cm.DeepCopy()
+		delete(cmCopy.Annotations, compv1alpha1.CmRemediationsProcessedAnnotation)
+		if err := r.Client.Update(context.TODO(), cmCopy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileComplianceScan) deleteNodeResultConfigMap(instance *compv1alpha1.ComplianceScan, nodeName string, logger logr.Logger) error {
+	cmName := getConfigMapForNodeName(instance.Name, nodeName)
+	// The output of the scanner of debug scans goes along with the results
+	for _, name := range []string{cmName, utils.GetScannerOutputConfigMapName(cmName)} {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: common.GetComplianceOperatorNamespace(),
+			},
+		}
+		err := r.Client.Delete(context.Background(), cm)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isValidRescanNode returns whether the node is one of the nodes targeted by
+// a node scan, and thus can be rescanned on its own
+func (r *ReconcileComplianceScan) isValidRescanNode(instance *compv1alpha1.ComplianceScan, nodeName string) (bool, error) {
+	if instance.GetScanType() != compv1alpha1.ScanTypeNode || nodeName == "" {
+		return false, nil
+	}
+	nodes, err := r.getNodesForScan(instance)
+	if err != nil {
+		return false, err
+	}
+	for _, node := range nodes.Items {
+		if node.Name == nodeName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deleteInputHashesConfigMap deletes the hashes of the inputs of the rules
+// from the last run of an incremental scan, so that its next run evaluates
+// all the rules
+func (r *ReconcileComplianceScan) deleteInputHashesConfigMap(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.GetInputHashesConfigMapName(instance.Name),
+			Namespace: common.GetComplianceOperatorNamespace(),
+		},
+	}
+	logger.Info("Deleting the input hashes of the scan", "ConfigMap.Name", cm.Name)
+	if err := r.Client.Delete(context.TODO(), cm); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
 func (r *ReconcileComplianceScan) deleteKubeletConfigConfigMaps(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
 	inNs := client.InNamespace(common.GetComplianceOperatorNamespace())
 	withLabel := client.MatchingLabels{
@@ -892,6 +1309,25 @@ func isPlatformScanPodRunning(r *ReconcileComplianceScan, scanInstance *compv1al
 	return isPodRunning(r, podName, common.GetComplianceOperatorNamespace(), timeout, logger)
 }
 
+// getNodeScanPhase returns the phase of the scan in a node based on the
+// state of its scan pod
+func getNodeScanPhase(pod *corev1.Pod) compv1alpha1.NodeScanPhase {
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		return compv1alpha1.NodeScanPhaseDone
+	case corev1.PodFailed:
+		return compv1alpha1.NodeScanPhaseFailed
+	case corev1.PodRunning:
+		return compv1alpha1.NodeScanPhaseRunning
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Reason == corev1.PodReasonUnschedulable {
+			return compv1alpha1.NodeScanPhaseUnschedulable
+		}
+	}
+	return compv1alpha1.NodeScanPhasePending
+}
+
 func isPodRunning(r *ReconcileComplianceScan, podName, namespace string, timeout time.Duration, logger logr.Logger) (bool, error) {
 	podlogger := logger.WithValues("Pod.Name", podName)
 	foundPod := &corev1.Pod{}
@@ -920,28 +1356,46 @@ func isPodRunning(r *ReconcileComplianceScan, podName, namespace string, timeout
 	if foundPod.Status.Phase == corev1.PodFailed {
 		podlogger.Info("Pod failed. It should be restarted.", "Reason", foundPod.Status.Reason, "Message", foundPod.Status.Message)
 		// We mark this as if the pod is still running, as it should be
-		// restarted by the kubelet due to the restart policy
-		return true, nil
+		// re-created
+		return true, newPodTransientFailureError(foundPod.Name, fmt.Sprintf("%s %s", foundPod.Status.Reason, foundPod.Status.Message))
 	}
 
 	// the pod is still running or being created etc
 	podlogger.Info("Pod still running")
 
 	// if timeout is not set, we don't check for timeout
-	if timeout == podTimeoutDisable {
-		return true, nil
-	}
-
-	podCreatiopnTime := foundPod.CreationTimestamp.Time
-	if time.Since(podCreatiopnTime) > timeout {
+	if timeout != podTimeoutDisable && time.Since(foundPod.CreationTimestamp.Time) > timeout {
 		podlogger.Info("Pod timed out")
 		timeoutErr := common.NewTimeoutError("Timeout reached while waiting for the scan to finish in the pod: %s", podName)
 		return true, timeoutErr
 	}
 
+	if reason := getPodImagePullFailure(foundPod); reason != "" {
+		podlogger.Info("Pod can't pull its images", "Reason", reason)
+		return true, newPodTransientFailureError(foundPod.Name, reason)
+	}
+
 	return true, nil
 }
 
+// getPodImagePullFailure returns the reason why any of the containers of
+// the pod can't pull its image, or an empty string if there's no such issue.
+func getPodImagePullFailure(pod *corev1.Pod) string {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting == nil {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff":
+			return fmt.Sprintf("container %s: %s %s", status.Name, status.State.Waiting.Reason, status.State.Waiting.Message)
+		}
+	}
+	return ""
+}
+
 func getPlatformScanCM(r *ReconcileComplianceScan, instance *compv1alpha1.ComplianceScan) (*corev1.ConfigMap, error) {
 	targetCM := types.NamespacedName{
 		Name:      getConfigMapForNodeName(instance.Name, PlatformScanName),
@@ -964,6 +1418,61 @@ func getNodeScanCM(r *ReconcileComplianceScan, instance *compv1alpha1.Compliance
 	return foundCM, err
 }
 
+// getResultDiff returns the diff of the results of the scan against its
+// previous run as stored by the aggregator, or nil if there was nothing to
+// compare against.
+func getResultDiff(r *ReconcileComplianceScan, instance *compv1alpha1.ComplianceScan) (*compv1alpha1.ScanResultDiff, error) {
+	targetCM := types.NamespacedName{
+		Name:      utils.GetResultDiffConfigMapName(instance.Name),
+		Namespace: common.GetComplianceOperatorNamespace(),
+	}
+
+	foundCM := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), targetCM, foundCM)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	raw := foundCM.Data[utils.ResultDiffKey]
+	if raw == "" {
+		return nil, nil
+	}
+	diff := &compv1alpha1.ScanResultDiff{}
+	if err := json.Unmarshal([]byte(raw), diff); err != nil {
+		return nil, fmt.Errorf("cannot parse the result diff: %w", err)
+	}
+	return diff, nil
+}
+
+// getResultCounts returns the amounts of checks of the scan by their result
+// and severity as stored by the aggregator, or nil if it didn't store any.
+func getResultCounts(r *ReconcileComplianceScan, instance *compv1alpha1.ComplianceScan) (*compv1alpha1.ResultCounts, error) {
+	targetCM := types.NamespacedName{
+		Name:      utils.GetResultCountsConfigMapName(instance.Name),
+		Namespace: common.GetComplianceOperatorNamespace(),
+	}
+
+	foundCM := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), targetCM, foundCM)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	raw := foundCM.Data[utils.ResultCountsKey]
+	if raw == "" {
+		return nil, nil
+	}
+	counts := &compv1alpha1.ResultCounts{}
+	if err := json.Unmarshal([]byte(raw), counts); err != nil {
+		return nil, fmt.Errorf("cannot parse the result counts: %w", err)
+	}
+	return counts, nil
+}
+
 // gatherResults will iterate the nodes in the scan and get the results
 // for the OpenSCAP check. If the results haven't yet been persisted in
 // the relevant ConfigMap, the a requeue will be requested since the
@@ -993,9 +1502,43 @@ func gatherResults(r *ReconcileComplianceScan, h scanTypeHandler) (compv1alpha1.
 				compv1alpha1.ComplianceCheckInconsistentLabel)
 	}
 
+	// The rules that an incremental scan didn't evaluate keep their results
+	// from the previous run, so the result is computed from all of them. If
+	// no rule was evaluated, the kept results are all there is.
+	if instance.Spec.Incremental && result == compv1alpha1.ResultCompliant {
+		var retainedList compv1alpha1.ComplianceCheckResultList
+		retainedListOpts := client.MatchingLabels{
+			compv1alpha1.ComplianceScanLabel: instance.Name,
+		}
+		if err := r.Client.List(context.TODO(), &retainedList, &retainedListOpts); err != nil {
+			isReady = false
+		} else {
+			result = getIncrementalScanResult(retainedList.Items)
+		}
+	}
+
 	return result, isReady, nil
 }
 
+// getIncrementalScanResult returns the result of an incremental scan whose
+// evaluated rules were compliant, given all of its check results. As for a
+// full scan, an errored check makes it errored and a failed check makes it
+// non-compliant, while the checks that need a manual review don't count.
+func getIncrementalScanResult(checks []compv1alpha1.ComplianceCheckResult) compv1alpha1.ComplianceScanStatusResult {
+	result := compv1alpha1.ResultCompliant
+	for _, check := range checks {
+		switch check.Status {
+		case compv1alpha1.CheckResultError:
+			result = compv1alpha1.WorseResult(result, compv1alpha1.ResultError)
+		case compv1alpha1.CheckResultInconsistent:
+			result = compv1alpha1.WorseResult(result, compv1alpha1.ResultInconsistent)
+		case compv1alpha1.CheckResultFail:
+			result = compv1alpha1.WorseResult(result, compv1alpha1.ResultNonCompliant)
+		}
+	}
+	return result
+}
+
 // pod names are limited to 63 chars, inclusive. Try to use a friendly name, if that can't be done,
 // just use a hash. Either way, the node would be present in a label of the pod.
 func getPodForNodeName(scanName, nodeName string) string {
@@ -1016,3 +1559,19 @@ func getInitContainerImage(scanSpec *compv1alpha1.ComplianceScanSpec, logger log
 	logger.Info("Content image", "image", image)
 	return image
 }
+
+// getScannerImage returns the image of the container running OpenSCAP in the
+// scan pods, which might be overridden per scan
+func getScannerImage(scanSpec *compv1alpha1.ComplianceScanSpec) string {
+	if scanSpec.ScannerImage != "" {
+		return scanSpec.ScannerImage
+	}
+	return utils.GetComponentImage(utils.OPENSCAP)
+}
+
+// updateStatus updates the status of the scan, along with the generation of
+// the scan it was updated for
+func (r *ReconcileComplianceScan) updateStatus(scan *compv1alpha1.ComplianceScan) error {
+	scan.Status.SetObservedGeneration(scan.Generation)
+	return r.Client.Status().Update(context.TODO(), scan)
+}
@@ -6,18 +6,24 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -25,6 +31,7 @@ import (
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	restclient "k8s.io/client-go/rest/fake"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -91,7 +98,8 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 		// test instance
 		compliancescaninstance = &compv1alpha1.ComplianceScan{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "test",
+				Name:       "test",
+				Generation: 2,
 			},
 			Spec: compv1alpha1.ComplianceScanSpec{
 				ScanType: compv1alpha1.ScanTypeNode,
@@ -194,6 +202,24 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 				Expect(err).To(BeNil())
 				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhasePending))
 			})
+
+			It("should record the generation of the scan in the status and its conditions", func() {
+				_, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				err = reconciler.Client.Get(context.TODO(), key, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.ObservedGeneration).To(BeEquivalentTo(2))
+				Expect(scan.Status.Conditions).ToNot(BeEmpty())
+				for _, cond := range scan.Status.Conditions {
+					Expect(cond.ObservedGeneration).To(BeEquivalentTo(2), string(cond.Type))
+				}
+			})
 		})
 		Context("With missing RawResultStorage.Size", func() {
 			It("should update the compliancescan instance with the default size", func() {
@@ -233,6 +259,95 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
 			})
 		})
+
+		Context("With invalid ScannerImage", func() {
+			It("report an error and move to phase DONE", func() {
+				compliancescaninstance.Spec.ScannerImage = "quay.io/Invalid Image"
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeFalse())
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				err = reconciler.Client.Get(context.TODO(), key, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
+				Expect(scan.Status.ErrorMessage).To(ContainSubstring("Invalid ScannerImage"))
+			})
+		})
+
+		Context("With a ScannerImage the operator doesn't allow", func() {
+			It("report an error and move to phase DONE", func() {
+				compliancescaninstance.Spec.ScannerImage = "quay.io/test/openscap:patched"
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeFalse())
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				err = reconciler.Client.Get(context.TODO(), key, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
+				Expect(scan.Status.ErrorMessage).To(ContainSubstring("isn't allowed by the operator"))
+			})
+		})
+
+		Context("With a ScannerImage the operator allows", func() {
+			BeforeEach(func() {
+				os.Setenv(utils.AllowedScannerImagesEnv, "registry.example.com/scanner:v1, quay.io/test/openscap")
+			})
+
+			AfterEach(func() {
+				os.Unsetenv(utils.AllowedScannerImagesEnv)
+			})
+
+			It("accepts any tag or digest of an allowed repository", func() {
+				compliancescaninstance.Spec.ScannerImage = "quay.io/test/openscap@sha256:0123456789012345678901234567890123456789012345678901234567890123"
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeTrue())
+				Expect(err).To(BeNil())
+			})
+
+			It("only accepts the allowed tag of a repository", func() {
+				compliancescaninstance.Spec.ScannerImage = "registry.example.com/scanner:v2"
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeFalse())
+				Expect(err).To(BeNil())
+			})
+		})
+
+		Context("With a scan window that never opens", func() {
+			It("report an error and move to phase DONE", func() {
+				compliancescaninstance.Spec.ScanWindow = &compv1alpha1.ScanWindow{Schedule: "0 22 * * 1-5"}
+				compliancescaninstance.Status.Phase = "PENDING"
+				cont, err := reconciler.validate(compliancescaninstance, logger)
+				Expect(cont).To(BeFalse())
+				Expect(err).To(BeNil())
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				err = reconciler.Client.Get(context.TODO(), key, scan)
+				Expect(err).To(BeNil())
+				Expect(scan.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(scan.Status.Result).To(Equal(compv1alpha1.ResultError))
+				Expect(scan.Status.ErrorMessage).To(Equal("Invalid ScanWindow: the duration must be positive"))
+			})
+		})
 	})
 	Context("On the PENDING phase", func() {
 		It("should update the compliancescan instance to phase LAUNCHING", func() {
@@ -254,6 +369,57 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 			})
 		})
 
+		Context("With a scan window", func() {
+			It("should queue the scan up until the window opens", func() {
+				// The window opens in two hours and closes an hour later
+				opensAt := time.Now().Add(2 * time.Hour)
+				compliancescaninstance.Spec.ScanWindow = &compv1alpha1.ScanWindow{
+					Schedule: fmt.Sprintf("%d %d * * *", opensAt.Minute(), opensAt.Hour()),
+					Duration: metav1.Duration{Duration: time.Hour},
+				}
+				result, err := reconciler.phasePendingHandler(compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+				Expect(result.RequeueAfter).To(BeNumerically("~", 2*time.Hour, time.Minute))
+
+				scan := &compv1alpha1.ComplianceScan{}
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				Expect(reconciler.Client.Get(context.TODO(), key, scan)).To(Succeed())
+				Expect(scan.Status.Phase).NotTo(Equal(compv1alpha1.PhaseLaunching))
+				Expect(scan.Status.QueuedUntil).NotTo(BeNil())
+				Expect(scan.Status.QueuedUntil.Time).To(BeTemporally("~", opensAt, time.Minute))
+				Expect(scan.Status.Conditions.GetCondition(compv1alpha1.ConditionReady).Reason).To(BeEquivalentTo("Queued"))
+			})
+
+			It("should launch the scan while the window is open", func() {
+				compliancescaninstance.Status.QueuedUntil = &metav1.Time{Time: time.Now()}
+				compliancescaninstance.Spec.ScanWindow = &compv1alpha1.ScanWindow{
+					Schedule: "* * * * *",
+					Duration: metav1.Duration{Duration: time.Hour},
+				}
+				_, err := reconciler.phasePendingHandler(compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseLaunching))
+				Expect(compliancescaninstance.Status.QueuedUntil).To(BeNil())
+			})
+
+			It("should tell how long it takes for the window to open", func() {
+				compliancescaninstance.Spec.ScanWindow = &compv1alpha1.ScanWindow{
+					Schedule: "0 22 * * 1-5",
+					Duration: metav1.Duration{Duration: 4 * time.Hour},
+				}
+				// Saturday at 1:00 is still within Friday's window
+				open, _ := scanWindowOpen(compliancescaninstance, time.Date(2024, 3, 2, 1, 0, 0, 0, time.Local))
+				Expect(open).To(BeTrue())
+				// Saturday at 12:00 waits until Monday at 22:00
+				open, wait := scanWindowOpen(compliancescaninstance, time.Date(2024, 3, 2, 12, 0, 0, 0, time.Local))
+				Expect(open).To(BeFalse())
+				Expect(wait).To(Equal(58 * time.Hour))
+			})
+		})
+
 	})
 
 	Context("On the LAUNCHING phase", func() {
@@ -421,135 +587,1228 @@ var _ = Describe("Testing compliancescan controller phases", func() {
 				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseAggregating))
 			})
 		})
-	})
 
-	Context("On the DONE phase", func() {
-		Context("with delete flag off", func() {
+		Context("With a pod that failed in the cluster", func() {
+			var failedPodName string
+
 			BeforeEach(func() {
-				// Create the pods and the secret for the test
-				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
-				createFakeRsSecret(reconciler, compliancescaninstance.Name)
+				failedPodName = getPodForNodeName(compliancescaninstance.Name, nodeinstance1.Name)
+				reconciler.Client.Create(context.TODO(), &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      failedPodName,
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+					Status: corev1.PodStatus{
+						Phase:  corev1.PodFailed,
+						Reason: "NodeShutdown",
+					},
+				})
+				reconciler.Client.Create(context.TODO(), &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name),
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+					Status: corev1.PodStatus{
+						Phase: corev1.PodRunning,
+					},
+				})
 
-				// Set state to DONE
-				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				// Set state to RUNNING
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseRunning
 				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
 				Expect(err).To(BeNil())
 			})
-			It("Should return success & preserve resources", func() {
-				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+
+			It("should delete the pod and count the retry if there are retries left", func() {
+				compliancescaninstance.Spec.MaxRetries = 1
+				err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
 				Expect(err).To(BeNil())
+				result, err := reconciler.phaseRunningHandler(handler, logger)
 				Expect(result).ToNot(BeNil())
-
-				// scan pods are cleaned up regardless
-				var pods corev1.PodList
-				err = reconciler.Client.List(context.TODO(), &pods)
 				Expect(err).To(BeNil())
-				Expect(pods.Items).To(BeEmpty())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+				Expect(compliancescaninstance.Status.ScanPodRetries).To(Equal(1))
+				Expect(compliancescaninstance.Status.LastScanPodRetryTimestamp).ToNot(BeNil())
 
-				// but other resources should be preserved
-				var secrets corev1.SecretList
-				err = reconciler.Client.List(context.TODO(), &secrets)
-				Expect(err).To(BeNil())
-				Expect(secrets.Items).ToNot(BeEmpty())
+				pod := &corev1.Pod{}
+				key := types.NamespacedName{Name: failedPodName, Namespace: common.GetComplianceOperatorNamespace()}
+				err = reconciler.Client.Get(context.TODO(), key, pod)
+				Expect(err).ToNot(BeNil())
 			})
-		})
-		Context("with delete flag on", func() {
-			BeforeEach(func() {
-				// Create the pods and the secret for the test
-				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
-				createFakeRsSecret(reconciler, compliancescaninstance.Name)
-				createFakeKubletConfigCM(reconciler, compliancescaninstance, nodeinstance1)
-				createFakeKubletConfigCM(reconciler, compliancescaninstance, nodeinstance2)
 
-				// Set state to DONE
-				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
-				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
-				Expect(err).To(BeNil())
-			})
-			It("Should return success & clean up resources", func() {
-				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, doDelete)
-				Expect(err).To(BeNil())
+			It("should keep the pod and store its failure if there are no retries left", func() {
+				compliancescaninstance.Spec.MaxRetries = 1
+				compliancescaninstance.Status.ScanPodRetries = 1
+				result, err := reconciler.phaseRunningHandler(handler, logger)
 				Expect(result).ToNot(BeNil())
-
-				var pods corev1.PodList
-				err = reconciler.Client.List(context.TODO(), &pods)
 				Expect(err).To(BeNil())
-				Expect(pods.Items).To(BeEmpty())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+				Expect(compliancescaninstance.Status.ScanPodRetries).To(Equal(1))
 
-				// also other resources should be gone
-				var secrets corev1.SecretList
-				err = reconciler.Client.List(context.TODO(), &secrets)
+				pod := &corev1.Pod{}
+				key := types.NamespacedName{Name: failedPodName, Namespace: common.GetComplianceOperatorNamespace()}
+				err = reconciler.Client.Get(context.TODO(), key, pod)
 				Expect(err).To(BeNil())
-				Expect(secrets.Items).To(BeEmpty())
 
-				var kubeletConfigCM corev1.ConfigMapList
-				err = reconciler.Client.List(context.TODO(), &kubeletConfigCM, &client.ListOptions{
-					LabelSelector: labels.SelectorFromSet(map[string]string{
-						compv1alpha1.KubeletConfigLabel: "",
-					}),
-				})
+				cm, err := getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance1.Name)
 				Expect(err).To(BeNil())
-				Expect(kubeletConfigCM.Items).To(BeEmpty())
-
+				Expect(cm.Data["exit-code"]).To(Equal(common.PodFailedExitCode))
+				Expect(checkScanUnknownError(cm)).To(MatchError(ContainSubstring("NodeShutdown")))
 			})
-		})
-		Context("with delete flag off but debug on as well", func() {
-			BeforeEach(func() {
-				// Create the pods for the test
-				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
-				createFakeRsSecret(reconciler, compliancescaninstance.Name)
 
-				// Set state to DONE
-				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
-				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			It("should error out with the failure of the pod once the other nodes are done", func() {
+				// No retries at all, as by default
+				compliancescaninstance.Spec.MaxRetries = 0
+				podKey := types.NamespacedName{
+					Name:      getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				}
+				pod := &corev1.Pod{}
+				Expect(reconciler.Client.Get(context.TODO(), podKey, pod)).To(Succeed())
+				pod.Status.Phase = corev1.PodSucceeded
+				Expect(reconciler.Client.Status().Update(context.TODO(), pod)).To(Succeed())
+				cm := utils.GetResultConfigMap(compliancescaninstance, getConfigMapForNodeName(compliancescaninstance.Name, nodeinstance2.Name),
+					"results", nodeinstance2.Name, strings.NewReader(""), false, common.OpenSCAPExitCodeCompliant, "")
+				Expect(reconciler.Client.Create(context.TODO(), cm)).To(Succeed())
+
+				_, err := reconciler.phaseRunningHandler(handler, logger)
 				Expect(err).To(BeNil())
-				compliancescaninstance.Spec.Debug = true
-				err = reconciler.Client.Update(context.TODO(), compliancescaninstance)
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseAggregating))
+
+				_, err = reconciler.phaseAggregatingHandler(handler, logger)
 				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseDone))
+				Expect(compliancescaninstance.Status.Result).To(Equal(compv1alpha1.ResultError))
+				Expect(compliancescaninstance.Status.ErrorMessage).To(ContainSubstring("NodeShutdown"))
 			})
-			It("Should return success & not delete the scan pods or secrets (doDelete=false)", func() {
-				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
-				Expect(err).To(BeNil())
-				Expect(result).ToNot(BeNil())
 
-				var pods corev1.PodList
-				err = reconciler.Client.List(context.TODO(), &pods)
+			It("should store the failure of the platform scan pod if there are no retries left", func() {
+				compliancescaninstance.Spec.ScanType = compv1alpha1.ScanTypePlatform
+				platformPodName := getPodForNodeName(compliancescaninstance.Name, PlatformScanName)
+				Expect(reconciler.Client.Create(context.TODO(), &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      platformPodName,
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+					Status: corev1.PodStatus{
+						Phase:  corev1.PodFailed,
+						Reason: "Evicted",
+					},
+				})).To(Succeed())
+				platformHandler, err := getScanTypeHandler(&reconciler, compliancescaninstance, logger)
 				Expect(err).To(BeNil())
-				Expect(pods.Items).ToNot(BeEmpty())
 
-				var secrets corev1.SecretList
-				err = reconciler.Client.List(context.TODO(), &secrets)
+				_, err = reconciler.phaseRunningHandler(platformHandler, logger)
 				Expect(err).To(BeNil())
-				Expect(secrets.Items).ToNot(BeEmpty())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseAggregating))
+				cm, err := getPlatformScanCM(&reconciler, compliancescaninstance)
+				Expect(err).To(BeNil())
+				Expect(checkScanUnknownError(cm)).To(MatchError(ContainSubstring("Evicted")))
 			})
-		})
-		Context("with delete flag on but debug on as well", func() {
-			BeforeEach(func() {
-				// Create the pods for the test
-				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
-				createFakeRsSecret(reconciler, compliancescaninstance.Name)
 
-				// Set state to DONE
-				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
-				compliancescaninstance.Spec.Debug = true
-				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			It("should keep checking the other nodes while the pod is retried", func() {
+				compliancescaninstance.Spec.MaxRetries = 3
+				compliancescaninstance.Status.ScanPodRetries = 1
+				compliancescaninstance.Status.LastScanPodRetryTimestamp = &metav1.Time{Time: time.Now()}
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name),
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+				}
+				Expect(reconciler.Client.Delete(context.TODO(), pod)).To(Succeed())
+
+				_, err := reconciler.phaseRunningHandler(handler, logger)
 				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseLaunching))
 			})
-			It("Should return success & delete the scan pods (doDelete=true)", func() {
-				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, doDelete)
-				Expect(err).To(BeNil())
-				Expect(result).ToNot(BeNil())
 
-				var pods corev1.PodList
-				err = reconciler.Client.List(context.TODO(), &pods)
+			It("should wait for the backoff before retrying again", func() {
+				compliancescaninstance.Spec.MaxRetries = 3
+				compliancescaninstance.Status.ScanPodRetries = 1
+				compliancescaninstance.Status.LastScanPodRetryTimestamp = &metav1.Time{Time: time.Now()}
+				_, err := reconciler.phaseRunningHandler(handler, logger)
 				Expect(err).To(BeNil())
-				Expect(pods.Items).To(BeEmpty())
+				Expect(compliancescaninstance.Status.ScanPodRetries).To(Equal(1))
 
-				var secrets corev1.SecretList
-				err = reconciler.Client.List(context.TODO(), &secrets)
+				pod := &corev1.Pod{}
+				key := types.NamespacedName{Name: failedPodName, Namespace: common.GetComplianceOperatorNamespace()}
+				err = reconciler.Client.Get(context.TODO(), key, pod)
 				Expect(err).To(BeNil())
-				Expect(secrets.Items).To(BeEmpty())
 			})
 		})
 	})
+
+	Context("With a limit of concurrently scanned nodes", func() {
+		BeforeEach(func() {
+			compliancescaninstance.Spec.MaxConcurrentNodes = 1
+			err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+			compliancescaninstance.Status.Phase = compv1alpha1.PhaseRunning
+			err = reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+		})
+
+		It("should only launch as many pods as the limit allows", func() {
+			err := handler.createScanWorkload()
+			Expect(err).To(BeNil())
+
+			pods := &corev1.PodList{}
+			err = reconciler.Client.List(context.TODO(), pods)
+			Expect(err).To(BeNil())
+			Expect(pods.Items).To(HaveLen(1))
+			Expect(pods.Items[0].Name).To(Equal(getPodForNodeName(compliancescaninstance.Name, nodeinstance1.Name)))
+		})
+
+		It("should wait while the running pods take up all the slots", func() {
+			err := handler.createScanWorkload()
+			Expect(err).To(BeNil())
+
+			running, _, err := handler.handleRunningScan()
+			Expect(err).To(BeNil())
+			Expect(running).To(BeTrue())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseRunning))
+			Expect(compliancescaninstance.Status.Progress).ToNot(BeNil())
+			Expect(compliancescaninstance.Status.Progress.NodesTotal).To(Equal(2))
+			Expect(compliancescaninstance.Status.Progress.NodesCompleted).To(Equal(0))
+			Expect(compliancescaninstance.Status.Progress.Summary).To(Equal("0/2"))
+			Expect(compliancescaninstance.Status.Progress.Nodes).To(Equal([]compv1alpha1.NodeScanProgress{
+				{Node: nodeinstance1.Name, Phase: compv1alpha1.NodeScanPhasePending},
+				{Node: nodeinstance2.Name, Phase: compv1alpha1.NodeScanPhaseWaiting},
+			}))
+		})
+
+		It("should go back to LAUNCHING once a slot is free", func() {
+			err := handler.createScanWorkload()
+			Expect(err).To(BeNil())
+
+			pod := &corev1.Pod{}
+			key := types.NamespacedName{
+				Name:      getPodForNodeName(compliancescaninstance.Name, nodeinstance1.Name),
+				Namespace: common.GetComplianceOperatorNamespace(),
+			}
+			err = reconciler.Client.Get(context.TODO(), key, pod)
+			Expect(err).To(BeNil())
+			pod.Status.Phase = corev1.PodSucceeded
+			err = reconciler.Client.Status().Update(context.TODO(), pod)
+			Expect(err).To(BeNil())
+
+			running, _, err := handler.handleRunningScan()
+			Expect(err).To(BeNil())
+			Expect(running).To(BeTrue())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseLaunching))
+			Expect(compliancescaninstance.Status.Progress.NodesCompleted).To(Equal(1))
+			Expect(compliancescaninstance.Status.Progress.Nodes[0].Phase).To(Equal(compv1alpha1.NodeScanPhaseDone))
+
+			err = handler.createScanWorkload()
+			Expect(err).To(BeNil())
+			pods := &corev1.PodList{}
+			err = reconciler.Client.List(context.TODO(), pods)
+			Expect(err).To(BeNil())
+			Expect(pods.Items).To(HaveLen(2))
+		})
+	})
+
+	Context("When resuming a scan whose scan pods are gone", func() {
+		createResultCM := func(node *corev1.Node) {
+			err := reconciler.Client.Create(context.TODO(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      getConfigMapForNodeName(compliancescaninstance.Name, node.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+			})
+			Expect(err).To(BeNil())
+		}
+
+		BeforeEach(func() {
+			compliancescaninstance.Status.Phase = compv1alpha1.PhaseRunning
+			err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+		})
+
+		It("should only relaunch the pods of the nodes without results", func() {
+			createResultCM(nodeinstance1)
+
+			_, err := reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseLaunching))
+			Expect(compliancescaninstance.Status.CompletedNodes).To(Equal([]string{nodeinstance1.Name}))
+
+			err = handler.createScanWorkload()
+			Expect(err).To(BeNil())
+			pods := &corev1.PodList{}
+			err = reconciler.Client.List(context.TODO(), pods)
+			Expect(err).To(BeNil())
+			Expect(pods.Items).To(HaveLen(1))
+			Expect(pods.Items[0].Name).To(Equal(getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name)))
+		})
+
+		It("should issue an event for each node that finished", func() {
+			recorder := record.NewFakeRecorder(10)
+			reconciler.Recorder = recorder
+			createResultCM(nodeinstance1)
+
+			_, err := reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(recorder.Events).To(Receive(Equal(
+				fmt.Sprintf("Normal NodeScanCompleted The scan finished on node %s", nodeinstance1.Name))))
+
+			By("not issuing it again for nodes that were already recorded")
+			_, err = reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(recorder.Events).ToNot(Receive(ContainSubstring("NodeScanCompleted")))
+		})
+
+		It("should move to AGGREGATING once all the nodes have results", func() {
+			createResultCM(nodeinstance1)
+			createResultCM(nodeinstance2)
+
+			_, err := reconciler.phaseRunningHandler(handler, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhaseAggregating))
+			Expect(compliancescaninstance.Status.CompletedNodes).To(ConsistOf(nodeinstance1.Name, nodeinstance2.Name))
+			Expect(compliancescaninstance.Status.Progress.NodesCompleted).To(Equal(2))
+		})
+
+		It("should forget the completed nodes when the scan starts again", func() {
+			compliancescaninstance.Status.Phase = compv1alpha1.PhasePending
+			compliancescaninstance.Status.CompletedNodes = []string{nodeinstance1.Name}
+			err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.phasePendingHandler(compliancescaninstance, logger)
+			Expect(err).To(BeNil())
+			Expect(compliancescaninstance.Status.CompletedNodes).To(BeEmpty())
+		})
+	})
+
+	Context("When gathering the results of nodes that errored", func() {
+		createResultCM := func(node *corev1.Node, result compv1alpha1.ComplianceScanStatusResult) {
+			err := reconciler.Client.Create(context.TODO(), &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      getConfigMapForNodeName(compliancescaninstance.Name, node.Name),
+					Namespace: common.GetComplianceOperatorNamespace(),
+					Annotations: map[string]string{
+						compv1alpha1.CmScanResultAnnotation: string(result),
+						compv1alpha1.CmScanResultErrMsg:     "",
+					},
+				},
+			})
+			Expect(err).To(BeNil())
+		}
+
+		BeforeEach(func() {
+			createResultCM(nodeinstance1, compv1alpha1.ResultError)
+			createResultCM(nodeinstance2, compv1alpha1.ResultCompliant)
+		})
+
+		It("should report an error by default", func() {
+			result, isReady, _ := handler.gatherResults()
+			Expect(isReady).To(BeTrue())
+			Expect(result).To(Equal(compv1alpha1.ResultError))
+		})
+
+		It("should report the result of the healthy nodes if the errors are tolerated", func() {
+			compliancescaninstance.Spec.MaxErroredNodesPercentage = 50
+			result, isReady, err := handler.gatherResults()
+			Expect(err).To(BeNil())
+			Expect(isReady).To(BeTrue())
+			Expect(result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(compliancescaninstance.Status.ErroredNodes).To(Equal([]string{nodeinstance1.Name}))
+
+			compliancescaninstance.Status.Result = result
+			compliancescaninstance.Status.SetConditionReady()
+			degraded := compliancescaninstance.Status.Conditions.GetCondition(compv1alpha1.ConditionDegraded)
+			Expect(degraded).ToNot(BeNil())
+			Expect(degraded.Status).To(Equal(corev1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal(compv1alpha1.ConditionReason("NodesErrored")))
+		})
+
+		It("should report an error if too many nodes errored", func() {
+			compliancescaninstance.Spec.MaxErroredNodesPercentage = 49
+			result, isReady, _ := handler.gatherResults()
+			Expect(isReady).To(BeTrue())
+			Expect(result).To(Equal(compv1alpha1.ResultError))
+			Expect(compliancescaninstance.Status.ErroredNodes).To(BeEmpty())
+		})
+	})
+
+	Context("With a list of node names", func() {
+		BeforeEach(func() {
+			compliancescaninstance.Spec.NodeNames = []string{nodeinstance2.Name}
+			err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
+			Expect(err).To(BeNil())
+			handler, err = getScanTypeHandler(&reconciler, compliancescaninstance, logger)
+			Expect(err).To(BeNil())
+		})
+
+		It("should only launch pods on the listed nodes", func() {
+			err := handler.createScanWorkload()
+			Expect(err).To(BeNil())
+
+			pods := &corev1.PodList{}
+			err = reconciler.Client.List(context.TODO(), pods)
+			Expect(err).To(BeNil())
+			Expect(pods.Items).To(HaveLen(1))
+			Expect(pods.Items[0].Name).To(Equal(getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name)))
+		})
+
+		It("should not target any node if none of the listed nodes exist", func() {
+			compliancescaninstance.Spec.NodeNames = []string{"node-3"}
+			nodes, err := reconciler.getNodesForScan(compliancescaninstance)
+			Expect(err).To(BeNil())
+			Expect(nodes.Items).To(BeEmpty())
+		})
+	})
+	Context("On the DONE phase", func() {
+		Context("with a single node rescan", func() {
+			BeforeEach(func() {
+				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
+				for _, node := range []*corev1.Node{nodeinstance1, nodeinstance2} {
+					cm := utils.GetResultConfigMap(compliancescaninstance,
+						getConfigMapForNodeName(compliancescaninstance.Name, node.Name), "results", node.Name,
+						strings.NewReader(""), false, common.OpenSCAPExitCodeCompliant, "")
+					err := reconciler.Client.Create(context.TODO(), cm)
+					Expect(err).To(BeNil())
+				}
+
+				compliancescaninstance.Annotations = map[string]string{
+					compv1alpha1.ComplianceScanRescanNodeAnnotation: nodeinstance2.Name,
+				}
+				err := reconciler.Client.Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				err = reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+
+			It("Should only reset the results of the rescanned node", func() {
+				_, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				_, err = getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance1.Name)
+				Expect(err).To(BeNil())
+				_, err = getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance2.Name)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}, compliancescaninstance)
+				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+				Expect(compliancescaninstance.Status.RescanNode).To(Equal(nodeinstance2.Name))
+			})
+
+			It("Should only launch a pod on the rescanned node", func() {
+				_, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}, compliancescaninstance)
+				Expect(err).To(BeNil())
+				handler, err = getScanTypeHandler(&reconciler, compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+				err = handler.createScanWorkload()
+				Expect(err).To(BeNil())
+
+				pods := &corev1.PodList{}
+				err = reconciler.Client.List(context.TODO(), pods)
+				Expect(err).To(BeNil())
+				Expect(pods.Items).To(HaveLen(1))
+				Expect(pods.Items[0].Name).To(Equal(getPodForNodeName(compliancescaninstance.Name, nodeinstance2.Name)))
+			})
+
+			It("Should have the aggregator process the results of the rest of the nodes again", func() {
+				cm, err := getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance1.Name)
+				Expect(err).To(BeNil())
+				cm.Annotations = map[string]string{
+					compv1alpha1.CmRemediationsProcessedAnnotation: "",
+					compv1alpha1.CmScanResultAnnotation:            string(compv1alpha1.ResultCompliant),
+				}
+				Expect(reconciler.Client.Update(context.TODO(), cm)).To(Succeed())
+
+				_, err = reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				cm, err = getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance1.Name)
+				Expect(err).To(BeNil())
+				Expect(cm.Annotations).ToNot(HaveKey(compv1alpha1.CmRemediationsProcessedAnnotation))
+				Expect(cm.Annotations).To(HaveKey(compv1alpha1.CmScanResultAnnotation))
+			})
+
+			It("Should rescan all the nodes if the raw results of another node are missing", func() {
+				cm, err := getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance1.Name)
+				Expect(err).To(BeNil())
+				Expect(reconciler.Client.Delete(context.TODO(), cm)).To(Succeed())
+
+				_, err = reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				_, err = getNodeScanCM(&reconciler, compliancescaninstance, nodeinstance2.Name)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}, compliancescaninstance)
+				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+				Expect(compliancescaninstance.Status.RescanNode).To(BeEmpty())
+			})
+
+			It("Should rescan all the nodes if the raw results were pruned", func() {
+				err := reconciler.deleteResultConfigMaps(compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+				_, err = reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}, compliancescaninstance)
+				Expect(err).To(BeNil())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+				Expect(compliancescaninstance.Status.RescanNode).To(BeEmpty())
+			})
+
+			It("Should run a full scan if the scan was marked as pruned", func() {
+				inputHashes := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      utils.GetInputHashesConfigMapName(compliancescaninstance.Name),
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+					Data: map[string]string{utils.InputHashesKey: "{}"},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), inputHashes)).To(Succeed())
+				compliancescaninstance.Annotations[compv1alpha1.ComplianceScanRawResultsPrunedAnnotation] = ""
+				Expect(reconciler.Client.Update(context.TODO(), compliancescaninstance)).To(Succeed())
+
+				_, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+
+				// The raw results the GC left behind aren't relied on
+				for _, node := range []*corev1.Node{nodeinstance1, nodeinstance2} {
+					_, err = getNodeScanCM(&reconciler, compliancescaninstance, node.Name)
+					Expect(errors.IsNotFound(err)).To(BeTrue())
+				}
+				err = reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(inputHashes), inputHashes)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				key := types.NamespacedName{
+					Name:      compliancescaninstance.Name,
+					Namespace: compliancescaninstance.Namespace,
+				}
+				Expect(reconciler.Client.Get(context.TODO(), key, compliancescaninstance)).To(Succeed())
+				Expect(compliancescaninstance.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+				Expect(compliancescaninstance.Status.RescanNode).To(BeEmpty())
+
+				By("clearing the mark once the scan runs again")
+				_, err = reconciler.phasePendingHandler(compliancescaninstance, logger)
+				Expect(err).To(BeNil())
+				Expect(reconciler.Client.Get(context.TODO(), key, compliancescaninstance)).To(Succeed())
+				Expect(compliancescaninstance.RawResultsWerePruned()).To(BeFalse())
+			})
+		})
+		Context("with delete flag off", func() {
+			BeforeEach(func() {
+				// Create the pods and the secret for the test
+				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
+				createFakeRsSecret(reconciler, compliancescaninstance.Name)
+
+				// Set state to DONE
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+			It("Should return success & preserve resources", func() {
+				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+				Expect(result).ToNot(BeNil())
+
+				// scan pods are cleaned up regardless
+				var pods corev1.PodList
+				err = reconciler.Client.List(context.TODO(), &pods)
+				Expect(err).To(BeNil())
+				Expect(pods.Items).To(BeEmpty())
+
+				// but other resources should be preserved
+				var secrets corev1.SecretList
+				err = reconciler.Client.List(context.TODO(), &secrets)
+				Expect(err).To(BeNil())
+				Expect(secrets.Items).ToNot(BeEmpty())
+			})
+			It("Should keep the result server running if it serves the raw results", func() {
+				deployment := resultServer(compliancescaninstance, map[string]string{}, 0, 0, logger)
+				Expect(reconciler.Client.Create(context.TODO(), deployment)).To(Succeed())
+				getReplicas := func() int32 {
+					found := &appsv1.Deployment{}
+					Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(deployment), found)).To(Succeed())
+					return *found.Spec.Replicas
+				}
+
+				compliancescaninstance.Spec.RawResultStorage.ServeRawResults = true
+				_, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+				Expect(getReplicas()).To(BeEquivalentTo(1))
+
+				compliancescaninstance.Spec.RawResultStorage.ServeRawResults = false
+				_, err = reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+				Expect(getReplicas()).To(BeEquivalentTo(0))
+			})
+		})
+		Context("with delete flag on", func() {
+			BeforeEach(func() {
+				// Create the pods and the secret for the test
+				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
+				createFakeRsSecret(reconciler, compliancescaninstance.Name)
+				createFakeKubletConfigCM(reconciler, compliancescaninstance, nodeinstance1)
+				createFakeKubletConfigCM(reconciler, compliancescaninstance, nodeinstance2)
+
+				// Set state to DONE
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+			It("Should return success & clean up resources", func() {
+				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, doDelete)
+				Expect(err).To(BeNil())
+				Expect(result).ToNot(BeNil())
+
+				var pods corev1.PodList
+				err = reconciler.Client.List(context.TODO(), &pods)
+				Expect(err).To(BeNil())
+				Expect(pods.Items).To(BeEmpty())
+
+				// also other resources should be gone
+				var secrets corev1.SecretList
+				err = reconciler.Client.List(context.TODO(), &secrets)
+				Expect(err).To(BeNil())
+				Expect(secrets.Items).To(BeEmpty())
+
+				var kubeletConfigCM corev1.ConfigMapList
+				err = reconciler.Client.List(context.TODO(), &kubeletConfigCM, &client.ListOptions{
+					LabelSelector: labels.SelectorFromSet(map[string]string{
+						compv1alpha1.KubeletConfigLabel: "",
+					}),
+				})
+				Expect(err).To(BeNil())
+				Expect(kubeletConfigCM.Items).To(BeEmpty())
+
+			})
+		})
+		Context("with delete flag off but debug on as well", func() {
+			BeforeEach(func() {
+				// Create the pods for the test
+				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
+				createFakeRsSecret(reconciler, compliancescaninstance.Name)
+
+				// Set state to DONE
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+				compliancescaninstance.Spec.Debug = true
+				err = reconciler.Client.Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+			It("Should return success & not delete the scan pods or secrets (doDelete=false)", func() {
+				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, dontDelete)
+				Expect(err).To(BeNil())
+				Expect(result).ToNot(BeNil())
+
+				var pods corev1.PodList
+				err = reconciler.Client.List(context.TODO(), &pods)
+				Expect(err).To(BeNil())
+				Expect(pods.Items).ToNot(BeEmpty())
+
+				var secrets corev1.SecretList
+				err = reconciler.Client.List(context.TODO(), &secrets)
+				Expect(err).To(BeNil())
+				Expect(secrets.Items).ToNot(BeEmpty())
+			})
+		})
+		Context("with delete flag on but debug on as well", func() {
+			BeforeEach(func() {
+				// Create the pods for the test
+				createFakeScanPods(reconciler, compliancescaninstance.Name, nodeinstance1.Name, nodeinstance2.Name)
+				createFakeRsSecret(reconciler, compliancescaninstance.Name)
+
+				// Set state to DONE
+				compliancescaninstance.Status.Phase = compv1alpha1.PhaseDone
+				compliancescaninstance.Spec.Debug = true
+				err := reconciler.Client.Status().Update(context.TODO(), compliancescaninstance)
+				Expect(err).To(BeNil())
+			})
+			It("Should return success & delete the scan pods (doDelete=true)", func() {
+				result, err := reconciler.phaseDoneHandler(handler, compliancescaninstance, logger, doDelete)
+				Expect(err).To(BeNil())
+				Expect(result).ToNot(BeNil())
+
+				var pods corev1.PodList
+				err = reconciler.Client.List(context.TODO(), &pods)
+				Expect(err).To(BeNil())
+				Expect(pods.Items).To(BeEmpty())
+
+				var secrets corev1.SecretList
+				err = reconciler.Client.List(context.TODO(), &secrets)
+				Expect(err).To(BeNil())
+				Expect(secrets.Items).To(BeEmpty())
+			})
+		})
+	})
+})
+
+var _ = Describe("Testing scanner container resources", func() {
+	var scanInstance *compv1alpha1.ComplianceScan
+
+	BeforeEach(func() {
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+	})
+
+	It("should use the default requests and limits", func() {
+		res := scannerResources(scanInstance, "500Mi", "100m")
+		Expect(res.Requests.Memory().String()).To(Equal("50Mi"))
+		Expect(res.Requests.Cpu().String()).To(Equal("10m"))
+		Expect(res.Limits.Memory().String()).To(Equal("500Mi"))
+		Expect(res.Limits.Cpu().String()).To(Equal("100m"))
+	})
+
+	It("should prefer scannerResources over scanLimits", func() {
+		scanInstance.Spec.ScanLimits = map[corev1.ResourceName]resource.Quantity{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+			corev1.ResourceCPU:    resource.MustParse("200m"),
+		}
+		scanInstance.Spec.ScannerResources = &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}
+		res := scannerResources(scanInstance, "500Mi", "100m")
+		Expect(res.Requests.Memory().String()).To(Equal("256Mi"))
+		Expect(res.Requests.Cpu().String()).To(Equal("10m"))
+		Expect(res.Limits.Memory().String()).To(Equal("2Gi"))
+		Expect(res.Limits.Cpu().String()).To(Equal("200m"))
+	})
+})
+
+var _ = Describe("Testing scanner output collection", func() {
+	var scanInstance *compv1alpha1.ComplianceScan
+	var node *corev1.Node
+
+	getCollectorCommand := func(pod *corev1.Pod) []string {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == "log-collector" {
+				return container.Command
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+		}
+	})
+
+	It("should not keep the scanner output by default", func() {
+		pod := newScanPodForNode(scanInstance, node, zapr.NewLogger(zap.NewNop()))
+		Expect(getCollectorCommand(pod)).ToNot(ContainElement(HavePrefix("--scanner-output-config-map=")))
+	})
+
+	It("should keep the scanner output of debug scans", func() {
+		scanInstance.Spec.Debug = true
+		pod := newScanPodForNode(scanInstance, node, zapr.NewLogger(zap.NewNop()))
+		cmName := utils.GetScannerOutputConfigMapName(getConfigMapForNodeName(scanInstance.Name, node.Name))
+		Expect(getCollectorCommand(pod)).To(ContainElement("--scanner-output-config-map=" + cmName))
+	})
+})
+
+var _ = Describe("Testing scanner arguments", func() {
+	It("should pass the arguments to the scanner one per line", func() {
+		scanInstance := &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: compv1alpha1.ComplianceScanSpec{
+				ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{
+					ScannerArgs: []string{"--skip-valid", "--verbose", "DEVEL"},
+				},
+			},
+		}
+		cm := defaultOpenScapEnvCm("test-env", scanInstance)
+		Expect(cm.Data).To(HaveKeyWithValue(OpenScapScannerArgsEnvName, "--skip-valid\n--verbose\nDEVEL"))
+	})
+
+	It("should not pass any arguments by default", func() {
+		scanInstance := &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+		cm := defaultOpenScapEnvCm("test-env", scanInstance)
+		Expect(cm.Data).ToNot(HaveKey(OpenScapScannerArgsEnvName))
+	})
+
+	It("should reject the arguments set by the operator", func() {
+		Expect(validateScannerArgs([]string{"--skip-valid", "--fetch-remote-resources"})).To(Succeed())
+		Expect(validateScannerArgs([]string{"--profile", "xccdf_org.ssgproject.content_profile_e8"})).ToNot(Succeed())
+		Expect(validateScannerArgs([]string{"--results-arf=/tmp/arf.xml"})).ToNot(Succeed())
+		Expect(validateScannerArgs([]string{"--skip-valid\n--profile"})).ToNot(Succeed())
+	})
+
+	It("should only allow the arguments that don't change the nodes", func() {
+		Expect(validateScannerArgs([]string{"--remediate"})).To(MatchError(ContainSubstring("--remediate is not allowed")))
+		Expect(validateScannerArgs([]string{"--skip-valid", "--remediate"})).ToNot(Succeed())
+		Expect(validateScannerArgs([]string{"--verbose-log-file", "/host/etc/passwd"})).ToNot(Succeed())
+		Expect(validateScannerArgs([]string{"--skip-valid=true"})).ToNot(Succeed())
+	})
+
+	It("should check the values of the arguments", func() {
+		Expect(validateScannerArgs([]string{"--verbose", "DEVEL", "--skip-valid"})).To(Succeed())
+		Expect(validateScannerArgs([]string{"--verbose=INFO"})).To(Succeed())
+		Expect(validateScannerArgs([]string{"--verbose", "--remediate"})).ToNot(Succeed())
+		Expect(validateScannerArgs([]string{"--verbose"})).To(MatchError(ContainSubstring("needs a value")))
+	})
+})
+
+var _ = Describe("Testing the scanner image", func() {
+	var scanInstance *compv1alpha1.ComplianceScan
+	var node *corev1.Node
+
+	getScannerContainerImage := func(pod *corev1.Pod) string {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == OpenSCAPScanContainerName {
+				return container.Image
+			}
+		}
+		return ""
+	}
+
+	BeforeEach(func() {
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+		}
+	})
+
+	It("should use the scanner image of the operator by default", func() {
+		pod := newScanPodForNode(scanInstance, node, zapr.NewLogger(zap.NewNop()))
+		Expect(getScannerContainerImage(pod)).To(Equal(utils.GetComponentImage(utils.OPENSCAP)))
+	})
+
+	It("should use the scanner image of the scan", func() {
+		image := "quay.io/test/openscap@sha256:0123456789012345678901234567890123456789012345678901234567890123"
+		scanInstance.Spec.ScannerImage = image
+		pod := newScanPodForNode(scanInstance, node, zapr.NewLogger(zap.NewNop()))
+		Expect(getScannerContainerImage(pod)).To(Equal(image))
+	})
+})
+
+var _ = Describe("Testing workload scheduling", func() {
+	var (
+		scanInstance *compv1alpha1.ComplianceScan
+		reconciler   *ReconcileComplianceScan
+		logger       logr.Logger
+	)
+
+	BeforeEach(func() {
+		zaplog, _ := zap.NewDevelopment()
+		logger = zapr.NewLogger(zaplog)
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: compv1alpha1.ComplianceScanSpec{
+				ScanType: compv1alpha1.ScanTypePlatform,
+			},
+		}
+		reconciler = &ReconcileComplianceScan{
+			schedulingInfo: utils.CtlplaneSchedulingInfo{
+				Selector: map[string]string{"node-role.kubernetes.io/master": ""},
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      "node-role.kubernetes.io/master",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					},
+				},
+			},
+		}
+	})
+
+	It("should schedule the workloads on the control plane by default", func() {
+		pod := reconciler.newAggregatorPod(scanInstance, logger)
+		Expect(pod.Spec.NodeSelector).To(Equal(reconciler.schedulingInfo.Selector))
+		Expect(pod.Spec.Tolerations).To(Equal(reconciler.schedulingInfo.Tolerations))
+		Expect(pod.Spec.Affinity).To(BeNil())
+
+		pod = reconciler.newPlatformScanPod(scanInstance, logger)
+		Expect(pod.Spec.NodeSelector).To(Equal(reconciler.schedulingInfo.Selector))
+		Expect(pod.Spec.Tolerations).To(Equal(reconciler.schedulingInfo.Tolerations))
+	})
+
+	It("should use the custom scheduling of each workload", func() {
+		infraTolerations := []corev1.Toleration{
+			{
+				Key:      "node-role.kubernetes.io/infra",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			},
+		}
+		affinity := &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchExpressions: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "node-role.kubernetes.io/infra",
+									Operator: corev1.NodeSelectorOpExists,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		scanInstance.Spec.AggregatorScheduling = &compv1alpha1.WorkloadScheduling{
+			Tolerations: infraTolerations,
+			Affinity:    affinity,
+		}
+		scanInstance.Spec.ScannerScheduling = &compv1alpha1.WorkloadScheduling{
+			NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+			Tolerations:  infraTolerations,
+		}
+		scanInstance.Spec.ResultServerScheduling = &compv1alpha1.WorkloadScheduling{
+			Tolerations: infraTolerations,
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+				{
+					MaxSkew:           1,
+					TopologyKey:       corev1.LabelTopologyZone,
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+				},
+			},
+		}
+
+		pod := reconciler.newAggregatorPod(scanInstance, logger)
+		Expect(pod.Spec.NodeSelector).To(Equal(reconciler.schedulingInfo.Selector))
+		Expect(pod.Spec.Tolerations).To(Equal(infraTolerations))
+		Expect(pod.Spec.Affinity).To(Equal(affinity))
+
+		pod = reconciler.newPlatformScanPod(scanInstance, logger)
+		Expect(pod.Spec.NodeSelector).To(HaveKey("node-role.kubernetes.io/infra"))
+		Expect(pod.Spec.Tolerations).To(Equal(infraTolerations))
+
+		deployment := resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		Expect(deployment.Spec.Template.Spec.Tolerations).To(Equal(infraTolerations))
+		Expect(deployment.Spec.Template.Spec.TopologySpreadConstraints).To(HaveLen(1))
+	})
+
+	It("Configures the retention of the raw results of the scan runs", func() {
+		deployment := resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		command := deployment.Spec.Template.Spec.Containers[0].Command
+		Expect(command).To(ContainElement(fmt.Sprintf("--rotation=%d", scanInstance.Spec.RawResultStorage.Rotation)))
+		Expect(command).ToNot(ContainElement(HavePrefix("--max-age")))
+
+		maxRuns := uint16(10)
+		scanInstance.Spec.RawResultStorage.MaxRetainedScanRuns = &maxRuns
+		scanInstance.Spec.RawResultStorage.MaxScanRunAge = &metav1.Duration{Duration: 720 * time.Hour}
+		deployment = resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		command = deployment.Spec.Template.Spec.Containers[0].Command
+		Expect(command).To(ContainElements("--rotation=10", "--max-age=720h0m0s"))
+	})
+
+	It("Uploads the raw results of the scan to the object storage", func() {
+		scanInstance.Spec.RawResultStorage.ObjectStorage = &compv1alpha1.RawResultObjectStorage{
+			Endpoint:              "https://s3.eu-west-1.amazonaws.com",
+			Bucket:                "compliance",
+			Prefix:                "prod/",
+			CredentialsSecretName: "s3-credentials",
+			ServerSideEncryption:  compv1alpha1.ObjectStorageEncryptionAES256,
+		}
+		deployment := resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		container := deployment.Spec.Template.Spec.Containers[0]
+		Expect(container.Command).To(ContainElements(
+			"--object-storage-endpoint=https://s3.eu-west-1.amazonaws.com",
+			"--object-storage-region=us-east-1",
+			"--object-storage-bucket=compliance",
+			"--object-storage-prefix=prod/"+scanInstance.Name+"/",
+			"--object-storage-sse=AES256"))
+		Expect(container.Command).ToNot(ContainElement(HavePrefix("--object-storage-kms-key-id")))
+		Expect(container.Env).To(HaveLen(2))
+		Expect(container.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("s3-credentials"))
+		Expect(container.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal(compv1alpha1.ObjectStorageAccessKeyIDKey))
+		Expect(deployment.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim).ToNot(BeNil())
+
+		By("only uploading them")
+		scanInstance.Spec.RawResultStorage.ObjectStorage.DisablePersistentVolume = true
+		deployment = resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		Expect(deployment.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim).To(BeNil())
+		Expect(deployment.Spec.Template.Spec.Volumes[0].EmptyDir).ToNot(BeNil())
+		reconciler.Client = fake.NewClientBuilder().Build()
+		resume, err := reconciler.handleRawResultsForScan(scanInstance, logger)
+		Expect(err).To(BeNil())
+		Expect(resume).To(BeTrue())
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		Expect(reconciler.Client.List(context.TODO(), pvcList)).To(Succeed())
+		Expect(pvcList.Items).To(BeEmpty())
+	})
+
+	It("Creates neither a PVC nor a result server if the raw results are discarded", func() {
+		scanInstance.Spec.RawResultStorage.DisablePersistentVolume = true
+		reconciler.Client = fake.NewClientBuilder().Build()
+		resume, err := reconciler.handleRawResultsForScan(scanInstance, logger)
+		Expect(err).To(BeNil())
+		Expect(resume).To(BeTrue())
+		Expect(reconciler.createResultServer(scanInstance, logger)).To(Succeed())
+
+		pvcList := &corev1.PersistentVolumeClaimList{}
+		Expect(reconciler.Client.List(context.TODO(), pvcList)).To(Succeed())
+		Expect(pvcList.Items).To(BeEmpty())
+		deployments := &appsv1.DeploymentList{}
+		Expect(reconciler.Client.List(context.TODO(), deployments)).To(Succeed())
+		Expect(deployments.Items).To(BeEmpty())
+	})
+
+	It("Serves the raw results of the scan", func() {
+		deployment := resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		Expect(deployment.Spec.Template.Spec.Containers[0].Command).ToNot(ContainElement("--serve-results"))
+
+		scanInstance.Spec.RawResultStorage.ServeRawResults = true
+		deployment = resultServer(scanInstance, map[string]string{}, 0, 0, logger)
+		Expect(deployment.Spec.Template.Spec.Containers[0].Command).To(ContainElements(
+			"--serve-results",
+			"--scan-name="+scanInstance.Name,
+			"--scan-namespace="+scanInstance.Namespace))
+	})
+})
+
+var _ = Describe("Testing custom rules", func() {
+	var (
+		scanInstance *compv1alpha1.ComplianceScan
+		reconciler   *ReconcileComplianceScan
+	)
+
+	getCommand := func(containers []corev1.Container, name string) []string {
+		for _, container := range containers {
+			if container.Name == name {
+				return container.Command
+			}
+		}
+		return nil
+	}
+
+	BeforeEach(func() {
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Spec: compv1alpha1.ComplianceScanSpec{
+				ScanType: compv1alpha1.ScanTypePlatform,
+			},
+		}
+		reconciler = &ReconcileComplianceScan{}
+	})
+
+	It("should not evaluate custom rules by default", func() {
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.InitContainers, PlatformScanResourceCollectorName)).ToNot(ContainElement(HavePrefix("--custom-rules=")))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement(HavePrefix("--custom-rule-results-file=")))
+	})
+
+	It("should evaluate the custom rules and upload their results", func() {
+		scanInstance.Spec.CustomRules = []string{"etcd-encrypted", "no-kubeadmin"}
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.InitContainers, PlatformScanResourceCollectorName)).To(ContainElements(
+			"--custom-rules=etcd-encrypted,no-kubeadmin",
+			"--custom-rule-results-file="+customRuleResultsFile,
+		))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).To(ContainElement("--custom-rule-results-file=" + customRuleResultsFile))
+	})
+
+	It("should only collect the evidence of failing checks if asked to", func() {
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement(HavePrefix("--failure-evidence-")))
+
+		scanInstance.Spec.FailureEvidence = &compv1alpha1.FailureEvidenceSettings{
+			RedactFields: []string{"value", "text"},
+		}
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).To(ContainElements(
+			"--failure-evidence-max-size=1024",
+			"--failure-evidence-redact-fields=value,text",
+		))
+	})
+
+	It("should compress the raw results with the codec of the scan", func() {
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement(HavePrefix("--compression")))
+
+		scanInstance.Spec.RawResultStorage.Compression = compv1alpha1.RawResultCompressionZstd
+		scanInstance.Spec.RawResultStorage.CompressionLevel = 9
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).To(ContainElements(
+			"--compression=zstd",
+			"--compression-level=9",
+		))
+	})
+
+	It("should discard the raw results if they aren't stored anywhere", func() {
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement("--discard-arf"))
+
+		scanInstance.Spec.RawResultStorage.DisablePersistentVolume = true
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).To(ContainElement("--discard-arf"))
+
+		By("uploading them to the object storage instead")
+		scanInstance.Spec.RawResultStorage.ObjectStorage = &compv1alpha1.RawResultObjectStorage{
+			Endpoint:              "https://s3.eu-west-1.amazonaws.com",
+			Bucket:                "compliance",
+			CredentialsSecretName: "s3-credentials",
+		}
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement("--discard-arf"))
+	})
+
+	It("should generate the HTML reports if asked to", func() {
+		Expect(defaultOpenScapEnvCm("test-env", scanInstance).Data).ToNot(HaveKey(OpenScapHTMLReportEnvName))
+		pod := reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement(HavePrefix("--html-report-file")))
+
+		scanInstance.Spec.RawResultStorage.HTMLReport = true
+		Expect(platformOpenScapEnvCm("test-env", scanInstance).Data).To(HaveKeyWithValue(OpenScapHTMLReportEnvName, "true"))
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).To(ContainElement("--html-report-file=/reports/report.html"))
+
+		By("discarding the raw results")
+		scanInstance.Spec.RawResultStorage.DisablePersistentVolume = true
+		Expect(platformOpenScapEnvCm("test-env", scanInstance).Data).ToNot(HaveKey(OpenScapHTMLReportEnvName))
+		pod = reconciler.newPlatformScanPod(scanInstance, zapr.NewLogger(zap.NewNop()))
+		Expect(getCommand(pod.Spec.Containers, "log-collector")).ToNot(ContainElement(HavePrefix("--html-report-file")))
+	})
+})
+
+var _ = Describe("Testing scanner security context", func() {
+	var scanInstance *compv1alpha1.ComplianceScan
+
+	BeforeEach(func() {
+		scanInstance = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+		}
+	})
+
+	It("should keep the default security context if the scan doesn't set one", func() {
+		trueP := true
+		sc := scannerSecurityContext(scanInstance, &corev1.PodSecurityContext{RunAsNonRoot: &trueP})
+		Expect(sc.SeccompProfile).To(BeNil())
+		Expect(*sc.RunAsNonRoot).To(BeTrue())
+		Expect(sc.RunAsUser).To(BeNil())
+	})
+
+	It("should only use a seccomp profile that is set in the scan", func() {
+		scanInstance.Spec.ScannerSecurityContext = &corev1.PodSecurityContext{
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+		}
+		sc := scannerSecurityContext(scanInstance, &corev1.PodSecurityContext{})
+		Expect(sc.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeRuntimeDefault))
+		Expect(sc.RunAsNonRoot).To(BeNil())
+	})
+
+	It("should only override the attributes set in the scan", func() {
+		trueP := true
+		uid := int64(1000)
+		scanInstance.Spec.ScannerSecurityContext = &corev1.PodSecurityContext{
+			RunAsUser: &uid,
+			SELinuxOptions: &corev1.SELinuxOptions{
+				Type: "spc_t",
+			},
+			SeccompProfile: &corev1.SeccompProfile{
+				Type:             corev1.SeccompProfileTypeLocalhost,
+				LocalhostProfile: &[]string{"scanner.json"}[0],
+			},
+		}
+		sc := scannerSecurityContext(scanInstance, &corev1.PodSecurityContext{RunAsNonRoot: &trueP})
+		Expect(*sc.RunAsNonRoot).To(BeTrue())
+		Expect(*sc.RunAsUser).To(Equal(uid))
+		Expect(sc.SELinuxOptions.Type).To(Equal("spc_t"))
+		Expect(sc.SeccompProfile.Type).To(Equal(corev1.SeccompProfileTypeLocalhost))
+	})
+})
+
+var _ = Describe("Testing the result of incremental scans", func() {
+	check := func(status compv1alpha1.ComplianceCheckStatus) compv1alpha1.ComplianceCheckResult {
+		return compv1alpha1.ComplianceCheckResult{Status: status}
+	}
+
+	It("should be compliant if no results were kept", func() {
+		Expect(getIncrementalScanResult(nil)).To(Equal(compv1alpha1.ResultCompliant))
+	})
+
+	for _, tc := range []struct {
+		description string
+		statuses    []compv1alpha1.ComplianceCheckStatus
+		expected    compv1alpha1.ComplianceScanStatusResult
+	}{
+		{
+			description: "compliant if all the kept results pass",
+			statuses:    []compv1alpha1.ComplianceCheckStatus{compv1alpha1.CheckResultPass, compv1alpha1.CheckResultInfo, compv1alpha1.CheckResultNotApplicable},
+			expected:    compv1alpha1.ResultCompliant,
+		},
+		{
+			description: "compliant if a kept result needs a manual review",
+			statuses:    []compv1alpha1.ComplianceCheckStatus{compv1alpha1.CheckResultPass, compv1alpha1.CheckResultManual},
+			expected:    compv1alpha1.ResultCompliant,
+		},
+		{
+			description: "non-compliant if a kept result fails",
+			statuses:    []compv1alpha1.ComplianceCheckStatus{compv1alpha1.CheckResultPass, compv1alpha1.CheckResultManual, compv1alpha1.CheckResultFail},
+			expected:    compv1alpha1.ResultNonCompliant,
+		},
+		{
+			description: "inconsistent if a kept result is inconsistent",
+			statuses:    []compv1alpha1.ComplianceCheckStatus{compv1alpha1.CheckResultFail, compv1alpha1.CheckResultInconsistent},
+			expected:    compv1alpha1.ResultInconsistent,
+		},
+		{
+			description: "errored if a kept result errored",
+			statuses:    []compv1alpha1.ComplianceCheckStatus{compv1alpha1.CheckResultError, compv1alpha1.CheckResultFail, compv1alpha1.CheckResultInconsistent},
+			expected:    compv1alpha1.ResultError,
+		},
+	} {
+		tc := tc
+		It("should be "+tc.description, func() {
+			var checks []compv1alpha1.ComplianceCheckResult
+			for _, status := range tc.statuses {
+				checks = append(checks, check(status))
+			}
+			Expect(getIncrementalScanResult(checks)).To(Equal(tc.expected))
+		})
+	}
 })
@@ -2,7 +2,9 @@ package compliancescan
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -40,8 +42,11 @@ const (
 	OpenScapContentEnvName      = "CONTENT"
 	OpenScapReportDirEnvName    = "REPORT_DIR"
 	OpenScapRuleEnvName         = "RULE"
+	OpenScapSkipRulesEnvName    = "SKIP_RULES"
 	OpenScapVerbosityeEnvName   = "VERBOSITY"
 	OpenScapTailoringDirEnvName = "TAILORING_DIR"
+	OpenScapScannerArgsEnvName  = "SCANNER_ARGS"
+	OpenScapHTMLReportEnvName   = "HTML_REPORT"
 	HTTPSProxyEnvName           = "HTTPS_PROXY"
 	DisconnectedInstallEnvName  = "DISCONNECTED"
 
@@ -54,6 +59,10 @@ const (
 	PlatformScanResourceCollectorName = "api-resource-collector"
 	// This coincides with the default ocp_data_root var in CaC.
 	PlatformScanDataRoot = "/kubernetes-api-resources"
+
+	// This is where the input hashes from the previous run of an
+	// incremental scan are mounted
+	InputHashesDir = "/input-hashes"
 )
 
 var defaultOpenScapScriptContents = `#!/bin/bash
@@ -115,6 +124,28 @@ cmd+=(
 
 if [ ! -z $RULE ]; then
     cmd+=(--rule $RULE)
+elif [ -f "$REPORT_DIR/changed_rules" ]; then
+    # Incremental scan, only evaluate the rules whose inputs changed. If
+    # none did, all the results of the previous run are kept.
+    if [ ! -s "$REPORT_DIR/changed_rules" ]; then
+        echo "No rule inputs changed since the previous run, not running the scanner" | tee $REPORT_DIR/cmd_output
+        echo "unchanged" > $REPORT_DIR/exit_code
+        exit 0
+    fi
+    while read -r changed_rule; do
+        cmd+=(--rule "$changed_rule")
+    done < "$REPORT_DIR/changed_rules"
+fi
+
+for skip_rule in $SKIP_RULES; do
+    cmd+=(--skip-rule "$skip_rule")
+done
+
+# Additional arguments requested in the scan, one per line
+if [ ! -z "$SCANNER_ARGS" ]; then
+    while read -r scanner_arg; do
+        cmd+=("$scanner_arg")
+    done <<< "$SCANNER_ARGS"
 fi
 
 cmd+=($CONTENT)
@@ -157,6 +188,17 @@ if [ -f "$ARF_REPORT" ]; then
 		sed -i "s/\(<target>\)[^<>]*\(<\/target\)/\1$OVERRIDE_TARGET\2/" "$ARF_REPORT"
 	fi
 
+	# The HTML report is optional, the scan goes on if it can't be
+	# generated
+	if [ ! -z "$HTML_REPORT" ]; then
+		oscap xccdf generate report --output /tmp/report.html $ARF_REPORT
+		report_rv=$?
+		echo "The HTML report generation returned $report_rv"
+		if [ $report_rv -eq 0 ]; then
+			mv /tmp/report.html $REPORT_DIR
+		fi
+	fi
+
 	mv $ARF_REPORT $REPORT_DIR
 fi
 echo "$rv" > $REPORT_DIR/exit_code
@@ -246,6 +288,14 @@ func commonOpenScapEnvCm(name string, scan *compv1alpha1.ComplianceScan) *corev1
 		cm.Data[OpenScapRuleEnvName] = scan.Spec.Rule
 	}
 
+	if len(scan.Spec.ExcludeRules) > 0 {
+		cm.Data[OpenScapSkipRulesEnvName] = strings.Join(scan.Spec.ExcludeRules, " ")
+	}
+
+	if len(scan.Spec.ScannerArgs) > 0 {
+		cm.Data[OpenScapScannerArgsEnvName] = strings.Join(scan.Spec.ScannerArgs, "\n")
+	}
+
 	cm.Data[OpenScapVerbosityeEnvName] = getLogLevel(scan)
 
 	// the env var takes precedence
@@ -266,6 +316,10 @@ func commonOpenScapEnvCm(name string, scan *compv1alpha1.ComplianceScan) *corev1
 		cm.Data[DisconnectedInstallEnvName] = "true"
 	}
 
+	if scan.Spec.RawResultStorage.GeneratesHTMLReports() {
+		cm.Data[OpenScapHTMLReportEnvName] = "true"
+	}
+
 	return cm
 }
 
@@ -327,3 +381,61 @@ func envCmForScan(scan *compv1alpha1.ComplianceScan) string {
 func envCmForPlatformScan(scan *compv1alpha1.ComplianceScan) string {
 	return utils.DNSLengthName("scap-env-", "%s-%s", scan.Name, OpenScapPlatformEnvConfigMapName)
 }
+
+// allowedScannerArgs are the arguments of the scanner that can be passed in
+// the scannerArgs of a scan, along with the values they take, if any. The
+// scanner runs privileged with the file system of the host mounted, so only
+// the arguments that don't change the host or the files the operator reads
+// are allowed, and e.g. --remediate isn't.
+var allowedScannerArgs = map[string][]string{
+	"--skip-valid":                nil,
+	"--skip-validation":           nil,
+	"--skip-signature-validation": nil,
+	"--enforce-signature":         nil,
+	"--fetch-remote-resources":    nil,
+	"--progress":                  nil,
+	"--progress-full":             nil,
+	"--thin-results":              nil,
+	"--without-syschar":           nil,
+	"--verbose":                   {"DEVEL", "INFO", "WARNING", "ERROR"},
+}
+
+// validateScannerArgs makes sure that the additional arguments of the
+// scanner are all allowed, along with their values. An argument that takes a
+// value is followed by it, either as the next item or after a '='.
+func validateScannerArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.Contains(arg, "\n") {
+			return fmt.Errorf("scanner argument %q contains a newline", arg)
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		values, ok := allowedScannerArgs[name]
+		if !ok {
+			return fmt.Errorf("scanner argument %s is not allowed", name)
+		}
+		if values == nil {
+			if hasValue {
+				return fmt.Errorf("scanner argument %s doesn't take a value", name)
+			}
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return fmt.Errorf("scanner argument %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		valid := false
+		for _, v := range values {
+			if value == v {
+				valid = true
+			}
+		}
+		if !valid {
+			return fmt.Errorf("scanner argument %s doesn't take the value %q, only one of %s", name, value, strings.Join(values, ", "))
+		}
+	}
+	return nil
+}
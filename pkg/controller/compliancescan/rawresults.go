@@ -24,6 +24,10 @@ var (
 // that the PVC gets created, and, if necessary, the scan instance will get updated too.
 // Returns whether the reconcile loop should continue or not, and an error if encountered.
 func (r *ReconcileComplianceScan) handleRawResultsForScan(instance *compv1alpha1.ComplianceScan, logger logr.Logger) (bool, error) {
+	// The raw results are only uploaded to the object storage
+	if !instance.Spec.RawResultStorage.UsesPersistentVolume() {
+		return true, nil
+	}
 	// Create PVC
 	pvc := getPVCForScan(instance)
 	logger.Info("Creating PVC for scan", "PersistentVolumeClaim.Name", pvc.Name, "PersistentVolumeClaim.Namespace", pvc.Namespace)
@@ -34,7 +38,7 @@ func (r *ReconcileComplianceScan) handleRawResultsForScan(instance *compv1alpha1
 			scanCopy.Status.Phase = compv1alpha1.PhaseDone
 			scanCopy.Status.Result = compv1alpha1.ResultError
 			scanCopy.Status.ErrorMessage = rawStorageAllocationErrorPrefix + err.Error()
-			return false, r.Client.Status().Update(context.TODO(), scanCopy)
+			return false, r.updateStatus(scanCopy)
 		}
 		return false, err
 	}
@@ -45,7 +49,7 @@ func (r *ReconcileComplianceScan) handleRawResultsForScan(instance *compv1alpha1
 		scanCopy.Status.ResultsStorage.Name = pvc.Name
 		scanCopy.Status.ResultsStorage.Namespace = pvc.Namespace
 		logger.Info("Updating scan status with raw result reference")
-		return false, r.Client.Status().Update(context.TODO(), scanCopy)
+		return false, r.updateStatus(scanCopy)
 	}
 	return true, nil
 }
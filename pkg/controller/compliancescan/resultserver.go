@@ -30,6 +30,10 @@ const (
 // stores them in a PVC.
 // It's comprised of the PVC for the scan, the pod and a service that fronts it
 func (r *ReconcileComplianceScan) createResultServer(instance *compv1alpha1.ComplianceScan, logger logr.Logger) error {
+	// The raw results are discarded, so there's nothing to upload them to
+	if instance.Spec.RawResultStorage.DiscardsRawResults() {
+		return nil
+	}
 	ctx := context.Background()
 	resultServerLabels := getResultServerLabels(instance)
 
@@ -170,6 +174,39 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 	podFSGroup, podUid int64, logger logr.Logger) *appsv1.Deployment {
 	falseP := false
 	trueP := true
+	command := []string{
+		"compliance-operator", "resultserver",
+		"--path=/reports/",
+		"--address=0.0.0.0",
+		fmt.Sprintf("--port=%d", ResultServerPort),
+		fmt.Sprintf("--scan-index=%d", scanInstance.Status.CurrentIndex),
+		fmt.Sprintf("--rotation=%d", scanInstance.Spec.RawResultStorage.GetRetainedScanRuns()),
+		"--tls-server-cert=/etc/pki/tls/tls.crt",
+		"--tls-server-key=/etc/pki/tls/tls.key",
+		"--tls-ca=/etc/pki/tls/ca.crt",
+	}
+	if maxAge := scanInstance.Spec.RawResultStorage.MaxScanRunAge; maxAge != nil && maxAge.Duration > 0 {
+		command = append(command, fmt.Sprintf("--max-age=%s", maxAge.Duration))
+	}
+	if scanInstance.Spec.RawResultStorage.ServeRawResults {
+		command = append(command,
+			"--serve-results",
+			"--scan-name="+scanInstance.Name,
+			"--scan-namespace="+scanInstance.Namespace)
+	}
+	var env []corev1.EnvVar
+	if storage := scanInstance.Spec.RawResultStorage.ObjectStorage; storage != nil {
+		command = append(command, getObjectStorageArgs(scanInstance.Name, storage)...)
+		env = getObjectStorageCredentialsEnv(storage)
+	}
+	reportsVolume := corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: getPVCForScanName(scanInstance.Name),
+		},
+	}
+	if !scanInstance.Spec.RawResultStorage.UsesPersistentVolume() {
+		reportsVolume = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	}
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getResultServerName(scanInstance),
@@ -188,10 +225,12 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 					},
 				},
 				Spec: corev1.PodSpec{
-					NodeSelector:       scanInstance.Spec.RawResultStorage.NodeSelector,
-					Tolerations:        scanInstance.Spec.RawResultStorage.Tolerations,
-					ServiceAccountName: resultserverSA,
-					PriorityClassName:  scanInstance.Spec.PriorityClass,
+					NodeSelector:              scanInstance.Spec.ResultServerScheduling.GetNodeSelector(scanInstance.Spec.RawResultStorage.NodeSelector),
+					Tolerations:               scanInstance.Spec.ResultServerScheduling.GetTolerations(scanInstance.Spec.RawResultStorage.Tolerations),
+					Affinity:                  scanInstance.Spec.ResultServerScheduling.GetAffinity(),
+					TopologySpreadConstraints: scanInstance.Spec.ResultServerScheduling.GetTopologySpreadConstraints(),
+					ServiceAccountName:        resultserverSA,
+					PriorityClassName:         scanInstance.Spec.PriorityClass,
 					SecurityContext: &corev1.PodSecurityContext{
 						FSGroup:      &podFSGroup,
 						RunAsNonRoot: &trueP,
@@ -202,17 +241,8 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 							Name:            "result-server",
 							Image:           utils.GetComponentImage(utils.OPERATOR),
 							ImagePullPolicy: corev1.PullAlways,
-							Command: []string{
-								"compliance-operator", "resultserver",
-								"--path=/reports/",
-								"--address=0.0.0.0",
-								fmt.Sprintf("--port=%d", ResultServerPort),
-								fmt.Sprintf("--scan-index=%d", scanInstance.Status.CurrentIndex),
-								fmt.Sprintf("--rotation=%d", scanInstance.Spec.RawResultStorage.Rotation),
-								"--tls-server-cert=/etc/pki/tls/tls.crt",
-								"--tls-server-key=/etc/pki/tls/tls.key",
-								"--tls-ca=/etc/pki/tls/ca.crt",
-							},
+							Command:         command,
+							Env:             env,
 							SecurityContext: &corev1.SecurityContext{
 								AllowPrivilegeEscalation: &falseP,
 								ReadOnlyRootFilesystem:   &trueP,
@@ -235,12 +265,8 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 					},
 					Volumes: []corev1.Volume{
 						{
-							Name: "arfreports",
-							VolumeSource: corev1.VolumeSource{
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: getPVCForScanName(scanInstance.Name),
-								},
-							},
+							Name:         "arfreports",
+							VolumeSource: reportsVolume,
 						},
 						{
 							Name: "tls",
@@ -257,6 +283,45 @@ func resultServer(scanInstance *compv1alpha1.ComplianceScan, labels map[string]s
 	}
 }
 
+// getObjectStorageArgs returns the arguments of the result server to upload
+// the raw results of the scan to the object storage with
+func getObjectStorageArgs(scanName string, storage *compv1alpha1.RawResultObjectStorage) []string {
+	args := []string{
+		"--object-storage-endpoint=" + storage.Endpoint,
+		"--object-storage-region=" + storage.GetRegion(),
+		"--object-storage-bucket=" + storage.Bucket,
+		"--object-storage-prefix=" + storage.Prefix + scanName + "/",
+	}
+	if storage.ServerSideEncryption != "" {
+		args = append(args, "--object-storage-sse="+string(storage.ServerSideEncryption))
+	}
+	if storage.KMSKeyID != "" {
+		args = append(args, "--object-storage-kms-key-id="+storage.KMSKeyID)
+	}
+	return args
+}
+
+// getObjectStorageCredentialsEnv returns the environment of the result
+// server that holds the credentials to the object storage, taken from its
+// Secret
+func getObjectStorageCredentialsEnv(storage *compv1alpha1.RawResultObjectStorage) []corev1.EnvVar {
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: storage.CredentialsSecretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	return []corev1.EnvVar{
+		fromSecret("AWS_ACCESS_KEY_ID", compv1alpha1.ObjectStorageAccessKeyIDKey),
+		fromSecret("AWS_SECRET_ACCESS_KEY", compv1alpha1.ObjectStorageSecretAccessKeyKey),
+	}
+}
+
 func resultServerService(scanInstance *compv1alpha1.ComplianceScan, labels map[string]string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
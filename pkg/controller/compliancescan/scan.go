@@ -25,6 +25,7 @@ const (
 	apiResourceCollectorSA  = "api-resource-collector"
 	tailoringCMVolumeName   = "tailoring"
 	tailoringNotFoundPrefix = "Tailoring ConfigMap not found: "
+	customRuleResultsFile   = "/reports/custom_rule_results"
 )
 
 func (r *ReconcileComplianceScan) launchScanPod(instance *compv1alpha1.ComplianceScan, pod *corev1.Pod, logger logr.Logger) error {
@@ -66,6 +67,63 @@ func scanLimits(scanInstance *compv1alpha1.ComplianceScan, defaultMem, defaultCp
 	return &limits
 }
 
+// scannerResources returns the resource requirements of the container that
+// runs OpenSCAP, taking the overrides set in the scan into account
+func scannerResources(scanInstance *compv1alpha1.ComplianceScan, defaultMem, defaultCpu string) corev1.ResourceRequirements {
+	requests := corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("50Mi"),
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+	}
+	limits := *scanLimits(scanInstance, defaultMem, defaultCpu)
+
+	if custom := scanInstance.Spec.ScannerResources; custom != nil {
+		for name, quantity := range custom.Requests {
+			requests[name] = quantity
+		}
+		for name, quantity := range custom.Limits {
+			limits[name] = quantity
+		}
+	}
+
+	return corev1.ResourceRequirements{
+		Requests: requests,
+		Limits:   limits,
+	}
+}
+
+// scannerSecurityContext returns the pod security context of the scan pods,
+// overriding the attributes of def with the ones set in the scan. Nothing is
+// added to def otherwise, e.g. a seccomp profile is only used if it's set.
+func scannerSecurityContext(scanInstance *compv1alpha1.ComplianceScan, def *corev1.PodSecurityContext) *corev1.PodSecurityContext {
+	sc := def.DeepCopy()
+	custom := scanInstance.Spec.ScannerSecurityContext
+	if custom == nil {
+		return sc
+	}
+	if custom.SELinuxOptions != nil {
+		sc.SELinuxOptions = custom.SELinuxOptions.DeepCopy()
+	}
+	if custom.RunAsUser != nil {
+		sc.RunAsUser = custom.RunAsUser
+	}
+	if custom.RunAsGroup != nil {
+		sc.RunAsGroup = custom.RunAsGroup
+	}
+	if custom.RunAsNonRoot != nil {
+		sc.RunAsNonRoot = custom.RunAsNonRoot
+	}
+	if len(custom.SupplementalGroups) > 0 {
+		sc.SupplementalGroups = custom.SupplementalGroups
+	}
+	if custom.FSGroup != nil {
+		sc.FSGroup = custom.FSGroup
+	}
+	if custom.SeccompProfile != nil {
+		sc.SeccompProfile = custom.SeccompProfile.DeepCopy()
+	}
+	return sc
+}
+
 func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.Node, logger logr.Logger) *corev1.Pod {
 	mode := int32(0744)
 
@@ -82,7 +140,7 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 	trueP := true
 	hostToContainer := corev1.MountPropagationHostToContainer
 
-	return &corev1.Pod{
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
 			Namespace: common.GetComplianceOperatorNamespace(),
@@ -93,6 +151,7 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 		},
 		Spec: corev1.PodSpec{
 			ServiceAccountName: resultscollectorSA,
+			SecurityContext:    scannerSecurityContext(scanInstance, &corev1.PodSecurityContext{}),
 			PriorityClassName:  scanInstance.Spec.PriorityClass,
 			InitContainers: []corev1.Container{
 				{
@@ -216,7 +275,7 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 				},
 				{
 					Name:    OpenSCAPScanContainerName,
-					Image:   utils.GetComponentImage(utils.OPENSCAP),
+					Image:   getScannerImage(&scanInstance.Spec),
 					Command: []string{OpenScapScriptPath},
 					SecurityContext: &corev1.SecurityContext{
 						Privileged:             &trueVal,
@@ -224,15 +283,9 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 						// TODO(jaosorior): Figure out if the default
 						// seccomp profile is sufficient here.
 					},
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceMemory: resource.MustParse("50Mi"),
-							corev1.ResourceCPU:    resource.MustParse("10m"),
-						},
-						// NOTE: when changing the default limits, remember to also change the
-						// doc text in the CRD.
-						Limits: *scanLimits(scanInstance, "500Mi", "100m"),
-					},
+					// NOTE: when changing the default limits, remember to also change the
+					// doc text in the CRD.
+					Resources: scannerResources(scanInstance, "500Mi", "100m"),
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:             "host",
@@ -285,7 +338,7 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 					},
 				},
 			},
-			Tolerations: scanInstance.Spec.ScanTolerations,
+			Tolerations: scanInstance.Spec.ScannerScheduling.GetTolerations(scanInstance.Spec.ScanTolerations),
 			NodeSelector: map[string]string{
 				corev1.LabelHostname: node.Labels[corev1.LabelHostname],
 			},
@@ -353,6 +406,28 @@ func newScanPodForNode(scanInstance *compv1alpha1.ComplianceScan, node *corev1.N
 			},
 		},
 	}
+
+	if scanInstance.Spec.Debug {
+		addScannerOutputCollection(pod, cmName)
+	}
+
+	if scanInstance.Spec.FailureEvidence != nil {
+		addFailureEvidenceCollection(scanInstance.Spec.FailureEvidence, pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.Compression != "" {
+		addRawResultCompression(&scanInstance.Spec.RawResultStorage, pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.DiscardsRawResults() {
+		addRawResultDiscarding(pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.GeneratesHTMLReports() {
+		addHTMLReportCollection(pod)
+	}
+
+	return pod
 }
 
 func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.ComplianceScan, logger logr.Logger) *corev1.Pod {
@@ -385,7 +460,22 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 		collectorCmd = append(collectorCmd, "--debug")
 	}
 
-	return &corev1.Pod{
+	if scanInstance.Spec.Incremental {
+		collectorCmd = append(collectorCmd,
+			"--input-hashes-file=/reports/input_hashes",
+			"--previous-input-hashes-file="+path.Join(InputHashesDir, utils.InputHashesKey),
+			"--changed-rules-file=/reports/changed_rules",
+		)
+	}
+
+	if len(scanInstance.Spec.CustomRules) > 0 {
+		collectorCmd = append(collectorCmd,
+			"--custom-rules="+strings.Join(scanInstance.Spec.CustomRules, ","),
+			"--custom-rule-results-file="+customRuleResultsFile,
+		)
+	}
+
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
 			Namespace: common.GetComplianceOperatorNamespace(),
@@ -396,9 +486,9 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 		},
 		Spec: corev1.PodSpec{
 			ServiceAccountName: apiResourceCollectorSA,
-			SecurityContext: &corev1.PodSecurityContext{
+			SecurityContext: scannerSecurityContext(scanInstance, &corev1.PodSecurityContext{
 				RunAsNonRoot: &trueP,
-			},
+			}),
 			PriorityClassName: scanInstance.Spec.PriorityClass,
 			InitContainers: []corev1.Container{
 				{
@@ -542,7 +632,7 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 				},
 				{
 					Name:    OpenSCAPScanContainerName,
-					Image:   utils.GetComponentImage(utils.OPENSCAP),
+					Image:   getScannerImage(&scanInstance.Spec),
 					Command: []string{OpenScapScriptPath},
 					SecurityContext: &corev1.SecurityContext{
 						AllowPrivilegeEscalation: &falseP,
@@ -551,15 +641,9 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 							Drop: []corev1.Capability{"ALL"},
 						},
 					},
-					Resources: corev1.ResourceRequirements{
-						Requests: corev1.ResourceList{
-							corev1.ResourceMemory: resource.MustParse("50Mi"),
-							corev1.ResourceCPU:    resource.MustParse("10m"),
-						},
-						// NOTE: when changing the default limits, remember to also change the
-						// doc text in the CRD.
-						Limits: *scanLimits(scanInstance, "500Mi", "100m"),
-					},
+					// NOTE: when changing the default limits, remember to also change the
+					// doc text in the CRD.
+					Resources: scannerResources(scanInstance, "500Mi", "100m"),
 					VolumeMounts: []corev1.VolumeMount{
 						{
 							Name:      "report-dir",
@@ -595,9 +679,11 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 					},
 				},
 			},
-			NodeSelector:  r.schedulingInfo.Selector,
-			Tolerations:   r.schedulingInfo.Tolerations,
-			RestartPolicy: corev1.RestartPolicyOnFailure,
+			NodeSelector:              scanInstance.Spec.ScannerScheduling.GetNodeSelector(r.schedulingInfo.Selector),
+			Tolerations:               scanInstance.Spec.ScannerScheduling.GetTolerations(r.schedulingInfo.Tolerations),
+			Affinity:                  scanInstance.Spec.ScannerScheduling.GetAffinity(),
+			TopologySpreadConstraints: scanInstance.Spec.ScannerScheduling.GetTopologySpreadConstraints(),
+			RestartPolicy:             corev1.RestartPolicyOnFailure,
 			Volumes: []corev1.Volume{
 				{
 					Name: "report-dir",
@@ -645,6 +731,160 @@ func (r *ReconcileComplianceScan) newPlatformScanPod(scanInstance *compv1alpha1.
 			},
 		},
 	}
+
+	if scanInstance.Spec.Incremental {
+		addInputHashesVolume(scanInstance, pod)
+	}
+
+	if scanInstance.Spec.Debug {
+		addScannerOutputCollection(pod, cmName)
+	}
+
+	if len(scanInstance.Spec.CustomRules) > 0 {
+		addCustomRuleResultsCollection(pod)
+	}
+
+	if scanInstance.Spec.FailureEvidence != nil {
+		addFailureEvidenceCollection(scanInstance.Spec.FailureEvidence, pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.Compression != "" {
+		addRawResultCompression(&scanInstance.Spec.RawResultStorage, pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.DiscardsRawResults() {
+		addRawResultDiscarding(pod)
+	}
+
+	if scanInstance.Spec.RawResultStorage.GeneratesHTMLReports() {
+		addHTMLReportCollection(pod)
+	}
+
+	return pod
+}
+
+// addScannerOutputCollection has the result collector of a scan pod keep
+// the output of the scanner in a ConfigMap, so that it's available for
+// debugging even if the scan succeeded
+func addScannerOutputCollection(pod *corev1.Pod, cmName string) {
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command,
+			"--scanner-output-config-map="+utils.GetScannerOutputConfigMapName(cmName))
+	}
+}
+
+// addCustomRuleResultsCollection has the result collector of a platform scan
+// pod upload the results of the custom rules along with the results
+func addCustomRuleResultsCollection(pod *corev1.Pod) {
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, "--custom-rule-results-file="+customRuleResultsFile)
+	}
+}
+
+// addFailureEvidenceCollection has the result collector of a scan pod upload
+// the evidence of the failing checks along with the results
+func addFailureEvidenceCollection(settings *compv1alpha1.FailureEvidenceSettings, pod *corev1.Pod) {
+	maxSize := settings.MaxSize
+	if maxSize <= 0 {
+		maxSize = utils.DefaultFailureEvidenceMaxSize
+	}
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, fmt.Sprintf("--failure-evidence-max-size=%d", maxSize))
+		if len(settings.RedactFields) > 0 {
+			container.Command = append(container.Command,
+				"--failure-evidence-redact-fields="+strings.Join(settings.RedactFields, ","))
+		}
+	}
+}
+
+// addRawResultCompression has the result collector of a scan pod compress
+// the raw results with the codec of the scan
+func addRawResultCompression(settings *compv1alpha1.RawResultStorageSettings, pod *corev1.Pod) {
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, "--compression="+string(settings.Compression))
+		if settings.CompressionLevel > 0 {
+			container.Command = append(container.Command, fmt.Sprintf("--compression-level=%d", settings.CompressionLevel))
+		}
+	}
+}
+
+// addRawResultDiscarding has the result collector of a scan pod discard the
+// ARF report instead of uploading it to the result server
+func addRawResultDiscarding(pod *corev1.Pod) {
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, "--discard-arf")
+	}
+}
+
+// addHTMLReportCollection has the result collector of a scan pod upload the
+// HTML report along with the ARF report
+func addHTMLReportCollection(pod *corev1.Pod) {
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, "--html-report-file=/reports/report.html")
+	}
+}
+
+// addInputHashesVolume makes the hashes from the previous run of an
+// incremental scan available to the resource collector, and has the
+// result collector upload the new ones along with the results
+func addInputHashesVolume(scanInstance *compv1alpha1.ComplianceScan, pod *corev1.Pod) {
+	optional := true
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: "input-hashes",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: utils.GetInputHashesConfigMapName(scanInstance.Name),
+				},
+				// There are no hashes on the first run
+				Optional: &optional,
+			},
+		},
+	})
+
+	for idx := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[idx]
+		if container.Name != PlatformScanResourceCollectorName {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      "input-hashes",
+			MountPath: InputHashesDir,
+			ReadOnly:  true,
+		})
+	}
+
+	for idx := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[idx]
+		if container.Name != "log-collector" {
+			continue
+		}
+		container.Command = append(container.Command, "--input-hashes-file=/reports/input_hashes")
+	}
 }
 
 func (r *ReconcileComplianceScan) deleteScanPods(instance *compv1alpha1.ComplianceScan, nodes []corev1.Node, logger logr.Logger) error {
@@ -782,7 +1022,7 @@ func (r *ReconcileComplianceScan) reconcileReplicatedTailoringConfigMap(scan *co
 			scanCopy := scan.DeepCopy()
 			scanCopy.Status.ErrorMessage = tailoringNotFoundPrefix + err.Error()
 			scanCopy.Status.Result = compv1alpha1.ResultError
-			if updateerr := r.Client.Status().Update(context.TODO(), scanCopy); updateerr != nil {
+			if updateerr := r.updateStatus(scanCopy); updateerr != nil {
 				log.Error(updateerr, "Failed to update a scan")
 				return reconcile.Result{}, updateerr
 			}
@@ -799,7 +1039,7 @@ func (r *ReconcileComplianceScan) reconcileReplicatedTailoringConfigMap(scan *co
 				scanCopy := scan.DeepCopy()
 				scanCopy.Status.ErrorMessage = ""
 				scanCopy.Status.Result = compv1alpha1.ResultNotAvailable
-				if updateerr := r.Client.Status().Update(context.TODO(), scanCopy); updateerr != nil {
+				if updateerr := r.updateStatus(scanCopy); updateerr != nil {
 					log.Error(updateerr, "Failed to update a scan")
 					return reconcile.Result{}, updateerr
 				}
@@ -870,7 +1110,8 @@ func checkScanUnknownError(cm *corev1.ConfigMap) error {
 		return fmt.Errorf("the ConfigMap '%s' was missing 'exit-code'", cm.Name)
 	}
 
-	if exitcode != common.OpenSCAPExitCodeCompliant && exitcode != common.OpenSCAPExitCodeNonCompliant && exitcode != common.PodUnschedulableExitCode {
+	if exitcode != common.OpenSCAPExitCodeCompliant && exitcode != common.OpenSCAPExitCodeNonCompliant &&
+		exitcode != common.PodUnschedulableExitCode && exitcode != common.OpenSCAPExitCodeUnchanged {
 		errorMsg, ok := cm.Data["error-msg"]
 		if ok {
 			return fmt.Errorf(errorMsg)
@@ -4,6 +4,7 @@ import (
 	"context"
 	goerrors "errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -13,7 +14,6 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -86,13 +86,9 @@ func (nh *nodeScanTypeHandler) getTargetNodes() ([]corev1.Node, error) {
 	case compv1alpha1.ScanTypePlatform:
 		return nodes.Items, nil // Nodes are only relevant to the node scan type. Return the empty node list otherwise.
 	case compv1alpha1.ScanTypeNode:
-		// we only scan Linux nodes
-		nodeScanSelector := map[string]string{"kubernetes.io/os": "linux"}
-		listOpts := client.ListOptions{
-			LabelSelector: labels.SelectorFromSet(labels.Merge(nh.scan.Spec.NodeSelector, nodeScanSelector)),
-		}
-
-		if err := nh.r.Client.List(context.TODO(), &nodes, &listOpts); err != nil {
+		var err error
+		nodes, err = nh.r.getNodesForScan(nh.scan)
+		if err != nil {
 			return nodes.Items, err
 		}
 	}
@@ -103,6 +99,9 @@ func (nh *nodeScanTypeHandler) getTargetNodes() ([]corev1.Node, error) {
 func (nh *nodeScanTypeHandler) validate() (bool, error) {
 	if len(nh.nodes) == 0 {
 		warning := "No nodes matched the nodeSelector"
+		if len(nh.scan.Spec.NodeNames) > 0 {
+			warning = "No nodes matched the nodeSelector and nodeNames"
+		}
 		nh.l.Info(warning)
 		nh.r.Recorder.Event(nh.scan, corev1.EventTypeWarning, "NoMatchingNodes", warning)
 		instanceCopy := nh.scan.DeepCopy()
@@ -136,10 +135,160 @@ func (nh *nodeScanTypeHandler) validate() (bool, error) {
 	return true, nil
 }
 
-func (nh *nodeScanTypeHandler) createScanWorkload() error {
-	// On each eligible node..
+// getScannedNodes returns the nodes that are scanned in the current run. If
+// a single node is being rescanned, the rest of the nodes keep the results of
+// the previous run and don't need a scan pod.
+func (nh *nodeScanTypeHandler) getScannedNodes() []*corev1.Node {
+	nodes := make([]*corev1.Node, 0, len(nh.nodes))
 	for idx := range nh.nodes {
 		node := &nh.nodes[idx]
+		if nh.scan.Status.RescanNode != "" && node.Name != nh.scan.Status.RescanNode {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// isNodeCompleted returns whether the results of the node were already
+// collected in the current run of the scan
+func (nh *nodeScanTypeHandler) isNodeCompleted(nodeName string) bool {
+	for _, completed := range nh.scan.Status.CompletedNodes {
+		if completed == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
+// checkpointCompletedNodes records the nodes whose result ConfigMap exists
+// in the scan status, so that the scan can be resumed without having to
+// re-scan them, e.g. if their scan pods are gone after an operator restart.
+func (nh *nodeScanTypeHandler) checkpointCompletedNodes() error {
+	var completed []string
+	for _, node := range nh.getScannedNodes() {
+		if nh.isNodeCompleted(node.Name) {
+			continue
+		}
+		_, err := getNodeScanCM(nh.r, nh.scan, node.Name)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		completed = append(completed, node.Name)
+	}
+	if len(completed) == 0 {
+		return nil
+	}
+
+	nh.l.Info("Recording the nodes whose results were collected", "nodes", completed)
+	nh.scan.Status.CompletedNodes = append(nh.scan.Status.CompletedNodes, completed...)
+	if err := nh.r.updateStatus(nh.scan); err != nil {
+		return err
+	}
+	if nh.r.Recorder != nil {
+		for _, nodeName := range completed {
+			nh.r.Recorder.Eventf(nh.scan, corev1.EventTypeNormal, "NodeScanCompleted",
+				"The scan finished on node %s", nodeName)
+		}
+	}
+	return nil
+}
+
+// nodeScanProgress describes the state of the scan pods of a node scan
+type nodeScanProgress struct {
+	// pending are the nodes that don't have a scan pod yet
+	pending []*corev1.Node
+	// active is the number of scan pods that haven't finished yet
+	active int
+	// done is the number of scan pods that finished
+	done int
+	// nodes is the phase of the scan in each node
+	nodes []compv1alpha1.NodeScanProgress
+}
+
+// toStatus converts the progress into the one reported in the scan status
+func (p *nodeScanProgress) toStatus() *compv1alpha1.ScanProgress {
+	total := len(p.nodes)
+	return &compv1alpha1.ScanProgress{
+		NodesTotal:     total,
+		NodesCompleted: p.done,
+		Summary:        fmt.Sprintf("%d/%d", p.done, total),
+		Nodes:          p.nodes,
+	}
+}
+
+// getProgress lists the scan pods of the scan and sorts the target nodes by
+// the state of their pod
+func (nh *nodeScanTypeHandler) getProgress() (*nodeScanProgress, error) {
+	var pods corev1.PodList
+	listOpts := []client.ListOption{
+		client.InNamespace(common.GetComplianceOperatorNamespace()),
+		client.MatchingLabels{compv1alpha1.ComplianceScanLabel: nh.scan.Name},
+	}
+	if err := nh.r.Client.List(context.TODO(), &pods, listOpts...); err != nil {
+		return nil, err
+	}
+
+	podsByName := make(map[string]*corev1.Pod, len(pods.Items))
+	for idx := range pods.Items {
+		podsByName[pods.Items[idx].Name] = &pods.Items[idx]
+	}
+
+	progress := &nodeScanProgress{}
+	for _, node := range nh.getScannedNodes() {
+		phase := compv1alpha1.NodeScanPhaseWaiting
+		if nh.isNodeCompleted(node.Name) {
+			// The pod might be gone already, but the results are there
+			phase = compv1alpha1.NodeScanPhaseDone
+		} else if pod, ok := podsByName[getPodForNodeName(nh.scan.Name, node.Name)]; ok {
+			phase = getNodeScanPhase(pod)
+		}
+		if phase == compv1alpha1.NodeScanPhaseWaiting {
+			progress.pending = append(progress.pending, node)
+		} else if phase.IsFinished() {
+			progress.done++
+		} else {
+			progress.active++
+		}
+		progress.nodes = append(progress.nodes, compv1alpha1.NodeScanProgress{
+			Node:  node.Name,
+			Phase: phase,
+		})
+	}
+	return progress, nil
+}
+
+func (nh *nodeScanTypeHandler) createScanWorkload() error {
+	nodes := make([]*corev1.Node, 0, len(nh.nodes))
+	if limit := nh.scan.Spec.MaxConcurrentNodes; limit > 0 {
+		progress, err := nh.getProgress()
+		if err != nil {
+			return err
+		}
+		// Only fill up the free slots, the rest of the nodes are launched
+		// as the running pods finish
+		free := limit - progress.active
+		if free < 0 {
+			free = 0
+		}
+		if free > len(progress.pending) {
+			free = len(progress.pending)
+		}
+		nodes = progress.pending[:free]
+		nh.l.Info("Limiting the amount of nodes scanned at the same time", "MaxConcurrentNodes", limit,
+			"Launching", len(nodes), "Waiting", len(progress.pending)-len(nodes))
+	} else {
+		for _, node := range nh.getScannedNodes() {
+			if !nh.isNodeCompleted(node.Name) {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	// On each eligible node..
+	for _, node := range nodes {
 		// ..schedule a pod..
 		nh.l.Info("Creating a pod for node", "Pod.Name", node.Name)
 		pod := newScanPodForNode(nh.scan, node, nh.l)
@@ -168,17 +317,53 @@ func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 			return true, timeoutNodes, fmt.Errorf("couldn't parse timeout: %w", err)
 		}
 	}
-	for idx := range nh.nodes {
-		node := &nh.nodes[idx]
+
+	if err := nh.checkpointCompletedNodes(); err != nil {
+		return true, timeoutNodes, err
+	}
+
+	progress, err := nh.getProgress()
+	if err != nil {
+		return true, timeoutNodes, err
+	}
+	limit := nh.scan.Spec.MaxConcurrentNodes
+	relaunch := limit > 0 && len(progress.pending) > 0 && progress.active < limit
+	if relaunch {
+		nh.l.Info("Phase: Running: There are nodes waiting to be scanned. Going to state LAUNCHING to launch their pods",
+			"compliancescan", nh.scan.ObjectMeta.Name, "waiting", len(progress.pending))
+		nh.scan.Status.Phase = compv1alpha1.PhaseLaunching
+	}
+	status := progress.toStatus()
+	if relaunch || !reflect.DeepEqual(nh.scan.Status.Progress, status) {
+		nh.scan.Status.Progress = status
+		if err := nh.r.updateStatus(nh.scan); err != nil {
+			return true, timeoutNodes, err
+		}
+	}
+	if relaunch {
+		return true, timeoutNodes, nil
+	}
+
+	// Whether the pod of a node that was checked is being retried
+	anyRetrying := false
+	for _, node := range nh.getScannedNodes() {
+		if nh.isNodeCompleted(node.Name) {
+			continue
+		}
 		var unschedulableErr *podUnschedulableError
+		var transientErr *podTransientFailureError
 		var timeoutErr *common.TimeoutError
 		running, err := isPodRunningInNode(nh.r, nh.scan, node, timeoutVal, nh.l)
-		if errors.IsNotFound(err) {
+		if errors.IsNotFound(err) && limit > 0 {
+			// All the slots are taken, so the pod will be launched once
+			// one of the running pods finishes
+			return true, timeoutNodes, nil
+		} else if errors.IsNotFound(err) {
 			// Let's go back to the previous state and make sure all the nodes are covered.
 			nh.l.Info("Phase: Running: A pod is missing. Going to state LAUNCHING to make sure we launch it",
 				"compliancescan", nh.scan.ObjectMeta.Name, "node", node.Name)
 			nh.scan.Status.Phase = compv1alpha1.PhaseLaunching
-			err = nh.r.Client.Status().Update(context.TODO(), nh.scan)
+			err = nh.r.updateStatus(nh.scan)
 			if err != nil {
 				return true, timeoutNodes, err
 			}
@@ -205,6 +390,16 @@ func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 
 			// We're good, the CM that tells us about this error is already there
 			// let's continue to check the next pod
+		} else if goerrors.As(err, &transientErr) {
+			cmName := getConfigMapForNodeName(nh.scan.Name, node.Name)
+			retrying, retryErr := nh.r.retryScanPod(nh.scan, transientErr, cmName, node.Name, nh.l)
+			if retryErr != nil {
+				return true, timeoutNodes, retryErr
+			}
+			// The other nodes are still checked, e.g. for pods that
+			// are missing, and a pod that ran out of retries is done
+			anyRetrying = anyRetrying || retrying
+			continue
 		} else if goerrors.As(err, &timeoutErr) {
 			nh.l.Info("Timeout while waiting for the Node scan pod to be finished.")
 			timeoutNodes = append(timeoutNodes, node.Name)
@@ -216,11 +411,13 @@ func (nh *nodeScanTypeHandler) handleRunningScan() (bool, []string, error) {
 			return true, timeoutNodes, nil
 		}
 	}
-	return false, timeoutNodes, nil
+	return anyRetrying, timeoutNodes, nil
 }
 
 func (nh *nodeScanTypeHandler) shouldLaunchAggregator() (bool, string, error) {
 	var warnings string
+	var firstErr error
+	errored := 0
 	for _, node := range nh.nodes {
 		foundCM, err := getNodeScanCM(nh.r, nh.scan, node.Name)
 
@@ -238,15 +435,40 @@ func (nh *nodeScanTypeHandler) shouldLaunchAggregator() (bool, string, error) {
 		// NOTE: err is only set if there is an error in the scan run
 		err = checkScanUnknownError(foundCM)
 		if err != nil {
-			return true, warnings, err
+			if nh.scan.Spec.MaxErroredNodesPercentage == 0 {
+				return true, warnings, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			errored++
 		}
 	}
+	if !nh.toleratesErroredNodes(errored) {
+		return true, warnings, firstErr
+	}
 	return true, warnings, nil
 }
 
+// toleratesErroredNodes tells whether the scan can be computed from the
+// healthy nodes if the scan of the given number of nodes errored out
+func (nh *nodeScanTypeHandler) toleratesErroredNodes(errored int) bool {
+	if errored == 0 {
+		return true
+	}
+	total := len(nh.nodes)
+	// There's nothing left to compute the result from
+	if errored >= total {
+		return false
+	}
+	return errored*100 <= nh.scan.Spec.MaxErroredNodesPercentage*total
+}
+
 func (nh *nodeScanTypeHandler) gatherResults() (compv1alpha1.ComplianceScanStatusResult, bool, error) {
 	var lastNonCompliance compv1alpha1.ComplianceScanStatusResult
 	var result compv1alpha1.ComplianceScanStatusResult
+	var erroredNodes []string
+	var firstErr error
 	compliant := true
 	isReady := true
 
@@ -270,7 +492,21 @@ func (nh *nodeScanTypeHandler) gatherResults() (compv1alpha1.ComplianceScanStatu
 		}
 
 		// NOTE: err is only set if there is an error in the scan run
-		result, err = getScanResult(foundCM)
+		nodeResult, err := getScanResult(foundCM)
+
+		// Nodes whose scan errored out are left out of the result if
+		// the scan tolerates it
+		unschedulable := foundCM.Data["exit-code"] == common.PodUnschedulableExitCode
+		if nodeResult == compv1alpha1.ResultError && nh.scan.Spec.MaxErroredNodesPercentage > 0 &&
+			(nh.getScan().IsStrictNodeScan() || !unschedulable) {
+			nh.l.Info("Node scan error, leaving the node out of the results", "node.Name", node.Name, "errMsg", err)
+			erroredNodes = append(erroredNodes, node.Name)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result = nodeResult
 
 		// we output the last result if it was an error
 		if result == compv1alpha1.ResultError {
@@ -297,6 +533,11 @@ func (nh *nodeScanTypeHandler) gatherResults() (compv1alpha1.ComplianceScanStatu
 		}
 	}
 
+	if !nh.toleratesErroredNodes(len(erroredNodes)) {
+		return compv1alpha1.ResultError, true, firstErr
+	}
+	nh.scan.Status.ErroredNodes = erroredNodes
+
 	if !compliant {
 		return lastNonCompliance, isReady, nil
 	}
@@ -361,6 +602,7 @@ func (ph *platformScanTypeHandler) handleRunningScan() (bool, []string, error) {
 	timeoutVal := podTimeoutDisable
 	var err error
 	timeoutNodes := []string{}
+	var transientErr *podTransientFailureError
 	var timeoutErr *common.TimeoutError
 	if ph.scan.Spec.ComplianceScanSettings.Timeout != "" {
 		timeoutVal, err = time.ParseDuration(ph.scan.Spec.ComplianceScanSettings.Timeout)
@@ -373,11 +615,15 @@ func (ph *platformScanTypeHandler) handleRunningScan() (bool, []string, error) {
 		// Let's go back to the previous state and make sure all the nodes are covered.
 		ph.l.Info("Phase: Running: The platform scan pod is missing. Going to state LAUNCHING to make sure we launch it, compliancescan")
 		ph.scan.Status.Phase = compv1alpha1.PhaseLaunching
-		err = ph.r.Client.Status().Update(context.TODO(), ph.scan)
+		err = ph.r.updateStatus(ph.scan)
 		if err != nil {
 			return true, timeoutNodes, err
 		}
 		return true, timeoutNodes, nil
+	} else if goerrors.As(err, &transientErr) {
+		cmName := getConfigMapForNodeName(ph.scan.Name, PlatformScanName)
+		retrying, retryErr := ph.r.retryScanPod(ph.scan, transientErr, cmName, "", ph.l)
+		return retrying, timeoutNodes, retryErr
 	} else if goerrors.As(err, &timeoutErr) {
 		ph.l.Info("Timeout while waiting for the platform scan pod to be finished.")
 		timeoutNodes = append(timeoutNodes, PlatformScanName)
@@ -48,6 +48,24 @@ func (e *podUnschedulableError) Error() string {
 	return fmt.Sprintf("Couldn't schedule scan pod '%s': %s", e.pod, e.msg)
 }
 
+// newPodTransientFailureError returns an error that tells us that a scan pod
+// failed in a way that re-creating it might fix.
+func newPodTransientFailureError(pod, msg string) error {
+	return &podTransientFailureError{pod, msg}
+}
+
+// podTransientFailureError represents an error that tells us that a scan pod
+// failed due to a transient issue, e.g. the image couldn't be pulled or the
+// node was rebooted while the pod was running
+type podTransientFailureError struct {
+	pod string
+	msg string
+}
+
+func (e *podTransientFailureError) Error() string {
+	return fmt.Sprintf("Scan pod '%s' failed: %s", e.pod, e.msg)
+}
+
 func absContentPath(relContentPath string) string {
 	return path.Join("/content/", relContentPath)
 }
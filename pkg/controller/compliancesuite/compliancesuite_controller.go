@@ -3,6 +3,8 @@ package compliancesuite
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
@@ -16,13 +18,16 @@ import (
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,6 +38,8 @@ var log = logf.Log.WithName("suitectrl")
 const (
 	// The default time we should wait before requeuing
 	requeueAfterDefault = 10 * time.Second
+	// how often the MachineConfigPools are checked for finished updates
+	mcfgPoolPollInterval = time.Minute
 )
 
 func (r *ReconcileComplianceSuite) SetupWithManager(mgr ctrl.Manager) error {
@@ -44,11 +51,16 @@ func (r *ReconcileComplianceSuite) SetupWithManager(mgr ctrl.Manager) error {
 // Add creates a new ComplianceSuite Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, met *metrics.Metrics, si utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
-	return add(mgr, newReconciler(mgr, met, si))
+	notifications := common.NewNotificationQueue("suitectrl", met)
+	if err := mgr.Add(notifications); err != nil {
+		return err
+	}
+	return add(mgr, newReconciler(mgr, met, si, notifications))
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, met *metrics.Metrics, si utils.CtlplaneSchedulingInfo) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, met *metrics.Metrics, si utils.CtlplaneSchedulingInfo,
+	notifications *common.NotificationQueue) reconcile.Reconciler {
 	return &ReconcileComplianceSuite{
 		Reader:         mgr.GetAPIReader(),
 		Client:         mgr.GetClient(),
@@ -56,6 +68,7 @@ func newReconciler(mgr manager.Manager, met *metrics.Metrics, si utils.CtlplaneS
 		Recorder:       mgr.GetEventRecorderFor("suitectrl"),
 		Metrics:        met,
 		schedulingInfo: si,
+		notifications:  notifications,
 	}
 }
 
@@ -65,6 +78,8 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		Named("compliancesuite-controller").
 		For(&compv1alpha1.ComplianceSuite{}).
 		Owns(&compv1alpha1.ComplianceScan{}).
+		Watches(&compv1alpha1.ComplianceCheckResult{}, handler.EnqueueRequestsFromMapFunc(mapCheckResultToSuite),
+			builder.WithPredicates(manualReviewChanged)).
 		Complete(r)
 }
 
@@ -84,6 +99,11 @@ type ReconcileComplianceSuite struct {
 	// helps us schedule platform scans on the nodes labeled for the
 	// compliance operator's control plane
 	schedulingInfo utils.CtlplaneSchedulingInfo
+	// sends the notifications of the suites in the background
+	notifications *common.NotificationQueue
+	// returns the publisher to the Kafka topic of a suite, defaults to
+	// utils.NewKafkaPublisher
+	newKafkaPublisher func(config *utils.KafkaConfig) utils.KafkaPublisher
 }
 
 // Reconcile reads that state of the cluster for a ComplianceSuite object and makes changes based on the state read
@@ -136,7 +156,7 @@ func (r *ReconcileComplianceSuite) Reconcile(ctx context.Context, request reconc
 		if sCopy.Status.ScanStatuses == nil {
 			sCopy.Status.ScanStatuses = make([]compv1alpha1.ComplianceScanStatusWrapper, 0)
 		}
-		updateErr := r.Client.Status().Update(context.TODO(), sCopy)
+		updateErr := r.updateStatus(sCopy)
 		if updateErr != nil {
 			return reconcile.Result{}, fmt.Errorf("Error setting initial status for suite: %w", updateErr)
 		}
@@ -151,13 +171,23 @@ func (r *ReconcileComplianceSuite) Reconcile(ctx context.Context, request reconc
 	if suite.Status.Conditions.GetCondition("Processing") == nil {
 		sCopy := suite.DeepCopy()
 		sCopy.Status.SetConditionsProcessing()
-		updateErr := r.Client.Status().Update(context.TODO(), sCopy)
+		updateErr := r.updateStatus(sCopy)
 		if updateErr != nil {
 			return reconcile.Result{}, fmt.Errorf("Error setting processing status for suite: %w", updateErr)
 		}
 		return reconcile.Result{}, nil
 	}
 
+	if suite.RerunAnnotationSet() {
+		return reconcile.Result{}, r.rerunSuite(suite, reqLogger)
+	}
+
+	if updated, err := r.reconcileManualCheckReviews(suite, reqLogger); err != nil {
+		return common.ReturnWithRetriableError(reqLogger, err)
+	} else if updated {
+		return reconcile.Result{}, nil
+	}
+
 	suiteCopy := suite.DeepCopy()
 	rescheduleWithDelay, err := r.reconcileScans(suiteCopy, reqLogger)
 	if err != nil {
@@ -171,14 +201,40 @@ func (r *ReconcileComplianceSuite) Reconcile(ctx context.Context, request reconc
 		return common.ReturnWithRetriableError(reqLogger, err)
 	}
 
+	if res.IsZero() {
+		if res, err = r.reconcileMachineConfigPoolRescans(suiteCopy, reqLogger); err != nil {
+			return common.ReturnWithRetriableError(reqLogger, err)
+		}
+	}
+
+	if err := r.reconcileRemediationExport(suiteCopy, reqLogger); err != nil {
+		return common.ReturnWithRetriableError(reqLogger, err)
+	}
+
+	if err := r.reconcileResultExport(suiteCopy, reqLogger); err != nil {
+		return common.ReturnWithRetriableError(reqLogger, err)
+	}
+
 	if suiteCopy.IsResultAvailable() {
 		sCopy := suite.DeepCopy()
 		sCopy.Status.SetConditionReady()
-		updateErr := r.Client.Status().Update(context.TODO(), sCopy)
+		updateErr := r.updateStatus(sCopy)
 		if updateErr != nil {
 			return reconcile.Result{}, fmt.Errorf("Error setting ready status for suite: %w", updateErr)
 		}
-		return res, r.reconcileScanRerunnerCronJob(suiteCopy, reqLogger)
+		if err := r.reconcileScanRerunnerCronJob(suiteCopy, reqLogger); err != nil {
+			return res, err
+		}
+		r.reconcileInsightsSummary(sCopy, reqLogger)
+		// These are done last. The webhooks are called and the results are
+		// forwarded in the background, which records their failures rather
+		// than retrying them, so only errors of the API server requeue.
+		if err := r.reconcileWebhooks(sCopy, reqLogger); err != nil {
+			return common.ReturnWithRetriableError(reqLogger, err)
+		}
+		if err := r.reconcileResultForwarding(sCopy, reqLogger); err != nil {
+			return common.ReturnWithRetriableError(reqLogger, err)
+		}
 	}
 
 	return res, nil
@@ -188,6 +244,15 @@ func (r *ReconcileComplianceSuite) suiteDeleteHandler(suite *compv1alpha1.Compli
 	if err := r.handleRerunnerDelete(suite, logger); err != nil {
 		return err
 	}
+	if err := r.deleteAdditionalRerunners(suite, nil, logger); err != nil {
+		return err
+	}
+	// The summary is informational, so it doesn't hold up the deletion
+	if suite.Status.InsightsSummaryShared {
+		if err := r.deleteInsightsSummary(suite); err != nil {
+			logger.Error(err, "Cannot remove the Insights summary of the suite")
+		}
+	}
 
 	suiteCopy := suite.DeepCopy()
 	// remove our finalizer from the list and update it.
@@ -202,9 +267,65 @@ func (r *ReconcileComplianceSuite) validateSuite(suite *compv1alpha1.ComplianceS
 	if isValid, errorMsg := r.validateSchedule(suite); !isValid {
 		return isValid, errorMsg
 	}
+	if isValid, errorMsg := r.validateDependencies(suite); !isValid {
+		return isValid, errorMsg
+	}
+	if isValid, errorMsg := r.validateRemediationApplyWindow(suite); !isValid {
+		return isValid, errorMsg
+	}
+	if isValid, errorMsg := r.validateWebhooks(suite); !isValid {
+		return isValid, errorMsg
+	}
+	return true, ""
+}
+
+// validateDependencies makes sure that the suite doesn't depend on itself,
+// either directly or through the suites it depends on, as it would never run
+func (r *ReconcileComplianceSuite) validateDependencies(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	visited := make(map[string]bool)
+	pending := append([]string{}, suite.Spec.DependsOn...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if name == suite.Name {
+			return false, "The suite depends on itself"
+		}
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		dependency := &compv1alpha1.ComplianceSuite{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: suite.Namespace}, dependency)
+		if err != nil {
+			// Suites that don't exist yet are waited for
+			continue
+		}
+		pending = append(pending, dependency.Spec.DependsOn...)
+	}
 	return true, ""
 }
 
+// dependenciesDone tells whether all the suites the suite depends on are
+// done, so its scans can be launched
+func (r *ReconcileComplianceSuite) dependenciesDone(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (bool, error) {
+	for _, name := range suite.Spec.DependsOn {
+		dependency := &compv1alpha1.ComplianceSuite{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: suite.Namespace}, dependency)
+		if err != nil && errors.IsNotFound(err) {
+			logger.Info("Waiting for a suite this suite depends on to be created", "ComplianceSuite.Name", name)
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if dependency.Status.Phase != compv1alpha1.PhaseDone {
+			logger.Info("Waiting for a suite this suite depends on to be done", "ComplianceSuite.Name", name)
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func (r *ReconcileComplianceSuite) issueValidationError(suite *compv1alpha1.ComplianceSuite, errorMsg string, logger logr.Logger) error {
 	enhancedMessage := fmt.Sprintf("Suite was invalid: %s", errorMsg)
 	logger.Info(enhancedMessage)
@@ -226,22 +347,50 @@ func (r *ReconcileComplianceSuite) issueValidationError(suite *compv1alpha1.Comp
 	if suiteCopy.Status.ScanStatuses == nil {
 		suiteCopy.Status.ScanStatuses = make([]compv1alpha1.ComplianceScanStatusWrapper, 0)
 	}
-	return r.Client.Status().Update(context.TODO(), suiteCopy)
+	return r.updateStatus(suiteCopy)
 }
 
 func (r *ReconcileComplianceSuite) reconcileScans(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (bool, error) {
 	requiredScansNames := make(map[string]bool)
-	for idx := range suite.Spec.Scans {
+	// In serial mode, a scan only runs once all the scans ahead of it
+	// are done
+	serial := suite.RunsScansSerially()
+	waitForPrevious := false
+	// The scans are only launched once the suites this suite depends
+	// on are done
+	checkedDependencies := false
+	waitForDependencies := false
+	for _, idx := range getScanExecutionOrder(suite) {
 		scanWrap := &suite.Spec.Scans[idx]
+		requiredScansNames[scanWrap.Name] = true
 		scan := &compv1alpha1.ComplianceScan{}
 		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: scanWrap.Name, Namespace: suite.Namespace}, scan)
 		if err != nil && errors.IsNotFound(err) {
+			if serial && waitForPrevious {
+				logger.Info("Waiting for the previous scans to finish before launching", "ComplianceScan.Name", scanWrap.Name)
+				continue
+			}
+			if !checkedDependencies {
+				done, err := r.dependenciesDone(suite, logger)
+				if err != nil {
+					return false, err
+				}
+				checkedDependencies = true
+				waitForDependencies = !done
+			}
+			if waitForDependencies {
+				continue
+			}
 			// If the scan was not found, launch it
 			logger.Info("Scan not found, launching..", "ComplianceScan.Name", scanWrap.Name)
 			if err = launchScanForSuite(r, suite, scanWrap, logger); err != nil {
 				return false, err
 			}
 			logger.Info("Scan created", "ComplianceScan.Name", scanWrap.Name)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(suite, corev1.EventTypeNormal, "ScanLaunched", "Launched scan %s", scanWrap.Name)
+			}
+			waitForPrevious = true
 			// No point in reconciling status yet
 			continue
 		} else if err != nil {
@@ -249,8 +398,30 @@ func (r *ReconcileComplianceSuite) reconcileScans(suite *compv1alpha1.Compliance
 			return false, err
 		}
 
+		// Pending re-runs are also started right away if the suite
+		// was switched to parallel execution in the meantime
+		if scan.HasPendingRescan() && (!serial || !waitForPrevious) {
+			logger.Info("Starting pending re-run of scan", "ComplianceScan.Name", scan.Name)
+			delete(scan.Annotations, compv1alpha1.ComplianceScanPendingRescanAnnotation)
+			scan.Annotations[compv1alpha1.ComplianceScanRescanAnnotation] = ""
+			if err := r.Client.Update(context.TODO(), scan); err != nil {
+				return false, err
+			}
+		}
+		if scan.Status.Phase != compv1alpha1.PhaseDone || scan.NeedsRescan() || scan.HasPendingRescan() {
+			waitForPrevious = true
+		}
+
 		// The scan already exists and is up to date, let's just make sure its status is reflected
-		if err := r.reconcileScanStatus(suite, scan, logger); err != nil {
+		scanForStatus := scan
+		if serial && (scan.HasPendingRescan() || scan.NeedsRescan()) {
+			// The scan is waiting to be re-run, so its previous
+			// results shouldn't count as the results of the suite
+			scanForStatus = scan.DeepCopy()
+			scanForStatus.Status.Phase = compv1alpha1.PhasePending
+			scanForStatus.Status.Result = compv1alpha1.ResultNotAvailable
+		}
+		if err := r.reconcileScanStatus(suite, scanForStatus, logger); err != nil {
 			return false, err
 		}
 
@@ -259,7 +430,6 @@ func (r *ReconcileComplianceSuite) reconcileScans(suite *compv1alpha1.Compliance
 		if rescheduleWithDelay || err != nil {
 			return rescheduleWithDelay, err
 		}
-		requiredScansNames[scan.Name] = true
 	}
 
 	// check all the scans owned by the suite and see if they are still in the spec
@@ -285,7 +455,39 @@ func (r *ReconcileComplianceSuite) reconcileScans(suite *compv1alpha1.Compliance
 		}
 	}
 
-	return false, nil
+	return waitForDependencies, nil
+}
+
+// getScanExecutionOrder returns the indexes of the scans of the suite in the
+// order they should run. Serially executed suites run the platform scans
+// first, then the worker node scans, then the master node scans and lastly
+// the rest of them. Otherwise the order of the spec is kept.
+func getScanExecutionOrder(suite *compv1alpha1.ComplianceSuite) []int {
+	order := make([]int, len(suite.Spec.Scans))
+	for idx := range order {
+		order[idx] = idx
+	}
+	if !suite.RunsScansSerially() {
+		return order
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return getScanExecutionPriority(&suite.Spec.Scans[order[i]]) < getScanExecutionPriority(&suite.Spec.Scans[order[j]])
+	})
+	return order
+}
+
+func getScanExecutionPriority(scanWrap *compv1alpha1.ComplianceScanSpecWrapper) int {
+	if strings.EqualFold(string(scanWrap.ScanType), string(compv1alpha1.ScanTypePlatform)) {
+		return 0
+	}
+	switch utils.GetFirstNodeRole(scanWrap.NodeSelector) {
+	case "worker":
+		return 1
+	case "master":
+		return 2
+	}
+	return 3
 }
 
 func (r *ReconcileComplianceSuite) reconcileScanStatus(suite *compv1alpha1.ComplianceSuite, scan *compv1alpha1.ComplianceScan, logger logr.Logger) error {
@@ -350,6 +552,7 @@ func (r *ReconcileComplianceSuite) reconcileScanSpec(scanWrap *compv1alpha1.Comp
 // updates the status of a scan in the compliance suite. Note that the suite that this takes is already a copy, so it's safe to modify
 func (r *ReconcileComplianceSuite) updateScanStatus(suite *compv1alpha1.ComplianceSuite, idx int, scanStatusWrap *compv1alpha1.ComplianceScanStatusWrapper, scan *compv1alpha1.ComplianceScan, logger logr.Logger) error {
 	// if yes, update it, if the status differs
+	previousPhase := scanStatusWrap.Phase
 	if scanStatusWrap.Phase == scan.Status.Phase {
 		logger.Info("Not updating scan, the phase is the same", "ComplianceScan.Name", scanStatusWrap.Name, "ComplianceScan.Phase", scanStatusWrap.Phase)
 		return nil
@@ -361,6 +564,13 @@ func (r *ReconcileComplianceSuite) updateScanStatus(suite *compv1alpha1.Complian
 	suite.Status.ScanStatuses[idx] = modScanStatus
 	suite.Status.Phase = suite.LowestCommonState()
 	suite.Status.Result = suite.LowestCommonResult()
+	suite.Status.ResultCounts = suite.AggregateResultCounts()
+	if err := r.applyManualCheckReviews(suite, logger); err != nil {
+		return err
+	}
+	if err := r.applyComplianceThreshold(suite, logger); err != nil {
+		return err
+	}
 
 	if suite.Status.Result == compv1alpha1.ResultNotApplicable {
 		suite.Status.ErrorMessage = "The suite result is not applicable, please check if you're using the correct platform"
@@ -372,16 +582,90 @@ func (r *ReconcileComplianceSuite) updateScanStatus(suite *compv1alpha1.Complian
 	}
 
 	logger.Info("Updating scan status", "ComplianceScan.Name", modScanStatus.Name, "ComplianceScan.Phase", modScanStatus.Phase)
-	if err := r.Client.Status().Update(context.TODO(), suite); err != nil {
+	if err := r.updateStatus(suite); err != nil {
 		return err
 	}
+	if r.Recorder != nil && modScanStatus.Phase == compv1alpha1.PhaseDone {
+		if modScanStatus.Result == compv1alpha1.ResultError {
+			r.Recorder.Eventf(suite, corev1.EventTypeWarning, "ScanError",
+				"Scan %s errored out: %s", modScanStatus.Name, modScanStatus.ErrorMessage)
+		} else {
+			r.Recorder.Eventf(suite, corev1.EventTypeNormal, "ScanDone",
+				"Scan %s is done, its result is: %s", modScanStatus.Name, modScanStatus.Result)
+		}
+	}
+	r.publishScanLifecycle(suite, previousPhase, &modScanStatus, logger)
 	return r.setSuiteMetric(suite)
 }
 
+// applyComplianceThreshold records how the results of a finished suite
+// compare to its compliance threshold and reports the suite as compliant if
+// enough of its checks passed. Note that the suite is modified in place.
+func (r *ReconcileComplianceSuite) applyComplianceThreshold(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	suite.Status.ComplianceThreshold = nil
+	if suite.Spec.ComplianceThreshold == 0 || suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+	if suite.Status.Result != compv1alpha1.ResultCompliant && suite.Status.Result != compv1alpha1.ResultNonCompliant {
+		return nil
+	}
+
+	passed, err := r.countSuiteCheckResults(suite, compv1alpha1.CheckResultPass)
+	if err != nil {
+		return err
+	}
+	failed, err := r.countSuiteCheckResults(suite, compv1alpha1.CheckResultFail)
+	if err != nil {
+		return err
+	}
+	// The MANUAL checks that were reviewed count with the outcome of their
+	// review
+	if suite.Status.ManualChecks != nil {
+		passed += suite.Status.ManualChecks.Passed
+		failed += suite.Status.ManualChecks.Failed
+	}
+
+	thresholdStatus := &compv1alpha1.ComplianceThresholdStatus{
+		PassedChecks:   passed,
+		FailedChecks:   failed,
+		PassPercentage: 100,
+	}
+	if passed+failed > 0 {
+		thresholdStatus.PassPercentage = passed * 100 / (passed + failed)
+	}
+	if thresholdStatus.PassPercentage < suite.Spec.ComplianceThreshold {
+		thresholdStatus.Gap = suite.Spec.ComplianceThreshold - thresholdStatus.PassPercentage
+	}
+	suite.Status.ComplianceThreshold = thresholdStatus
+
+	if suite.Status.Result == compv1alpha1.ResultNonCompliant && thresholdStatus.Gap == 0 {
+		logger.Info("The suite meets its compliance threshold, reporting it as compliant",
+			"PassPercentage", thresholdStatus.PassPercentage, "ComplianceThreshold", suite.Spec.ComplianceThreshold)
+		suite.Status.Result = compv1alpha1.ResultCompliant
+	}
+	return nil
+}
+
+func (r *ReconcileComplianceSuite) countSuiteCheckResults(suite *compv1alpha1.ComplianceSuite, status compv1alpha1.ComplianceCheckStatus) (int, error) {
+	var checkList compv1alpha1.ComplianceCheckResultList
+	listOpts := client.MatchingLabels{
+		compv1alpha1.SuiteLabel:                       suite.Name,
+		compv1alpha1.ComplianceCheckResultStatusLabel: string(status),
+	}
+	if err := r.Client.List(context.TODO(), &checkList, client.InNamespace(suite.Namespace), listOpts); err != nil {
+		return 0, err
+	}
+	return len(checkList.Items), nil
+}
+
 func (r *ReconcileComplianceSuite) generateEventsForSuite(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) {
 	logger.Info("Generating events for suite")
 
-	if suite.Status.Result == compv1alpha1.ResultNotApplicable {
+	if suite.Status.Result == compv1alpha1.ResultError {
+		r.Recorder.Eventf(
+			suite, corev1.EventTypeWarning, "SuiteError",
+			"The suite errored out, please check the status of its scans")
+	} else if suite.Status.Result == compv1alpha1.ResultNotApplicable {
 		r.Recorder.Eventf(
 			suite, corev1.EventTypeNormal, "SuiteNotApplicable",
 			"The suite result is not applicable, please check if you're using the correct platform")
@@ -415,9 +699,10 @@ func (r *ReconcileComplianceSuite) addScanStatus(suite *compv1alpha1.ComplianceS
 	logger.Info("Adding scan status", "ComplianceScan.Name", newScanStatus.Name, "ComplianceScan.Phase", newScanStatus.Phase)
 	suite.Status.Phase = suite.LowestCommonState()
 	suite.Status.Result = suite.LowestCommonResult()
-	if err := r.Client.Status().Update(context.TODO(), suite); err != nil {
+	if err := r.updateStatus(suite); err != nil {
 		return err
 	}
+	r.publishScanLifecycle(suite, "", &newScanStatus, logger)
 	return r.setSuiteMetric(suite)
 }
 
@@ -509,6 +794,15 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 		return reconcile.Result{}, nil
 	}
 
+	// Outside of the remediation apply window, the remediations that
+	// weren't applied yet are queued up until it opens
+	windowOpen, windowOpensIn := remediationApplyWindowOpen(suite, time.Now())
+	queued := 0
+
+	// The remediations that are left out by the auto-apply filter or
+	// that are queued up
+	skipped := map[string]bool{}
+
 	// Construct the list of the statuses
 	for _, rem := range remList.Items {
 		// get relevant scan
@@ -525,6 +819,35 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 			continue
 		}
 
+		if !rem.IsApplied() {
+			allowed, err := r.isAllowedToAutoApply(suite, &rem, scan, mcfgpools, logger)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if !allowed {
+				logger.Info("Not applying remediation, it's left out by the auto-apply filter", "ComplianceRemediation.Name", rem.Name)
+				skipped[rem.Name] = true
+				continue
+			}
+			if !windowOpen {
+				logger.Info("Not applying remediation, waiting for the remediation apply window", "ComplianceRemediation.Name", rem.Name)
+				skipped[rem.Name] = true
+				queued++
+				continue
+			}
+			// Remember to verify the remediations applied within the
+			// window once they're all applied
+			if suite.Spec.RemediationApplyWindow != nil && !suite.ApplyRemediationsAnnotationSet() && !suite.VerifyRemediationsAnnotationSet() {
+				if suite.Annotations == nil {
+					suite.Annotations = make(map[string]string)
+				}
+				suite.Annotations[compv1alpha1.VerifyRemediationsAnnotation] = ""
+				if err := r.Client.Update(context.TODO(), suite); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+		}
+
 		if err := r.applyRemediation(rem, suite, scan, mcfgpools, affectedMcfgPools, logger); err != nil {
 			return reconcile.Result{}, err
 		}
@@ -540,8 +863,28 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 	// Check that all remediations have been applied yet. If not, requeue.
 	for _, rem := range postProcessRemList.Items {
 		if !rem.IsApplied() {
+			if skipped[rem.Name] {
+				continue
+			}
 			if rem.Status.ApplicationState == compv1alpha1.RemediationNeedsReview {
-				r.Recorder.Event(suite, corev1.EventTypeWarning, "CannotRemediate", "Remediation needs-review. Values not set"+" Remediation:"+rem.Name)
+				reason := "Values not set"
+				if rem.Status.ErrorMessage != "" {
+					reason = rem.Status.ErrorMessage
+				}
+				r.Recorder.Event(suite, corev1.EventTypeWarning, "CannotRemediate", "Remediation needs-review. "+reason+" Remediation:"+rem.Name)
+				continue
+			}
+			if rem.Status.ApplicationState == compv1alpha1.RemediationConflicting {
+				r.Recorder.Event(suite, corev1.EventTypeWarning, "CannotRemediate", "Remediation conflicting. "+rem.Status.ErrorMessage+" Remediation:"+rem.Name)
+				continue
+			}
+			// Don't keep the pools paused until the remediation is scheduled to be applied
+			if rem.Status.ApplicationState == compv1alpha1.RemediationScheduled {
+				logger.Info("Remediation is scheduled to be applied later. Not waiting for it", "ComplianceRemediation.Name", rem.Name)
+				continue
+			}
+			if rem.Status.ApplicationState == compv1alpha1.RemediationPendingApproval {
+				logger.Info("Remediation needs to be approved. Not waiting for it", "ComplianceRemediation.Name", rem.Name)
 				continue
 			}
 			logger.Info("Remediation not applied yet. Skipping post-processing", "ComplianceRemediation.Name", rem.Name)
@@ -569,7 +912,17 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 		}
 	}
 
-	if suite.ApplyRemediationsAnnotationSet() || suite.RemoveOutdatedAnnotationSet() {
+	res := reconcile.Result{}
+	if queued > 0 {
+		logger.Info("Remediations are queued up until the remediation apply window opens", "queued", queued, "opensIn", windowOpensIn)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(suite, corev1.EventTypeNormal, "RemediationsQueued",
+				"%d remediations will be applied once the remediation apply window opens in %s", queued, windowOpensIn.Round(time.Second))
+		}
+		res = reconcile.Result{Requeue: true, RequeueAfter: windowOpensIn}
+	}
+
+	if suite.ApplyRemediationsAnnotationSet() || suite.RemoveOutdatedAnnotationSet() || suite.VerifyRemediationsAnnotationSet() {
 		suiteCopy := suite.DeepCopy()
 		if suite.ApplyRemediationsAnnotationSet() {
 			delete(suiteCopy.Annotations, compv1alpha1.ApplyRemediationsAnnotation)
@@ -577,10 +930,83 @@ func (r *ReconcileComplianceSuite) reconcileRemediations(suite *compv1alpha1.Com
 		if suite.RemoveOutdatedAnnotationSet() {
 			delete(suiteCopy.Annotations, compv1alpha1.RemoveOutdatedAnnotation)
 		}
+		if suite.VerifyRemediationsAnnotationSet() {
+			// Re-run the suite to verify the remediations that were
+			// applied within the window
+			logger.Info("Remediations applied within the window, re-running the suite to verify them")
+			delete(suiteCopy.Annotations, compv1alpha1.VerifyRemediationsAnnotation)
+			suiteCopy.Annotations[compv1alpha1.RerunAnnotation] = remediationApplyWindowRequester
+		}
 		updateErr := r.Client.Update(context.TODO(), suiteCopy)
-		return reconcile.Result{}, updateErr
+		return res, updateErr
 	}
-	return reconcile.Result{}, nil
+	return res, nil
+}
+
+// isAllowedToAutoApply tells whether the remediation passes the auto-apply
+// filter of the suite, based on the check it was generated from, and whether
+// its pool is one of the pools the remediations are auto-applied to. These
+// only restrict the remediations that are applied because the suite has
+// autoApplyRemediations set.
+func (r *ReconcileComplianceSuite) isAllowedToAutoApply(suite *compv1alpha1.ComplianceSuite, rem *compv1alpha1.ComplianceRemediation,
+	scan *compv1alpha1.ComplianceScan, mcfgpools *mcfgv1.MachineConfigPoolList, logger logr.Logger) (bool, error) {
+	if !suite.Spec.AutoApplyRemediations || suite.ApplyRemediationsAnnotationSet() {
+		return true, nil
+	}
+	if !r.poolAllowsAutoApply(suite, rem, scan, mcfgpools) {
+		logger.Info("The pool of the remediation isn't one of the pools remediations are applied automatically to",
+			"ComplianceRemediation.Name", rem.Name)
+		return false, nil
+	}
+	filter := suite.Spec.AutoApplyRemediationsFilter
+	if filter == nil {
+		return true, nil
+	}
+	return r.remediationPassesFilter(filter, rem, logger)
+}
+
+// poolAllowsAutoApply tells whether the remediation is one of a pool the
+// suite applies remediations to automatically. Remediations that aren't
+// applied per pool are always allowed.
+func (r *ReconcileComplianceSuite) poolAllowsAutoApply(suite *compv1alpha1.ComplianceSuite, rem *compv1alpha1.ComplianceRemediation,
+	scan *compv1alpha1.ComplianceScan, mcfgpools *mcfgv1.MachineConfigPoolList) bool {
+	if len(suite.Spec.AutoApplyRemediationsPools) == 0 {
+		return true
+	}
+	if !utils.IsMachineConfig(rem.Spec.Current.Object) && !utils.IsKubeletConfig(rem.Spec.Current.Object) {
+		return true
+	}
+	pool := r.getAffectedMcfgPool(scan, mcfgpools)
+	if pool == nil {
+		// Not applied to any pool anyway
+		return true
+	}
+	for _, poolName := range suite.Spec.AutoApplyRemediationsPools {
+		if poolName == pool.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// remediationPassesFilter tells whether the remediation passes the filter,
+// based on the check it was generated from
+func (r *ReconcileComplianceSuite) remediationPassesFilter(filter *compv1alpha1.RemediationApplyFilter, rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) (bool, error) {
+	// Remediations are owned by the check they were generated from
+	checkName := rem.Name
+	if owner := metav1.GetControllerOf(rem); owner != nil && owner.Kind == "ComplianceCheckResult" {
+		checkName = owner.Name
+	}
+	check := &compv1alpha1.ComplianceCheckResult{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: checkName, Namespace: rem.Namespace}, check)
+	if errors.IsNotFound(err) {
+		logger.Info("Cannot find the check of the remediation to filter it", "ComplianceRemediation.Name", rem.Name)
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return filter.Allows(check.Severity, check.Annotations[compv1alpha1.ComplianceCheckResultRuleAnnotation]), nil
 }
 
 func (r *ReconcileComplianceSuite) applyRemediation(rem compv1alpha1.ComplianceRemediation,
@@ -658,6 +1084,140 @@ func (r *ReconcileComplianceSuite) applyMcfgRemediationAndPausePool(rem compv1al
 	return nil
 }
 
+// rerunSuite marks all the scans of the suite for a re-run and records the
+// request in the status of the suite. The annotation is removed before the
+// status is updated so that a failed status update never re-runs the scans
+// twice.
+func (r *ReconcileComplianceSuite) rerunSuite(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	// Just like with scheduled re-runs, the scans of serially executed
+	// suites are only marked for re-running and get started one after
+	// another
+	rescanAnnotation := compv1alpha1.ComplianceScanRescanAnnotation
+	if suite.RunsScansSerially() {
+		rescanAnnotation = compv1alpha1.ComplianceScanPendingRescanAnnotation
+	}
+
+	for idx := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: suite.Spec.Scans[idx].Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); errors.IsNotFound(err) {
+			// The scan will run anyway once it's launched
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if _, ok := scan.Annotations[rescanAnnotation]; ok {
+			continue
+		}
+		scanCopy := scan.DeepCopy()
+		if scanCopy.Annotations == nil {
+			scanCopy.Annotations = make(map[string]string)
+		}
+		scanCopy.Annotations[rescanAnnotation] = ""
+		logger.Info("Re-running scan on request", "ComplianceScan.Name", scan.Name)
+		if err := r.Client.Update(context.TODO(), scanCopy); err != nil {
+			return err
+		}
+	}
+
+	triggeredBy := getRerunRequester(suite)
+	suiteCopy := suite.DeepCopy()
+	delete(suiteCopy.Annotations, compv1alpha1.RerunAnnotation)
+	delete(suiteCopy.Annotations, compv1alpha1.RerunRequesterAnnotation)
+	if err := r.Client.Update(context.TODO(), suiteCopy); err != nil {
+		return err
+	}
+
+	suiteCopy.Status.LastRerun = &compv1alpha1.ComplianceSuiteRerunStatus{
+		TriggeredBy: triggeredBy,
+		TriggeredAt: metav1.Now(),
+		Reason:      suite.Annotations[compv1alpha1.RerunAnnotation],
+	}
+	if err := r.updateStatus(suiteCopy); err != nil {
+		return err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(suite, corev1.EventTypeNormal, "SuiteRerun", "The suite was re-run by %s", triggeredBy)
+	}
+	return nil
+}
+
+// getRerunRequester returns who asked for the suite to be re-run, as set by
+// the webhook. Without the webhook, anyone requesting a re-run could claim to
+// be someone else, so the requester is unknown.
+func getRerunRequester(suite *compv1alpha1.ComplianceSuite) string {
+	if !rerunRequesterWebhookServed.Load() {
+		return "unknown"
+	}
+	if requester := suite.Annotations[compv1alpha1.RerunRequesterAnnotation]; requester != "" {
+		return requester
+	}
+	return "unknown"
+}
+
+// reconcileMachineConfigPoolRescans re-runs the node scans of the suite once
+// the MachineConfigPool of the scanned nodes finishes rolling out a new
+// rendered MachineConfig. The pools are polled rather than watched, as the
+// operator might run on clusters without MachineConfigPools.
+func (r *ReconcileComplianceSuite) reconcileMachineConfigPoolRescans(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (reconcile.Result, error) {
+	if !suite.Spec.RescanOnMachineConfigPoolUpdate {
+		return reconcile.Result{}, nil
+	}
+
+	mcfgpools := &mcfgv1.MachineConfigPoolList{}
+	if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
+		logger.Error(err, "Failed to list pools")
+		return reconcile.Result{}, err
+	}
+
+	for idx := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: suite.Spec.Scans[idx].Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if scan.GetScanType() != compv1alpha1.ScanTypeNode || scan.Status.Phase != compv1alpha1.PhaseDone || scan.NeedsRescan() {
+			continue
+		}
+
+		pool := r.getAffectedMcfgPool(scan, mcfgpools)
+		if pool == nil || !mcfgv1.IsMachineConfigPoolConditionTrue(pool.Status.Conditions, mcfgv1.MachineConfigPoolUpdated) {
+			continue
+		}
+
+		rendered := pool.Status.Configuration.Name
+		scanned, found := scan.Annotations[compv1alpha1.ComplianceScanMachineConfigAnnotation]
+		if rendered == "" || scanned == rendered {
+			continue
+		}
+
+		scanCopy := scan.DeepCopy()
+		if scanCopy.Annotations == nil {
+			scanCopy.Annotations = make(map[string]string)
+		}
+		scanCopy.Annotations[compv1alpha1.ComplianceScanMachineConfigAnnotation] = rendered
+		// The first time we only record the configuration the nodes
+		// were scanned with
+		if found {
+			logger.Info("MachineConfigPool finished updating, re-running scan",
+				"MachineConfigPool.Name", pool.Name, "ComplianceScan.Name", scan.Name)
+			scanCopy.Annotations[compv1alpha1.ComplianceScanRescanAnnotation] = ""
+			r.Recorder.Eventf(
+				suite, corev1.EventTypeNormal, "MachineConfigPoolUpdated",
+				"MachineConfigPool %s finished updating to %s, re-running scan %s", pool.Name, rendered, scan.Name)
+		}
+		if err := r.Client.Update(context.TODO(), scanCopy); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{RequeueAfter: mcfgPoolPollInterval}, nil
+}
+
 func (r *ReconcileComplianceSuite) getAffectedMcfgPool(scan *compv1alpha1.ComplianceScan, mcfgpools *mcfgv1.MachineConfigPoolList) *mcfgv1.MachineConfigPool {
 	for i := range mcfgpools.Items {
 		pool := &mcfgpools.Items[i]
@@ -684,3 +1244,10 @@ func (r *ReconcileComplianceSuite) setSuiteMetric(suite *compv1alpha1.Compliance
 	}
 	return nil
 }
+
+// updateStatus updates the status of the suite, along with the generation of
+// the suite it was updated for
+func (r *ReconcileComplianceSuite) updateStatus(suite *compv1alpha1.ComplianceSuite) error {
+	suite.Status.SetObservedGeneration(suite.Generation)
+	return r.Client.Status().Update(context.TODO(), suite)
+}
@@ -3,11 +3,22 @@ package compliancesuite
 import (
 	"context"
 	"encoding/json"
-
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics/metricsfakes"
 
 	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
 	. "github.com/onsi/ginkgo"
@@ -15,18 +26,46 @@ import (
 	mcfgapi "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io"
 	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 	"go.uber.org/zap"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 )
 
+// fakeKafkaPublisher keeps the messages it's given instead of publishing them
+type fakeKafkaPublisher struct {
+	published *publishedMessages
+}
+
+func (p *fakeKafkaPublisher) Publish(_ context.Context, messages []utils.KafkaMessage) error {
+	p.published.Lock()
+	defer p.published.Unlock()
+	p.published.messages = append(p.published.messages, messages...)
+	return nil
+}
+
+// publishedMessages are the messages given to the fake publishers, which are
+// given the messages in the background
+type publishedMessages struct {
+	sync.Mutex
+	messages []utils.KafkaMessage
+}
+
+func (p *publishedMessages) get() []utils.KafkaMessage {
+	p.Lock()
+	defer p.Unlock()
+	return append([]utils.KafkaMessage{}, p.messages...)
+}
+
 var _ = Describe("ComplianceSuiteController", func() {
 	var (
 		suite              *compv1alpha1.ComplianceSuite
@@ -104,7 +143,8 @@ var _ = Describe("ComplianceSuiteController", func() {
 		err = mockMetrics.Register()
 		Expect(err).To(BeNil())
 
-		reconciler = &ReconcileComplianceSuite{Reader: client, Client: client, Scheme: cscheme, Metrics: mockMetrics}
+		reconciler = &ReconcileComplianceSuite{Reader: client, Client: client, Scheme: cscheme, Metrics: mockMetrics,
+			notifications: common.NewNotificationQueue("test", mockMetrics)}
 		zaplog, _ := zap.NewDevelopment()
 		logger = zapr.NewLogger(zaplog)
 	})
@@ -166,6 +206,348 @@ var _ = Describe("ComplianceSuiteController", func() {
 		Expect(rem.Spec.Outdated.Object).To(BeNil())
 	}
 
+	reconcileAndGetExport := func(export *compv1alpha1.RemediationExport) *corev1.ConfigMap {
+		reconciler.Recorder = record.NewFakeRecorder(10)
+		suite.Spec.RemediationExport = export
+		err := reconciler.reconcileRemediationExport(suite, logger)
+		Expect(err).To(BeNil())
+
+		cm := &corev1.ConfigMap{}
+		err = reconciler.Client.Get(ctx, types.NamespacedName{Name: export.ConfigMapName, Namespace: namespace}, cm)
+		Expect(err).To(BeNil())
+		return cm
+	}
+
+	Context("With a result export", func() {
+		newCheck := func(name, ruleID string, status compv1alpha1.ComplianceCheckStatus) *compv1alpha1.ComplianceCheckResult {
+			return &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels: map[string]string{
+						compv1alpha1.SuiteLabel:          suiteName,
+						compv1alpha1.ComplianceScanLabel: "testScanNode",
+					},
+				},
+				ID:          ruleID,
+				Status:      status,
+				Severity:    compv1alpha1.CheckResultSeverityMedium,
+				Description: "Title of " + name + "\nWhat the check does",
+			}
+		}
+		newRule := func(name, ruleID string, controls ...string) *compv1alpha1.Rule {
+			return &compv1alpha1.Rule{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				RulePayload: compv1alpha1.RulePayload{
+					ID:       ruleID,
+					Controls: []compv1alpha1.RuleControls{{Standard: "NIST-800-53", Controls: controls}},
+				},
+			}
+		}
+		reconcileAndGetResults := func() map[string]interface{} {
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			suite.Spec.ResultExport = &compv1alpha1.ResultExport{ConfigMapName: "exported-results"}
+			err := reconciler.reconcileResultExport(suite, logger)
+			Expect(err).To(BeNil())
+
+			cm := &corev1.ConfigMap{}
+			err = reconciler.Client.Get(ctx, types.NamespacedName{Name: "exported-results", Namespace: namespace}, cm)
+			Expect(err).To(BeNil())
+			Expect(cm.Labels).To(HaveKeyWithValue(compv1alpha1.SuiteLabel, suiteName))
+			Expect(metav1.IsControlledBy(cm, suite)).To(BeTrue())
+			Expect(cm.Data).To(HaveKey(assessmentResultsKey))
+			doc := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(cm.Data[assessmentResultsKey]), &doc)).To(Succeed())
+			return doc["assessment-results"].(map[string]interface{})
+		}
+
+		BeforeEach(func() {
+			suiteAndScansInDonePhase()
+			rulePrefix := "xccdf_org.ssgproject.content_rule_"
+			review := newCheck("test-manual-reviewed", rulePrefix+"manual_reviewed", compv1alpha1.CheckResultManual)
+			review.Review = &compv1alpha1.ManualCheckReview{Reviewer: "auditor", Outcome: compv1alpha1.CheckResultPass}
+			for _, obj := range []client.Object{
+				newCheck("test-pass", rulePrefix+"pass", compv1alpha1.CheckResultPass),
+				newCheck("test-fail", rulePrefix+"fail", compv1alpha1.CheckResultFail),
+				newCheck("test-manual", rulePrefix+"manual", compv1alpha1.CheckResultManual),
+				review,
+				newRule("ocp4-pass", rulePrefix+"pass", "AC-2(1)", "AU-2"),
+				newRule("ocp4-fail", rulePrefix+"fail", "AU-2"),
+				newRule("ocp4-manual", rulePrefix+"manual", "CM-6"),
+				newRule("ocp4-manual-reviewed", rulePrefix+"manual_reviewed", "AC-2(1)"),
+			} {
+				Expect(reconciler.Client.Create(ctx, obj)).To(Succeed())
+			}
+		})
+
+		It("Should export the check results as OSCAL assessment results", func() {
+			results := reconcileAndGetResults()
+			Expect(results["import-ap"]).To(HaveKeyWithValue("href", "#"))
+			Expect(results["metadata"]).To(HaveKeyWithValue("oscal-version", oscalVersion))
+			Expect(results["results"]).To(HaveLen(1))
+			result := results["results"].([]interface{})[0].(map[string]interface{})
+			Expect(result["observations"]).To(HaveLen(4))
+
+			states := map[string]string{}
+			for _, f := range result["findings"].([]interface{}) {
+				target := f.(map[string]interface{})["target"].(map[string]interface{})
+				status := target["status"].(map[string]interface{})
+				states[target["target-id"].(string)] = status["state"].(string) + "/" + status["reason"].(string)
+			}
+			Expect(states).To(Equal(map[string]string{
+				"ac-2.1": "satisfied/pass",
+				"au-2":   "not-satisfied/fail",
+				"cm-6":   "not-satisfied/other",
+			}))
+			selections := result["reviewed-controls"].(map[string]interface{})["control-selections"].([]interface{})
+			Expect(selections).To(HaveLen(1))
+			Expect(selections[0]).To(HaveKeyWithValue("include-controls", HaveLen(3)))
+		})
+
+		It("Should export the same document until the scans run again", func() {
+			Expect(reconcileAndGetResults()).To(Equal(reconcileAndGetResults()))
+		})
+
+		It("Should export the check results as a flat CSV or JSON file", func() {
+			reconcileAndGetFlatExport := func(format compv1alpha1.ResultExportFormat) *corev1.ConfigMap {
+				suite.Spec.ResultExport = &compv1alpha1.ResultExport{ConfigMapName: "exported-results", Format: format}
+				Expect(reconciler.reconcileResultExport(suite, logger)).To(Succeed())
+				cm := &corev1.ConfigMap{}
+				err := reconciler.Client.Get(ctx, types.NamespacedName{Name: "exported-results", Namespace: namespace}, cm)
+				Expect(err).To(BeNil())
+				return cm
+			}
+			reconciler.Recorder = record.NewFakeRecorder(10)
+
+			cm := reconcileAndGetFlatExport(compv1alpha1.ResultExportCSV)
+			Expect(cm.Data).To(HaveLen(1))
+			lines := strings.Split(strings.TrimSpace(cm.Data[checkResultsCSVKey]), "\n")
+			Expect(lines).To(HaveLen(5))
+			Expect(lines[0]).To(Equal(strings.Join(checkResultsCSVHeader, ",")))
+			Expect(lines[1]).To(Equal("testScanNode,,test-fail,xccdf_org.ssgproject.content_rule_fail,medium,FAIL,,,NIST-800-53:AU-2"))
+			Expect(lines[4]).To(Equal("testScanNode,,test-pass,xccdf_org.ssgproject.content_rule_pass,medium,PASS,,,NIST-800-53:AC-2(1);NIST-800-53:AU-2"))
+
+			cm = reconcileAndGetFlatExport(compv1alpha1.ResultExportJSON)
+			Expect(cm.Data).To(HaveLen(1))
+			var rows []exportedCheckResult
+			Expect(json.Unmarshal([]byte(cm.Data[checkResultsJSONKey]), &rows)).To(Succeed())
+			Expect(rows).To(HaveLen(4))
+			Expect(rows[0].Status).To(Equal("FAIL"))
+			Expect(rows[0].Controls).To(Equal([]compv1alpha1.RuleControls{{Standard: "NIST-800-53", Controls: []string{"AU-2"}}}))
+		})
+
+		getForwardingStatus := func() *compv1alpha1.ResultForwardingStatus {
+			updated := &compv1alpha1.ComplianceSuite{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, updated)).To(Succeed())
+			return updated.Status.ResultForwarding
+		}
+
+		It("Should forward the results to the Splunk HTTP Event Collector once per scan run", func() {
+			queueCtx, stopQueue := context.WithCancel(ctx)
+			defer stopQueue()
+			go reconciler.notifications.Start(queueCtx)
+			var eventsLock sync.Mutex
+			var events []map[string]interface{}
+			getEvents := func() []map[string]interface{} {
+				eventsLock.Lock()
+				defer eventsLock.Unlock()
+				return append([]map[string]interface{}{}, events...)
+			}
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+				Expect(r.Header.Get("Authorization")).To(Equal("Splunk hec-token"))
+				decoder := json.NewDecoder(r.Body)
+				eventsLock.Lock()
+				defer eventsLock.Unlock()
+				for decoder.More() {
+					event := map[string]interface{}{}
+					Expect(decoder.Decode(&event)).To(Succeed())
+					events = append(events, event)
+				}
+				w.Write([]byte(`{"text":"Success","code":0}`))
+			}))
+			defer server.Close()
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "splunk", Namespace: namespace},
+				Data: map[string][]byte{
+					forwardingTokenKey: []byte("hec-token"),
+					forwardingCAKey:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+				},
+			}
+			Expect(reconciler.Client.Create(ctx, secret)).To(Succeed())
+			scan := &compv1alpha1.ComplianceScan{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode", Namespace: namespace}, scan)).To(Succeed())
+			started := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+			scan.Status.StartTimestamp = &started
+			scan.Status.Result = compv1alpha1.ResultNonCompliant
+			Expect(reconciler.Client.Status().Update(ctx, scan)).To(Succeed())
+
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			suite.Spec.ResultForwarding = &compv1alpha1.ResultForwarding{
+				Splunk: &compv1alpha1.SplunkForwarding{URL: server.URL, TokenSecretName: "splunk", BatchSize: 2},
+			}
+			Expect(reconciler.reconcileResultForwarding(suite, logger)).To(Succeed())
+			Expect(getForwardingStatus().Splunk.ScanRun.Equal(&started)).To(BeTrue())
+			Eventually(func() *metav1.Time {
+				return getForwardingStatus().Splunk.ForwardedAt
+			}).ShouldNot(BeNil())
+			forwarded := getEvents()
+			Expect(forwarded).To(HaveLen(5))
+			Expect(forwarded[0]).To(HaveKeyWithValue("source", defaultSplunkSource))
+			Expect(forwarded[0]).To(HaveKeyWithValue("event", And(
+				HaveKeyWithValue("type", forwardedScanSummaryType),
+				HaveKeyWithValue("result", string(compv1alpha1.ResultNonCompliant)),
+				HaveKeyWithValue("checks", HaveKeyWithValue("MANUAL", BeNumerically("==", 2))),
+			)))
+			Expect(forwarded[1]).To(HaveKeyWithValue("event", And(
+				HaveKeyWithValue("type", forwardedCheckResultType),
+				HaveKeyWithValue("name", "test-fail"),
+				HaveKeyWithValue("status", "FAIL"),
+			)))
+
+			Expect(getForwardingStatus().Splunk.Events).To(Equal(5))
+			Expect(getForwardingStatus().Splunk.Error).To(BeEmpty())
+
+			updated := &compv1alpha1.ComplianceSuite{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, updated)).To(Succeed())
+			updated.Spec.ResultForwarding = suite.Spec.ResultForwarding
+			Expect(reconciler.reconcileResultForwarding(updated, logger)).To(Succeed())
+			Consistently(getEvents, "200ms").Should(HaveLen(5))
+		})
+
+		It("Should record why the results couldn't be forwarded in the background", func() {
+			queueCtx, stopQueue := context.WithCancel(ctx)
+			defer stopQueue()
+			go reconciler.notifications.Start(queueCtx)
+			scan := &compv1alpha1.ComplianceScan{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode", Namespace: namespace}, scan)).To(Succeed())
+			started := metav1.Now()
+			scan.Status.StartTimestamp = &started
+			Expect(reconciler.Client.Status().Update(ctx, scan)).To(Succeed())
+
+			recorder := record.NewFakeRecorder(10)
+			reconciler.Recorder = recorder
+			suite.Spec.ResultForwarding = &compv1alpha1.ResultForwarding{
+				Splunk: &compv1alpha1.SplunkForwarding{URL: "https://splunk.example.com:8088", TokenSecretName: "missing"},
+			}
+			// The reconcile doesn't wait for the collector
+			Expect(reconciler.reconcileResultForwarding(suite, logger)).To(Succeed())
+			Eventually(func() string {
+				return getForwardingStatus().Splunk.Error
+			}).Should(ContainSubstring("cannot get the Secret missing"))
+			Expect(getForwardingStatus().Splunk.ForwardedAt).To(BeNil())
+			Eventually(recorder.Events).Should(Receive(ContainSubstring("ResultForwardingFailed")))
+		})
+
+		It("Should publish the lifecycle and the summaries of the scans to a Kafka topic", func() {
+			queueCtx, stopQueue := context.WithCancel(ctx)
+			defer stopQueue()
+			go reconciler.notifications.Start(queueCtx)
+			published := &publishedMessages{}
+			reconciler.newKafkaPublisher = func(config *utils.KafkaConfig) utils.KafkaPublisher {
+				defer GinkgoRecover()
+				Expect(config.Brokers).To(Equal([]string{"kafka-0:9093", "kafka-1:9093"}))
+				Expect(config.TLSConfig).ToNot(BeNil())
+				return &fakeKafkaPublisher{published: published}
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "kafka", Namespace: namespace},
+				Data: map[string][]byte{
+					utils.KafkaBrokersKey: []byte("kafka-0:9093, kafka-1:9093"),
+					utils.KafkaTopicKey:   []byte("compliance-events"),
+					utils.KafkaTLSKey:     []byte("true"),
+				},
+			}
+			Expect(reconciler.Client.Create(ctx, secret)).To(Succeed())
+			suite.Spec.ResultForwarding = &compv1alpha1.ResultForwarding{
+				Kafka: &compv1alpha1.KafkaForwarding{SecretName: "kafka"},
+			}
+			started := metav1.Now()
+			scanStatus := &compv1alpha1.ComplianceScanStatusWrapper{Name: "testScanNode"}
+			scanStatus.StartTimestamp = &started
+
+			By("Publishing the lifecycle of the scan")
+			for _, phase := range []compv1alpha1.ComplianceScanStatusPhase{
+				compv1alpha1.PhasePending, compv1alpha1.PhaseLaunching, compv1alpha1.PhaseRunning, compv1alpha1.PhaseDone,
+			} {
+				previous := scanStatus.Phase
+				scanStatus.Phase = phase
+				if phase == compv1alpha1.PhaseDone {
+					scanStatus.Result = compv1alpha1.ResultNonCompliant
+				}
+				reconciler.publishScanLifecycle(suite, previous, scanStatus, logger)
+			}
+			Eventually(published.get).Should(HaveLen(2))
+			messages := published.get()
+			Expect(messages[0].Key).To(Equal(suiteName))
+			Expect(messages[0].Value).To(HaveField("Type", forwardedScanStartedType))
+			Expect(messages[0].Value).To(HaveField("Phase", string(compv1alpha1.PhaseLaunching)))
+			Expect(messages[1].Value).To(HaveField("Type", forwardedScanFinishedType))
+			Expect(messages[1].Value).To(HaveField("Result", string(compv1alpha1.ResultNonCompliant)))
+
+			By("Publishing the summary of the scan once it's done")
+			scan := &compv1alpha1.ComplianceScan{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode", Namespace: namespace}, scan)).To(Succeed())
+			scan.Status.StartTimestamp = &started
+			Expect(reconciler.Client.Status().Update(ctx, scan)).To(Succeed())
+			Expect(reconciler.reconcileResultForwarding(suite, logger)).To(Succeed())
+			Eventually(published.get).Should(HaveLen(3))
+			messages = published.get()
+			Eventually(func() int {
+				return getForwardingStatus().Kafka.Events
+			}).Should(Equal(1))
+			Expect(messages[2].Value).To(HaveField("Type", forwardedScanSummaryType))
+			Expect(messages[2].Value).To(HaveField("Checks", HaveLen(3)))
+		})
+
+		It("Should send the check results to a syslog receiver as LEEF messages", func() {
+			queueCtx, stopQueue := context.WithCancel(ctx)
+			defer stopQueue()
+			go reconciler.notifications.Start(queueCtx)
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer listener.Close()
+			received := make(chan string, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				contents, _ := io.ReadAll(conn)
+				received <- string(contents)
+			}()
+			scan := &compv1alpha1.ComplianceScan{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode", Namespace: namespace}, scan)).To(Succeed())
+			started := metav1.Now()
+			scan.Status.StartTimestamp = &started
+			Expect(reconciler.Client.Status().Update(ctx, scan)).To(Succeed())
+
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			suite.Spec.ResultForwarding = &compv1alpha1.ResultForwarding{
+				Syslog: &compv1alpha1.SyslogForwarding{
+					Address:      listener.Addr().String(),
+					Protocol:     compv1alpha1.SyslogTCP,
+					Format:       compv1alpha1.SyslogLEEF,
+					FieldMapping: &compv1alpha1.SyslogFieldMapping{RuleID: "ruleName"},
+				},
+			}
+			Expect(reconciler.reconcileResultForwarding(suite, logger)).To(Succeed())
+			var messages string
+			Eventually(received).Should(Receive(&messages))
+			Expect(strings.Count(messages, "LEEF:1.0|ComplianceAsCode|compliance-operator|")).To(Equal(4))
+			Expect(messages).To(ContainSubstring("|xccdf_org.ssgproject.content_rule_fail|cat=test-fail\tsev=6\t" +
+				"ruleName=xccdf_org.ssgproject.content_rule_fail\tcomplianceSeverity=medium\toutcome=FAIL\t"))
+			Expect(messages).To(ContainSubstring("cat=test-pass\tsev=1\t"))
+
+			Eventually(func() int {
+				return getForwardingStatus().Syslog.Events
+			}).Should(Equal(4))
+			Expect(getForwardingStatus().Splunk).To(BeNil())
+		})
+	})
+
 	Context("When reconciling generic remediations", func() {
 		BeforeEach(func() {
 			remediation := &compv1alpha1.ComplianceRemediation{
@@ -220,6 +602,34 @@ var _ = Describe("ComplianceSuiteController", func() {
 			It("Should leave the remediation unapplied", reconcileShouldNotApplyTheRemediation)
 		})
 
+		Context("With a remediation export", func() {
+			BeforeEach(suiteAndScansInDonePhase)
+
+			It("Should export the manifests of the remediations", func() {
+				cm := reconcileAndGetExport(&compv1alpha1.RemediationExport{
+					ConfigMapName: "exported-remediations",
+					Format:        compv1alpha1.RemediationExportManifests,
+				})
+				Expect(cm.Data).To(HaveLen(1))
+				Expect(cm.Data).To(HaveKeyWithValue(remediationName+".yaml", ContainSubstring("kind: ConfigMap")))
+				Expect(cm.Labels).To(HaveKeyWithValue(compv1alpha1.SuiteLabel, suiteName))
+				Expect(metav1.IsControlledBy(cm, suite)).To(BeTrue())
+			})
+
+			It("Should only export the remediations that pass the filter", func() {
+				cm := reconcileAndGetExport(&compv1alpha1.RemediationExport{
+					ConfigMapName: "exported-remediations",
+					Format:        compv1alpha1.RemediationExportKustomize,
+					Filter: &compv1alpha1.RemediationApplyFilter{
+						Rules: []string{"other-rule"},
+					},
+				})
+				Expect(cm.Data).To(HaveLen(1))
+				Expect(cm.Data).To(HaveKey(kustomizationFileName))
+				Expect(cm.Data[kustomizationFileName]).ToNot(ContainSubstring(remediationName))
+			})
+		})
+
 		Context("With spec.AutoApplyRemediations = true", func() {
 			BeforeEach(func() {
 				suite.Spec.AutoApplyRemediations = true
@@ -233,6 +643,94 @@ var _ = Describe("ComplianceSuiteController", func() {
 				BeforeEach(suiteAndScansInDonePhase)
 				It("Should apply the remediation", reconcileShouldApplyTheRemediation)
 
+				Context("With an auto-apply filter", func() {
+					BeforeEach(func() {
+						check := &compv1alpha1.ComplianceCheckResult{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      remediationName,
+								Namespace: namespace,
+								Annotations: map[string]string{
+									compv1alpha1.ComplianceCheckResultRuleAnnotation: "test-rule",
+								},
+							},
+							Severity: compv1alpha1.CheckResultSeverityMedium,
+						}
+						err := reconciler.Client.Create(ctx, check)
+						Expect(err).To(BeNil())
+					})
+
+					It("Should apply the remediation if it matches the filter", func() {
+						suite.Spec.AutoApplyRemediationsFilter = &compv1alpha1.RemediationApplyFilter{
+							Severities: []compv1alpha1.ComplianceCheckResultSeverity{compv1alpha1.CheckResultSeverityMedium},
+						}
+						reconcileShouldApplyTheRemediation()
+					})
+
+					It("Should not apply the remediation of other severities", func() {
+						suite.Spec.AutoApplyRemediationsFilter = &compv1alpha1.RemediationApplyFilter{
+							Severities: []compv1alpha1.ComplianceCheckResultSeverity{compv1alpha1.CheckResultSeverityHigh},
+						}
+						reconcileShouldNotApplyTheRemediation()
+					})
+
+					It("Should not apply the remediation of excluded rules", func() {
+						suite.Spec.AutoApplyRemediationsFilter = &compv1alpha1.RemediationApplyFilter{
+							Rules:         []string{"test-rule"},
+							ExcludedRules: []string{"test-rule"},
+						}
+						reconcileShouldNotApplyTheRemediation()
+					})
+
+					It("Should apply the remediation if requested through the annotation", func() {
+						suite.Spec.AutoApplyRemediationsFilter = &compv1alpha1.RemediationApplyFilter{
+							Rules: []string{"other-rule"},
+						}
+						suite.Annotations = map[string]string{compv1alpha1.ApplyRemediationsAnnotation: ""}
+						rem := reconcileAndGetRemediation()
+						Expect(rem.Spec.Apply).To(BeTrue())
+					})
+				})
+
+				Context("With a remediation apply window", func() {
+					getSuite := func() *compv1alpha1.ComplianceSuite {
+						s := &compv1alpha1.ComplianceSuite{}
+						err := reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, s)
+						Expect(err).To(BeNil())
+						return s
+					}
+
+					It("Should apply the remediation and re-run the suite within the window", func() {
+						suite.Spec.RemediationApplyWindow = &compv1alpha1.RemediationApplyWindow{
+							Schedule: "* * * * *",
+							Duration: metav1.Duration{Duration: 2 * time.Minute},
+						}
+						reconcileShouldApplyTheRemediation()
+
+						s := getSuite()
+						Expect(s.Annotations).ToNot(HaveKey(compv1alpha1.VerifyRemediationsAnnotation))
+						Expect(s.Annotations).To(HaveKeyWithValue(compv1alpha1.RerunAnnotation, remediationApplyWindowRequester))
+					})
+
+					It("Should queue up the remediation outside of the window", func() {
+						recorder := record.NewFakeRecorder(10)
+						reconciler.Recorder = recorder
+						suite.Spec.RemediationApplyWindow = &compv1alpha1.RemediationApplyWindow{
+							Schedule: "0 0 1 1 *",
+							Duration: metav1.Duration{Duration: time.Minute},
+						}
+						res, err := reconciler.reconcileRemediations(suite, logger)
+						Expect(err).To(BeNil())
+						Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+						Expect(recorder.Events).To(Receive(ContainSubstring("RemediationsQueued")))
+
+						rem := &compv1alpha1.ComplianceRemediation{}
+						err = reconciler.Client.Get(ctx, types.NamespacedName{Name: remediationName, Namespace: namespace}, rem)
+						Expect(err).To(BeNil())
+						Expect(rem.Spec.Apply).To(BeFalse())
+						Expect(getSuite().Annotations).ToNot(HaveKey(compv1alpha1.RerunAnnotation))
+					})
+				})
+
 				Context("With remove-outdated annotation", func() {
 					BeforeEach(prepareForRemoveOutdatedScenarios)
 					It("Should remove the outdated remediation and remove the annotation", func() {
@@ -385,6 +883,42 @@ var _ = Describe("ComplianceSuiteController", func() {
 				BeforeEach(suiteAndScansInDonePhase)
 				It("Should apply the remediation", reconcileShouldApplyTheRemediationAndHandlePausingPools)
 
+				It("Should apply the remediation if its pool is auto-applied to", func() {
+					suite.Spec.AutoApplyRemediationsPools = []string{"other-pool", poolName}
+					reconcileShouldApplyTheRemediationAndHandlePausingPools()
+				})
+
+				It("Should not apply the remediation if its pool isn't auto-applied to", func() {
+					suite.Spec.AutoApplyRemediationsPools = []string{"other-pool"}
+					reconcileShouldNotApplyTheRemediation()
+
+					By("the pool should not be paused")
+					p := &mcfgv1.MachineConfigPool{}
+					err := reconciler.Client.Get(ctx, types.NamespacedName{Name: poolName}, p)
+					Expect(err).To(BeNil())
+					Expect(p.Spec.Paused).To(BeFalse())
+				})
+
+				It("Should apply the remediation of any pool if requested through the annotation", func() {
+					suite.Spec.AutoApplyRemediationsPools = []string{"other-pool"}
+					suite.Annotations = map[string]string{compv1alpha1.ApplyRemediationsAnnotation: ""}
+					rem := reconcileAndGetRemediation()
+					Expect(rem.Spec.Apply).To(BeTrue())
+				})
+
+				It("Should export the MachineConfig the way it would be applied", func() {
+					rem := &compv1alpha1.ComplianceRemediation{}
+					err := reconciler.Client.Get(ctx, types.NamespacedName{Name: remediationName, Namespace: namespace}, rem)
+					Expect(err).To(BeNil())
+
+					cm := reconcileAndGetExport(&compv1alpha1.RemediationExport{
+						ConfigMapName: "exported-remediations",
+						Format:        compv1alpha1.RemediationExportKustomize,
+					})
+					Expect(cm.Data).To(HaveKeyWithValue(remediationName+".yaml", ContainSubstring("name: "+rem.GetMcName())))
+					Expect(cm.Data).To(HaveKeyWithValue(kustomizationFileName, ContainSubstring("- "+remediationName+".yaml")))
+				})
+
 				Context("With remove-outdated annotation", func() {
 					BeforeEach(prepareForRemoveOutdatedScenarios)
 					It("Should remove the outdated remediation and remove the annotation", func() {
@@ -625,6 +1159,35 @@ var _ = Describe("ComplianceSuiteController", func() {
 				BeforeEach(suiteAndScansInDonePhase)
 				It("Should apply the remediation", reconcileShouldApplyTheRemediationAndHandlePausingPools)
 
+				It("Should export the KubeletConfigs of the pool merged", func() {
+					other := &compv1alpha1.ComplianceRemediation{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "otherRem",
+							Namespace: namespace,
+							Labels: map[string]string{
+								compv1alpha1.SuiteLabel:          suiteName,
+								compv1alpha1.ComplianceScanLabel: "testScanNode",
+							},
+						},
+					}
+					kcPayload := `{"apiVersion": "machineconfiguration.openshift.io/v1","kind": "KubeletConfig","spec": {"kubeletConfig": {"maxPods": 100}}}`
+					other.Spec.Current.Object = &unstructured.Unstructured{}
+					err := other.Spec.Current.Object.UnmarshalJSON([]byte(kcPayload))
+					Expect(err).To(BeNil())
+					err = reconciler.Client.Create(ctx, other)
+					Expect(err).To(BeNil())
+
+					cm := reconcileAndGetExport(&compv1alpha1.RemediationExport{
+						ConfigMapName: "exported-remediations",
+						Format:        compv1alpha1.RemediationExportKustomize,
+					})
+					kcFileName := utils.GetManagedKubeletConfigName(poolName) + ".yaml"
+					Expect(cm.Data).To(HaveLen(2))
+					Expect(cm.Data).To(HaveKeyWithValue(kcFileName, ContainSubstring("maxPods: 100")))
+					Expect(cm.Data).To(HaveKeyWithValue(kcFileName, ContainSubstring("streamingConnectionIdleTimeout: 0s")))
+					Expect(cm.Data).To(HaveKeyWithValue(kcFileName, ContainSubstring("pools.operator.machineconfiguration.openshift.io/"+poolName)))
+				})
+
 				Context("With remove-outdated annotation", func() {
 					BeforeEach(prepareForRemoveOutdatedScenarios)
 					It("Should remove the outdated remediation and remove the annotation", func() {
@@ -926,4 +1489,1006 @@ var _ = Describe("ComplianceSuiteController", func() {
 		})
 	})
 
+	Context("When rescanning on MachineConfigPool updates", func() {
+		var poolName = "test-pool"
+		var scanKey = types.NamespacedName{Name: "testScanNode", Namespace: namespace}
+
+		setPoolConfiguration := func(rendered string, updated corev1.ConditionStatus) {
+			p := &mcfgv1.MachineConfigPool{}
+			err := reconciler.Client.Get(ctx, types.NamespacedName{Name: poolName}, p)
+			Expect(err).To(BeNil())
+			p.Status.Configuration.Name = rendered
+			p.Status.Conditions = []mcfgv1.MachineConfigPoolCondition{
+				{
+					Type:   mcfgv1.MachineConfigPoolUpdated,
+					Status: updated,
+				},
+			}
+			err = reconciler.Client.Update(ctx, p)
+			Expect(err).To(BeNil())
+		}
+
+		getScan := func() *compv1alpha1.ComplianceScan {
+			scan := &compv1alpha1.ComplianceScan{}
+			err := reconciler.Client.Get(ctx, scanKey, scan)
+			Expect(err).To(BeNil())
+			return scan
+		}
+
+		BeforeEach(func() {
+			mcp := &mcfgv1.MachineConfigPool{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "MachineConfigPool",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: poolName,
+				},
+				Spec: mcfgv1.MachineConfigPoolSpec{
+					NodeSelector: &metav1.LabelSelector{
+						MatchLabels: targetNodeSelector,
+					},
+				},
+			}
+			err := reconciler.Client.Create(ctx, mcp)
+			Expect(err).To(BeNil())
+
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			suite.Spec.RescanOnMachineConfigPoolUpdate = true
+			err = reconciler.Client.Update(ctx, suite)
+			Expect(err).To(BeNil())
+			suiteAndScansInDonePhase()
+			setPoolConfiguration("rendered-1", corev1.ConditionTrue)
+		})
+
+		It("Should only record the configuration on the first pass", func() {
+			res, err := reconciler.reconcileMachineConfigPoolRescans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(res.RequeueAfter).To(Equal(mcfgPoolPollInterval))
+
+			scan := getScan()
+			Expect(scan.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceScanMachineConfigAnnotation, "rendered-1"))
+			Expect(scan.NeedsRescan()).To(BeFalse())
+		})
+
+		It("Should rescan once the pool finishes rolling out a new configuration", func() {
+			_, err := reconciler.reconcileMachineConfigPoolRescans(suite, logger)
+			Expect(err).To(BeNil())
+
+			By("Not rescanning while the pool is updating")
+			setPoolConfiguration("rendered-2", corev1.ConditionFalse)
+			_, err = reconciler.reconcileMachineConfigPoolRescans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(getScan().NeedsRescan()).To(BeFalse())
+
+			By("Rescanning once the pool is updated")
+			setPoolConfiguration("rendered-2", corev1.ConditionTrue)
+			_, err = reconciler.reconcileMachineConfigPoolRescans(suite, logger)
+			Expect(err).To(BeNil())
+			scan := getScan()
+			Expect(scan.NeedsRescan()).To(BeTrue())
+			Expect(scan.Annotations).To(HaveKeyWithValue(compv1alpha1.ComplianceScanMachineConfigAnnotation, "rendered-2"))
+		})
+
+		It("Should not rescan if the option is disabled", func() {
+			suite.Spec.RescanOnMachineConfigPoolUpdate = false
+			res, err := reconciler.reconcileMachineConfigPoolRescans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(res.IsZero()).To(BeTrue())
+			Expect(getScan().Annotations).ToNot(HaveKey(compv1alpha1.ComplianceScanMachineConfigAnnotation))
+		})
+	})
+
+	Context("When running the scans serially", func() {
+		var platformScanKey = types.NamespacedName{Name: "testScanPlatform", Namespace: namespace}
+		var workerScanKey = types.NamespacedName{Name: "testScanWorker", Namespace: namespace}
+		var nodeScanKey = types.NamespacedName{Name: "testScanNode", Namespace: namespace}
+
+		reconcileScans := func() {
+			s := &compv1alpha1.ComplianceSuite{}
+			err := reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, s)
+			Expect(err).To(BeNil())
+			_, err = reconciler.reconcileScans(s, logger)
+			Expect(err).To(BeNil())
+		}
+
+		getScan := func(key types.NamespacedName) (*compv1alpha1.ComplianceScan, error) {
+			scan := &compv1alpha1.ComplianceScan{}
+			err := reconciler.Client.Get(ctx, key, scan)
+			return scan, err
+		}
+
+		BeforeEach(func() {
+			suite.Spec.ScanExecutionMode = compv1alpha1.ScanExecutionModeSerial
+			suite.Spec.Scans = append(suite.Spec.Scans,
+				compv1alpha1.ComplianceScanSpecWrapper{
+					Name: "testScanWorker",
+					ComplianceScanSpec: compv1alpha1.ComplianceScanSpec{
+						ScanType:     compv1alpha1.ScanTypeNode,
+						NodeSelector: utils.GetNodeRoleSelector("worker"),
+					},
+				},
+				compv1alpha1.ComplianceScanSpecWrapper{
+					Name: "testScanPlatform",
+					ComplianceScanSpec: compv1alpha1.ComplianceScanSpec{
+						ScanType: compv1alpha1.ScanTypePlatform,
+					},
+				},
+			)
+			err := reconciler.Client.Update(ctx, suite)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should order the platform scans first and the worker scans before the rest", func() {
+			Expect(getScanExecutionOrder(suite)).To(Equal([]int{2, 1, 0}))
+
+			suite.Spec.ScanExecutionMode = compv1alpha1.ScanExecutionModeParallel
+			Expect(getScanExecutionOrder(suite)).To(Equal([]int{0, 1, 2}))
+		})
+
+		It("Should only launch a scan once the previous ones are done", func() {
+			By("Launching the platform scan first")
+			reconcileScans()
+			_, err := getScan(platformScanKey)
+			Expect(err).To(BeNil())
+			_, err = getScan(workerScanKey)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Launching the worker scan once the platform scan is done")
+			platformScan, err := getScan(platformScanKey)
+			Expect(err).To(BeNil())
+			platformScan.Status.Phase = compv1alpha1.PhaseDone
+			platformScan.Status.Result = compv1alpha1.ResultCompliant
+			err = reconciler.Client.Status().Update(ctx, platformScan)
+			Expect(err).To(BeNil())
+			reconcileScans()
+			_, err = getScan(workerScanKey)
+			Expect(err).To(BeNil())
+
+			By("Keeping the suite pending while scans are waiting to be launched")
+			s := &compv1alpha1.ComplianceSuite{}
+			err = reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, s)
+			Expect(err).To(BeNil())
+			Expect(s.Status.Phase).To(Equal(compv1alpha1.PhasePending))
+			Expect(s.IsResultAvailable()).To(BeFalse())
+		})
+
+		It("Should start the pending re-runs one at a time", func() {
+			pendingRescan := map[string]string{compv1alpha1.ComplianceScanPendingRescanAnnotation: ""}
+			suite.Status.ScanStatuses = nil
+			for _, scanWrap := range suite.Spec.Scans {
+				scan := compv1alpha1.ComplianceScanFromWrapper(&scanWrap)
+				scan.Namespace = namespace
+				scan.Labels = map[string]string{compv1alpha1.SuiteLabel: suiteName}
+				scan.Annotations = pendingRescan
+				if scan.Name == nodeScanKey.Name {
+					existing, err := getScan(nodeScanKey)
+					Expect(err).To(BeNil())
+					existing.Annotations = pendingRescan
+					err = reconciler.Client.Update(ctx, existing)
+					Expect(err).To(BeNil())
+					scan = existing
+				} else {
+					err := reconciler.Client.Create(ctx, scan)
+					Expect(err).To(BeNil())
+				}
+				scan.Status.Phase = compv1alpha1.PhaseDone
+				err := reconciler.Client.Status().Update(ctx, scan)
+				Expect(err).To(BeNil())
+
+				suite.Status.ScanStatuses = append(suite.Status.ScanStatuses, compv1alpha1.ComplianceScanStatusWrapper{
+					Name:                 scan.Name,
+					ComplianceScanStatus: compv1alpha1.ComplianceScanStatus{Phase: compv1alpha1.PhasePending},
+				})
+			}
+			err := reconciler.Client.Status().Update(ctx, suite)
+			Expect(err).To(BeNil())
+
+			reconcileScans()
+
+			platformScan, err := getScan(platformScanKey)
+			Expect(err).To(BeNil())
+			Expect(platformScan.NeedsRescan()).To(BeTrue())
+			Expect(platformScan.HasPendingRescan()).To(BeFalse())
+
+			for _, key := range []types.NamespacedName{workerScanKey, nodeScanKey} {
+				scan, err := getScan(key)
+				Expect(err).To(BeNil())
+				Expect(scan.NeedsRescan()).To(BeFalse())
+				Expect(scan.HasPendingRescan()).To(BeTrue())
+			}
+		})
+	})
+
+	Context("When a compliance threshold is set", func() {
+		createCheckResults := func(status compv1alpha1.ComplianceCheckStatus, count int) {
+			for i := 0; i < count; i++ {
+				check := &compv1alpha1.ComplianceCheckResult{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("check-%s-%d", strings.ToLower(string(status)), i),
+						Namespace: namespace,
+						Labels: map[string]string{
+							compv1alpha1.SuiteLabel:                       suiteName,
+							compv1alpha1.ComplianceCheckResultStatusLabel: string(status),
+						},
+					},
+					Status: status,
+				}
+				err := reconciler.Client.Create(ctx, check)
+				Expect(err).To(BeNil())
+			}
+		}
+
+		BeforeEach(func() {
+			suite.Spec.ComplianceThreshold = 80
+			suite.Status.Phase = compv1alpha1.PhaseDone
+			suite.Status.Result = compv1alpha1.ResultNonCompliant
+		})
+
+		It("Should report the suite as compliant if the threshold is met", func() {
+			createCheckResults(compv1alpha1.CheckResultPass, 8)
+			createCheckResults(compv1alpha1.CheckResultFail, 2)
+			createCheckResults(compv1alpha1.CheckResultManual, 5)
+
+			err := reconciler.applyComplianceThreshold(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(suite.Status.ComplianceThreshold).To(Equal(&compv1alpha1.ComplianceThresholdStatus{
+				PassedChecks:   8,
+				FailedChecks:   2,
+				PassPercentage: 80,
+			}))
+		})
+
+		It("Should report the gap if the threshold is not met", func() {
+			createCheckResults(compv1alpha1.CheckResultPass, 7)
+			createCheckResults(compv1alpha1.CheckResultFail, 3)
+
+			err := reconciler.applyComplianceThreshold(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(suite.Status.ComplianceThreshold.PassPercentage).To(Equal(70))
+			Expect(suite.Status.ComplianceThreshold.Gap).To(Equal(10))
+		})
+
+		It("Should not change the result of suites that errored", func() {
+			createCheckResults(compv1alpha1.CheckResultPass, 9)
+			suite.Status.Result = compv1alpha1.ResultError
+
+			err := reconciler.applyComplianceThreshold(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultError))
+			Expect(suite.Status.ComplianceThreshold).To(BeNil())
+		})
+
+		It("Should not report anything while the suite is running", func() {
+			suite.Status.Phase = compv1alpha1.PhaseRunning
+			suite.Status.ComplianceThreshold = &compv1alpha1.ComplianceThresholdStatus{PassPercentage: 90}
+
+			err := reconciler.applyComplianceThreshold(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(suite.Status.ComplianceThreshold).To(BeNil())
+		})
+	})
+
+	Context("When MANUAL checks are reviewed", func() {
+		var checkCount int
+
+		createCheckResult := func(status compv1alpha1.ComplianceCheckStatus, reviewOutcome compv1alpha1.ComplianceCheckStatus) {
+			checkCount++
+			check := &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("check-%d", checkCount),
+					Namespace: namespace,
+					Labels: map[string]string{
+						compv1alpha1.SuiteLabel:                       suiteName,
+						compv1alpha1.ComplianceCheckResultStatusLabel: string(status),
+					},
+				},
+				Status: status,
+			}
+			if reviewOutcome != "" {
+				check.Review = &compv1alpha1.ManualCheckReview{
+					Reviewer:  "auditor",
+					Timestamp: metav1.Now(),
+					Outcome:   reviewOutcome,
+				}
+			}
+			Expect(reconciler.Client.Create(ctx, check)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			checkCount = 0
+			suite.Status.Phase = compv1alpha1.PhaseDone
+			suite.Status.Result = compv1alpha1.ResultNonCompliant
+		})
+
+		It("Should report the suite as compliant once all the MANUAL checks passed their review", func() {
+			createCheckResult(compv1alpha1.CheckResultPass, "")
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultPass)
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultPass)
+
+			Expect(reconciler.applyManualCheckReviews(suite, logger)).To(Succeed())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+			Expect(suite.Status.ManualChecks).To(Equal(&compv1alpha1.ManualChecksStatus{Passed: 2}))
+		})
+
+		It("Should keep the suite non-compliant while reviews are pending or checks fail", func() {
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultPass)
+			createCheckResult(compv1alpha1.CheckResultManual, "")
+
+			Expect(reconciler.applyManualCheckReviews(suite, logger)).To(Succeed())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(suite.Status.ManualChecks).To(Equal(&compv1alpha1.ManualChecksStatus{Passed: 1, Pending: 1}))
+
+			createCheckResult(compv1alpha1.CheckResultFail, "")
+			Expect(reconciler.Client.Delete(ctx, &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{Name: "check-2", Namespace: namespace},
+			})).To(Succeed())
+			Expect(reconciler.applyManualCheckReviews(suite, logger)).To(Succeed())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+		})
+
+		It("Should report a compliant suite with a failed review as non-compliant", func() {
+			suite.Status.Result = compv1alpha1.ResultCompliant
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultFail)
+
+			Expect(reconciler.applyManualCheckReviews(suite, logger)).To(Succeed())
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+			Expect(suite.Status.ManualChecks).To(Equal(&compv1alpha1.ManualChecksStatus{Failed: 1}))
+		})
+
+		It("Should count the reviewed MANUAL checks towards the compliance threshold", func() {
+			suite.Spec.ComplianceThreshold = 80
+			for i := 0; i < 7; i++ {
+				createCheckResult(compv1alpha1.CheckResultPass, "")
+			}
+			createCheckResult(compv1alpha1.CheckResultFail, "")
+			createCheckResult(compv1alpha1.CheckResultFail, "")
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultPass)
+
+			Expect(reconciler.applyManualCheckReviews(suite, logger)).To(Succeed())
+			Expect(reconciler.applyComplianceThreshold(suite, logger)).To(Succeed())
+			Expect(suite.Status.ComplianceThreshold.PassedChecks).To(Equal(8))
+			Expect(suite.Status.ComplianceThreshold.FailedChecks).To(Equal(2))
+			Expect(suite.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+		})
+
+		It("Should update the result of a finished suite when a review changes", func() {
+			suite.Status.ScanStatuses = []compv1alpha1.ComplianceScanStatusWrapper{
+				{
+					Name: "testScanNode",
+					ComplianceScanStatus: compv1alpha1.ComplianceScanStatus{
+						Phase:  compv1alpha1.PhaseDone,
+						Result: compv1alpha1.ResultNonCompliant,
+					},
+				},
+			}
+			Expect(reconciler.Client.Status().Update(ctx, suite)).To(Succeed())
+			createCheckResult(compv1alpha1.CheckResultManual, compv1alpha1.CheckResultPass)
+
+			updated, err := reconciler.reconcileManualCheckReviews(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(updated).To(BeTrue())
+
+			updatedSuite := &compv1alpha1.ComplianceSuite{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, updatedSuite)).To(Succeed())
+			Expect(updatedSuite.Status.Result).To(Equal(compv1alpha1.ResultCompliant))
+
+			updated, err = reconciler.reconcileManualCheckReviews(updatedSuite, logger)
+			Expect(err).To(BeNil())
+			Expect(updated).To(BeFalse())
+		})
+	})
+
+	Context("When re-running the suite on request", func() {
+		var scanKey = types.NamespacedName{Name: "testScanNode", Namespace: namespace}
+
+		// requestRerun requests a re-run as the webhook records it
+		requestRerun := func(requester string) {
+			suite.Annotations = map[string]string{
+				compv1alpha1.RerunAnnotation:          "before-audit",
+				compv1alpha1.RerunRequesterAnnotation: requester,
+			}
+			err := reconciler.Client.Update(ctx, suite)
+			Expect(err).To(BeNil())
+		}
+
+		getSuite := func() *compv1alpha1.ComplianceSuite {
+			s := &compv1alpha1.ComplianceSuite{}
+			err := reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, s)
+			Expect(err).To(BeNil())
+			return s
+		}
+
+		BeforeEach(func() {
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			rerunRequesterWebhookServed.Store(true)
+		})
+
+		AfterEach(func() {
+			rerunRequesterWebhookServed.Store(false)
+		})
+
+		It("Should re-run the scans and record the request", func() {
+			requestRerun("alice")
+			err := reconciler.rerunSuite(getSuite(), logger)
+			Expect(err).To(BeNil())
+
+			scan := &compv1alpha1.ComplianceScan{}
+			err = reconciler.Client.Get(ctx, scanKey, scan)
+			Expect(err).To(BeNil())
+			Expect(scan.NeedsRescan()).To(BeTrue())
+
+			s := getSuite()
+			Expect(s.RerunAnnotationSet()).To(BeFalse())
+			Expect(s.Annotations).ToNot(HaveKey(compv1alpha1.RerunRequesterAnnotation))
+			Expect(s.Status.LastRerun).ToNot(BeNil())
+			Expect(s.Status.LastRerun.TriggeredBy).To(Equal("alice"))
+			Expect(s.Status.LastRerun.Reason).To(Equal("before-audit"))
+			Expect(s.Status.LastRerun.TriggeredAt.IsZero()).To(BeFalse())
+		})
+
+		It("Should only mark the scans of serial suites for re-running", func() {
+			suite.Spec.ScanExecutionMode = compv1alpha1.ScanExecutionModeSerial
+			requestRerun("alice")
+			err := reconciler.rerunSuite(getSuite(), logger)
+			Expect(err).To(BeNil())
+
+			scan := &compv1alpha1.ComplianceScan{}
+			err = reconciler.Client.Get(ctx, scanKey, scan)
+			Expect(err).To(BeNil())
+			Expect(scan.NeedsRescan()).To(BeFalse())
+			Expect(scan.HasPendingRescan()).To(BeTrue())
+		})
+
+		It("Should only trust the requester recorded by the webhook", func() {
+			suite.Annotations = map[string]string{compv1alpha1.RerunAnnotation: "alice"}
+			suite.ManagedFields = []metav1.ManagedFieldsEntry{
+				{
+					Manager:  "kubectl-annotate",
+					FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:compliance.openshift.io/rerun":{}}}}`)},
+				},
+			}
+			Expect(getRerunRequester(suite)).To(Equal("unknown"))
+
+			suite.Annotations[compv1alpha1.RerunRequesterAnnotation] = "alice"
+			rerunRequesterWebhookServed.Store(false)
+			Expect(getRerunRequester(suite)).To(Equal("unknown"))
+		})
+	})
+
+	Context("When the suite depends on another suite", func() {
+		var dependency *compv1alpha1.ComplianceSuite
+		var nodeScanKey = types.NamespacedName{Name: "testScanNode", Namespace: namespace}
+
+		BeforeEach(func() {
+			dependency = &compv1alpha1.ComplianceSuite{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "platformSuite",
+					Namespace: namespace,
+				},
+			}
+			err := reconciler.Client.Create(ctx, dependency)
+			Expect(err).To(BeNil())
+			dependency.Status.Phase = compv1alpha1.PhaseRunning
+			err = reconciler.Client.Status().Update(ctx, dependency)
+			Expect(err).To(BeNil())
+
+			suite.Spec.DependsOn = []string{dependency.Name}
+			err = reconciler.Client.Update(ctx, suite)
+			Expect(err).To(BeNil())
+
+			// Start from a suite whose scans weren't launched yet
+			scan := &compv1alpha1.ComplianceScan{}
+			err = reconciler.Client.Get(ctx, nodeScanKey, scan)
+			Expect(err).To(BeNil())
+			err = reconciler.Client.Delete(ctx, scan)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should only launch the scans once the other suite is done", func() {
+			By("Waiting while the other suite is running")
+			rescheduleWithDelay, err := reconciler.reconcileScans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(rescheduleWithDelay).To(BeTrue())
+			err = reconciler.Client.Get(ctx, nodeScanKey, &compv1alpha1.ComplianceScan{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Launching the scans once the other suite is done")
+			dependency.Status.Phase = compv1alpha1.PhaseDone
+			err = reconciler.Client.Status().Update(ctx, dependency)
+			Expect(err).To(BeNil())
+			rescheduleWithDelay, err = reconciler.reconcileScans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(rescheduleWithDelay).To(BeFalse())
+			err = reconciler.Client.Get(ctx, nodeScanKey, &compv1alpha1.ComplianceScan{})
+			Expect(err).To(BeNil())
+		})
+
+		It("Should wait for a suite that doesn't exist yet", func() {
+			suite.Spec.DependsOn = []string{"nonexistentSuite"}
+			rescheduleWithDelay, err := reconciler.reconcileScans(suite, logger)
+			Expect(err).To(BeNil())
+			Expect(rescheduleWithDelay).To(BeTrue())
+		})
+
+		It("Should be invalid if the suites depend on each other", func() {
+			isValid, _ := reconciler.validateDependencies(suite)
+			Expect(isValid).To(BeTrue())
+
+			dependency.Spec.DependsOn = []string{suiteName}
+			err := reconciler.Client.Update(ctx, dependency)
+			Expect(err).To(BeNil())
+			isValid, errorMsg := reconciler.validateDependencies(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(Equal("The suite depends on itself"))
+		})
+	})
+
+	Context("When a scan of the suite finishes", func() {
+		var recorder *record.FakeRecorder
+		var scan *compv1alpha1.ComplianceScan
+
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(10)
+			reconciler.Recorder = recorder
+
+			suite.Status.ScanStatuses = []compv1alpha1.ComplianceScanStatusWrapper{
+				{
+					Name:                 "testScanNode",
+					ComplianceScanStatus: compv1alpha1.ComplianceScanStatus{Phase: compv1alpha1.PhaseRunning},
+				},
+			}
+			err := reconciler.Client.Status().Update(ctx, suite)
+			Expect(err).To(BeNil())
+
+			scan = &compv1alpha1.ComplianceScan{}
+			err = reconciler.Client.Get(ctx, types.NamespacedName{Name: "testScanNode", Namespace: namespace}, scan)
+			Expect(err).To(BeNil())
+			scan.Status.Phase = compv1alpha1.PhaseDone
+		})
+
+		It("Should issue an event with the result of the scan", func() {
+			scan.Status.Result = compv1alpha1.ResultNonCompliant
+			err := reconciler.updateScanStatus(suite, 0, &suite.Status.ScanStatuses[0], scan, logger)
+			Expect(err).To(BeNil())
+			Expect(recorder.Events).To(Receive(Equal("Normal ScanDone Scan testScanNode is done, its result is: NON-COMPLIANT")))
+		})
+
+		It("Should issue a warning if the scan errored out", func() {
+			scan.Status.Result = compv1alpha1.ResultError
+			scan.Status.ErrorMessage = "the content can't be parsed"
+			err := reconciler.updateScanStatus(suite, 0, &suite.Status.ScanStatuses[0], scan, logger)
+			Expect(err).To(BeNil())
+			Expect(recorder.Events).To(Receive(Equal("Warning ScanError Scan testScanNode errored out: the content can't be parsed")))
+		})
+	})
+
+	Context("With webhooks", func() {
+		var (
+			server    *httptest.Server
+			calls     map[string][]map[string]interface{}
+			statuses  []int
+			runs      int
+			stopQueue context.CancelFunc
+		)
+
+		// finishRun finishes a run of the scans with the result, and waits
+		// for the given number of webhooks to be called in the background
+		finishRun := func(result compv1alpha1.ComplianceScanStatusResult, webhookCalls int) {
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, suite)).To(Succeed())
+			runs++
+			started := metav1.NewTime(time.Now().Add(time.Duration(runs) * time.Minute).Truncate(time.Second))
+			scanStatus := compv1alpha1.ComplianceScanStatusWrapper{Name: "testScanNode"}
+			scanStatus.Phase = compv1alpha1.PhaseDone
+			scanStatus.Result = result
+			scanStatus.StartTimestamp = &started
+			suite.Status.ScanStatuses = []compv1alpha1.ComplianceScanStatusWrapper{scanStatus}
+			suite.Status.Phase = compv1alpha1.PhaseDone
+			suite.Status.Result = result
+			Expect(reconciler.Client.Status().Update(ctx, suite)).To(Succeed())
+			Expect(reconciler.reconcileWebhooks(suite, logger)).To(Succeed())
+			events := reconciler.Recorder.(*record.FakeRecorder).Events
+			for i := 0; i < webhookCalls; i++ {
+				Eventually(events).Should(Receive(ContainSubstring("Notification")))
+			}
+		}
+		getStatus := func(name string) compv1alpha1.WebhookStatus {
+			updated := &compv1alpha1.ComplianceSuite{}
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, updated)).To(Succeed())
+			for _, status := range updated.Status.Webhooks {
+				if status.Name == name {
+					return status
+				}
+			}
+			Fail("no status for the webhook " + name)
+			return compv1alpha1.WebhookStatus{}
+		}
+
+		BeforeEach(func() {
+			calls = map[string][]map[string]interface{}{}
+			statuses = nil
+			runs = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(statuses) > 0 {
+					w.WriteHeader(statuses[0])
+					statuses = statuses[1:]
+					return
+				}
+				payload := map[string]interface{}{}
+				Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+				payload["authorization"] = r.Header.Get("Authorization")
+				calls[r.URL.Path] = append(calls[r.URL.Path], payload)
+			}))
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: namespace},
+				Data:       map[string][]byte{forwardingTokenKey: []byte("webhook-token")},
+			}
+			Expect(reconciler.Client.Create(ctx, secret)).To(Succeed())
+			retries := 0
+			suite.Spec.Webhooks = []compv1alpha1.Webhook{
+				{
+					Name:            "done",
+					URL:             server.URL + "/done",
+					PayloadTemplate: `{"text": {{ printf "%s is %s" .Suite .Result | json }}}`,
+					RetryPolicy:     &compv1alpha1.WebhookRetryPolicy{MaxRetries: &retries},
+				},
+				{
+					Name:           "non-compliant",
+					URL:            server.URL + "/non-compliant",
+					Trigger:        compv1alpha1.WebhookOnNonCompliant,
+					AuthSecretName: "webhook",
+				},
+			}
+			Expect(reconciler.Client.Update(ctx, suite)).To(Succeed())
+			reconciler.Recorder = record.NewFakeRecorder(10)
+			var queueCtx context.Context
+			queueCtx, stopQueue = context.WithCancel(ctx)
+			go reconciler.notifications.Start(queueCtx)
+		})
+
+		AfterEach(func() {
+			stopQueue()
+			server.Close()
+		})
+
+		It("Should call the webhooks once per scan run", func() {
+			finishRun(compv1alpha1.ResultNonCompliant, 2)
+			Expect(calls["/done"]).To(HaveLen(1))
+			Expect(calls["/done"][0]).To(HaveKeyWithValue("text", "testSuite is NON-COMPLIANT"))
+			Expect(calls["/non-compliant"]).To(HaveLen(1))
+			Expect(calls["/non-compliant"][0]).To(And(
+				HaveKeyWithValue("authorization", "Bearer webhook-token"),
+				HaveKeyWithValue("trigger", string(compv1alpha1.WebhookOnNonCompliant)),
+				HaveKeyWithValue("result", string(compv1alpha1.ResultNonCompliant)),
+				HaveKeyWithValue("scans", ConsistOf(HaveKeyWithValue("name", "testScanNode"))),
+			))
+			Expect(getStatus("done").CalledAt).ToNot(BeNil())
+
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, suite)).To(Succeed())
+			Expect(reconciler.reconcileWebhooks(suite, logger)).To(Succeed())
+			Consistently(reconciler.Recorder.(*record.FakeRecorder).Events, "100ms").ShouldNot(Receive())
+			Expect(calls["/done"]).To(HaveLen(1))
+			Expect(calls["/non-compliant"]).To(HaveLen(1))
+		})
+
+		It("Should only call the NonCompliant webhooks when the suite becomes NON-COMPLIANT", func() {
+			finishRun(compv1alpha1.ResultNonCompliant, 2)
+			finishRun(compv1alpha1.ResultNonCompliant, 1)
+			Expect(calls["/done"]).To(HaveLen(2))
+			Expect(calls["/non-compliant"]).To(HaveLen(1))
+
+			finishRun(compv1alpha1.ResultCompliant, 1)
+			Expect(getStatus("non-compliant").Result).To(Equal(compv1alpha1.ResultCompliant))
+			finishRun(compv1alpha1.ResultNonCompliant, 2)
+			Expect(calls["/done"]).To(HaveLen(4))
+			Expect(calls["/non-compliant"]).To(HaveLen(2))
+		})
+
+		It("Should record the calls that failed once their retries are exhausted", func() {
+			statuses = []int{http.StatusServiceUnavailable}
+			suite.Spec.Webhooks = suite.Spec.Webhooks[:1]
+			Expect(reconciler.Client.Update(ctx, suite)).To(Succeed())
+			finishRun(compv1alpha1.ResultCompliant, 1)
+			Expect(calls["/done"]).To(BeEmpty())
+			status := getStatus("done")
+			Expect(status.Error).To(ContainSubstring("503 Service Unavailable"))
+			Expect(status.CalledAt).To(BeNil())
+
+			Expect(reconciler.Client.Get(ctx, types.NamespacedName{Name: suiteName, Namespace: namespace}, suite)).To(Succeed())
+			Expect(reconciler.reconcileWebhooks(suite, logger)).To(Succeed())
+			Consistently(reconciler.Recorder.(*record.FakeRecorder).Events, "100ms").ShouldNot(Receive())
+			Expect(calls["/done"]).To(BeEmpty())
+		})
+
+		It("Should reject webhooks with a wrongly formatted payload template", func() {
+			suite.Spec.Webhooks[0].PayloadTemplate = "{{ .Suite"
+			isValid, errorMsg := reconciler.validateSuite(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(ContainSubstring("payload template"))
+		})
+
+		It("Should reject webhooks that are defined twice", func() {
+			suite.Spec.Webhooks[1].Name = "done"
+			isValid, errorMsg := reconciler.validateSuite(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(ContainSubstring("defined more than once"))
+		})
+	})
+
+	Context("With an Insights summary", func() {
+		newCheck := func(name, ruleID string, status compv1alpha1.ComplianceCheckStatus, severity compv1alpha1.ComplianceCheckResultSeverity) *compv1alpha1.ComplianceCheckResult {
+			return &compv1alpha1.ComplianceCheckResult{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels: map[string]string{
+						compv1alpha1.SuiteLabel:                         suiteName,
+						compv1alpha1.ComplianceCheckResultStatusLabel:   string(status),
+						compv1alpha1.ComplianceCheckResultSeverityLabel: string(severity),
+					},
+				},
+				ID:       ruleID,
+				Status:   status,
+				Severity: severity,
+			}
+		}
+		getSummaries := func() map[string]string {
+			cm := &corev1.ConfigMap{}
+			key := types.NamespacedName{Name: insightsSummaryConfigMapName, Namespace: insightsSummaryNamespace}
+			Expect(reconciler.Client.Get(ctx, key, cm)).To(Succeed())
+			return cm.Data
+		}
+
+		BeforeEach(func() {
+			rulePrefix := "xccdf_org.ssgproject.content_rule_"
+			for _, obj := range []client.Object{
+				newCheck("master-audit", rulePrefix+"audit", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityHigh),
+				newCheck("worker-audit", rulePrefix+"audit", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityHigh),
+				newCheck("master-banner", rulePrefix+"banner", compv1alpha1.CheckResultFail, compv1alpha1.CheckResultSeverityLow),
+				newCheck("master-fips", rulePrefix+"fips", compv1alpha1.CheckResultPass, compv1alpha1.CheckResultSeverityHigh),
+			} {
+				Expect(reconciler.Client.Create(ctx, obj)).To(Succeed())
+			}
+			suite.Spec.InsightsSummary = true
+			Expect(reconciler.Client.Update(ctx, suite)).To(Succeed())
+			started := metav1.NewTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+			scanStatus := compv1alpha1.ComplianceScanStatusWrapper{Name: "testScanNode"}
+			scanStatus.StartTimestamp = &started
+			suite.Status.ScanStatuses = []compv1alpha1.ComplianceScanStatusWrapper{scanStatus}
+			suite.Status.Phase = compv1alpha1.PhaseDone
+			suite.Status.Result = compv1alpha1.ResultNonCompliant
+			suite.Status.ResultCounts = &compv1alpha1.ResultCounts{
+				Total:   4,
+				Results: compv1alpha1.CheckStatusCounts{Pass: 1, Fail: 3},
+			}
+			reconciler.Recorder = record.NewFakeRecorder(10)
+		})
+
+		stopSharing := func(suite *compv1alpha1.ComplianceSuite) {
+			status := suite.Status
+			suite.Spec.InsightsSummary = false
+			Expect(reconciler.Client.Update(ctx, suite)).To(Succeed())
+			suite.Status = status
+		}
+
+		It("Should keep the summary of the suite in the ConfigMap the Insights Operator gathers", func() {
+			reconciler.reconcileInsightsSummary(suite, logger)
+			summaries := getSummaries()
+			Expect(summaries).To(HaveLen(1))
+			var summary insightsSummary
+			Expect(json.Unmarshal([]byte(summaries["test-ns.testSuite.json"]), &summary)).To(Succeed())
+			Expect(summary).To(Equal(insightsSummary{
+				Suite:                   suiteName,
+				Namespace:               namespace,
+				Result:                  string(compv1alpha1.ResultNonCompliant),
+				ScanRun:                 "2024-01-02T03:04:05Z",
+				Checks:                  compv1alpha1.CheckStatusCounts{Pass: 1, Fail: 3},
+				FailedHighSeverityRules: []string{"xccdf_org.ssgproject.content_rule_audit"},
+			}))
+
+			Expect(suite.Status.InsightsSummaryShared).To(BeTrue())
+
+			By("Keeping the summaries of the other suites")
+			other := &compv1alpha1.ComplianceSuite{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-suite", Namespace: namespace},
+				Spec:       *suite.Spec.DeepCopy(),
+			}
+			Expect(reconciler.Client.Create(ctx, other)).To(Succeed())
+			other.Status = *suite.Status.DeepCopy()
+			other.Status.Result = compv1alpha1.ResultCompliant
+			reconciler.reconcileInsightsSummary(other, logger)
+			Expect(getSummaries()).To(HaveLen(2))
+			Expect(getSummaries()).To(HaveKeyWithValue("test-ns.other-suite.json", ContainSubstring(`"result":"COMPLIANT"`)))
+
+			By("Removing the summary once the suite no longer shares it")
+			stopSharing(suite)
+			reconciler.reconcileInsightsSummary(suite, logger)
+			Expect(getSummaries()).To(HaveLen(1))
+			Expect(getSummaries()).To(HaveKey("test-ns.other-suite.json"))
+			Expect(suite.Status.InsightsSummaryShared).To(BeFalse())
+
+			By("Removing the summary once the suite is deleted")
+			Expect(reconciler.suiteDeleteHandler(other, logger)).To(Succeed())
+			Expect(getSummaries()).To(BeEmpty())
+		})
+
+		It("Should not create the ConfigMap for suites that don't share their summary", func() {
+			stopSharing(suite)
+			reconciler.reconcileInsightsSummary(suite, logger)
+			cm := &corev1.ConfigMap{}
+			key := types.NamespacedName{Name: insightsSummaryConfigMapName, Namespace: insightsSummaryNamespace}
+			err := reconciler.Client.Get(ctx, key, cm)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should not clean up the ConfigMap for suites that never shared their summary", func() {
+			reconciler.reconcileInsightsSummary(suite, logger)
+			stopSharing(suite)
+			// The ConfigMap isn't even read for the suites whose status
+			// doesn't tell that they shared their summary
+			suite.Status.InsightsSummaryShared = false
+			reconciler.reconcileInsightsSummary(suite, logger)
+			Expect(reconciler.suiteDeleteHandler(suite, logger)).To(Succeed())
+			Expect(getSummaries()).To(HaveKey("test-ns.testSuite.json"))
+		})
+	})
+})
+
+var _ = Describe("Testing the remediation apply window", func() {
+	var suite *compv1alpha1.ComplianceSuite
+
+	BeforeEach(func() {
+		suite = &compv1alpha1.ComplianceSuite{
+			Spec: compv1alpha1.ComplianceSuiteSpec{
+				ComplianceSuiteSettings: compv1alpha1.ComplianceSuiteSettings{
+					RemediationApplyWindow: &compv1alpha1.RemediationApplyWindow{
+						Schedule: "0 2 * * *",
+						Duration: metav1.Duration{Duration: 2 * time.Hour},
+					},
+				},
+			},
+		}
+	})
+
+	It("Should be open within the window", func() {
+		open, _ := remediationApplyWindowOpen(suite, time.Date(2024, 3, 1, 3, 30, 0, 0, time.Local))
+		Expect(open).To(BeTrue())
+	})
+
+	It("Should tell when the window opens outside of it", func() {
+		open, opensIn := remediationApplyWindowOpen(suite, time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local))
+		Expect(open).To(BeFalse())
+		Expect(opensIn).To(Equal(14 * time.Hour))
+	})
+
+	It("Should be open if the remediations are applied through the annotation", func() {
+		suite.Annotations = map[string]string{compv1alpha1.ApplyRemediationsAnnotation: ""}
+		open, _ := remediationApplyWindowOpen(suite, time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local))
+		Expect(open).To(BeTrue())
+	})
+})
+
+var _ = Describe("Testing the rerunner schedule", func() {
+	var (
+		suite      *compv1alpha1.ComplianceSuite
+		reconciler *ReconcileComplianceSuite
+		ctx        = context.Background()
+	)
+
+	BeforeEach(func() {
+		suite = &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "testSuite",
+				Namespace: "test-ns",
+			},
+			Spec: compv1alpha1.ComplianceSuiteSpec{
+				ComplianceSuiteSettings: compv1alpha1.ComplianceSuiteSettings{
+					Schedule: "0 2 * * *",
+					Timezone: "Europe/Berlin",
+				},
+				Scans: []compv1alpha1.ComplianceScanSpecWrapper{
+					{Name: "ocp4-cis"},
+					{Name: "ocp4-cis-node-master"},
+					{Name: "ocp4-cis-node-worker"},
+				},
+			},
+		}
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		reconciler = &ReconcileComplianceSuite{Client: fake.NewClientBuilder().WithScheme(cscheme).Build()}
+	})
+
+	It("Should interpret the schedule in the time zone of the suite", func() {
+		isValid, _ := reconciler.validateSchedule(suite)
+		Expect(isValid).To(BeTrue())
+		c := reconciler.generateRerunnerSpec(suite, suiteSchedule(suite), "")
+		Expect(c.Spec.Schedule).To(Equal("0 2 * * *"))
+		Expect(c.Spec.TimeZone).ToNot(BeNil())
+		Expect(*c.Spec.TimeZone).To(Equal("Europe/Berlin"))
+	})
+
+	It("Should leave the time zone unset by default", func() {
+		suite.Spec.Timezone = ""
+		c := reconciler.generateRerunnerSpec(suite, suiteSchedule(suite), "")
+		Expect(c.Spec.TimeZone).To(BeNil())
+	})
+
+	It("Should reject time zones that aren't IANA names", func() {
+		for _, tz := range []string{"Mars/Olympus_Mons", "Local"} {
+			suite.Spec.Timezone = tz
+			isValid, errorMsg := reconciler.validateSchedule(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(ContainSubstring(tz))
+		}
+	})
+
+	It("Should delay the scheduled runs by the jitter of the suite", func() {
+		c := reconciler.generateRerunnerSpec(suite, suiteSchedule(suite), "")
+		Expect(c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command).ToNot(ContainElement("--jitter"))
+
+		suite.Spec.ScheduleJitter = &metav1.Duration{Duration: 45 * time.Minute}
+		isValid, _ := reconciler.validateSchedule(suite)
+		Expect(isValid).To(BeTrue())
+		c = reconciler.generateRerunnerSpec(suite, suiteSchedule(suite), "")
+		Expect(c.Spec.Schedule).To(Equal("0 2 * * *"))
+		Expect(c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--jitter", "45m0s"))
+
+		suite.Spec.ScheduleJitter = &metav1.Duration{Duration: -time.Minute}
+		isValid, errorMsg := reconciler.validateSchedule(suite)
+		Expect(isValid).To(BeFalse())
+		Expect(errorMsg).To(ContainSubstring("jitter"))
+	})
+	Context("With additional schedules", func() {
+		var logger logr.Logger
+
+		BeforeEach(func() {
+			suite.Spec.AdditionalSchedules = []compv1alpha1.ScanSchedule{
+				{
+					Name:     "daily",
+					Schedule: "0 3 * * *",
+					Scans:    []string{"ocp4-cis-node-*"},
+				},
+			}
+			zaplog, _ := zap.NewDevelopment()
+			logger = zapr.NewLogger(zaplog)
+		})
+
+		It("Should create a rerunner that only re-runs the scans of the schedule", func() {
+			isValid, _ := reconciler.validateSchedule(suite)
+			Expect(isValid).To(BeTrue())
+			Expect(reconciler.reconcileAdditionalRerunners(suite, logger)).To(Succeed())
+
+			c := &batchv1.CronJob{}
+			key := scheduleReRunnerNamespacedName(suite.Name, "daily")
+			Expect(key.Name).To(Equal("testSuite-daily-rerunner"))
+			Expect(reconciler.Client.Get(ctx, key, c)).To(Succeed())
+			Expect(c.Spec.Schedule).To(Equal("0 3 * * *"))
+			Expect(*c.Spec.TimeZone).To(Equal("Europe/Berlin"))
+			Expect(c.Labels).To(HaveKeyWithValue(compv1alpha1.SuiteScheduleLabel, "daily"))
+			Expect(c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command).To(ContainElements("--scans", "ocp4-cis-node-*"))
+
+			By("Deleting the rerunner once the schedule is removed")
+			suite.Spec.AdditionalSchedules = nil
+			Expect(reconciler.reconcileAdditionalRerunners(suite, logger)).To(Succeed())
+			err := reconciler.Client.Get(ctx, key, c)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should reject schedules that run none of the scans", func() {
+			suite.Spec.AdditionalSchedules[0].Scans = []string{"rhcos4-*"}
+			isValid, errorMsg := reconciler.validateSchedule(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(ContainSubstring("rhcos4-*"))
+		})
+
+		It("Should reject schedules that are defined twice", func() {
+			suite.Spec.AdditionalSchedules = append(suite.Spec.AdditionalSchedules, suite.Spec.AdditionalSchedules[0])
+			isValid, errorMsg := reconciler.validateSchedule(suite)
+			Expect(isValid).To(BeFalse())
+			Expect(errorMsg).To(ContainSubstring("defined more than once"))
+		})
+	})
 })
@@ -0,0 +1,172 @@
+package compliancesuite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// The Insights Operator gathers the ConfigMaps of the openshift-config
+// namespace into the data it uploads. The namespace isn't cached, so the
+// ConfigMap is read through the API reader.
+const (
+	insightsSummaryNamespace     = "openshift-config"
+	insightsSummaryConfigMapName = "compliance-operator-insights"
+)
+
+// insightsSummary is the compact summary of the results of a suite that's
+// uploaded by the Insights Operator
+type insightsSummary struct {
+	Suite     string `json:"suite"`
+	Namespace string `json:"namespace"`
+	Result    string `json:"result"`
+	ScanRun   string `json:"scanRun,omitempty"`
+	// The number of checks by their status
+	Checks compv1alpha1.CheckStatusCounts `json:"checks"`
+	// The IDs of the high severity rules that failed
+	FailedHighSeverityRules []string `json:"failedHighSeverityRules"`
+}
+
+// insightsSummaryKey returns the key of the summary of the suite in the
+// ConfigMap. Namespaces can't contain dots, so the keys are unique.
+func insightsSummaryKey(suite *compv1alpha1.ComplianceSuite) string {
+	return suite.Namespace + "." + suite.Name + ".json"
+}
+
+// reconcileInsightsSummary keeps the summary of the results of the suite in
+// the ConfigMap that the Insights Operator gathers once its scans are done,
+// and removes it once the suite no longer shares its summary. Whether the
+// summary was shared is kept in the status of the suite, which is updated in
+// place, so that the suites that never shared it don't read the ConfigMap.
+// Since the summary is informational, failures are only reported.
+func (r *ReconcileComplianceSuite) reconcileInsightsSummary(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) {
+	var err error
+	shared := suite.Status.InsightsSummaryShared
+	if !suite.Spec.InsightsSummary {
+		if shared {
+			err = r.deleteInsightsSummary(suite)
+			shared = err != nil
+		}
+	} else if suite.Status.Phase == compv1alpha1.PhaseDone {
+		err = r.updateInsightsSummary(suite, logger)
+		shared = shared || err == nil
+	}
+	if err == nil && shared != suite.Status.InsightsSummaryShared {
+		suite.Status.InsightsSummaryShared = shared
+		err = r.updateStatus(suite)
+	}
+	if err != nil {
+		logger.Error(err, "Cannot update the Insights summary of the suite")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(suite, corev1.EventTypeWarning, "InsightsSummaryFailed",
+				"The summary of the suite couldn't be updated in the ConfigMap %s/%s: %s",
+				insightsSummaryNamespace, insightsSummaryConfigMapName, err)
+		}
+	}
+}
+
+// newInsightsSummary returns the summary of the latest results of the suite
+func (r *ReconcileComplianceSuite) newInsightsSummary(suite *compv1alpha1.ComplianceSuite) (*insightsSummary, error) {
+	var checkList compv1alpha1.ComplianceCheckResultList
+	listOpts := client.MatchingLabels{
+		compv1alpha1.SuiteLabel:                         suite.Name,
+		compv1alpha1.ComplianceCheckResultStatusLabel:   string(compv1alpha1.CheckResultFail),
+		compv1alpha1.ComplianceCheckResultSeverityLabel: string(compv1alpha1.CheckResultSeverityHigh),
+	}
+	if err := r.Client.List(context.TODO(), &checkList, client.InNamespace(suite.Namespace), listOpts); err != nil {
+		return nil, err
+	}
+	summary := &insightsSummary{
+		Suite:                   suite.Name,
+		Namespace:               suite.Namespace,
+		Result:                  string(suite.Status.Result),
+		FailedHighSeverityRules: []string{},
+	}
+	if scanRun := getSuiteScanRun(suite); !scanRun.IsZero() {
+		summary.ScanRun = scanRun.UTC().Format(time.RFC3339)
+	}
+	if suite.Status.ResultCounts != nil {
+		summary.Checks = suite.Status.ResultCounts.Results
+	}
+	// The same rule fails once per scan and per node role
+	seen := map[string]bool{}
+	for _, check := range checkList.Items {
+		if !seen[check.ID] {
+			seen[check.ID] = true
+			summary.FailedHighSeverityRules = append(summary.FailedHighSeverityRules, check.ID)
+		}
+	}
+	sort.Strings(summary.FailedHighSeverityRules)
+	return summary, nil
+}
+
+// updateInsightsSummary writes the summary of the suite to the ConfigMap, and
+// creates the ConfigMap if it doesn't exist yet
+func (r *ReconcileComplianceSuite) updateInsightsSummary(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	summary, err := r.newInsightsSummary(suite)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	key := insightsSummaryKey(suite)
+
+	cm := &corev1.ConfigMap{}
+	err = r.Reader.Get(context.TODO(), types.NamespacedName{Name: insightsSummaryConfigMapName, Namespace: insightsSummaryNamespace}, cm)
+	if errors.IsNotFound(err) {
+		logger.Info("Creating the Insights summary ConfigMap", "ConfigMap.Namespace", insightsSummaryNamespace)
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      insightsSummaryConfigMapName,
+				Namespace: insightsSummaryNamespace,
+			},
+			Data: map[string]string{key: string(data)},
+		}
+		return r.Client.Create(context.TODO(), cm)
+	} else if err != nil {
+		return err
+	}
+	if cm.Data[key] == string(data) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[key] = string(data)
+	logger.Info("Updating the Insights summary of the suite", "result", summary.Result)
+	return r.Client.Update(context.TODO(), cm)
+}
+
+// deleteInsightsSummary removes the summary of the suite from the ConfigMap,
+// if it's there
+func (r *ReconcileComplianceSuite) deleteInsightsSummary(suite *compv1alpha1.ComplianceSuite) error {
+	cm := &corev1.ConfigMap{}
+	err := r.Reader.Get(context.TODO(), types.NamespacedName{Name: insightsSummaryConfigMapName, Namespace: insightsSummaryNamespace}, cm)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	key := insightsSummaryKey(suite)
+	if _, ok := cm.Data[key]; !ok {
+		return nil
+	}
+	delete(cm.Data, key)
+	if err := r.Client.Update(context.TODO(), cm); err != nil {
+		return fmt.Errorf("cannot remove the summary of the suite: %w", err)
+	}
+	return nil
+}
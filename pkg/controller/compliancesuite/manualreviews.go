@@ -0,0 +1,135 @@
+package compliancesuite
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// manualReviewChanged only lets through the updates of check results that
+// change their manual review, as the rest of the updates are made by the
+// aggregator while the scan runs
+var manualReviewChanged = predicate.Funcs{
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldCheck, oldOk := e.ObjectOld.(*compv1alpha1.ComplianceCheckResult)
+		newCheck, newOk := e.ObjectNew.(*compv1alpha1.ComplianceCheckResult)
+		if !oldOk || !newOk {
+			return false
+		}
+		return !equality.Semantic.DeepEqual(oldCheck.Review, newCheck.Review)
+	},
+}
+
+// mapCheckResultToSuite enqueues the suite of a check result
+func mapCheckResultToSuite(_ context.Context, obj client.Object) []reconcile.Request {
+	suiteName := obj.GetLabels()[compv1alpha1.SuiteLabel]
+	if suiteName == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: suiteName, Namespace: obj.GetNamespace()}},
+	}
+}
+
+// applyManualCheckReviews records the reviews of the MANUAL checks of a
+// finished suite. A non-compliant suite whose only failures are MANUAL
+// checks that all passed their review is reported as compliant, and a
+// compliant suite with a MANUAL check that failed its review is reported as
+// non-compliant. Note that the suite is modified in place.
+func (r *ReconcileComplianceSuite) applyManualCheckReviews(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	suite.Status.ManualChecks = nil
+	if suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+
+	var checkList compv1alpha1.ComplianceCheckResultList
+	listOpts := client.MatchingLabels{
+		compv1alpha1.SuiteLabel:                       suite.Name,
+		compv1alpha1.ComplianceCheckResultStatusLabel: string(compv1alpha1.CheckResultManual),
+	}
+	if err := r.Client.List(context.TODO(), &checkList, client.InNamespace(suite.Namespace), listOpts); err != nil {
+		return err
+	}
+	if len(checkList.Items) == 0 {
+		return nil
+	}
+
+	manualStatus := &compv1alpha1.ManualChecksStatus{}
+	for i := range checkList.Items {
+		review := checkList.Items[i].Review
+		switch {
+		case review == nil:
+			manualStatus.Pending++
+		case review.Outcome == compv1alpha1.CheckResultPass:
+			manualStatus.Passed++
+		default:
+			manualStatus.Failed++
+		}
+	}
+	suite.Status.ManualChecks = manualStatus
+
+	switch suite.Status.Result {
+	case compv1alpha1.ResultNonCompliant:
+		if manualStatus.Failed > 0 || manualStatus.Pending > 0 {
+			return nil
+		}
+		failed, err := r.countSuiteCheckResults(suite, compv1alpha1.CheckResultFail)
+		if err != nil {
+			return err
+		}
+		if failed == 0 {
+			logger.Info("All the MANUAL checks of the suite passed their review, reporting it as compliant",
+				"Passed", manualStatus.Passed)
+			suite.Status.Result = compv1alpha1.ResultCompliant
+		}
+	case compv1alpha1.ResultCompliant:
+		if manualStatus.Failed > 0 {
+			logger.Info("MANUAL checks of the suite failed their review, reporting it as non-compliant",
+				"Failed", manualStatus.Failed)
+			suite.Status.Result = compv1alpha1.ResultNonCompliant
+		}
+	}
+	return nil
+}
+
+// reconcileManualCheckReviews updates the result of a finished suite when
+// the reviews of its MANUAL checks change. It returns whether the status of
+// the suite was updated.
+func (r *ReconcileComplianceSuite) reconcileManualCheckReviews(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (bool, error) {
+	if suite.Status.Phase != compv1alpha1.PhaseDone {
+		return false, nil
+	}
+
+	sCopy := suite.DeepCopy()
+	sCopy.Status.Result = sCopy.LowestCommonResult()
+	if err := r.applyManualCheckReviews(sCopy, logger); err != nil {
+		return false, err
+	}
+	// Suites without MANUAL checks have nothing to review
+	if sCopy.Status.ManualChecks == nil && suite.Status.ManualChecks == nil {
+		return false, nil
+	}
+	if err := r.applyComplianceThreshold(sCopy, logger); err != nil {
+		return false, err
+	}
+	if equality.Semantic.DeepEqual(suite.Status, sCopy.Status) {
+		return false, nil
+	}
+
+	logger.Info("Updating the result of the suite with the reviews of its MANUAL checks", "Result", sCopy.Status.Result)
+	if err := r.updateStatus(sCopy); err != nil {
+		return false, err
+	}
+	return true, r.setSuiteMetric(sCopy)
+}
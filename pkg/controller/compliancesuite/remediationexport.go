@@ -0,0 +1,189 @@
+package compliancesuite
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	kustomizationFileName = "kustomization.yaml"
+	// The API server refuses ConfigMaps that are bigger than this
+	maxRemediationExportSize = 1024 * 1024
+)
+
+// reconcileRemediationExport exports the remediations of the suite into the
+// ConfigMap of its remediation export once its scans are done
+func (r *ReconcileComplianceSuite) reconcileRemediationExport(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	export := suite.Spec.RemediationExport
+	if export == nil || suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+
+	data, err := r.exportRemediations(suite, export, logger)
+	if err != nil {
+		return err
+	}
+	size := 0
+	for key, manifest := range data {
+		size += len(key) + len(manifest)
+	}
+	if size > maxRemediationExportSize {
+		logger.Info("The exported remediations don't fit into a ConfigMap", "ConfigMap.Name", export.ConfigMapName, "size", size)
+		r.Recorder.Event(suite, corev1.EventTypeWarning, "RemediationExportFailed",
+			fmt.Sprintf("The exported remediations don't fit into the ConfigMap %s, consider filtering them", export.ConfigMapName))
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: export.ConfigMapName, Namespace: suite.Namespace}, cm)
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      export.ConfigMapName,
+				Namespace: suite.Namespace,
+				Labels: map[string]string{
+					compv1alpha1.SuiteLabel: suite.Name,
+				},
+			},
+			Data: data,
+		}
+		if err := controllerutil.SetControllerReference(suite, cm, r.Scheme); err != nil {
+			return err
+		}
+		logger.Info("Exporting the remediations", "ConfigMap.Name", cm.Name, "manifests", len(data))
+		if err := r.Client.Create(context.TODO(), cm); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		if reflect.DeepEqual(cm.Data, data) {
+			return nil
+		}
+		cmCopy := cm.DeepCopy()
+		cmCopy.Data = data
+		logger.Info("Updating the exported remediations", "ConfigMap.Name", cm.Name, "manifests", len(data))
+		if err := r.Client.Update(context.TODO(), cmCopy); err != nil {
+			return err
+		}
+	}
+	r.Recorder.Event(suite, corev1.EventTypeNormal, "RemediationsExported",
+		fmt.Sprintf("The remediations were exported to the ConfigMap %s", export.ConfigMapName))
+	return nil
+}
+
+// exportRemediations renders the manifests of the remediations of the suite
+// that pass the filter of the export, keyed by their file name.
+// MachineConfigs are completed the way the operator would apply them, and the
+// KubeletConfigs of a pool are merged into the one the operator would manage
+// for the pool, as the Machine Config Operator only renders one of them.
+func (r *ReconcileComplianceSuite) exportRemediations(suite *compv1alpha1.ComplianceSuite, export *compv1alpha1.RemediationExport,
+	logger logr.Logger) (map[string]string, error) {
+	remList := &compv1alpha1.ComplianceRemediationList{}
+	listOpts := client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{compv1alpha1.SuiteLabel: suite.Name}),
+	}
+	if err := r.Client.List(context.TODO(), remList, &listOpts); err != nil {
+		return nil, err
+	}
+	mcfgpools := &mcfgv1.MachineConfigPoolList{}
+	if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
+		return nil, err
+	}
+	sort.Slice(remList.Items, func(i, j int) bool {
+		return remList.Items[i].Name < remList.Items[j].Name
+	})
+
+	manifests := map[string]*unstructured.Unstructured{}
+	for i := range remList.Items {
+		rem := &remList.Items[i]
+		if rem.Spec.Current.Object == nil {
+			continue
+		}
+		if export.Filter != nil {
+			allowed, err := r.remediationPassesFilter(export.Filter, rem, logger)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				continue
+			}
+		}
+
+		obj := rem.Spec.Current.Object.DeepCopy()
+		fileName := rem.Name + ".yaml"
+		if utils.IsMachineConfig(obj) || utils.IsKubeletConfig(obj) {
+			scan := &compv1alpha1.ComplianceScan{}
+			scanKey := types.NamespacedName{Name: rem.Labels[compv1alpha1.ComplianceScanLabel], Namespace: rem.Namespace}
+			if err := r.Client.Get(context.TODO(), scanKey, scan); err != nil {
+				return nil, err
+			}
+			pool := r.getAffectedMcfgPool(scan, mcfgpools)
+			if pool == nil {
+				logger.Info("Not exporting remediation that doesn't have a matching MachineConfigPool", "ComplianceRemediation.Name", rem.Name)
+				continue
+			}
+			if utils.IsMachineConfig(obj) {
+				obj.SetName(rem.GetMcName())
+				obj.SetLabels(map[string]string{mcfgv1.MachineConfigRoleLabelKey: utils.GetFirstNodeRole(scan.Spec.NodeSelector)})
+			} else {
+				obj.SetName(utils.GetManagedKubeletConfigName(pool.Name))
+				poolSelector := map[string]string{"pools.operator.machineconfiguration.openshift.io/" + pool.Name: ""}
+				if err := unstructured.SetNestedStringMap(obj.Object, poolSelector, "spec", "machineConfigPoolSelector", "matchLabels"); err != nil {
+					return nil, err
+				}
+				fileName = obj.GetName() + ".yaml"
+				if merged, ok := manifests[fileName]; ok {
+					mergedSpec, _, _ := unstructured.NestedMap(merged.Object, "spec")
+					spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+					utils.MergeRemediationFields(mergedSpec, spec)
+					if err := unstructured.SetNestedMap(merged.Object, mergedSpec, "spec"); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+		}
+		manifests[fileName] = obj
+	}
+
+	data := make(map[string]string, len(manifests)+1)
+	fileNames := make([]string, 0, len(manifests))
+	for fileName, obj := range manifests {
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't render the manifest %s: %w", fileName, err)
+		}
+		data[fileName] = string(manifest)
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	if export.Format != compv1alpha1.RemediationExportManifests {
+		var kustomization strings.Builder
+		kustomization.WriteString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+		for _, fileName := range fileNames {
+			kustomization.WriteString("- " + fileName + "\n")
+		}
+		data[kustomizationFileName] = kustomization.String()
+	}
+	return data, nil
+}
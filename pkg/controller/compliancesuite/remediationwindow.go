@@ -0,0 +1,48 @@
+package compliancesuite
+
+import (
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// remediationApplyWindowRequester identifies the re-runs of suites that
+// verify the remediations applied within their remediation apply window
+const remediationApplyWindowRequester = "remediation-apply-window"
+
+// validates that the provided remediation apply window is correctly set.
+// Else it returns false (not valid) and an error message
+func (r *ReconcileComplianceSuite) validateRemediationApplyWindow(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	window := suite.Spec.RemediationApplyWindow
+	if window == nil {
+		return true, ""
+	}
+	if _, err := cron.ParseStandard(window.Schedule); err != nil {
+		return false, "ComplianceSuite's remediation apply window schedule is wrongly formatted"
+	}
+	if window.Duration.Duration <= 0 {
+		return false, "ComplianceSuite's remediation apply window duration must be positive"
+	}
+	return true, ""
+}
+
+// remediationApplyWindowOpen tells whether the remediations of the suite can
+// be applied automatically at the given time. If not, it also returns how
+// long it takes until the window opens.
+func remediationApplyWindowOpen(suite *compv1alpha1.ComplianceSuite, now time.Time) (bool, time.Duration) {
+	window := suite.Spec.RemediationApplyWindow
+	// Remediations that were requested through the annotation are
+	// applied right away
+	if window == nil || suite.ApplyRemediationsAnnotationSet() {
+		return true, 0
+	}
+	open, wait, err := utils.ApplyWindowOpen(window, now)
+	if err != nil {
+		// The suite is validated earlier, so this isn't expected
+		return true, 0
+	}
+	return open, wait
+}
@@ -0,0 +1,77 @@
+package compliancesuite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// rerunRequesterDefaulter records the user that sets the rerun annotation of
+// a ComplianceSuite, which can't be told from the suite itself. Any requester
+// set by the user is overwritten, and the requester is kept as long as the
+// annotation is. The webhook fails closed, so that no re-run is requested
+// without it recording the requester.
+type rerunRequesterDefaulter struct{}
+
+var _ admission.CustomDefaulter = &rerunRequesterDefaulter{}
+
+// rerunRequesterWebhookServed tells whether the webhook is served, the
+// requesters of the re-runs are set by hand otherwise and can't be trusted
+var rerunRequesterWebhookServed atomic.Bool
+
+//+kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-compliancesuite,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=compliancesuites,verbs=create;update,versions=v1alpha1,name=mcompliancesuite.compliance.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the webhook recording the requesters of
+// the re-runs of ComplianceSuites
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ComplianceSuite{}).
+		WithDefaulter(&rerunRequesterDefaulter{}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	rerunRequesterWebhookServed.Store(true)
+	return nil
+}
+
+func (d *rerunRequesterDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	suite, ok := obj.(*compv1alpha1.ComplianceSuite)
+	if !ok {
+		return fmt.Errorf("expected a ComplianceSuite but got a %T", obj)
+	}
+	if !suite.RerunAnnotationSet() {
+		delete(suite.Annotations, compv1alpha1.RerunRequesterAnnotation)
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	requester := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		old := &compv1alpha1.ComplianceSuite{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("cannot decode the ComplianceSuite being updated: %w", err)
+		}
+		// The re-run was requested by whoever set the annotation
+		if old.RerunAnnotationSet() {
+			requester = old.Annotations[compv1alpha1.RerunRequesterAnnotation]
+		}
+	}
+	if requester == "" {
+		delete(suite.Annotations, compv1alpha1.RerunRequesterAnnotation)
+		return nil
+	}
+	suite.Annotations[compv1alpha1.RerunRequesterAnnotation] = requester
+	return nil
+}
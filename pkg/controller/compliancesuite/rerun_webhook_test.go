@@ -0,0 +1,79 @@
+package compliancesuite
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("Testing the rerun requester webhook", func() {
+	var defaulter *rerunRequesterDefaulter
+
+	newRequest := func(operation admissionv1.Operation, old *compv1alpha1.ComplianceSuite) context.Context {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				UserInfo:  authenticationv1.UserInfo{Username: "kube:admin"},
+			},
+		}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			Expect(err).To(BeNil())
+			req.OldObject.Raw = raw
+		}
+		return admission.NewContextWithRequest(context.TODO(), req)
+	}
+	newSuite := func(annotations map[string]string) *compv1alpha1.ComplianceSuite {
+		return &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{Name: "cis", Annotations: annotations},
+		}
+	}
+
+	BeforeEach(func() {
+		defaulter = &rerunRequesterDefaulter{}
+	})
+
+	It("records the user that requested the re-run", func() {
+		old := newSuite(nil)
+		suite := newSuite(map[string]string{
+			compv1alpha1.RerunAnnotation:          "before-audit",
+			compv1alpha1.RerunRequesterAnnotation: "someone-else",
+		})
+		Expect(defaulter.Default(newRequest(admissionv1.Update, old), suite)).To(Succeed())
+		Expect(suite.Annotations).To(HaveKeyWithValue(compv1alpha1.RerunRequesterAnnotation, "kube:admin"))
+		Expect(suite.Annotations).To(HaveKeyWithValue(compv1alpha1.RerunAnnotation, "before-audit"))
+	})
+
+	It("records the user that created a suite with the annotation", func() {
+		suite := newSuite(map[string]string{compv1alpha1.RerunAnnotation: ""})
+		Expect(defaulter.Default(newRequest(admissionv1.Create, nil), suite)).To(Succeed())
+		Expect(suite.Annotations).To(HaveKeyWithValue(compv1alpha1.RerunRequesterAnnotation, "kube:admin"))
+	})
+
+	It("keeps the requester while the re-run is pending", func() {
+		old := newSuite(map[string]string{
+			compv1alpha1.RerunAnnotation:          "",
+			compv1alpha1.RerunRequesterAnnotation: "alice",
+		})
+		suite := newSuite(map[string]string{
+			compv1alpha1.RerunAnnotation:          "",
+			compv1alpha1.RerunRequesterAnnotation: "someone-else",
+		})
+		Expect(defaulter.Default(newRequest(admissionv1.Update, old), suite)).To(Succeed())
+		Expect(suite.Annotations).To(HaveKeyWithValue(compv1alpha1.RerunRequesterAnnotation, "alice"))
+	})
+
+	It("doesn't let the requester be set without a re-run", func() {
+		suite := newSuite(map[string]string{compv1alpha1.RerunRequesterAnnotation: "alice"})
+		Expect(defaulter.Default(newRequest(admissionv1.Create, nil), suite)).To(Succeed())
+		Expect(suite.Annotations).ToNot(HaveKey(compv1alpha1.RerunRequesterAnnotation))
+	})
+})
@@ -0,0 +1,89 @@
+package compliancesuite
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/go-logr/logr"
+	cron "github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+// validates that the additional schedules of the suite are correctly set.
+// Else it returns false (not valid) and an error message
+func (r *ReconcileComplianceSuite) validateAdditionalSchedules(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	seen := make(map[string]bool, len(suite.Spec.AdditionalSchedules))
+	for _, sched := range suite.Spec.AdditionalSchedules {
+		if seen[sched.Name] {
+			return false, fmt.Sprintf("ComplianceSuite's additional schedule '%s' is defined more than once", sched.Name)
+		}
+		seen[sched.Name] = true
+		if _, err := cron.ParseStandard(sched.Schedule); err != nil {
+			return false, fmt.Sprintf("ComplianceSuite's additional schedule '%s' is wrongly formatted", sched.Name)
+		}
+		for _, pattern := range sched.Scans {
+			if !scheduleMatchesAnyScan(suite, pattern) {
+				return false, fmt.Sprintf("ComplianceSuite's additional schedule '%s' runs '%s', which matches none of its scans", sched.Name, pattern)
+			}
+		}
+	}
+	return true, ""
+}
+
+func scheduleMatchesAnyScan(suite *compv1alpha1.ComplianceSuite, pattern string) bool {
+	for _, scanWrap := range suite.Spec.Scans {
+		// A malformed pattern matches no scan
+		if ok, _ := path.Match(pattern, scanWrap.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAdditionalRerunners makes sure each additional schedule of the
+// suite has its rerunner, and that the rerunners of the schedules that were
+// removed are gone
+func (r *ReconcileComplianceSuite) reconcileAdditionalRerunners(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	keep := make(map[string]bool, len(suite.Spec.AdditionalSchedules))
+	for i := range suite.Spec.AdditionalSchedules {
+		sched := &suite.Spec.AdditionalSchedules[i]
+		keep[sched.Name] = true
+		key := scheduleReRunnerNamespacedName(suite.Name, sched.Name)
+		if err := r.createOrUpdateScheduleRerunner(suite, sched, key, logger); err != nil {
+			return err
+		}
+	}
+	return r.deleteAdditionalRerunners(suite, keep, logger)
+}
+
+// deleteAdditionalRerunners deletes the rerunners of the additional schedules
+// of the suite that aren't kept, along with their jobs
+func (r *ReconcileComplianceSuite) deleteAdditionalRerunners(suite *compv1alpha1.ComplianceSuite, keep map[string]bool, logger logr.Logger) error {
+	cronJobs := &batchv1.CronJobList{}
+	err := r.Client.List(context.TODO(), cronJobs,
+		client.InNamespace(common.GetComplianceOperatorNamespace()),
+		client.MatchingLabels{compv1alpha1.SuiteLabel: suite.Name},
+		client.HasLabels{compv1alpha1.SuiteScheduleLabel})
+	if err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		c := &cronJobs.Items[i]
+		if keep[c.GetLabels()[compv1alpha1.SuiteScheduleLabel]] {
+			continue
+		}
+		logger.Info("Deleting rerunner", "CronJob.Name", c.GetName())
+		background := metav1.DeletePropagationBackground
+		err := r.Client.Delete(context.TODO(), c, &client.DeleteOptions{PropagationPolicy: &background})
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
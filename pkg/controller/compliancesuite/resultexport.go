@@ -0,0 +1,557 @@
+package compliancesuite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+	"github.com/ComplianceAsCode/compliance-operator/version"
+)
+
+const (
+	// The key of the ConfigMap that the assessment results are exported to,
+	// with a .gz suffix in the binary data if they need to be compressed
+	// to fit
+	assessmentResultsKey = "assessment-results.json"
+	oscalVersion         = "1.1.2"
+	// The keys of the flat exports of the check results
+	checkResultsCSVKey  = "check-results.csv"
+	checkResultsJSONKey = "check-results.json"
+	// The namespace of the properties that aren't defined by OSCAL
+	oscalPropNamespace = "https://compliance.openshift.io/ns/oscal"
+)
+
+// The subset of the OSCAL assessment results model that the results of a
+// suite are exported to
+type oscalDocument struct {
+	AssessmentResults oscalAssessmentResults `json:"assessment-results"`
+}
+
+type oscalAssessmentResults struct {
+	UUID     string        `json:"uuid"`
+	Metadata oscalMetadata `json:"metadata"`
+	ImportAP oscalImportAP `json:"import-ap"`
+	Results  []oscalResult `json:"results"`
+}
+
+type oscalMetadata struct {
+	Title        string `json:"title"`
+	LastModified string `json:"last-modified"`
+	Version      string `json:"version"`
+	OSCALVersion string `json:"oscal-version"`
+}
+
+type oscalImportAP struct {
+	Href string `json:"href"`
+}
+
+type oscalProp struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	NS    string `json:"ns,omitempty"`
+}
+
+type oscalResult struct {
+	UUID             string                `json:"uuid"`
+	Title            string                `json:"title"`
+	Description      string                `json:"description"`
+	Start            string                `json:"start"`
+	End              string                `json:"end,omitempty"`
+	Props            []oscalProp           `json:"props,omitempty"`
+	ReviewedControls oscalReviewedControls `json:"reviewed-controls"`
+	Observations     []oscalObservation    `json:"observations,omitempty"`
+	Findings         []oscalFinding        `json:"findings,omitempty"`
+}
+
+type oscalReviewedControls struct {
+	ControlSelections []oscalControlSelection `json:"control-selections"`
+}
+
+type oscalControlSelection struct {
+	Description     string              `json:"description,omitempty"`
+	IncludeAll      *struct{}           `json:"include-all,omitempty"`
+	IncludeControls []oscalSelectedCtrl `json:"include-controls,omitempty"`
+}
+
+type oscalSelectedCtrl struct {
+	ControlID string `json:"control-id"`
+}
+
+type oscalObservation struct {
+	UUID        string      `json:"uuid"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description"`
+	Props       []oscalProp `json:"props,omitempty"`
+	Methods     []string    `json:"methods"`
+	Collected   string      `json:"collected"`
+	Remarks     string      `json:"remarks,omitempty"`
+}
+
+type oscalFinding struct {
+	UUID                string                    `json:"uuid"`
+	Title               string                    `json:"title"`
+	Description         string                    `json:"description"`
+	Props               []oscalProp               `json:"props,omitempty"`
+	Target              oscalFindingTarget        `json:"target"`
+	RelatedObservations []oscalRelatedObservation `json:"related-observations,omitempty"`
+}
+
+type oscalFindingTarget struct {
+	Type     string             `json:"type"`
+	TargetID string             `json:"target-id"`
+	Status   oscalFindingStatus `json:"status"`
+}
+
+type oscalFindingStatus struct {
+	State  string `json:"state"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type oscalRelatedObservation struct {
+	ObservationUUID string `json:"observation-uuid"`
+}
+
+// exportedCheckResult is a row of the flat exports of the check results
+type exportedCheckResult struct {
+	Scan     string `json:"scan"`
+	ScanRun  string `json:"scanRun"`
+	Name     string `json:"name"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Status   string `json:"status"`
+	// The role of the nodes of a node scan
+	NodeRole string `json:"nodeRole,omitempty"`
+	// The statuses of the nodes that differ from the most common one, or
+	// of all the nodes, as they're listed, if the check is inconsistent
+	InconsistentNodes string                      `json:"inconsistentNodes,omitempty"`
+	Controls          []compv1alpha1.RuleControls `json:"controls,omitempty"`
+}
+
+// checkResultsCSVHeader is the header row of the CSV export of the check
+// results. The controls are listed as 'standard:control' separated by
+// semicolons.
+var checkResultsCSVHeader = []string{
+	"scan", "scanRun", "name", "rule", "severity", "status", "nodeRole", "inconsistentNodes", "controls",
+}
+
+// reconcileResultExport exports the results of the suite into the ConfigMap
+// of its result export once its scans are done
+func (r *ReconcileComplianceSuite) reconcileResultExport(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	export := suite.Spec.ResultExport
+	if export == nil || suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+
+	key := assessmentResultsKey
+	var doc []byte
+	var err error
+	switch export.Format {
+	case compv1alpha1.ResultExportCSV:
+		key = checkResultsCSVKey
+		doc, err = r.exportCheckResults(suite, export.Format)
+	case compv1alpha1.ResultExportJSON:
+		key = checkResultsJSONKey
+		doc, err = r.exportCheckResults(suite, export.Format)
+	default:
+		doc, err = r.exportAssessmentResults(suite, export)
+	}
+	if err != nil {
+		return err
+	}
+	var data map[string]string
+	var binaryData map[string][]byte
+	if len(key)+len(doc) <= maxRemediationExportSize {
+		data = map[string]string{key: string(doc)}
+	} else {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		if _, err := w.Write(doc); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if compressed.Len() > maxRemediationExportSize {
+			logger.Info("The exported results don't fit into a ConfigMap", "ConfigMap.Name", export.ConfigMapName, "size", compressed.Len())
+			if r.Recorder != nil {
+				r.Recorder.Event(suite, corev1.EventTypeWarning, "ResultExportFailed",
+					fmt.Sprintf("The exported results don't fit into the ConfigMap %s, even compressed", export.ConfigMapName))
+			}
+			return nil
+		}
+		binaryData = map[string][]byte{key + ".gz": compressed.Bytes()}
+	}
+
+	cm := &corev1.ConfigMap{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: export.ConfigMapName, Namespace: suite.Namespace}, cm)
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      export.ConfigMapName,
+				Namespace: suite.Namespace,
+				Labels: map[string]string{
+					compv1alpha1.SuiteLabel: suite.Name,
+				},
+			},
+			Data:       data,
+			BinaryData: binaryData,
+		}
+		if err := controllerutil.SetControllerReference(suite, cm, r.Scheme); err != nil {
+			return err
+		}
+		logger.Info("Exporting the results", "ConfigMap.Name", cm.Name)
+		if err := r.Client.Create(context.TODO(), cm); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		if reflect.DeepEqual(cm.Data, data) && reflect.DeepEqual(cm.BinaryData, binaryData) {
+			return nil
+		}
+		cmCopy := cm.DeepCopy()
+		cmCopy.Data = data
+		cmCopy.BinaryData = binaryData
+		logger.Info("Updating the exported results", "ConfigMap.Name", cm.Name)
+		if err := r.Client.Update(context.TODO(), cmCopy); err != nil {
+			return err
+		}
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(suite, corev1.EventTypeNormal, "ResultsExported",
+			fmt.Sprintf("The results were exported to the ConfigMap %s", export.ConfigMapName))
+	}
+	return nil
+}
+
+// exportAssessmentResults renders the check results of the suite as an OSCAL
+// assessment results document, with a result per scan. Each check result is
+// an observation, and each control the rules of the checks implement is a
+// finding that's only satisfied if all of its checks pass. The UUIDs are
+// derived from the suite and the runs of its scans, so the document only
+// changes when the scans run again.
+func (r *ReconcileComplianceSuite) exportAssessmentResults(suite *compv1alpha1.ComplianceSuite, export *compv1alpha1.ResultExport) ([]byte, error) {
+	checksByScan, controlsByRuleID, err := r.getExportedCheckResults(suite)
+	if err != nil {
+		return nil, err
+	}
+
+	docName := fmt.Sprintf("%s/%s/%s", suite.UID, suite.Namespace, suite.Name)
+	docID := docName
+	var lastModified time.Time
+	results := make([]oscalResult, 0, len(suite.Spec.Scans))
+	for _, scanWrapper := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: scanWrapper.Name, Namespace: suite.Namespace}, scan)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		var start, end time.Time
+		if scan.Status.StartTimestamp != nil {
+			start = scan.Status.StartTimestamp.UTC()
+		}
+		if scan.Status.EndTimestamp != nil {
+			end = scan.Status.EndTimestamp.UTC()
+		}
+		if end.After(lastModified) {
+			lastModified = end
+		}
+		resultName := fmt.Sprintf("%s/%s/%s", docName, scan.Name, start.Format(time.RFC3339))
+		docID += "/" + resultName
+		results = append(results, newOSCALResult(scan, resultName, start, end, checksByScan[scan.Name], controlsByRuleID))
+	}
+	if lastModified.IsZero() {
+		lastModified = suite.CreationTimestamp.UTC()
+	}
+
+	href := export.AssessmentPlanHref
+	if href == "" {
+		href = "#"
+	}
+	doc := oscalDocument{
+		AssessmentResults: oscalAssessmentResults{
+			UUID: xccdf.GenerateUniqueIDFromDNS(docID),
+			Metadata: oscalMetadata{
+				Title:        fmt.Sprintf("Results of the ComplianceSuite %s/%s", suite.Namespace, suite.Name),
+				LastModified: lastModified.Format(time.RFC3339),
+				Version:      version.Version,
+				OSCALVersion: oscalVersion,
+			},
+			ImportAP: oscalImportAP{Href: href},
+			Results:  results,
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// getExportedCheckResults returns the check results of the suite by the name
+// of their scan, sorted by name, and the controls of the rules of the
+// namespace of the suite by their XCCDF ID
+func (r *ReconcileComplianceSuite) getExportedCheckResults(suite *compv1alpha1.ComplianceSuite) (map[string][]*compv1alpha1.ComplianceCheckResult,
+	map[string][]compv1alpha1.RuleControls, error) {
+	checkList := &compv1alpha1.ComplianceCheckResultList{}
+	listOpts := client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{compv1alpha1.SuiteLabel: suite.Name}),
+	}
+	if err := r.Client.List(context.TODO(), checkList, &listOpts); err != nil {
+		return nil, nil, err
+	}
+	ruleList := &compv1alpha1.RuleList{}
+	if err := r.Client.List(context.TODO(), ruleList, client.InNamespace(suite.Namespace)); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(ruleList.Items, func(i, j int) bool {
+		return ruleList.Items[i].Name < ruleList.Items[j].Name
+	})
+	// The rules of different profile bundles can share an XCCDF ID, they
+	// implement the same controls then
+	controlsByRuleID := make(map[string][]compv1alpha1.RuleControls)
+	for i := range ruleList.Items {
+		rule := &ruleList.Items[i]
+		if _, ok := controlsByRuleID[rule.ID]; !ok {
+			controlsByRuleID[rule.ID] = rule.Controls
+		}
+	}
+	sort.Slice(checkList.Items, func(i, j int) bool {
+		return checkList.Items[i].Name < checkList.Items[j].Name
+	})
+	checksByScan := make(map[string][]*compv1alpha1.ComplianceCheckResult)
+	for i := range checkList.Items {
+		check := &checkList.Items[i]
+		scanName := check.Labels[compv1alpha1.ComplianceScanLabel]
+		checksByScan[scanName] = append(checksByScan[scanName], check)
+	}
+	return checksByScan, controlsByRuleID, nil
+}
+
+// getCheckResultRows returns the scans of the suite along with the flat rows
+// of their check results, ordered by scan and check name
+func (r *ReconcileComplianceSuite) getCheckResultRows(suite *compv1alpha1.ComplianceSuite) ([]*compv1alpha1.ComplianceScan, []exportedCheckResult, error) {
+	checksByScan, controlsByRuleID, err := r.getExportedCheckResults(suite)
+	if err != nil {
+		return nil, nil, err
+	}
+	scans := make([]*compv1alpha1.ComplianceScan, 0, len(suite.Spec.Scans))
+	rows := []exportedCheckResult{}
+	for _, scanWrapper := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: scanWrapper.Name, Namespace: suite.Namespace}, scan)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		scans = append(scans, scan)
+		scanRun := ""
+		if scan.Status.StartTimestamp != nil {
+			scanRun = scan.Status.StartTimestamp.UTC().Format(time.RFC3339)
+		}
+		nodeRole := ""
+		if scan.Spec.ScanType != compv1alpha1.ScanTypePlatform {
+			nodeRole = utils.GetFirstNodeRole(scan.Spec.NodeSelector)
+		}
+		for _, check := range checksByScan[scan.Name] {
+			rows = append(rows, exportedCheckResult{
+				Scan:              scan.Name,
+				ScanRun:           scanRun,
+				Name:              check.Name,
+				Rule:              check.ID,
+				Severity:          string(check.Severity),
+				Status:            string(check.Status),
+				NodeRole:          nodeRole,
+				InconsistentNodes: check.Annotations[compv1alpha1.ComplianceCheckResultInconsistentSourceAnnotation],
+				Controls:          controlsByRuleID[check.ID],
+			})
+		}
+	}
+	return scans, rows, nil
+}
+
+// exportCheckResults renders the check results of the suite as a flat CSV
+// file or JSON array, one row per check, ordered by scan and check name
+func (r *ReconcileComplianceSuite) exportCheckResults(suite *compv1alpha1.ComplianceSuite, format compv1alpha1.ResultExportFormat) ([]byte, error) {
+	_, rows, err := r.getCheckResultRows(suite)
+	if err != nil {
+		return nil, err
+	}
+	if format == compv1alpha1.ResultExportJSON {
+		return json.MarshalIndent(rows, "", "  ")
+	}
+	var buffer bytes.Buffer
+	w := csv.NewWriter(&buffer)
+	if err := w.Write(checkResultsCSVHeader); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		var controls []string
+		for _, std := range row.Controls {
+			for _, ctrl := range std.Controls {
+				controls = append(controls, std.Standard+":"+ctrl)
+			}
+		}
+		if err := w.Write([]string{
+			row.Scan, row.ScanRun, row.Name, row.Rule, row.Severity, row.Status, row.NodeRole,
+			row.InconsistentNodes, strings.Join(controls, ";"),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buffer.Bytes(), w.Error()
+}
+
+// newOSCALResult renders the check results of a scan as an OSCAL result
+func newOSCALResult(scan *compv1alpha1.ComplianceScan, resultName string, start, end time.Time,
+	checks []*compv1alpha1.ComplianceCheckResult, controlsByRuleID map[string][]compv1alpha1.RuleControls) oscalResult {
+	result := oscalResult{
+		UUID:        xccdf.GenerateUniqueIDFromDNS(resultName),
+		Title:       fmt.Sprintf("Results of the ComplianceScan %s", scan.Name),
+		Description: fmt.Sprintf("The results of the %s profile", scan.Spec.Profile),
+		Start:       start.Format(time.RFC3339),
+		Props: []oscalProp{
+			{Name: "scan", Value: scan.Name, NS: oscalPropNamespace},
+			{Name: "profile", Value: scan.Spec.Profile, NS: oscalPropNamespace},
+		},
+	}
+	if !end.IsZero() {
+		result.End = end.Format(time.RFC3339)
+	}
+
+	type controlKey struct{ standard, control string }
+	findings := make(map[controlKey]*oscalFinding)
+	var controlKeys []controlKey
+	for _, check := range checks {
+		observation := newOSCALObservation(check, resultName, start)
+		result.Observations = append(result.Observations, observation)
+		if check.Status == compv1alpha1.CheckResultNotApplicable || check.Status == compv1alpha1.CheckResultNoResult {
+			continue
+		}
+		state, reason := getOSCALFindingStatus(check)
+		for _, std := range controlsByRuleID[check.ID] {
+			for _, ctrl := range std.Controls {
+				key := controlKey{standard: std.Standard, control: getOSCALControlID(ctrl)}
+				finding, ok := findings[key]
+				if !ok {
+					finding = &oscalFinding{
+						UUID:        xccdf.GenerateUniqueIDFromDNS(resultName + "/" + key.standard + "/" + key.control),
+						Title:       fmt.Sprintf("%s %s", std.Standard, ctrl),
+						Description: fmt.Sprintf("The checks of the control %s of %s", ctrl, std.Standard),
+						Props:       []oscalProp{{Name: "standard", Value: std.Standard, NS: oscalPropNamespace}},
+						Target: oscalFindingTarget{
+							Type:     "objective-id",
+							TargetID: key.control,
+							Status:   oscalFindingStatus{State: "satisfied", Reason: "pass"},
+						},
+					}
+					findings[key] = finding
+					controlKeys = append(controlKeys, key)
+				}
+				finding.RelatedObservations = append(finding.RelatedObservations, oscalRelatedObservation{ObservationUUID: observation.UUID})
+				// A failed check outweighs the ones that need a review
+				if finding.Target.Status.Reason != "fail" && state != "satisfied" {
+					finding.Target.Status = oscalFindingStatus{State: state, Reason: reason}
+				}
+			}
+		}
+	}
+
+	sort.Slice(controlKeys, func(i, j int) bool {
+		if controlKeys[i].standard != controlKeys[j].standard {
+			return controlKeys[i].standard < controlKeys[j].standard
+		}
+		return controlKeys[i].control < controlKeys[j].control
+	})
+	selections := make(map[string]*oscalControlSelection)
+	for _, key := range controlKeys {
+		result.Findings = append(result.Findings, *findings[key])
+		selection, ok := selections[key.standard]
+		if !ok {
+			result.ReviewedControls.ControlSelections = append(result.ReviewedControls.ControlSelections,
+				oscalControlSelection{Description: key.standard})
+			selection = &result.ReviewedControls.ControlSelections[len(result.ReviewedControls.ControlSelections)-1]
+			selections[key.standard] = selection
+		}
+		selection.IncludeControls = append(selection.IncludeControls, oscalSelectedCtrl{ControlID: key.control})
+	}
+	if len(result.ReviewedControls.ControlSelections) == 0 {
+		// OSCAL requires a selection, none of the rules references controls
+		result.ReviewedControls.ControlSelections = []oscalControlSelection{{IncludeAll: &struct{}{}}}
+	}
+	return result
+}
+
+func newOSCALObservation(check *compv1alpha1.ComplianceCheckResult, resultName string, collected time.Time) oscalObservation {
+	// The descriptions start with the title of the rule
+	title, _, _ := strings.Cut(strings.TrimSpace(check.Description), "\n")
+	description := title
+	if description == "" {
+		description = check.ID
+	}
+	observation := oscalObservation{
+		UUID:        xccdf.GenerateUniqueIDFromDNS(resultName + "/" + check.Name),
+		Title:       check.Name,
+		Description: description,
+		Props: []oscalProp{
+			{Name: "rule", Value: check.ID, NS: oscalPropNamespace},
+			{Name: "result", Value: string(check.Status), NS: oscalPropNamespace},
+			{Name: "severity", Value: string(check.Severity), NS: oscalPropNamespace},
+		},
+		Methods:   []string{"TEST"},
+		Collected: collected.Format(time.RFC3339),
+	}
+	if check.Review != nil {
+		observation.Methods = append(observation.Methods, "EXAMINE")
+		observation.Props = append(observation.Props,
+			oscalProp{Name: "review-outcome", Value: string(check.Review.Outcome), NS: oscalPropNamespace},
+			oscalProp{Name: "reviewer", Value: check.Review.Reviewer, NS: oscalPropNamespace})
+		observation.Remarks = check.Review.Notes
+	}
+	return observation
+}
+
+// getOSCALFindingStatus maps the result of a check to the state of the
+// findings of its controls, and the reason for it. The outcome of the review
+// of a MANUAL check stands in for its result.
+func getOSCALFindingStatus(check *compv1alpha1.ComplianceCheckResult) (string, string) {
+	status := check.Status
+	if status == compv1alpha1.CheckResultManual && check.Review != nil {
+		status = check.Review.Outcome
+	}
+	switch status {
+	case compv1alpha1.CheckResultFail, compv1alpha1.CheckResultError, compv1alpha1.CheckResultInconsistent:
+		return "not-satisfied", "fail"
+	case compv1alpha1.CheckResultManual:
+		return "not-satisfied", "other"
+	}
+	return "satisfied", "pass"
+}
+
+// getOSCALControlID returns the ID of a control the way the OSCAL catalogs
+// name them, e.g. ac-2.1 for AC-2(1)
+func getOSCALControlID(ctrl string) string {
+	id := strings.ToLower(strings.TrimSpace(ctrl))
+	id = strings.ReplaceAll(id, "(", ".")
+	id = strings.ReplaceAll(id, ")", "")
+	return strings.ReplaceAll(id, " ", "")
+}
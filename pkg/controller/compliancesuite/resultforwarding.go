@@ -0,0 +1,579 @@
+package compliancesuite
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+	"github.com/ComplianceAsCode/compliance-operator/version"
+)
+
+const (
+	// The keys of the Secret of a sink that hold its token and the CA
+	// certificate to verify it with
+	forwardingTokenKey = "token"
+	forwardingCAKey    = "ca.crt"
+
+	// The vendor and the product the results are forwarded as
+	forwardingVendor  = "ComplianceAsCode"
+	forwardingProduct = "compliance-operator"
+
+	defaultSplunkSource     = forwardingProduct
+	defaultSplunkSourceType = "_json"
+
+	// How long forwarding the results to a sink may take, retries
+	// included
+	resultForwardingTimeout = 2 * time.Minute
+
+	forwardedScanSummaryType  = "scanSummary"
+	forwardedCheckResultType  = "checkResult"
+	forwardedScanStartedType  = "scanStarted"
+	forwardedScanFinishedType = "scanFinished"
+
+	// The sinks of the notifications, in the metrics
+	kafkaNotificationSink  = "kafka"
+	splunkNotificationSink = "splunk"
+	syslogNotificationSink = "syslog"
+)
+
+// forwardedScanSummary is the event that sums up the results of a scan
+type forwardedScanSummary struct {
+	Type      string `json:"type"`
+	Suite     string `json:"suite"`
+	Namespace string `json:"namespace"`
+	Scan      string `json:"scan"`
+	ScanRun   string `json:"scanRun"`
+	Profile   string `json:"profile"`
+	Result    string `json:"result"`
+	// The number of checks by their status
+	Checks map[string]int `json:"checks"`
+}
+
+// forwardedCheckResult is the event of the result of a check, with the same
+// fields as the flat exports
+type forwardedCheckResult struct {
+	Type      string `json:"type"`
+	Suite     string `json:"suite"`
+	Namespace string `json:"namespace"`
+	exportedCheckResult
+}
+
+// forwardedScanLifecycle is the event of a scan that started or finished
+type forwardedScanLifecycle struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Suite     string    `json:"suite"`
+	Namespace string    `json:"namespace"`
+	Scan      string    `json:"scan"`
+	ScanRun   string    `json:"scanRun,omitempty"`
+	Phase     string    `json:"phase"`
+	// The result of the scan, once it finished
+	Result       string `json:"result,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// forwardedEvent is an event that's forwarded to the sinks, along with when
+// it happened
+type forwardedEvent struct {
+	time  time.Time
+	event interface{}
+}
+
+// resultSink is a sink that the results of a suite are forwarded to
+type resultSink struct {
+	// The name of the sink in the events of the suite
+	name string
+	// The sink of the notifications, in the metrics
+	notificationSink string
+	// getStatus returns the status of the forwarding to the sink
+	getStatus func(status *compv1alpha1.ResultForwardingStatus) **compv1alpha1.SinkForwardingStatus
+	// forward forwards the events and returns how many were forwarded
+	forward func(ctx context.Context, suite *compv1alpha1.ComplianceSuite, events []forwardedEvent) (int, error)
+}
+
+// reconcileResultForwarding forwards the results of the suite to the sinks of
+// its result forwarding once its scans are done, once per run of the scans.
+// The results are forwarded in the background, so that a sink that's slow or
+// down doesn't hold up the reconciles; the run is recorded in the status of
+// the sink once it's queued so that it isn't queued again. The status of the
+// suite is updated in place.
+func (r *ReconcileComplianceSuite) reconcileResultForwarding(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	if suite.Spec.ResultForwarding == nil || suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+	// The sinks keep the settings they were queued with
+	forwarding := suite.Spec.ResultForwarding.DeepCopy()
+
+	status := suite.Status.ResultForwarding.DeepCopy()
+	if status == nil {
+		status = &compv1alpha1.ResultForwardingStatus{}
+	}
+	var sinks []resultSink
+	if forwarding.Splunk != nil {
+		sinks = append(sinks, resultSink{
+			name:             "the Splunk HTTP Event Collector",
+			notificationSink: splunkNotificationSink,
+			getStatus: func(status *compv1alpha1.ResultForwardingStatus) **compv1alpha1.SinkForwardingStatus {
+				return &status.Splunk
+			},
+			forward: func(ctx context.Context, suite *compv1alpha1.ComplianceSuite, events []forwardedEvent) (int, error) {
+				return len(events), r.forwardToSplunk(ctx, suite, forwarding.Splunk, events)
+			},
+		})
+	}
+	if forwarding.Syslog != nil {
+		sinks = append(sinks, resultSink{
+			name:             "the syslog receiver",
+			notificationSink: syslogNotificationSink,
+			getStatus: func(status *compv1alpha1.ResultForwardingStatus) **compv1alpha1.SinkForwardingStatus {
+				return &status.Syslog
+			},
+			forward: func(ctx context.Context, suite *compv1alpha1.ComplianceSuite, events []forwardedEvent) (int, error) {
+				return r.forwardToSyslog(ctx, suite, forwarding.Syslog, events)
+			},
+		})
+	}
+	if forwarding.Kafka != nil {
+		sinks = append(sinks, resultSink{
+			name:             "the Kafka topic",
+			notificationSink: kafkaNotificationSink,
+			getStatus: func(status *compv1alpha1.ResultForwardingStatus) **compv1alpha1.SinkForwardingStatus {
+				return &status.Kafka
+			},
+			forward: func(ctx context.Context, suite *compv1alpha1.ComplianceSuite, events []forwardedEvent) (int, error) {
+				return r.publishScanSummaries(ctx, suite, forwarding.Kafka, events)
+			},
+		})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	scans, rows, err := r.getCheckResultRows(suite)
+	if err != nil {
+		return err
+	}
+	scanRun := getLatestScanRun(scans)
+	if scanRun.IsZero() {
+		return nil
+	}
+
+	var events []forwardedEvent
+	var queued []resultSink
+	for _, sink := range sinks {
+		sinkStatus := sink.getStatus(status)
+		if *sinkStatus != nil && (*sinkStatus).ScanRun.Equal(&scanRun) {
+			continue
+		}
+		if events == nil {
+			events = newForwardedEvents(suite, scans, rows)
+		}
+		*sinkStatus = &compv1alpha1.SinkForwardingStatus{ScanRun: scanRun}
+		queued = append(queued, sink)
+	}
+
+	if len(queued) == 0 {
+		return nil
+	}
+	suite.Status.ResultForwarding = status
+	if err := r.updateStatus(suite); err != nil {
+		return err
+	}
+	for _, sink := range queued {
+		r.queueResultForwarding(suite, sink, scanRun, events, logger)
+	}
+	return nil
+}
+
+// queueResultForwarding queues the forwarding of the events of the run of the
+// scans to the sink, whose outcome is recorded in the status of the sink once
+// the events are forwarded
+func (r *ReconcileComplianceSuite) queueResultForwarding(suite *compv1alpha1.ComplianceSuite, sink resultSink,
+	scanRun metav1.Time, events []forwardedEvent, logger logr.Logger) {
+	suite = suite.DeepCopy()
+	logger.Info("Queueing the forwarding of the results", "sink", sink.name, "events", len(events))
+	forwarded := 0
+	queued := r.notifications.Enqueue(common.Notification{
+		Sink: sink.notificationSink,
+		Send: func(ctx context.Context) error {
+			var err error
+			forwarded, err = sink.forward(ctx, suite, events)
+			return err
+		},
+		Done: func(err error) {
+			r.recordResultForwarding(suite, sink, scanRun, forwarded, err, logger)
+		},
+	})
+	if !queued {
+		r.recordResultForwarding(suite, sink, scanRun, 0, fmt.Errorf("too many notifications are waiting to be sent"), logger)
+	}
+}
+
+// recordResultForwarding records the outcome of the forwarding of the run of
+// the scans in the status of the sink, unless the suite moved on to another
+// run since
+func (r *ReconcileComplianceSuite) recordResultForwarding(suite *compv1alpha1.ComplianceSuite, sink resultSink,
+	scanRun metav1.Time, forwarded int, forwardErr error, logger logr.Logger) {
+	// The status the forwarding was queued with might not be cached yet, so
+	// the suite is read from the API server
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &compv1alpha1.ComplianceSuite{}
+		if err := r.Reader.Get(context.TODO(), types.NamespacedName{Name: suite.Name, Namespace: suite.Namespace}, current); err != nil {
+			return err
+		}
+		if current.Status.ResultForwarding == nil {
+			return nil
+		}
+		status := *sink.getStatus(current.Status.ResultForwarding)
+		if status == nil || !status.ScanRun.Equal(&scanRun) {
+			return nil
+		}
+		if forwardErr != nil {
+			status.Error = forwardErr.Error()
+		} else {
+			now := metav1.Now()
+			status.ForwardedAt = &now
+			status.Events = forwarded
+			status.Error = ""
+		}
+		return r.updateStatus(current)
+	})
+	if err != nil {
+		logger.Error(err, "Cannot record the forwarding of the results", "sink", sink.name)
+	}
+
+	if forwardErr != nil {
+		logger.Error(forwardErr, "Cannot forward the results", "sink", sink.name)
+		if r.Recorder != nil {
+			r.Recorder.Event(suite, corev1.EventTypeWarning, "ResultForwardingFailed",
+				fmt.Sprintf("The results couldn't be forwarded to %s: %s", sink.name, forwardErr))
+		}
+		return
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(suite, corev1.EventTypeNormal, "ResultsForwarded",
+			"%d events were forwarded to %s", forwarded, sink.name)
+	}
+}
+
+// getLatestScanRun returns when the latest run of the scans started
+func getLatestScanRun(scans []*compv1alpha1.ComplianceScan) metav1.Time {
+	var latest metav1.Time
+	for _, scan := range scans {
+		if scan.Status.StartTimestamp != nil && latest.Before(scan.Status.StartTimestamp) {
+			latest = *scan.Status.StartTimestamp
+		}
+	}
+	return latest
+}
+
+// newForwardedEvents returns a summary event per scan followed by the events
+// of its check results, which happened when the scan ended
+func newForwardedEvents(suite *compv1alpha1.ComplianceSuite, scans []*compv1alpha1.ComplianceScan,
+	rows []exportedCheckResult) []forwardedEvent {
+	events := make([]forwardedEvent, 0, len(scans)+len(rows))
+	for _, scan := range scans {
+		ended := time.Now()
+		if scan.Status.EndTimestamp != nil {
+			ended = scan.Status.EndTimestamp.Time
+		}
+		summary := forwardedScanSummary{
+			Type:      forwardedScanSummaryType,
+			Suite:     suite.Name,
+			Namespace: suite.Namespace,
+			Scan:      scan.Name,
+			Profile:   scan.Spec.Profile,
+			Result:    string(scan.Status.Result),
+			Checks:    map[string]int{},
+		}
+		if scan.Status.StartTimestamp != nil {
+			summary.ScanRun = scan.Status.StartTimestamp.UTC().Format(time.RFC3339)
+		}
+		var checks []forwardedEvent
+		for _, row := range rows {
+			if row.Scan != scan.Name {
+				continue
+			}
+			summary.Checks[row.Status]++
+			checks = append(checks, forwardedEvent{time: ended, event: forwardedCheckResult{
+				Type:                forwardedCheckResultType,
+				Suite:               suite.Name,
+				Namespace:           suite.Namespace,
+				exportedCheckResult: row,
+			}})
+		}
+		events = append(events, forwardedEvent{time: ended, event: summary})
+		events = append(events, checks...)
+	}
+	return events
+}
+
+// forwardToSplunk posts the events to the Splunk HTTP Event Collector, with
+// the token and the CA certificate of its Secret
+func (r *ReconcileComplianceSuite) forwardToSplunk(ctx context.Context, suite *compv1alpha1.ComplianceSuite,
+	splunk *compv1alpha1.SplunkForwarding, events []forwardedEvent) error {
+	secret, pool, err := r.getForwardingSecret(suite.Namespace, splunk.TokenSecretName)
+	if err != nil {
+		return err
+	}
+	token := string(secret.Data[forwardingTokenKey])
+	if token == "" {
+		return fmt.Errorf("the Secret %s has no %s key", splunk.TokenSecretName, forwardingTokenKey)
+	}
+	var httpClient *http.Client
+	if pool != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		httpClient = &http.Client{Transport: transport}
+	}
+	config := utils.SplunkHECConfig{
+		URL:        splunk.URL,
+		Token:      token,
+		Index:      splunk.Index,
+		Source:     splunk.Source,
+		SourceType: splunk.SourceType,
+		BatchSize:  splunk.BatchSize,
+	}
+	if config.Source == "" {
+		config.Source = defaultSplunkSource
+	}
+	if config.SourceType == "" {
+		config.SourceType = defaultSplunkSourceType
+	}
+	client, err := utils.NewSplunkHECClient(config, httpClient)
+	if err != nil {
+		return err
+	}
+
+	splunkEvents := make([]utils.SplunkEvent, len(events))
+	for i, event := range events {
+		splunkEvents[i] = utils.SplunkEvent{Time: event.time.Unix(), Event: event.event}
+	}
+	ctx, cancel := context.WithTimeout(ctx, resultForwardingTimeout)
+	defer cancel()
+	return client.SendEvents(ctx, splunkEvents)
+}
+
+// publishScanSummaries publishes the summaries of the scans among the events
+// to the Kafka topic, and returns how many were published
+func (r *ReconcileComplianceSuite) publishScanSummaries(ctx context.Context, suite *compv1alpha1.ComplianceSuite,
+	kafka *compv1alpha1.KafkaForwarding, events []forwardedEvent) (int, error) {
+	var messages []utils.KafkaMessage
+	for _, event := range events {
+		if _, ok := event.event.(forwardedScanSummary); ok {
+			messages = append(messages, utils.KafkaMessage{Key: suite.Name, Time: event.time, Value: event.event})
+		}
+	}
+	return len(messages), r.publishToKafka(ctx, suite, kafka, messages)
+}
+
+// publishScanLifecycle publishes to the Kafka topic of the suite that the
+// scan started or finished, depending on the phase it moved on from. The
+// event is published in the background, and since the scans move on
+// regardless, failures are only reported.
+func (r *ReconcileComplianceSuite) publishScanLifecycle(suite *compv1alpha1.ComplianceSuite, previousPhase compv1alpha1.ComplianceScanStatusPhase,
+	scanStatus *compv1alpha1.ComplianceScanStatusWrapper, logger logr.Logger) {
+	if suite.Spec.ResultForwarding == nil || suite.Spec.ResultForwarding.Kafka == nil {
+		return
+	}
+	event := forwardedScanLifecycle{
+		Time:      time.Now().UTC(),
+		Suite:     suite.Name,
+		Namespace: suite.Namespace,
+		Scan:      scanStatus.Name,
+		Phase:     string(scanStatus.Phase),
+	}
+	if scanStatus.StartTimestamp != nil {
+		event.ScanRun = scanStatus.StartTimestamp.UTC().Format(time.RFC3339)
+	}
+	idle := func(phase compv1alpha1.ComplianceScanStatusPhase) bool {
+		return phase == "" || phase == compv1alpha1.PhasePending || phase == compv1alpha1.PhaseDone
+	}
+	switch {
+	case scanStatus.Phase == compv1alpha1.PhaseDone && previousPhase != compv1alpha1.PhaseDone:
+		event.Type = forwardedScanFinishedType
+		event.Result = string(scanStatus.Result)
+		event.ErrorMessage = scanStatus.ErrorMessage
+	case !idle(scanStatus.Phase) && idle(previousPhase):
+		event.Type = forwardedScanStartedType
+	default:
+		return
+	}
+
+	message := utils.KafkaMessage{Key: suite.Name, Time: event.Time, Value: event}
+	suite = suite.DeepCopy()
+	r.notifications.Enqueue(common.Notification{
+		Sink: kafkaNotificationSink,
+		Send: func(ctx context.Context) error {
+			return r.publishToKafka(ctx, suite, suite.Spec.ResultForwarding.Kafka, []utils.KafkaMessage{message})
+		},
+		Done: func(err error) {
+			if err == nil {
+				return
+			}
+			logger.Error(err, "Cannot publish the lifecycle of the scan", "ComplianceScan.Name", event.Scan, "type", event.Type)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(suite, corev1.EventTypeWarning, "EventPublishingFailed",
+					"The %s event of scan %s couldn't be published to the Kafka topic: %s", event.Type, event.Scan, err)
+			}
+		},
+	})
+}
+
+// publishToKafka publishes the messages to the Kafka topic that the Secret of
+// the suite configures
+func (r *ReconcileComplianceSuite) publishToKafka(ctx context.Context, suite *compv1alpha1.ComplianceSuite, kafka *compv1alpha1.KafkaForwarding,
+	messages []utils.KafkaMessage) error {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: kafka.SecretName, Namespace: suite.Namespace}, secret); err != nil {
+		return fmt.Errorf("cannot get the Secret %s: %w", kafka.SecretName, err)
+	}
+	config, err := utils.NewKafkaConfigFromSecret(secret)
+	if err != nil {
+		return err
+	}
+	newPublisher := r.newKafkaPublisher
+	if newPublisher == nil {
+		newPublisher = utils.NewKafkaPublisher
+	}
+	ctx, cancel := context.WithTimeout(ctx, resultForwardingTimeout)
+	defer cancel()
+	return newPublisher(config).Publish(ctx, messages)
+}
+
+// forwardToSyslog sends the check results among the events to the syslog
+// receiver as CEF or LEEF messages, and returns how many were sent
+func (r *ReconcileComplianceSuite) forwardToSyslog(ctx context.Context, suite *compv1alpha1.ComplianceSuite,
+	syslog *compv1alpha1.SyslogForwarding, events []forwardedEvent) (int, error) {
+	config := utils.SyslogConfig{Address: syslog.Address, AppName: forwardingProduct}
+	if syslog.Protocol != compv1alpha1.SyslogTCP {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		if syslog.CASecretName != "" {
+			_, pool, err := r.getForwardingSecret(suite.Namespace, syslog.CASecretName)
+			if err != nil {
+				return 0, err
+			}
+			if pool == nil {
+				return 0, fmt.Errorf("the Secret %s has no %s key", syslog.CASecretName, forwardingCAKey)
+			}
+			config.TLSConfig.RootCAs = pool
+		}
+	}
+	client, err := utils.NewSyslogClient(config)
+	if err != nil {
+		return 0, err
+	}
+
+	mapping := syslog.FieldMapping
+	if mapping == nil {
+		mapping = &compv1alpha1.SyslogFieldMapping{}
+	}
+	var messages []utils.SyslogMessage
+	for _, event := range events {
+		check, ok := event.event.(forwardedCheckResult)
+		if !ok {
+			continue
+		}
+		securityEvent, failed := newSecurityEvent(check, mapping)
+		message := utils.SyslogMessage{Time: event.time, Severity: utils.SyslogInformational}
+		if failed {
+			message.Severity = utils.SyslogWarning
+		}
+		if syslog.Format == compv1alpha1.SyslogLEEF {
+			message.Message = securityEvent.LEEF()
+		} else {
+			message.Message = securityEvent.CEF()
+		}
+		messages = append(messages, message)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resultForwardingTimeout)
+	defer cancel()
+	return len(messages), client.Send(ctx, messages)
+}
+
+// newSecurityEvent returns the CEF or LEEF event of a check result, with the
+// rule ID, the severity and the nodes of the check in the fields of the
+// mapping, and whether the check failed. The event has the severity of the
+// check if it failed, and the lowest severity otherwise.
+func newSecurityEvent(check forwardedCheckResult, mapping *compv1alpha1.SyslogFieldMapping) (*utils.SecurityEvent, bool) {
+	event := &utils.SecurityEvent{
+		Vendor:   forwardingVendor,
+		Product:  forwardingProduct,
+		Version:  version.Version,
+		ClassID:  check.Rule,
+		Name:     check.Name,
+		Severity: 1,
+	}
+	failed := false
+	switch compv1alpha1.ComplianceCheckStatus(check.Status) {
+	case compv1alpha1.CheckResultFail, compv1alpha1.CheckResultError, compv1alpha1.CheckResultInconsistent:
+		failed = true
+		event.Severity = securityEventSeverities[compv1alpha1.ComplianceCheckResultSeverity(check.Severity)]
+	}
+
+	node := check.NodeRole
+	if check.InconsistentNodes != "" {
+		node = check.InconsistentNodes
+	}
+	for _, field := range []utils.SecurityEventField{
+		{Key: getMappedField(mapping.RuleID, "ruleId"), Value: check.Rule},
+		{Key: getMappedField(mapping.Severity, "complianceSeverity"), Value: check.Severity},
+		{Key: getMappedField(mapping.Node, "node"), Value: node},
+		{Key: "outcome", Value: check.Status},
+		{Key: "suite", Value: check.Suite},
+		{Key: "namespace", Value: check.Namespace},
+		{Key: "scan", Value: check.Scan},
+	} {
+		if field.Value != "" {
+			event.Fields = append(event.Fields, field)
+		}
+	}
+	return event, failed
+}
+
+// The CEF and LEEF severities, from 0 to 10, of the failed checks
+var securityEventSeverities = map[compv1alpha1.ComplianceCheckResultSeverity]int{
+	compv1alpha1.CheckResultSeverityUnknown: 1,
+	compv1alpha1.CheckResultSeverityInfo:    1,
+	compv1alpha1.CheckResultSeverityLow:     3,
+	compv1alpha1.CheckResultSeverityMedium:  6,
+	compv1alpha1.CheckResultSeverityHigh:    8,
+}
+
+func getMappedField(key, defaultKey string) string {
+	if key == "" {
+		return defaultKey
+	}
+	return key
+}
+
+// getForwardingSecret returns the Secret of a sink, along with the pool of
+// the CA certificate to verify the sink with, if the Secret has one
+func (r *ReconcileComplianceSuite) getForwardingSecret(namespace, secretName string) (*corev1.Secret, *x509.CertPool, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+		return nil, nil, fmt.Errorf("cannot get the Secret %s: %w", secretName, err)
+	}
+	ca, ok := secret.Data[forwardingCAKey]
+	if !ok {
+		return secret, nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, nil, fmt.Errorf("the %s key of the Secret %s has no PEM certificate", forwardingCAKey, secretName)
+	}
+	return secret, pool, nil
+}
@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
@@ -30,14 +31,22 @@ func (r *ReconcileComplianceSuite) reconcileScanRerunnerCronJob(suite *compv1alp
 		r.Recorder.Eventf(suite, corev1.EventTypeWarning, "PriorityClass", why+" Suite:"+suite.Name)
 	}
 	if suite.Spec.Schedule == "" {
-		return r.handleRerunnerDelete(suite, logger)
+		err = r.handleRerunnerDelete(suite, logger)
+	} else {
+		err = r.handleCreate(suite, logger)
 	}
-	return r.handleCreate(suite, logger)
+	if err != nil {
+		return err
+	}
+	return r.reconcileAdditionalRerunners(suite, logger)
 }
 
 // validates that the provided schedule is correctly set. Else it returns false (not valid) and an
 // error message
 func (r *ReconcileComplianceSuite) validateSchedule(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	if isValid, errorMsg := r.validateAdditionalSchedules(suite); !isValid {
+		return isValid, errorMsg
+	}
 	if suite.Spec.Schedule == "" {
 		return true, ""
 	}
@@ -46,6 +55,12 @@ func (r *ReconcileComplianceSuite) validateSchedule(suite *compv1alpha1.Complian
 	if err != nil {
 		return false, "ComplianceSuite's schedule is wrongly formatted"
 	}
+	if !utils.ValidTimezone(suite.Spec.Timezone) {
+		return false, fmt.Sprintf("ComplianceSuite's timezone '%s' is not a valid IANA time zone name", suite.Spec.Timezone)
+	}
+	if suite.Spec.ScheduleJitter != nil && suite.Spec.ScheduleJitter.Duration < 0 {
+		return false, "ComplianceSuite's schedule jitter can't be negative"
+	}
 	return true, ""
 }
 
@@ -75,11 +90,18 @@ func (r *ReconcileComplianceSuite) getPriorityClassName(suite *compv1alpha1.Comp
 
 func (r *ReconcileComplianceSuite) handleRerunnerDelete(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
 	inNs := client.InNamespace(common.GetComplianceOperatorNamespace())
-	withLabel := client.MatchingLabels{
-		compv1alpha1.SuiteLabel:       suite.Name,
-		compv1alpha1.SuiteScriptLabel: "",
+	// The jobs of the additional schedules are left to their rerunners
+	noSchedule, err := labels.NewRequirement(compv1alpha1.SuiteScheduleLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+	withLabel := client.MatchingLabelsSelector{
+		Selector: labels.SelectorFromSet(labels.Set{
+			compv1alpha1.SuiteLabel:       suite.Name,
+			compv1alpha1.SuiteScriptLabel: "",
+		}).Add(*noSchedule),
 	}
-	err := r.Client.DeleteAllOf(context.Background(), &corev1.Pod{}, inNs, withLabel)
+	err = r.Client.DeleteAllOf(context.Background(), &corev1.Pod{}, inNs, withLabel)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,8 @@ package compliancesuite
 
 import (
 	"context"
+	"reflect"
+	"strings"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
@@ -27,20 +29,49 @@ func GetRerunnerName(suiteName string) string {
 	return suiteName + "-rerunner"
 }
 
+// getScheduleRerunnerName gets the name of the rerunner workload of a
+// schedule of the suite. The suite's own schedule has no name.
+func getScheduleRerunnerName(suiteName, scheduleName string) string {
+	if scheduleName == "" {
+		return GetRerunnerName(suiteName)
+	}
+	// Leave room for the name of the schedule too, which is at most 20
+	// characters
+	maxLen := 42 - len(scheduleName) - 1
+	if len(suiteName) >= maxLen {
+		suiteName = suiteName[0:maxLen]
+	}
+	return suiteName + "-" + scheduleName + "-rerunner"
+}
+
+// suiteSchedule returns the suite's own schedule, which runs all its scans
+func suiteSchedule(suite *compv1alpha1.ComplianceSuite) *compv1alpha1.ScanSchedule {
+	return &compv1alpha1.ScanSchedule{Schedule: suite.Spec.Schedule}
+}
+
 func (r *ReconcileComplianceSuite) CreateOrUpdateRerunner(
 	suite *compv1alpha1.ComplianceSuite,
 	key types.NamespacedName,
 	logger logr.Logger,
+) error {
+	return r.createOrUpdateScheduleRerunner(suite, suiteSchedule(suite), key, logger)
+}
+
+func (r *ReconcileComplianceSuite) createOrUpdateScheduleRerunner(
+	suite *compv1alpha1.ComplianceSuite,
+	sched *compv1alpha1.ScanSchedule,
+	key types.NamespacedName,
+	logger logr.Logger,
 ) error {
 	c := batchv1.CronJob{}
 	err := r.Client.Get(context.TODO(), key, &c)
 
 	if err != nil && errors.IsNotFound(err) {
-		return r.createCronJob(suite, &c, logger)
+		return r.createCronJob(suite, sched, &c, logger)
 	} else if err != nil {
 		return err
 	}
-	return r.updateCronJob(suite, &c, logger)
+	return r.updateCronJob(suite, sched, &c, logger)
 }
 
 func (r *ReconcileComplianceSuite) getCronJob(key types.NamespacedName) (batchv1.CronJob, error) {
@@ -52,82 +83,146 @@ func (r *ReconcileComplianceSuite) getCronJob(key types.NamespacedName) (batchv1
 	return c, nil
 }
 
-func (r *ReconcileComplianceSuite) createCronJob(suite *compv1alpha1.ComplianceSuite, c *batchv1.CronJob, logger logr.Logger) error {
+func (r *ReconcileComplianceSuite) createCronJob(suite *compv1alpha1.ComplianceSuite, sched *compv1alpha1.ScanSchedule, c *batchv1.CronJob, logger logr.Logger) error {
 	logger.Info("Creating rerunner", "CronJob.Name", c.GetName())
 	priorityClassName, err := r.getPriorityClassName(suite)
 	if err != nil {
 		logger.Error(err, "Cannot get priority class name, scan will not be run with set priority class")
 	}
-	s := r.generateRerunnerSpec(suite, priorityClassName)
+	s := r.generateRerunnerSpec(suite, sched, priorityClassName)
 	return r.Client.Create(context.TODO(), s)
 }
 
-func (r *ReconcileComplianceSuite) updateCronJob(suite *compv1alpha1.ComplianceSuite, c *batchv1.CronJob, logger logr.Logger) error {
-	var isSameSchedule = c.Spec.Schedule == suite.Spec.Schedule
+func (r *ReconcileComplianceSuite) updateCronJob(suite *compv1alpha1.ComplianceSuite, sched *compv1alpha1.ScanSchedule, c *batchv1.CronJob, logger logr.Logger) error {
+	var isSameSchedule = c.Spec.Schedule == sched.Schedule
 	var isSuspend = c.Spec.Suspend == &suite.Spec.Suspend
+	var isSameTimeZone = reflect.DeepEqual(c.Spec.TimeZone, rerunnerTimeZone(suite))
 	var isSameImage = c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image == utils.GetComponentImage(utils.OPERATOR)
-	if isSameSchedule && isSuspend && isSameImage {
+	var isSameCommand = reflect.DeepEqual(c.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command, rerunnerCommand(suite, sched))
+	if isSameSchedule && isSuspend && isSameTimeZone && isSameImage && isSameCommand {
 		logger.Info("Suite rerunner configuration is up-to-date, no update necessary", "CronJob.Name", c.GetName())
 		return nil
 	}
 	logger.Info("Updating rerunner configuration", "CronJob.Name", c.GetName())
 	co := c.DeepCopy()
-	co.Spec.Schedule = suite.Spec.Schedule
+	co.Spec.Schedule = sched.Schedule
 	co.Spec.Suspend = &suite.Spec.Suspend
+	co.Spec.TimeZone = rerunnerTimeZone(suite)
 	co.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image = utils.GetComponentImage(utils.OPERATOR)
+	co.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command = rerunnerCommand(suite, sched)
 	return r.Client.Update(context.TODO(), co)
 }
 
+// rerunnerTimeZone returns the time zone the schedule of the rerunner is
+// interpreted in, if the suite sets one
+func rerunnerTimeZone(suite *compv1alpha1.ComplianceSuite) *string {
+	if suite.Spec.Timezone == "" {
+		return nil
+	}
+	tz := suite.Spec.Timezone
+	return &tz
+}
+
 func reRunnerNamespacedName(suiteName string) types.NamespacedName {
+	return scheduleReRunnerNamespacedName(suiteName, "")
+}
+
+func scheduleReRunnerNamespacedName(suiteName, scheduleName string) types.NamespacedName {
 	return types.NamespacedName{
-		Name:      GetRerunnerName(suiteName),
+		Name:      getScheduleRerunnerName(suiteName, scheduleName),
 		Namespace: common.GetComplianceOperatorNamespace(),
 	}
 }
 
-func reRunnerObjectMeta(suiteName string) *metav1.ObjectMeta {
-	nsName := reRunnerNamespacedName(suiteName)
+func reRunnerObjectMeta(suiteName, scheduleName string) *metav1.ObjectMeta {
+	nsName := scheduleReRunnerNamespacedName(suiteName, scheduleName)
 
-	return &metav1.ObjectMeta{
+	meta := &metav1.ObjectMeta{
 		Name:      nsName.Name,
 		Namespace: nsName.Namespace,
 	}
+	// The rerunners of the additional schedules are labeled, so the ones
+	// of removed schedules can be found
+	if scheduleName != "" {
+		meta.Labels = map[string]string{
+			compv1alpha1.SuiteLabel:         suiteName,
+			compv1alpha1.SuiteScheduleLabel: scheduleName,
+		}
+	}
+	return meta
+}
+
+// rerunnerCommand returns the command that re-runs the scans of the suite
+// the schedule runs
+func rerunnerCommand(suite *compv1alpha1.ComplianceSuite, sched *compv1alpha1.ScanSchedule) []string {
+	command := []string{
+		"compliance-operator", "suitererunner",
+		"--name", suite.GetName(),
+		"--namespace", suite.GetNamespace(),
+	}
+	if len(sched.Scans) > 0 {
+		command = append(command, "--scans", strings.Join(sched.Scans, ","))
+	}
+	if jitter := suite.Spec.ScheduleJitter; jitter != nil && jitter.Duration > 0 {
+		command = append(command, "--jitter", jitter.Duration.String())
+	}
+	return command
 }
 
 func (r *ReconcileComplianceSuite) generateRerunnerSpec(
 	suite *compv1alpha1.ComplianceSuite,
+	sched *compv1alpha1.ScanSchedule,
 	priorityClassName string,
 ) *batchv1.CronJob {
 	return &batchv1.CronJob{
-		ObjectMeta: *reRunnerObjectMeta(suite.Name),
+		ObjectMeta: *reRunnerObjectMeta(suite.Name, sched.Name),
 		Spec: batchv1.CronJobSpec{
-			Schedule: suite.Spec.Schedule,
+			Schedule: sched.Schedule,
+			TimeZone: rerunnerTimeZone(suite),
 			JobTemplate: batchv1.JobTemplateSpec{
+				// The jobs are labeled like their pods, so the completed
+				// ones of the suite can be found and pruned
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: rerunnerLabels(suite, sched),
+				},
 				Spec: batchv1.JobSpec{
-					Template: *r.getRerunnerPodTemplate(suite, priorityClassName),
+					Template: *r.getRerunnerPodTemplate(suite, sched, priorityClassName),
 				},
 			},
 		},
 	}
 }
 
+// rerunnerLabels returns the labels of the jobs and pods of the rerunner of
+// the schedule
+func rerunnerLabels(suite *compv1alpha1.ComplianceSuite, sched *compv1alpha1.ScanSchedule) map[string]string {
+	labels := map[string]string{
+		compv1alpha1.SuiteLabel:       suite.Name,
+		compv1alpha1.SuiteScriptLabel: "",
+	}
+	if sched.Name != "" {
+		labels[compv1alpha1.SuiteScheduleLabel] = sched.Name
+	}
+	return labels
+}
+
 func (r *ReconcileComplianceSuite) getRerunnerPodTemplate(
 	suite *compv1alpha1.ComplianceSuite,
+	sched *compv1alpha1.ScanSchedule,
 	priorityClassName string,
 ) *corev1.PodTemplateSpec {
 	falseP := false
 	trueP := true
 
+	podLabels := rerunnerLabels(suite, sched)
+	podLabels["workload"] = "suitererunner"
+
 	// We need to support both v1 and beta1 CronJobs, so we need to use the
 	// same pod template for both. We can't use the same CronJob object
 	// because the API is different.
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels: map[string]string{
-				compv1alpha1.SuiteLabel:       suite.Name,
-				compv1alpha1.SuiteScriptLabel: "",
-				"workload":                    "suitererunner",
-			},
+			Labels: podLabels,
 			Annotations: map[string]string{
 				"workload.openshift.io/management": `{"effect": "PreferredDuringScheduling"}`,
 			},
@@ -146,11 +241,7 @@ func (r *ReconcileComplianceSuite) getRerunnerPodTemplate(
 						AllowPrivilegeEscalation: &falseP,
 						ReadOnlyRootFilesystem:   &trueP,
 					},
-					Command: []string{
-						"compliance-operator", "suitererunner",
-						"--name", suite.GetName(),
-						"--namespace", suite.GetNamespace(),
-					},
+					Command: rerunnerCommand(suite, sched),
 					Resources: corev1.ResourceRequirements{
 						Requests: corev1.ResourceList{
 							corev1.ResourceMemory: resource.MustParse("20Mi"),
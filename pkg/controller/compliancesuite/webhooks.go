@@ -0,0 +1,312 @@
+package compliancesuite
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	// The keys of the Secret of a webhook that hold its basic
+	// authentication credentials, next to its token and CA certificate
+	webhookUsernameKey = "username"
+	webhookPasswordKey = "password"
+
+	// The sink of the webhook calls, in the metrics
+	webhookNotificationSink = "webhook"
+)
+
+// webhookPayload is what's posted to the webhooks, and what their payload
+// templates are executed on
+type webhookPayload struct {
+	Trigger      string                     `json:"trigger"`
+	Suite        string                     `json:"suite"`
+	Namespace    string                     `json:"namespace"`
+	Phase        string                     `json:"phase"`
+	Result       string                     `json:"result"`
+	ScanRun      string                     `json:"scanRun,omitempty"`
+	ErrorMessage string                     `json:"errorMessage,omitempty"`
+	ResultCounts *compv1alpha1.ResultCounts `json:"resultCounts,omitempty"`
+	Scans        []webhookScan              `json:"scans"`
+}
+
+// webhookScan is the result of a scan in the payload of a webhook
+type webhookScan struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+}
+
+// validates that the webhooks of the suite are correctly set. Else it
+// returns false (not valid) and an error message
+func (r *ReconcileComplianceSuite) validateWebhooks(suite *compv1alpha1.ComplianceSuite) (bool, string) {
+	seen := make(map[string]bool, len(suite.Spec.Webhooks))
+	for _, webhook := range suite.Spec.Webhooks {
+		if seen[webhook.Name] {
+			return false, fmt.Sprintf("ComplianceSuite's webhook '%s' is defined more than once", webhook.Name)
+		}
+		seen[webhook.Name] = true
+		if _, err := parseWebhookTemplate(&webhook); err != nil {
+			return false, fmt.Sprintf("ComplianceSuite's webhook '%s' has a wrongly formatted payload template: %s", webhook.Name, err)
+		}
+	}
+	return true, ""
+}
+
+// parseWebhookTemplate returns the payload template of the webhook, or nil if
+// it posts the JSON payload
+func parseWebhookTemplate(webhook *compv1alpha1.Webhook) (*template.Template, error) {
+	if webhook.PayloadTemplate == "" {
+		return nil, nil
+	}
+	return template.New(webhook.Name).Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(webhook.PayloadTemplate)
+}
+
+// reconcileWebhooks calls the webhooks of the suite once its scans are done,
+// once per run of the scans. The webhooks that are triggered by NON-COMPLIANT
+// suites are only called when the result of the run is NON-COMPLIANT and the
+// one of the previous run wasn't. The webhooks are called in the background,
+// once the run they're called for is recorded in their status, and the calls
+// that still fail once their retries are exhausted are recorded there rather
+// than retried for the run, so that an endpoint that's down doesn't hold up
+// the suite. The status of the suite is updated in place.
+func (r *ReconcileComplianceSuite) reconcileWebhooks(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) error {
+	if len(suite.Spec.Webhooks) == 0 && len(suite.Status.Webhooks) == 0 {
+		return nil
+	}
+	if suite.Status.Phase != compv1alpha1.PhaseDone {
+		return nil
+	}
+	scanRun := getSuiteScanRun(suite)
+	if scanRun.IsZero() {
+		return nil
+	}
+
+	previous := make(map[string]*compv1alpha1.WebhookStatus, len(suite.Status.Webhooks))
+	for i := range suite.Status.Webhooks {
+		previous[suite.Status.Webhooks[i].Name] = &suite.Status.Webhooks[i]
+	}
+	// The statuses of the webhooks that were removed are dropped
+	changed := len(suite.Status.Webhooks) != len(suite.Spec.Webhooks)
+	statuses := make([]compv1alpha1.WebhookStatus, 0, len(suite.Spec.Webhooks))
+	var triggered []*compv1alpha1.Webhook
+	for i := range suite.Spec.Webhooks {
+		webhook := &suite.Spec.Webhooks[i]
+		status := previous[webhook.Name]
+		if status != nil && status.ScanRun.Equal(&scanRun) {
+			statuses = append(statuses, *status)
+			continue
+		}
+		changed = true
+		newStatus := compv1alpha1.WebhookStatus{Name: webhook.Name, ScanRun: scanRun, Result: suite.Status.Result}
+		if status != nil {
+			newStatus.CalledAt = status.CalledAt
+		}
+		if webhookTriggered(webhook, suite, status) {
+			triggered = append(triggered, webhook)
+		}
+		statuses = append(statuses, newStatus)
+	}
+
+	if !changed {
+		return nil
+	}
+	suite.Status.Webhooks = statuses
+	if err := r.updateStatus(suite); err != nil {
+		return err
+	}
+	for _, webhook := range triggered {
+		r.queueWebhookCall(suite, webhook, scanRun, logger)
+	}
+	return nil
+}
+
+// queueWebhookCall queues the call of the webhook for the run of the scans of
+// the suite, whose outcome is recorded in the status of the webhook once the
+// webhook is called
+func (r *ReconcileComplianceSuite) queueWebhookCall(suite *compv1alpha1.ComplianceSuite, webhook *compv1alpha1.Webhook,
+	scanRun metav1.Time, logger logr.Logger) {
+	suite = suite.DeepCopy()
+	webhook = webhook.DeepCopy()
+	logger.Info("Queueing the call of the webhook", "webhook", webhook.Name)
+	queued := r.notifications.Enqueue(common.Notification{
+		Sink: webhookNotificationSink,
+		Send: func(ctx context.Context) error {
+			return r.callWebhook(ctx, suite, webhook, scanRun)
+		},
+		Done: func(err error) {
+			r.recordWebhookCall(suite, webhook.Name, scanRun, err, logger)
+		},
+	})
+	if !queued {
+		r.recordWebhookCall(suite, webhook.Name, scanRun, fmt.Errorf("too many notifications are waiting to be sent"), logger)
+	}
+}
+
+// recordWebhookCall records the outcome of the call of the webhook for the run
+// of the scans in its status, unless the suite moved on to another run since
+func (r *ReconcileComplianceSuite) recordWebhookCall(suite *compv1alpha1.ComplianceSuite, name string, scanRun metav1.Time,
+	callErr error, logger logr.Logger) {
+	// The status the call was queued with might not be cached yet, so the
+	// suite is read from the API server
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &compv1alpha1.ComplianceSuite{}
+		if err := r.Reader.Get(context.TODO(), types.NamespacedName{Name: suite.Name, Namespace: suite.Namespace}, current); err != nil {
+			return err
+		}
+		for i := range current.Status.Webhooks {
+			status := &current.Status.Webhooks[i]
+			if status.Name != name || !status.ScanRun.Equal(&scanRun) {
+				continue
+			}
+			if callErr != nil {
+				status.Error = callErr.Error()
+			} else {
+				now := metav1.Now()
+				status.CalledAt = &now
+			}
+			return r.updateStatus(current)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Cannot record the call of the webhook", "webhook", name)
+	}
+
+	if callErr != nil {
+		logger.Error(callErr, "Cannot call the webhook", "webhook", name)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(suite, corev1.EventTypeWarning, "NotificationFailed",
+				"The webhook %s couldn't be called: %s", name, callErr)
+		}
+	} else if r.Recorder != nil {
+		r.Recorder.Eventf(suite, corev1.EventTypeNormal, "NotificationSent",
+			"The webhook %s was called", name)
+	}
+}
+
+// webhookTriggered tells whether the webhook is called for the latest run of
+// the scans of the suite, given its status for the previous run
+func webhookTriggered(webhook *compv1alpha1.Webhook, suite *compv1alpha1.ComplianceSuite, previous *compv1alpha1.WebhookStatus) bool {
+	if webhook.Trigger != compv1alpha1.WebhookOnNonCompliant {
+		return true
+	}
+	if suite.Status.Result != compv1alpha1.ResultNonCompliant {
+		return false
+	}
+	return previous == nil || previous.Result != compv1alpha1.ResultNonCompliant
+}
+
+// getSuiteScanRun returns when the latest run of the scans of the suite
+// started
+func getSuiteScanRun(suite *compv1alpha1.ComplianceSuite) metav1.Time {
+	var latest metav1.Time
+	for _, scanStatus := range suite.Status.ScanStatuses {
+		if scanStatus.StartTimestamp != nil && latest.Before(scanStatus.StartTimestamp) {
+			latest = *scanStatus.StartTimestamp
+		}
+	}
+	return latest
+}
+
+// newWebhookPayload returns the payload of the webhook for the latest run of
+// the scans of the suite
+func newWebhookPayload(suite *compv1alpha1.ComplianceSuite, webhook *compv1alpha1.Webhook, scanRun metav1.Time) *webhookPayload {
+	trigger := webhook.Trigger
+	if trigger == "" {
+		trigger = compv1alpha1.WebhookOnDone
+	}
+	payload := &webhookPayload{
+		Trigger:      string(trigger),
+		Suite:        suite.Name,
+		Namespace:    suite.Namespace,
+		Phase:        string(suite.Status.Phase),
+		Result:       string(suite.Status.Result),
+		ScanRun:      scanRun.UTC().Format(time.RFC3339),
+		ErrorMessage: suite.Status.ErrorMessage,
+		ResultCounts: suite.Status.ResultCounts,
+		Scans:        make([]webhookScan, 0, len(suite.Status.ScanStatuses)),
+	}
+	for _, scanStatus := range suite.Status.ScanStatuses {
+		payload.Scans = append(payload.Scans, webhookScan{Name: scanStatus.Name, Result: string(scanStatus.Result)})
+	}
+	return payload
+}
+
+// callWebhook renders the payload of the webhook and posts it, with the
+// credentials and the CA certificate of its Secret
+func (r *ReconcileComplianceSuite) callWebhook(ctx context.Context, suite *compv1alpha1.ComplianceSuite, webhook *compv1alpha1.Webhook,
+	scanRun metav1.Time) error {
+	payload := newWebhookPayload(suite, webhook, scanRun)
+	tmpl, err := parseWebhookTemplate(webhook)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if tmpl == nil {
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	} else {
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, payload); err != nil {
+			return fmt.Errorf("cannot render the payload template: %w", err)
+		}
+		body = out.Bytes()
+	}
+
+	config := utils.WebhookConfig{URL: webhook.URL, ContentType: webhook.ContentType}
+	if policy := webhook.RetryPolicy; policy != nil {
+		config.MaxRetries = policy.MaxRetries
+		if policy.InitialBackoff != nil {
+			config.InitialBackoff = policy.InitialBackoff.Duration
+		}
+		if policy.MaxBackoff != nil {
+			config.MaxBackoff = policy.MaxBackoff.Duration
+		}
+	}
+	var httpClient *http.Client
+	if webhook.AuthSecretName != "" {
+		secret, pool, err := r.getForwardingSecret(suite.Namespace, webhook.AuthSecretName)
+		if err != nil {
+			return err
+		}
+		config.Token = string(secret.Data[forwardingTokenKey])
+		config.Username = string(secret.Data[webhookUsernameKey])
+		config.Password = string(secret.Data[webhookPasswordKey])
+		if pool != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+			httpClient = &http.Client{Transport: transport}
+		}
+	}
+	client, err := utils.NewWebhookClient(config, httpClient)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resultForwardingTimeout)
+	defer cancel()
+	return client.Post(ctx, body)
+}
@@ -23,6 +23,8 @@ const (
 	metricNameComplianceScanError         = "compliance_scan_error_total"
 	metricNameComplianceRemediationStatus = "compliance_remediation_status_total"
 	metricNameComplianceStateGauge        = "compliance_state"
+	metricNameNotificationError           = "compliance_notification_error_total"
+	metricNameNotificationDropped         = "compliance_notification_dropped_total"
 
 	metricLabelScanResult       = "result"
 	metricLabelScanName         = "name"
@@ -31,6 +33,7 @@ const (
 	metricLabelScanError        = "error"
 	metricLabelRemediationName  = "name"
 	metricLabelRemediationState = "state"
+	metricLabelNotificationSink = "sink"
 
 	HandlerPath                  = "/metrics-co"
 	ControllerMetricsServiceName = "metrics-co"
@@ -57,6 +60,8 @@ type ControllerMetrics struct {
 	metricComplianceScanStatus        *prometheus.CounterVec
 	metricComplianceRemediationStatus *prometheus.CounterVec
 	metricComplianceStateGauge        *prometheus.GaugeVec
+	metricNotificationError           *prometheus.CounterVec
+	metricNotificationDropped         *prometheus.CounterVec
 }
 
 func DefaultControllerMetrics() *ControllerMetrics {
@@ -102,6 +107,22 @@ func DefaultControllerMetrics() *ControllerMetrics {
 				metricLabelSuiteName,
 			},
 		),
+		metricNotificationError: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:      metricNameNotificationError,
+				Namespace: metricNamespace,
+				Help:      "A counter for the total number of notifications, e.g. Kafka messages or webhook calls, that couldn't be sent",
+			},
+			[]string{metricLabelNotificationSink},
+		),
+		metricNotificationDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name:      metricNameNotificationDropped,
+				Namespace: metricNamespace,
+				Help:      "A counter for the total number of notifications that were dropped because too many were waiting to be sent",
+			},
+			[]string{metricLabelNotificationSink},
+		),
 	}
 }
 
@@ -125,6 +146,8 @@ func (m *Metrics) Register() error {
 		metricNameComplianceScanStatus:        m.metrics.metricComplianceScanStatus,
 		metricNameComplianceRemediationStatus: m.metrics.metricComplianceRemediationStatus,
 		metricNameComplianceStateGauge:        m.metrics.metricComplianceStateGauge,
+		metricNameNotificationError:           m.metrics.metricNotificationError,
+		metricNameNotificationDropped:         m.metrics.metricNotificationDropped,
 	} {
 		m.log.Info(fmt.Sprintf("Registering metric: %s", name))
 		if err := m.impl.Register(collector); err != nil {
@@ -197,3 +220,15 @@ func (m *Metrics) SetComplianceStateOutOfCompliance(name string) {
 func (m *Metrics) SetComplianceStateInCompliance(name string) {
 	m.metrics.metricComplianceStateGauge.WithLabelValues(name).Set(METRIC_STATE_COMPLIANT)
 }
+
+// IncNotificationError increments the counter of the notifications of the
+// sink that couldn't be sent
+func (m *Metrics) IncNotificationError(sink string) {
+	m.metrics.metricNotificationError.WithLabelValues(sink).Inc()
+}
+
+// IncNotificationDropped increments the counter of the notifications of the
+// sink that were dropped
+func (m *Metrics) IncNotificationDropped(sink string) {
+	m.metrics.metricNotificationDropped.WithLabelValues(sink).Inc()
+}
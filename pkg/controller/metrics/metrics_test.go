@@ -117,6 +117,21 @@ func TestComplianceOperatorMetrics(t *testing.T) {
 				require.Equal(t, 1, getMetricValue(ctr))
 			},
 		},
+		{ // notification errors and drops
+			when: func(m *Metrics) {
+				m.IncNotificationError("kafka")
+				m.IncNotificationError("kafka")
+				m.IncNotificationDropped("webhook")
+			},
+			then: func(m *Metrics) {
+				ctr, err := m.metrics.metricNotificationError.GetMetricWith(prometheus.Labels{metricLabelNotificationSink: "kafka"})
+				require.Nil(t, err)
+				require.Equal(t, 2, getMetricValue(ctr))
+				ctr, err = m.metrics.metricNotificationDropped.GetMetricWith(prometheus.Labels{metricLabelNotificationSink: "webhook"})
+				require.Nil(t, err)
+				require.Equal(t, 1, getMetricValue(ctr))
+			},
+		},
 	} {
 		mock := &metricsfakes.FakeImpl{}
 		sut := New()
@@ -0,0 +1,232 @@
+package profilebundle
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/library-go/pkg/image/reference"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+const (
+	contentContainerName  = "content-container"
+	contentAuthVolumeName = "content-auth"
+	contentAuthDir        = "/content-auth"
+)
+
+// fetchesContent tells whether the content of the bundle is downloaded,
+// from an URL or as an OCI artifact, rather than copied out of the content
+// image
+func fetchesContent(pb *compliancev1alpha1.ProfileBundle) bool {
+	return pb.Spec.ContentURL != "" || pb.Spec.ContentArtifact != ""
+}
+
+// validateBundle checks the attributes of the bundle that the API server
+// can't validate
+func validateBundle(pb *compliancev1alpha1.ProfileBundle) error {
+	if err := validateContentFiles(pb); err != nil {
+		return err
+	}
+	if _, err := parseRefreshInterval(pb); err != nil {
+		return err
+	}
+	if fetchesContent(pb) {
+		return validateContentSource(pb)
+	}
+	return nil
+}
+
+// validateContentFiles checks the data stream files the bundle lists
+func validateContentFiles(pb *compliancev1alpha1.ProfileBundle) error {
+	if (pb.Spec.ContentFile == "") == (len(pb.Spec.ContentFiles) == 0) {
+		return common.NewNonRetriableCtrlError("exactly one of 'contentFile' and 'contentFiles' needs to be set")
+	}
+	if len(pb.Spec.ContentFiles) == 0 {
+		return nil
+	}
+	if fetchesContent(pb) {
+		return common.NewNonRetriableCtrlError("the 'contentFiles' can only be used with a 'contentImage'")
+	}
+
+	prefixes := map[string]bool{}
+	for _, file := range pb.GetContentFiles() {
+		if file.File == "" {
+			return common.NewNonRetriableCtrlError("the 'contentFiles' need to set the 'file' of each of them")
+		}
+		if prefixes[file.Prefix] {
+			return common.NewNonRetriableCtrlError("the prefix '%s' is used by several of the 'contentFiles'", file.Prefix)
+		}
+		prefixes[file.Prefix] = true
+	}
+	return nil
+}
+
+// validateContentSource checks the attributes of a bundle whose content is
+// downloaded
+func validateContentSource(pb *compliancev1alpha1.ProfileBundle) error {
+	sources := 0
+	for _, source := range []string{pb.Spec.ContentImage, pb.Spec.ContentURL, pb.Spec.ContentArtifact} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return common.NewNonRetriableCtrlError("only one of 'contentImage', 'contentURL' and 'contentArtifact' can be set")
+	}
+
+	if pb.Spec.ContentArtifact != "" {
+		ref, err := reference.Parse(pb.Spec.ContentArtifact)
+		if err != nil {
+			return common.NewNonRetriableCtrlError("the 'contentArtifact' does not appear to be a valid reference to an artifact: %v", err)
+		}
+		if _, err := utils.ParseContentChecksum(ref.ID); err != nil {
+			return common.NewNonRetriableCtrlError("the 'contentArtifact' must refer to the artifact by its sha256 digest: %v", err)
+		}
+		return nil
+	}
+
+	contentURL, err := url.Parse(pb.Spec.ContentURL)
+	if err != nil {
+		return common.NewNonRetriableCtrlError("the 'contentURL' is not a valid URL: %v", err)
+	}
+	if contentURL.Scheme != "https" {
+		return common.NewNonRetriableCtrlError("the 'contentURL' must be an HTTPS URL")
+	}
+	if _, err := utils.ParseContentChecksum(pb.Spec.ContentChecksum); err != nil {
+		return common.NewNonRetriableCtrlError("the 'contentChecksum' is required with the 'contentURL': %v", err)
+	}
+	return nil
+}
+
+// validateContentPullSecret checks that the contentSecret of a bundle with a
+// contentImage can be used to pull the image. The kubelet ignores pull
+// secrets that don't exist or aren't of a pull secret type, which would only
+// surface as an image pull error. Secrets aren't watched, so the bundle is
+// checked again until the Secret is fixed.
+func (r *ReconcileProfileBundle) validateContentPullSecret(pb *compliancev1alpha1.ProfileBundle, logger logr.Logger) error {
+	if pb.Spec.ContentSecret == nil {
+		return nil
+	}
+
+	// Like mirror sets, the Secret is read with the API reader to not have
+	// the informer watch all the Secrets of the namespace
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: pb.Spec.ContentSecret.Name, Namespace: common.GetComplianceOperatorNamespace()}
+	var msg string
+	if err := r.reader.Get(context.TODO(), key, secret); errors.IsNotFound(err) {
+		msg = fmt.Sprintf("the 'contentSecret' %s doesn't exist in the %s namespace", key.Name, key.Namespace)
+	} else if err != nil {
+		return err
+	} else if secret.Type != corev1.SecretTypeDockerConfigJson && secret.Type != corev1.SecretTypeDockercfg {
+		msg = fmt.Sprintf("the 'contentSecret' %s of a 'contentImage' needs to be of the %s type to pull the image, not %s",
+			key.Name, corev1.SecretTypeDockerConfigJson, secret.Type)
+	} else {
+		return nil
+	}
+
+	return common.NewRetriableCtrlErrorWithCustomHandler(func() (reconcile.Result, error) {
+		if err := r.setBundleInvalid(pb, fmt.Errorf("%s", msg)); err != nil {
+			logger.Error(err, "Couldn't update ProfileBundle status")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}, "%s", msg)
+}
+
+// useContentFetcher makes the content container of the workload download
+// the content of the bundle instead of copying it out of the content image
+func useContentFetcher(pb *compliancev1alpha1.ProfileBundle, podSpec *corev1.PodSpec) {
+	container := getContentContainer(podSpec)
+	container.Image = utils.GetComponentImage(utils.OPERATOR)
+	container.Command = []string{"compliance-operator", "contentfetcher"}
+	if pb.Spec.ContentArtifact != "" {
+		container.Command = append(container.Command,
+			"--artifact", pb.Spec.ContentArtifact,
+			"--artifact-file", pb.Spec.ContentFile)
+	} else {
+		container.Command = append(container.Command,
+			"--url", pb.Spec.ContentURL,
+			"--checksum", pb.Spec.ContentChecksum)
+	}
+	container.Command = append(container.Command, "--output", path.Join("/content", pb.Spec.ContentFile))
+	container.ImagePullPolicy = ""
+	// Unlike copying a file, downloading needs as much as the parser
+	container.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("200Mi"),
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+	}
+
+	if pb.Spec.ContentSecret == nil {
+		return
+	}
+	container.Command = append(container.Command, "--auth-dir", contentAuthDir)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      contentAuthVolumeName,
+		MountPath: contentAuthDir,
+		ReadOnly:  true,
+	})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: contentAuthVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: pb.Spec.ContentSecret.Name,
+			},
+		},
+	})
+}
+
+// useArtifactMirrors makes the content fetcher try to download the artifact
+// from its mirrors first
+func useArtifactMirrors(podSpec *corev1.PodSpec, mirrors *imageMirrors) {
+	container := getContentContainer(podSpec)
+	for _, mirror := range mirrors.references {
+		container.Command = append(container.Command, "--artifact-mirror", mirror)
+	}
+	if mirrors.neverContactSource && len(mirrors.references) > 0 {
+		container.Command = append(container.Command, "--never-contact-source")
+	}
+}
+
+// contentSourceMatches tells whether the content container of the existing
+// workload fetches the content the same way as the desired one
+func contentSourceMatches(desired, found *corev1.PodSpec) bool {
+	desiredContainer := getContentContainer(desired)
+	foundContainer := getContentContainer(found)
+	if desiredContainer == nil || foundContainer == nil {
+		return false
+	}
+	return reflect.DeepEqual(desiredContainer.Command, foundContainer.Command) &&
+		getContentAuthSecretName(desired) == getContentAuthSecretName(found) &&
+		reflect.DeepEqual(desired.ImagePullSecrets, found.ImagePullSecrets)
+}
+
+func getContentContainer(podSpec *corev1.PodSpec) *corev1.Container {
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == contentContainerName {
+			return &podSpec.InitContainers[i]
+		}
+	}
+	return nil
+}
+
+func getContentAuthSecretName(podSpec *corev1.PodSpec) string {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == contentAuthVolumeName && volume.Secret != nil {
+			return volume.Secret.SecretName
+		}
+	}
+	return ""
+}
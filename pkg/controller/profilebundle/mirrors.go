@@ -0,0 +1,137 @@
+package profilebundle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// imageMirrors are the mirrors that the ImageDigestMirrorSets and the
+// ImageTagMirrorSets of the cluster configure for an image
+type imageMirrors struct {
+	// The references to the image in its mirrors, in the order they're
+	// tried
+	references []string
+	// Whether the image may only be pulled from its mirrors
+	neverContactSource bool
+	// Whether the cluster has any mirror sets, as disconnected clusters do
+	hasMirrorSets bool
+	// Whether the image is referred to by tag and only ImageDigestMirrorSets,
+	// which don't apply to tags, mirror it
+	onlyDigestMirrors bool
+}
+
+// getImageMirrors returns the mirrors of an image. Clusters without the
+// mirror set APIs, or that don't allow reading them, have no mirrors.
+func (r *ReconcileProfileBundle) getImageMirrors(image string) (*imageMirrors, error) {
+	ref, err := reference.Parse(image)
+	if err != nil {
+		return nil, err
+	}
+	ref = ref.DockerClientDefaults()
+	repository := ref.AsRepository().Exact()
+	byDigest := ref.ID != ""
+
+	// Like image stream tags, mirror sets are read with the API reader to
+	// not have the informer watch them
+	idmsList := &configv1.ImageDigestMirrorSetList{}
+	if err := r.reader.List(context.TODO(), idmsList); err != nil && !isMissingMirrorSetAPI(err) {
+		return nil, err
+	}
+	itmsList := &configv1.ImageTagMirrorSetList{}
+	if err := r.reader.List(context.TODO(), itmsList); err != nil && !isMissingMirrorSetAPI(err) {
+		return nil, err
+	}
+
+	mirrors := &imageMirrors{hasMirrorSets: len(idmsList.Items)+len(itmsList.Items) > 0}
+	for _, idms := range idmsList.Items {
+		for _, m := range idms.Spec.ImageDigestMirrors {
+			suffix, ok := matchMirrorSource(m.Source, repository)
+			if !ok {
+				continue
+			}
+			if !byDigest {
+				mirrors.onlyDigestMirrors = true
+				continue
+			}
+			mirrors.add(m.Mirrors, suffix+"@"+ref.ID, m.MirrorSourcePolicy)
+		}
+	}
+	if !byDigest {
+		for _, itms := range itmsList.Items {
+			for _, m := range itms.Spec.ImageTagMirrors {
+				if suffix, ok := matchMirrorSource(m.Source, repository); ok {
+					mirrors.add(m.Mirrors, suffix+":"+ref.Tag, m.MirrorSourcePolicy)
+				}
+			}
+		}
+	}
+	if len(mirrors.references) > 0 {
+		mirrors.onlyDigestMirrors = false
+	}
+	return mirrors, nil
+}
+
+func (m *imageMirrors) add(mirrors []configv1.ImageMirror, suffix string, policy configv1.MirrorSourcePolicy) {
+	for _, mirror := range mirrors {
+		m.references = append(m.references, string(mirror)+suffix)
+	}
+	if policy == configv1.NeverContactSource {
+		m.neverContactSource = true
+	}
+}
+
+// matchMirrorSource tells whether a mirror source applies to a repository,
+// and returns the part of the repository the source doesn't cover. Sources
+// are either the repository, one of its parents, or a wildcard for the
+// subdomains of its registry.
+func matchMirrorSource(source, repository string) (string, bool) {
+	if source == repository {
+		return "", true
+	}
+	if strings.HasPrefix(repository, source+"/") {
+		return strings.TrimPrefix(repository, source), true
+	}
+	if strings.HasPrefix(source, "*.") {
+		registry, _, _ := strings.Cut(repository, "/")
+		if strings.HasSuffix(registry, source[1:]) {
+			return strings.TrimPrefix(repository, registry), true
+		}
+	}
+	return "", false
+}
+
+func isMissingMirrorSetAPI(err error) bool {
+	return errors.IsNotFound(err) || errors.IsForbidden(err) || runtime.IsNotRegisteredError(err) || meta.IsNoMatchError(err)
+}
+
+// getImagePullErrorMessage explains why the content image couldn't be
+// pulled, pointing at the mirrors of the image, or at the lack of them in
+// clusters that pull their images from mirrors
+func getImagePullErrorMessage(image, pullMessage string, mirrors *imageMirrors) string {
+	msg := fmt.Sprintf("The content image %s couldn't be pulled", image)
+	if pullMessage != "" {
+		msg += ": " + pullMessage
+	}
+	msg += "."
+	switch {
+	case len(mirrors.references) > 0:
+		msg += fmt.Sprintf(" The image is mirrored to %s, verify that the mirrors have the image.",
+			strings.Join(mirrors.references, ", "))
+	case mirrors.onlyDigestMirrors:
+		msg += " Only ImageDigestMirrorSets mirror the image, which don't apply to images referred to by tag." +
+			" Refer to the image by its digest or add an ImageTagMirrorSet."
+	case mirrors.hasMirrorSets:
+		msg += " None of the ImageDigestMirrorSets and ImageTagMirrorSets of the cluster mirror the image," +
+			" which the cluster needs if it can't reach the registry of the image."
+	default:
+		msg += " Verify Spec.ContentImage and Spec.ContentSecret."
+	}
+	return msg
+}
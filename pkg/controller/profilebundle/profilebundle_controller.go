@@ -12,6 +12,8 @@ import (
 
 	"fmt"
 	"path"
+	"reflect"
+	"strings"
 
 	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
@@ -147,18 +149,23 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 	}
 
 	annotations := map[string]string{}
-	isISTag, isTagImageRef, err := r.pointsToISTag(instance.Spec.ContentImage)
+	isISTag, isTagImageRef := false, ""
+	err = validateBundle(instance)
+	if err == nil && !fetchesContent(instance) {
+		err = r.validateContentPullSecret(instance, reqLogger)
+	}
+	if err == nil && !fetchesContent(instance) {
+		isISTag, isTagImageRef, err = r.pointsToISTag(instance.Spec.ContentImage)
+	}
 	if err != nil {
+		if common.HasCustomHandler(err) {
+			return common.CallCustomHandler(err)
+		}
 		if common.IsRetriable(err) {
 			return reconcile.Result{}, err
 		}
 
-		pbCopy := instance.DeepCopy()
-		pbCopy.Status.DataStreamStatus = compliancev1alpha1.DataStreamInvalid
-		pbCopy.Status.ErrorMessage = err.Error()
-		pbCopy.Status.SetConditionInvalid()
-		err = r.Client.Status().Update(context.TODO(), pbCopy)
-		if err != nil {
+		if err := r.setBundleInvalid(instance, err); err != nil {
 			reqLogger.Error(err, "Couldn't update ProfileBundle status")
 			return reconcile.Result{}, err
 		}
@@ -167,7 +174,9 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 	}
 
 	effectiveImage := instance.Spec.ContentImage
-	if isISTag {
+	if fetchesContent(instance) {
+		effectiveImage = utils.GetComponentImage(utils.OPERATOR)
+	} else if isISTag {
 		// NOTE(jaosorior): Errors were already checked for in the pointsToISTag function
 		ref, _ := reference.Parse(instance.Spec.ContentImage)
 		annotations = getISTagAnnotation(ref.NameString(), getISTagNamespace(ref))
@@ -176,6 +185,13 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 
 	// Define a new Pod object
 	depl := r.newWorkloadForBundle(instance, effectiveImage)
+	if instance.Spec.ContentArtifact != "" {
+		mirrors, err := r.getImageMirrors(instance.Spec.ContentArtifact)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		useArtifactMirrors(&depl.Spec.Template.Spec, mirrors)
+	}
 
 	found := &appsv1.Deployment{}
 	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: depl.Name, Namespace: depl.Namespace}, found)
@@ -192,7 +208,8 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 		return reconcile.Result{}, err
 	}
 
-	if workloadNeedsUpdate(effectiveImage, found) {
+	if workloadNeedsUpdate(effectiveImage, found) || !contentSourceMatches(&depl.Spec.Template.Spec, &found.Spec.Template.Spec) ||
+		!profileParserCommandMatches(&depl.Spec.Template.Spec, &found.Spec.Template.Spec) {
 		pbCopy := instance.DeepCopy()
 		pbCopy.Status.DataStreamStatus = compliancev1alpha1.DataStreamPending
 		pbCopy.Status.ErrorMessage = ""
@@ -239,6 +256,15 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 		pbCopy := instance.DeepCopy()
 		pbCopy.Status.DataStreamStatus = compliancev1alpha1.DataStreamInvalid
 		pbCopy.Status.ErrorMessage = "The init container failed to start. Verify Status.ContentImage."
+		if fetchesContent(instance) {
+			pbCopy.Status.ErrorMessage = "The init container failed to fetch the content. Verify the content source and Spec.ContentSecret."
+		} else if pullMessage, failed := getContentImagePullError(relevantPod); failed {
+			mirrors, err := r.getImageMirrors(effectiveImage)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			pbCopy.Status.ErrorMessage = getImagePullErrorMessage(effectiveImage, pullMessage, mirrors)
+		}
 		pbCopy.Status.SetConditionInvalid()
 		err = r.Client.Status().Update(context.TODO(), pbCopy)
 		if err != nil {
@@ -252,6 +278,19 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 	// Pod already exists and its init container at least ran - don't requeue
 	reqLogger.Info("Skip reconcile: Workload already up-to-date", "Deployment.Namespace", found.Namespace, "Deployment.Name", found.Name)
 
+	if digest := getContentImageDigest(relevantPod); !fetchesContent(instance) && digest != "" && digest != instance.Status.ContentDigest {
+		reqLogger.Info("Recording the digest of the parsed content image", "digest", digest)
+		pbCopy := instance.DeepCopy()
+		pbCopy.Status.PreviousContentDigest = instance.Status.ContentDigest
+		pbCopy.Status.ContentDigest = digest
+		err = r.Client.Status().Update(context.TODO(), pbCopy)
+		if err != nil {
+			reqLogger.Error(err, "Couldn't update ProfileBundle status")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	// Handle upgrades
 	if instance.Status.DataStreamStatus == compliancev1alpha1.DataStreamValid &&
 		instance.Status.Conditions.GetCondition("Ready") == nil {
@@ -264,9 +303,22 @@ func (r *ReconcileProfileBundle) Reconcile(ctx context.Context, request reconcil
 			return reconcile.Result{}, err
 		}
 	}
+
+	if interval := getRefreshInterval(instance, isISTag); interval > 0 {
+		return r.refreshContent(found, relevantPod, interval, reqLogger)
+	}
 	return reconcile.Result{}, nil
 }
 
+// setBundleInvalid marks the bundle as invalid because of the given error
+func (r *ReconcileProfileBundle) setBundleInvalid(pb *compliancev1alpha1.ProfileBundle, err error) error {
+	pbCopy := pb.DeepCopy()
+	pbCopy.Status.DataStreamStatus = compliancev1alpha1.DataStreamInvalid
+	pbCopy.Status.ErrorMessage = err.Error()
+	pbCopy.Status.SetConditionInvalid()
+	return r.Client.Status().Update(context.TODO(), pbCopy)
+}
+
 func (r *ReconcileProfileBundle) profileBundleDeleteHandler(pb *compliancev1alpha1.ProfileBundle, logger logr.Logger) error {
 	logger.Info("The ProfileBundle is being deleted")
 	pod := r.newWorkloadForBundle(pb, "")
@@ -276,6 +328,13 @@ func (r *ReconcileProfileBundle) profileBundleDeleteHandler(pb *compliancev1alph
 		return err
 	}
 
+	// The objects parsed out of the content are owned by the bundle and
+	// garbage collected along with it, but objects that lost their owner
+	// reference would linger, so delete them explicitly
+	if err := r.deleteParsedObjects(pb, logger); err != nil {
+		return err
+	}
+
 	pbCopy := pb.DeepCopy()
 	// remove our finalizer from the list and update it.
 	pbCopy.ObjectMeta.Finalizers = common.RemoveFinalizer(pbCopy.ObjectMeta.Finalizers, compliancev1alpha1.ProfileBundleFinalizer)
@@ -285,6 +344,20 @@ func (r *ReconcileProfileBundle) profileBundleDeleteHandler(pb *compliancev1alph
 	return nil
 }
 
+func (r *ReconcileProfileBundle) deleteParsedObjects(pb *compliancev1alpha1.ProfileBundle, logger logr.Logger) error {
+	inNs := client.InNamespace(pb.Namespace)
+	withPbOwnerLabel := client.MatchingLabels{
+		compliancev1alpha1.ProfileBundleOwnerLabel: pb.Name,
+	}
+	for _, obj := range []client.Object{&compliancev1alpha1.Profile{}, &compliancev1alpha1.Rule{}, &compliancev1alpha1.Variable{}} {
+		if err := r.Client.DeleteAllOf(context.TODO(), obj, inNs, withPbOwnerLabel); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	logger.Info("Deleted the objects parsed out of the content")
+	return nil
+}
+
 func (r *ReconcileProfileBundle) pointsToISTag(contentImageRef string) (bool, string, error) {
 	ref, err := reference.Parse(contentImageRef)
 	if err != nil {
@@ -402,7 +475,7 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 	falseP := false
 	trueP := true
 	labels := getWorkloadLabels(pb)
-	return &appsv1.Deployment{
+	depl := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pb.Name + "-" + pb.Namespace + "-pp",
 			Namespace: common.GetComplianceOperatorNamespace(),
@@ -433,7 +506,7 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 							Command: []string{
 								"sh",
 								"-c",
-								fmt.Sprintf("cp %s /content | /bin/true", path.Join("/", pb.Spec.ContentFile)),
+								fmt.Sprintf("cp %s /content | /bin/true", strings.Join(getContentFilePaths(pb), " ")),
 							},
 							ImagePullPolicy: corev1.PullAlways,
 							SecurityContext: &corev1.SecurityContext{
@@ -482,12 +555,7 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 									corev1.ResourceCPU:    resource.MustParse("100m"),
 								},
 							},
-							Command: []string{
-								"compliance-operator", "profileparser",
-								"--name", pb.Name,
-								"--namespace", pb.Namespace,
-								"--ds-path", path.Join("/content", pb.Spec.ContentFile),
-							},
+							Command: getProfileParserCommand(pb),
 							Env: []corev1.EnvVar{
 								corev1.EnvVar{Name: "PLATFORM", Value: utils.GetPlatform()},
 								corev1.EnvVar{Name: "CONTROL_PLANE_TOPOLOGY", Value: utils.GetControlPlaneTopology()},
@@ -549,6 +617,53 @@ func (r *ReconcileProfileBundle) newWorkloadForBundle(pb *compliancev1alpha1.Pro
 			},
 		},
 	}
+	if fetchesContent(pb) {
+		useContentFetcher(pb, &depl.Spec.Template.Spec)
+	} else if pb.Spec.ContentSecret != nil {
+		depl.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{*pb.Spec.ContentSecret}
+	}
+	return depl
+}
+
+// getContentFilePaths returns the paths of the data stream files of the
+// bundle in the content image
+func getContentFilePaths(pb *compliancev1alpha1.ProfileBundle) []string {
+	paths := []string{}
+	for _, file := range pb.GetContentFiles() {
+		paths = append(paths, path.Join("/", file.File))
+	}
+	return paths
+}
+
+func getProfileParserCommand(pb *compliancev1alpha1.ProfileBundle) []string {
+	command := []string{
+		"compliance-operator", "profileparser",
+		"--name", pb.Name,
+		"--namespace", pb.Namespace,
+	}
+	if pb.Spec.DryRunGarbageCollection {
+		command = append(command, "--dry-run-garbage-collection")
+	}
+	// A bundle with several data streams has them all copied to /content
+	if len(pb.Spec.ContentFiles) > 0 {
+		return append(command, "--content-dir", "/content")
+	}
+	return append(command, "--ds-path", path.Join("/content", pb.Spec.ContentFile))
+}
+
+// profileParserCommandMatches tells whether the profile parser of the found
+// workload runs with the desired arguments, which change with the spec of
+// the bundle
+func profileParserCommandMatches(desired, found *corev1.PodSpec) bool {
+	getCommand := func(podSpec *corev1.PodSpec) []string {
+		for i := range podSpec.InitContainers {
+			if podSpec.InitContainers[i].Name == "profileparser" {
+				return podSpec.InitContainers[i].Command
+			}
+		}
+		return nil
+	}
+	return reflect.DeepEqual(getCommand(desired), getCommand(found))
 }
 
 // podStartupError returns false if for some reason the pod couldn't even
@@ -571,12 +686,33 @@ func podStartupError(pod *corev1.Pod) bool {
 		switch initStatus.State.Waiting.Reason {
 		case "ImagePullBackOff", "ErrImagePull":
 			return true
+		case "CrashLoopBackOff":
+			// Only downloading the content from its URL can fail in
+			// the content container
+			if initStatus.Name == contentContainerName {
+				return true
+			}
 		}
 	}
 
 	return false
 }
 
+// getContentImagePullError returns the message of the error the content
+// image couldn't be pulled with, if it couldn't be
+func getContentImagePullError(pod *corev1.Pod) (string, bool) {
+	for _, initStatus := range pod.Status.InitContainerStatuses {
+		if initStatus.Name != contentContainerName || initStatus.State.Waiting == nil {
+			continue
+		}
+		switch initStatus.State.Waiting.Reason {
+		case "ImagePullBackOff", "ErrImagePull":
+			return initStatus.State.Waiting.Message, true
+		}
+	}
+	return "", false
+}
+
 func workloadNeedsUpdate(image string, depl *appsv1.Deployment) bool {
 	initContainers := depl.Spec.Template.Spec.InitContainers
 	if len(initContainers) != 2 {
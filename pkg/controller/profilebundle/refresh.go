@@ -0,0 +1,100 @@
+package profilebundle
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/openshift/library-go/pkg/image/reference"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+// Set on the pod template of the workload to roll it out again, which
+// pulls the content image again
+const contentRefreshAnnotation = "compliance.openshift.io/content-refreshed"
+
+func parseRefreshInterval(pb *compliancev1alpha1.ProfileBundle) (time.Duration, error) {
+	if pb.Spec.RefreshInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(pb.Spec.RefreshInterval)
+	if err != nil {
+		return 0, common.NewNonRetriableCtrlError("the 'refreshInterval' is not a valid duration: %v", err)
+	}
+	if interval <= 0 {
+		return 0, common.NewNonRetriableCtrlError("the 'refreshInterval' needs to be positive")
+	}
+	if fetchesContent(pb) {
+		return 0, common.NewNonRetriableCtrlError("the 'refreshInterval' can only be used with a 'contentImage'")
+	}
+	return interval, nil
+}
+
+// getRefreshInterval returns how often the content of the bundle is
+// refreshed, or zero if it isn't. Images referred to by digest can't
+// change, and image stream tags already roll out the workload when they're
+// updated.
+func getRefreshInterval(pb *compliancev1alpha1.ProfileBundle, isISTag bool) time.Duration {
+	interval, err := parseRefreshInterval(pb)
+	if err != nil || isISTag {
+		return 0
+	}
+	ref, err := reference.Parse(pb.Spec.ContentImage)
+	if err != nil || ref.ID != "" {
+		return 0
+	}
+	return interval
+}
+
+// refreshContent rolls out the workload again once the refresh interval
+// passed since the content was last pulled, which makes the content image
+// be pulled again and its content be re-parsed
+func (r *ReconcileProfileBundle) refreshContent(depl *appsv1.Deployment, pod *corev1.Pod, interval time.Duration, logger logr.Logger) (reconcile.Result, error) {
+	lastRefresh := pod.CreationTimestamp.Time
+	// The pod of the last refresh might not have been created yet
+	refreshed, err := time.Parse(time.RFC3339, depl.Spec.Template.Annotations[contentRefreshAnnotation])
+	if err == nil && refreshed.After(lastRefresh) {
+		lastRefresh = refreshed
+	}
+	if elapsed := time.Since(lastRefresh); elapsed < interval {
+		return reconcile.Result{RequeueAfter: interval - elapsed}, nil
+	}
+
+	logger.Info("Refreshing the content", "Deployment.Namespace", depl.Namespace, "Deployment.Name", depl.Name)
+	updatedDepl := depl.DeepCopy()
+	if updatedDepl.Spec.Template.Annotations == nil {
+		updatedDepl.Spec.Template.Annotations = map[string]string{}
+	}
+	updatedDepl.Spec.Template.Annotations[contentRefreshAnnotation] = time.Now().Format(time.RFC3339)
+	if err := r.Client.Update(context.TODO(), updatedDepl); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
+}
+
+// getContentImageDigest returns the digest of the content image that the
+// content was parsed from, once the profile parser of the pod succeeded
+func getContentImageDigest(pod *corev1.Pod) string {
+	digest := ""
+	parsed := false
+	for _, status := range pod.Status.InitContainerStatuses {
+		switch status.Name {
+		case contentContainerName:
+			if i := strings.LastIndex(status.ImageID, "@"); i >= 0 {
+				digest = status.ImageID[i+1:]
+			}
+		case "profileparser":
+			parsed = status.State.Terminated != nil && status.State.Terminated.ExitCode == 0
+		}
+	}
+	if !parsed {
+		return ""
+	}
+	return digest
+}
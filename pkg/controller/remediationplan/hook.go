@@ -0,0 +1,93 @@
+package remediationplan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// Runs the pre-apply hook of the plan for the step, unless it already ran
+// for it, and tells whether it succeeded. The hook runs again for every
+// generation of the plan, so that e.g. applying the plan again after it was
+// un-applied backs up etcd again.
+func (r *ReconcileRemediationPlan) runPreApplyHook(plan *compv1alpha1.RemediationPlan, step []planRemediation,
+	status *compv1alpha1.RemediationPlanStatus, logger logr.Logger) (bool, error) {
+	stepName := "all"
+	if plan.Spec.Order == compv1alpha1.RemediationPlanOrderSeverity {
+		stepName = string(step[0].severity)
+	}
+	jobName := utils.DNSLengthName("rplan-hook-", "%s-hook-%s-%d", plan.Name, stepName, plan.Generation)
+	status.HookJob = jobName
+
+	job := &batchv1.Job{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: jobName, Namespace: plan.Namespace}, job)
+	if kerrors.IsNotFound(err) {
+		return false, r.createHookJob(plan, jobName, status, logger)
+	} else if err != nil {
+		return false, err
+	}
+
+	if jobHasCondition(job, batchv1.JobFailed) {
+		status.ErrorMessage = fmt.Sprintf("The pre-apply hook Job %s failed, delete it to run the hook again", jobName)
+		return false, nil
+	}
+	return jobHasCondition(job, batchv1.JobComplete), nil
+}
+
+// Creates the Job of the hook from the job template of its CronJob
+func (r *ReconcileRemediationPlan) createHookJob(plan *compv1alpha1.RemediationPlan, jobName string,
+	status *compv1alpha1.RemediationPlanStatus, logger logr.Logger) error {
+	cronJobKey := types.NamespacedName{Name: plan.Spec.PreApplyHook.CronJobName, Namespace: plan.Namespace}
+	cronJob := &batchv1.CronJob{}
+	err := r.Client.Get(context.TODO(), cronJobKey, cronJob)
+	if kerrors.IsNotFound(err) {
+		status.ErrorMessage = fmt.Sprintf("The CronJob %s of the pre-apply hook doesn't exist", cronJobKey.Name)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	template := cronJob.Spec.JobTemplate
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        jobName,
+			Namespace:   plan.Namespace,
+			Labels:      map[string]string{},
+			Annotations: template.Annotations,
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	for key, value := range template.Labels {
+		job.Labels[key] = value
+	}
+	job.Labels[compv1alpha1.RemediationPlanLabel] = plan.Name
+	if err := controllerutil.SetControllerReference(plan, job, r.Scheme); err != nil {
+		return err
+	}
+
+	logger.Info("Running the pre-apply hook of the plan", "Job.Name", jobName, "CronJob.Name", cronJob.Name)
+	err = r.Client.Create(context.TODO(), job)
+	if kerrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func jobHasCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package remediationplan
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+type remediationMapper struct {
+	client.Client
+}
+
+// Map enqueues all the plans in the namespace of the remediation, as any of
+// them might select it
+func (m *remediationMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	planList := compv1alpha1.RemediationPlanList{}
+	err := m.List(ctx, &planList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return requests
+	}
+
+	for _, plan := range planList.Items {
+		objKey := types.NamespacedName{
+			Name:      plan.GetName(),
+			Namespace: plan.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
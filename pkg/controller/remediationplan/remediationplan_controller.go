@@ -0,0 +1,241 @@
+package remediationplan
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("remediationplanctrl")
+
+// Add creates a new RemediationPlan Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, _ *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileRemediationPlan{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	remMapper := &remediationMapper{mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("remediationplan-controller").
+		For(&compv1alpha1.RemediationPlan{}).
+		Owns(&batchv1.Job{}).
+		Watches(&compv1alpha1.ComplianceRemediation{}, handler.EnqueueRequestsFromMapFunc(remMapper.Map)).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileRemediationPlan implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileRemediationPlan{}
+
+// ReconcileRemediationPlan reconciles a RemediationPlan object
+type ReconcileRemediationPlan struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile reads that state of the cluster for a RemediationPlan object and makes changes based on the state read
+// and what is in the RemediationPlan.Spec
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileRemediationPlan) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling RemediationPlan")
+
+	// Fetch the RemediationPlan instance
+	instance := &compv1alpha1.RemediationPlan{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// The remediations the plan applied stay applied.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	rems, err := r.getPlanRemediations(instance, reqLogger)
+	if err != nil {
+		if common.IsRetriable(err) {
+			return reconcile.Result{}, err
+		}
+		status := compv1alpha1.RemediationPlanStatus{
+			Phase:        compv1alpha1.RemediationPlanError,
+			ErrorMessage: err.Error(),
+		}
+		return reconcile.Result{}, r.updatePlanStatus(instance, status, reqLogger)
+	}
+
+	var status compv1alpha1.RemediationPlanStatus
+	if instance.Spec.Apply {
+		status, err = r.applyPlan(instance, rems, reqLogger)
+	} else {
+		status, err = r.unapplyPlan(instance, rems, reqLogger)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.updatePlanStatus(instance, status, reqLogger)
+}
+
+// Applies the remediations of the first step of the plan that isn't fully
+// applied yet and returns the resulting status of the plan
+func (r *ReconcileRemediationPlan) applyPlan(plan *compv1alpha1.RemediationPlan, rems []planRemediation,
+	logger logr.Logger) (compv1alpha1.RemediationPlanStatus, error) {
+	status := countPlanRemediations(rems)
+
+	for _, step := range getPlanSteps(plan, rems) {
+		if stepIsApplied(step) {
+			continue
+		}
+		if plan.Spec.Order == compv1alpha1.RemediationPlanOrderSeverity {
+			status.CurrentSeverity = step[0].severity
+		}
+		if plan.Spec.PreApplyHook != nil && !stepApplyIsSet(step) {
+			succeeded, err := r.runPreApplyHook(plan, step, &status, logger)
+			if err != nil || !succeeded {
+				return getPlanPhase(status), err
+			}
+		}
+		for i := range step {
+			if err := r.applyPlanRemediation(plan, step[i].rem, logger); err != nil {
+				return status, err
+			}
+		}
+		break
+	}
+	return getPlanPhase(status), nil
+}
+
+// Sets the phase of an applied plan from the rest of its status
+func getPlanPhase(status compv1alpha1.RemediationPlanStatus) compv1alpha1.RemediationPlanStatus {
+	if len(status.FailedRemediations) > 0 || status.ErrorMessage != "" {
+		status.Phase = compv1alpha1.RemediationPlanError
+	} else if status.AppliedRemediations == status.TotalRemediations {
+		status.Phase = compv1alpha1.RemediationPlanApplied
+	} else {
+		status.Phase = compv1alpha1.RemediationPlanApplying
+	}
+	return status
+}
+
+// Un-applies the remediations that the plan applied. The remediations that
+// were applied before the plan selected them are left alone.
+func (r *ReconcileRemediationPlan) unapplyPlan(plan *compv1alpha1.RemediationPlan, rems []planRemediation,
+	logger logr.Logger) (compv1alpha1.RemediationPlanStatus, error) {
+	// The selection of the plan might have changed since it applied the
+	// remediations, so they are looked up by the label instead
+	applied := &compv1alpha1.ComplianceRemediationList{}
+	err := r.Client.List(context.TODO(), applied, client.InNamespace(plan.Namespace),
+		client.MatchingLabels{compv1alpha1.RemediationPlanLabel: plan.Name})
+	if err != nil {
+		return compv1alpha1.RemediationPlanStatus{}, err
+	}
+	for i := range applied.Items {
+		rem := &applied.Items[i]
+		logger.Info("Un-applying the remediation of the plan", "ComplianceRemediation.Name", rem.Name)
+		remCopy := rem.DeepCopy()
+		remCopy.Spec.Apply = false
+		delete(remCopy.Labels, compv1alpha1.RemediationPlanLabel)
+		if err := r.Client.Update(context.TODO(), remCopy); err != nil {
+			return compv1alpha1.RemediationPlanStatus{}, err
+		}
+	}
+
+	status := countPlanRemediations(rems)
+	status.Phase = compv1alpha1.RemediationPlanPending
+	return status, nil
+}
+
+// Sets apply on the remediation and marks it as applied by the plan, unless
+// it is applied already
+func (r *ReconcileRemediationPlan) applyPlanRemediation(plan *compv1alpha1.RemediationPlan,
+	rem *compv1alpha1.ComplianceRemediation, logger logr.Logger) error {
+	if rem.Spec.Apply {
+		return nil
+	}
+	logger.Info("Applying the remediation of the plan", "ComplianceRemediation.Name", rem.Name)
+	remCopy := rem.DeepCopy()
+	remCopy.Spec.Apply = true
+	if utils.IsMachineConfig(remCopy.Spec.Current.Object) {
+		remCopy.Spec.Batch = plan.Spec.Batch
+	}
+	if remCopy.Labels == nil {
+		remCopy.Labels = make(map[string]string)
+	}
+	remCopy.Labels[compv1alpha1.RemediationPlanLabel] = plan.Name
+	return r.Client.Update(context.TODO(), remCopy)
+}
+
+func (r *ReconcileRemediationPlan) updatePlanStatus(plan *compv1alpha1.RemediationPlan, status compv1alpha1.RemediationPlanStatus,
+	logger logr.Logger) error {
+	if equality.Semantic.DeepEqual(plan.Status, status) {
+		return nil
+	}
+	logger.Info("Updating the status of the plan", "phase", status.Phase,
+		"applied", status.AppliedRemediations, "total", status.TotalRemediations)
+	planCopy := plan.DeepCopy()
+	planCopy.Status = status
+	return r.Client.Status().Update(context.TODO(), planCopy)
+}
+
+func countPlanRemediations(rems []planRemediation) compv1alpha1.RemediationPlanStatus {
+	status := compv1alpha1.RemediationPlanStatus{
+		TotalRemediations: int32(len(rems)),
+	}
+	for _, prem := range rems {
+		switch prem.rem.Status.ApplicationState {
+		case compv1alpha1.RemediationApplied:
+			status.AppliedRemediations++
+		case compv1alpha1.RemediationError:
+			status.FailedRemediations = append(status.FailedRemediations, prem.rem.Name)
+		}
+	}
+	return status
+}
+
+// Tells whether apply is set on all the remediations of the step, in which
+// case the plan doesn't need to run its pre-apply hook for it anymore
+func stepApplyIsSet(step []planRemediation) bool {
+	for _, prem := range step {
+		if !prem.rem.Spec.Apply {
+			return false
+		}
+	}
+	return true
+}
+
+func stepIsApplied(step []planRemediation) bool {
+	for _, prem := range step {
+		if !prem.rem.Spec.Apply || prem.rem.Status.ApplicationState != compv1alpha1.RemediationApplied {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package remediationplan
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mcfgapi "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Testing remediationplan controller", func() {
+	const namespace = "test-ns"
+
+	var (
+		plan       *compv1alpha1.RemediationPlan
+		reconciler *ReconcileRemediationPlan
+	)
+
+	newCheck := func(name string, severity compv1alpha1.ComplianceCheckResultSeverity) *compv1alpha1.ComplianceCheckResult {
+		return &compv1alpha1.ComplianceCheckResult{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: map[string]string{compv1alpha1.ComplianceCheckResultRuleAnnotation: name},
+			},
+			Severity: severity,
+		}
+	}
+	newRemediation := func(name, suite string, obj *unstructured.Unstructured) *compv1alpha1.ComplianceRemediation {
+		return &compv1alpha1.ComplianceRemediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					compv1alpha1.SuiteLabel:          suite,
+					compv1alpha1.ComplianceScanLabel: "my-scan",
+				},
+			},
+			Spec: compv1alpha1.ComplianceRemediationSpec{
+				Current: compv1alpha1.ComplianceRemediationPayload{Object: obj},
+			},
+		}
+	}
+	newObject := func(apiVersion, kind, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		return obj
+	}
+
+	reconcilePlan := func() *compv1alpha1.RemediationPlan {
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: plan.Name, Namespace: namespace},
+		})
+		Expect(err).To(BeNil())
+		found := &compv1alpha1.RemediationPlan{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: plan.Name, Namespace: namespace}, found)
+		Expect(err).To(BeNil())
+		return found
+	}
+	getRemediation := func(name string) *compv1alpha1.ComplianceRemediation {
+		rem := &compv1alpha1.ComplianceRemediation{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, rem)
+		Expect(err).To(BeNil())
+		return rem
+	}
+	setApplicationState := func(name string, state compv1alpha1.RemediationApplicationState) {
+		rem := getRemediation(name)
+		rem.Status.ApplicationState = state
+		Expect(reconciler.Client.Status().Update(context.TODO(), rem)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		plan = &compv1alpha1.RemediationPlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-plan",
+				Namespace: namespace,
+			},
+			Spec: compv1alpha1.RemediationPlanSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{compv1alpha1.SuiteLabel: "my-suite"},
+				},
+				Order: compv1alpha1.RemediationPlanOrderSeverity,
+				Apply: true,
+			},
+		}
+		pool := &mcfgv1.MachineConfigPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec: mcfgv1.MachineConfigPoolSpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+				},
+			},
+		}
+		scan := &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-scan", Namespace: namespace},
+			Spec: compv1alpha1.ComplianceScanSpec{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/worker": ""},
+			},
+		}
+
+		highRem := newRemediation("high-rem", "my-suite", newObject("v1", "ConfigMap", "high-cm"))
+		lowRem := newRemediation("low-rem", "my-suite", newObject("v1", "ConfigMap", "low-cm"))
+		mcRem := newRemediation("mc-rem", "my-suite", newObject("machineconfiguration.openshift.io/v1", "MachineConfig", "mc"))
+		otherRem := newRemediation("other-rem", "other-suite", newObject("v1", "ConfigMap", "other-cm"))
+
+		objs := []runtime.Object{
+			plan, pool, scan, highRem, lowRem, mcRem, otherRem,
+			newCheck("high-rem", compv1alpha1.CheckResultSeverityHigh),
+			newCheck("low-rem", compv1alpha1.CheckResultSeverityLow),
+			newCheck("mc-rem", compv1alpha1.CheckResultSeverityHigh),
+			newCheck("other-rem", compv1alpha1.CheckResultSeverityHigh),
+		}
+		cscheme := scheme.Scheme
+		err := apis.AddToScheme(cscheme)
+		Expect(err).To(BeNil())
+		err = mcfgapi.Install(cscheme)
+		Expect(err).To(BeNil())
+
+		client := fake.NewClientBuilder().
+			WithScheme(cscheme).
+			WithStatusSubresource(plan, highRem, lowRem, mcRem, otherRem).
+			WithRuntimeObjects(objs...).
+			Build()
+		reconciler = &ReconcileRemediationPlan{Client: client, Scheme: cscheme}
+	})
+
+	It("should apply the remediations of the plan by severity", func() {
+		By("applying the remediations of the highest severity first")
+		found := reconcilePlan()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanApplying))
+		Expect(found.Status.TotalRemediations).To(BeEquivalentTo(3))
+		Expect(found.Status.CurrentSeverity).To(Equal(compv1alpha1.CheckResultSeverityHigh))
+		Expect(getRemediation("high-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("high-rem").Labels[compv1alpha1.RemediationPlanLabel]).To(Equal("my-plan"))
+		Expect(getRemediation("mc-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeFalse())
+		Expect(getRemediation("other-rem").Spec.Apply).To(BeFalse())
+
+		By("applying the next severity once the previous one is applied")
+		setApplicationState("high-rem", compv1alpha1.RemediationApplied)
+		reconcilePlan()
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeFalse())
+		setApplicationState("mc-rem", compv1alpha1.RemediationApplied)
+		found = reconcilePlan()
+		Expect(found.Status.CurrentSeverity).To(Equal(compv1alpha1.CheckResultSeverityLow))
+		Expect(found.Status.AppliedRemediations).To(BeEquivalentTo(2))
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeTrue())
+
+		By("reporting the plan as applied once all remediations are")
+		setApplicationState("low-rem", compv1alpha1.RemediationApplied)
+		found = reconcilePlan()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanApplied))
+		Expect(found.Status.AppliedRemediations).To(BeEquivalentTo(3))
+		Expect(found.Status.CurrentSeverity).To(BeEmpty())
+	})
+
+	It("should apply all the remediations at once in parallel order", func() {
+		plan.Spec.Order = compv1alpha1.RemediationPlanOrderParallel
+		plan.Spec.Batch = true
+		Expect(reconciler.Client.Update(context.TODO(), plan)).To(Succeed())
+
+		reconcilePlan()
+		Expect(getRemediation("high-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("mc-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("mc-rem").Spec.Batch).To(BeTrue())
+		Expect(getRemediation("high-rem").Spec.Batch).To(BeFalse())
+	})
+
+	It("should only select the remediations passing the filter and of the pools", func() {
+		plan.Spec.Order = compv1alpha1.RemediationPlanOrderParallel
+		plan.Spec.Filter = &compv1alpha1.RemediationApplyFilter{
+			Severities: []compv1alpha1.ComplianceCheckResultSeverity{compv1alpha1.CheckResultSeverityHigh},
+		}
+		plan.Spec.Pools = []string{"master"}
+		Expect(reconciler.Client.Update(context.TODO(), plan)).To(Succeed())
+
+		found := reconcilePlan()
+		Expect(found.Status.TotalRemediations).To(BeEquivalentTo(1))
+		Expect(getRemediation("high-rem").Spec.Apply).To(BeTrue())
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeFalse())
+		Expect(getRemediation("mc-rem").Spec.Apply).To(BeFalse())
+	})
+
+	It("should report the remediations that failed", func() {
+		reconcilePlan()
+		setApplicationState("high-rem", compv1alpha1.RemediationError)
+
+		found := reconcilePlan()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanError))
+		Expect(found.Status.FailedRemediations).To(ConsistOf("high-rem"))
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeFalse())
+	})
+
+	It("should only un-apply the remediations the plan applied", func() {
+		lowRem := getRemediation("low-rem")
+		lowRem.Spec.Apply = true
+		Expect(reconciler.Client.Update(context.TODO(), lowRem)).To(Succeed())
+		reconcilePlan()
+		Expect(getRemediation("high-rem").Spec.Apply).To(BeTrue())
+
+		found := reconcilePlan()
+		found.Spec.Apply = false
+		Expect(reconciler.Client.Update(context.TODO(), found)).To(Succeed())
+
+		found = reconcilePlan()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanPending))
+		highRem := getRemediation("high-rem")
+		Expect(highRem.Spec.Apply).To(BeFalse())
+		Expect(highRem.Labels).ToNot(HaveKey(compv1alpha1.RemediationPlanLabel))
+		Expect(getRemediation("low-rem").Spec.Apply).To(BeTrue())
+	})
+
+	Context("with a pre-apply hook", func() {
+		BeforeEach(func() {
+			plan.Spec.PreApplyHook = &compv1alpha1.RemediationPlanHook{CronJobName: "etcd-backup"}
+			Expect(reconciler.Client.Update(context.TODO(), plan)).To(Succeed())
+		})
+
+		getHookJob := func(name string) *batchv1.Job {
+			job := &batchv1.Job{}
+			err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, job)
+			Expect(err).To(BeNil())
+			return job
+		}
+		setJobCondition := func(job *batchv1.Job, conditionType batchv1.JobConditionType) {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+			Expect(reconciler.Client.Status().Update(context.TODO(), job)).To(Succeed())
+		}
+
+		Context("with the CronJob of the hook", func() {
+			BeforeEach(func() {
+				suspend := true
+				cronJob := &batchv1.CronJob{
+					ObjectMeta: metav1.ObjectMeta{Name: "etcd-backup", Namespace: namespace},
+					Spec: batchv1.CronJobSpec{
+						Schedule: "@yearly",
+						Suspend:  &suspend,
+						JobTemplate: batchv1.JobTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "etcd-backup"}},
+							Spec: batchv1.JobSpec{
+								Template: corev1.PodTemplateSpec{
+									Spec: corev1.PodSpec{
+										Containers: []corev1.Container{{Name: "backup", Image: "backup-image"}},
+									},
+								},
+							},
+						},
+					},
+				}
+				Expect(reconciler.Client.Create(context.TODO(), cronJob)).To(Succeed())
+			})
+
+			It("should only apply each step once its hook completed", func() {
+				By("running the hook before the first step")
+				found := reconcilePlan()
+				Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanApplying))
+				Expect(found.Status.HookJob).ToNot(BeEmpty())
+				job := getHookJob(found.Status.HookJob)
+				Expect(job.Labels).To(HaveKeyWithValue("app", "etcd-backup"))
+				Expect(job.Labels).To(HaveKeyWithValue(compv1alpha1.RemediationPlanLabel, "my-plan"))
+				Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("backup-image"))
+				Expect(job.OwnerReferences).To(HaveLen(1))
+				Expect(getRemediation("high-rem").Spec.Apply).To(BeFalse())
+
+				By("applying the step once the hook completed")
+				setJobCondition(job, batchv1.JobComplete)
+				reconcilePlan()
+				Expect(getRemediation("high-rem").Spec.Apply).To(BeTrue())
+				Expect(getRemediation("mc-rem").Spec.Apply).To(BeTrue())
+
+				By("running the hook again before the next step")
+				setApplicationState("high-rem", compv1alpha1.RemediationApplied)
+				setApplicationState("mc-rem", compv1alpha1.RemediationApplied)
+				next := reconcilePlan()
+				Expect(next.Status.HookJob).ToNot(Equal(found.Status.HookJob))
+				getHookJob(next.Status.HookJob)
+				Expect(getRemediation("low-rem").Spec.Apply).To(BeFalse())
+			})
+
+			It("should not apply the step if the hook failed", func() {
+				found := reconcilePlan()
+				setJobCondition(getHookJob(found.Status.HookJob), batchv1.JobFailed)
+
+				found = reconcilePlan()
+				Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanError))
+				Expect(found.Status.ErrorMessage).To(ContainSubstring("failed"))
+				Expect(getRemediation("high-rem").Spec.Apply).To(BeFalse())
+			})
+		})
+
+		It("should report a missing CronJob", func() {
+			found := reconcilePlan()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanError))
+			Expect(found.Status.ErrorMessage).To(ContainSubstring("etcd-backup"))
+			Expect(getRemediation("high-rem").Spec.Apply).To(BeFalse())
+		})
+	})
+
+	It("should report an invalid selector", func() {
+		plan.Spec.Selector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "foo", Operator: "Bogus"}},
+		}
+		Expect(reconciler.Client.Update(context.TODO(), plan)).To(Succeed())
+
+		found := reconcilePlan()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.RemediationPlanError))
+		Expect(found.Status.ErrorMessage).To(ContainSubstring("invalid selector"))
+	})
+})
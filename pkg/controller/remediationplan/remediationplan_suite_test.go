@@ -0,0 +1,13 @@
+package remediationplan
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestRemediationplan(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Remediationplan Suite")
+}
@@ -0,0 +1,175 @@
+package remediationplan
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-logr/logr"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// The order in which the severities are applied when a plan applies its
+// remediations by severity
+var severityOrder = []compv1alpha1.ComplianceCheckResultSeverity{
+	compv1alpha1.CheckResultSeverityHigh,
+	compv1alpha1.CheckResultSeverityMedium,
+	compv1alpha1.CheckResultSeverityLow,
+	compv1alpha1.CheckResultSeverityInfo,
+	compv1alpha1.CheckResultSeverityUnknown,
+}
+
+// A remediation that belongs to a plan along with the severity of the check
+// it was generated from
+type planRemediation struct {
+	rem      *compv1alpha1.ComplianceRemediation
+	severity compv1alpha1.ComplianceCheckResultSeverity
+}
+
+// Lists the remediations that belong to the plan, sorted by name
+func (r *ReconcileRemediationPlan) getPlanRemediations(plan *compv1alpha1.RemediationPlan, logger logr.Logger) ([]planRemediation, error) {
+	selector := labels.Everything()
+	if plan.Spec.Selector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(plan.Spec.Selector)
+		if err != nil {
+			return nil, common.NewNonRetriableCtrlError("invalid selector of the plan: %s", err)
+		}
+	}
+	remList := &compv1alpha1.ComplianceRemediationList{}
+	err := r.Client.List(context.TODO(), remList, client.InNamespace(plan.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	var mcfgpools *mcfgv1.MachineConfigPoolList
+	scans := map[string]*compv1alpha1.ComplianceScan{}
+	var rems []planRemediation
+	for i := range remList.Items {
+		rem := &remList.Items[i]
+		if len(plan.Spec.Pools) > 0 && (utils.IsMachineConfig(rem.Spec.Current.Object) || utils.IsKubeletConfig(rem.Spec.Current.Object)) {
+			if mcfgpools == nil {
+				mcfgpools = &mcfgv1.MachineConfigPoolList{}
+				if err := r.Client.List(context.TODO(), mcfgpools); err != nil {
+					return nil, err
+				}
+			}
+			inPools, err := r.isInPlanPools(plan, rem, scans, mcfgpools)
+			if err != nil {
+				return nil, err
+			}
+			if !inPools {
+				continue
+			}
+		}
+
+		check, err := r.getRemediationCheck(rem)
+		if err != nil {
+			return nil, err
+		}
+		prem := planRemediation{rem: rem, severity: compv1alpha1.CheckResultSeverityUnknown}
+		for _, severity := range severityOrder {
+			if check != nil && check.Severity == severity {
+				prem.severity = severity
+			}
+		}
+		if plan.Spec.Filter != nil {
+			if check == nil {
+				logger.Info("Cannot find the check of the remediation to filter it", "ComplianceRemediation.Name", rem.Name)
+				continue
+			}
+			if !plan.Spec.Filter.Allows(check.Severity, check.Annotations[compv1alpha1.ComplianceCheckResultRuleAnnotation]) {
+				continue
+			}
+		}
+		rems = append(rems, prem)
+	}
+
+	sort.Slice(rems, func(i, j int) bool {
+		return rems[i].rem.Name < rems[j].rem.Name
+	})
+	return rems, nil
+}
+
+// Tells whether the pool of the MachineConfig or KubeletConfig remediation is
+// one of the pools of the plan. Remediations whose scan doesn't match any
+// pool aren't applied per pool, so they aren't restricted.
+func (r *ReconcileRemediationPlan) isInPlanPools(plan *compv1alpha1.RemediationPlan, rem *compv1alpha1.ComplianceRemediation,
+	scans map[string]*compv1alpha1.ComplianceScan, mcfgpools *mcfgv1.MachineConfigPoolList) (bool, error) {
+	scan, ok := scans[rem.GetScan()]
+	if !ok {
+		scan = &compv1alpha1.ComplianceScan{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: rem.GetScan(), Namespace: rem.Namespace}, scan)
+		if kerrors.IsNotFound(err) {
+			scan = nil
+		} else if err != nil {
+			return false, err
+		}
+		scans[rem.GetScan()] = scan
+	}
+	if scan == nil {
+		return true, nil
+	}
+	found, pool := utils.AnyMcfgPoolLabelMatches(scan.Spec.NodeSelector, mcfgpools)
+	if !found {
+		return true, nil
+	}
+	for _, poolName := range plan.Spec.Pools {
+		if poolName == pool.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Returns the check the remediation was generated from, or nil if it
+// doesn't exist
+func (r *ReconcileRemediationPlan) getRemediationCheck(rem *compv1alpha1.ComplianceRemediation) (*compv1alpha1.ComplianceCheckResult, error) {
+	// Remediations are owned by the check they were generated from
+	checkName := rem.Name
+	if owner := metav1.GetControllerOf(rem); owner != nil && owner.Kind == "ComplianceCheckResult" {
+		checkName = owner.Name
+	}
+	check := &compv1alpha1.ComplianceCheckResult{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: checkName, Namespace: rem.Namespace}, check)
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// Splits the remediations of the plan into the steps they are applied in.
+// All the remediations are applied in a single step unless the plan applies
+// them by severity.
+func getPlanSteps(plan *compv1alpha1.RemediationPlan, rems []planRemediation) [][]planRemediation {
+	if len(rems) == 0 {
+		return nil
+	}
+	if plan.Spec.Order != compv1alpha1.RemediationPlanOrderSeverity {
+		return [][]planRemediation{rems}
+	}
+
+	var steps [][]planRemediation
+	for _, severity := range severityOrder {
+		var step []planRemediation
+		for _, prem := range rems {
+			if prem.severity == severity {
+				step = append(step, prem)
+			}
+		}
+		if len(step) > 0 {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
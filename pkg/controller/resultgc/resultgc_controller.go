@@ -0,0 +1,361 @@
+package resultgc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("resultgcctrl")
+
+// How often the results of the suites are checked against their retention
+// policies
+const pruneInterval = time.Hour
+
+// Add creates a new result garbage collection Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, _ *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileResultGC{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("resultgcctrl"),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// The suites are pruned periodically, so only the changes of their
+	// retention policies need to be reconciled right away
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("resultgc-controller").
+		For(&compv1alpha1.ComplianceSuite{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileResultGC implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileResultGC{}
+
+// ReconcileResultGC prunes the results of a ComplianceSuite according to its
+// retention policy
+type ReconcileResultGC struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// pruneCounts holds how many of each kind of result were pruned
+type pruneCounts struct {
+	checkResults int
+	remediations int
+	rawResults   int
+	rerunnerJobs int
+}
+
+func (c *pruneCounts) total() int {
+	return c.checkResults + c.remediations + c.rawResults + c.rerunnerJobs
+}
+
+// Reconcile prunes the check results, the obsolete remediations, the raw
+// results and the completed rerunner jobs of a ComplianceSuite that its
+// retention policy no longer keeps, and checks them again periodically.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileResultGC) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling the retention of the results of the ComplianceSuite")
+
+	// Fetch the ComplianceSuite instance
+	suite := &compv1alpha1.ComplianceSuite{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, suite)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	policy := suite.Spec.ResultRetention
+	if !suite.GetDeletionTimestamp().IsZero() || !policy.IsEnabled() {
+		return reconcile.Result{}, nil
+	}
+
+	now := time.Now()
+	counts := &pruneCounts{}
+	if policy.MaxCheckResultAge != nil {
+		if counts.checkResults, err = r.pruneCheckResults(suite, now.Add(-policy.GetMaxCheckResultAge()), reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if policy.PruneObsoleteRemediations {
+		if counts.remediations, err = r.pruneObsoleteRemediations(suite, reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if policy.MaxRawResultAge != nil {
+		if counts.rawResults, err = r.pruneRawResults(suite, now.Add(-policy.GetMaxRawResultAge()), reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if policy.MaxCompletedRerunnerJobs != nil {
+		if counts.rerunnerJobs, err = r.pruneRerunnerJobs(suite, int(*policy.MaxCompletedRerunnerJobs), reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if counts.total() > 0 && r.Recorder != nil {
+		r.Recorder.Eventf(suite, corev1.EventTypeNormal, "ResultsPruned",
+			"Pruned %d check results, %d remediations, %d raw results and %d rerunner jobs",
+			counts.checkResults, counts.remediations, counts.rawResults, counts.rerunnerJobs)
+	}
+	return reconcile.Result{RequeueAfter: pruneInterval}, nil
+}
+
+// pruneCheckResults deletes the check results of the suite that were last
+// reported before the given time, along with those of scans that are no
+// longer part of the suite. The remediations of the deleted results are
+// garbage collected along with them, so the results whose remediation is
+// applied, or set to be applied, are kept: the objects of the remediation
+// would be left behind with no remediation to un-apply them.
+func (r *ReconcileResultGC) pruneCheckResults(suite *compv1alpha1.ComplianceSuite, reportedBefore time.Time, logger logr.Logger) (int, error) {
+	results := &compv1alpha1.ComplianceCheckResultList{}
+	err := r.Client.List(context.TODO(), results,
+		client.InNamespace(suite.Namespace),
+		client.MatchingLabels{compv1alpha1.SuiteLabel: suite.Name})
+	if err != nil {
+		return 0, fmt.Errorf("cannot list the check results of the suite: %w", err)
+	}
+	withAppliedRemediation, err := r.getResultsWithAppliedRemediations(suite)
+	if err != nil {
+		return 0, err
+	}
+
+	scans := make(map[string]bool, len(suite.Spec.Scans))
+	for _, scan := range suite.Spec.Scans {
+		scans[scan.Name] = true
+	}
+
+	pruned := 0
+	for i := range results.Items {
+		result := &results.Items[i]
+		if scans[result.Labels[compv1alpha1.ComplianceScanLabel]] && !result.GetLastReported().Before(reportedBefore) {
+			continue
+		}
+		if withAppliedRemediation[result.Name] {
+			logger.Info("Not pruning check result whose remediation is applied", "ComplianceCheckResult.Name", result.Name)
+			continue
+		}
+		logger.Info("Pruning check result", "ComplianceCheckResult.Name", result.Name)
+		if err := r.Client.Delete(context.TODO(), result); client.IgnoreNotFound(err) != nil {
+			return pruned, fmt.Errorf("cannot delete check result %s: %w", result.Name, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// getResultsWithAppliedRemediations returns the names of the check results of
+// the suite that own a remediation that is applied or set to be applied
+func (r *ReconcileResultGC) getResultsWithAppliedRemediations(suite *compv1alpha1.ComplianceSuite) (map[string]bool, error) {
+	remediations := &compv1alpha1.ComplianceRemediationList{}
+	err := r.Client.List(context.TODO(), remediations,
+		client.InNamespace(suite.Namespace),
+		client.MatchingLabels{compv1alpha1.SuiteLabel: suite.Name})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the remediations of the suite: %w", err)
+	}
+
+	owners := make(map[string]bool)
+	for i := range remediations.Items {
+		rem := &remediations.Items[i]
+		if !rem.Spec.Apply && !rem.IsApplied() {
+			continue
+		}
+		owner := metav1.GetControllerOf(rem)
+		if owner != nil && owner.Kind == "ComplianceCheckResult" {
+			owners[owner.Name] = true
+		}
+	}
+	return owners, nil
+}
+
+// pruneObsoleteRemediations deletes the remediations of the suite that are
+// obsolete and aren't applied, as they're only kept to be reviewed
+func (r *ReconcileResultGC) pruneObsoleteRemediations(suite *compv1alpha1.ComplianceSuite, logger logr.Logger) (int, error) {
+	remediations := &compv1alpha1.ComplianceRemediationList{}
+	err := r.Client.List(context.TODO(), remediations,
+		client.InNamespace(suite.Namespace),
+		client.MatchingLabels{compv1alpha1.SuiteLabel: suite.Name},
+		client.HasLabels{compv1alpha1.ObsoleteRemediationLabel})
+	if err != nil {
+		return 0, fmt.Errorf("cannot list the obsolete remediations of the suite: %w", err)
+	}
+
+	pruned := 0
+	for i := range remediations.Items {
+		rem := &remediations.Items[i]
+		if rem.Spec.Apply || rem.IsApplied() {
+			continue
+		}
+		logger.Info("Pruning obsolete remediation", "ComplianceRemediation.Name", rem.Name)
+		if err := r.Client.Delete(context.TODO(), rem); client.IgnoreNotFound(err) != nil {
+			return pruned, fmt.Errorf("cannot delete remediation %s: %w", rem.Name, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// pruneRawResults deletes the raw results of the nodes of the scans of the
+// suite that were done before the given time. The check results were
+// already created out of them, they're only needed to rescan a single node
+// or to run an incremental scan, so the scans are marked for their next run
+// to be a full scan.
+func (r *ReconcileResultGC) pruneRawResults(suite *compv1alpha1.ComplianceSuite, doneBefore time.Time, logger logr.Logger) (int, error) {
+	pruned := 0
+	for _, scanWrap := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: scanWrap.Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); kerrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return pruned, err
+		}
+		if scan.Status.Phase != compv1alpha1.PhaseDone || scan.Status.EndTimestamp == nil ||
+			!scan.Status.EndTimestamp.Time.Before(doneBefore) {
+			continue
+		}
+
+		cms := &corev1.ConfigMapList{}
+		err := r.Client.List(context.TODO(), cms,
+			client.InNamespace(common.GetComplianceOperatorNamespace()),
+			client.MatchingLabels{
+				compv1alpha1.ComplianceScanLabel: scan.Name,
+				compv1alpha1.ResultLabel:         "",
+			})
+		if err != nil {
+			return pruned, fmt.Errorf("cannot list the raw results of scan %s: %w", scan.Name, err)
+		}
+		if len(cms.Items) == 0 {
+			continue
+		}
+		// The scan is marked first, so that it doesn't rely on the raw
+		// results if deleting them fails half-way
+		if !scan.RawResultsWerePruned() {
+			scanCopy := scan.DeepCopy()
+			if scanCopy.Annotations == nil {
+				scanCopy.Annotations = make(map[string]string)
+			}
+			scanCopy.Annotations[compv1alpha1.ComplianceScanRawResultsPrunedAnnotation] = ""
+			if err := r.Client.Update(context.TODO(), scanCopy); err != nil {
+				return pruned, fmt.Errorf("cannot mark the raw results of scan %s as pruned: %w", scan.Name, err)
+			}
+		}
+		for i := range cms.Items {
+			cm := &cms.Items[i]
+			logger.Info("Pruning raw result", "ComplianceScan.Name", scan.Name, "ConfigMap.Name", cm.Name)
+			if err := r.Client.Delete(context.TODO(), cm); client.IgnoreNotFound(err) != nil {
+				return pruned, fmt.Errorf("cannot delete raw result %s: %w", cm.Name, err)
+			}
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// pruneRerunnerJobs deletes the completed jobs of the rerunners of the suite
+// but the given number of the most recently completed ones
+func (r *ReconcileResultGC) pruneRerunnerJobs(suite *compv1alpha1.ComplianceSuite, keep int, logger logr.Logger) (int, error) {
+	jobs := &batchv1.JobList{}
+	err := r.Client.List(context.TODO(), jobs,
+		client.InNamespace(common.GetComplianceOperatorNamespace()),
+		client.MatchingLabels{
+			compv1alpha1.SuiteLabel:       suite.Name,
+			compv1alpha1.SuiteScriptLabel: "",
+		})
+	if err != nil {
+		return 0, fmt.Errorf("cannot list the rerunner jobs of the suite: %w", err)
+	}
+
+	completed := make([]*batchv1.Job, 0, len(jobs.Items))
+	for i := range jobs.Items {
+		if isJobFinished(&jobs.Items[i]) {
+			completed = append(completed, &jobs.Items[i])
+		}
+	}
+	if len(completed) <= keep {
+		return 0, nil
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return getJobCompletionTime(completed[j]).Before(getJobCompletionTime(completed[i]))
+	})
+
+	pruned := 0
+	background := metav1.DeletePropagationBackground
+	for _, job := range completed[keep:] {
+		logger.Info("Pruning rerunner job", "Job.Name", job.Name)
+		err := r.Client.Delete(context.TODO(), job, &client.DeleteOptions{PropagationPolicy: &background})
+		if client.IgnoreNotFound(err) != nil {
+			return pruned, fmt.Errorf("cannot delete rerunner job %s: %w", job.Name, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// isJobFinished tells whether the job either succeeded or failed
+func isJobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// getJobCompletionTime returns when the job finished, failed jobs have no
+// completion time so the transition of their condition is used instead
+func getJobCompletionTime(job *batchv1.Job) time.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time
+		}
+	}
+	return job.CreationTimestamp.Time
+}
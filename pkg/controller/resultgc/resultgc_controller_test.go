@@ -0,0 +1,261 @@
+package resultgc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Testing the result garbage collection controller", func() {
+	var (
+		namespace  = common.GetComplianceOperatorNamespace()
+		suite      *compv1alpha1.ComplianceSuite
+		scan       *compv1alpha1.ComplianceScan
+		objects    []client.Object
+		reconciler *ReconcileResultGC
+		recorder   *record.FakeRecorder
+	)
+
+	ago := func(d time.Duration) metav1.Time {
+		return metav1.NewTime(time.Now().Add(-d))
+	}
+	newCheckResult := func(name, scanName string, lastReported metav1.Time) *compv1alpha1.ComplianceCheckResult {
+		return &compv1alpha1.ComplianceCheckResult{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					compv1alpha1.SuiteLabel:          suite.Name,
+					compv1alpha1.ComplianceScanLabel: scanName,
+				},
+				Annotations: map[string]string{
+					compv1alpha1.ComplianceCheckResultLastReportedAnnotation: lastReported.UTC().Format(time.RFC3339),
+				},
+			},
+		}
+	}
+	newRemediation := func(name string, obsolete bool, state compv1alpha1.RemediationApplicationState) *compv1alpha1.ComplianceRemediation {
+		rem := &compv1alpha1.ComplianceRemediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{compv1alpha1.SuiteLabel: suite.Name},
+			},
+			Status: compv1alpha1.ComplianceRemediationStatus{ApplicationState: state},
+		}
+		if obsolete {
+			rem.Labels[compv1alpha1.ObsoleteRemediationLabel] = ""
+		}
+		return rem
+	}
+	newRawResult := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					compv1alpha1.ComplianceScanLabel: scan.Name,
+					compv1alpha1.ResultLabel:         "",
+				},
+			},
+		}
+	}
+	newRerunnerJob := func(name string, completed *metav1.Time) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					compv1alpha1.SuiteLabel:       suite.Name,
+					compv1alpha1.SuiteScriptLabel: "",
+				},
+			},
+		}
+		if completed != nil {
+			job.Status.CompletionTime = completed
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: *completed},
+			}
+		}
+		return job
+	}
+
+	reconcileSuite := func() reconcile.Result {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		client := fake.NewClientBuilder().
+			WithScheme(cscheme).
+			WithObjects(append(objects, suite, scan)...).
+			WithStatusSubresource(suite, scan).
+			Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ReconcileResultGC{Client: client, Scheme: cscheme, Recorder: recorder}
+		key := types.NamespacedName{Name: suite.Name, Namespace: namespace}
+		res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		Expect(err).To(BeNil())
+		return res
+	}
+	names := func(list client.ObjectList) []string {
+		Expect(reconciler.Client.List(context.TODO(), list, client.InNamespace(namespace))).To(Succeed())
+		var found []string
+		switch l := list.(type) {
+		case *compv1alpha1.ComplianceCheckResultList:
+			for _, i := range l.Items {
+				found = append(found, i.Name)
+			}
+		case *compv1alpha1.ComplianceRemediationList:
+			for _, i := range l.Items {
+				found = append(found, i.Name)
+			}
+		case *corev1.ConfigMapList:
+			for _, i := range l.Items {
+				found = append(found, i.Name)
+			}
+		case *batchv1.JobList:
+			for _, i := range l.Items {
+				found = append(found, i.Name)
+			}
+		}
+		return found
+	}
+
+	BeforeEach(func() {
+		suite = &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{Name: "cis", Namespace: namespace},
+			Spec: compv1alpha1.ComplianceSuiteSpec{
+				Scans: []compv1alpha1.ComplianceScanSpecWrapper{{Name: "ocp4-cis"}},
+			},
+		}
+		end := ago(3 * time.Hour)
+		scan = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-cis", Namespace: namespace},
+			Status: compv1alpha1.ComplianceScanStatus{
+				Phase:        compv1alpha1.PhaseDone,
+				EndTimestamp: &end,
+			},
+		}
+		objects = nil
+	})
+
+	It("doesn't prune anything without a retention policy", func() {
+		objects = []client.Object{newCheckResult("ocp4-cis-old", "ocp4-moderate", ago(1000*time.Hour))}
+		res := reconcileSuite()
+		Expect(res.RequeueAfter).To(BeZero())
+		Expect(names(&compv1alpha1.ComplianceCheckResultList{})).To(ConsistOf("ocp4-cis-old"))
+	})
+
+	It("prunes the check results that are no longer reported", func() {
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{
+			MaxCheckResultAge: &metav1.Duration{Duration: 24 * time.Hour},
+		}
+		objects = []client.Object{
+			newCheckResult("ocp4-cis-current", "ocp4-cis", ago(time.Hour)),
+			newCheckResult("ocp4-cis-old", "ocp4-cis", ago(48*time.Hour)),
+			newCheckResult("ocp4-moderate-removed", "ocp4-moderate", ago(time.Hour)),
+		}
+		res := reconcileSuite()
+		Expect(res.RequeueAfter).To(Equal(pruneInterval))
+		Expect(names(&compv1alpha1.ComplianceCheckResultList{})).To(ConsistOf("ocp4-cis-current"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Pruned 2 check results, 0 remediations")))
+	})
+
+	It("keeps the check results whose remediation is applied or set to be applied", func() {
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{
+			MaxCheckResultAge: &metav1.Duration{Duration: 24 * time.Hour},
+		}
+		ownedBy := func(rem *compv1alpha1.ComplianceRemediation, result string) *compv1alpha1.ComplianceRemediation {
+			controller := true
+			rem.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: compv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "ComplianceCheckResult",
+				Name:       result,
+				Controller: &controller,
+			}}
+			return rem
+		}
+		toApply := ownedBy(newRemediation("ocp4-cis-to-apply", false, compv1alpha1.RemediationNotApplied), "ocp4-cis-to-apply")
+		toApply.Spec.Apply = true
+		objects = []client.Object{
+			newCheckResult("ocp4-cis-applied", "ocp4-cis", ago(48*time.Hour)),
+			newCheckResult("ocp4-cis-to-apply", "ocp4-cis", ago(48*time.Hour)),
+			newCheckResult("ocp4-cis-not-applied", "ocp4-cis", ago(48*time.Hour)),
+			ownedBy(newRemediation("ocp4-cis-applied", false, compv1alpha1.RemediationApplied), "ocp4-cis-applied"),
+			toApply,
+			ownedBy(newRemediation("ocp4-cis-not-applied", false, compv1alpha1.RemediationNotApplied), "ocp4-cis-not-applied"),
+		}
+		reconcileSuite()
+		Expect(names(&compv1alpha1.ComplianceCheckResultList{})).To(ConsistOf("ocp4-cis-applied", "ocp4-cis-to-apply"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Pruned 1 check results")))
+	})
+
+	It("prunes the obsolete remediations that aren't applied", func() {
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{PruneObsoleteRemediations: true}
+		objects = []client.Object{
+			newRemediation("current", false, compv1alpha1.RemediationNotApplied),
+			newRemediation("obsolete", true, compv1alpha1.RemediationNotApplied),
+			newRemediation("obsolete-applied", true, compv1alpha1.RemediationApplied),
+		}
+		reconcileSuite()
+		Expect(names(&compv1alpha1.ComplianceRemediationList{})).To(ConsistOf("current", "obsolete-applied"))
+	})
+
+	It("prunes the raw results of scans that are done for long enough", func() {
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{
+			MaxRawResultAge: &metav1.Duration{Duration: 4 * time.Hour},
+		}
+		objects = []client.Object{newRawResult("ocp4-cis-api-checks-pod")}
+		reconcileSuite()
+		Expect(names(&corev1.ConfigMapList{})).To(ConsistOf("ocp4-cis-api-checks-pod"))
+
+		By("shortening the retention")
+		suite.Spec.ResultRetention.MaxRawResultAge.Duration = 2 * time.Hour
+		reconcileSuite()
+		Expect(names(&corev1.ConfigMapList{})).To(BeEmpty())
+
+		// The next run of the scan doesn't rely on the pruned raw results
+		found := &compv1alpha1.ComplianceScan{}
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(scan), found)).To(Succeed())
+		Expect(found.RawResultsWerePruned()).To(BeTrue())
+	})
+
+	It("keeps the raw results of running scans", func() {
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{
+			MaxRawResultAge: &metav1.Duration{Duration: time.Hour},
+		}
+		scan.Status.Phase = compv1alpha1.PhaseAggregating
+		objects = []client.Object{newRawResult("ocp4-cis-api-checks-pod")}
+		reconcileSuite()
+		Expect(names(&corev1.ConfigMapList{})).To(ConsistOf("ocp4-cis-api-checks-pod"))
+
+		found := &compv1alpha1.ComplianceScan{}
+		Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(scan), found)).To(Succeed())
+		Expect(found.RawResultsWerePruned()).To(BeFalse())
+	})
+
+	It("keeps the most recently completed rerunner jobs", func() {
+		var keep int32 = 1
+		suite.Spec.ResultRetention = &compv1alpha1.ResultRetentionPolicy{MaxCompletedRerunnerJobs: &keep}
+		older, newer := ago(2*time.Hour), ago(time.Hour)
+		objects = []client.Object{
+			newRerunnerJob("cis-rerunner-1", &older),
+			newRerunnerJob("cis-rerunner-2", &newer),
+			newRerunnerJob("cis-rerunner-3", nil),
+		}
+		reconcileSuite()
+		Expect(names(&batchv1.JobList{})).To(ConsistOf("cis-rerunner-2", "cis-rerunner-3"))
+	})
+})
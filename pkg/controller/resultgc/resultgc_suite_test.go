@@ -0,0 +1,13 @@
+package resultgc
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestResultgc(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Resultgc Suite")
+}
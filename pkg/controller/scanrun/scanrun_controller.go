@@ -0,0 +1,251 @@
+package scanrun
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/metrics"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var log = logf.Log.WithName("scanrunctrl")
+
+// How long to wait for the suite of the binding and its scans to be created
+const suiteWaitInterval = 10 * time.Second
+
+// Add creates a new ScanRun Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, _ *metrics.Metrics, _ utils.CtlplaneSchedulingInfo, _ *kubernetes.Clientset) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileScanRun{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	mapper := &suiteMapper{mgr.GetClient()}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("scanrun-controller").
+		For(&compv1alpha1.ScanRun{}).
+		Watches(&compv1alpha1.ComplianceSuite{}, handler.EnqueueRequestsFromMapFunc(mapper.Map)).
+		Watches(&compv1alpha1.ComplianceScan{}, handler.EnqueueRequestsFromMapFunc(mapper.Map)).
+		Complete(r)
+}
+
+// blank assignment to verify that ReconcileScanRun implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileScanRun{}
+
+// ReconcileScanRun reconciles a ScanRun object
+type ReconcileScanRun struct {
+	// This Client, initialized using mgr.Client() above, is a split Client
+	// that reads objects from the cache and writes to the apiserver
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile re-runs the scans of the binding of a ScanRun once, and then
+// follows them until they are done with that run
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileScanRun) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling ScanRun")
+
+	// Fetch the ScanRun instance
+	instance := &compv1alpha1.ScanRun{}
+	err := r.Client.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// The scans that were re-run keep running.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+	if instance.IsFinished() {
+		return reconcile.Result{}, nil
+	}
+
+	status := instance.Status.DeepCopy()
+	if status.Requester == "" {
+		status.Requester = getScanRunRequester(instance)
+	}
+
+	binding := &compv1alpha1.ScanSettingBinding{}
+	bindingKey := types.NamespacedName{Name: instance.Spec.ScanSettingBindingName, Namespace: instance.Namespace}
+	if err := r.Client.Get(context.TODO(), bindingKey, binding); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		status.Phase = compv1alpha1.ScanRunError
+		status.ErrorMessage = fmt.Sprintf("ScanSettingBinding '%s' not found", bindingKey.Name)
+		return reconcile.Result{}, r.updateScanRunStatus(instance, status, reqLogger)
+	}
+
+	// The suite of a binding has the name of the binding
+	suite := &compv1alpha1.ComplianceSuite{}
+	if err := r.Client.Get(context.TODO(), bindingKey, suite); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		reqLogger.Info("Waiting for the suite of the binding to be created")
+		status.Phase = compv1alpha1.ScanRunPending
+		return reconcile.Result{RequeueAfter: suiteWaitInterval}, r.updateScanRunStatus(instance, status, reqLogger)
+	}
+	status.SuiteName = suite.Name
+
+	if status.StartTimestamp == nil {
+		return r.startScanRun(instance, suite, status, reqLogger)
+	}
+	if err := r.rerunScans(suite, status, reqLogger); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.updateScanRunStatus(instance, status, reqLogger)
+}
+
+// startScanRun records the index each scan of the suite is at, so that the
+// run the ScanRun triggers can be told apart from the previous ones. The scans
+// are only re-run once the indexes are stored, so that the run isn't
+// triggered twice if storing them fails.
+func (r *ReconcileScanRun) startScanRun(instance *compv1alpha1.ScanRun, suite *compv1alpha1.ComplianceSuite,
+	status *compv1alpha1.ScanRunStatus, logger logr.Logger) (reconcile.Result, error) {
+	scans := make([]compv1alpha1.ScanRunScanStatus, 0, len(suite.Spec.Scans))
+	for _, scanWrap := range suite.Spec.Scans {
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: scanWrap.Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); err != nil {
+			if !kerrors.IsNotFound(err) {
+				return reconcile.Result{}, err
+			}
+			logger.Info("Waiting for the scans of the suite to be created", "ComplianceScan.Name", scanWrap.Name)
+			status.Phase = compv1alpha1.ScanRunPending
+			return reconcile.Result{RequeueAfter: suiteWaitInterval}, r.updateScanRunStatus(instance, status, logger)
+		}
+		scans = append(scans, compv1alpha1.ScanRunScanStatus{
+			Name:  scan.Name,
+			Index: scan.Status.CurrentIndex,
+		})
+	}
+
+	now := metav1.Now()
+	status.Phase = compv1alpha1.ScanRunRunning
+	status.Scans = scans
+	status.StartTimestamp = &now
+	return reconcile.Result{}, r.updateScanRunStatus(instance, status, logger)
+}
+
+// rerunScans re-runs the scans of the ScanRun that weren't re-run yet, and
+// records the results of those that are done with the run. The ScanRun is
+// done once all of them are. The scans are re-run with the same annotations
+// as any other re-run, so the run is told apart by the index of the scans
+// rather than by how it was triggered.
+func (r *ReconcileScanRun) rerunScans(suite *compv1alpha1.ComplianceSuite, status *compv1alpha1.ScanRunStatus, logger logr.Logger) error {
+	// Just like with scheduled re-runs, the scans of serially executed
+	// suites are only marked for re-running and get started one after
+	// another
+	rescanAnnotation := compv1alpha1.ComplianceScanRescanAnnotation
+	if suite.RunsScansSerially() {
+		rescanAnnotation = compv1alpha1.ComplianceScanPendingRescanAnnotation
+	}
+
+	done := true
+	for i := range status.Scans {
+		scanStatus := &status.Scans[i]
+		if scanStatus.Result != "" {
+			continue
+		}
+		scan := &compv1alpha1.ComplianceScan{}
+		key := types.NamespacedName{Name: scanStatus.Name, Namespace: suite.Namespace}
+		if err := r.Client.Get(context.TODO(), key, scan); err != nil {
+			if !kerrors.IsNotFound(err) {
+				return err
+			}
+			status.Phase = compv1alpha1.ScanRunError
+			status.ErrorMessage = fmt.Sprintf("ComplianceScan '%s' was deleted before it was done", scanStatus.Name)
+			return nil
+		}
+
+		if scan.Status.CurrentIndex > scanStatus.Index {
+			if scan.Status.Phase == compv1alpha1.PhaseDone {
+				scanStatus.Result = scan.Status.Result
+			} else {
+				done = false
+			}
+			continue
+		}
+		done = false
+		// A scan whose index didn't increase yet still needs to be re-run,
+		// unless it's already marked for re-running once it's done
+		if !scanIsMarkedForRescan(scan) {
+			scanCopy := scan.DeepCopy()
+			if scanCopy.Annotations == nil {
+				scanCopy.Annotations = make(map[string]string)
+			}
+			scanCopy.Annotations[rescanAnnotation] = ""
+			logger.Info("Re-running scan", "ComplianceScan.Name", scan.Name)
+			if err := r.Client.Update(context.TODO(), scanCopy); err != nil {
+				return err
+			}
+		}
+	}
+
+	if done {
+		now := metav1.Now()
+		status.Phase = compv1alpha1.ScanRunDone
+		status.Result = status.AggregateResult()
+		status.CompletionTimestamp = &now
+	}
+	return nil
+}
+
+func scanIsMarkedForRescan(scan *compv1alpha1.ComplianceScan) bool {
+	_, rescan := scan.Annotations[compv1alpha1.ComplianceScanRescanAnnotation]
+	_, pending := scan.Annotations[compv1alpha1.ComplianceScanPendingRescanAnnotation]
+	return rescan || pending
+}
+
+// getScanRunRequester returns who created the ScanRun, as set by the webhook.
+// Without the webhook, anyone creating a ScanRun could claim to be someone
+// else, so the requester is unknown.
+func getScanRunRequester(instance *compv1alpha1.ScanRun) string {
+	if !requesterWebhookServed.Load() {
+		return "unknown"
+	}
+	if requester := instance.Annotations[compv1alpha1.ScanRunRequesterAnnotation]; requester != "" {
+		return requester
+	}
+	return "unknown"
+}
+
+func (r *ReconcileScanRun) updateScanRunStatus(instance *compv1alpha1.ScanRun, status *compv1alpha1.ScanRunStatus,
+	logger logr.Logger) error {
+	if equality.Semantic.DeepEqual(&instance.Status, status) {
+		return nil
+	}
+	logger.Info("Updating the status of the ScanRun", "phase", status.Phase)
+	instanceCopy := instance.DeepCopy()
+	instanceCopy.Status = *status
+	return r.Client.Status().Update(context.TODO(), instanceCopy)
+}
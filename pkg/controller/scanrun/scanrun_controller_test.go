@@ -0,0 +1,261 @@
+package scanrun
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Testing scanrun controller", func() {
+	const namespace = "test-ns"
+
+	var (
+		run        *compv1alpha1.ScanRun
+		suite      *compv1alpha1.ComplianceSuite
+		platform   *compv1alpha1.ComplianceScan
+		node       *compv1alpha1.ComplianceScan
+		reconciler *ReconcileScanRun
+	)
+
+	reconcileRun := func() (reconcile.Result, *compv1alpha1.ScanRun) {
+		res, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: run.Name, Namespace: namespace},
+		})
+		Expect(err).To(BeNil())
+		found := &compv1alpha1.ScanRun{}
+		err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: run.Name, Namespace: namespace}, found)
+		Expect(err).To(BeNil())
+		return res, found
+	}
+	getScan := func(name string) *compv1alpha1.ComplianceScan {
+		scan := &compv1alpha1.ComplianceScan{}
+		err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, scan)
+		Expect(err).To(BeNil())
+		return scan
+	}
+	// finishRun simulates the scan controller re-running the scan
+	finishRun := func(name string, result compv1alpha1.ComplianceScanStatusResult) {
+		scan := getScan(name)
+		delete(scan.Annotations, compv1alpha1.ComplianceScanRescanAnnotation)
+		Expect(reconciler.Client.Update(context.TODO(), scan)).To(Succeed())
+		scan.Status.CurrentIndex++
+		scan.Status.Phase = compv1alpha1.PhaseDone
+		scan.Status.Result = result
+		Expect(reconciler.Client.Status().Update(context.TODO(), scan)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		// The requesters are recorded by the webhook
+		requesterWebhookServed.Store(true)
+		run = &compv1alpha1.ScanRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cis-now",
+				Namespace:   namespace,
+				Annotations: map[string]string{compv1alpha1.ScanRunRequesterAnnotation: "alice"},
+			},
+			Spec: compv1alpha1.ScanRunSpec{ScanSettingBindingName: "cis"},
+		}
+		suite = &compv1alpha1.ComplianceSuite{
+			ObjectMeta: metav1.ObjectMeta{Name: "cis", Namespace: namespace},
+			Spec: compv1alpha1.ComplianceSuiteSpec{
+				Scans: []compv1alpha1.ComplianceScanSpecWrapper{
+					{Name: "ocp4-cis"},
+					{Name: "ocp4-cis-node-worker"},
+				},
+			},
+		}
+		platform = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ocp4-cis",
+				Namespace: namespace,
+				Labels:    map[string]string{compv1alpha1.SuiteLabel: "cis"},
+			},
+			Status: compv1alpha1.ComplianceScanStatus{
+				Phase:        compv1alpha1.PhaseDone,
+				Result:       compv1alpha1.ResultNonCompliant,
+				CurrentIndex: 2,
+			},
+		}
+		node = &compv1alpha1.ComplianceScan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ocp4-cis-node-worker",
+				Namespace: namespace,
+				Labels:    map[string]string{compv1alpha1.SuiteLabel: "cis"},
+			},
+			Status: compv1alpha1.ComplianceScanStatus{
+				Phase: compv1alpha1.PhaseRunning,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		client := fake.NewClientBuilder().
+			WithScheme(cscheme).
+			WithStatusSubresource(run, platform, node).
+			WithObjects(run, &compv1alpha1.ScanSettingBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "cis", Namespace: namespace},
+			}, suite, platform, node).
+			Build()
+		reconciler = &ReconcileScanRun{Client: client, Scheme: cscheme}
+	})
+
+	It("re-runs the scans of the binding once and follows them until they are done", func() {
+		_, found := reconcileRun()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunRunning))
+		Expect(found.Status.Requester).To(Equal("alice"))
+		Expect(found.Status.SuiteName).To(Equal("cis"))
+		Expect(found.Status.StartTimestamp).ToNot(BeNil())
+		Expect(found.Status.Scans).To(ConsistOf(
+			compv1alpha1.ScanRunScanStatus{Name: "ocp4-cis", Index: 2},
+			compv1alpha1.ScanRunScanStatus{Name: "ocp4-cis-node-worker", Index: 0},
+		))
+
+		By("Marking the scans for a re-run")
+		reconcileRun()
+		Expect(getScan("ocp4-cis").Annotations).To(HaveKey(compv1alpha1.ComplianceScanRescanAnnotation))
+		Expect(getScan("ocp4-cis-node-worker").Annotations).To(HaveKey(compv1alpha1.ComplianceScanRescanAnnotation))
+
+		By("Waiting for the scans to be done with the new run")
+		finishRun("ocp4-cis", compv1alpha1.ResultCompliant)
+		_, found = reconcileRun()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunRunning))
+		Expect(found.Status.Scans[0].Result).To(Equal(compv1alpha1.ResultCompliant))
+		Expect(getScan("ocp4-cis").Annotations).ToNot(HaveKey(compv1alpha1.ComplianceScanRescanAnnotation))
+
+		scan := getScan("ocp4-cis-node-worker")
+		scan.Status.Phase = compv1alpha1.PhaseDone
+		Expect(reconciler.Client.Status().Update(context.TODO(), scan)).To(Succeed())
+		_, found = reconcileRun()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunRunning))
+
+		finishRun("ocp4-cis-node-worker", compv1alpha1.ResultNonCompliant)
+		_, found = reconcileRun()
+		Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunDone))
+		Expect(found.Status.Result).To(Equal(compv1alpha1.ResultNonCompliant))
+		Expect(found.Status.CompletionTimestamp).ToNot(BeNil())
+
+		By("Not re-running the scans again once done")
+		finishRun("ocp4-cis", compv1alpha1.ResultCompliant)
+		reconcileRun()
+		Expect(getScan("ocp4-cis").Annotations).ToNot(HaveKey(compv1alpha1.ComplianceScanRescanAnnotation))
+	})
+
+	Context("With a serially executed suite", func() {
+		BeforeEach(func() {
+			suite.Spec.ScanExecutionMode = compv1alpha1.ScanExecutionModeSerial
+		})
+
+		It("only marks the scans for re-running", func() {
+			reconcileRun()
+			reconcileRun()
+			Expect(getScan("ocp4-cis").Annotations).To(HaveKey(compv1alpha1.ComplianceScanPendingRescanAnnotation))
+			Expect(getScan("ocp4-cis").Annotations).ToNot(HaveKey(compv1alpha1.ComplianceScanRescanAnnotation))
+		})
+	})
+
+	Context("Without the suite of the binding", func() {
+		BeforeEach(func() {
+			suite.Name = "other"
+		})
+
+		It("waits for the suite to be created", func() {
+			res, found := reconcileRun()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunPending))
+			Expect(res.RequeueAfter).To(Equal(suiteWaitInterval))
+			Expect(getScan("ocp4-cis").Annotations).To(BeEmpty())
+		})
+	})
+
+	Context("Without the binding", func() {
+		BeforeEach(func() {
+			run.Spec.ScanSettingBindingName = "moderate"
+		})
+
+		It("reports an error", func() {
+			_, found := reconcileRun()
+			Expect(found.Status.Phase).To(Equal(compv1alpha1.ScanRunError))
+			Expect(found.Status.ErrorMessage).To(ContainSubstring("moderate"))
+		})
+	})
+})
+
+var _ = Describe("Testing the scanrun requester webhook", func() {
+	newRequest := func(operation admissionv1.Operation, old *compv1alpha1.ScanRun) context.Context {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				UserInfo:  authenticationv1.UserInfo{Username: "kube:admin"},
+			},
+		}
+		if old != nil {
+			raw, err := json.Marshal(old)
+			Expect(err).To(BeNil())
+			req.OldObject.Raw = raw
+		}
+		return admission.NewContextWithRequest(context.TODO(), req)
+	}
+
+	BeforeEach(func() {
+		requesterWebhookServed.Store(true)
+	})
+
+	AfterEach(func() {
+		requesterWebhookServed.Store(false)
+	})
+
+	It("records the user that created the ScanRun", func() {
+		run := &compv1alpha1.ScanRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cis-now",
+				Annotations: map[string]string{compv1alpha1.ScanRunRequesterAnnotation: "someone-else"},
+			},
+		}
+		Expect((&scanRunRequesterDefaulter{}).Default(newRequest(admissionv1.Create, nil), run)).To(Succeed())
+		Expect(run.Annotations).To(HaveKeyWithValue(compv1alpha1.ScanRunRequesterAnnotation, "kube:admin"))
+		Expect(getScanRunRequester(run)).To(Equal("kube:admin"))
+	})
+
+	It("keeps the requester when the ScanRun is updated", func() {
+		old := &compv1alpha1.ScanRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "cis-now",
+				Annotations: map[string]string{compv1alpha1.ScanRunRequesterAnnotation: "alice"},
+			},
+		}
+		run := old.DeepCopy()
+		run.Annotations[compv1alpha1.ScanRunRequesterAnnotation] = "someone-else"
+		Expect((&scanRunRequesterDefaulter{}).Default(newRequest(admissionv1.Update, old), run)).To(Succeed())
+		Expect(run.Annotations).To(HaveKeyWithValue(compv1alpha1.ScanRunRequesterAnnotation, "alice"))
+
+		By("not adding a requester to a ScanRun that had none")
+		delete(old.Annotations, compv1alpha1.ScanRunRequesterAnnotation)
+		Expect((&scanRunRequesterDefaulter{}).Default(newRequest(admissionv1.Update, old), run)).To(Succeed())
+		Expect(run.Annotations).ToNot(HaveKey(compv1alpha1.ScanRunRequesterAnnotation))
+		Expect(getScanRunRequester(run)).To(Equal("unknown"))
+	})
+
+	It("doesn't trust the requester if the webhook isn't served", func() {
+		requesterWebhookServed.Store(false)
+		run := &compv1alpha1.ScanRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:   map[string]string{compv1alpha1.ScanRunRequesterAnnotation: "kube:admin"},
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl-create"}},
+			},
+		}
+		Expect(getScanRunRequester(run)).To(Equal("unknown"))
+	})
+})
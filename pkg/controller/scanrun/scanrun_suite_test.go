@@ -0,0 +1,13 @@
+package scanrun
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestScanrun(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Scanrun Suite")
+}
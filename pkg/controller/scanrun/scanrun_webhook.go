@@ -0,0 +1,72 @@
+package scanrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// scanRunRequesterDefaulter records the user that creates a ScanRun, which
+// can't be told from the ScanRun itself. Any requester set by the user is
+// overwritten, and the requester can't be changed once the ScanRun is
+// created. The webhook fails closed, so that no ScanRun is created without
+// it recording the requester.
+type scanRunRequesterDefaulter struct{}
+
+var _ admission.CustomDefaulter = &scanRunRequesterDefaulter{}
+
+// requesterWebhookServed tells whether the webhook is served, the requesters
+// of the ScanRuns are set by hand otherwise and can't be trusted
+var requesterWebhookServed atomic.Bool
+
+//+kubebuilder:webhook:path=/mutate-compliance-openshift-io-v1alpha1-scanrun,mutating=true,failurePolicy=fail,sideEffects=None,groups=compliance.openshift.io,resources=scanruns,verbs=create;update,versions=v1alpha1,name=mscanrun.compliance.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the webhook recording the requesters of
+// ScanRuns
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&compv1alpha1.ScanRun{}).
+		WithDefaulter(&scanRunRequesterDefaulter{}).
+		Complete()
+	if err != nil {
+		return err
+	}
+	requesterWebhookServed.Store(true)
+	return nil
+}
+
+func (d *scanRunRequesterDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	run, ok := obj.(*compv1alpha1.ScanRun)
+	if !ok {
+		return fmt.Errorf("expected a ScanRun but got a %T", obj)
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	requester := req.UserInfo.Username
+	if req.Operation == admissionv1.Update {
+		old := &compv1alpha1.ScanRun{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("cannot decode the ScanRun being updated: %w", err)
+		}
+		requester = old.Annotations[compv1alpha1.ScanRunRequesterAnnotation]
+	}
+	if requester == "" {
+		delete(run.Annotations, compv1alpha1.ScanRunRequesterAnnotation)
+		return nil
+	}
+	if run.Annotations == nil {
+		run.Annotations = make(map[string]string)
+	}
+	run.Annotations[compv1alpha1.ScanRunRequesterAnnotation] = requester
+	return nil
+}
@@ -0,0 +1,48 @@
+package scanrun
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+type suiteMapper struct {
+	client.Client
+}
+
+// Map enqueues the ScanRuns that aren't finished of the binding of the suite,
+// or of the suite of the scan
+func (m *suiteMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	suiteName := obj.GetLabels()[compv1alpha1.SuiteLabel]
+	if _, isSuite := obj.(*compv1alpha1.ComplianceSuite); isSuite {
+		suiteName = obj.GetName()
+	}
+	if suiteName == "" {
+		return requests
+	}
+
+	runList := compv1alpha1.ScanRunList{}
+	err := m.List(ctx, &runList, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		return requests
+	}
+
+	for _, run := range runList.Items {
+		if run.Spec.ScanSettingBindingName != suiteName || run.IsFinished() {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      run.GetName(),
+			Namespace: run.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
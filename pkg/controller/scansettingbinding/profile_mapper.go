@@ -0,0 +1,81 @@
+package scansettingbinding
+
+import (
+	"context"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type profileMapper struct {
+	client.Client
+}
+
+func (s *profileMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	ssbList := v1alpha1.ScanSettingBindingList{}
+	err := s.List(ctx, &ssbList, &client.ListOptions{})
+	if err != nil {
+		return requests
+	}
+
+	for _, ssb := range ssbList.Items {
+		add := false
+
+		for _, profRef := range ssb.Profiles {
+			if profRef.Kind != "Profile" {
+				continue
+			}
+
+			if profRef.Name != obj.GetName() {
+				continue
+			}
+
+			add = true
+			break
+		}
+		if !add {
+			add = bindingSelectsObject(&ssb, "Profile", obj)
+		}
+
+		if add == false {
+			continue
+		}
+
+		objKey := types.NamespacedName{
+			Name:      ssb.GetName(),
+			Namespace: ssb.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
+
+// bindingSelectsObject tells whether the profile selectors of the binding
+// select the object of the given kind. As both the old and the new object
+// are mapped on updates, the bindings are also reconciled once the object
+// stops matching.
+func bindingSelectsObject(ssb *v1alpha1.ScanSettingBinding, kind string, obj client.Object) bool {
+	if ssb.GetNamespace() != obj.GetNamespace() {
+		return false
+	}
+	for i := range ssb.ProfileSelectors {
+		ps := &ssb.ProfileSelectors[i]
+		if ps.Kind != kind {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&ps.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package scansettingbinding
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	cron "github.com/robfig/cron/v3"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	compliancev1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// minRawResultSizePerScanRun is the least amount of raw result storage each
+// retained scan run should get before the ScanSetting is warned about
+var minRawResultSizePerScanRun = resource.MustParse("50Mi")
+
+// scanSettingValidator rejects ScanSettings the ScanSettingBindings using
+// them would fail on, e.g. because of a malformed schedule or a storage class
+// that doesn't exist, and warns about the settings that are likely mistakes
+type scanSettingValidator struct {
+	reader client.Reader
+}
+
+var _ admission.CustomValidator = &scanSettingValidator{}
+
+var roleVal = regexp.MustCompile(roleValRegexp)
+
+//+kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-scansetting,mutating=false,failurePolicy=ignore,sideEffects=None,groups=compliance.openshift.io,resources=scansettings,verbs=create;update,versions=v1alpha1,name=vscansetting.compliance.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the webhook validating ScanSettings
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&compliancev1alpha1.ScanSetting{}).
+		WithValidator(&scanSettingValidator{reader: mgr.GetClient()}).
+		Complete()
+}
+
+func (v *scanSettingValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	setting, ok := obj.(*compliancev1alpha1.ScanSetting)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSetting but got a %T", obj)
+	}
+	return v.validateScanSetting(ctx, setting, nil)
+}
+
+// ValidateUpdate only checks the storage classes that changed, so that the
+// ScanSettings can still be updated after a storage class they use was
+// removed
+func (v *scanSettingValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldSetting, ok := oldObj.(*compliancev1alpha1.ScanSetting)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSetting but got a %T", oldObj)
+	}
+	setting, ok := newObj.(*compliancev1alpha1.ScanSetting)
+	if !ok {
+		return nil, fmt.Errorf("expected a ScanSetting but got a %T", newObj)
+	}
+	return v.validateScanSetting(ctx, setting, getStorageClassNames(oldSetting))
+}
+
+func (v *scanSettingValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *scanSettingValidator) validateScanSetting(ctx context.Context, setting *compliancev1alpha1.ScanSetting, previousClasses map[string]bool) (admission.Warnings, error) {
+	allErrs := validateSchedules(setting)
+
+	storageErrs, warnings := validateStorageSizes(setting)
+	allErrs = append(allErrs, storageErrs...)
+
+	classErrs, err := v.validateStorageClasses(ctx, setting, previousClasses)
+	if err != nil {
+		return warnings, err
+	}
+	allErrs = append(allErrs, classErrs...)
+
+	roleErrs, roleWarnings, err := v.validateRolePools(ctx, setting)
+	if err != nil {
+		return warnings, err
+	}
+	allErrs = append(allErrs, roleErrs...)
+	warnings = append(warnings, roleWarnings...)
+
+	overridesPath := field.NewPath("severityOverrides")
+	overridden := make(map[string]bool, len(setting.SeverityOverrides))
+	for i, override := range setting.SeverityOverrides {
+		if overridden[override.Rule] {
+			allErrs = append(allErrs, field.Duplicate(overridesPath.Index(i).Child("rule"), override.Rule))
+		}
+		overridden[override.Rule] = true
+	}
+
+	if err := validateRoleRawResultStorage(setting); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("roleRawResultStorage"), setting.RoleRawResultStorage, err.Error()))
+	}
+
+	if len(allErrs) > 0 {
+		gk := compliancev1alpha1.SchemeGroupVersion.WithKind("ScanSetting").GroupKind()
+		return warnings, kerrors.NewInvalid(gk, setting.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+func validateSchedules(setting *compliancev1alpha1.ScanSetting) field.ErrorList {
+	var allErrs field.ErrorList
+	if setting.Schedule != "" {
+		if _, err := cron.ParseStandard(setting.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("schedule"), setting.Schedule, err.Error()))
+		}
+	}
+	if !utils.ValidTimezone(setting.Timezone) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("timezone"), setting.Timezone, "not a valid IANA time zone name"))
+	}
+	if setting.ScheduleJitter != nil && setting.ScheduleJitter.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("scheduleJitter"), setting.ScheduleJitter.Duration.String(), "can't be negative"))
+	}
+
+	schedulesPath := field.NewPath("additionalSchedules")
+	seen := make(map[string]bool, len(setting.AdditionalSchedules))
+	for i, sched := range setting.AdditionalSchedules {
+		if seen[sched.Name] {
+			allErrs = append(allErrs, field.Duplicate(schedulesPath.Index(i).Child("name"), sched.Name))
+		}
+		seen[sched.Name] = true
+		if _, err := cron.ParseStandard(sched.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(schedulesPath.Index(i).Child("schedule"), sched.Schedule, err.Error()))
+		}
+	}
+
+	if setting.RemediationApplyWindow != nil {
+		if _, err := cron.ParseStandard(setting.RemediationApplyWindow.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("remediationApplyWindow", "schedule"),
+				setting.RemediationApplyWindow.Schedule, err.Error()))
+		}
+	}
+	if setting.ScanWindow != nil {
+		windowPath := field.NewPath("scanWindow")
+		if _, err := cron.ParseStandard(setting.ScanWindow.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("schedule"), setting.ScanWindow.Schedule, err.Error()))
+		}
+		if setting.ScanWindow.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("duration"), setting.ScanWindow.Duration.Duration.String(), "must be positive"))
+		}
+	}
+	return allErrs
+}
+
+// validateStorageSizes rejects the sizes that aren't quantities, and warns
+// about the raw result storage that is likely to fill up given how many scan
+// runs it keeps
+func validateStorageSizes(setting *compliancev1alpha1.ScanSetting) (field.ErrorList, admission.Warnings) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+
+	storage := &setting.RawResultStorage
+	storagePath := field.NewPath("rawResultStorage")
+	if storage.MaxScanRunAge != nil && storage.MaxScanRunAge.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("maxScanRunAge"), storage.MaxScanRunAge.Duration.String(), "must be positive"))
+	}
+	retained := storage.GetRetainedScanRuns()
+	if retained == 0 && storage.MaxScanRunAge == nil {
+		warnings = append(warnings, fmt.Sprintf("%s: the raw results of all the scan runs are kept, so they will eventually fill up the volume unless maxScanRunAge is set", storagePath))
+	}
+
+	checkSize := func(sizePath *field.Path, size string) {
+		if size == "" {
+			return
+		}
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(sizePath, size, err.Error()))
+			return
+		}
+		if quantity.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(sizePath, size, "must be positive"))
+			return
+		}
+		if retained == 0 {
+			return
+		}
+		perRun := resource.NewQuantity(quantity.Value()/int64(retained), resource.BinarySI)
+		if perRun.Cmp(minRawResultSizePerScanRun) < 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: %s leaves %s for each of the %d retained scan runs, which might not fit their raw results",
+				sizePath, size, perRun, retained))
+		}
+	}
+
+	checkSize(storagePath.Child("size"), storage.Size)
+	for i, override := range setting.RoleRawResultStorage {
+		checkSize(field.NewPath("roleRawResultStorage").Index(i).Child("size"), override.Size)
+	}
+	return allErrs, warnings
+}
+
+// validateStorageClasses rejects the storage classes that don't exist, except
+// for those in previousClasses
+func (v *scanSettingValidator) validateStorageClasses(ctx context.Context, setting *compliancev1alpha1.ScanSetting, previousClasses map[string]bool) (field.ErrorList, error) {
+	var allErrs field.ErrorList
+	checkClass := func(classPath *field.Path, className *string) error {
+		if className == nil || *className == "" || previousClasses[*className] {
+			return nil
+		}
+		class := &storagev1.StorageClass{}
+		err := v.reader.Get(ctx, types.NamespacedName{Name: *className}, class)
+		if kerrors.IsNotFound(err) {
+			allErrs = append(allErrs, field.NotFound(classPath, *className))
+			return nil
+		}
+		return err
+	}
+
+	if err := checkClass(field.NewPath("rawResultStorage", "storageClassName"), setting.RawResultStorage.StorageClassName); err != nil {
+		return allErrs, err
+	}
+	for i, override := range setting.RoleRawResultStorage {
+		if err := checkClass(field.NewPath("roleRawResultStorage").Index(i).Child("storageClassName"), override.StorageClassName); err != nil {
+			return allErrs, err
+		}
+	}
+	return allErrs, nil
+}
+
+// validateRolePools rejects the malformed roles, and warns about the roles
+// no MachineConfigPool selects the nodes of, as their remediations can't be
+// applied. The pools aren't checked on clusters without MachineConfigPools.
+func (v *scanSettingValidator) validateRolePools(ctx context.Context, setting *compliancev1alpha1.ScanSetting) (field.ErrorList, admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	rolesPath := field.NewPath("roles")
+	var poolRoles []int
+	for i, role := range setting.Roles {
+		if role == compliancev1alpha1.AllRoles {
+			if len(setting.Roles) > 1 {
+				allErrs = append(allErrs, field.Invalid(rolesPath.Index(i), role, "can't be used alongside other roles"))
+			}
+			continue
+		}
+		if !roleVal.MatchString(role) {
+			allErrs = append(allErrs, field.Invalid(rolesPath.Index(i), role, fmt.Sprintf("must match %s", roleValRegexp)))
+			continue
+		}
+		poolRoles = append(poolRoles, i)
+	}
+	if len(poolRoles) == 0 {
+		return allErrs, warnings, nil
+	}
+
+	pools := &mcfgv1.MachineConfigPoolList{}
+	if err := v.reader.List(ctx, pools); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return allErrs, warnings, nil
+		}
+		return allErrs, warnings, err
+	}
+	for _, i := range poolRoles {
+		role := setting.Roles[i]
+		if !roleHasPool(role, pools) {
+			warnings = append(warnings, fmt.Sprintf("%s: no MachineConfigPool selects the nodes of role %s, so their remediations can't be applied",
+				rolesPath.Index(i), role))
+		}
+	}
+	return allErrs, warnings, nil
+}
+
+func roleHasPool(role string, pools *mcfgv1.MachineConfigPoolList) bool {
+	selector := utils.GetNodeRoleSelector(role)
+	for i := range pools.Items {
+		pool := &pools.Items[i]
+		if pool.Name == role || utils.McfgPoolLabelMatches(selector, pool) {
+			return true
+		}
+	}
+	return false
+}
+
+func getStorageClassNames(setting *compliancev1alpha1.ScanSetting) map[string]bool {
+	names := make(map[string]bool)
+	if setting.RawResultStorage.StorageClassName != nil {
+		names[*setting.RawResultStorage.StorageClassName] = true
+	}
+	for _, override := range setting.RoleRawResultStorage {
+		if override.StorageClassName != nil {
+			names[*override.StorageClassName] = true
+		}
+	}
+	return names
+}
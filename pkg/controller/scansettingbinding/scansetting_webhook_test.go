@@ -0,0 +1,186 @@
+package scansettingbinding
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mcfgv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("ScanSetting webhook", func() {
+	var (
+		namespace = "openshift-compliance"
+		validator *scanSettingValidator
+		setting   *compv1alpha1.ScanSetting
+	)
+
+	BeforeEach(func() {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+		Expect(mcfgv1.AddToScheme(cscheme)).To(Succeed())
+
+		gp3 := &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "gp3-csi"},
+			Provisioner: "ebs.csi.aws.com",
+		}
+		workerPool := &mcfgv1.MachineConfigPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec: mcfgv1.MachineConfigPoolSpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"node-role.kubernetes.io/worker": ""},
+				},
+			},
+		}
+		infraPool := &mcfgv1.MachineConfigPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "infra-pool"},
+			Spec: mcfgv1.MachineConfigPoolSpec{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"node-role.kubernetes.io/infra": ""},
+				},
+			},
+		}
+		masterPool := &mcfgv1.MachineConfigPool{ObjectMeta: metav1.ObjectMeta{Name: "master"}}
+		client := fake.NewClientBuilder().WithScheme(cscheme).WithObjects(gp3, workerPool, infraPool, masterPool).Build()
+		validator = &scanSettingValidator{reader: client}
+
+		className := "gp3-csi"
+		setting = &compv1alpha1.ScanSetting{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace},
+			ComplianceSuiteSettings: compv1alpha1.ComplianceSuiteSettings{
+				Schedule: "0 1 * * *",
+				Timezone: "Europe/Prague",
+			},
+			ComplianceScanSettings: compv1alpha1.ComplianceScanSettings{
+				RawResultStorage: compv1alpha1.RawResultStorageSettings{
+					Size:             "1Gi",
+					Rotation:         3,
+					StorageClassName: &className,
+				},
+			},
+			Roles: []string{"master", "worker", "infra"},
+		}
+	})
+
+	It("admits a valid ScanSetting", func() {
+		warnings, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("rejects malformed schedules and time zones", func() {
+		setting.Schedule = "every day"
+		setting.Timezone = "Local"
+		setting.ScheduleJitter = &metav1.Duration{Duration: -time.Minute}
+		setting.AdditionalSchedules = []compv1alpha1.ScanSchedule{
+			{Name: "weekly", Schedule: "0 2 * * 0"},
+			{Name: "weekly", Schedule: "0 2 * * 8"},
+		}
+		setting.RemediationApplyWindow = &compv1alpha1.RemediationApplyWindow{
+			Schedule: "0 25 * * *",
+			Duration: metav1.Duration{Duration: time.Hour},
+		}
+		setting.ScanWindow = &compv1alpha1.ScanWindow{Schedule: "0 22 * * 1-5"}
+		_, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(kerrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`schedule: Invalid value: "every day"`)))
+		Expect(err).To(MatchError(ContainSubstring(`timezone: Invalid value: "Local": not a valid IANA time zone name`)))
+		Expect(err).To(MatchError(ContainSubstring(`scheduleJitter: Invalid value: "-1m0s": can't be negative`)))
+		Expect(err).To(MatchError(ContainSubstring(`additionalSchedules[1].name: Duplicate value: "weekly"`)))
+		Expect(err).To(MatchError(ContainSubstring(`additionalSchedules[1].schedule: Invalid value: "0 2 * * 8"`)))
+		Expect(err).To(MatchError(ContainSubstring(`remediationApplyWindow.schedule: Invalid value: "0 25 * * *"`)))
+		Expect(err).To(MatchError(ContainSubstring(`scanWindow.duration: Invalid value: "0s": must be positive`)))
+	})
+
+	It("rejects storage classes that don't exist", func() {
+		missing := "standard"
+		setting.RoleRawResultStorage = []compv1alpha1.RoleRawResultStorageSettings{
+			{Role: "worker", StorageClassName: &missing},
+		}
+		_, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(kerrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`roleRawResultStorage[0].storageClassName: Not found: "standard"`)))
+	})
+
+	It("only checks the storage classes that changed on update", func() {
+		missing := "standard"
+		oldSetting := setting.DeepCopy()
+		oldSetting.RawResultStorage.StorageClassName = &missing
+		updated := oldSetting.DeepCopy()
+		updated.Schedule = "0 2 * * *"
+		_, err := validator.ValidateUpdate(context.TODO(), oldSetting, updated)
+		Expect(err).To(BeNil())
+
+		otherMissing := "slow"
+		updated.RawResultStorage.StorageClassName = &otherMissing
+		_, err = validator.ValidateUpdate(context.TODO(), oldSetting, updated)
+		Expect(err).To(MatchError(ContainSubstring(`rawResultStorage.storageClassName: Not found: "slow"`)))
+	})
+
+	It("rejects invalid sizes and roles", func() {
+		setting.RawResultStorage.Size = "lots"
+		setting.Roles = []string{"worker", "@all", "infra.nodes"}
+		setting.RoleRawResultStorage = []compv1alpha1.RoleRawResultStorageSettings{
+			{Role: "worker", Size: "-1Gi"},
+		}
+		_, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(kerrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`rawResultStorage.size: Invalid value: "lots"`)))
+		Expect(err).To(MatchError(ContainSubstring(`roleRawResultStorage[0].size: Invalid value: "-1Gi": must be positive`)))
+		Expect(err).To(MatchError(ContainSubstring(`roles[1]: Invalid value: "@all": can't be used alongside other roles`)))
+		Expect(err).To(MatchError(ContainSubstring(`roles[2]: Invalid value: "infra.nodes"`)))
+	})
+
+	It("rejects severity overrides of the same rule", func() {
+		setting.SeverityOverrides = []compv1alpha1.SeverityOverride{
+			{Rule: "no-empty-passwords", Severity: compv1alpha1.CheckResultSeverityLow},
+			{Rule: "no-empty-passwords", Severity: compv1alpha1.CheckResultSeverityInfo},
+		}
+		_, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(MatchError(ContainSubstring(`severityOverrides[1].rule: Duplicate value: "no-empty-passwords"`)))
+	})
+
+	It("rejects overrides of roles that aren't set", func() {
+		setting.RoleRawResultStorage = []compv1alpha1.RoleRawResultStorageSettings{
+			{Role: "gpu", Size: "2Gi"},
+		}
+		_, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(MatchError(ContainSubstring("the raw result storage is overridden for role gpu, which isn't one of the roles")))
+	})
+
+	It("warns about storage that doesn't fit the retained scan runs", func() {
+		setting.RawResultStorage.Size = "100Mi"
+		setting.RawResultStorage.Rotation = 10
+		warnings, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(ConsistOf("rawResultStorage.size: 100Mi leaves 10Mi for each of the 10 retained scan runs, which might not fit their raw results"))
+
+		var maxRetained uint16
+		setting.RawResultStorage.Size = "1Gi"
+		setting.RawResultStorage.MaxRetainedScanRuns = &maxRetained
+		warnings, err = validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(ConsistOf(ContainSubstring("the raw results of all the scan runs are kept")))
+
+		setting.RawResultStorage.MaxScanRunAge = &metav1.Duration{Duration: 720 * time.Hour}
+		warnings, err = validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("warns about roles without a MachineConfigPool", func() {
+		setting.Roles = append(setting.Roles, "gpu")
+		warnings, err := validator.ValidateCreate(context.TODO(), setting)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(ConsistOf("roles[3]: no MachineConfigPool selects the nodes of role gpu, so their remediations can't be applied"))
+	})
+})
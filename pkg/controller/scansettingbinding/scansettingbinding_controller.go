@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -68,6 +70,7 @@ func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconcil
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	ssMapper := &scanSettingMapper{mgr.GetClient()}
 	tpMapper := &tailoredProfileMapper{mgr.GetClient()}
+	pMapper := &profileMapper{mgr.GetClient()}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("scansettingbinding-controller").
@@ -75,6 +78,7 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		Owns(&compliancev1alpha1.ComplianceSuite{}).
 		Watches(&compliancev1alpha1.ScanSetting{}, handler.EnqueueRequestsFromMapFunc(ssMapper.Map)).
 		Watches(&compliancev1alpha1.TailoredProfile{}, handler.EnqueueRequestsFromMapFunc(tpMapper.Map)).
+		Watches(&compliancev1alpha1.Profile{}, handler.EnqueueRequestsFromMapFunc(pMapper.Map)).
 		Complete(r)
 }
 
@@ -148,8 +152,34 @@ func (r *ReconcileScanSettingBinding) Reconcile(ctx context.Context, request rec
 		return reconcile.Result{}, err
 	}
 
-	for i := range instance.Profiles {
-		ss := &instance.Profiles[i]
+	excludedRules, err := r.getExcludedRuleIDs(instance)
+	if errors.IsNotFound(err) {
+		msg := fmt.Sprintf("The excluded rule could not be found: %s", err)
+		ssb := instance.DeepCopy()
+		ssb.Status.SetConditionInvalid(msg)
+		ssb.Status.Phase = compliancev1alpha1.ScanSettingBindingPhaseInvalid
+		if updateErr := r.Client.Status().Update(context.TODO(), ssb); updateErr != nil {
+			return reconcile.Result{}, fmt.Errorf("couldn't update ScanSettingBinding condition: %w", updateErr)
+		}
+		return reconcile.Result{}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := validateProfileSelectors(instance); err != nil {
+		return r.setInvalidCondition(instance, fmt.Sprintf("Invalid profile selector: %s", err))
+	}
+	profiles, err := r.getBindingProfiles(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(profiles) == 0 && len(instance.ProfileSelectors) > 0 {
+		return r.setInvalidCondition(instance, "No Profile or TailoredProfile matches the profile selectors of the binding")
+	}
+
+	var deprecatedProfiles []string
+	for i := range profiles {
+		ss := &profiles[i]
 
 		key := types.NamespacedName{Namespace: instance.Namespace, Name: ss.Name}
 		profileObj, geterr := getUnstructured(r, instance, key, ss.Kind, ss.APIGroup, reqLogger)
@@ -157,6 +187,10 @@ func (r *ReconcileScanSettingBinding) Reconcile(ctx context.Context, request rec
 			return reconcile.Result{}, geterr
 		}
 
+		if msg := getProfileDeprecation(profileObj); msg != "" {
+			deprecatedProfiles = append(deprecatedProfiles, msg)
+		}
+
 		if profileObj.GetKind() == "TailoredProfile" {
 			val, found, nsErr := unstructured.NestedString(
 				profileObj.Object, "status", "state")
@@ -186,12 +220,27 @@ func (r *ReconcileScanSettingBinding) Reconcile(ctx context.Context, request rec
 			}
 		}
 
-		scan, _, err := newCompScanFromBindingProfile(r, instance, profileObj, log)
+		scan, _, err := newCompScanFromBindingProfile(r, instance, profileObj, excludedRules, log)
 		if err != nil {
 			return common.ReturnWithRetriableError(reqLogger, err)
 		}
 
 		suite.Spec.Scans = append(suite.Spec.Scans, *scan)
+
+		if profileObj.GetKind() == "TailoredProfile" {
+			additionalScans, err := newCompScansFromAdditionalOutputs(r, instance, profileObj, excludedRules, log)
+			if err != nil {
+				return common.ReturnWithRetriableError(reqLogger, err)
+			}
+			suite.Spec.Scans = append(suite.Spec.Scans, additionalScans...)
+		}
+	}
+	suite.Spec.DependsOn = instance.DependsOn
+
+	if updated, err := r.updateDeprecatedProfilesCondition(instance, deprecatedProfiles); err != nil {
+		return reconcile.Result{}, fmt.Errorf("couldn't update ScanSettingBinding condition: %w", err)
+	} else if updated {
+		return reconcile.Result{Requeue: true}, nil
 	}
 
 	if instance.SettingsRef != nil {
@@ -201,6 +250,15 @@ func (r *ReconcileScanSettingBinding) Reconcile(ctx context.Context, request rec
 		}
 	}
 
+	// The metadata of the binding that its policy selects is set on the
+	// results of all of its scans
+	resultLabels := instance.GetPropagatedResultLabels()
+	resultAnnotations := instance.GetPropagatedResultAnnotations()
+	for i := range suite.Spec.Scans {
+		suite.Spec.Scans[i].ResultLabels = resultLabels
+		suite.Spec.Scans[i].ResultAnnotations = resultAnnotations
+	}
+
 	if instance.SettingsRef != nil {
 		if err != nil {
 			return reconcile.Result{}, fmt.Errorf("Failed to get ScanSetting: %s", err)
@@ -357,13 +415,15 @@ func (r *ReconcileScanSettingBinding) applyConstraint(
 	}
 
 	// create per-role scans
-	suite.Spec.Scans = r.createScansWithSelector(suite, &v1setting, logger)
+	var scanRoles map[string]string
+	suite.Spec.Scans, scanRoles = r.createScansWithSelector(suite, &v1setting, logger)
 	// apply settings for suite - deep copy to future proof in case there are any slices or so later
 	suite.Spec.ComplianceSuiteSettings = *v1setting.ComplianceSuiteSettings.DeepCopy()
 	// apply settings for scans, need to DeepCopy as ScanSetting contains a slice
 	for i := range suite.Spec.Scans {
 		scan := &suite.Spec.Scans[i]
 		scan.ComplianceScanSettings = *v1setting.ComplianceScanSettings.DeepCopy()
+		scan.RawResultStorage = v1setting.GetRawResultStorage(scanRoles[scan.Name])
 	}
 
 	return nil
@@ -373,11 +433,11 @@ func (r *ReconcileScanSettingBinding) validateRoles(setting *compliancev1alpha1.
 	if len(setting.Roles) == 0 {
 		r.Eventf(setting, corev1.EventTypeWarning, "EmptyRoles",
 			"The ScanSetting's roles are empty. Node scans won't be scheduled.")
-		return nil
+		return validateRoleRawResultStorage(setting)
 	}
 	// This is fine and expected
 	if len(setting.Roles) == 1 && setting.Roles[0] == compliancev1alpha1.AllRoles {
-		return nil
+		return validateRoleRawResultStorage(setting)
 	}
 	for _, role := range setting.Roles {
 		if role == compliancev1alpha1.AllRoles {
@@ -387,15 +447,43 @@ func (r *ReconcileScanSettingBinding) validateRoles(setting *compliancev1alpha1.
 			return fmt.Errorf("role %s is invalid", role)
 		}
 	}
+	return validateRoleRawResultStorage(setting)
+}
+
+// validateRoleRawResultStorage makes sure the raw result storage settings
+// are only overridden for the roles of the ScanSetting, once for each
+func validateRoleRawResultStorage(setting *compliancev1alpha1.ScanSetting) error {
+	roles := map[string]bool{compliancev1alpha1.PlatformRole: true}
+	for _, role := range setting.Roles {
+		roles[role] = true
+	}
+	seen := make(map[string]bool, len(setting.RoleRawResultStorage))
+	for _, override := range setting.RoleRawResultStorage {
+		if seen[override.Role] {
+			return fmt.Errorf("the raw result storage of role %s is overridden more than once", override.Role)
+		}
+		seen[override.Role] = true
+		if !roles[override.Role] {
+			return fmt.Errorf("the raw result storage is overridden for role %s, which isn't one of the roles", override.Role)
+		}
+		if override.Size != "" {
+			if _, err := resource.ParseQuantity(override.Size); err != nil {
+				return fmt.Errorf("the raw result storage size of role %s is invalid: %w", override.Role, err)
+			}
+		}
+	}
 	return nil
 }
 
+// createScansWithSelector returns the per-role scans of the suite, along with
+// the role of each of them by their names
 func (r *ReconcileScanSettingBinding) createScansWithSelector(
 	suite *compliancev1alpha1.ComplianceSuite,
 	v1setting *compliancev1alpha1.ScanSetting,
 	logger logr.Logger,
-) []compliancev1alpha1.ComplianceScanSpecWrapper {
+) ([]compliancev1alpha1.ComplianceScanSpecWrapper, map[string]string) {
 	scansWithSelector := make([]compliancev1alpha1.ComplianceScanSpecWrapper, 0)
+	scanRoles := make(map[string]string)
 	for _, scan := range suite.Spec.Scans {
 		logger.Info("Processing original scan", "scan.Name", scan.Name)
 		if strings.ToLower(string(scan.ScanType)) == "node" {
@@ -405,16 +493,18 @@ func (r *ReconcileScanSettingBinding) createScansWithSelector(
 				scanCopy.NodeSelector = utils.GetNodeRoleSelector(role)
 				logger.Info("Adding per-role scan", "scanCopy.Name", scanCopy.Name)
 				scansWithSelector = append(scansWithSelector, *scanCopy)
+				scanRoles[scanCopy.Name] = role
 			}
 		} else {
 			scanCopy := scan.DeepCopy()
 			logger.Info("Adding platform scan", "scanCopy.Name", scanCopy.Name)
 			scansWithSelector = append(scansWithSelector, *scanCopy)
+			scanRoles[scanCopy.Name] = compliancev1alpha1.PlatformRole
 		}
 
 	}
 
-	return scansWithSelector
+	return scansWithSelector, scanRoles
 }
 
 // returns a sanitized role name that can be used
@@ -431,7 +521,106 @@ func (r *ReconcileScanSettingBinding) sanitizeRoleForName(roleName string) strin
 
 }
 
-func newCompScanFromBindingProfile(r *ReconcileScanSettingBinding, instance *compliancev1alpha1.ScanSettingBinding, profile *unstructured.Unstructured, logger logr.Logger) (*compliancev1alpha1.ComplianceScanSpecWrapper, string, error) {
+// getExcludedRuleIDs resolves the Rules excluded by the binding to their
+// XCCDF IDs, keyed by the name of the ProfileBundle the Rule comes from.
+func (r *ReconcileScanSettingBinding) getExcludedRuleIDs(instance *compliancev1alpha1.ScanSettingBinding) (map[string][]string, error) {
+	excluded := make(map[string][]string)
+	for _, ruleName := range instance.ExcludeRules {
+		rule := &compliancev1alpha1.Rule{}
+		key := types.NamespacedName{Namespace: instance.Namespace, Name: ruleName}
+		if err := r.Client.Get(context.TODO(), key, rule); err != nil {
+			return nil, fmt.Errorf("couldn't get excluded rule %s: %w", ruleName, err)
+		}
+		bundle := rule.GetLabels()[compliancev1alpha1.ProfileBundleOwnerLabel]
+		excluded[bundle] = append(excluded[bundle], rule.ID)
+	}
+	return excluded, nil
+}
+
+// validateProfileSelectors makes sure the profile selectors of the binding
+// can be evaluated, and that none of them selects all the profiles of a kind
+func validateProfileSelectors(instance *compliancev1alpha1.ScanSettingBinding) error {
+	for i := range instance.ProfileSelectors {
+		ps := &instance.ProfileSelectors[i]
+		if ps.Kind != "Profile" && ps.Kind != "TailoredProfile" {
+			return fmt.Errorf("the kind of the selected objects must be Profile or TailoredProfile, not '%s'", ps.Kind)
+		}
+		if len(ps.Selector.MatchLabels) == 0 && len(ps.Selector.MatchExpressions) == 0 {
+			return fmt.Errorf("the selector of the %s objects is empty, which would select all of them", ps.Kind)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&ps.Selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBindingProfiles returns the profiles the binding refers to by name,
+// followed by the ones its profile selectors select, sorted by their name.
+// The profiles that are selected more than once are only returned once.
+func (r *ReconcileScanSettingBinding) getBindingProfiles(instance *compliancev1alpha1.ScanSettingBinding) ([]compliancev1alpha1.NamedObjectReference, error) {
+	profiles := make([]compliancev1alpha1.NamedObjectReference, 0, len(instance.Profiles))
+	seen := make(map[string]bool)
+	for _, profRef := range instance.Profiles {
+		profiles = append(profiles, profRef)
+		seen[profRef.Kind+"/"+profRef.Name] = true
+	}
+
+	for i := range instance.ProfileSelectors {
+		ps := &instance.ProfileSelectors[i]
+		// The selectors are validated earlier
+		selector, _ := metav1.LabelSelectorAsSelector(&ps.Selector)
+		listOpts := []client.ListOption{
+			client.InNamespace(instance.Namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		}
+
+		var names []string
+		if ps.Kind == "Profile" {
+			list := &compliancev1alpha1.ProfileList{}
+			if err := r.Client.List(context.TODO(), list, listOpts...); err != nil {
+				return nil, err
+			}
+			for _, p := range list.Items {
+				names = append(names, p.Name)
+			}
+		} else {
+			list := &compliancev1alpha1.TailoredProfileList{}
+			if err := r.Client.List(context.TODO(), list, listOpts...); err != nil {
+				return nil, err
+			}
+			for _, tp := range list.Items {
+				names = append(names, tp.Name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if seen[ps.Kind+"/"+name] {
+				continue
+			}
+			seen[ps.Kind+"/"+name] = true
+			profiles = append(profiles, compliancev1alpha1.NamedObjectReference{
+				Name:     name,
+				Kind:     ps.Kind,
+				APIGroup: compliancev1alpha1.SchemeGroupVersion.String(),
+			})
+		}
+	}
+	return profiles, nil
+}
+
+func (r *ReconcileScanSettingBinding) setInvalidCondition(instance *compliancev1alpha1.ScanSettingBinding, msg string) (reconcile.Result, error) {
+	ssb := instance.DeepCopy()
+	ssb.Status.SetConditionInvalid(msg)
+	ssb.Status.Phase = compliancev1alpha1.ScanSettingBindingPhaseInvalid
+	if updateErr := r.Client.Status().Update(context.TODO(), ssb); updateErr != nil {
+		return reconcile.Result{}, fmt.Errorf("couldn't update ScanSettingBinding condition: %w", updateErr)
+	}
+	return reconcile.Result{}, nil
+}
+
+func newCompScanFromBindingProfile(r *ReconcileScanSettingBinding, instance *compliancev1alpha1.ScanSettingBinding, profile *unstructured.Unstructured, excludedRules map[string][]string, logger logr.Logger) (*compliancev1alpha1.ComplianceScanSpecWrapper, string, error) {
 	parsedProfReference, err := resolveProfileReference(r, instance, profile, logger)
 	if err != nil {
 		return nil, "", err
@@ -445,10 +634,47 @@ func newCompScanFromBindingProfile(r *ReconcileScanSettingBinding, instance *com
 		)
 		return nil, "", err
 	}
+	scan.ExcludeRules = excludedRules[parsedProfReference.profileBundle.GetName()]
 
 	return scan, platform, nil
 }
 
+// newCompScansFromAdditionalOutputs creates a scan per tailoring file of the
+// rules a tailored profile selects from other ProfileBundles, which are
+// checked against the content of their ProfileBundle
+func newCompScansFromAdditionalOutputs(r *ReconcileScanSettingBinding, instance *compliancev1alpha1.ScanSettingBinding, tp *unstructured.Unstructured, excludedRules map[string][]string, logger logr.Logger) ([]compliancev1alpha1.ComplianceScanSpecWrapper, error) {
+	v1alphaTp := compliancev1alpha1.TailoredProfile{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(tp.Object, &v1alphaTp)
+	if err != nil {
+		return nil, common.WrapNonRetriableCtrlError(err)
+	}
+
+	var scans []compliancev1alpha1.ComplianceScanSpecWrapper
+	for _, output := range v1alphaTp.Status.AdditionalOutputs {
+		key := types.NamespacedName{Namespace: tp.GetNamespace(), Name: output.ProfileBundle}
+		bundle, err := getUnstructured(r, instance, key, "ProfileBundle", compliancev1alpha1.SchemeGroupVersion.String(), logger)
+		if err != nil {
+			return nil, err
+		}
+
+		scan := compliancev1alpha1.ComplianceScanSpecWrapper{
+			Name: fmt.Sprintf("%s-%s", tp.GetName(), output.ProfileBundle),
+		}
+		if err := fillContentData(bundle, nil, &scan); err != nil {
+			return nil, err
+		}
+		if output.ContentFile != "" {
+			scan.Content = output.ContentFile
+		}
+		scan.Profile = output.ID
+		scan.TailoringConfigMap = &compliancev1alpha1.TailoringConfigMapRef{Name: output.OutputRef.Name}
+		scan.ScanType = output.ScanType
+		scan.ExcludeRules = excludedRules[output.ProfileBundle]
+		scans = append(scans, scan)
+	}
+	return scans, nil
+}
+
 type profileReference struct {
 	name string
 
@@ -465,7 +691,11 @@ func profileReferenceToScan(reference *profileReference) (*compliancev1alpha1.Co
 		Name:               reference.name,
 	}
 
-	err = fillContentData(reference.profileBundle, &scan)
+	if reference.tailoredProfile != nil {
+		err = fillContentData(reference.profileBundle, reference.tailoredProfile, &scan)
+	} else {
+		err = fillContentData(reference.profileBundle, reference.profile, &scan)
+	}
 	if err != nil {
 		return nil, "", err
 	}
@@ -504,7 +734,9 @@ func profileReferenceToScan(reference *profileReference) (*compliancev1alpha1.Co
 	return &scan, product, nil
 }
 
-func fillContentData(bundle *unstructured.Unstructured, scan *compliancev1alpha1.ComplianceScanSpecWrapper) error {
+// fillContentData sets the content of the scan to the data stream file of the
+// bundle that the profile or tailored profile is in
+func fillContentData(bundle, source *unstructured.Unstructured, scan *compliancev1alpha1.ComplianceScanSpecWrapper) error {
 	if err := isCmpv1Alpha1Gvk(bundle, "ProfileBundle"); err != nil {
 		return common.WrapNonRetriableCtrlError(err)
 	}
@@ -523,6 +755,9 @@ func fillContentData(bundle *unstructured.Unstructured, scan *compliancev1alpha1
 	}
 
 	scan.Content = v1alphaBundle.Spec.ContentFile
+	if source != nil {
+		scan.Content = v1alphaBundle.GetContentFileOf(source)
+	}
 	scan.ContentImage = v1alphaBundle.Spec.ContentImage
 	return nil
 }
@@ -543,6 +778,9 @@ func fillTailoredProfileData(tp *unstructured.Unstructured, scan *compliancev1al
 		// FIXME: OutputRef also has a namespace, but tailorringCofnigMapRef not?
 		scan.TailoringConfigMap = &compliancev1alpha1.TailoringConfigMapRef{Name: v1alphaTp.Status.OutputRef.Name}
 	}
+	for _, customRule := range v1alphaTp.Spec.CustomRules {
+		scan.CustomRules = append(scan.CustomRules, customRule.Name)
+	}
 
 	return nil
 }
@@ -818,3 +1056,43 @@ func (r *ReconcileScanSettingBinding) removeSuspendedCondition(ssb *compliancev1
 	}
 	return nil
 }
+
+// getProfileDeprecation returns a message explaining how a Profile marked as
+// deprecated by its content was replaced, or an empty string if the Profile
+// isn't deprecated
+func getProfileDeprecation(profileObj *unstructured.Unstructured) string {
+	if profileObj.GetKind() != "Profile" {
+		return ""
+	}
+	annotations := profileObj.GetAnnotations()
+	if annotations[compliancev1alpha1.ProfileDeprecatedAnnotation] != "true" {
+		return ""
+	}
+	if replacement := annotations[compliancev1alpha1.ProfileReplacedByAnnotation]; replacement != "" {
+		return fmt.Sprintf("Profile %s is deprecated and replaced by Profile %s", profileObj.GetName(), replacement)
+	}
+	return fmt.Sprintf("Profile %s is deprecated", profileObj.GetName())
+}
+
+// updateDeprecatedProfilesCondition flags the binding when it refers to
+// deprecated Profiles, and unflags it once it no longer does. It returns
+// whether the status was updated.
+func (r *ReconcileScanSettingBinding) updateDeprecatedProfilesCondition(ssb *compliancev1alpha1.ScanSettingBinding, deprecatedProfiles []string) (bool, error) {
+	c := ssb.DeepCopy()
+	var changed bool
+	if len(deprecatedProfiles) == 0 {
+		changed = c.Status.Conditions.RemoveCondition(compliancev1alpha1.ScanSettingBindingConditionDeprecatedProfiles)
+	} else {
+		changed = c.Status.SetConditionDeprecatedProfiles(strings.Join(deprecatedProfiles, "; "))
+	}
+	if !changed {
+		return false, nil
+	}
+	if err := r.Client.Status().Update(context.TODO(), c); err != nil {
+		return false, err
+	}
+	for _, msg := range deprecatedProfiles {
+		r.Eventf(ssb, corev1.EventTypeWarning, "DeprecatedProfile", "%s", msg)
+	}
+	return true, nil
+}
@@ -13,6 +13,7 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -187,6 +188,8 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 
 		scheme := scheme.Scheme
 		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, objs...)
+		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, &compv1alpha1.Rule{}, &compv1alpha1.RuleList{})
+		scheme.AddKnownTypes(compv1alpha1.SchemeGroupVersion, &compv1alpha1.ProfileList{}, &compv1alpha1.TailoredProfileList{}, &compv1alpha1.ScanSettingBindingList{})
 
 		statusObjs := []runtimeclient.Object{}
 		statusObjs = append(statusObjs, ssb, scratchTP)
@@ -365,6 +368,239 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 			}
 			Expect(suite.Spec.Scans).To(ConsistOf(expScanWorker, expScanMaster))
 		})
+
+		It("Should override the raw result storage of the scans of a role", func() {
+			className := "fast"
+			setting.RawResultStorage.Size = "1Gi"
+			setting.RoleRawResultStorage = []compv1alpha1.RoleRawResultStorageSettings{
+				{
+					Role:             "worker",
+					Size:             "20Gi",
+					StorageClassName: &className,
+				},
+			}
+			err := reconciler.Client.Update(context.TODO(), setting)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+			Expect(suite.Spec.Scans).To(HaveLen(2))
+			for _, scan := range suite.Spec.Scans {
+				if scan.Name == profRhcosE8.Name+"-worker" {
+					Expect(scan.RawResultStorage.Size).To(Equal("20Gi"))
+					Expect(scan.RawResultStorage.StorageClassName).To(Equal(&className))
+				} else {
+					Expect(scan.RawResultStorage.Size).To(Equal("1Gi"))
+					Expect(scan.RawResultStorage.StorageClassName).To(BeNil())
+				}
+			}
+		})
+	})
+
+	Context("Excludes rules from the scans of a Profile", func() {
+		var rule *compv1alpha1.Rule
+
+		JustBeforeEach(func() {
+			rule = &compv1alpha1.Rule{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "rhcos4-audit-rules-login-events",
+					Namespace: common.GetComplianceOperatorNamespace(),
+					Labels: map[string]string{
+						compv1alpha1.ProfileBundleOwnerLabel: pBundleRhcos.Name,
+					},
+				},
+				RulePayload: compv1alpha1.RulePayload{
+					ID: "xccdf_org.ssgproject.content_rule_audit_rules_login_events",
+				},
+			}
+			err := reconciler.Client.Create(context.TODO(), rule)
+			Expect(err).To(BeNil())
+
+			bindingTypeMeta := v1.TypeMeta{}
+			bindingTypeMeta.SetGroupVersionKind(compv1alpha1.SchemeGroupVersion.WithKind("ScanSettingBinding"))
+			ssb = &compv1alpha1.ScanSettingBinding{
+				TypeMeta: bindingTypeMeta,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "compliance-requirements-excluded-rules",
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{
+						Name:     profRhcosE8.Name,
+						Kind:     profRhcosE8.Kind,
+						APIGroup: profRhcosE8.APIVersion,
+					},
+				},
+				ExcludeRules: []string{rule.Name},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name:     setting.Name,
+					Kind:     setting.Kind,
+					APIGroup: setting.APIVersion,
+				},
+			}
+			ssb.Status.SetConditionPending()
+
+			err = reconciler.Client.Create(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should set the excluded rule IDs on the scans", func() {
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+			Expect(suite.Spec.Scans).To(HaveLen(2))
+			for _, scan := range suite.Spec.Scans {
+				Expect(scan.ExcludeRules).To(ConsistOf(rule.ID))
+			}
+		})
+
+		It("Should propagate the selected metadata of the binding onto the results", func() {
+			ssb.Labels = map[string]string{
+				"team":                    "payments",
+				"example.com/environment": "prod",
+				"unrelated":               "value",
+				compv1alpha1.SuiteLabel:   "other-suite",
+			}
+			ssb.Annotations = map[string]string{
+				"example.com/ticket": "SEC-1234",
+				"notes":              "not propagated",
+			}
+			ssb.ResultMetadataPropagation = &compv1alpha1.ResultMetadataPropagation{
+				Labels:      []string{"team", "example.com/*", compv1alpha1.SuiteLabel},
+				Annotations: []string{"example.com/ticket"},
+			}
+			err := reconciler.Client.Update(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+			Expect(suite.Spec.Scans).To(HaveLen(2))
+			for _, scan := range suite.Spec.Scans {
+				Expect(scan.ResultLabels).To(Equal(map[string]string{
+					"team":                    "payments",
+					"example.com/environment": "prod",
+				}))
+				Expect(scan.ResultAnnotations).To(Equal(map[string]string{"example.com/ticket": "SEC-1234"}))
+			}
+		})
+
+		It("Should mark the binding as invalid if an excluded rule doesn't exist", func() {
+			ssb.ExcludeRules = append(ssb.ExcludeRules, "rhcos4-nonexistent-rule")
+			err := reconciler.Client.Update(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, ssb)
+			Expect(err).To(BeNil())
+			Expect(ssb.Status.Phase).To(Equal(compv1alpha1.ScanSettingBindingPhaseInvalid))
+			Expect(ssb.Status.Conditions.IsFalseFor("Ready")).To(BeTrue())
+		})
+	})
+
+	Context("Flags deprecated profiles", func() {
+		JustBeforeEach(func() {
+			prof := &compv1alpha1.Profile{}
+			err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: profRhcosE8.Name, Namespace: profRhcosE8.Namespace}, prof)
+			Expect(err).To(BeNil())
+			prof.Annotations[compv1alpha1.ProfileDeprecatedAnnotation] = "true"
+			prof.Annotations[compv1alpha1.ProfileReplacedByAnnotation] = "rhcos4-essential-eight"
+			err = reconciler.Client.Update(context.TODO(), prof)
+			Expect(err).To(BeNil())
+
+			bindingTypeMeta := v1.TypeMeta{}
+			bindingTypeMeta.SetGroupVersionKind(compv1alpha1.SchemeGroupVersion.WithKind("ScanSettingBinding"))
+			ssb = &compv1alpha1.ScanSettingBinding{
+				TypeMeta: bindingTypeMeta,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "deprecated-compliance-requirements",
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{
+						Name:     profRhcosE8.Name,
+						Kind:     profRhcosE8.Kind,
+						APIGroup: profRhcosE8.APIVersion,
+					},
+				},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name:     setting.Name,
+					Kind:     setting.Kind,
+					APIGroup: setting.APIVersion,
+				},
+			}
+			ssb.Status.SetConditionPending()
+
+			err = reconciler.Client.Create(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+		})
+
+		It("Should explain what replaces the profile and still create the suite", func() {
+			key := types.NamespacedName{Namespace: ssb.Namespace, Name: ssb.Name}
+			result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeNil())
+			Expect(result.Requeue).To(BeTrue())
+
+			err = reconciler.Client.Get(context.TODO(), key, ssb)
+			Expect(err).To(BeNil())
+			cond := ssb.Status.Conditions.GetCondition(compv1alpha1.ScanSettingBindingConditionDeprecatedProfiles)
+			Expect(cond).ToNot(BeNil())
+			Expect(cond.Message).To(Equal("Profile rhcos4-e8 is deprecated and replaced by Profile rhcos4-essential-eight"))
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeNil())
+			err = reconciler.Client.Get(context.TODO(), key, suite)
+			Expect(err).To(BeNil())
+			Expect(suite.Spec.Scans).To(HaveLen(2))
+		})
+
+		It("Should remove the condition once the profile is no longer deprecated", func() {
+			key := types.NamespacedName{Namespace: ssb.Namespace, Name: ssb.Name}
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeNil())
+
+			prof := &compv1alpha1.Profile{}
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: profRhcosE8.Name, Namespace: profRhcosE8.Namespace}, prof)
+			Expect(err).To(BeNil())
+			delete(prof.Annotations, compv1alpha1.ProfileDeprecatedAnnotation)
+			err = reconciler.Client.Update(context.TODO(), prof)
+			Expect(err).To(BeNil())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			Expect(err).To(BeNil())
+			err = reconciler.Client.Get(context.TODO(), key, ssb)
+			Expect(err).To(BeNil())
+			Expect(ssb.Status.Conditions.GetCondition(compv1alpha1.ScanSettingBindingConditionDeprecatedProfiles)).To(BeNil())
+		})
 	})
 
 	Context("Creates a simple suite from a TailoredProfile", func() {
@@ -469,6 +705,113 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 		})
 	})
 
+	Context("Selects profiles by their labels", func() {
+		JustBeforeEach(func() {
+			for _, tp := range []*compv1alpha1.TailoredProfile{tpRhcosE8, scratchTP} {
+				tp.Labels = map[string]string{"baseline": "org"}
+				Expect(reconciler.Client.Update(context.TODO(), tp)).To(Succeed())
+			}
+
+			bindingTypeMeta := v1.TypeMeta{}
+			bindingTypeMeta.SetGroupVersionKind(compv1alpha1.SchemeGroupVersion.WithKind("ScanSettingBinding"))
+			ssb = &compv1alpha1.ScanSettingBinding{
+				TypeMeta: bindingTypeMeta,
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "org-baseline",
+					Namespace: common.GetComplianceOperatorNamespace(),
+				},
+				Profiles: []compv1alpha1.NamedObjectReference{
+					{
+						Name:     tpRhcosE8.Name,
+						Kind:     tpRhcosE8.Kind,
+						APIGroup: tpRhcosE8.APIVersion,
+					},
+				},
+				ProfileSelectors: []compv1alpha1.ProfileSelector{
+					{
+						Kind: "TailoredProfile",
+						Selector: v1.LabelSelector{
+							MatchLabels: map[string]string{"baseline": "org"},
+						},
+					},
+				},
+				SettingsRef: &compv1alpha1.NamedObjectReference{
+					Name:     setting.Name,
+					Kind:     setting.Kind,
+					APIGroup: setting.APIVersion,
+				},
+			}
+			ssb.Status.SetConditionPending()
+
+			err := reconciler.Client.Create(context.TODO(), ssb)
+			Expect(err).To(BeNil())
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{
+				Namespace: ssb.Namespace,
+				Name:      ssb.Name,
+			}, ssb)
+			Expect(err).To(BeNil())
+		})
+
+		reconcileBinding := func() {
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ssb.Namespace,
+					Name:      ssb.Name,
+				},
+			})
+			Expect(err).To(BeNil())
+			err = reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, ssb)
+			Expect(err).To(BeNil())
+		}
+
+		It("Should scan for the selected profiles once", func() {
+			reconcileBinding()
+			Expect(ssb.Status.Phase).To(Equal(compv1alpha1.ScanSettingBindingPhaseReady))
+
+			err := reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace}, suite)
+			Expect(err).To(BeNil())
+			var scanNames []string
+			for _, scan := range suite.Spec.Scans {
+				scanNames = append(scanNames, scan.Name)
+			}
+			Expect(scanNames).To(Equal([]string{
+				tpRhcosE8.Name + "-master", tpRhcosE8.Name + "-worker",
+				scratchTP.Name + "-master", scratchTP.Name + "-worker",
+			}))
+		})
+
+		It("Should reconcile the binding when a profile starts or stops matching", func() {
+			mapper := &tailoredProfileMapper{reconciler.Client}
+			Expect(mapper.Map(context.TODO(), scratchTP)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: ssb.Name, Namespace: ssb.Namespace},
+			}))
+
+			scratchTP.Labels = nil
+			Expect(mapper.Map(context.TODO(), scratchTP)).To(BeEmpty())
+			profileMapper := &profileMapper{reconciler.Client}
+			Expect(profileMapper.Map(context.TODO(), profRhcosE8)).To(BeEmpty())
+		})
+
+		It("Should mark the binding as invalid if no profile matches", func() {
+			ssb.Profiles = nil
+			ssb.ProfileSelectors[0].Selector.MatchLabels = map[string]string{"baseline": "none"}
+			Expect(reconciler.Client.Update(context.TODO(), ssb)).To(Succeed())
+
+			reconcileBinding()
+			Expect(ssb.Status.Phase).To(Equal(compv1alpha1.ScanSettingBindingPhaseInvalid))
+			Expect(ssb.Status.Conditions.GetCondition("Ready").Message).To(Equal("No Profile or TailoredProfile matches the profile selectors of the binding"))
+		})
+
+		It("Should mark the binding as invalid if a selector is empty", func() {
+			ssb.ProfileSelectors[0].Selector = v1.LabelSelector{}
+			Expect(reconciler.Client.Update(context.TODO(), ssb)).To(Succeed())
+
+			reconcileBinding()
+			Expect(ssb.Status.Phase).To(Equal(compv1alpha1.ScanSettingBindingPhaseInvalid))
+			Expect(ssb.Status.Conditions.GetCondition("Ready").Message).To(ContainSubstring("the selector of the TailoredProfile objects is empty"))
+		})
+	})
+
 	Context("Creates a suite from a TailoredProfile created from scratch", func() {
 		JustBeforeEach(func() {
 			bindingTypeMeta := v1.TypeMeta{}
@@ -835,6 +1178,101 @@ var _ = Describe("Testing scansettingbinding controller", func() {
 			Entry("empty string", []string{""}),
 			Entry("invalid character", []string{"l33t$"}),
 		)
+
+		DescribeTable("Should validate the raw result storage overrides",
+			func(roles []string, override compv1alpha1.RoleRawResultStorageSettings, valid bool) {
+				ss := &compv1alpha1.ScanSetting{
+					Roles:                roles,
+					RoleRawResultStorage: []compv1alpha1.RoleRawResultStorageSettings{override},
+				}
+				err := reconciler.validateRoles(ss)
+				if valid {
+					Expect(err).To(BeNil())
+				} else {
+					Expect(err).ToNot(BeNil(), "validation should have returned an error")
+				}
+			},
+			Entry("of a role", []string{"master", "worker"},
+				compv1alpha1.RoleRawResultStorageSettings{Role: "worker", Size: "10Gi"}, true),
+			Entry("of the platform scans", []string{},
+				compv1alpha1.RoleRawResultStorageSettings{Role: compv1alpha1.PlatformRole, Size: "100Mi"}, true),
+			Entry("of an unknown role", []string{"master", "worker"},
+				compv1alpha1.RoleRawResultStorageSettings{Role: "infra", Size: "10Gi"}, false),
+			Entry("with an invalid size", []string{"worker"},
+				compv1alpha1.RoleRawResultStorageSettings{Role: "worker", Size: "lots"}, false),
+		)
 	})
 
+	Context("Uses the content file of the profile", func() {
+		It("Should scan with the data stream file the profile was parsed out of", func() {
+			pBundleRhcos.Spec.ContentFile = ""
+			pBundleRhcos.Spec.ContentFiles = []compv1alpha1.ProfileBundleContentFile{
+				{File: "ssg-ocp4-ds.xml"},
+				{File: "ssg-rhcos4-ds.xml"},
+			}
+			profRhcosE8.Annotations[compv1alpha1.ContentFileAnnotation] = "ssg-rhcos4-ds.xml"
+
+			bundleObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pBundleRhcos)
+			Expect(err).To(BeNil())
+			profileObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(profRhcosE8)
+			Expect(err).To(BeNil())
+
+			scan := compv1alpha1.ComplianceScanSpecWrapper{}
+			err = fillContentData(&unstructured.Unstructured{Object: bundleObj}, &unstructured.Unstructured{Object: profileObj}, &scan)
+			Expect(err).To(BeNil())
+			Expect(scan.Content).To(Equal("ssg-rhcos4-ds.xml"))
+			Expect(scan.ContentImage).To(Equal(pBundleRhcos.Spec.ContentImage))
+		})
+	})
+
+	Context("Uses the custom rules of the TailoredProfile", func() {
+		It("Should evaluate the custom rules in the scan", func() {
+			tpRhcosE8.Spec.CustomRules = []compv1alpha1.RuleReferenceSpec{
+				{Name: "etcd-encrypted"},
+				{Name: "no-kubeadmin"},
+			}
+			tpObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tpRhcosE8)
+			Expect(err).To(BeNil())
+
+			scan := compv1alpha1.ComplianceScanSpecWrapper{}
+			err = fillTailoredProfileData(&unstructured.Unstructured{Object: tpObj}, &scan)
+			Expect(err).To(BeNil())
+			Expect(scan.CustomRules).To(Equal([]string{"etcd-encrypted", "no-kubeadmin"}))
+		})
+	})
+
+	Context("Scans the rules the TailoredProfile selects from other bundles", func() {
+		It("Should create a scan per additional tailoring file", func() {
+			scratchTP.Status.AdditionalOutputs = []compv1alpha1.TailoredProfileOutput{
+				{
+					ProfileBundle: pBundleRhcos.Name,
+					ScanType:      compv1alpha1.ScanTypeNode,
+					ID:            scratchTP.Status.ID,
+					OutputRef: compv1alpha1.OutputRef{
+						Name:      "scratch-tp-rhcos4-tp",
+						Namespace: common.GetComplianceOperatorNamespace(),
+					},
+				},
+			}
+			tpObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(scratchTP)
+			Expect(err).To(BeNil())
+
+			excludedRules := map[string][]string{pBundleRhcos.Name: {"xccdf_org.ssgproject.content_rule_no_empty_passwords"}}
+			scans, err := newCompScansFromAdditionalOutputs(&reconciler, ssb, &unstructured.Unstructured{Object: tpObj}, excludedRules, log)
+			Expect(err).To(BeNil())
+			Expect(scans).To(ConsistOf(compv1alpha1.ComplianceScanSpecWrapper{
+				ComplianceScanSpec: compv1alpha1.ComplianceScanSpec{
+					ScanType:     compv1alpha1.ScanTypeNode,
+					ContentImage: pBundleRhcos.Spec.ContentImage,
+					Content:      pBundleRhcos.Spec.ContentFile,
+					Profile:      scratchTP.Status.ID,
+					TailoringConfigMap: &compv1alpha1.TailoringConfigMapRef{
+						Name: "scratch-tp-rhcos4-tp",
+					},
+					ExcludeRules: excludedRules[pBundleRhcos.Name],
+				},
+				Name: "scratch-tp-rhcos4",
+			}))
+		})
+	})
 })
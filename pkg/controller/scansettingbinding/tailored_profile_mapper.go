@@ -36,6 +36,9 @@ func (s *tailoredProfileMapper) Map(ctx context.Context, obj client.Object) []re
 			add = true
 			break
 		}
+		if !add {
+			add = bindingSelectsObject(&ssb, "TailoredProfile", obj)
+		}
 
 		if add == false {
 			continue
@@ -0,0 +1,272 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+// additionalTailoring holds the rules and variables a tailored profile
+// selects from a ProfileBundle other than its own. They end up in a tailoring
+// file of their own, as a profile can only tailor a single data stream.
+type additionalTailoring struct {
+	pb *cmpv1alpha1.ProfileBundle
+	// tp only selects the rules of the ProfileBundle
+	tp        *cmpv1alpha1.TailoredProfile
+	rules     map[string]*cmpv1alpha1.Rule
+	variables []*cmpv1alpha1.Variable
+	scanType  cmpv1alpha1.ComplianceScanType
+}
+
+// isFromOtherBundle returns whether the selection references a rule of a
+// ProfileBundle other than the one of the tailored profile
+func isFromOtherBundle(selection cmpv1alpha1.RuleReferenceSpec, pb *cmpv1alpha1.ProfileBundle) bool {
+	return selection.ProfileBundle != "" && selection.ProfileBundle != pb.GetName()
+}
+
+// getAdditionalTailorings gets the rules the tailored profile selects from
+// other ProfileBundles, grouped by their ProfileBundle and sorted by its name
+func (r *ReconcileTailoredProfile) getAdditionalTailorings(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle) ([]*additionalTailoring, error) {
+	for _, selection := range tp.Spec.DisableRules {
+		if isFromOtherBundle(selection, pb) {
+			return nil, common.NewNonRetriableCtrlError("Rule '%s' of the ProfileBundle %s can't be disabled, only the rules of the ProfileBundle %s can",
+				selection.Name, selection.ProfileBundle, pb.GetName())
+		}
+	}
+
+	byBundle := make(map[string]*additionalTailoring)
+	addSelection := func(selection cmpv1alpha1.RuleReferenceSpec, manual bool) error {
+		a, ok := byBundle[selection.ProfileBundle]
+		if !ok {
+			otherPb := &cmpv1alpha1.ProfileBundle{}
+			pbKey := types.NamespacedName{Name: selection.ProfileBundle, Namespace: tp.Namespace}
+			if err := r.Client.Get(context.TODO(), pbKey, otherPb); err != nil {
+				if kerrors.IsNotFound(err) {
+					return common.NewNonRetriableCtrlError("fetching the ProfileBundle of rule '%s': %w", selection.Name, err)
+				}
+				return err
+			}
+			a = &additionalTailoring{
+				pb:    otherPb,
+				tp:    newAdditionalTailoredProfile(tp),
+				rules: make(map[string]*cmpv1alpha1.Rule),
+			}
+			byBundle[selection.ProfileBundle] = a
+		}
+		if _, ok := a.rules[selection.Name]; ok {
+			return common.NewNonRetriableCtrlError("Rule '%s' appears twice in selections (enableRules or disableRules or manualRules)", selection.Name)
+		}
+
+		rule := &cmpv1alpha1.Rule{}
+		ruleKey := types.NamespacedName{Name: selection.Name, Namespace: tp.Namespace}
+		if err := r.Client.Get(context.TODO(), ruleKey, rule); err != nil {
+			if kerrors.IsNotFound(err) {
+				return common.NewNonRetriableCtrlError("Fetching rule: %w", err)
+			}
+			return err
+		}
+		if !isOwnedBy(rule, a.pb) {
+			return common.NewNonRetriableCtrlError("rule %s not owned by expected ProfileBundle %s",
+				rule.GetName(), a.pb.GetName())
+		}
+		// All the rules of a ProfileBundle should be in the same data stream
+		contentFile := a.pb.GetContentFileOf(rule)
+		if len(a.rules) == 0 {
+			a.tp.Annotations[cmpv1alpha1.ContentFileAnnotation] = contentFile
+		} else if a.tp.Annotations[cmpv1alpha1.ContentFileAnnotation] != contentFile {
+			return common.NewNonRetriableCtrlError("rule %s isn't in the data stream %s of the other rules of the ProfileBundle %s",
+				rule.GetName(), a.tp.Annotations[cmpv1alpha1.ContentFileAnnotation], a.pb.GetName())
+		}
+
+		a.rules[selection.Name] = rule
+		if manual {
+			a.tp.Spec.ManualRules = append(a.tp.Spec.ManualRules, selection)
+		} else {
+			a.tp.Spec.EnableRules = append(a.tp.Spec.EnableRules, selection)
+		}
+		return nil
+	}
+
+	for _, selection := range tp.Spec.EnableRules {
+		if isFromOtherBundle(selection, pb) {
+			if err := addSelection(selection, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, selection := range tp.Spec.ManualRules {
+		if isFromOtherBundle(selection, pb) {
+			if err := addSelection(selection, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	additional := make([]*additionalTailoring, 0, len(byBundle))
+	for _, a := range byBundle {
+		if err := assertValidRuleTypes(a.rules); err != nil {
+			return nil, err
+		}
+		a.scanType = getScanTypeOfRules(a.rules)
+		additional = append(additional, a)
+	}
+	sort.Slice(additional, func(i, j int) bool {
+		return additional[i].pb.GetName() < additional[j].pb.GetName()
+	})
+	return additional, nil
+}
+
+// newAdditionalTailoredProfile returns a copy of the tailored profile with
+// none of its selections or values, which don't extend any profile, as the
+// profile it extends is in another ProfileBundle
+func newAdditionalTailoredProfile(tp *cmpv1alpha1.TailoredProfile) *cmpv1alpha1.TailoredProfile {
+	tpCopy := tp.DeepCopy()
+	tpCopy.Spec.Extends = ""
+	tpCopy.Spec.EnableRules = nil
+	tpCopy.Spec.DisableRules = nil
+	tpCopy.Spec.ManualRules = nil
+	tpCopy.Spec.SetValues = nil
+	tpCopy.Spec.CustomRules = nil
+	if tpCopy.Annotations == nil {
+		tpCopy.Annotations = make(map[string]string)
+	}
+	return tpCopy
+}
+
+// getPrimaryTailoredProfile returns a copy of the tailored profile that only
+// selects the rules of its own ProfileBundle
+func getPrimaryTailoredProfile(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle) *cmpv1alpha1.TailoredProfile {
+	filter := func(selections []cmpv1alpha1.RuleReferenceSpec) []cmpv1alpha1.RuleReferenceSpec {
+		var filtered []cmpv1alpha1.RuleReferenceSpec
+		for _, selection := range selections {
+			if !isFromOtherBundle(selection, pb) {
+				filtered = append(filtered, selection)
+			}
+		}
+		return filtered
+	}
+	tpCopy := tp.DeepCopy()
+	tpCopy.Spec.EnableRules = filter(tp.Spec.EnableRules)
+	tpCopy.Spec.ManualRules = filter(tp.Spec.ManualRules)
+	return tpCopy
+}
+
+// getAdditionalTailoringOf returns the additional tailoring of the
+// ProfileBundle owning the object, if there's one
+func getAdditionalTailoringOf(additional []*additionalTailoring, obj metav1.Object) *additionalTailoring {
+	for _, a := range additional {
+		if isOwnedBy(obj, a.pb) {
+			return a
+		}
+	}
+	return nil
+}
+
+// getScanTypeOfRules returns the type of scan that checks the rules. Rules
+// that are merely informational fit every type, so they're checked by the
+// platform scan if there are no other rules.
+func getScanTypeOfRules(rules map[string]*cmpv1alpha1.Rule) cmpv1alpha1.ComplianceScanType {
+	for _, rule := range rules {
+		if rule.CheckType == cmpv1alpha1.CheckTypeNode {
+			return cmpv1alpha1.ScanTypeNode
+		}
+	}
+	return cmpv1alpha1.ScanTypePlatform
+}
+
+// ensureAdditionalOutputs creates or updates the ConfigMaps with the
+// tailoring files of the rules of other ProfileBundles, and deletes those of
+// the ProfileBundles the tailored profile no longer selects rules from
+func (r *ReconcileTailoredProfile) ensureAdditionalOutputs(tp *cmpv1alpha1.TailoredProfile, additional []*additionalTailoring, logger logr.Logger) ([]cmpv1alpha1.TailoredProfileOutput, error) {
+	var outputs []cmpv1alpha1.TailoredProfileOutput
+	keep := map[string]bool{newTailoredProfileCM(tp).Name: true}
+	for _, a := range additional {
+		cm := newAdditionalTailoredProfileCM(tp, a.pb)
+		var err error
+		cm.Data[tailoringFile], err = xccdf.TailoredProfileToXML(a.tp, nil, a.pb, a.rules, a.variables)
+		if err != nil {
+			return nil, err
+		}
+		if err := controllerutil.SetControllerReference(tp, cm, r.Scheme); err != nil {
+			return nil, err
+		}
+
+		found := &corev1.ConfigMap{}
+		err = r.Client.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, found)
+		if kerrors.IsNotFound(err) {
+			logger.Info("Creating a new ConfigMap", "ConfigMap.Namespace", cm.Namespace, "ConfigMap.Name", cm.Name)
+			if err := r.Client.Create(context.TODO(), cm); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		} else if !reflect.DeepEqual(found.Data, cm.Data) {
+			update := found.DeepCopy()
+			update.Data = cm.Data
+			if err := r.Client.Update(context.TODO(), update); err != nil {
+				return nil, err
+			}
+		}
+
+		keep[cm.Name] = true
+		outputs = append(outputs, cmpv1alpha1.TailoredProfileOutput{
+			ProfileBundle: a.pb.GetName(),
+			ContentFile:   a.tp.Annotations[cmpv1alpha1.ContentFileAnnotation],
+			ScanType:      a.scanType,
+			ID:            xccdf.GetXCCDFProfileID(a.tp),
+			OutputRef: cmpv1alpha1.OutputRef{
+				Name:      cm.Name,
+				Namespace: cm.Namespace,
+			},
+		})
+	}
+
+	if err := r.deleteAdditionalOutputs(tp, keep); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// deleteAdditionalOutputs deletes the ConfigMaps with the tailoring files of
+// the tailored profile, except for those to keep
+func (r *ReconcileTailoredProfile) deleteAdditionalOutputs(tp *cmpv1alpha1.TailoredProfile, keep map[string]bool) error {
+	cmList := &corev1.ConfigMapList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(tp.Namespace),
+		client.MatchingLabels{cmpv1alpha1.TailoredProfileOutputLabel: tp.Name},
+	}
+	if err := r.Client.List(context.TODO(), cmList, listOpts...); err != nil {
+		return err
+	}
+	for i := range cmList.Items {
+		cm := &cmList.Items[i]
+		if keep[cm.Name] || !metav1.IsControlledBy(cm, tp) {
+			continue
+		}
+		if err := r.Client.Delete(context.TODO(), cm); err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// newAdditionalTailoredProfileCM creates the ConfigMap with the tailoring
+// file of the rules the tailored profile selects from another ProfileBundle
+func newAdditionalTailoredProfileCM(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle) *corev1.ConfigMap {
+	cm := newTailoredProfileCM(tp)
+	cm.Name = fmt.Sprintf("%s-%s-tp", tp.Name, pb.GetName())
+	return cm
+}
@@ -0,0 +1,37 @@
+package tailoredprofile
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type configMapMapper struct {
+	client.Client
+}
+
+func (t *configMapMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	tpList := v1alpha1.TailoredProfileList{}
+	err := t.List(ctx, &tpList, &client.ListOptions{Namespace: obj.GetNamespace()})
+	if err != nil {
+		return requests
+	}
+
+	for _, tp := range tpList.Items {
+		if tp.Spec.ImportFrom == nil || tp.Spec.ImportFrom.ConfigMapName != obj.GetName() {
+			continue
+		}
+		objKey := types.NamespacedName{
+			Name:      tp.GetName(),
+			Namespace: tp.GetNamespace(),
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: objKey})
+	}
+
+	return requests
+}
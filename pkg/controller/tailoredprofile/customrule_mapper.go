@@ -0,0 +1,40 @@
+package tailoredprofile
+
+import (
+	"context"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type customRuleMapper struct {
+	client.Client
+}
+
+func (t *customRuleMapper) Map(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+
+	tpList := v1alpha1.TailoredProfileList{}
+	err := t.List(ctx, &tpList, &client.ListOptions{Namespace: obj.GetNamespace()})
+	if err != nil {
+		return requests
+	}
+
+	for _, tp := range tpList.Items {
+		for _, customRule := range tp.Spec.CustomRules {
+			if customRule.Name != obj.GetName() {
+				continue
+			}
+			objKey := types.NamespacedName{
+				Name:      tp.GetName(),
+				Namespace: tp.GetNamespace(),
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: objKey})
+			break
+		}
+	}
+
+	return requests
+}
@@ -0,0 +1,118 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// cloneProfile materializes the profile the clone annotation of the
+// TailoredProfile names into its spec: instead of extending the profile, the
+// TailoredProfile enables all of its rules and sets all of its variables to
+// their current values, so that any of them can be edited. The clone
+// annotation is replaced by the cloned-from one, so the profile is only
+// cloned once.
+func (r *ReconcileTailoredProfile) cloneProfile(tp *cmpv1alpha1.TailoredProfile, profileName string, logger logr.Logger) error {
+	p := &cmpv1alpha1.Profile{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: profileName, Namespace: tp.Namespace}, p)
+	if kerrors.IsNotFound(err) {
+		return common.NewNonRetriableCtrlError("fetching the profile to clone: %w", err)
+	} else if err != nil {
+		return err
+	}
+	pb, err := r.getProfileBundleFrom("Profile", p)
+	if kerrors.IsNotFound(err) {
+		return common.NewNonRetriableCtrlError("fetching the ProfileBundle of the profile to clone: %w", err)
+	} else if err != nil {
+		return err
+	}
+
+	tpCopy := tp.DeepCopy()
+	rationale := fmt.Sprintf("Cloned from the profile %s", p.Name)
+	manual := make(map[string]bool, len(tp.Spec.ManualRules))
+	for _, selection := range tp.Spec.ManualRules {
+		manual[selection.Name] = true
+	}
+	tpCopy.Spec.Extends = ""
+	tpCopy.Spec.EnableRules = nil
+	tpCopy.Spec.DisableRules = nil
+	for _, rule := range p.Rules {
+		// The manual rules are already selected
+		if manual[string(rule)] {
+			continue
+		}
+		tpCopy.Spec.EnableRules = append(tpCopy.Spec.EnableRules, cmpv1alpha1.RuleReferenceSpec{
+			Name:      string(rule),
+			Rationale: rationale,
+		})
+	}
+
+	variables, err := r.getVariablesByID(tp.Namespace, pb, pb.GetContentFileOf(p))
+	if err != nil {
+		return err
+	}
+	var skipped []string
+	tpCopy.Spec.SetValues = nil
+	for _, id := range p.Values {
+		variable, ok := variables[string(id)]
+		if !ok || variable.Value == "" {
+			skipped = append(skipped, string(id))
+			continue
+		}
+		tpCopy.Spec.SetValues = append(tpCopy.Spec.SetValues, cmpv1alpha1.VariableValueSpec{
+			Name:      variable.Name,
+			Rationale: rationale,
+			Value:     variable.Value,
+		})
+	}
+	if len(skipped) > 0 {
+		logger.Info("Skipped the values of the profile whose variables don't exist or have no value", "Profile", p.Name, "IDs", skipped)
+		r.Eventf(tp, corev1.EventTypeWarning, "ProfileCloneSkipped",
+			"Skipped the values of the profile %s whose variables don't exist or have no value: %s", p.Name, strings.Join(skipped, ","))
+	}
+
+	anns := tpCopy.GetAnnotations()
+	delete(anns, cmpv1alpha1.CloneProfileAnnotation)
+	anns[cmpv1alpha1.ClonedFromProfileAnnotation] = p.Name
+	anns[cmpv1alpha1.ProductTypeAnnotation] = string(utils.GetScanType(p.GetAnnotations()))
+	if contentFile, ok := p.GetAnnotations()[cmpv1alpha1.ContentFileAnnotation]; ok {
+		anns[cmpv1alpha1.ContentFileAnnotation] = contentFile
+	}
+	tpCopy.SetAnnotations(anns)
+	err = r.Client.Update(context.TODO(), tpCopy)
+	if kerrors.IsInvalid(err) {
+		// e.g. the webhook rejected the current value of a variable
+		return common.NewNonRetriableCtrlError("cloning the profile: %w", err)
+	} else if err != nil {
+		return err
+	}
+	logger.Info("Cloned the profile", "Profile", p.Name)
+	return nil
+}
+
+// getVariablesByID gets the variables of a data stream file of a
+// ProfileBundle by their XCCDF IDs
+func (r *ReconcileTailoredProfile) getVariablesByID(namespace string, pb *cmpv1alpha1.ProfileBundle, contentFile string) (map[string]*cmpv1alpha1.Variable, error) {
+	variableList := &cmpv1alpha1.VariableList{}
+	if err := r.Client.List(context.TODO(), variableList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	variables := make(map[string]*cmpv1alpha1.Variable, len(variableList.Items))
+	for i := range variableList.Items {
+		variable := &variableList.Items[i]
+		if isOwnedBy(variable, pb) && pb.GetContentFileOf(variable) == contentFile {
+			variables[variable.ID] = variable
+		}
+	}
+	return variables, nil
+}
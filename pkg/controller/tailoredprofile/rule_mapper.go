@@ -23,7 +23,8 @@ func (t *ruleMapper) Map(ctx context.Context, obj client.Object) []reconcile.Req
 	}
 
 	for _, tp := range tpList.Items {
-		add := false
+		// Any rule might be selected by its severity or references
+		add := hasRuleSelectors(&tp) && tp.GetNamespace() == obj.GetNamespace()
 
 		for _, rule := range append(tp.Spec.EnableRules, append(tp.Spec.DisableRules, tp.Spec.ManualRules...)...) {
 			if rule.Name != obj.GetName() {
@@ -0,0 +1,178 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+// ruleSeverities are the severities XCCDF rules can have
+var ruleSeverities = []string{"unknown", "info", "low", "medium", "high"}
+
+// ruleSelector selects rules by their severity or by the controls they
+// implement
+type ruleSelector struct {
+	severityField  string
+	severities     map[string]bool
+	referenceField string
+	references     []controlReference
+}
+
+// controlReference matches the controls of a standard, e.g. CIS-OCP:1.2.*
+type controlReference struct {
+	reference string
+	standard  string
+	control   string
+}
+
+// hasRuleSelectors returns whether the tailored profile selects rules by
+// their severity or references
+func hasRuleSelectors(tp *cmpv1alpha1.TailoredProfile) bool {
+	return len(tp.Spec.EnableRulesBySeverity) > 0 || len(tp.Spec.DisableRulesBySeverity) > 0 ||
+		len(tp.Spec.EnableRulesByReference) > 0 || len(tp.Spec.DisableRulesByReference) > 0
+}
+
+func newRuleSelector(severityField string, severities []string, referenceField string, references []string) (*ruleSelector, error) {
+	s := &ruleSelector{
+		severityField:  severityField,
+		severities:     make(map[string]bool, len(severities)),
+		referenceField: referenceField,
+	}
+	for _, severity := range severities {
+		severity = strings.ToLower(severity)
+		if !containsValue(ruleSeverities, severity) {
+			return nil, common.NewNonRetriableCtrlError("%s has the unknown severity '%s', expected one of: %s",
+				severityField, severity, strings.Join(ruleSeverities, ","))
+		}
+		s.severities[severity] = true
+	}
+	for _, reference := range references {
+		standard, control, ok := strings.Cut(reference, ":")
+		if !ok || standard == "" || control == "" {
+			return nil, common.NewNonRetriableCtrlError("%s has the reference '%s', which isn't in the <standard>:<control> format",
+				referenceField, reference)
+		}
+		if _, err := path.Match(control, ""); err != nil {
+			return nil, common.NewNonRetriableCtrlError("%s has the reference '%s' with an invalid pattern: %s",
+				referenceField, reference, err)
+		}
+		s.references = append(s.references, controlReference{reference: reference, standard: standard, control: control})
+	}
+	return s, nil
+}
+
+// match returns why the selector selects the rule, if it does
+func (s *ruleSelector) match(rule *cmpv1alpha1.Rule) (string, bool) {
+	if s.severities[strings.ToLower(rule.Severity)] {
+		return fmt.Sprintf("%s: %s", s.severityField, strings.ToLower(rule.Severity)), true
+	}
+	for _, ref := range s.references {
+		if ref.matches(rule) {
+			return fmt.Sprintf("%s: %s", s.referenceField, ref.reference), true
+		}
+	}
+	return "", false
+}
+
+// matches returns whether the rule implements a control the reference
+// matches. As with the control labels of the rules, a base control like AC-2
+// matches its enhancements, like AC-2(1).
+func (ref *controlReference) matches(rule *cmpv1alpha1.Rule) bool {
+	for _, std := range rule.Controls {
+		if !strings.EqualFold(std.Standard, ref.standard) {
+			continue
+		}
+		for _, ctrl := range std.Controls {
+			ids := []string{ctrl}
+			if base, _, ok := strings.Cut(ctrl, "("); ok && base != "" {
+				ids = append(ids, base)
+			}
+			for _, id := range ids {
+				// The pattern was validated already
+				if ok, _ := path.Match(ref.control, id); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// expandRuleSelectors returns a copy of the tailored profile that also
+// enables and disables the rules its severities and references select, along
+// with the names of those rules. The rules the spec lists aren't selected
+// again, and the disabling selectors win over the enabling ones, e.g. to
+// enable the rules of a section of a benchmark except for the low severity
+// ones. Only the rules of the extended profile can be disabled, and only the
+// rules outside of it can be enabled.
+func (r *ReconcileTailoredProfile) expandRuleSelectors(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profile, pb *cmpv1alpha1.ProfileBundle) (*cmpv1alpha1.TailoredProfile, *cmpv1alpha1.SelectedRules, error) {
+	if !hasRuleSelectors(tp) {
+		return tp, nil, nil
+	}
+	enable, err := newRuleSelector("enableRulesBySeverity", tp.Spec.EnableRulesBySeverity,
+		"enableRulesByReference", tp.Spec.EnableRulesByReference)
+	if err != nil {
+		return nil, nil, err
+	}
+	disable, err := newRuleSelector("disableRulesBySeverity", tp.Spec.DisableRulesBySeverity,
+		"disableRulesByReference", tp.Spec.DisableRulesByReference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listed := make(map[string]bool)
+	for _, selection := range append(tp.Spec.EnableRules, append(tp.Spec.DisableRules, tp.Spec.ManualRules...)...) {
+		listed[selection.Name] = true
+	}
+	inProfile := make(map[string]bool)
+	if p != nil {
+		for _, rule := range p.Rules {
+			inProfile[string(rule)] = true
+		}
+	}
+
+	ruleList := &cmpv1alpha1.RuleList{}
+	if err := r.Client.List(context.TODO(), ruleList, client.InNamespace(tp.Namespace)); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(ruleList.Items, func(i, j int) bool {
+		return ruleList.Items[i].Name < ruleList.Items[j].Name
+	})
+
+	tpCopy := tp.DeepCopy()
+	selected := &cmpv1alpha1.SelectedRules{}
+	contentFile := pb.GetContentFileOf(tp)
+	for i := range ruleList.Items {
+		rule := &ruleList.Items[i]
+		if listed[rule.Name] || !isOwnedBy(rule, pb) || pb.GetContentFileOf(rule) != contentFile {
+			continue
+		}
+		disableReason, disabled := disable.match(rule)
+		if inProfile[rule.Name] {
+			if disabled {
+				tpCopy.Spec.DisableRules = append(tpCopy.Spec.DisableRules, newSelectedRuleReference(rule, disableReason))
+				selected.DisableRules = append(selected.DisableRules, rule.Name)
+			}
+			continue
+		}
+		if enableReason, enabled := enable.match(rule); enabled && !disabled {
+			tpCopy.Spec.EnableRules = append(tpCopy.Spec.EnableRules, newSelectedRuleReference(rule, enableReason))
+			selected.EnableRules = append(selected.EnableRules, rule.Name)
+		}
+	}
+	return tpCopy, selected, nil
+}
+
+func newSelectedRuleReference(rule *cmpv1alpha1.Rule, reason string) cmpv1alpha1.RuleReferenceSpec {
+	return cmpv1alpha1.RuleReferenceSpec{
+		Name:      rule.Name,
+		Rationale: fmt.Sprintf("Selected by %s", reason),
+	}
+}
@@ -0,0 +1,107 @@
+package tailoredprofile
+
+import (
+	"context"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+)
+
+// flattenTailoredProfile returns a copy of the tailored profile with the
+// spec of the chain of TailoredProfiles it extends merged into its own, from
+// the one at the root of the chain down to it. The root is the only one that
+// can extend a profile.
+func (r *ReconcileTailoredProfile) flattenTailoredProfile(tp *cmpv1alpha1.TailoredProfile) (*cmpv1alpha1.TailoredProfile, error) {
+	chain := []*cmpv1alpha1.TailoredProfile{tp}
+	names := []string{tp.Name}
+	for current := tp; current.Spec.ExtendsTailoredProfile != ""; {
+		if current.Spec.Extends != "" {
+			return nil, common.NewNonRetriableCtrlError("TailoredProfile '%s' can't extend both a Profile and a TailoredProfile", current.Name)
+		}
+		parentName := current.Spec.ExtendsTailoredProfile
+		names = append(names, parentName)
+		if containsValue(names[:len(names)-1], parentName) {
+			return nil, common.NewNonRetriableCtrlError("the TailoredProfiles extend each other: %s", strings.Join(names, " -> "))
+		}
+
+		parent := &cmpv1alpha1.TailoredProfile{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: parentName, Namespace: tp.Namespace}, parent)
+		if kerrors.IsNotFound(err) {
+			return nil, common.NewNonRetriableCtrlError("fetching the TailoredProfile to extend: %w", err)
+		} else if err != nil {
+			return nil, err
+		}
+		chain = append(chain, parent)
+		current = parent
+	}
+
+	spec := chain[len(chain)-1].Spec.DeepCopy()
+	for i := len(chain) - 2; i >= 0; i-- {
+		mergeTailoredProfileSpec(spec, &chain[i].Spec)
+	}
+	flattened := tp.DeepCopy()
+	flattened.Spec = *spec
+	flattened.Spec.ImportFrom = tp.Spec.ImportFrom
+	flattened.Spec.ExtendsTailoredProfile = tp.Spec.ExtendsTailoredProfile
+	return flattened, nil
+}
+
+// mergeTailoredProfileSpec merges the spec of a TailoredProfile into the one
+// of the TailoredProfile it extends. Its selections of a rule replace those
+// of the extended TailoredProfile, e.g. to disable a rule the extended one
+// enables, and so do the values it sets and its custom rules. The selectors
+// of both apply.
+func mergeTailoredProfileSpec(base, overlay *cmpv1alpha1.TailoredProfileSpec) {
+	base.Title = overlay.Title
+	base.Description = overlay.Description
+
+	selected := make(map[string]bool)
+	for _, selection := range append(overlay.EnableRules, append(overlay.DisableRules, overlay.ManualRules...)...) {
+		selected[selection.Name] = true
+	}
+	keepUnselected := func(selections []cmpv1alpha1.RuleReferenceSpec) []cmpv1alpha1.RuleReferenceSpec {
+		var kept []cmpv1alpha1.RuleReferenceSpec
+		for _, selection := range selections {
+			if !selected[selection.Name] {
+				kept = append(kept, selection)
+			}
+		}
+		return kept
+	}
+	base.EnableRules = append(keepUnselected(base.EnableRules), overlay.EnableRules...)
+	base.DisableRules = append(keepUnselected(base.DisableRules), overlay.DisableRules...)
+	base.ManualRules = append(keepUnselected(base.ManualRules), overlay.ManualRules...)
+
+	customRules := make(map[string]bool, len(overlay.CustomRules))
+	for _, selection := range overlay.CustomRules {
+		customRules[selection.Name] = true
+	}
+	var mergedCustomRules []cmpv1alpha1.RuleReferenceSpec
+	for _, selection := range base.CustomRules {
+		if !customRules[selection.Name] {
+			mergedCustomRules = append(mergedCustomRules, selection)
+		}
+	}
+	base.CustomRules = append(mergedCustomRules, overlay.CustomRules...)
+
+	values := make(map[string]bool, len(overlay.SetValues))
+	for _, value := range overlay.SetValues {
+		values[value.Name] = true
+	}
+	var mergedValues []cmpv1alpha1.VariableValueSpec
+	for _, value := range base.SetValues {
+		if !values[value.Name] {
+			mergedValues = append(mergedValues, value)
+		}
+	}
+	base.SetValues = append(mergedValues, overlay.SetValues...)
+
+	base.EnableRulesBySeverity = append(base.EnableRulesBySeverity, overlay.EnableRulesBySeverity...)
+	base.DisableRulesBySeverity = append(base.DisableRulesBySeverity, overlay.DisableRulesBySeverity...)
+	base.EnableRulesByReference = append(base.EnableRulesByReference, overlay.EnableRulesByReference...)
+	base.DisableRulesByReference = append(base.DisableRulesByReference, overlay.DisableRulesByReference...)
+}
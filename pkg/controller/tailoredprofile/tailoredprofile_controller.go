@@ -13,6 +13,7 @@ import (
 	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
 	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
 	"github.com/go-logr/logr"
+	"github.com/itchyny/gojq"
 
 	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
@@ -56,12 +57,18 @@ func newReconciler(mgr manager.Manager, met *metrics.Metrics) reconcile.Reconcil
 func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	varMapper := &variableMapper{mgr.GetClient()}
 	ruleMapper := &ruleMapper{mgr.GetClient()}
+	customRuleMapper := &customRuleMapper{mgr.GetClient()}
+	configMapMapper := &configMapMapper{mgr.GetClient()}
+	tailoredProfileMapper := &tailoredProfileMapper{mgr.GetClient()}
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("tailoredprofile-controller").
 		For(&cmpv1alpha1.TailoredProfile{}).
 		Owns(&corev1.ConfigMap{}).
 		Watches(&cmpv1alpha1.Variable{}, handler.EnqueueRequestsFromMapFunc(varMapper.Map)).
 		Watches(&cmpv1alpha1.Rule{}, handler.EnqueueRequestsFromMapFunc(ruleMapper.Map)).
+		Watches(&cmpv1alpha1.CustomRule{}, handler.EnqueueRequestsFromMapFunc(customRuleMapper.Map)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(configMapMapper.Map)).
+		Watches(&cmpv1alpha1.TailoredProfile{}, handler.EnqueueRequestsFromMapFunc(tailoredProfileMapper.Map)).
 		Complete(r)
 }
 
@@ -109,12 +116,55 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 		return reconcile.Result{}, err
 	}
 
+	if instance.Spec.ImportFrom != nil {
+		imported, importErr := r.importTailoring(instance, reqLogger)
+		if importErr != nil && !common.IsRetriable(importErr) {
+			// The tailoring file couldn't be imported. Surface the error.
+			err = r.handleTailoredProfileStatusError(instance, importErr)
+			return reconcile.Result{}, err
+		} else if importErr != nil {
+			return reconcile.Result{}, importErr
+		}
+		if imported {
+			// This update will trigger a requeue with the imported spec.
+			return reconcile.Result{}, nil
+		}
+	}
+
+	if profileName, ok := instance.GetAnnotations()[cmpv1alpha1.CloneProfileAnnotation]; ok {
+		cloneErr := r.cloneProfile(instance, profileName, reqLogger)
+		if cloneErr != nil && !common.IsRetriable(cloneErr) {
+			// The profile couldn't be cloned. Surface the error.
+			err = r.handleTailoredProfileStatusError(instance, cloneErr)
+			return reconcile.Result{}, err
+		} else if cloneErr != nil {
+			return reconcile.Result{}, cloneErr
+		}
+		// This update will trigger a requeue with the cloned spec.
+		return reconcile.Result{}, nil
+	}
+
+	// The spec of the TailoredProfile along with the ones of the
+	// TailoredProfiles it extends. Only the spec of the instance is persisted.
+	flattened := instance
+	if instance.Spec.ExtendsTailoredProfile != "" {
+		var flattenErr error
+		flattened, flattenErr = r.flattenTailoredProfile(instance)
+		if flattenErr != nil && !common.IsRetriable(flattenErr) {
+			// The chain of TailoredProfiles is invalid. Surface the error.
+			err = r.handleTailoredProfileStatusError(instance, flattenErr)
+			return reconcile.Result{}, err
+		} else if flattenErr != nil {
+			return reconcile.Result{}, flattenErr
+		}
+	}
+
 	var pb *cmpv1alpha1.ProfileBundle
 	var p *cmpv1alpha1.Profile
 
-	if instance.Spec.Extends != "" {
+	if flattened.Spec.Extends != "" {
 		var pbgetErr error
-		p, pb, pbgetErr = r.getProfileInfoFromExtends(instance)
+		p, pb, pbgetErr = r.getProfileInfoFromExtends(flattened)
 		if pbgetErr != nil && !common.IsRetriable(pbgetErr) {
 			// the Profile or ProfileBundle objects didn't exist. Surface the error.
 			err = r.handleTailoredProfileStatusError(instance, pbgetErr)
@@ -131,6 +181,11 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 			if _, ok := instance.GetAnnotations()[cmpv1alpha1.ProductTypeAnnotation]; !ok {
 				needsAnnotation = true
 			}
+			// The profile might be in a bundle with several data streams
+			contentFile, ok := p.GetAnnotations()[cmpv1alpha1.ContentFileAnnotation]
+			if ok && instance.GetAnnotations()[cmpv1alpha1.ContentFileAnnotation] != contentFile {
+				needsAnnotation = true
+			}
 		}
 
 		if needsAnnotation {
@@ -142,6 +197,9 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 
 			scanType := utils.GetScanType(p.GetAnnotations())
 			anns[cmpv1alpha1.ProductTypeAnnotation] = string(scanType)
+			if contentFile, ok := p.GetAnnotations()[cmpv1alpha1.ContentFileAnnotation]; ok {
+				anns[cmpv1alpha1.ContentFileAnnotation] = contentFile
+			}
 			tpCopy.SetAnnotations(anns)
 
 			// Set labels for the TailoredProfile
@@ -170,7 +228,7 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 		}
 
 	} else {
-		if !isValidationRequired(instance) {
+		if !isValidationRequired(flattened) {
 			// check if the TailoredProfile is empty without any extends
 			// if it is empty, we should not update the tp, and set the state of tp to Error
 			err = r.handleTailoredProfileStatusError(instance, fmt.Errorf("Custom TailoredProfile with no extends does not have any rules enabled"))
@@ -180,7 +238,8 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 			return reconcile.Result{}, nil
 		}
 		var pbgetErr error
-		pb, pbgetErr = r.getProfileBundleFromRulesOrVars(instance)
+		var contentFile string
+		pb, contentFile, pbgetErr = r.getProfileBundleFromRulesOrVars(flattened)
 		if pbgetErr != nil && !common.IsRetriable(pbgetErr) {
 			// the Profile or ProfileBundle objects didn't exist. Surface the error.
 			err = r.handleTailoredProfileStatusError(instance, pbgetErr)
@@ -209,6 +268,10 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 				}
 				tpCopy.SetAnnotations(anns)
 			}
+			if len(pb.Spec.ContentFiles) > 0 {
+				anns[cmpv1alpha1.ContentFileAnnotation] = contentFile
+				tpCopy.SetAnnotations(anns)
+			}
 			// This will trigger an update anyway
 			return r.setOwnership(tpCopy, pb)
 		}
@@ -245,7 +308,30 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 
 	}
 
-	rules, ruleErr := r.getRulesFromSelections(instance, pb)
+	// The selections of the TailoredProfile, including the rules selected by
+	// their severity or references
+	tailored, selectedRules, selectErr := r.expandRuleSelectors(flattened, p, pb)
+	if selectErr != nil && !common.IsRetriable(selectErr) {
+		// Surface the error.
+		suerr := r.handleTailoredProfileStatusError(instance, selectErr)
+		return reconcile.Result{}, suerr
+	} else if selectErr != nil {
+		return reconcile.Result{}, selectErr
+	}
+
+	validation, validationErr := r.validateReferences(tailored, p)
+	if validationErr != nil {
+		return reconcile.Result{}, validationErr
+	}
+	if len(validation.UnknownReferences) > 0 {
+		// Surface all the references that don't exist at once
+		unknownErr := common.NewNonRetriableCtrlError("the TailoredProfile references rules or variables that were not found: %s",
+			strings.Join(validation.UnknownReferences, ", "))
+		suerr := r.handleTailoredProfileValidationError(instance, unknownErr, validation)
+		return reconcile.Result{}, suerr
+	}
+
+	rules, ruleErr := r.getRulesFromSelections(tailored, pb)
 	if ruleErr != nil && !common.IsRetriable(ruleErr) {
 		// Surface the error.
 		suerr := r.handleTailoredProfileStatusError(instance, ruleErr)
@@ -260,7 +346,25 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 		return reconcile.Result{}, suerr
 	}
 
-	variables, varErr := r.getVariablesFromSelections(instance, pb)
+	additional, additionalErr := r.getAdditionalTailorings(tailored, pb)
+	if additionalErr != nil && !common.IsRetriable(additionalErr) {
+		// Surface the error.
+		suerr := r.handleTailoredProfileStatusError(instance, additionalErr)
+		return reconcile.Result{}, suerr
+	} else if additionalErr != nil {
+		return reconcile.Result{}, additionalErr
+	}
+
+	customRuleErr := r.assertValidCustomRules(tailored)
+	if customRuleErr != nil && !common.IsRetriable(customRuleErr) {
+		// Surface the error.
+		suerr := r.handleTailoredProfileStatusError(instance, customRuleErr)
+		return reconcile.Result{}, suerr
+	} else if customRuleErr != nil {
+		return reconcile.Result{}, customRuleErr
+	}
+
+	variables, varErr := r.getVariablesFromSelections(tailored, pb, additional)
 	if varErr != nil && !common.IsRetriable(varErr) {
 		// Surface the error.
 		suerr := r.handleTailoredProfileStatusError(instance, varErr)
@@ -272,12 +376,22 @@ func (r *ReconcileTailoredProfile) Reconcile(ctx context.Context, request reconc
 	// Get tailored profile config map
 	tpcm := newTailoredProfileCM(instance)
 
-	tpcm.Data[tailoringFile], err = xccdf.TailoredProfileToXML(instance, p, pb, rules, variables)
+	tpcm.Data[tailoringFile], err = xccdf.TailoredProfileToXML(getPrimaryTailoredProfile(tailored, pb), p, pb, rules, variables)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	return r.ensureOutputObject(instance, tpcm, reqLogger)
+	outputs, err := r.ensureAdditionalOutputs(instance, additional, reqLogger)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	resolved := &resolvedStatus{
+		additionalOutputs: outputs,
+		selectedRules:     selectedRules,
+		validation:        validation,
+	}
+	return r.ensureOutputObject(instance, tpcm, resolved, reqLogger)
 }
 
 // generateWarningMessage generates a warning message for the user
@@ -367,7 +481,7 @@ func (r *ReconcileTailoredProfile) handleRulePruning(
 		}
 	}
 
-	if v1alphaTp.Spec.Extends == "" && len(v1alphaTpCP.Spec.DisableRules) == 0 && len(v1alphaTpCP.Spec.EnableRules) == 0 {
+	if v1alphaTp.Spec.Extends == "" && v1alphaTp.Spec.ExtendsTailoredProfile == "" && len(v1alphaTpCP.Spec.DisableRules) == 0 && len(v1alphaTpCP.Spec.EnableRules) == 0 {
 		errorMsg := "TailoredProfile does not have any rules left after removing migrated rules and it does not extend any profile"
 		v1alphaTpCP.Status.State = cmpv1alpha1.TailoredProfileStateError
 		v1alphaTpCP.Status.ErrorMessage = errorMsg
@@ -388,9 +502,9 @@ func (r *ReconcileTailoredProfile) handleRulePruning(
 
 func isValidationRequired(tp *cmpv1alpha1.TailoredProfile) bool {
 	if tp.Spec.Extends != "" {
-		return tp.Spec.DisableRules != nil || tp.Spec.EnableRules != nil || tp.Spec.ManualRules != nil || tp.Spec.SetValues != nil
+		return tp.Spec.DisableRules != nil || tp.Spec.EnableRules != nil || tp.Spec.ManualRules != nil || tp.Spec.SetValues != nil || hasRuleSelectors(tp)
 	}
-	return tp.Spec.EnableRules != nil || tp.Spec.ManualRules != nil || tp.Spec.SetValues != nil
+	return tp.Spec.EnableRules != nil || tp.Spec.ManualRules != nil || tp.Spec.SetValues != nil || hasRuleSelectors(tp)
 }
 
 // getMigratedRules get list of rules and check if it has RuleLastCheckTypeChangedAnnotationKey annotation
@@ -444,10 +558,21 @@ func (r *ReconcileTailoredProfile) getProfileInfoFromExtends(tp *cmpv1alpha1.Tai
 	return p, pb, nil
 }
 
-// getProfileBundleFromRulesOrVars gets the ProfileBundle where the rules come from
-func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alpha1.TailoredProfile) (*cmpv1alpha1.ProfileBundle, error) {
+// getProfileBundleFromRulesOrVars gets the ProfileBundle where the rules come from,
+// along with the data stream file of the bundle they are in
+func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alpha1.TailoredProfile) (*cmpv1alpha1.ProfileBundle, string, error) {
 	var ruleToBeChecked *cmpv1alpha1.Rule
+	// The rules that aren't qualified with a ProfileBundle are in the one of
+	// the tailored profile, so they're checked first
+	var qualified, unqualified []cmpv1alpha1.RuleReferenceSpec
 	for _, selection := range append(tp.Spec.EnableRules, append(tp.Spec.DisableRules, tp.Spec.ManualRules...)...) {
+		if selection.ProfileBundle != "" {
+			qualified = append(qualified, selection)
+		} else {
+			unqualified = append(unqualified, selection)
+		}
+	}
+	for _, selection := range append(unqualified, qualified...) {
 		rule := &cmpv1alpha1.Rule{}
 		ruleKey := types.NamespacedName{Name: selection.Name, Namespace: tp.Namespace}
 		geterr := r.Client.Get(context.TODO(), ruleKey, rule)
@@ -456,7 +581,7 @@ func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alph
 			if kerrors.IsNotFound(geterr) {
 				continue
 			}
-			return nil, geterr
+			return nil, "", geterr
 		}
 		ruleToBeChecked = rule
 		break
@@ -464,10 +589,10 @@ func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alph
 	if ruleToBeChecked != nil {
 		pb, err := r.getProfileBundleFrom("Rule", ruleToBeChecked)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
-		return pb, nil
+		return pb, pb.GetContentFileOf(ruleToBeChecked), nil
 	}
 
 	var varToBeChecked *cmpv1alpha1.Variable
@@ -480,7 +605,7 @@ func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alph
 			if kerrors.IsNotFound(err) {
 				continue
 			}
-			return nil, err
+			return nil, "", err
 		}
 
 		varToBeChecked = variable
@@ -490,19 +615,23 @@ func (r *ReconcileTailoredProfile) getProfileBundleFromRulesOrVars(tp *cmpv1alph
 	if varToBeChecked != nil {
 		pb, err := r.getProfileBundleFrom("Variable", varToBeChecked)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
-		return pb, nil
+		return pb, pb.GetContentFileOf(varToBeChecked), nil
 	}
 
-	return nil, common.NewNonRetriableCtrlError("Unable to get ProfileBundle from selected rules and variables")
+	return nil, "", common.NewNonRetriableCtrlError("Unable to get ProfileBundle from selected rules and variables")
 }
 
 func (r *ReconcileTailoredProfile) getRulesFromSelections(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle) (map[string]*cmpv1alpha1.Rule, error) {
 	rules := make(map[string]*cmpv1alpha1.Rule, len(tp.Spec.EnableRules)+len(tp.Spec.DisableRules)+len(tp.Spec.ManualRules))
 
 	for _, selection := range append(tp.Spec.EnableRules, append(tp.Spec.DisableRules, tp.Spec.ManualRules...)...) {
+		if isFromOtherBundle(selection, pb) {
+			// These are checked along with the other rules of their ProfileBundle
+			continue
+		}
 		_, ok := rules[selection.Name]
 		if ok {
 			return nil, common.NewNonRetriableCtrlError("Rule '%s' appears twice in selections (enableRules or disableRules or manualRules)", selection.Name)
@@ -528,7 +657,10 @@ func (r *ReconcileTailoredProfile) getRulesFromSelections(tp *cmpv1alpha1.Tailor
 	return rules, nil
 }
 
-func (r *ReconcileTailoredProfile) getVariablesFromSelections(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle) ([]*cmpv1alpha1.Variable, error) {
+// getVariablesFromSelections gets the variables the tailored profile sets
+// the values of. The variables of the other ProfileBundles it selects rules
+// from are added to their additional tailoring instead.
+func (r *ReconcileTailoredProfile) getVariablesFromSelections(tp *cmpv1alpha1.TailoredProfile, pb *cmpv1alpha1.ProfileBundle, additional []*additionalTailoring) ([]*cmpv1alpha1.Variable, error) {
 	variableList := []*cmpv1alpha1.Variable{}
 	for _, setValues := range tp.Spec.SetValues {
 		variable := &cmpv1alpha1.Variable{}
@@ -541,10 +673,15 @@ func (r *ReconcileTailoredProfile) getVariablesFromSelections(tp *cmpv1alpha1.Ta
 			return nil, err
 		}
 
-		// All variables should be part of the same ProfileBundle
+		// All variables should be part of the same ProfileBundle as the
+		// rules
+		var a *additionalTailoring
 		if !isOwnedBy(variable, pb) {
-			return nil, common.NewNonRetriableCtrlError("variable %s not owned by expected ProfileBundle %s",
-				variable.GetName(), pb.GetName())
+			a = getAdditionalTailoringOf(additional, variable)
+			if a == nil {
+				return nil, common.NewNonRetriableCtrlError("variable %s not owned by expected ProfileBundle %s",
+					variable.GetName(), pb.GetName())
+			}
 		}
 
 		// try setting the variable, this also validates the value
@@ -553,12 +690,16 @@ func (r *ReconcileTailoredProfile) getVariablesFromSelections(tp *cmpv1alpha1.Ta
 			return nil, common.NewNonRetriableCtrlError("setting variable: %s", err)
 		}
 
+		if a != nil {
+			a.variables = append(a.variables, variable)
+			continue
+		}
 		variableList = append(variableList, variable)
 	}
 	return variableList, nil
 }
 
-func (r *ReconcileTailoredProfile) updateTailoredProfileStatusReady(tp *cmpv1alpha1.TailoredProfile, out metav1.Object) error {
+func (r *ReconcileTailoredProfile) updateTailoredProfileStatusReady(tp *cmpv1alpha1.TailoredProfile, out metav1.Object, resolved *resolvedStatus) error {
 	// Never update the original (update the copy)
 	tpCopy := tp.DeepCopy()
 	tpCopy.Status.State = cmpv1alpha1.TailoredProfileStateReady
@@ -568,22 +709,30 @@ func (r *ReconcileTailoredProfile) updateTailoredProfileStatusReady(tp *cmpv1alp
 		Namespace: out.GetNamespace(),
 	}
 	tpCopy.Status.ID = xccdf.GetXCCDFProfileID(tp)
+	resolved.applyTo(&tpCopy.Status)
 	return r.Client.Status().Update(context.TODO(), tpCopy)
 }
 
 func (r *ReconcileTailoredProfile) handleTailoredProfileStatusError(tp *cmpv1alpha1.TailoredProfile, err error) error {
+	return r.handleTailoredProfileValidationError(tp, err, nil)
+}
+
+// handleTailoredProfileValidationError surfaces the error, along with what
+// the references of the TailoredProfile resolved to, if it's known
+func (r *ReconcileTailoredProfile) handleTailoredProfileValidationError(tp *cmpv1alpha1.TailoredProfile, err error, validation *cmpv1alpha1.TailoredProfileValidation) error {
 	if delErr := r.deleteOutputObject(tp); delErr != nil {
 		return delErr
 	}
 
-	return r.updateTailoredProfileStatusError(tp, err)
+	return r.updateTailoredProfileStatusError(tp, err, validation)
 }
 
-func (r *ReconcileTailoredProfile) updateTailoredProfileStatusError(tp *cmpv1alpha1.TailoredProfile, err error) error {
+func (r *ReconcileTailoredProfile) updateTailoredProfileStatusError(tp *cmpv1alpha1.TailoredProfile, err error, validation *cmpv1alpha1.TailoredProfileValidation) error {
 	// Never update the original (update the copy)
 	tpCopy := tp.DeepCopy()
 	tpCopy.Status.State = cmpv1alpha1.TailoredProfileStateError
 	tpCopy.Status.ErrorMessage = err.Error()
+	tpCopy.Status.Validation = validation
 	return r.Client.Status().Update(context.TODO(), tpCopy)
 }
 
@@ -616,10 +765,10 @@ func (r *ReconcileTailoredProfile) deleteOutputObject(tp *cmpv1alpha1.TailoredPr
 		return err
 	}
 
-	return nil
+	return r.deleteAdditionalOutputs(tp, nil)
 }
 
-func (r *ReconcileTailoredProfile) ensureOutputObject(tp *cmpv1alpha1.TailoredProfile, tpcm *corev1.ConfigMap, logger logr.Logger) (reconcile.Result, error) {
+func (r *ReconcileTailoredProfile) ensureOutputObject(tp *cmpv1alpha1.TailoredProfile, tpcm *corev1.ConfigMap, resolved *resolvedStatus, logger logr.Logger) (reconcile.Result, error) {
 	// Set TailoredProfile instance as the owner and controller
 	if err := controllerutil.SetControllerReference(tp, tpcm, r.Scheme); err != nil {
 		return reconcile.Result{}, err
@@ -630,7 +779,7 @@ func (r *ReconcileTailoredProfile) ensureOutputObject(tp *cmpv1alpha1.TailoredPr
 	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: tpcm.Name, Namespace: tpcm.Namespace}, found)
 	if err != nil && kerrors.IsNotFound(err) {
 		// update status
-		err = r.updateTailoredProfileStatusReady(tp, tpcm)
+		err = r.updateTailoredProfileStatusReady(tp, tpcm, resolved)
 		if err != nil {
 			fmt.Printf("Couldn't update TailoredProfile status: %v\n", err)
 			return reconcile.Result{}, err
@@ -658,6 +807,14 @@ func (r *ReconcileTailoredProfile) ensureOutputObject(tp *cmpv1alpha1.TailoredPr
 		return reconcile.Result{}, err
 	}
 
+	// What the selections resolve to might have changed, e.g. the rules of
+	// other ProfileBundles
+	if resolved.differsFrom(&tp.Status) {
+		if err := r.updateTailoredProfileStatusReady(tp, tpcm, resolved); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	logger.Info("Skip reconcile: ConfigMap already exists and is up-to-date", "ConfigMap.Namespace", found.Namespace, "ConfigMap.Name", found.Name)
 	return reconcile.Result{}, nil
 }
@@ -682,7 +839,7 @@ func getProfileBundleReference(objtype string, o metav1.Object) (*metav1.OwnerRe
 // newTailoredProfileCM creates a tailored profile XML inside a configmap
 func newTailoredProfileCM(tp *cmpv1alpha1.TailoredProfile) *corev1.ConfigMap {
 	labels := map[string]string{
-		"tailored-profile": tp.Name,
+		cmpv1alpha1.TailoredProfileOutputLabel: tp.Name,
 	}
 	return &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
@@ -750,3 +907,43 @@ func assertValidRuleTypes(rules map[string]*cmpv1alpha1.Rule) error {
 	}
 	return nil
 }
+
+// assertValidCustomRules verifies that the custom rules of the tailored
+// profile exist and that their assertions can be evaluated. The assertions
+// are evaluated by the platform scan, so custom rules can't be added to node
+// profiles.
+func (r *ReconcileTailoredProfile) assertValidCustomRules(tp *cmpv1alpha1.TailoredProfile) error {
+	if len(tp.Spec.CustomRules) == 0 {
+		return nil
+	}
+	if utils.GetScanType(tp.GetAnnotations()) != cmpv1alpha1.ScanTypePlatform {
+		return common.NewNonRetriableCtrlError("Custom rules can only be added to Platform profiles")
+	}
+
+	seen := make(map[string]bool, len(tp.Spec.CustomRules))
+	for _, selection := range tp.Spec.CustomRules {
+		if seen[selection.Name] {
+			return common.NewNonRetriableCtrlError("Custom rule '%s' appears twice in customRules", selection.Name)
+		}
+		seen[selection.Name] = true
+
+		customRule := &cmpv1alpha1.CustomRule{}
+		key := types.NamespacedName{Name: selection.Name, Namespace: tp.GetNamespace()}
+		if err := r.Client.Get(context.TODO(), key, customRule); err != nil {
+			if kerrors.IsNotFound(err) {
+				return common.NewNonRetriableCtrlError("Fetching custom rule: %w", err)
+			}
+			return err
+		}
+		var err error
+		if customRule.Spec.AssertionLanguage == cmpv1alpha1.CustomRuleAssertionLanguageCEL {
+			_, err = utils.CompileCELAssertion(customRule.Spec.Assertion)
+		} else {
+			_, err = gojq.Parse(customRule.Spec.Assertion)
+		}
+		if err != nil {
+			return common.NewNonRetriableCtrlError("Custom rule '%s' has an invalid assertion: %v", selection.Name, err)
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package tailoredprofile
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -458,6 +459,514 @@ var _ = Describe("TailoredprofileController", func() {
 					`variable .* not owned by expected ProfileBundle .*`))
 			})
 		})
+
+		Context("with rules qualified with another bundle", func() {
+			BeforeEach(func() {
+				tp := &compv1alpha1.TailoredProfile{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      tpName,
+						Namespace: namespace,
+					},
+					Spec: compv1alpha1.TailoredProfileSpec{
+						Extends: profileName,
+						EnableRules: []compv1alpha1.RuleReferenceSpec{
+							{
+								Name:      "rule-3",
+								Rationale: "Why not",
+							},
+							{
+								Name:          "rule-5",
+								Rationale:     "Why not",
+								ProfileBundle: "pb-2",
+							},
+						},
+						ManualRules: []compv1alpha1.RuleReferenceSpec{
+							{
+								Name:          "rule-7",
+								Rationale:     "Why not",
+								ProfileBundle: "pb-2",
+							},
+						},
+						SetValues: []compv1alpha1.VariableValueSpec{
+							{
+								Name:      "var-5",
+								Rationale: "Why not",
+								Value:     "1234",
+							},
+						},
+					},
+				}
+
+				createErr := r.Client.Create(ctx, tp)
+				Expect(createErr).To(BeNil())
+			})
+			It("generates a tailoring file per bundle", func() {
+				tpKey := types.NamespacedName{
+					Name:      tpName,
+					Namespace: namespace,
+				}
+				tpReq := reconcile.Request{}
+				tpReq.Name = tpName
+				tpReq.Namespace = namespace
+
+				By("Reconciling twice")
+				_, err := r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+				_, err = r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+
+				tp := &compv1alpha1.TailoredProfile{}
+				geterr := r.Client.Get(ctx, tpKey, tp)
+				Expect(geterr).To(BeNil())
+
+				By("Has the appropriate status")
+				Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+				Expect(tp.Status.AdditionalOutputs).To(HaveLen(1))
+				output := tp.Status.AdditionalOutputs[0]
+				Expect(output.ProfileBundle).To(Equal("pb-2"))
+				Expect(output.ScanType).To(Equal(compv1alpha1.ScanTypePlatform))
+				Expect(output.ID).To(Equal(tp.Status.ID))
+				Expect(output.OutputRef.Name).To(Equal(tpName + "-pb-2-tp"))
+
+				By("Only selecting the rules of the extended profile's bundle in its ConfigMap")
+				cm := &corev1.ConfigMap{}
+				geterr = r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)
+				Expect(geterr).To(BeNil())
+				data := cm.Data["tailoring.xml"]
+				Expect(data).To(ContainSubstring(`extends="profile_1"`))
+				Expect(data).To(ContainSubstring(`select idref="rule_3" selected="true"`))
+				Expect(data).NotTo(ContainSubstring(`rule_5`))
+				Expect(data).NotTo(ContainSubstring(`var_5`))
+
+				By("Selecting the rules of the other bundle in their own ConfigMap")
+				geterr = r.Client.Get(ctx, types.NamespacedName{Name: output.OutputRef.Name, Namespace: namespace}, cm)
+				Expect(geterr).To(BeNil())
+				Expect(cm.GetLabels()).To(HaveKeyWithValue(compv1alpha1.TailoredProfileOutputLabel, tpName))
+				data = cm.Data["tailoring.xml"]
+				Expect(data).NotTo(ContainSubstring(`extends=`))
+				Expect(data).NotTo(ContainSubstring(`rule_3`))
+				Expect(data).To(ContainSubstring(`select idref="rule_5" selected="true"`))
+				Expect(data).To(ContainSubstring(`select idref="rule_7" selected="true"`))
+				Expect(data).To(ContainSubstring(`set-value idref="var_5"`))
+
+				By("Removing the ConfigMap once no rules of the other bundle are selected")
+				tp.Spec.EnableRules = tp.Spec.EnableRules[:1]
+				tp.Spec.ManualRules = nil
+				tp.Spec.SetValues = nil
+				Expect(r.Client.Update(ctx, tp)).To(Succeed())
+				_, err = r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+
+				geterr = r.Client.Get(ctx, types.NamespacedName{Name: output.OutputRef.Name, Namespace: namespace}, cm)
+				Expect(kerrors.IsNotFound(geterr)).To(BeTrue())
+				geterr = r.Client.Get(ctx, tpKey, tp)
+				Expect(geterr).To(BeNil())
+				Expect(tp.Status.AdditionalOutputs).To(BeEmpty())
+			})
+		})
+
+		Context("with a disabled rule qualified with another bundle", func() {
+			BeforeEach(func() {
+				tp := &compv1alpha1.TailoredProfile{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      tpName,
+						Namespace: namespace,
+					},
+					Spec: compv1alpha1.TailoredProfileSpec{
+						Extends: profileName,
+						DisableRules: []compv1alpha1.RuleReferenceSpec{
+							{
+								Name:          "rule-5",
+								Rationale:     "Why not",
+								ProfileBundle: "pb-2",
+							},
+						},
+					},
+				}
+
+				createErr := r.Client.Create(ctx, tp)
+				Expect(createErr).To(BeNil())
+			})
+			It("reports an error", func() {
+				tpReq := reconcile.Request{}
+				tpReq.Name = tpName
+				tpReq.Namespace = namespace
+
+				_, err := r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+				_, err = r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+
+				tp := &compv1alpha1.TailoredProfile{}
+				geterr := r.Client.Get(ctx, types.NamespacedName{Name: tpName, Namespace: namespace}, tp)
+				Expect(geterr).To(BeNil())
+				Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+				Expect(tp.Status.ErrorMessage).To(ContainSubstring("of the ProfileBundle pb-2 can't be disabled"))
+			})
+		})
+	})
+
+	When("selecting rules by their severity or references", func() {
+		var tpName = "tailoring"
+		BeforeEach(func() {
+			setRule := func(name, severity string, controls ...string) {
+				rule := &compv1alpha1.Rule{}
+				Expect(r.Client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, rule)).To(Succeed())
+				rule.Severity = severity
+				if len(controls) > 0 {
+					rule.Controls = []compv1alpha1.RuleControls{{Standard: "CIS-OCP", Controls: controls}}
+				}
+				Expect(r.Client.Update(ctx, rule)).To(Succeed())
+			}
+			setRule("rule-1", "medium", "1.1.1")
+			setRule("rule-2", "low", "1.1.2")
+			setRule("rule-3", "high", "1.2.1")
+			setRule("rule-4", "low", "1.2.2")
+			setRule("rule-5", "high", "1.2.3")
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tpName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends:                profileName,
+					EnableRulesByReference: []string{"CIS-OCP:1.2.*"},
+					DisableRulesBySeverity: []string{"low"},
+				},
+			}
+			Expect(r.Client.Create(ctx, tp)).To(Succeed())
+		})
+
+		reconcileTwice := func() *compv1alpha1.TailoredProfile {
+			tpReq := reconcile.Request{}
+			tpReq.Name = tpName
+			tpReq.Namespace = namespace
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+			_, err = r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tpName, Namespace: namespace}, tp)).To(Succeed())
+			return tp
+		}
+
+		It("expands the selectors into the rules of the bundle", func() {
+			tp := reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+			Expect(tp.Status.SelectedRules).NotTo(BeNil())
+			Expect(tp.Status.SelectedRules.EnableRules).To(Equal([]string{"rule-3"}))
+			Expect(tp.Status.SelectedRules.DisableRules).To(Equal([]string{"rule-2"}))
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)).To(Succeed())
+			data := cm.Data["tailoring.xml"]
+			Expect(data).To(ContainSubstring(`select idref="rule_3" selected="true"`))
+			Expect(data).To(ContainSubstring(`Selected by enableRulesByReference: CIS-OCP:1.2.*`))
+			Expect(data).To(ContainSubstring(`select idref="rule_2" selected="false"`))
+			Expect(data).To(ContainSubstring(`Selected by disableRulesBySeverity: low`))
+			Expect(data).NotTo(ContainSubstring(`rule_4`))
+			Expect(data).NotTo(ContainSubstring(`rule_5`))
+		})
+
+		It("doesn't select the rules the spec lists", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tpName, Namespace: namespace}, tp)).To(Succeed())
+			tp.Spec.EnableRules = []compv1alpha1.RuleReferenceSpec{{Name: "rule-4", Rationale: "Needed anyway"}}
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+			Expect(tp.Status.SelectedRules.EnableRules).To(Equal([]string{"rule-3"}))
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)).To(Succeed())
+			Expect(cm.Data["tailoring.xml"]).To(ContainSubstring(`select idref="rule_4" selected="true"`))
+		})
+
+		It("reports an error for unknown severities and malformed references", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tpName, Namespace: namespace}, tp)).To(Succeed())
+			tp.Spec.DisableRulesBySeverity = []string{"trivial"}
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("disableRulesBySeverity has the unknown severity 'trivial'"))
+
+			tp.Spec.DisableRulesBySeverity = nil
+			tp.Spec.EnableRulesByReference = []string{"1.2.*"}
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("isn't in the <standard>:<control> format"))
+		})
+	})
+
+	When("cloning a profile", func() {
+		var tpName = "my-profile-clone"
+		BeforeEach(func() {
+			p := &compv1alpha1.Profile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: profileName, Namespace: namespace}, p)).To(Succeed())
+			p.Values = []compv1alpha1.ProfileValue{"var_1", "var_2", "var_missing"}
+			Expect(r.Client.Update(ctx, p)).To(Succeed())
+
+			v := &compv1alpha1.Variable{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "var-1", Namespace: namespace}, v)).To(Succeed())
+			v.Value = "42"
+			Expect(r.Client.Update(ctx, v)).To(Succeed())
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tpName,
+					Namespace: namespace,
+					Annotations: map[string]string{
+						compv1alpha1.CloneProfileAnnotation: profileName,
+					},
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Title:       "Clone",
+					Description: "A clone",
+					ManualRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "rule-2",
+							Rationale: "Checked by hand",
+						},
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, tp)).To(Succeed())
+		})
+
+		It("materializes the rules and values of the profile", func() {
+			tpKey := types.NamespacedName{Name: tpName, Namespace: namespace}
+			tpReq := reconcile.Request{NamespacedName: tpKey}
+
+			By("Cloning the profile")
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.GetAnnotations()).NotTo(HaveKey(compv1alpha1.CloneProfileAnnotation))
+			Expect(tp.GetAnnotations()).To(HaveKeyWithValue(compv1alpha1.ClonedFromProfileAnnotation, profileName))
+			Expect(tp.Spec.Extends).To(BeEmpty())
+			Expect(tp.Spec.EnableRules).To(Equal([]compv1alpha1.RuleReferenceSpec{
+				{Name: "rule-1", Rationale: "Cloned from the profile my-profile"},
+			}))
+			Expect(tp.Spec.ManualRules).To(HaveLen(1))
+			Expect(tp.Spec.SetValues).To(Equal([]compv1alpha1.VariableValueSpec{
+				{Name: "var-1", Rationale: "Cloned from the profile my-profile", Value: "42"},
+			}))
+
+			By("Generating a tailoring file that doesn't extend the profile")
+			for i := 0; i < 2; i++ {
+				_, err = r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+			}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)).To(Succeed())
+			data := cm.Data["tailoring.xml"]
+			Expect(data).NotTo(ContainSubstring(`extends=`))
+			Expect(data).To(ContainSubstring(`select idref="rule_1" selected="true"`))
+			Expect(data).To(ContainSubstring(`select idref="rule_2" selected="true"`))
+			Expect(data).To(ContainSubstring(`set-value idref="var_1">42<`))
+		})
+
+		It("reports an error for a missing profile", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			tpKey := types.NamespacedName{Name: tpName, Namespace: namespace}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			tp.Annotations[compv1alpha1.CloneProfileAnnotation] = "missing-profile"
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tpKey})
+			Expect(err).To(BeNil())
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("fetching the profile to clone"))
+		})
+	})
+
+	When("validating the selections", func() {
+		var tpName = "tailoring"
+		BeforeEach(func() {
+			v := &compv1alpha1.Variable{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "var-3", Namespace: namespace}, v)).To(Succeed())
+			v.Value = "7"
+			Expect(r.Client.Update(ctx, v)).To(Succeed())
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tpName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends: profileName,
+					EnableRules: []compv1alpha1.RuleReferenceSpec{
+						{Name: "rule-1", Rationale: "Already there"},
+						{Name: "rule-3", Rationale: "Why not"},
+					},
+					DisableRules: []compv1alpha1.RuleReferenceSpec{
+						{Name: "rule-2", Rationale: "Why not"},
+						{Name: "rule-4", Rationale: "Not there"},
+					},
+					SetValues: []compv1alpha1.VariableValueSpec{
+						{Name: "var-1", Rationale: "Why not", Value: "5"},
+						{Name: "var-3", Rationale: "The default", Value: "7"},
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, tp)).To(Succeed())
+		})
+
+		reconcileTwice := func() *compv1alpha1.TailoredProfile {
+			tpKey := types.NamespacedName{Name: tpName, Namespace: namespace}
+			for i := 0; i < 2; i++ {
+				_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tpKey})
+				Expect(err).To(BeNil())
+			}
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			return tp
+		}
+
+		It("reports the enabled rules and the selections that are no-ops", func() {
+			tp := reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+			Expect(tp.Status.Validation).To(Equal(&compv1alpha1.TailoredProfileValidation{
+				EnabledRuleCount: 2,
+				NoOpSelections: []string{
+					"enableRules: rule-1 is already enabled by the profile",
+					"disableRules: rule-4 isn't enabled by the profile",
+					"setValues: var-3 is already set to 7",
+				},
+			}))
+		})
+
+		It("reports all the unknown references", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tpName, Namespace: namespace}, tp)).To(Succeed())
+			tp.Spec.ManualRules = []compv1alpha1.RuleReferenceSpec{{Name: "missing-rule", Rationale: "Typo"}}
+			tp.Spec.SetValues = append(tp.Spec.SetValues, compv1alpha1.VariableValueSpec{Name: "missing-var", Value: "1"})
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("manualRules: missing-rule, setValues: missing-var"))
+			Expect(tp.Status.Validation).NotTo(BeNil())
+			Expect(tp.Status.Validation.UnknownReferences).To(Equal([]string{"manualRules: missing-rule", "setValues: missing-var"}))
+		})
+	})
+
+	When("extending another TailoredProfile", func() {
+		var baselineName = "baseline"
+		var overlayName = "overlay"
+		BeforeEach(func() {
+			baseline := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      baselineName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends:     profileName,
+					Title:       "Baseline",
+					Description: "The baseline of the organization",
+					EnableRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "rule-3",
+							Rationale: "Required by the organization",
+						},
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, baseline)).To(Succeed())
+
+			overlay := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      overlayName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					ExtendsTailoredProfile: baselineName,
+					Title:                  "Overlay",
+					Description:            "The overlay of a team",
+					DisableRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "rule-3",
+							Rationale: "Not applicable to the team",
+						},
+					},
+					EnableRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "rule-4",
+							Rationale: "Required by the team",
+						},
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, overlay)).To(Succeed())
+		})
+
+		It("applies its selections on top of the extended one", func() {
+			tpKey := types.NamespacedName{Name: overlayName, Namespace: namespace}
+			tpReq := reconcile.Request{NamespacedName: tpKey}
+			for i := 0; i < 3; i++ {
+				_, err := r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+			}
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+			By("Not persisting the flattened spec")
+			Expect(tp.Spec.Extends).To(BeEmpty())
+			Expect(tp.Spec.EnableRules).To(HaveLen(1))
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)).To(Succeed())
+			data := cm.Data["tailoring.xml"]
+			Expect(data).To(ContainSubstring(`extends="profile_1"`))
+			Expect(data).To(ContainSubstring(`select idref="rule_3" selected="false"`))
+			Expect(data).To(ContainSubstring(`select idref="rule_4" selected="true"`))
+			Expect(data).To(ContainSubstring("Overlay"))
+		})
+
+		It("reports an error for a cycle", func() {
+			baseline := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: baselineName, Namespace: namespace}, baseline)).To(Succeed())
+			baseline.Spec.Extends = ""
+			baseline.Spec.ExtendsTailoredProfile = overlayName
+			Expect(r.Client.Update(ctx, baseline)).To(Succeed())
+
+			tpKey := types.NamespacedName{Name: overlayName, Namespace: namespace}
+			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tpKey})
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("overlay -> baseline -> overlay"))
+		})
+
+		It("reports an error when extending a profile too", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			tpKey := types.NamespacedName{Name: overlayName, Namespace: namespace}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			tp.Spec.Extends = profileName
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tpKey})
+			Expect(err).To(BeNil())
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("can't extend both a Profile and a TailoredProfile"))
+		})
 	})
 
 	When("Trying to reference an unexistent rule", func() {
@@ -546,6 +1055,116 @@ var _ = Describe("TailoredprofileController", func() {
 		})
 	})
 
+	When("adding custom rules", func() {
+		var (
+			tpName = "tailoring"
+			tpKey  = types.NamespacedName{Name: tpName, Namespace: namespace}
+			tpReq  = reconcile.Request{NamespacedName: tpKey}
+		)
+
+		BeforeEach(func() {
+			customRule := &compv1alpha1.CustomRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "etcd-encrypted",
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.CustomRuleSpec{
+					Title:        "Etcd is encrypted",
+					ResourcePath: "/apis/config.openshift.io/v1/apiservers/cluster",
+					Assertion:    `.spec.encryption.type == "aescbc"`,
+				},
+			}
+			Expect(r.Client.Create(ctx, customRule)).To(Succeed())
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      tpName,
+					Namespace: namespace,
+				},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Extends: profileName,
+					CustomRules: []compv1alpha1.RuleReferenceSpec{
+						{
+							Name:      "etcd-encrypted",
+							Rationale: "Required by our policy",
+						},
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, tp)).To(Succeed())
+		})
+
+		reconcileTwice := func() *compv1alpha1.TailoredProfile {
+			By("Reconciling the first time")
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			By("Reconciling a second time")
+			_, err = r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			return tp
+		}
+
+		It("succeeds with existing custom rules", func() {
+			tp := reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+		})
+
+		It("reports an error for a missing custom rule", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			tp.Spec.CustomRules = append(tp.Spec.CustomRules, compv1alpha1.RuleReferenceSpec{Name: "unexistent"})
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("not found"))
+		})
+
+		It("reports an error for an invalid assertion", func() {
+			customRule := &compv1alpha1.CustomRule{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "etcd-encrypted", Namespace: namespace}, customRule)).To(Succeed())
+			customRule.Spec.Assertion = ".spec["
+			Expect(r.Client.Update(ctx, customRule)).To(Succeed())
+
+			tp := reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("invalid assertion"))
+		})
+
+		It("validates the assertions written in CEL", func() {
+			customRule := &compv1alpha1.CustomRule{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "etcd-encrypted", Namespace: namespace}, customRule)).To(Succeed())
+			customRule.Spec.AssertionLanguage = compv1alpha1.CustomRuleAssertionLanguageCEL
+			customRule.Spec.Assertion = `resource.spec.encryption.type == "aescbc"`
+			Expect(r.Client.Update(ctx, customRule)).To(Succeed())
+
+			tp := reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+
+			customRule.Spec.Assertion = `size(resource.spec.encryption.type) + 1`
+			Expect(r.Client.Update(ctx, customRule)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("instead of a boolean"))
+		})
+
+		It("reports an error for node profiles", func() {
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			tp.SetAnnotations(map[string]string{compv1alpha1.ProductTypeAnnotation: string(compv1alpha1.ScanTypeNode)})
+			Expect(r.Client.Update(ctx, tp)).To(Succeed())
+
+			tp = reconcileTwice()
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("only be added to Platform profiles"))
+		})
+	})
+
 	When("Trying to reference an unexistent variable", func() {
 		var tpName = "tailoring"
 		BeforeEach(func() {
@@ -1250,4 +1869,141 @@ var _ = Describe("TailoredprofileController", func() {
 			})
 		})
 	})
+
+	When("importing a tailoring file", func() {
+		var (
+			tpName = "imported"
+			tpKey  = types.NamespacedName{Name: tpName, Namespace: namespace}
+			tpReq  = reconcile.Request{NamespacedName: tpKey}
+		)
+		const tailoringFile = `<xccdf:Tailoring xmlns:xccdf="http://checklists.nist.gov/xccdf/1.2" id="xccdf_scap-workbench_tailoring_default">
+  <xccdf:benchmark href="/usr/share/xml/scap/ssg/content/ssg-ocp4-ds.xml"/>
+  <xccdf:Profile id="xccdf_org.ssgproject.content_profile_cis_customized" extends="xccdf_org.ssgproject.content_profile_cis">
+    <xccdf:title>CIS customized</xccdf:title>
+    <xccdf:select idref="xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled" selected="true"/>
+    <xccdf:select idref="xccdf_org.ssgproject.content_rule_kubeadmin_removed" selected="false"/>
+    <xccdf:select idref="xccdf_org.ssgproject.content_group_accounts" selected="false"/>
+    <xccdf:set-value idref="xccdf_org.ssgproject.content_value_var_openshift_audit_profile">WriteRequestBodies</xccdf:set-value>
+  </xccdf:Profile>
+</xccdf:Tailoring>`
+
+		BeforeEach(func() {
+			pb := &compv1alpha1.ProfileBundle{
+				ObjectMeta: metav1.ObjectMeta{Name: "ocp4", Namespace: namespace},
+				Spec:       compv1alpha1.ProfileBundleSpec{ContentFile: "ssg-ocp4-ds.xml"},
+			}
+			Expect(r.Client.Create(ctx, pb)).To(Succeed())
+			owned := metav1.ObjectMeta{
+				Namespace: namespace,
+				Labels:    map[string]string{compv1alpha1.ProfileBundleOwnerLabel: pb.Name},
+			}
+			Expect(controllerutil.SetControllerReference(pb, &owned, r.Scheme)).To(Succeed())
+
+			p := &compv1alpha1.Profile{ObjectMeta: *owned.DeepCopy()}
+			p.Name = "ocp4-cis"
+			p.ID = "xccdf_org.ssgproject.content_profile_cis"
+			Expect(r.Client.Create(ctx, p)).To(Succeed())
+			for name, id := range map[string]string{
+				"ocp4-audit-log-forwarding-enabled": "xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled",
+				"ocp4-kubeadmin-removed":            "xccdf_org.ssgproject.content_rule_kubeadmin_removed",
+			} {
+				rule := &compv1alpha1.Rule{ObjectMeta: *owned.DeepCopy()}
+				rule.Name = name
+				rule.ID = id
+				rule.CheckType = compv1alpha1.CheckTypePlatform
+				Expect(r.Client.Create(ctx, rule)).To(Succeed())
+			}
+			v := &compv1alpha1.Variable{ObjectMeta: *owned.DeepCopy()}
+			v.Name = "ocp4-var-openshift-audit-profile"
+			v.ID = "xccdf_org.ssgproject.content_value_var_openshift_audit_profile"
+			Expect(r.Client.Create(ctx, v)).To(Succeed())
+
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "oscap-tailoring", Namespace: namespace},
+				Data:       map[string]string{"tailoring.xml": tailoringFile},
+			}
+			Expect(r.Client.Create(ctx, cm)).To(Succeed())
+
+			tp := &compv1alpha1.TailoredProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: tpName, Namespace: namespace},
+				Spec: compv1alpha1.TailoredProfileSpec{
+					Title:       "Imported",
+					Description: "Imported from oscap",
+					ImportFrom:  &compv1alpha1.TailoringImportSpec{ConfigMapName: cm.Name},
+				},
+			}
+			Expect(r.Client.Create(ctx, tp)).To(Succeed())
+		})
+
+		It("converts the tailoring file into the TailoredProfile", func() {
+			By("Reconciling the first time (importing the tailoring file)")
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			rationale := "Imported from the tailoring file of the ConfigMap oscap-tailoring"
+			Expect(tp.Spec.Extends).To(Equal("ocp4-cis"))
+			Expect(tp.Spec.Title).To(Equal("CIS customized"))
+			Expect(tp.Spec.Description).To(Equal("Imported from oscap"))
+			Expect(tp.Spec.EnableRules).To(Equal([]compv1alpha1.RuleReferenceSpec{
+				{Name: "ocp4-audit-log-forwarding-enabled", Rationale: rationale},
+			}))
+			Expect(tp.Spec.DisableRules).To(Equal([]compv1alpha1.RuleReferenceSpec{
+				{Name: "ocp4-kubeadmin-removed", Rationale: rationale},
+			}))
+			Expect(tp.Spec.SetValues).To(Equal([]compv1alpha1.VariableValueSpec{
+				{Name: "ocp4-var-openshift-audit-profile", Rationale: rationale, Value: "WriteRequestBodies"},
+			}))
+			Expect(tp.GetAnnotations()).To(HaveKey(compv1alpha1.TailoringImportedAnnotation))
+
+			By("Reconciling until the TailoredProfile is ready")
+			for i := 0; i < 3; i++ {
+				_, err = r.Reconcile(context.TODO(), tpReq)
+				Expect(err).To(BeNil())
+			}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Spec.Extends).To(Equal("ocp4-cis"))
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateReady))
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: tp.Status.OutputRef.Name, Namespace: namespace}, cm)).To(Succeed())
+			data := cm.Data["tailoring.xml"]
+			Expect(data).To(ContainSubstring(`extends="xccdf_org.ssgproject.content_profile_cis"`))
+			Expect(data).To(ContainSubstring(`select idref="xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled" selected="true"`))
+			Expect(data).To(ContainSubstring(`select idref="xccdf_org.ssgproject.content_rule_kubeadmin_removed" selected="false"`))
+			Expect(data).To(ContainSubstring(`<xccdf-1.2:remark>Imported from the tailoring file of the ConfigMap oscap-tailoring</xccdf-1.2:remark>`))
+		})
+
+		It("imports the tailoring file again when the ConfigMap changes", func() {
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "oscap-tailoring", Namespace: namespace}, cm)).To(Succeed())
+			cm.Data["tailoring.xml"] = strings.Replace(tailoringFile, `selected="false"`, `selected="true"`, 1)
+			Expect(r.Client.Update(ctx, cm)).To(Succeed())
+
+			_, err = r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Spec.EnableRules).To(HaveLen(2))
+			Expect(tp.Spec.DisableRules).To(BeEmpty())
+		})
+
+		It("surfaces the tailoring files that can't be imported", func() {
+			cm := &corev1.ConfigMap{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: "oscap-tailoring", Namespace: namespace}, cm)).To(Succeed())
+			cm.Data["tailoring.xml"] = strings.Replace(tailoringFile, "ssg-ocp4-ds.xml", "ssg-rhel9-ds.xml", 1)
+			Expect(r.Client.Update(ctx, cm)).To(Succeed())
+
+			_, err := r.Reconcile(context.TODO(), tpReq)
+			Expect(err).To(BeNil())
+			tp := &compv1alpha1.TailoredProfile{}
+			Expect(r.Client.Get(ctx, tpKey, tp)).To(Succeed())
+			Expect(tp.Status.State).To(Equal(compv1alpha1.TailoredProfileStateError))
+			Expect(tp.Status.ErrorMessage).To(ContainSubstring("no ProfileBundle has the data stream file"))
+		})
+	})
 })
@@ -0,0 +1,127 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// tailoredProfileValidator rejects TailoredProfiles that set variables to
+// values that don't have the type of the variable or don't meet its
+// constraints, instead of the TailoredProfile ending up in the ERROR state
+type tailoredProfileValidator struct {
+	reader client.Reader
+}
+
+var _ admission.CustomValidator = &tailoredProfileValidator{}
+
+//+kubebuilder:webhook:path=/validate-compliance-openshift-io-v1alpha1-tailoredprofile,mutating=false,failurePolicy=ignore,sideEffects=None,groups=compliance.openshift.io,resources=tailoredprofiles,verbs=create;update,versions=v1alpha1,name=vtailoredprofile.compliance.openshift.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the webhook validating TailoredProfiles
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&cmpv1alpha1.TailoredProfile{}).
+		WithValidator(&tailoredProfileValidator{reader: mgr.GetClient()}).
+		Complete()
+}
+
+func (v *tailoredProfileValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	tp, ok := obj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", obj)
+	}
+	return v.validateSetValues(ctx, tp, nil)
+}
+
+// ValidateUpdate only validates the values that changed, so that the
+// TailoredProfiles created before the webhook can still be updated
+func (v *tailoredProfileValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTp, ok := oldObj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", oldObj)
+	}
+	tp, ok := newObj.(*cmpv1alpha1.TailoredProfile)
+	if !ok {
+		return nil, fmt.Errorf("expected a TailoredProfile but got a %T", newObj)
+	}
+
+	previousValues := make(map[string]string, len(oldTp.Spec.SetValues))
+	for _, setValue := range oldTp.Spec.SetValues {
+		previousValues[setValue.Name] = setValue.Value
+	}
+	return v.validateSetValues(ctx, tp, previousValues)
+}
+
+func (v *tailoredProfileValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSetValues validates the values the TailoredProfile sets, except for
+// those it already set to the same value before. Variables that don't exist
+// yet, e.g. because their ProfileBundle is still being parsed, are left for
+// the controller to validate.
+func (v *tailoredProfileValidator) validateSetValues(ctx context.Context, tp *cmpv1alpha1.TailoredProfile, previousValues map[string]string) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	var allErrs field.ErrorList
+	setValuesPath := field.NewPath("spec", "setValues")
+	for i, setValue := range tp.Spec.SetValues {
+		if previous, ok := previousValues[setValue.Name]; ok && previous == setValue.Value {
+			continue
+		}
+		valuePath := setValuesPath.Index(i).Child("value")
+
+		variable := &cmpv1alpha1.Variable{}
+		err := v.reader.Get(ctx, types.NamespacedName{Name: setValue.Name, Namespace: tp.Namespace}, variable)
+		if err != nil {
+			if !kerrors.IsNotFound(err) {
+				return warnings, err
+			}
+			warnings = append(warnings, fmt.Sprintf("%s: the variable %s doesn't exist, so its value couldn't be validated", valuePath, setValue.Name))
+			continue
+		}
+
+		if err := variable.ValidateValue(setValue.Value); err != nil {
+			allErrs = append(allErrs, field.Invalid(valuePath, setValue.Value,
+				fmt.Sprintf("not a valid value of the %s variable %s: %s", variable.Type, setValue.Name, err)))
+			continue
+		}
+		// Unlike the constraints, the selections are only suggestions
+		if suggested := getSuggestedValues(variable); len(suggested) > 0 && !containsValue(suggested, setValue.Value) {
+			warnings = append(warnings, fmt.Sprintf("%s: %q isn't one of the values suggested for the variable %s: %s",
+				valuePath, setValue.Value, setValue.Name, strings.Join(suggested, ",")))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		gk := cmpv1alpha1.SchemeGroupVersion.WithKind("TailoredProfile").GroupKind()
+		return warnings, kerrors.NewInvalid(gk, tp.Name, allErrs)
+	}
+	return warnings, nil
+}
+
+func getSuggestedValues(variable *cmpv1alpha1.Variable) []string {
+	values := make([]string, 0, len(variable.Selections))
+	for _, selection := range variable.Selections {
+		values = append(values, selection.Value)
+	}
+	return values
+}
+
+func containsValue(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package tailoredprofile
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/apis"
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+var _ = Describe("TailoredProfile webhook", func() {
+	var (
+		namespace = "test-ns"
+		validator *tailoredProfileValidator
+		tp        *compv1alpha1.TailoredProfile
+	)
+
+	BeforeEach(func() {
+		cscheme := scheme.Scheme
+		Expect(apis.AddToScheme(cscheme)).To(Succeed())
+
+		lowerBound := 60
+		timeout := &compv1alpha1.Variable{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-var-oauth-token-inactivity-timeout", Namespace: namespace},
+			VariablePayload: compv1alpha1.VariablePayload{
+				ID:          "xccdf_org.ssgproject.content_value_var_oauth_token_inactivity_timeout",
+				Type:        compv1alpha1.VarTypeNumber,
+				Constraints: &compv1alpha1.ValueConstraints{LowerBound: &lowerBound},
+			},
+		}
+		auditProfile := &compv1alpha1.Variable{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-var-openshift-audit-profile", Namespace: namespace},
+			VariablePayload: compv1alpha1.VariablePayload{
+				ID:   "xccdf_org.ssgproject.content_value_var_openshift_audit_profile",
+				Type: compv1alpha1.VarTypeString,
+				Selections: []compv1alpha1.ValueSelection{
+					{Description: "Default", Value: "Default"},
+					{Description: "WriteRequestBodies", Value: "WriteRequestBodies"},
+				},
+			},
+		}
+		client := fake.NewClientBuilder().WithScheme(cscheme).WithObjects(timeout, auditProfile).Build()
+		validator = &tailoredProfileValidator{reader: client}
+
+		tp = &compv1alpha1.TailoredProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp4-moderate-custom", Namespace: namespace},
+			Spec: compv1alpha1.TailoredProfileSpec{
+				Extends: "ocp4-moderate",
+				SetValues: []compv1alpha1.VariableValueSpec{
+					{Name: "ocp4-var-oauth-token-inactivity-timeout", Value: "600"},
+					{Name: "ocp4-var-openshift-audit-profile", Value: "WriteRequestBodies"},
+				},
+			},
+		}
+	})
+
+	It("admits valid values", func() {
+		warnings, err := validator.ValidateCreate(context.TODO(), tp)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("rejects values of the wrong type or out of bounds", func() {
+		tp.Spec.SetValues[0].Value = "10m"
+		_, err := validator.ValidateCreate(context.TODO(), tp)
+		Expect(kerrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`spec.setValues[0].value: Invalid value: "10m": not a valid value of the number variable ocp4-var-oauth-token-inactivity-timeout`)))
+
+		tp.Spec.SetValues[0].Value = "30"
+		_, err = validator.ValidateCreate(context.TODO(), tp)
+		Expect(err).To(MatchError(ContainSubstring("value 30 is lower than the lower bound 60")))
+	})
+
+	It("warns about values that aren't suggested or can't be validated", func() {
+		tp.Spec.SetValues[1].Value = "AllRequestBodies"
+		tp.Spec.SetValues = append(tp.Spec.SetValues, compv1alpha1.VariableValueSpec{Name: "ocp4-var-missing", Value: "1"})
+		warnings, err := validator.ValidateCreate(context.TODO(), tp)
+		Expect(err).To(BeNil())
+		Expect(warnings).To(HaveLen(2))
+		Expect(warnings[0]).To(ContainSubstring(`"AllRequestBodies" isn't one of the values suggested`))
+		Expect(warnings[1]).To(ContainSubstring("the variable ocp4-var-missing doesn't exist"))
+	})
+
+	It("only validates the values that changed on updates", func() {
+		tp.Spec.SetValues[0].Value = "30"
+		updated := tp.DeepCopy()
+		updated.Spec.Title = "Updated"
+		_, err := validator.ValidateUpdate(context.TODO(), tp, updated)
+		Expect(err).To(BeNil())
+
+		updated.Spec.SetValues[0].Value = "20"
+		_, err = validator.ValidateUpdate(context.TODO(), tp, updated)
+		Expect(err).To(MatchError(ContainSubstring("value 20 is lower than the lower bound 60")))
+	})
+})
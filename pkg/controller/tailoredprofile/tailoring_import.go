@@ -0,0 +1,232 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/controller/common"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/xccdf"
+)
+
+// xccdfObjects are the names of the profiles, rules and variables of a data
+// stream file, by their XCCDF IDs
+type xccdfObjects struct {
+	profiles  map[string]string
+	rules     map[string]string
+	variables map[string]string
+}
+
+// importTailoring imports the tailoring file the TailoredProfile references
+// into its spec, unless it was already imported from the current version of
+// the ConfigMap. It returns whether the TailoredProfile was updated.
+func (r *ReconcileTailoredProfile) importTailoring(tp *cmpv1alpha1.TailoredProfile, logger logr.Logger) (bool, error) {
+	importSpec := tp.Spec.ImportFrom
+	cm := &corev1.ConfigMap{}
+	cmKey := types.NamespacedName{Name: importSpec.ConfigMapName, Namespace: tp.Namespace}
+	err := r.Client.Get(context.TODO(), cmKey, cm)
+	if kerrors.IsNotFound(err) {
+		return false, common.NewNonRetriableCtrlError("fetching the ConfigMap with the tailoring file: %w", err)
+	} else if err != nil {
+		return false, err
+	}
+	if tp.GetAnnotations()[cmpv1alpha1.TailoringImportedAnnotation] == cm.ResourceVersion {
+		return false, nil
+	}
+
+	data, ok := cm.Data[importSpec.GetKey()]
+	if !ok {
+		return false, common.NewNonRetriableCtrlError("the ConfigMap %s has no %s key with the tailoring file", cm.Name, importSpec.GetKey())
+	}
+	tailoring, err := xccdf.ParseTailoring(data)
+	if err != nil {
+		return false, common.NewNonRetriableCtrlError("importing the tailoring file: %w", err)
+	}
+	profile, err := tailoring.GetProfile(importSpec.ProfileID)
+	if err != nil {
+		return false, common.NewNonRetriableCtrlError("importing the tailoring file: %w", err)
+	}
+
+	pb, contentFile, err := r.getImportedProfileBundle(tp, tailoring.GetBenchmarkHref())
+	if err != nil {
+		return false, err
+	}
+	objs, err := r.getXCCDFObjects(tp.Namespace, pb, contentFile)
+	if err != nil {
+		return false, err
+	}
+
+	tpCopy := tp.DeepCopy()
+	rationale := fmt.Sprintf("Imported from the tailoring file of the ConfigMap %s", cm.Name)
+	skipped, err := applyImportedProfile(tpCopy, profile, objs, rationale)
+	if err != nil {
+		return false, err
+	}
+	if len(skipped) > 0 {
+		logger.Info("Skipped the IDs of the tailoring file that aren't in the ProfileBundle", "ProfileBundle", pb.Name, "IDs", skipped)
+		r.Eventf(tp, corev1.EventTypeWarning, "TailoringImportSkipped",
+			"Skipped the IDs of the tailoring file that aren't rules or variables of the ProfileBundle %s: %s", pb.Name, strings.Join(skipped, ","))
+	}
+
+	anns := tpCopy.GetAnnotations()
+	if anns == nil {
+		anns = make(map[string]string)
+	}
+	anns[cmpv1alpha1.TailoringImportedAnnotation] = cm.ResourceVersion
+	tpCopy.SetAnnotations(anns)
+	err = r.Client.Update(context.TODO(), tpCopy)
+	if kerrors.IsInvalid(err) {
+		// e.g. the webhook rejected the imported values
+		return false, common.NewNonRetriableCtrlError("importing the tailoring file: %w", err)
+	} else if err != nil {
+		return false, err
+	}
+	logger.Info("Imported the tailoring file", "ConfigMap", cm.Name, "Profile", profile.ID)
+	return true, nil
+}
+
+// getImportedProfileBundle gets the ProfileBundle with the content a
+// tailoring file tailors, along with the data stream file of the bundle the
+// benchmark of the tailoring file refers to
+func (r *ReconcileTailoredProfile) getImportedProfileBundle(tp *cmpv1alpha1.TailoredProfile, href string) (*cmpv1alpha1.ProfileBundle, string, error) {
+	var bundles []cmpv1alpha1.ProfileBundle
+	if pbName := tp.Spec.ImportFrom.ProfileBundle; pbName != "" {
+		pb := &cmpv1alpha1.ProfileBundle{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pbName, Namespace: tp.Namespace}, pb)
+		if kerrors.IsNotFound(err) {
+			return nil, "", common.NewNonRetriableCtrlError("fetching the ProfileBundle of the tailoring file: %w", err)
+		} else if err != nil {
+			return nil, "", err
+		}
+		bundles = append(bundles, *pb)
+	} else {
+		pbList := &cmpv1alpha1.ProfileBundleList{}
+		if err := r.Client.List(context.TODO(), pbList, client.InNamespace(tp.Namespace)); err != nil {
+			return nil, "", err
+		}
+		bundles = pbList.Items
+	}
+
+	// The href is a path to the data stream, which isn't necessarily mounted
+	// where the operator mounts it
+	for i := range bundles {
+		files := bundles[i].GetContentFiles()
+		for _, file := range files {
+			if filepath.Base(file.File) == filepath.Base(href) {
+				return &bundles[i], file.File, nil
+			}
+		}
+		if tp.Spec.ImportFrom.ProfileBundle != "" && len(files) == 1 {
+			return &bundles[i], files[0].File, nil
+		}
+	}
+	return nil, "", common.NewNonRetriableCtrlError("no ProfileBundle has the data stream file %s the tailoring file tailors", href)
+}
+
+// getXCCDFObjects gets the profiles, rules and variables parsed out of a data
+// stream file of a ProfileBundle
+func (r *ReconcileTailoredProfile) getXCCDFObjects(namespace string, pb *cmpv1alpha1.ProfileBundle, contentFile string) (*xccdfObjects, error) {
+	listOpts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{cmpv1alpha1.ProfileBundleOwnerLabel: pb.Name},
+	}
+	profileList := &cmpv1alpha1.ProfileList{}
+	if err := r.Client.List(context.TODO(), profileList, listOpts...); err != nil {
+		return nil, err
+	}
+	ruleList := &cmpv1alpha1.RuleList{}
+	if err := r.Client.List(context.TODO(), ruleList, listOpts...); err != nil {
+		return nil, err
+	}
+	variableList := &cmpv1alpha1.VariableList{}
+	if err := r.Client.List(context.TODO(), variableList, listOpts...); err != nil {
+		return nil, err
+	}
+
+	objs := &xccdfObjects{
+		profiles:  make(map[string]string, len(profileList.Items)),
+		rules:     make(map[string]string, len(ruleList.Items)),
+		variables: make(map[string]string, len(variableList.Items)),
+	}
+	addObject := func(ids map[string]string, id string, obj metav1.Object) {
+		if pb.GetContentFileOf(obj) == contentFile {
+			ids[id] = obj.GetName()
+		}
+	}
+	for i := range profileList.Items {
+		addObject(objs.profiles, profileList.Items[i].ID, &profileList.Items[i])
+	}
+	for i := range ruleList.Items {
+		addObject(objs.rules, ruleList.Items[i].ID, &ruleList.Items[i])
+	}
+	for i := range variableList.Items {
+		addObject(objs.variables, variableList.Items[i].ID, &variableList.Items[i])
+	}
+	return objs, nil
+}
+
+// applyImportedProfile replaces the profile the TailoredProfile extends and
+// the rules and values it tailors with those of the imported profile. The
+// rationale of the rules is their remark, if they have one. It returns the
+// IDs of the rules and variables that aren't in the content, which are
+// skipped.
+func applyImportedProfile(tp *cmpv1alpha1.TailoredProfile, profile *xccdf.ImportedProfile, objs *xccdfObjects, rationale string) ([]string, error) {
+	tp.Spec.Extends = ""
+	if profile.Extends != "" {
+		name, ok := objs.profiles[profile.Extends]
+		if !ok {
+			return nil, common.NewNonRetriableCtrlError("the profile %s the tailoring file extends isn't in the ProfileBundle", profile.Extends)
+		}
+		tp.Spec.Extends = name
+	}
+	if title := profile.GetTitle(); title != "" {
+		tp.Spec.Title = title
+	}
+	if description := profile.GetDescription(); description != "" {
+		tp.Spec.Description = description
+	}
+
+	var skipped []string
+	tp.Spec.EnableRules = nil
+	tp.Spec.DisableRules = nil
+	for _, selection := range profile.Selections {
+		name, ok := objs.rules[selection.IDRef]
+		if !ok {
+			skipped = append(skipped, selection.IDRef)
+			continue
+		}
+		ruleRef := cmpv1alpha1.RuleReferenceSpec{Name: name, Rationale: rationale}
+		if remark := selection.GetRemark(); remark != "" {
+			ruleRef.Rationale = remark
+		}
+		if selection.Selected {
+			tp.Spec.EnableRules = append(tp.Spec.EnableRules, ruleRef)
+		} else {
+			tp.Spec.DisableRules = append(tp.Spec.DisableRules, ruleRef)
+		}
+	}
+
+	tp.Spec.SetValues = nil
+	for _, value := range profile.Values {
+		name, ok := objs.variables[value.IDRef]
+		if !ok {
+			skipped = append(skipped, value.IDRef)
+			continue
+		}
+		tp.Spec.SetValues = append(tp.Spec.SetValues, cmpv1alpha1.VariableValueSpec{
+			Name:      name,
+			Rationale: rationale,
+			Value:     strings.TrimSpace(value.Value),
+		})
+	}
+	return skipped, nil
+}
@@ -0,0 +1,115 @@
+package tailoredprofile
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// resolvedStatus is what the selections of a TailoredProfile resolve to,
+// which is recorded in its status once it's ready
+type resolvedStatus struct {
+	additionalOutputs []cmpv1alpha1.TailoredProfileOutput
+	selectedRules     *cmpv1alpha1.SelectedRules
+	validation        *cmpv1alpha1.TailoredProfileValidation
+}
+
+func (s *resolvedStatus) applyTo(status *cmpv1alpha1.TailoredProfileStatus) {
+	status.AdditionalOutputs = s.additionalOutputs
+	status.SelectedRules = s.selectedRules
+	status.Validation = s.validation
+}
+
+func (s *resolvedStatus) differsFrom(status *cmpv1alpha1.TailoredProfileStatus) bool {
+	return !reflect.DeepEqual(status.AdditionalOutputs, s.additionalOutputs) ||
+		!reflect.DeepEqual(status.SelectedRules, s.selectedRules) ||
+		!reflect.DeepEqual(status.Validation, s.validation)
+}
+
+// validateReferences resolves the rules and variables the tailored profile
+// references: how many rules it ends up enabling, which references don't
+// exist and which selections don't change the extended profile
+func (r *ReconcileTailoredProfile) validateReferences(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profile) (*cmpv1alpha1.TailoredProfileValidation, error) {
+	validation := &cmpv1alpha1.TailoredProfileValidation{}
+	inProfile := make(map[string]bool)
+	inProfileValues := make(map[string]bool)
+	if p != nil {
+		for _, rule := range p.Rules {
+			inProfile[string(rule)] = true
+		}
+		for _, value := range p.Values {
+			inProfileValues[string(value)] = true
+		}
+	}
+
+	ruleExists := func(field string, selection cmpv1alpha1.RuleReferenceSpec) (bool, error) {
+		rule := &cmpv1alpha1.Rule{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: selection.Name, Namespace: tp.Namespace}, rule)
+		if kerrors.IsNotFound(err) {
+			validation.UnknownReferences = append(validation.UnknownReferences, fmt.Sprintf("%s: %s", field, selection.Name))
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	enabled := make(map[string]bool, len(inProfile))
+	for name := range inProfile {
+		enabled[name] = true
+	}
+	for _, selection := range tp.Spec.EnableRules {
+		if ok, err := ruleExists("enableRules", selection); !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if inProfile[selection.Name] {
+			validation.NoOpSelections = append(validation.NoOpSelections, fmt.Sprintf("enableRules: %s is already enabled by the profile", selection.Name))
+		}
+		enabled[selection.Name] = true
+	}
+	for _, selection := range tp.Spec.ManualRules {
+		if ok, err := ruleExists("manualRules", selection); !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		enabled[selection.Name] = true
+	}
+	for _, selection := range tp.Spec.DisableRules {
+		if ok, err := ruleExists("disableRules", selection); !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !inProfile[selection.Name] {
+			validation.NoOpSelections = append(validation.NoOpSelections, fmt.Sprintf("disableRules: %s isn't enabled by the profile", selection.Name))
+		}
+		delete(enabled, selection.Name)
+	}
+	validation.EnabledRuleCount = len(enabled)
+
+	for _, setValue := range tp.Spec.SetValues {
+		variable := &cmpv1alpha1.Variable{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: setValue.Name, Namespace: tp.Namespace}, variable)
+		if kerrors.IsNotFound(err) {
+			validation.UnknownReferences = append(validation.UnknownReferences, fmt.Sprintf("setValues: %s", setValue.Name))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		// The profile might set the variable to another value than its
+		// default one
+		if !inProfileValues[variable.ID] && variable.Value == setValue.Value {
+			validation.NoOpSelections = append(validation.NoOpSelections, fmt.Sprintf("setValues: %s is already set to %s", setValue.Name, setValue.Value))
+		}
+	}
+	return validation, nil
+}
@@ -0,0 +1,476 @@
+package profileparser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+
+	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+// Data streams are large, and their DOM takes several times their size in
+// memory. So rather than reading them into a DOM, the parser streams them and
+// only reads the elements it parses objects out of, or that the rules refer
+// to, into a DOM of their own, one at a time. As rules refer to elements that
+// can come after them, data streams are streamed twice: once to index the
+// elements the rules refer to, and once to parse the profiles, variables and
+// rules.
+
+// The qualified names of the elements of data streams that are read into a
+// DOM of their own
+const (
+	benchmarkElement  = "xccdf-1.2:Benchmark"
+	profileElement    = "xccdf-1.2:Profile"
+	valueElement      = "xccdf-1.2:Value"
+	ruleElement       = "xccdf-1.2:Rule"
+	definitionElement = "oval-def:definition"
+	questionElement   = "ocil:boolean_question"
+)
+
+var errStreamStopped = errors.New("streaming the content was stopped")
+
+// contentIndex holds the elements of a data stream that the rules refer to
+type contentIndex struct {
+	questions   utils.NodeByIdHashTable
+	definitions utils.NodeByIdHashTable
+	profiles    utils.NodeByIdHashTable
+	// The default values of the variables, by their ID without the prefix
+	values map[string]string
+	// The benchmarks in the order of the data stream, and the product
+	// their profiles are for by default, by their ID
+	benchmarks []cmpv1alpha1.ProfileBundleBenchmark
+	products   map[string]product
+}
+
+type product struct {
+	productType cmpv1alpha1.ComplianceScanType
+	productName string
+}
+
+// profileNode is a profile sent to be parsed, along with the product of its
+// benchmark
+type profileNode struct {
+	node *xmlquery.Node
+	product
+}
+
+// newContentIndex indexes the DOM of a data stream
+func newContentIndex(contentDom *xmlquery.Node) *contentIndex {
+	index := &contentIndex{
+		questions:   utils.NewOcilQuestionTable(contentDom),
+		definitions: utils.NewDefHashTable(contentDom),
+		profiles:    utils.NewProfileTable(contentDom),
+		values:      make(map[string]string),
+	}
+	for _, variable := range xmlquery.Find(contentDom, "//xccdf-1.2:Value") {
+		addDefaultValues(index.values, variable)
+	}
+	return index
+}
+
+// addDefaultValues adds the default value of a variable to the values the
+// descriptions and instructions of the rules are rendered with
+func addDefaultValues(valuesList map[string]string, variable *xmlquery.Node) {
+	for _, val := range variable.SelectElements("//xccdf-1.2:value") {
+		if val.SelectAttr("hidden") == "true" {
+			// this is typically used for functions
+			continue
+		}
+		if val.SelectAttr("selector") == "" {
+			// It is not an enum choice, but a default value instead
+			if strings.HasPrefix(variable.SelectAttr("id"), valuePrefix) {
+				valuesList[strings.TrimPrefix(variable.SelectAttr("id"), valuePrefix)] = val.OutputXML(false)
+			}
+		}
+	}
+}
+
+// addByID adds an element to a table unless an element with the same ID is
+// already in it, as the tables of a DOM hold the elements of the first
+// component of their type
+func addByID(table utils.NodeByIdHashTable, node *xmlquery.Node) {
+	id := node.SelectAttr("id")
+	if _, ok := table[id]; !ok {
+		table[id] = node
+	}
+}
+
+// sendNodes sends the nodes on a channel, which is closed once they're sent
+func sendNodes(nodes []*xmlquery.Node) <-chan *xmlquery.Node {
+	nodeChan := make(chan *xmlquery.Node)
+	go func() {
+		for _, node := range nodes {
+			nodeChan <- node
+		}
+		close(nodeChan)
+	}()
+	return nodeChan
+}
+
+// streamContentAndDo streams the data stream of a bundle content, first to
+// index it and then to parse its profiles, rules and variables, which are
+// parsed while the data stream is streamed
+func streamContentAndDo(content *BundleContent, stdParser *referenceParser, pb *cmpv1alpha1.ProfileBundle, nonce string,
+	profileAction func(p *cmpv1alpha1.Profile) error, ruleAction func(r *cmpv1alpha1.Rule) error, variableAction func(v *cmpv1alpha1.Variable) error) error {
+	index, err := indexContent(content, stdParser)
+	if err != nil {
+		return err
+	}
+	content.Benchmarks = index.benchmarks
+
+	r, err := content.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	profiles := make(chan profileNode)
+	values := make(chan *xmlquery.Node)
+	rules := make(chan *xmlquery.Node)
+	// Stops streaming if parsing fails
+	stop := make(chan struct{})
+	defer close(stop)
+	streamErr := make(chan error, 1)
+	go func() {
+		defer close(profiles)
+		defer close(values)
+		defer close(rules)
+		streamErr <- streamContent(r, stdParser, func(node *xmlquery.Node, benchmark string) error {
+			switch qualifiedNodeName(node) {
+			case profileElement:
+				profile := profileNode{node: node, product: product{productType: cmpv1alpha1.ScanTypeNode}}
+				if p, ok := index.products[benchmark]; ok {
+					profile.product = p
+				}
+				select {
+				case profiles <- profile:
+				case <-stop:
+					return errStreamStopped
+				}
+			case valueElement:
+				select {
+				case values <- node:
+				case <-stop:
+					return errStreamStopped
+				}
+			case ruleElement:
+				select {
+				case rules <- node:
+				case <-stop:
+					return errStreamStopped
+				}
+			}
+			return nil
+		}, profileElement, valueElement, ruleElement)
+	}()
+
+	err = parseInParallel(
+		func() error {
+			return parseProfilesAndDo(profiles, pb, content.Prefix, nonce, profileAction)
+		},
+		func() error {
+			return parseRulesAndDo(rules, index, stdParser, pb, content.Prefix, nonce, ruleAction)
+		},
+		func() error {
+			return parseVariablesAndDo(values, pb, nonce, variableAction)
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return <-streamErr
+}
+
+// indexContent streams the data stream of a bundle content to index the
+// elements the rules refer to
+func indexContent(content *BundleContent, stdParser *referenceParser) (*contentIndex, error) {
+	r, err := content.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	index := &contentIndex{
+		questions:   make(utils.NodeByIdHashTable),
+		definitions: make(utils.NodeByIdHashTable),
+		profiles:    make(utils.NodeByIdHashTable),
+		values:      make(map[string]string),
+		benchmarks:  []cmpv1alpha1.ProfileBundleBenchmark{},
+		products:    make(map[string]product),
+	}
+	err = streamContent(r, stdParser, func(node *xmlquery.Node, _ string) error {
+		switch qualifiedNodeName(node) {
+		case benchmarkElement:
+			index.benchmarks = append(index.benchmarks, getBenchmark(node))
+			productType, productName := getProductTypeAndName(node, cmpv1alpha1.ScanTypeNode, "")
+			index.products[node.SelectAttr("id")] = product{productType: productType, productName: productName}
+		case profileElement:
+			addByID(index.profiles, node)
+		case valueElement:
+			addDefaultValues(index.values, node)
+		case definitionElement:
+			addByID(index.definitions, node)
+		case questionElement:
+			addByID(index.questions, node)
+		}
+		return nil
+	}, benchmarkElement, profileElement, valueElement, definitionElement, questionElement)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read content XML: %w", err)
+	}
+	return index, nil
+}
+
+// streamedElement is an element that's open while streaming a data stream
+type streamedElement struct {
+	name xml.Name
+	// The namespaces the element declares
+	namespaces []xml.Attr
+	// The XML of the element, if it's read into a DOM of its own
+	fragment *bytes.Buffer
+	// Where the XML of the element and its children is written to, nil if
+	// it's skipped
+	out io.Writer
+}
+
+// streamContent streams a data stream and calls fn with each of the given
+// elements, read into a DOM of their own, along with the ID of the benchmark
+// they're in. The rest of the data stream is only read to find them. The DOM
+// of a benchmark doesn't have its profiles, values, groups and rules, which
+// are read on their own.
+func streamContent(r io.Reader, stdParser *referenceParser, fn func(node *xmlquery.Node, benchmark string) error, elements ...string) error {
+	decoder := xml.NewDecoder(r)
+	var stack []*streamedElement
+	var benchmark string
+	// Nested elements of the one being skipped
+	skipDepth := 0
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			if len(stack) > 0 {
+				return fmt.Errorf("the element %s isn't closed", qualifiedName(stack[len(stack)-1].name))
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if skipDepth > 0 {
+			switch token.(type) {
+			case xml.StartElement:
+				skipDepth++
+			case xml.EndElement:
+				skipDepth--
+			}
+			continue
+		}
+
+		// The element the token is in, or that it closes
+		var top *streamedElement
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if isUnusedElement(&t, stdParser) {
+				skipDepth = 1
+				continue
+			}
+
+			el := &streamedElement{name: t.Name, namespaces: getNamespaces(&t)}
+			name := qualifiedName(t.Name)
+			if name == benchmarkElement {
+				benchmark = getAttr(&t, "id")
+			}
+			if isStreamedElement(name, elements) {
+				// The fragment declares the namespaces it uses
+				t.Attr = append(getNamespacesInScope(stack, el.namespaces), t.Attr...)
+				el.fragment = &bytes.Buffer{}
+				el.out = el.fragment
+			} else if top != nil && !isBenchmarkItem(name) {
+				el.out = top.out
+			}
+			stack = append(stack, el)
+			if el.out != nil {
+				if err := writeToken(el.out, t); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			if top == nil || top.name != t.Name {
+				return fmt.Errorf("unexpected closing element %s", qualifiedName(t.Name))
+			}
+			stack = stack[:len(stack)-1]
+			if top.out != nil {
+				if err := writeToken(top.out, t); err != nil {
+					return err
+				}
+			}
+			if top.fragment != nil {
+				node, err := parseFragment(top.fragment)
+				if err != nil {
+					return err
+				}
+				if err := fn(node, benchmark); err != nil {
+					return err
+				}
+			}
+			if qualifiedName(t.Name) == benchmarkElement {
+				benchmark = ""
+			}
+		default:
+			if top != nil && top.out != nil {
+				if err := writeToken(top.out, token); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func isStreamedElement(name string, elements []string) bool {
+	for _, element := range elements {
+		if name == element {
+			return true
+		}
+	}
+	return false
+}
+
+// isBenchmarkItem tells whether an element is one of the items of a
+// benchmark, which aren't part of the DOM of the element they're in
+func isBenchmarkItem(name string) bool {
+	switch name {
+	case profileElement, valueElement, ruleElement, "xccdf-1.2:Group", "xccdf-1.2:TestResult":
+		return true
+	}
+	return false
+}
+
+// parseFragment reads the XML of an element into a DOM, and returns the
+// element
+func parseFragment(fragment *bytes.Buffer) (*xmlquery.Node, error) {
+	doc, err := xmlquery.Parse(fragment)
+	if err != nil {
+		return nil, err
+	}
+	for node := doc.FirstChild; node != nil; node = node.NextSibling {
+		if node.Type == xmlquery.ElementNode {
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("no element in the XML fragment")
+}
+
+// getNamespaces returns the namespace declarations of an element
+func getNamespaces(el *xml.StartElement) []xml.Attr {
+	var namespaces []xml.Attr
+	for _, attr := range el.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			namespaces = append(namespaces, attr)
+		}
+	}
+	return namespaces
+}
+
+// getNamespacesInScope returns the namespace declarations of the open
+// elements that are still in scope, except for the ones an element declares
+// itself
+func getNamespacesInScope(stack []*streamedElement, declared []xml.Attr) []xml.Attr {
+	seen := make(map[xml.Name]bool, len(declared))
+	for _, attr := range declared {
+		seen[attr.Name] = true
+	}
+	var namespaces []xml.Attr
+	for i := len(stack) - 1; i >= 0; i-- {
+		for _, attr := range stack[i].namespaces {
+			if !seen[attr.Name] {
+				seen[attr.Name] = true
+				namespaces = append(namespaces, attr)
+			}
+		}
+	}
+	return namespaces
+}
+
+func qualifiedNodeName(node *xmlquery.Node) string {
+	if node.Prefix == "" {
+		return node.Data
+	}
+	return node.Prefix + ":" + node.Data
+}
+
+// isUnusedElement tells whether the parser doesn't use an element
+func isUnusedElement(el *xml.StartElement, stdParser *referenceParser) bool {
+	switch el.Name.Space {
+	case "oval-def":
+		switch el.Name.Local {
+		case "tests", "objects", "states", "variables":
+			return true
+		}
+	case "xccdf-1.2":
+		switch el.Name.Local {
+		case "fix":
+			system := getAttr(el, "system")
+			return system != machineConfigFixType && system != kubernetesFixType
+		case "reference":
+			return !stdParser.isRegisteredReference(getAttr(el, "href"))
+		}
+	}
+	return false
+}
+
+func getAttr(el *xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func writeToken(w io.Writer, token xml.Token) error {
+	var err error
+	switch t := token.(type) {
+	case xml.StartElement:
+		_, err = io.WriteString(w, "<"+qualifiedName(t.Name))
+		for _, attr := range t.Attr {
+			if err == nil {
+				_, err = io.WriteString(w, " "+qualifiedName(attr.Name)+`="`)
+			}
+			if err == nil {
+				err = xml.EscapeText(w, []byte(attr.Value))
+			}
+			if err == nil {
+				_, err = io.WriteString(w, `"`)
+			}
+		}
+		if err == nil {
+			_, err = io.WriteString(w, ">")
+		}
+	case xml.EndElement:
+		_, err = io.WriteString(w, "</"+qualifiedName(t.Name)+">")
+	case xml.CharData:
+		err = xml.EscapeText(w, t)
+	case xml.Comment:
+		_, err = io.WriteString(w, "<!--"+string(t)+"-->")
+	case xml.ProcInst:
+		_, err = io.WriteString(w, "<?"+t.Target+" "+string(t.Inst)+"?>")
+	case xml.Directive:
+		_, err = io.WriteString(w, "<!"+string(t)+">")
+	}
+	return err
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -3,7 +3,9 @@ package profileparser
 import (
 	"context"
 	"fmt"
+	"io"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -38,10 +40,15 @@ const (
 var log = logf.Log.WithName("profileparser")
 
 type ParserConfig struct {
-	DataStreamPath   string
+	DataStreamPath string
+	// The directory the files of a bundle with several data streams are in
+	ContentDir       string
 	ProfileBundleKey types.NamespacedName
 	Client           runtimeclient.Client
 	Scheme           *k8sruntime.Scheme
+	// Whether the obsolete objects of the bundle are only reported instead
+	// of deleted
+	DryRunGarbageCollection bool
 }
 
 func LogAndReturnError(errormsg string) error {
@@ -53,17 +60,74 @@ func GetPrefixedName(pbName, objName string) string {
 	return pbName + "-" + objName
 }
 
+// BundleContent is a data stream file of a ProfileBundle
+type BundleContent struct {
+	// The DOM of the data stream, if it was read into memory. Otherwise,
+	// the data stream opened by Open is streamed.
+	ContentDom *xmlquery.Node
+	Open       func() (io.ReadCloser, error)
+	// The file of the bundle the data stream was read from
+	File string
+	// The prefix of the names of the objects parsed out of the data stream
+	Prefix string
+	// The number of profiles and rules parsed out of the data stream, which
+	// are counted while parsing
+	Profiles int
+	Rules    int
+	// The benchmarks of the data stream, which are read while parsing
+	Benchmarks []cmpv1alpha1.ProfileBundleBenchmark
+}
+
+// GetBenchmarks returns the IDs and versions of the XCCDF benchmarks of a
+// data stream
+func GetBenchmarks(contentDom *xmlquery.Node) []cmpv1alpha1.ProfileBundleBenchmark {
+	benchmarks := []cmpv1alpha1.ProfileBundleBenchmark{}
+	for _, benchmarkObj := range xmlquery.Find(contentDom, "//xccdf-1.2:Benchmark") {
+		benchmarks = append(benchmarks, getBenchmark(benchmarkObj))
+	}
+	return benchmarks
+}
+
+// getBenchmark returns the ID and version of an XCCDF benchmark
+func getBenchmark(benchmarkObj *xmlquery.Node) cmpv1alpha1.ProfileBundleBenchmark {
+	benchmark := cmpv1alpha1.ProfileBundleBenchmark{ID: benchmarkObj.SelectAttr("id")}
+	if v := benchmarkObj.SelectElement("xccdf-1.2:version"); v != nil {
+		benchmark.Version = strings.TrimSpace(v.InnerText())
+	}
+	return benchmark
+}
+
 func ParseBundle(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, pcfg *ParserConfig) error {
-	// One go routine per type
-	errChan := make(chan error)
-	done := make(chan string)
-	var wg sync.WaitGroup
-	wg.Add(3)
+	file := pb.GetContentFiles()[0]
+	return ParseBundleContents([]BundleContent{{ContentDom: contentDom, File: file.File, Prefix: file.Prefix}}, pb, pcfg)
+}
+
+// ParseBundleContents parses all the data stream files of a bundle. The
+// objects that aren't in any of them anymore are deleted.
+func ParseBundleContents(contents []BundleContent, pb *cmpv1alpha1.ProfileBundle, pcfg *ParserConfig) error {
+	epoch := NewContentEpoch(pb)
+	if err := ParseBundleContentsInEpoch(contents, pb, epoch, pcfg); err != nil {
+		return err
+	}
+	_, err := CollectGarbage(pcfg.Client, pb, epoch, false)
+	return err
+}
+
+// NewContentEpoch returns a new epoch to parse the content of a bundle in
+func NewContentEpoch(pb *cmpv1alpha1.ProfileBundle) string {
+	return names.SimpleNameGenerator.GenerateName(fmt.Sprintf("pb-%s", pb.Name))
+}
+
+// ParseBundleContentsInEpoch parses all the data stream files of a bundle,
+// annotating the objects parsed out of them with the given epoch. The objects
+// that aren't in any of them anymore are left for CollectGarbage. The files
+// are parsed one after another, so that only one of them is read at a time.
+func ParseBundleContentsInEpoch(contents []BundleContent, pb *cmpv1alpha1.ProfileBundle, nonce string, pcfg *ParserConfig) error {
 	stdParser := newStandardParser()
-	nonce := names.SimpleNameGenerator.GenerateName(fmt.Sprintf("pb-%s", pb.Name))
-	go func() {
-		profErr := ParseProfilesAndDo(contentDom, pb, nonce, func(p *cmpv1alpha1.Profile) error {
-			err := parseAction(p, "Profile", pb, pcfg, func(found, updated interface{}) error {
+	for i := range contents {
+		content := &contents[i]
+		profileAction := func(p *cmpv1alpha1.Profile) error {
+			err := parseAction(p, "Profile", pb, content, pcfg, func(found, updated interface{}) error {
 				foundProfile, ok := found.(*cmpv1alpha1.Profile)
 				if !ok {
 					return fmt.Errorf("unexpected type")
@@ -77,27 +141,19 @@ func ParseBundle(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, pcfg
 				foundProfile.ProfilePayload = *updatedProfile.ProfilePayload.DeepCopy()
 				return pcfg.Client.Update(context.TODO(), foundProfile)
 			})
+			if err == nil {
+				content.Profiles++
+			}
 			return err
-		})
-
-		if profErr != nil {
-			errChan <- profErr
-		}
-
-		if err := deleteObsoleteItems(pcfg.Client, "Profile", pb.Name, pb.Namespace, nonce); err != nil {
-			errChan <- err
 		}
-		wg.Done()
-	}()
 
-	go func() {
-		ruleErr := ParseRulesAndDo(contentDom, stdParser, pb, nonce, func(r *cmpv1alpha1.Rule) error {
+		ruleAction := func(r *cmpv1alpha1.Rule) error {
 			if r.Annotations == nil {
 				r.Annotations = make(map[string]string)
 			}
 			r.Annotations[cmpv1alpha1.RuleIDAnnotationKey] = r.Name
 
-			err := parseAction(r, "Rule", pb, pcfg, func(found, updated interface{}) error {
+			err := parseAction(r, "Rule", pb, content, pcfg, func(found, updated interface{}) error {
 				foundRule, ok := found.(*cmpv1alpha1.Rule)
 				if !ok {
 					return fmt.Errorf("unexpected type")
@@ -108,6 +164,7 @@ func ParseBundle(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, pcfg
 				}
 
 				foundRule.Annotations = updatedRule.Annotations
+				foundRule.Labels = updatedRule.Labels
 				// if the check type has changed, add an annotation to the rule
 				// to indicate that the rule needs to be checked in TailoredProfile validation
 				if foundRule.CheckType != updatedRule.CheckType {
@@ -117,22 +174,14 @@ func ParseBundle(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, pcfg
 				foundRule.RulePayload = *updatedRule.RulePayload.DeepCopy()
 				return pcfg.Client.Update(context.TODO(), foundRule)
 			})
+			if err == nil {
+				content.Rules++
+			}
 			return err
-		})
-
-		if ruleErr != nil {
-			errChan <- ruleErr
 		}
 
-		if err := deleteObsoleteItems(pcfg.Client, "Rule", pb.Name, pb.Namespace, nonce); err != nil {
-			errChan <- err
-		}
-		wg.Done()
-	}()
-
-	go func() {
-		varErr := ParseVariablesAndDo(contentDom, pb, nonce, func(v *cmpv1alpha1.Variable) error {
-			err := parseAction(v, "Variable", pb, pcfg, func(found, updated interface{}) error {
+		variableAction := func(v *cmpv1alpha1.Variable) error {
+			return parseAction(v, "Variable", pb, content, pcfg, func(found, updated interface{}) error {
 				foundVariable, ok := found.(*cmpv1alpha1.Variable)
 				if !ok {
 					return fmt.Errorf("unexpected type")
@@ -146,35 +195,55 @@ func ParseBundle(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, pcfg
 				foundVariable.VariablePayload = *updatedVariable.VariablePayload.DeepCopy()
 				return pcfg.Client.Update(context.TODO(), foundVariable)
 			})
-			return err
-		})
-
-		if varErr != nil {
-			errChan <- varErr
 		}
 
-		if err := deleteObsoleteItems(pcfg.Client, "Variable", pb.Name, pb.Namespace, nonce); err != nil {
-			errChan <- err
+		var err error
+		if content.ContentDom != nil {
+			content.Benchmarks = GetBenchmarks(content.ContentDom)
+			err = parseInParallel(
+				func() error {
+					return ParseProfilesAndDo(content.ContentDom, pb, content.Prefix, nonce, profileAction)
+				},
+				func() error {
+					return ParseRulesAndDo(content.ContentDom, stdParser, pb, content.Prefix, nonce, ruleAction)
+				},
+				func() error {
+					return ParseVariablesAndDo(content.ContentDom, pb, nonce, variableAction)
+				},
+			)
+		} else {
+			err = streamContentAndDo(content, stdParser, pb, nonce, profileAction, ruleAction, variableAction)
 		}
-		wg.Done()
-	}()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseInParallel runs the parsing functions in a go routine each, and
+// returns the first error of any of them
+func parseInParallel(parseFns ...func() error) error {
+	// Each of the go routines sends one error at most
+	errChan := make(chan error, len(parseFns))
+	var wg sync.WaitGroup
+	wg.Add(len(parseFns))
+	for _, parse := range parseFns {
+		go func(parse func() error) {
+			defer wg.Done()
+			if err := parse(); err != nil {
+				errChan <- err
+			}
+		}(parse)
+	}
 
-	// Wait for the go routines to end and close the "done" channel
+	// Close the channel once the go routines end, so that receiving from
+	// it returns nil if none of them failed
 	go func() {
 		wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		// carry on
-		break
-	case err := <-errChan:
 		close(errChan)
-		return err
-	}
-
-	return nil
+	}()
+	return <-errChan
 }
 
 type parsedItemIface interface {
@@ -182,10 +251,17 @@ type parsedItemIface interface {
 	k8sruntime.Object
 }
 
-func parseAction(parsedItem parsedItemIface, kind string, pb *cmpv1alpha1.ProfileBundle, pcfg *ParserConfig, updateFn func(found, updated interface{}) error) error {
+func parseAction(parsedItem parsedItemIface, kind string, pb *cmpv1alpha1.ProfileBundle, content *BundleContent, pcfg *ParserConfig, updateFn func(found, updated interface{}) error) error {
 	// overwrite name
 	itemName := parsedItem.GetName()
-	parsedItem.SetName(GetPrefixedName(pb.Name, itemName))
+	parsedItem.SetName(GetPrefixedName(content.Prefix, itemName))
+
+	annotations := parsedItem.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[cmpv1alpha1.ContentFileAnnotation] = content.File
+	parsedItem.SetAnnotations(annotations)
 
 	labels := parsedItem.GetLabels()
 	if labels == nil {
@@ -224,6 +300,13 @@ func createOrUpdate(cli runtimeclient.Client, kind string, key types.NamespacedN
 		return err
 	}
 
+	// The object might be left over from a deleted bundle of the same name,
+	// make sure it's owned by the current one so it's not garbage collected
+	// along with the deleted bundle
+	if updated, ok := updateTo.(metav1.Object); ok {
+		found.SetOwnerReferences(updated.GetOwnerReferences())
+	}
+
 	// Object exist, call up to update
 	if err := updateFn(found, updateTo); err != nil {
 		return err
@@ -232,7 +315,36 @@ func createOrUpdate(cli runtimeclient.Client, kind string, key types.NamespacedN
 	return nil
 }
 
-func deleteObsoleteItems(cli runtimeclient.Client, kind string, pbName, namespace string, nonce string) error {
+// CollectGarbage finds the Profiles, Rules and Variables of the bundle that
+// weren't parsed out of its content in the given epoch, and deletes them
+// unless it's a dry run
+func CollectGarbage(cli runtimeclient.Client, pb *cmpv1alpha1.ProfileBundle, epoch string, dryRun bool) (*cmpv1alpha1.ProfileBundleGarbageCollectionStatus, error) {
+	status := &cmpv1alpha1.ProfileBundleGarbageCollectionStatus{DryRun: dryRun}
+	for _, kind := range []string{"Profile", "Rule", "Variable"} {
+		obsolete, err := getObsoleteItems(cli, kind, pb.Name, pb.Namespace, epoch)
+		if err != nil {
+			return nil, err
+		}
+		for i := range obsolete {
+			item := &obsolete[i]
+			if !dryRun {
+				log.Info("Deleting object no longer used by the current profileBundle", "kind", kind, "name", item.GetName())
+				if err := cli.Delete(context.TODO(), item); err != nil && !errors.IsNotFound(err) {
+					return nil, err
+				}
+			}
+			status.ObsoleteObjectCount++
+			if len(status.ObsoleteObjects) < cmpv1alpha1.MaxGarbageCollectionObjects {
+				status.ObsoleteObjects = append(status.ObsoleteObjects, kind+"/"+item.GetName())
+			}
+		}
+	}
+	return status, nil
+}
+
+// getObsoleteItems returns the objects of a kind that are owned by the bundle,
+// but weren't parsed in the given epoch
+func getObsoleteItems(cli runtimeclient.Client, kind string, pbName, namespace string, nonce string) ([]unstructured.Unstructured, error) {
 	list := unstructured.UnstructuredList{}
 	list.SetGroupVersionKind(schema.GroupVersionKind{
 		Group:   cmpv1alpha1.SchemeGroupVersion.Group,
@@ -246,31 +358,21 @@ func deleteObsoleteItems(cli runtimeclient.Client, kind string, pbName, namespac
 
 	log.Info("Checking for unused object", "kind", kind, "owner", pbName, "namespace", namespace)
 	if err := cli.List(context.TODO(), &list, inNs, withPbOwnerLabel); err != nil {
-		return err
+		return nil, err
 	}
 
 	// TODO: Using the annotations forces us to iterate over all objects of
 	// a type. This might be inefficient with a large number of objects,
 	// if this ever becomes a performance problem, use labels instead
 	// with a short version of the hash
-	for i := range list.Items {
-		err := deleteIfNotCurrentDigest(cli, nonce, &list.Items[i])
-		if err != nil {
-			return err
+	var obsolete []unstructured.Unstructured
+	for _, item := range list.Items {
+		if item.GetAnnotations()[cmpv1alpha1.ProfileImageDigestAnnotation] != nonce {
+			obsolete = append(obsolete, item)
 		}
 	}
 
-	return nil
-}
-
-func deleteIfNotCurrentDigest(client runtimeclient.Client, imageDigest string, item *unstructured.Unstructured) error {
-	itemDigest := item.GetAnnotations()[cmpv1alpha1.ProfileImageDigestAnnotation]
-	if itemDigest == imageDigest {
-		return nil
-	}
-
-	log.Info("Deleting object no longer used by the current profileBundle", "kind", item.GetKind(), "name", item.GetName())
-	return client.Delete(context.TODO(), item)
+	return obsolete, nil
 }
 
 func getVariableType(varNode *xmlquery.Node) cmpv1alpha1.VariableType {
@@ -290,11 +392,11 @@ func getVariableType(varNode *xmlquery.Node) cmpv1alpha1.VariableType {
 	return cmpv1alpha1.VarTypeString
 }
 
-func ParseProfilesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
+func ParseProfilesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, prefix, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
 	benchmarks := xmlquery.Find(contentDom, "//xccdf-1.2:Benchmark")
 	for _, bench := range benchmarks {
 		productType, productName := getProductTypeAndName(bench, cmpv1alpha1.ScanTypeNode, "")
-		if err := parseProfileFromNode(bench, pb, productType, productName, nonce, action); err != nil {
+		if err := parseProfileFromNode(bench, pb, productType, productName, prefix, nonce, action); err != nil {
 			return err
 		}
 	}
@@ -302,102 +404,148 @@ func ParseProfilesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle
 	return nil
 }
 
-func parseProfileFromNode(profileRoot *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, defType cmpv1alpha1.ComplianceScanType, defName, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
+func parseProfileFromNode(profileRoot *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, defType cmpv1alpha1.ComplianceScanType, defName, prefix, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
 	profileObjs := xmlquery.Find(profileRoot, "//xccdf-1.2:Profile")
 	for _, profileObj := range profileObjs {
-
-		id := profileObj.SelectAttr("id")
-		if id == "" {
-			return LogAndReturnError("no id in profile")
-		}
-		title := profileObj.SelectElement("xccdf-1.2:title")
-		if title == nil {
-			return LogAndReturnError("no title in profile")
-		}
-		description := profileObj.SelectElement("xccdf-1.2:description")
-		if description == nil {
-			return LogAndReturnError("no description in profile")
+		if err := parseProfileAndDo(profileObj, pb, defType, defName, prefix, nonce, action); err != nil {
+			return err
 		}
-		v := profileObj.SelectElement("xccdf-1.2:version")
-		var version string
-		if v != nil {
-			version = v.InnerText()
+	}
+
+	return nil
+}
+
+// parseProfilesAndDo parses the profiles sent on the channel, until it's
+// closed
+func parseProfilesAndDo(profiles <-chan profileNode, pb *cmpv1alpha1.ProfileBundle, prefix, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
+	for profile := range profiles {
+		if err := parseProfileAndDo(profile.node, pb, profile.productType, profile.productName, prefix, nonce, action); err != nil {
+			return err
 		}
-		log.Info("Found profile", "id", id)
+	}
+	return nil
+}
 
-		// In case the profile sets its own CPE string
-		productType, productName := getProductTypeAndName(profileObj, defType, defName)
+// parseProfileAndDo parses a profile, whose product is the product of its
+// benchmark unless it sets its own CPE string
+func parseProfileAndDo(profileObj *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, defType cmpv1alpha1.ComplianceScanType, defName, prefix, nonce string, action func(p *cmpv1alpha1.Profile) error) error {
+	id := profileObj.SelectAttr("id")
+	if id == "" {
+		return LogAndReturnError("no id in profile")
+	}
+	title := profileObj.SelectElement("xccdf-1.2:title")
+	if title == nil {
+		return LogAndReturnError("no title in profile")
+	}
+	description := profileObj.SelectElement("xccdf-1.2:description")
+	if description == nil {
+		return LogAndReturnError("no description in profile")
+	}
+	v := profileObj.SelectElement("xccdf-1.2:version")
+	var version string
+	if v != nil {
+		version = v.InnerText()
+	}
+	log.Info("Found profile", "id", id)
+
+	// In case the profile sets its own CPE string
+	productType, productName := getProductTypeAndName(profileObj, defType, defName)
 
-		if strings.EqualFold(string(productType), "Platform") && strings.EqualFold(utils.GetPlatform(), "ROSA") && utils.IsHostedControlPlane() {
-			log.Info("Skipping platform profile creation because it is not supported on this platform", "id", xccdf.GetProfileNameFromID(id))
+	if strings.EqualFold(string(productType), "Platform") && strings.EqualFold(utils.GetPlatform(), "ROSA") && utils.IsHostedControlPlane() {
+		log.Info("Skipping platform profile creation because it is not supported on this platform", "id", xccdf.GetProfileNameFromID(id))
+		return nil
+	}
+	log.Info("Platform info", "type", productType, "name", productName)
+
+	ruleObjs := profileObj.SelectElements("xccdf-1.2:select")
+	selectedrules := []cmpv1alpha1.ProfileRule{}
+	for _, ruleObj := range ruleObjs {
+		idref := ruleObj.SelectAttr("idref")
+		if idref == "" {
+			log.Info("no idref in rule")
 			continue
 		}
-		log.Info("Platform info", "type", productType, "name", productName)
-
-		ruleObjs := profileObj.SelectElements("xccdf-1.2:select")
-		selectedrules := []cmpv1alpha1.ProfileRule{}
-		for _, ruleObj := range ruleObjs {
-			idref := ruleObj.SelectAttr("idref")
-			if idref == "" {
-				log.Info("no idref in rule")
-				continue
-			}
-			selected := ruleObj.SelectAttr("selected")
-			if selected == "true" {
-				ruleName := GetPrefixedName(pb.Name, xccdf.GetRuleNameFromID(idref))
-				selectedrules = append(selectedrules, cmpv1alpha1.NewProfileRule(ruleName))
-			}
+		selected := ruleObj.SelectAttr("selected")
+		if selected == "true" {
+			ruleName := GetPrefixedName(prefix, xccdf.GetRuleNameFromID(idref))
+			selectedrules = append(selectedrules, cmpv1alpha1.NewProfileRule(ruleName))
 		}
+	}
 
-		selectedvalues := []cmpv1alpha1.ProfileValue{}
-		valueObjs := profileObj.SelectElements("xccdf-1.2:set-value")
-		for _, valueObj := range valueObjs {
-			idref := valueObj.SelectAttr("idref")
-			if idref == "" {
-				log.Info("no idref in rule")
-				continue
-			}
-			selectedvalues = append(selectedvalues, cmpv1alpha1.ProfileValue(idref))
+	selectedvalues := []cmpv1alpha1.ProfileValue{}
+	valueObjs := profileObj.SelectElements("xccdf-1.2:set-value")
+	for _, valueObj := range valueObjs {
+		idref := valueObj.SelectAttr("idref")
+		if idref == "" {
+			log.Info("no idref in rule")
+			continue
 		}
+		selectedvalues = append(selectedvalues, cmpv1alpha1.ProfileValue(idref))
+	}
 
-		p := cmpv1alpha1.Profile{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       "Profile",
-				APIVersion: cmpv1alpha1.SchemeGroupVersion.String(),
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      xccdf.GetProfileNameFromID(id),
-				Namespace: pb.Namespace,
-				Annotations: map[string]string{
-					cmpv1alpha1.ProductAnnotation:     productName,
-					cmpv1alpha1.ProductTypeAnnotation: string(productType),
-				},
-				Labels: map[string]string{
-					cmpv1alpha1.ProfileGuidLabel: xccdf.GetProfileUniqueID(productName, id),
-				},
+	p := cmpv1alpha1.Profile{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Profile",
+			APIVersion: cmpv1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      xccdf.GetProfileNameFromID(id),
+			Namespace: pb.Namespace,
+			Annotations: map[string]string{
+				cmpv1alpha1.ProductAnnotation:     productName,
+				cmpv1alpha1.ProductTypeAnnotation: string(productType),
 			},
-			ProfilePayload: cmpv1alpha1.ProfilePayload{
-				ID:          id,
-				Title:       title.InnerText(),
-				Description: utils.XmlNodeAsMarkdown(description),
-				Rules:       selectedrules,
-				Values:      selectedvalues,
-				Version:     version,
+			Labels: map[string]string{
+				cmpv1alpha1.ProfileGuidLabel: xccdf.GetProfileUniqueID(productName, id),
 			},
-		}
+		},
+		ProfilePayload: cmpv1alpha1.ProfilePayload{
+			ID:          id,
+			Title:       title.InnerText(),
+			Description: utils.XmlNodeAsMarkdown(description),
+			Rules:       selectedrules,
+			Values:      selectedvalues,
+			Version:     version,
+		},
+	}
 
-		annotateWithNonce(&p, nonce)
+	annotateDeprecation(&p, profileObj, prefix)
+	annotateWithNonce(&p, nonce)
 
-		err := action(&p)
-		if err != nil {
-			log.Error(err, "couldn't execute action")
-			return err
-		}
+	err := action(&p)
+	if err != nil {
+		log.Error(err, "couldn't execute action")
+		return err
 	}
-
 	return nil
 }
 
+// annotateDeprecation marks profiles whose latest status is deprecated. A
+// deprecated profile that extends another profile is kept as an alias to it
+// after a rename, so it's annotated as replaced by the profile it extends.
+func annotateDeprecation(p *cmpv1alpha1.Profile, profileObj *xmlquery.Node, prefix string) {
+	if getLatestStatus(profileObj) != "deprecated" {
+		return
+	}
+	p.Annotations[cmpv1alpha1.ProfileDeprecatedAnnotation] = "true"
+	if extends := profileObj.SelectAttr("extends"); extends != "" {
+		replacement := GetPrefixedName(prefix, xccdf.GetProfileNameFromID(extends))
+		p.Annotations[cmpv1alpha1.ProfileReplacedByAnnotation] = replacement
+	}
+}
+
+// getLatestStatus returns the status of an item with the latest date. The
+// dates are in ISO 8601 format, so they're compared as strings.
+func getLatestStatus(item *xmlquery.Node) string {
+	var status, date string
+	for _, s := range item.SelectElements("xccdf-1.2:status") {
+		if d := s.SelectAttr("date"); d >= date {
+			status, date = strings.TrimSpace(s.InnerText()), d
+		}
+	}
+	return status
+}
+
 func getProductTypeAndName(root *xmlquery.Node, defaultType cmpv1alpha1.ComplianceScanType, defaultName string) (cmpv1alpha1.ComplianceScanType, string) {
 	p := root.SelectElement("xccdf-1.2:platform")
 
@@ -446,6 +594,12 @@ func parseProductTypeAndName(idref string, defaultType cmpv1alpha1.ComplianceSca
 }
 
 func ParseVariablesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, nonce string, action func(v *cmpv1alpha1.Variable) error) error {
+	return parseVariablesAndDo(sendNodes(contentDom.SelectElements("//xccdf-1.2:Value")), pb, nonce, action)
+}
+
+// parseVariablesAndDo parses the variables sent on the channel, until it's
+// closed
+func parseVariablesAndDo(varchan <-chan *xmlquery.Node, pb *cmpv1alpha1.ProfileBundle, nonce string, action func(v *cmpv1alpha1.Variable) error) error {
 	var wg sync.WaitGroup
 	processVar := func(vchan <-chan *xmlquery.Node, errs chan error) {
 		for varObj := range vchan {
@@ -498,6 +652,12 @@ func ParseVariablesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundl
 				continue
 			}
 
+			if err := parseVarConstraints(varObj, &v); err != nil {
+				// The variable is still usable, just not validated
+				log.Error(err, "couldn't parse variable constraints", "id", id)
+				v.Constraints = nil
+			}
+
 			annotateWithNonce(&v, nonce)
 
 			err = action(&v)
@@ -510,10 +670,8 @@ func ParseVariablesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundl
 		wg.Done()
 	}
 
-	varchan := make(chan *xmlquery.Node)
 	errchan := make(chan error)
 	waitchan := make(chan struct{})
-	varObjs := contentDom.SelectElements("//xccdf-1.2:Value")
 	nworkers := 5
 	wg.Add(5)
 	for i := 0; i < nworkers; i++ {
@@ -521,10 +679,6 @@ func ParseVariablesAndDo(contentDom *xmlquery.Node, pb *cmpv1alpha1.ProfileBundl
 	}
 
 	go func() {
-		for _, varObj := range varObjs {
-			varchan <- varObj
-		}
-		close(varchan)
 		wg.Wait()
 		close(waitchan)
 	}()
@@ -559,30 +713,65 @@ func parseVarValues(varNode *xmlquery.Node, v *cmpv1alpha1.Variable) error {
 	return nil
 }
 
-func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *cmpv1alpha1.ProfileBundle, nonce string, action func(p *cmpv1alpha1.Rule) error) error {
-	var wg sync.WaitGroup
-	questionsTable := utils.NewOcilQuestionTable(contentDom)
-	defTable := utils.NewDefHashTable(contentDom)
-	profileTable := utils.NewProfileTable(contentDom)
-
-	allValues := xmlquery.Find(contentDom, "//xccdf-1.2:Value")
-	valuesList := make(map[string]string)
-	for _, variable := range allValues {
-		for _, val := range variable.SelectElements("//xccdf-1.2:value") {
-			if val.SelectAttr("hidden") == "true" {
-				// this is typically used for functions
+// parseVarConstraints extracts the bounds, pattern and enforced choices of
+// the default value of the variable, ignoring the ones for selectors
+func parseVarConstraints(varNode *xmlquery.Node, v *cmpv1alpha1.Variable) error {
+	constraints := cmpv1alpha1.ValueConstraints{}
+
+	for _, bound := range []struct {
+		element string
+		target  **int
+	}{
+		{"lower-bound", &constraints.LowerBound},
+		{"upper-bound", &constraints.UpperBound},
+	} {
+		for _, node := range varNode.SelectElements("//xccdf-1.2:" + bound.element) {
+			if node.SelectAttr("selector") != "" {
 				continue
 			}
-			if val.SelectAttr("selector") == "" {
-				// It is not an enum choice, but a default value instead
-				if strings.HasPrefix(variable.SelectAttr("id"), valuePrefix) {
-					valuesList[strings.TrimPrefix(variable.SelectAttr("id"), valuePrefix)] = val.OutputXML(false)
-				}
-
+			value, err := strconv.Atoi(strings.TrimSpace(node.InnerText()))
+			if err != nil {
+				return fmt.Errorf("wrongly formatted %s for variable %s: %w", bound.element, v.ID, err)
 			}
+			*bound.target = &value
 		}
 	}
 
+	for _, node := range varNode.SelectElements("//xccdf-1.2:match") {
+		if node.SelectAttr("selector") != "" {
+			continue
+		}
+		constraints.Match = strings.TrimSpace(node.InnerText())
+	}
+
+	for _, node := range varNode.SelectElements("//xccdf-1.2:choices") {
+		if node.SelectAttr("selector") != "" || node.SelectAttr("mustMatch") != "true" {
+			continue
+		}
+		for _, choice := range node.SelectElements("//xccdf-1.2:choice") {
+			constraints.Choices = append(constraints.Choices, choice.InnerText())
+		}
+	}
+
+	if constraints.LowerBound != nil || constraints.UpperBound != nil || constraints.Match != "" || len(constraints.Choices) > 0 {
+		v.Constraints = &constraints
+	}
+	return nil
+}
+
+func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *cmpv1alpha1.ProfileBundle, prefix, nonce string, action func(p *cmpv1alpha1.Rule) error) error {
+	return parseRulesAndDo(sendNodes(xmlquery.Find(contentDom, "//xccdf-1.2:Rule")), newContentIndex(contentDom), stdParser, pb, prefix, nonce, action)
+}
+
+// parseRulesAndDo parses the rules sent on the channel, until it's closed.
+// The index holds the elements of the data stream the rules refer to.
+func parseRulesAndDo(rulechan <-chan *xmlquery.Node, index *contentIndex, stdParser *referenceParser, pb *cmpv1alpha1.ProfileBundle, prefix, nonce string, action func(p *cmpv1alpha1.Rule) error) error {
+	var wg sync.WaitGroup
+	questionsTable := index.questions
+	defTable := index.definitions
+	profileTable := index.profiles
+	valuesList := index.values
+
 	processRule := func(rchan <-chan *xmlquery.Node, errs chan error) {
 		for ruleObj := range rchan {
 			id := ruleObj.SelectAttr("id")
@@ -645,6 +834,7 @@ func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *
 				log.Error(err, "couldn't annotate a rule")
 				// We continue even if there's an error.
 			}
+			controls := stdParser.parseControls(ruleObj)
 
 			instructions, valuesRendered := utils.GetInstructionsForRule(ruleObj, questionsTable, valuesList)
 
@@ -667,7 +857,7 @@ func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *
 						log.Info("no id in profile")
 						continue
 					}
-					profileList = append(profileList, GetPrefixedName(pb.Name, xccdf.GetProfileNameFromID(profileID)))
+					profileList = append(profileList, GetPrefixedName(prefix, xccdf.GetProfileNameFromID(profileID)))
 				}
 				if len(profileList) > 0 {
 					annotations[cmpv1alpha1.RuleProfileAnnotationKey] = strings.Join(profileList, ",")
@@ -683,11 +873,13 @@ func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *
 					Name:        xccdf.GetRuleNameFromID(id),
 					Namespace:   pb.Namespace,
 					Annotations: annotations,
+					Labels:      getControlLabels(controls),
 				},
 				RulePayload: cmpv1alpha1.RulePayload{
 					ID:             id,
 					Title:          title.InnerText(),
 					AvailableFixes: nil,
+					Controls:       controls,
 				},
 			}
 			var valueRendered []string
@@ -748,10 +940,8 @@ func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *
 		wg.Done()
 	}
 
-	rulechan := make(chan *xmlquery.Node)
 	errchan := make(chan error)
 	waitchan := make(chan struct{})
-	ruleObjs := xmlquery.Find(contentDom, "//xccdf-1.2:Rule")
 	nworkers := 5
 	wg.Add(5)
 	for i := 0; i < nworkers; i++ {
@@ -759,10 +949,6 @@ func ParseRulesAndDo(contentDom *xmlquery.Node, stdParser *referenceParser, pb *
 	}
 
 	go func() {
-		for _, varObj := range ruleObjs {
-			rulechan <- varObj
-		}
-		close(rulechan)
 		wg.Wait()
 		close(waitchan)
 	}()
@@ -862,6 +1048,55 @@ func (p *referenceParser) registerFormatter(formatter annotationsFormatterFn) {
 func (p *referenceParser) parseXmlNode(ruleObj *xmlquery.Node) (map[string]string, error) {
 	ruleAnnotations := make(map[string]string)
 
+	p.forEachReference(ruleObj, func(std, ctrl string) {
+		for _, formatter := range p.annotationFormatters {
+			formatter(ruleAnnotations, std, ctrl)
+		}
+	})
+
+	return ruleAnnotations, nil
+}
+
+// parseControls returns the controls of the registered standards that the
+// references of the rule list, in the order the rule lists them
+func (p *referenceParser) parseControls(ruleObj *xmlquery.Node) []cmpv1alpha1.RuleControls {
+	var controls []cmpv1alpha1.RuleControls
+	stdIndex := make(map[string]int)
+
+	p.forEachReference(ruleObj, func(std, ctrl string) {
+		ctrl = strings.TrimSpace(ctrl)
+		if ctrl == "" {
+			return
+		}
+		i, ok := stdIndex[std]
+		if !ok {
+			i = len(controls)
+			stdIndex[std] = i
+			controls = append(controls, cmpv1alpha1.RuleControls{Standard: std})
+		}
+		for _, c := range controls[i].Controls {
+			if c == ctrl {
+				return
+			}
+		}
+		controls[i].Controls = append(controls[i].Controls, ctrl)
+	})
+
+	return controls
+}
+
+// isRegisteredReference tells whether a reference refers to one of the
+// registered standards
+func (p *referenceParser) isRegisteredReference(href string) bool {
+	for _, std := range p.registeredStds {
+		if std.hrefMatcher.MatchString(href) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *referenceParser) forEachReference(ruleObj *xmlquery.Node, fn func(std, ctrl string)) {
 	for _, refEl := range ruleObj.SelectElements("xccdf-1.2:reference") {
 		href := refEl.SelectAttr("href")
 		if href == "" {
@@ -869,17 +1104,28 @@ func (p *referenceParser) parseXmlNode(ruleObj *xmlquery.Node) (map[string]strin
 		}
 
 		for _, std := range p.registeredStds {
-			if !std.hrefMatcher.MatchString(href) {
-				continue
+			if std.hrefMatcher.MatchString(href) {
+				fn(std.Name, refEl.InnerText())
 			}
+		}
+	}
+}
 
-			for _, formatter := range p.annotationFormatters {
-				formatter(ruleAnnotations, std.Name, refEl.InnerText())
+// getControlLabels returns the labels that make the rule selectable by the
+// controls it implements
+func getControlLabels(controls []cmpv1alpha1.RuleControls) map[string]string {
+	if len(controls) == 0 {
+		return nil
+	}
+	labels := make(map[string]string)
+	for _, std := range controls {
+		for _, ctrl := range std.Controls {
+			for _, key := range cmpv1alpha1.GetControlLabelKeys(std.Standard, ctrl) {
+				labels[key] = "true"
 			}
 		}
 	}
-
-	return ruleAnnotations, nil
+	return labels
 }
 
 func profileOperatorFormatter(annotations map[string]string, std, ctrl string) {
@@ -2,7 +2,10 @@ package profileparser
 
 import (
 	"context"
+	"io"
 	"os"
+	"sort"
+	"strings"
 
 	cmpv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
 	"github.com/antchfx/xmlquery"
@@ -19,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apiserver/pkg/storage/names"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // FIXME: code duplication
@@ -391,6 +395,132 @@ var _ = Describe("Testing ParseBundle", func() {
 	})
 })
 
+var _ = Describe("Testing ParseBundleContents", func() {
+	var pb *cmpv1alpha1.ProfileBundle
+
+	getProfile := func(name string) (*cmpv1alpha1.Profile, error) {
+		p := &cmpv1alpha1.Profile{}
+		err := client.Get(context.TODO(), types.NamespacedName{Namespace: testNamespace, Name: name}, p)
+		return p, err
+	}
+
+	BeforeEach(func() {
+		pb = &cmpv1alpha1.ProfileBundle{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: testNamespace,
+				Name:      "test-multi",
+			},
+			Spec: cmpv1alpha1.ProfileBundleSpec{
+				ContentImage: pInput.pb.Spec.ContentImage,
+				ContentFiles: []cmpv1alpha1.ProfileBundleContentFile{
+					{File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+					{File: "ssg-ocp4-ds-new-modified.xml", Prefix: "multi-modified"},
+				},
+			},
+		}
+		contents := []BundleContent{
+			{ContentDom: pInput.contentDom, File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+			{ContentDom: pInputModified.contentDom, File: "ssg-ocp4-ds-new-modified.xml", Prefix: "multi-modified"},
+		}
+		Expect(ParseBundleContents(contents, pb, pInput.pcfg)).To(Succeed())
+	})
+
+	It("prefixes the objects with the prefix of their file", func() {
+		newProfile, err := getProfile("multi-new-coreos-ncp")
+		Expect(err).To(BeNil())
+		Expect(newProfile.Annotations).To(HaveKeyWithValue(cmpv1alpha1.ContentFileAnnotation, "ssg-ocp4-ds-new.xml"))
+		Expect(newProfile.Labels).To(HaveKeyWithValue(cmpv1alpha1.ProfileBundleOwnerLabel, "test-multi"))
+		Expect(newProfile.Rules).ToNot(BeEmpty())
+		for _, rule := range newProfile.Rules {
+			Expect(string(rule)).To(HavePrefix("multi-new-"))
+		}
+
+		modifiedProfile, err := getProfile("multi-modified-coreos-ncp-modified")
+		Expect(err).To(BeNil())
+		Expect(modifiedProfile.Annotations).To(HaveKeyWithValue(cmpv1alpha1.ContentFileAnnotation, "ssg-ocp4-ds-new-modified.xml"))
+
+		err, found := doesRuleExist(client, testNamespace, "multi-modified-chronyd-client-only")
+		Expect(err).To(BeNil())
+		Expect(found).To(BeTrue())
+	})
+
+	It("counts the profiles and rules parsed out of each file", func() {
+		contents := []BundleContent{
+			{ContentDom: pInput.contentDom, File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+		}
+		Expect(ParseBundleContents(contents, pb, pInput.pcfg)).To(Succeed())
+
+		profiles := cmpv1alpha1.ProfileList{}
+		Expect(client.List(context.TODO(), &profiles, runtimeclient.MatchingLabels{
+			cmpv1alpha1.ProfileBundleOwnerLabel: pb.Name,
+		})).To(Succeed())
+		Expect(contents[0].Profiles).To(Equal(len(profiles.Items)))
+		Expect(contents[0].Rules).ToNot(BeZero())
+	})
+
+	It("deletes the objects of a file that's no longer in the bundle", func() {
+		pb.Spec.ContentFiles = pb.Spec.ContentFiles[:1]
+		contents := []BundleContent{
+			{ContentDom: pInput.contentDom, File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+		}
+		Expect(ParseBundleContents(contents, pb, pInput.pcfg)).To(Succeed())
+
+		_, err := getProfile("multi-new-coreos-ncp")
+		Expect(err).To(BeNil())
+		_, err = getProfile("multi-modified-coreos-ncp-modified")
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("only reports the obsolete objects in a dry run", func() {
+		pb.Spec.ContentFiles = pb.Spec.ContentFiles[:1]
+		contents := []BundleContent{
+			{ContentDom: pInput.contentDom, File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+		}
+		epoch := NewContentEpoch(pb)
+		Expect(ParseBundleContentsInEpoch(contents, pb, epoch, pInput.pcfg)).To(Succeed())
+
+		gc, err := CollectGarbage(client, pb, epoch, true)
+		Expect(err).To(BeNil())
+		Expect(gc.DryRun).To(BeTrue())
+		Expect(gc.ObsoleteObjectCount).To(BeNumerically(">", cmpv1alpha1.MaxGarbageCollectionObjects))
+		Expect(gc.ObsoleteObjects).To(HaveLen(cmpv1alpha1.MaxGarbageCollectionObjects))
+		Expect(gc.ObsoleteObjects).To(ContainElement("Profile/multi-modified-coreos-ncp-modified"))
+		_, err = getProfile("multi-modified-coreos-ncp-modified")
+		Expect(err).To(BeNil())
+
+		gc, err = CollectGarbage(client, pb, epoch, false)
+		Expect(err).To(BeNil())
+		Expect(gc.DryRun).To(BeFalse())
+		Expect(gc.ObsoleteObjects).To(ContainElement("Profile/multi-modified-coreos-ncp-modified"))
+		_, err = getProfile("multi-modified-coreos-ncp-modified")
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+
+		gc, err = CollectGarbage(client, pb, epoch, false)
+		Expect(err).To(BeNil())
+		Expect(gc.ObsoleteObjectCount).To(BeZero())
+	})
+
+	It("owns the objects left over from a deleted bundle of the same name", func() {
+		profile, err := getProfile("multi-new-coreos-ncp")
+		Expect(err).To(BeNil())
+		profile.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: cmpv1alpha1.SchemeGroupVersion.String(), Kind: "ProfileBundle", Name: pb.Name, UID: "deleted-bundle"},
+		}
+		Expect(client.Update(context.TODO(), profile)).To(Succeed())
+
+		pb.UID = "current-bundle"
+		contents := []BundleContent{
+			{ContentDom: pInput.contentDom, File: "ssg-ocp4-ds-new.xml", Prefix: "multi-new"},
+		}
+		Expect(ParseBundleContents(contents, pb, pInput.pcfg)).To(Succeed())
+
+		profile, err = getProfile("multi-new-coreos-ncp")
+		Expect(err).To(BeNil())
+		Expect(profile.OwnerReferences).To(HaveLen(1))
+		Expect(profile.OwnerReferences[0].UID).To(BeEquivalentTo("current-bundle"))
+	})
+})
+
 var _ = Describe("Testing parse profiles", func() {
 	var (
 		profileList []cmpv1alpha1.Profile
@@ -417,7 +547,7 @@ var _ = Describe("Testing parse profiles", func() {
 		}()
 
 		nonce := names.SimpleNameGenerator.GenerateName("pb-")
-		err := ParseProfilesAndDo(pInput.contentDom, pInput.pb, nonce, profileAdder)
+		err := ParseProfilesAndDo(pInput.contentDom, pInput.pb, pInput.pb.Name, nonce, profileAdder)
 		Expect(err).To(BeNil())
 		close(profchan)
 		<-done
@@ -483,6 +613,57 @@ var _ = Describe("Testing parse profiles", func() {
 	})
 })
 
+var _ = Describe("Testing parse benchmarks", func() {
+	It("Has the ID and version of the benchmark", func() {
+		Expect(GetBenchmarks(pInput.contentDom)).To(Equal([]cmpv1alpha1.ProfileBundleBenchmark{
+			{ID: "xccdf_org.ssgproject.content_benchmark_OCP-4", Version: "0.1.51"},
+		}))
+	})
+})
+
+var _ = Describe("Testing parse profile deprecation", func() {
+	parseProfiles := func(profilesXML string) []cmpv1alpha1.Profile {
+		dom, err := xmlquery.Parse(strings.NewReader(`<xccdf-1.2:Benchmark xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2">` +
+			profilesXML + `</xccdf-1.2:Benchmark>`))
+		Expect(err).To(BeNil())
+		profiles := []cmpv1alpha1.Profile{}
+		err = parseProfileFromNode(dom, pInput.pb, cmpv1alpha1.ScanTypeNode, "rhcos4", "rhcos4", "nonce", func(p *cmpv1alpha1.Profile) error {
+			profiles = append(profiles, *p)
+			return nil
+		})
+		Expect(err).To(BeNil())
+		return profiles
+	}
+
+	It("Annotates a deprecated profile with the profile it was renamed to", func() {
+		profiles := parseProfiles(`<xccdf-1.2:Profile id="xccdf_org.ssgproject.content_profile_e8-new">
+				<xccdf-1.2:title>E8</xccdf-1.2:title>
+				<xccdf-1.2:description>E8</xccdf-1.2:description>
+			</xccdf-1.2:Profile>
+			<xccdf-1.2:Profile id="xccdf_org.ssgproject.content_profile_e8" extends="xccdf_org.ssgproject.content_profile_e8-new">
+				<xccdf-1.2:status date="2020-01-01">accepted</xccdf-1.2:status>
+				<xccdf-1.2:status date="2024-01-01">deprecated</xccdf-1.2:status>
+				<xccdf-1.2:title>E8</xccdf-1.2:title>
+				<xccdf-1.2:description>E8</xccdf-1.2:description>
+			</xccdf-1.2:Profile>`)
+		Expect(profiles).To(HaveLen(2))
+		Expect(profiles[0].Annotations).ToNot(HaveKey(cmpv1alpha1.ProfileDeprecatedAnnotation))
+		Expect(profiles[1].Annotations).To(HaveKeyWithValue(cmpv1alpha1.ProfileDeprecatedAnnotation, "true"))
+		Expect(profiles[1].Annotations).To(HaveKeyWithValue(cmpv1alpha1.ProfileReplacedByAnnotation, "rhcos4-e8-new"))
+	})
+
+	It("Only uses the latest status of a profile", func() {
+		profiles := parseProfiles(`<xccdf-1.2:Profile id="xccdf_org.ssgproject.content_profile_e8">
+				<xccdf-1.2:status date="2024-01-01">accepted</xccdf-1.2:status>
+				<xccdf-1.2:status date="2020-01-01">deprecated</xccdf-1.2:status>
+				<xccdf-1.2:title>E8</xccdf-1.2:title>
+				<xccdf-1.2:description>E8</xccdf-1.2:description>
+			</xccdf-1.2:Profile>`)
+		Expect(profiles).To(HaveLen(1))
+		Expect(profiles[0].Annotations).ToNot(HaveKey(cmpv1alpha1.ProfileDeprecatedAnnotation))
+	})
+})
+
 var _ = Describe("Testing parse variables", func() {
 	var (
 		varList []cmpv1alpha1.Variable
@@ -568,6 +749,66 @@ var _ = Describe("Testing parse variables", func() {
 		It("Has the expected type", func() {
 			Expect(sshdPrivSepVar.Type).To(BeEquivalentTo("string"))
 		})
+
+		It("Has no constraints", func() {
+			Expect(sshdPrivSepVar.Constraints).To(BeNil())
+		})
+	})
+
+	Context("Variables with constraints", func() {
+		parseConstraints := func(valueXML string) (*cmpv1alpha1.Variable, error) {
+			dom, err := xmlquery.Parse(strings.NewReader(`<xccdf-1.2:Benchmark xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2">` +
+				valueXML + `</xccdf-1.2:Benchmark>`))
+			Expect(err).To(BeNil())
+			v := &cmpv1alpha1.Variable{}
+			return v, parseVarConstraints(xmlquery.FindOne(dom, "//xccdf-1.2:Value"), v)
+		}
+
+		It("Parses the bounds of the default value", func() {
+			v, err := parseConstraints(`<xccdf-1.2:Value id="var_max_sessions" type="number">
+				<xccdf-1.2:value>10</xccdf-1.2:value>
+				<xccdf-1.2:lower-bound>1</xccdf-1.2:lower-bound>
+				<xccdf-1.2:upper-bound>100</xccdf-1.2:upper-bound>
+				<xccdf-1.2:upper-bound selector="strict">20</xccdf-1.2:upper-bound>
+			</xccdf-1.2:Value>`)
+			Expect(err).To(BeNil())
+			Expect(v.Constraints).ToNot(BeNil())
+			Expect(*v.Constraints.LowerBound).To(Equal(1))
+			Expect(*v.Constraints.UpperBound).To(Equal(100))
+		})
+
+		It("Parses the pattern and the enforced choices", func() {
+			v, err := parseConstraints(`<xccdf-1.2:Value id="var_mode" type="string">
+				<xccdf-1.2:value>yes</xccdf-1.2:value>
+				<xccdf-1.2:match>[a-z]+</xccdf-1.2:match>
+				<xccdf-1.2:choices mustMatch="true">
+					<xccdf-1.2:choice>yes</xccdf-1.2:choice>
+					<xccdf-1.2:choice>no</xccdf-1.2:choice>
+				</xccdf-1.2:choices>
+			</xccdf-1.2:Value>`)
+			Expect(err).To(BeNil())
+			Expect(v.Constraints).ToNot(BeNil())
+			Expect(v.Constraints.Match).To(Equal("[a-z]+"))
+			Expect(v.Constraints.Choices).To(ConsistOf("yes", "no"))
+		})
+
+		It("Ignores choices that aren't enforced", func() {
+			v, err := parseConstraints(`<xccdf-1.2:Value id="var_mode" type="string">
+				<xccdf-1.2:value>yes</xccdf-1.2:value>
+				<xccdf-1.2:choices>
+					<xccdf-1.2:choice>yes</xccdf-1.2:choice>
+				</xccdf-1.2:choices>
+			</xccdf-1.2:Value>`)
+			Expect(err).To(BeNil())
+			Expect(v.Constraints).To(BeNil())
+		})
+
+		It("Fails on wrongly formatted bounds", func() {
+			_, err := parseConstraints(`<xccdf-1.2:Value id="var_max_sessions" type="number">
+				<xccdf-1.2:lower-bound>one</xccdf-1.2:lower-bound>
+			</xccdf-1.2:Value>`)
+			Expect(err).ToNot(BeNil())
+		})
 	})
 })
 
@@ -601,7 +842,7 @@ var _ = Describe("Testing parse rules", func() {
 		zaplog, _ := zap.NewDevelopment()
 		log = zapr.NewLogger(zaplog)
 
-		err := ParseRulesAndDo(pInput.contentDom, stdParser, pInput.pb, nonce, ruleAdder)
+		err := ParseRulesAndDo(pInput.contentDom, stdParser, pInput.pb, pInput.pb.Name, nonce, ruleAdder)
 		Expect(err).To(BeNil())
 
 		close(rchan)
@@ -634,6 +875,19 @@ var _ = Describe("Testing parse rules", func() {
 			Expect(pwMinLenRule.Annotations).To(HaveKeyWithValue(rhacmStdsAnnotationKey, "NIST-800-53"))
 			Expect(pwMinLenRule.Annotations).To(HaveKeyWithValue(rhacmCtrlsAnnotationsKey, "IA-5(f),IA-5(1)(a),CM-6(a)"))
 		})
+
+		It("Has the expected structured controls", func() {
+			Expect(pwMinLenRule.Controls).To(ContainElement(cmpv1alpha1.RuleControls{
+				Standard: "NIST-800-53",
+				Controls: []string{"IA-5(f)", "IA-5(1)(a)", "CM-6(a)"},
+			}))
+		})
+
+		It("Is selectable by the controls it implements", func() {
+			Expect(pwMinLenRule.Labels).To(HaveKeyWithValue("nist-800-53.control.compliance.openshift.io/IA-5_1_a", "true"))
+			Expect(pwMinLenRule.Labels).To(HaveKeyWithValue("nist-800-53.control.compliance.openshift.io/IA-5", "true"))
+			Expect(pwMinLenRule.Labels).To(HaveKeyWithValue("nist-800-53.control.compliance.openshift.io/CM-6", "true"))
+		})
 	})
 })
 
@@ -658,3 +912,131 @@ var _ = Describe("Testing CPE string parsing in isolation", func() {
 		})
 	})
 })
+
+var _ = Describe("Testing streaming the content", func() {
+	const content = `<ds:data-stream-collection xmlns:ds="http://scap.nist.gov/schema/scap/source/1.2" xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2" xmlns:oval-def="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+	<xccdf-1.2:Benchmark id="xccdf_org.ssgproject.content_benchmark_OCP-4">
+		<xccdf-1.2:version>0.1.72</xccdf-1.2:version>
+		<xccdf-1.2:Profile id="xccdf_org.ssgproject.content_profile_cis"><xccdf-1.2:title>CIS</xccdf-1.2:title></xccdf-1.2:Profile>
+		<xccdf-1.2:Group id="xccdf_org.ssgproject.content_group_audit">
+			<xccdf-1.2:title>Audit</xccdf-1.2:title>
+			<xccdf-1.2:Rule id="xccdf_org.ssgproject.content_rule_audit_rules">
+				<xccdf-1.2:title>Audit &lt;rules&gt;</xccdf-1.2:title>
+				<xccdf-1.2:reference href="http://nvlpubs.nist.gov/nistpubs/SpecialPublications/NIST.SP.800-53r4.pdf">AU-2</xccdf-1.2:reference>
+				<xccdf-1.2:reference href="https://www.isaca.org/resources/cobit">APO10.01</xccdf-1.2:reference>
+				<xccdf-1.2:fix system="urn:xccdf:fix:script:ignition">ignition</xccdf-1.2:fix>
+				<xccdf-1.2:fix system="urn:xccdf:fix:script:sh"><xccdf-1.2:sub idref="var"/>script</xccdf-1.2:fix>
+			</xccdf-1.2:Rule>
+		</xccdf-1.2:Group>
+	</xccdf-1.2:Benchmark>
+	<oval-def:definitions><oval-def:definition id="audit_rules"/></oval-def:definitions>
+	<oval-def:tests><oval-def:test id="audit_rules_test"/></oval-def:tests>
+</ds:data-stream-collection>`
+
+	type streamed struct {
+		node      *xmlquery.Node
+		benchmark string
+	}
+
+	stream := func(elements ...string) (map[string]streamed, error) {
+		nodes := map[string]streamed{}
+		err := streamContent(strings.NewReader(content), newStandardParser(), func(node *xmlquery.Node, benchmark string) error {
+			nodes[qualifiedNodeName(node)] = streamed{node: node, benchmark: benchmark}
+			return nil
+		}, elements...)
+		return nodes, err
+	}
+
+	It("Reads the elements into a DOM of their own", func() {
+		nodes, err := stream(ruleElement, definitionElement)
+		Expect(err).To(BeNil())
+		Expect(nodes).To(HaveLen(2))
+		rule := nodes[ruleElement]
+		Expect(rule.benchmark).To(Equal("xccdf_org.ssgproject.content_benchmark_OCP-4"))
+		Expect(rule.node.SelectAttr("id")).To(Equal("xccdf_org.ssgproject.content_rule_audit_rules"))
+		Expect(rule.node.SelectElement("xccdf-1.2:title").InnerText()).To(Equal("Audit <rules>"))
+		Expect(nodes[definitionElement].node.SelectAttr("id")).To(Equal("audit_rules"))
+	})
+
+	It("Filters the parts the parser doesn't use out", func() {
+		nodes, err := stream(ruleElement)
+		Expect(err).To(BeNil())
+		rule := nodes[ruleElement].node
+		Expect(xmlquery.Find(rule, "//xccdf-1.2:reference")).To(HaveLen(1))
+		Expect(xmlquery.Find(rule, "//xccdf-1.2:fix")).To(HaveLen(1))
+		Expect(rule.SelectElement("xccdf-1.2:fix").SelectAttr("system")).To(Equal(machineConfigFixType))
+		Expect(rule.SelectElement("//xccdf-1.2:sub")).To(BeNil())
+	})
+
+	It("Reads benchmarks without their profiles, groups and rules", func() {
+		nodes, err := stream(benchmarkElement, profileElement)
+		Expect(err).To(BeNil())
+		benchmark := nodes[benchmarkElement].node
+		Expect(getBenchmark(benchmark)).To(Equal(cmpv1alpha1.ProfileBundleBenchmark{
+			ID:      "xccdf_org.ssgproject.content_benchmark_OCP-4",
+			Version: "0.1.72",
+		}))
+		Expect(benchmark.SelectElement("//xccdf-1.2:Profile")).To(BeNil())
+		Expect(benchmark.SelectElement("//xccdf-1.2:Group")).To(BeNil())
+		Expect(benchmark.SelectElement("//xccdf-1.2:Rule")).To(BeNil())
+		Expect(nodes[profileElement].node.SelectElement("xccdf-1.2:title").InnerText()).To(Equal("CIS"))
+	})
+
+	It("Reports malformed content", func() {
+		err := streamContent(strings.NewReader("<ds:data-stream-collection><xccdf-1.2:Rule></ds:data-stream-collection>"), newStandardParser(),
+			func(*xmlquery.Node, string) error { return nil }, ruleElement)
+		Expect(err).ToNot(BeNil())
+		err = streamContent(strings.NewReader("<ds:data-stream-collection><xccdf-1.2:Rule>"), newStandardParser(),
+			func(*xmlquery.Node, string) error { return nil }, ruleElement)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("Parses the same objects as the DOM of the content", func() {
+		parse := func(content BundleContent) ([]cmpv1alpha1.Profile, []cmpv1alpha1.Rule, []cmpv1alpha1.Variable, BundleContent) {
+			cli := fake.NewClientBuilder().WithScheme(pInput.pcfg.Scheme).Build()
+			pcfg := &ParserConfig{Client: cli, Scheme: pInput.pcfg.Scheme}
+			pb := &cmpv1alpha1.ProfileBundle{ObjectMeta: metav1.ObjectMeta{Name: "streamed", Namespace: testNamespace}}
+			contents := []BundleContent{content}
+			Expect(ParseBundleContents(contents, pb, pcfg)).To(Succeed())
+
+			var profiles cmpv1alpha1.ProfileList
+			Expect(cli.List(context.TODO(), &profiles)).To(Succeed())
+			var rules cmpv1alpha1.RuleList
+			Expect(cli.List(context.TODO(), &rules)).To(Succeed())
+			var variables cmpv1alpha1.VariableList
+			Expect(cli.List(context.TODO(), &variables)).To(Succeed())
+			// The objects are parsed in their own epoch
+			for i := range profiles.Items {
+				delete(profiles.Items[i].Annotations, cmpv1alpha1.ProfileImageDigestAnnotation)
+			}
+			for i := range rules.Items {
+				delete(rules.Items[i].Annotations, cmpv1alpha1.ProfileImageDigestAnnotation)
+				// The profiles of a rule are in no specific order
+				if ruleProfiles, ok := rules.Items[i].Annotations[cmpv1alpha1.RuleProfileAnnotationKey]; ok {
+					sorted := strings.Split(ruleProfiles, ",")
+					sort.Strings(sorted)
+					rules.Items[i].Annotations[cmpv1alpha1.RuleProfileAnnotationKey] = strings.Join(sorted, ",")
+				}
+			}
+			for i := range variables.Items {
+				delete(variables.Items[i].Annotations, cmpv1alpha1.ProfileImageDigestAnnotation)
+			}
+			return profiles.Items, rules.Items, variables.Items, contents[0]
+		}
+
+		domProfiles, domRules, domVariables, domContent := parse(BundleContent{ContentDom: pInput.contentDom, Prefix: "streamed"})
+		profiles, rules, variables, content := parse(BundleContent{
+			Open:   func() (io.ReadCloser, error) { return os.Open(pInput.pcfg.DataStreamPath) },
+			Prefix: "streamed",
+		})
+		Expect(profiles).ToNot(BeEmpty())
+		Expect(rules).ToNot(BeEmpty())
+		Expect(variables).ToNot(BeEmpty())
+		Expect(profiles).To(Equal(domProfiles))
+		Expect(rules).To(Equal(domRules))
+		Expect(variables).To(Equal(domVariables))
+		Expect(content.Benchmarks).To(Equal(domContent.Benchmarks))
+		Expect(content.Profiles).To(Equal(domContent.Profiles))
+		Expect(content.Rules).To(Equal(domContent.Rules))
+	})
+})
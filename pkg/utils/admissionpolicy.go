@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	admissionRegistrationGroup           = "admissionregistration.k8s.io"
+	validatingAdmissionPolicyKind        = "ValidatingAdmissionPolicy"
+	validatingAdmissionPolicyBindingKind = "ValidatingAdmissionPolicyBinding"
+)
+
+// The annotations of the content that the generated bindings of a
+// ValidatingAdmissionPolicy share with the policy
+var admissionPolicyBindingAnnotationKeys = []string{
+	optionalAnnotationKey,
+	remediationTypeAnnotationKey,
+	enforcementTypeAnnotationKey,
+	ocpVersionAnnotationKey,
+	k8sVersionAnnotationKey,
+}
+
+func isAdmissionRegistrationKind(obj *unstructured.Unstructured, kind string) bool {
+	if obj == nil {
+		return false
+	}
+	objgvk := obj.GroupVersionKind()
+	return kind == objgvk.Kind && admissionRegistrationGroup == objgvk.Group
+}
+
+// IsValidatingAdmissionPolicy checks if the specified object is a
+// ValidatingAdmissionPolicy object
+func IsValidatingAdmissionPolicy(obj *unstructured.Unstructured) bool {
+	return isAdmissionRegistrationKind(obj, validatingAdmissionPolicyKind)
+}
+
+// IsValidatingAdmissionPolicyBinding checks if the specified object is a
+// ValidatingAdmissionPolicyBinding object
+func IsValidatingAdmissionPolicyBinding(obj *unstructured.Unstructured) bool {
+	return isAdmissionRegistrationKind(obj, validatingAdmissionPolicyBindingKind)
+}
+
+// addAdmissionPolicyBindings appends a ValidatingAdmissionPolicyBinding for
+// every ValidatingAdmissionPolicy of a fix that none of the bindings of the
+// fix refers to, as policies aren't enforced without a binding. The generated
+// bindings deny the requests that fail the policy.
+func addAdmissionPolicyBindings(objs []*unstructured.Unstructured) []*unstructured.Unstructured {
+	bound := map[string]bool{}
+	for _, obj := range objs {
+		if IsValidatingAdmissionPolicyBinding(obj) {
+			policyName, _, _ := unstructured.NestedString(obj.Object, "spec", "policyName")
+			bound[policyName] = true
+		}
+	}
+
+	for _, obj := range objs {
+		if !IsValidatingAdmissionPolicy(obj) || bound[obj.GetName()] {
+			continue
+		}
+		binding := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"policyName":        obj.GetName(),
+				"validationActions": []interface{}{"Deny"},
+			},
+		}}
+		binding.SetAPIVersion(obj.GetAPIVersion())
+		binding.SetKind(validatingAdmissionPolicyBindingKind)
+		binding.SetName(obj.GetName())
+		annotations := map[string]string{}
+		for _, key := range admissionPolicyBindingAnnotationKeys {
+			if value, ok := obj.GetAnnotations()[key]; ok {
+				annotations[key] = value
+			}
+		}
+		if len(annotations) > 0 {
+			binding.SetAnnotations(annotations)
+		}
+		bound[obj.GetName()] = true
+		objs = append(objs, binding)
+	}
+	return objs
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+
+	compv1alpha1 "github.com/ComplianceAsCode/compliance-operator/pkg/apis/compliance/v1alpha1"
+)
+
+// ApplyWindowOpen tells whether the remediation apply window is open at the
+// given time. If not, it also returns how long it takes until it opens.
+func ApplyWindowOpen(window *compv1alpha1.RemediationApplyWindow, now time.Time) (bool, time.Duration, error) {
+	return windowOpen(window.Schedule, window.Duration.Duration, now)
+}
+
+// ScanWindowOpen tells whether the scan window is open at the given time. If
+// not, it also returns how long it takes until it opens.
+func ScanWindowOpen(window *compv1alpha1.ScanWindow, now time.Time) (bool, time.Duration, error) {
+	return windowOpen(window.Schedule, window.Duration.Duration, now)
+}
+
+func windowOpen(windowSchedule string, duration time.Duration, now time.Time) (bool, time.Duration, error) {
+	schedule, err := cron.ParseStandard(windowSchedule)
+	if err != nil {
+		return false, 0, err
+	}
+	// The window is open if it last opened less than its duration ago
+	if !schedule.Next(now.Add(-duration)).After(now) {
+		return true, 0, nil
+	}
+	return false, schedule.Next(now).Sub(now), nil
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const sha256ChecksumPrefix = "sha256:"
+
+// ParseContentChecksum parses a checksum in the sha256:<hex digest> format,
+// as used by the contentChecksum of ProfileBundles, and returns the digest
+func ParseContentChecksum(checksum string) ([]byte, error) {
+	if !strings.HasPrefix(checksum, sha256ChecksumPrefix) {
+		return nil, fmt.Errorf("the checksum %q isn't in the %s<hex digest> format", checksum, sha256ChecksumPrefix)
+	}
+	digest, err := hex.DecodeString(strings.TrimPrefix(checksum, sha256ChecksumPrefix))
+	if err != nil || len(digest) != 32 {
+		return nil, fmt.Errorf("the checksum %q doesn't contain a valid SHA-256 digest", checksum)
+	}
+	return digest, nil
+}
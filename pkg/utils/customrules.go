@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+)
+
+const (
+	// CELResourceVariable is the variable that CEL assertions of custom rules
+	// get the resource they check as, which is null if it doesn't exist
+	CELResourceVariable = "resource"
+
+	// The cost that evaluating a CEL assertion may take, and how many
+	// iterations of its comprehensions run before checking whether the
+	// evaluation was cancelled. These are the limits Kubernetes uses for the
+	// CEL expressions of its validation rules.
+	celCostLimit              = 1000000
+	celInterruptCheckInterval = 100
+)
+
+// CompileCELAssertion compiles the CEL assertion of a custom rule. The
+// assertion needs to return a boolean, which is only checked when it's
+// evaluated if it depends on the fields of the resource. Evaluating the
+// assertion fails once it exceeds the cost limit, and stops once the context
+// of the evaluation is done.
+func CompileCELAssertion(assertion string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable(CELResourceVariable, cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(assertion)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("the assertion returns %s instead of a boolean", t)
+	}
+	return env.Program(ast,
+		cel.CostLimit(celCostLimit),
+		cel.InterruptCheckFrequency(celInterruptCheckInterval),
+	)
+}
@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// FailureEvidenceKey is the key of the result ConfigMaps that holds the
+// JSON-encoded evidence of the failing checks of the scan, by rule ID
+const FailureEvidenceKey = "failure-evidence"
+
+// DefaultFailureEvidenceMaxSize is the size the evidence of a check is capped
+// to if the scan doesn't set one
+const DefaultFailureEvidenceMaxSize = 1024
+
+const (
+	redactedValue           = "<redacted>"
+	evidenceTruncatedNotice = "... (truncated)"
+)
+
+// redactedFieldNames are the fields of the collected items whose values are
+// always redacted, matched as case-insensitive substrings of the field name
+var redactedFieldNames = []string{"password", "passwd", "secret", "token", "credential"}
+
+// secretAssignmentRegex matches the values assigned to settings that look
+// like they hold credentials in the text the scanner collected, e.g. the
+// matching line of a configuration file
+var secretAssignmentRegex = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key)\w*\s*[=:]\s*)\S+`)
+
+// FailureEvidenceOptions configures how the evidence of failing checks is
+// rendered
+type FailureEvidenceOptions struct {
+	// The maximum size, in bytes, of the evidence of each check
+	MaxSize int
+	// The fields of the collected items whose values are redacted, in
+	// addition to the ones that look like they hold credentials
+	RedactFields []string
+}
+
+// ovalResults are the results of the OVAL checks of one report of an ARF,
+// indexed by their IDs
+type ovalResults struct {
+	definitions map[string]*xmlquery.Node
+	tests       map[string]*xmlquery.Node
+	items       map[string]*xmlquery.Node
+}
+
+// ParseFailureEvidence returns the evidence of each failing rule of an ARF
+// report, by rule ID. The evidence of a rule lists the items the scanner
+// collected for the OVAL tests that failed, or notes that no item was
+// collected, with the values of the fields that might hold credentials
+// redacted and capped to the maximum size of the options.
+func ParseFailureEvidence(arf io.Reader, opts FailureEvidenceOptions) (map[string]string, error) {
+	arfDom, err := xmlquery.Parse(arf)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultFailureEvidenceMaxSize
+	}
+
+	// The elements of the ARF are namespaced with different prefixes, so
+	// they're matched by their local names rather than with XPath
+	reports := make(map[string]*ovalResults)
+	var firstReport *ovalResults
+	var failedRules []*xmlquery.Node
+	for _, node := range xmlquery.Find(arfDom, "//*") {
+		switch node.Data {
+		case "report":
+			for _, results := range xmlquery.Find(node, "descendant::*") {
+				if results.Data != "oval_results" {
+					continue
+				}
+				report := newOvalResults(results)
+				reports[node.SelectAttr("id")] = report
+				if firstReport == nil {
+					firstReport = report
+				}
+				break
+			}
+		case "rule-result":
+			if strings.TrimSpace(getChildText(node, "result")) == "fail" {
+				failedRules = append(failedRules, node)
+			}
+		}
+	}
+
+	evidence := make(map[string]string)
+	for _, rule := range failedRules {
+		var lines []string
+		for _, ref := range getDescendants(rule, "check-content-ref") {
+			report, ok := reports[strings.TrimPrefix(ref.SelectAttr("href"), "#")]
+			if !ok {
+				report = firstReport
+			}
+			if report == nil {
+				continue
+			}
+			lines = append(lines, report.definitionEvidence(ref.SelectAttr("name"), opts, map[string]bool{})...)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		evidence[rule.SelectAttr("idref")] = capEvidence(strings.Join(lines, "\n"), opts.MaxSize)
+	}
+	return evidence, nil
+}
+
+func newOvalResults(results *xmlquery.Node) *ovalResults {
+	report := &ovalResults{
+		definitions: make(map[string]*xmlquery.Node),
+		tests:       make(map[string]*xmlquery.Node),
+		items:       make(map[string]*xmlquery.Node),
+	}
+	for _, node := range xmlquery.Find(results, "descendant::*") {
+		switch {
+		case node.Data == "definition" && node.SelectAttr("definition_id") != "":
+			report.definitions[node.SelectAttr("definition_id")] = node
+		case node.Data == "test" && node.SelectAttr("test_id") != "":
+			report.tests[node.SelectAttr("test_id")] = node
+		case node.Parent != nil && node.Parent.Data == "system_data" && node.SelectAttr("id") != "":
+			report.items[node.SelectAttr("id")] = node
+		}
+	}
+	return report
+}
+
+// definitionEvidence renders the items of the tests that failed in the
+// criteria of a definition, following the definitions it extends
+func (r *ovalResults) definitionEvidence(defID string, opts FailureEvidenceOptions, seen map[string]bool) []string {
+	definition, ok := r.definitions[defID]
+	if !ok || seen[defID] {
+		return nil
+	}
+	seen[defID] = true
+
+	var lines []string
+	for _, node := range xmlquery.Find(definition, "descendant::*") {
+		if node.SelectAttr("result") != "false" {
+			continue
+		}
+		switch node.Data {
+		case "criterion":
+			testID := node.SelectAttr("test_ref")
+			if seen[testID] {
+				continue
+			}
+			seen[testID] = true
+			lines = append(lines, r.testEvidence(testID, opts)...)
+		case "extend_definition":
+			lines = append(lines, r.definitionEvidence(node.SelectAttr("definition_ref"), opts, seen)...)
+		}
+	}
+	return lines
+}
+
+func (r *ovalResults) testEvidence(testID string, opts FailureEvidenceOptions) []string {
+	test, ok := r.tests[testID]
+	if !ok {
+		return nil
+	}
+	testedItems := getDescendants(test, "tested_item")
+	if len(testedItems) == 0 {
+		return []string{fmt.Sprintf("%s: no items were collected", testID)}
+	}
+
+	lines := []string{testID + ":"}
+	for _, testedItem := range testedItems {
+		item, ok := r.items[testedItem.SelectAttr("item_id")]
+		if !ok {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s (%s):", item.Data, testedItem.SelectAttr("result")))
+		lines = append(lines, renderItemFields(item, "    ", opts)...)
+	}
+	return lines
+}
+
+// renderItemFields renders the fields of a collected item, one per line. The
+// fields of records, e.g. the values of an item of a YAML file, are named by
+// their name attribute.
+func renderItemFields(item *xmlquery.Node, indent string, opts FailureEvidenceOptions) []string {
+	var lines []string
+	for child := item.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != xmlquery.ElementNode {
+			continue
+		}
+		name := child.Data
+		if fieldName := child.SelectAttr("name"); fieldName != "" {
+			name = fieldName
+		}
+		if child.SelectElement("*") != nil {
+			lines = append(lines, indent+name+":")
+			lines = append(lines, renderItemFields(child, indent+"  ", opts)...)
+			continue
+		}
+		value := strings.Join(strings.Fields(child.InnerText()), " ")
+		lines = append(lines, fmt.Sprintf("%s%s: %s", indent, name, redactValue(name, value, opts.RedactFields)))
+	}
+	return lines
+}
+
+func redactValue(field, value string, redactFields []string) string {
+	lowerField := strings.ToLower(field)
+	for _, redacted := range redactedFieldNames {
+		if strings.Contains(lowerField, redacted) {
+			return redactedValue
+		}
+	}
+	for _, redacted := range redactFields {
+		if strings.EqualFold(field, redacted) {
+			return redactedValue
+		}
+	}
+	return secretAssignmentRegex.ReplaceAllString(value, "${1}"+redactedValue)
+}
+
+// capEvidence cuts the evidence at the last line that fits in the maximum
+// size, noting that it was truncated
+func capEvidence(evidence string, maxSize int) string {
+	if len(evidence) <= maxSize {
+		return evidence
+	}
+	cut := maxSize - len(evidenceTruncatedNotice) - 1
+	if cut < 0 {
+		cut = 0
+	}
+	truncated := evidence[:cut]
+	if newline := strings.LastIndex(truncated, "\n"); newline > 0 {
+		truncated = truncated[:newline]
+	}
+	for !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
+	return truncated + "\n" + evidenceTruncatedNotice
+}
+
+func getChildText(node *xmlquery.Node, name string) string {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xmlquery.ElementNode && child.Data == name {
+			return child.InnerText()
+		}
+	}
+	return ""
+}
+
+func getDescendants(node *xmlquery.Node, name string) []*xmlquery.Node {
+	var found []*xmlquery.Node
+	for _, descendant := range xmlquery.Find(node, "descendant::*") {
+		if descendant.Data == name {
+			found = append(found, descendant)
+		}
+	}
+	return found
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const evidenceARF = `<?xml version="1.0" encoding="UTF-8"?>
+<arf:asset-report-collection xmlns:arf="http://scap.nist.gov/schema/asset-reporting-format/1.1">
+  <arf:reports>
+    <arf:report id="xccdf1">
+      <arf:content>
+        <TestResult xmlns="http://checklists.nist.gov/xccdf/1.2">
+          <rule-result idref="xccdf_org.ssgproject.content_rule_sshd_disable_root_login">
+            <result>fail</result>
+            <check system="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+              <check-content-ref name="oval:ssg-sshd_disable_root_login:def:1" href="#oval0"/>
+            </check>
+          </rule-result>
+          <rule-result idref="xccdf_org.ssgproject.content_rule_file_exists_banner">
+            <result>fail</result>
+            <check system="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+              <check-content-ref name="oval:ssg-file_exists_banner:def:1" href="#oval0"/>
+            </check>
+          </rule-result>
+          <rule-result idref="xccdf_org.ssgproject.content_rule_grub2_password">
+            <result>fail</result>
+            <check system="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+              <check-content-ref name="oval:ssg-grub2_password:def:1" href="#oval0"/>
+            </check>
+          </rule-result>
+          <rule-result idref="xccdf_org.ssgproject.content_rule_selinux_state">
+            <result>pass</result>
+            <check system="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+              <check-content-ref name="oval:ssg-selinux_state:def:1" href="#oval0"/>
+            </check>
+          </rule-result>
+        </TestResult>
+      </arf:content>
+    </arf:report>
+    <arf:report id="oval0">
+      <arf:content>
+        <oval_results xmlns="http://oval.mitre.org/XMLSchema/oval-results-5">
+          <results>
+            <system>
+              <definitions>
+                <definition definition_id="oval:ssg-sshd_disable_root_login:def:1" result="false">
+                  <criteria operator="AND" result="false">
+                    <extend_definition definition_ref="oval:ssg-sshd_installed:def:1" result="true"/>
+                    <criterion test_ref="oval:ssg-test_sshd_permit_root_login:tst:1" result="false"/>
+                  </criteria>
+                </definition>
+                <definition definition_id="oval:ssg-file_exists_banner:def:1" result="false">
+                  <criteria result="false">
+                    <criterion test_ref="oval:ssg-test_banner_exists:tst:1" result="false"/>
+                  </criteria>
+                </definition>
+                <definition definition_id="oval:ssg-grub2_password:def:1" result="false">
+                  <criteria result="false">
+                    <extend_definition definition_ref="oval:ssg-grub2_password_set:def:1" result="false"/>
+                  </criteria>
+                </definition>
+                <definition definition_id="oval:ssg-grub2_password_set:def:1" result="false">
+                  <criteria result="false">
+                    <criterion test_ref="oval:ssg-test_grub2_password:tst:1" result="false"/>
+                  </criteria>
+                </definition>
+                <definition definition_id="oval:ssg-selinux_state:def:1" result="true">
+                  <criteria result="true">
+                    <criterion test_ref="oval:ssg-test_selinux_state:tst:1" result="true"/>
+                  </criteria>
+                </definition>
+              </definitions>
+              <tests>
+                <test test_id="oval:ssg-test_sshd_permit_root_login:tst:1" result="false">
+                  <tested_item item_id="1001" result="false"/>
+                </test>
+                <test test_id="oval:ssg-test_banner_exists:tst:1" result="false"/>
+                <test test_id="oval:ssg-test_grub2_password:tst:1" result="false">
+                  <tested_item item_id="1002" result="false"/>
+                </test>
+                <test test_id="oval:ssg-test_selinux_state:tst:1" result="true">
+                  <tested_item item_id="1003" result="true"/>
+                </test>
+              </tests>
+              <oval_system_characteristics xmlns="http://oval.mitre.org/XMLSchema/oval-system-characteristics-5"
+                  xmlns:ind-sys="http://oval.mitre.org/XMLSchema/oval-system-characteristics-5#independent">
+                <system_data>
+                  <ind-sys:textfilecontent_item id="1001" status="exists">
+                    <ind-sys:filepath>/etc/ssh/sshd_config</ind-sys:filepath>
+                    <ind-sys:text>PermitRootLogin yes</ind-sys:text>
+                  </ind-sys:textfilecontent_item>
+                  <ind-sys:textfilecontent_item id="1002" status="exists">
+                    <ind-sys:filepath>/boot/grub2/user.cfg</ind-sys:filepath>
+                    <ind-sys:text>GRUB2_PASSWORD=grub.pbkdf2.sha512.10000.0123</ind-sys:text>
+                  </ind-sys:textfilecontent_item>
+                  <ind-sys:textfilecontent_item id="1003" status="exists">
+                    <ind-sys:filepath>/etc/selinux/config</ind-sys:filepath>
+                    <ind-sys:text>SELINUX=enforcing</ind-sys:text>
+                  </ind-sys:textfilecontent_item>
+                </system_data>
+              </oval_system_characteristics>
+            </system>
+          </results>
+        </oval_results>
+      </arf:content>
+    </arf:report>
+  </arf:reports>
+</arf:asset-report-collection>`
+
+var _ = Describe("Failure evidence", func() {
+	var opts FailureEvidenceOptions
+
+	BeforeEach(func() {
+		opts = FailureEvidenceOptions{}
+	})
+
+	It("Renders the items of the tests that failed", func() {
+		evidence, err := ParseFailureEvidence(strings.NewReader(evidenceARF), opts)
+		Expect(err).To(BeNil())
+		Expect(evidence).To(HaveLen(3))
+		Expect(evidence).NotTo(HaveKey("xccdf_org.ssgproject.content_rule_selinux_state"))
+		Expect(evidence["xccdf_org.ssgproject.content_rule_sshd_disable_root_login"]).To(Equal(
+			"oval:ssg-test_sshd_permit_root_login:tst:1:\n" +
+				"  textfilecontent_item (false):\n" +
+				"    filepath: /etc/ssh/sshd_config\n" +
+				"    text: PermitRootLogin yes"))
+	})
+
+	It("Notes the tests that collected no items", func() {
+		evidence, err := ParseFailureEvidence(strings.NewReader(evidenceARF), opts)
+		Expect(err).To(BeNil())
+		Expect(evidence["xccdf_org.ssgproject.content_rule_file_exists_banner"]).To(Equal(
+			"oval:ssg-test_banner_exists:tst:1: no items were collected"))
+	})
+
+	It("Redacts the values that look like credentials", func() {
+		evidence, err := ParseFailureEvidence(strings.NewReader(evidenceARF), opts)
+		Expect(err).To(BeNil())
+		grubEvidence := evidence["xccdf_org.ssgproject.content_rule_grub2_password"]
+		Expect(grubEvidence).To(ContainSubstring("text: GRUB2_PASSWORD=<redacted>"))
+		Expect(grubEvidence).NotTo(ContainSubstring("pbkdf2"))
+	})
+
+	It("Redacts the fields it's asked to", func() {
+		opts.RedactFields = []string{"filepath"}
+		evidence, err := ParseFailureEvidence(strings.NewReader(evidenceARF), opts)
+		Expect(err).To(BeNil())
+		Expect(evidence["xccdf_org.ssgproject.content_rule_sshd_disable_root_login"]).To(ContainSubstring("filepath: <redacted>"))
+	})
+
+	It("Caps the evidence of each check", func() {
+		opts.MaxSize = 96
+		evidence, err := ParseFailureEvidence(strings.NewReader(evidenceARF), opts)
+		Expect(err).To(BeNil())
+		sshdEvidence := evidence["xccdf_org.ssgproject.content_rule_sshd_disable_root_login"]
+		Expect(len(sshdEvidence)).To(BeNumerically("<=", 96))
+		Expect(sshdEvidence).To(Equal(
+			"oval:ssg-test_sshd_permit_root_login:tst:1:\n" +
+				"  textfilecontent_item (false):\n" +
+				"... (truncated)"))
+	})
+})
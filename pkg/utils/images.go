@@ -1,6 +1,9 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 type ComplianceComponent uint
 
@@ -30,3 +33,28 @@ func GetComponentImage(component ComplianceComponent) string {
 	}
 	return imageTag
 }
+
+// AllowedScannerImagesEnv is the environment variable of the operator that
+// lists the images, separated by commas, that a scan can run its scanner
+// with instead of the scanner image of the operator. An entry without a tag
+// or digest allows any tag or digest of the repository.
+const AllowedScannerImagesEnv = "ALLOWED_SCANNER_IMAGES"
+
+// ScannerImageAllowed tells whether a scan is allowed to run its scanner with
+// the given image. The scanner runs privileged on the nodes, so only the
+// scanner image of the operator and the images the operator allows are.
+func ScannerImageAllowed(image string) bool {
+	if image == GetComponentImage(OPENSCAP) {
+		return true
+	}
+	for _, allowed := range strings.Split(os.Getenv(AllowedScannerImagesEnv), ",") {
+		allowed = strings.TrimSpace(allowed)
+		if allowed == "" {
+			continue
+		}
+		if image == allowed || strings.HasPrefix(image, allowed+":") || strings.HasPrefix(image, allowed+"@") {
+			return true
+		}
+	}
+	return false
+}
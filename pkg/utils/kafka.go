@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The keys of the Secret that configures the Kafka topic the events are
+// published to
+const (
+	KafkaBrokersKey       = "brokers"
+	KafkaTopicKey         = "topic"
+	KafkaTLSKey           = "tls"
+	KafkaCAKey            = "ca.crt"
+	KafkaSASLMechanismKey = "saslMechanism"
+	KafkaUsernameKey      = "username"
+	KafkaPasswordKey      = "password"
+)
+
+const (
+	kafkaWriteTimeout = 30 * time.Second
+	kafkaMaxAttempts  = 5
+)
+
+// KafkaConfig configures the Kafka topic the events are published to
+type KafkaConfig struct {
+	// The host and port of the bootstrap brokers
+	Brokers []string
+	Topic   string
+	// The TLS configuration the brokers are connected to with, the brokers
+	// are connected to in plain text if it's nil
+	TLSConfig *tls.Config
+	// The SASL mechanism the client authenticates with, if any
+	SASLMechanism sasl.Mechanism
+}
+
+// NewKafkaConfigFromSecret returns the configuration of the Kafka topic in the
+// Secret. The Secret has the comma-separated brokers in its 'brokers' key and
+// the topic in its 'topic' key. The brokers are connected to over TLS if the
+// Secret has a 'ca.crt' key to verify them with, or if its 'tls' key is
+// 'true'. The client authenticates with the 'username' and 'password' keys if
+// the 'saslMechanism' key is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512.
+func NewKafkaConfigFromSecret(secret *corev1.Secret) (*KafkaConfig, error) {
+	config := &KafkaConfig{Topic: string(secret.Data[KafkaTopicKey])}
+	for _, broker := range strings.Split(string(secret.Data[KafkaBrokersKey]), ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			config.Brokers = append(config.Brokers, broker)
+		}
+	}
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("the Secret %s has no %s key", secret.Name, KafkaBrokersKey)
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("the Secret %s has no %s key", secret.Name, KafkaTopicKey)
+	}
+
+	ca, hasCA := secret.Data[KafkaCAKey]
+	if hasCA || string(secret.Data[KafkaTLSKey]) == "true" {
+		config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if hasCA {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("the %s key of the Secret %s has no PEM certificate", KafkaCAKey, secret.Name)
+		}
+		config.TLSConfig.RootCAs = pool
+	}
+
+	username := string(secret.Data[KafkaUsernameKey])
+	password := string(secret.Data[KafkaPasswordKey])
+	var err error
+	switch mechanism := string(secret.Data[KafkaSASLMechanismKey]); mechanism {
+	case "":
+	case plain.Mechanism{}.Name():
+		config.SASLMechanism = plain.Mechanism{Username: username, Password: password}
+	case scram.SHA256.Name():
+		config.SASLMechanism, err = scram.Mechanism(scram.SHA256, username, password)
+	case scram.SHA512.Name():
+		config.SASLMechanism, err = scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %s in the Secret %s", mechanism, secret.Name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot use the credentials of the Secret %s: %w", secret.Name, err)
+	}
+	return config, nil
+}
+
+// KafkaMessage is a message published to the topic, with a JSON value
+type KafkaMessage struct {
+	// The messages with the same key end up in the same partition, in order
+	Key   string
+	Time  time.Time
+	Value interface{}
+}
+
+// KafkaPublisher publishes messages to a Kafka topic
+type KafkaPublisher interface {
+	// Publish publishes the messages and waits for all the in-sync
+	// replicas to acknowledge them
+	Publish(ctx context.Context, messages []KafkaMessage) error
+}
+
+type kafkaWriterPublisher struct {
+	config *KafkaConfig
+}
+
+// NewKafkaPublisher returns a publisher to the topic of the configuration
+func NewKafkaPublisher(config *KafkaConfig) KafkaPublisher {
+	return &kafkaWriterPublisher{config: config}
+}
+
+func (p *kafkaWriterPublisher) Publish(ctx context.Context, messages []KafkaMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		value, err := json.Marshal(message.Value)
+		if err != nil {
+			return err
+		}
+		kafkaMessages[i] = kafka.Message{Key: []byte(message.Key), Value: value, Time: message.Time}
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(p.config.Brokers...),
+		Topic:        p.config.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		MaxAttempts:  kafkaMaxAttempts,
+		WriteTimeout: kafkaWriteTimeout,
+		Transport: &kafka.Transport{
+			TLS:  p.config.TLSConfig,
+			SASL: p.config.SASLMechanism,
+		},
+	}
+	defer writer.Close()
+	if err := writer.WriteMessages(ctx, kafkaMessages...); err != nil {
+		return fmt.Errorf("cannot publish the messages to the topic %s: %w", p.config.Topic, err)
+	}
+	return nil
+}
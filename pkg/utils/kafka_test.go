@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"encoding/pem"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Kafka", func() {
+	newSecret := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "kafka"}, Data: map[string][]byte{}}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		return secret
+	}
+
+	It("reads the brokers and the topic from the Secret", func() {
+		config, err := NewKafkaConfigFromSecret(newSecret(map[string]string{
+			KafkaBrokersKey: "kafka-0:9092, kafka-1:9092,",
+			KafkaTopicKey:   "compliance-events",
+		}))
+		Expect(err).To(BeNil())
+		Expect(config.Brokers).To(Equal([]string{"kafka-0:9092", "kafka-1:9092"}))
+		Expect(config.Topic).To(Equal("compliance-events"))
+		Expect(config.TLSConfig).To(BeNil())
+		Expect(config.SASLMechanism).To(BeNil())
+
+		_, err = NewKafkaConfigFromSecret(newSecret(map[string]string{KafkaBrokersKey: "kafka-0:9092"}))
+		Expect(err).To(MatchError("the Secret kafka has no topic key"))
+	})
+
+	It("connects over TLS and authenticates with SASL", func() {
+		server := httptest.NewTLSServer(nil)
+		defer server.Close()
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		config, err := NewKafkaConfigFromSecret(newSecret(map[string]string{
+			KafkaBrokersKey:       "kafka-0:9093",
+			KafkaTopicKey:         "compliance-events",
+			KafkaCAKey:            string(ca),
+			KafkaSASLMechanismKey: "PLAIN",
+			KafkaUsernameKey:      "operator",
+			KafkaPasswordKey:      "secret",
+		}))
+		Expect(err).To(BeNil())
+		Expect(config.TLSConfig.RootCAs).ToNot(BeNil())
+		Expect(config.SASLMechanism).To(Equal(plain.Mechanism{Username: "operator", Password: "secret"}))
+
+		config, err = NewKafkaConfigFromSecret(newSecret(map[string]string{
+			KafkaBrokersKey:       "kafka-0:9093",
+			KafkaTopicKey:         "compliance-events",
+			KafkaTLSKey:           "true",
+			KafkaSASLMechanismKey: "SCRAM-SHA-512",
+			KafkaUsernameKey:      "operator",
+			KafkaPasswordKey:      "secret",
+		}))
+		Expect(err).To(BeNil())
+		Expect(config.TLSConfig.RootCAs).To(BeNil())
+		Expect(config.SASLMechanism.Name()).To(Equal("SCRAM-SHA-512"))
+
+		_, err = NewKafkaConfigFromSecret(newSecret(map[string]string{
+			KafkaBrokersKey:       "kafka-0:9093",
+			KafkaTopicKey:         "compliance-events",
+			KafkaSASLMechanismKey: "GSSAPI",
+		}))
+		Expect(err).To(MatchError("unknown SASL mechanism GSSAPI in the Secret kafka"))
+	})
+})
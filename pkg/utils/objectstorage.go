@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	objectStorageService       = "s3"
+	objectStorageSigningScheme = "AWS4-HMAC-SHA256"
+	objectStorageTimeFormat    = "20060102T150405Z"
+	objectStorageDateFormat    = "20060102"
+)
+
+// ObjectStorageConfig configures the S3-compatible object storage the raw
+// results are uploaded to
+type ObjectStorageConfig struct {
+	// The URL of the endpoint, the bucket is addressed by path
+	Endpoint string
+	Region   string
+	Bucket   string
+	// The credentials the requests are signed with
+	AccessKeyID     string
+	SecretAccessKey string
+	// The server-side encryption of the objects, if any, and the key
+	// management service key they're encrypted with
+	ServerSideEncryption string
+	KMSKeyID             string
+}
+
+// ObjectStorageClient uploads objects to an S3-compatible object storage
+// with requests signed with AWS Signature Version 4
+type ObjectStorageClient struct {
+	config ObjectStorageConfig
+	client *http.Client
+	// now returns the time the requests are signed at
+	now func() time.Time
+}
+
+// NewObjectStorageClient returns a client for the object storage, which
+// sends its requests through the given HTTP client
+func NewObjectStorageClient(config ObjectStorageConfig, client *http.Client) (*ObjectStorageClient, error) {
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid object storage endpoint %s: %w", config.Endpoint, err)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("no object storage bucket given")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("no object storage credentials given")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ObjectStorageClient{config: config, client: client, now: time.Now}, nil
+}
+
+// UploadFile uploads the file at the given path as the object with the
+// given key
+func (c *ObjectStorageClient) UploadFile(ctx context.Context, key, path, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	// #nosec
+	defer f.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	objectURL := strings.TrimSuffix(c.config.Endpoint, "/") + "/" + escapeObjectPath(c.config.Bucket+"/"+key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.config.ServerSideEncryption != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", c.config.ServerSideEncryption)
+		if c.config.KMSKeyID != "" {
+			req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", c.config.KMSKeyID)
+		}
+	}
+	signObjectStorageRequest(req, hex.EncodeToString(hash.Sum(nil)), c.config.AccessKeyID,
+		c.config.SecretAccessKey, c.config.Region, c.now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot upload %s: %w", key, err)
+	}
+	// #nosec
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("cannot upload %s: the object storage answered %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// escapeObjectPath URI-encodes the segments of the path of an object
+func escapeObjectPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+// signObjectStorageRequest signs the request, along with all of its headers,
+// with AWS Signature Version 4, as described in
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signObjectStorageRequest(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(objectStorageTimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := now.Format(objectStorageDateFormat)
+	scope := strings.Join([]string{date, region, objectStorageService, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		objectStorageSigningScheme,
+		now.Format(objectStorageTimeFormat),
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + secretAccessKey)
+	for _, part := range []string{date, region, objectStorageService, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		objectStorageSigningScheme, accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
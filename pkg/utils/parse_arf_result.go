@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"text/template/parse"
@@ -508,6 +509,7 @@ func ParseResultsFromContentAndXccdf(scheme *runtime.Scheme, scanName string, na
 	defTable := NewDefHashTable(dsDom)
 	ovalTestVarTable := newValueListTable(dsDom, statesTable, objsTable)
 	results := resultsDom.SelectElements("//rule-result")
+	ruleResults := newRuleResultTable(results)
 	parsedResults := make([]*ParseResult, 0)
 	var remErrs string
 
@@ -531,6 +533,9 @@ func ParseResultsFromContentAndXccdf(scheme *runtime.Scheme, scanName string, na
 		}
 
 		if resCheck != nil {
+			if resCheck.Status == compv1alpha1.CheckResultNotApplicable {
+				resCheck.NotApplicableReason = getNotApplicableReason(resultRule, ruleResults)
+			}
 			pr := &ParseResult{
 				Id:          ruleIDRef,
 				CheckResult: resCheck,
@@ -696,6 +701,76 @@ func RuleHasHideTagWarning(rule *xmlquery.Node) bool {
 	return false
 }
 
+// newRuleResultTable returns the raw results of the rules, by rule ID
+func newRuleResultTable(results []*xmlquery.Node) map[string]string {
+	table := make(map[string]string, len(results))
+	for _, result := range results {
+		resultEl := result.SelectElement("result")
+		if resultEl == nil {
+			continue
+		}
+		table[result.SelectAttr("idref")] = resultEl.InnerText()
+	}
+	return table
+}
+
+// getNotApplicableReason tells why the rule of a NOT-APPLICABLE check
+// didn't apply. The scanner doesn't report it, so it's inferred from the
+// content: rules that require rules that weren't selected or didn't apply
+// in the scan didn't meet their prerequisites, and rules that are
+// restricted to platforms, directly or through one of their groups, didn't
+// match them. Otherwise, the check of the rule itself didn't apply.
+func getNotApplicableReason(rule *xmlquery.Node, ruleResults map[string]string) *compv1alpha1.NotApplicableReason {
+	var unmet []string
+	for _, requires := range rule.SelectElements("xccdf-1.2:requires") {
+		// A requirement can list alternatives, any of which meets it
+		alternatives := strings.Fields(requires.SelectAttr("idref"))
+		met := false
+		for _, required := range alternatives {
+			switch ruleResults[required] {
+			case "", "notselected", "notapplicable":
+			default:
+				met = true
+			}
+		}
+		if !met {
+			unmet = append(unmet, alternatives...)
+		}
+	}
+	if len(unmet) > 0 {
+		return &compv1alpha1.NotApplicableReason{
+			Type: compv1alpha1.NotApplicablePrerequisiteNotMet,
+			Refs: RemoveDuplicate(unmet),
+		}
+	}
+
+	var platforms, cpes []string
+	for item := rule; item != nil && (item.Data == "Rule" || item.Data == "Group"); item = item.Parent {
+		for _, platform := range item.SelectElements("xccdf-1.2:platform") {
+			idref := platform.SelectAttr("idref")
+			if strings.HasPrefix(idref, "#") {
+				platforms = append(platforms, strings.TrimPrefix(idref, "#"))
+			} else if idref != "" {
+				cpes = append(cpes, idref)
+			}
+		}
+	}
+	if len(platforms) > 0 {
+		return &compv1alpha1.NotApplicableReason{
+			Type: compv1alpha1.NotApplicablePlatformMismatch,
+			Refs: RemoveDuplicate(platforms),
+		}
+	}
+	if len(cpes) > 0 {
+		return &compv1alpha1.NotApplicableReason{
+			Type: compv1alpha1.NotApplicableCPENotMatched,
+			Refs: RemoveDuplicate(cpes),
+		}
+	}
+
+	return &compv1alpha1.NotApplicableReason{Type: compv1alpha1.NotApplicableCheckNotApplicable}
+}
+
 func mapComplianceCheckResultSeverity(result *xmlquery.Node) (compv1alpha1.ComplianceCheckResultSeverity, error) {
 	severityAttr := result.SelectAttr("severity")
 	if severityAttr == "" {
@@ -804,6 +879,7 @@ func remediationsFromString(scheme *runtime.Scheme, name string, namespace strin
 	if err != nil {
 		return nil, err
 	}
+	objs = addAdmissionPolicyBindings(objs)
 	rems := make([]*compv1alpha1.ComplianceRemediation, 0, len(objs))
 	for idx := range objs {
 		obj := objs[idx]
@@ -850,6 +926,17 @@ func remediationsFromString(scheme *runtime.Scheme, name string, namespace strin
 			annotations = handleEnforcementTypeAnnotation(obj, annotations)
 		}
 
+		// ValidatingAdmissionPolicies keep non-compliant objects from being
+		// admitted rather than changing the configuration
+		if IsValidatingAdmissionPolicy(obj) || IsValidatingAdmissionPolicyBinding(obj) {
+			remType = compv1alpha1.EnforcementRemediation
+			if _, ok := annotations[compv1alpha1.RemediationEnforcementTypeAnnotation]; !ok {
+				annotations[compv1alpha1.RemediationEnforcementTypeAnnotation] = compv1alpha1.RemediationEnforcementAdmission
+			}
+		}
+
+		annotations[compv1alpha1.RemediationRebootRequiredAnnotation] = strconv.FormatBool(RemediationRequiresReboot(obj))
+
 		var remName string
 		if idx == 0 {
 			// Use result's name
@@ -12,6 +12,7 @@ import (
 	igntypes "github.com/coreos/ignition/v2/config/v3_4/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 
@@ -432,6 +433,30 @@ Server 3.fedora.pool.ntp.org`
 			It("Should have the expected instructions", func() {
 				Expect(check.Instructions).To(HavePrefix(expInstructions))
 			})
+
+			It("Should have no not-applicable reason", func() {
+				Expect(check.NotApplicableReason).To(BeNil())
+			})
+		})
+
+		Context("Not applicable check", func() {
+			const expID = "xccdf_org.ssgproject.content_rule_selinux_confinement_of_daemons"
+
+			It("Should have the CPE it didn't match as reason", func() {
+				var check *compv1alpha1.ComplianceCheckResult
+				for i := range resultList {
+					if resultList[i].CheckResult != nil && resultList[i].CheckResult.ID == expID {
+						check = resultList[i].CheckResult
+						break
+					}
+				}
+				Expect(check).ToNot(BeNil())
+				Expect(check.Status).To(Equal(compv1alpha1.CheckResultNotApplicable))
+				Expect(check.NotApplicableReason).To(Equal(&compv1alpha1.NotApplicableReason{
+					Type: compv1alpha1.NotApplicableCPENotMatched,
+					Refs: []string{"cpe:/a:machine"},
+				}))
+			})
 		})
 
 		Context("First remediation type", func() {
@@ -480,6 +505,74 @@ Server 3.fedora.pool.ntp.org`
 		})
 	})
 
+	Describe("Testing for not-applicable reasons", func() {
+		const content = `<xccdf-1.2:Benchmark xmlns:xccdf-1.2="http://checklists.nist.gov/xccdf/1.2">
+  <xccdf-1.2:platform idref="cpe:/a:redhat:openshift_container_platform:4.1"/>
+  <xccdf-1.2:Group id="group_nodes">
+    <xccdf-1.2:platform idref="#machine"/>
+    <xccdf-1.2:Rule id="rule_grouped"/>
+  </xccdf-1.2:Group>
+  <xccdf-1.2:Rule id="rule_cpe">
+    <xccdf-1.2:platform idref="cpe:/a:chrony"/>
+  </xccdf-1.2:Rule>
+  <xccdf-1.2:Rule id="rule_requires">
+    <xccdf-1.2:platform idref="cpe:/a:chrony"/>
+    <xccdf-1.2:requires idref="rule_installed rule_alternative"/>
+    <xccdf-1.2:requires idref="rule_enabled"/>
+  </xccdf-1.2:Rule>
+  <xccdf-1.2:Rule id="rule_plain"/>
+</xccdf-1.2:Benchmark>`
+
+		var rules NodeByIdHashTable
+
+		BeforeEach(func() {
+			dom, err := xmlquery.Parse(strings.NewReader(content))
+			Expect(err).NotTo(HaveOccurred())
+			rules = newByIdHashTable(dom.SelectElements("//xccdf-1.2:Rule"))
+		})
+
+		It("reports the platforms of the groups of the rule", func() {
+			reason := getNotApplicableReason(rules["rule_grouped"], nil)
+			Expect(reason).To(Equal(&compv1alpha1.NotApplicableReason{
+				Type: compv1alpha1.NotApplicablePlatformMismatch,
+				Refs: []string{"machine"},
+			}))
+		})
+
+		It("reports the CPE names of the rule", func() {
+			reason := getNotApplicableReason(rules["rule_cpe"], nil)
+			Expect(reason).To(Equal(&compv1alpha1.NotApplicableReason{
+				Type: compv1alpha1.NotApplicableCPENotMatched,
+				Refs: []string{"cpe:/a:chrony"},
+			}))
+		})
+
+		It("reports the required rules that weren't met", func() {
+			ruleResults := map[string]string{
+				"rule_installed":   "notselected",
+				"rule_alternative": "notapplicable",
+				"rule_enabled":     "pass",
+			}
+			reason := getNotApplicableReason(rules["rule_requires"], ruleResults)
+			Expect(reason).To(Equal(&compv1alpha1.NotApplicableReason{
+				Type: compv1alpha1.NotApplicablePrerequisiteNotMet,
+				Refs: []string{"rule_installed", "rule_alternative"},
+			}))
+
+			By("meeting the requirement through an alternative")
+			ruleResults["rule_alternative"] = "fail"
+			reason = getNotApplicableReason(rules["rule_requires"], ruleResults)
+			Expect(reason.Type).To(Equal(compv1alpha1.NotApplicableCPENotMatched))
+		})
+
+		It("falls back to the check of the rule", func() {
+			reason := getNotApplicableReason(rules["rule_plain"], nil)
+			Expect(reason).To(Equal(&compv1alpha1.NotApplicableReason{
+				Type: compv1alpha1.NotApplicableCheckNotApplicable,
+			}))
+		})
+	})
+
 	Describe("Testing for parseValues", func() {
 		var value_dic = map[string]string{
 			"the_value_1": "3600,1200,3122",
@@ -884,6 +977,61 @@ Server 3.fedora.pool.ntp.org`
 		})
 	})
 
+	Describe("Testing for ValidatingAdmissionPolicy remediations", func() {
+		const policy = `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: no-privileged-pods
+  annotations:
+    complianceascode.io/optional: ""
+spec:
+  matchConstraints:
+    resourceRules:
+    - apiGroups: [""]
+      apiVersions: ["v1"]
+      operations: ["CREATE", "UPDATE"]
+      resources: ["pods"]
+  validations:
+  - expression: "object.spec.containers.all(c, !has(c.securityContext) || !has(c.securityContext.privileged) || !c.securityContext.privileged)"
+`
+
+		It("Should generate a binding for policies without one", func() {
+			rems, err := remediationsFromString(scheme.Scheme, "test-rem", "test-ns", policy, nil)
+			Expect(err).To(BeNil())
+			Expect(rems).To(HaveLen(2))
+			for _, rem := range rems {
+				Expect(rem.Spec.Type).To(Equal(compv1alpha1.EnforcementRemediation))
+				Expect(rem.GetEnforcementType()).To(Equal(compv1alpha1.RemediationEnforcementAdmission))
+				Expect(rem.Annotations).To(HaveKey(compv1alpha1.RemediationOptionalAnnotation))
+			}
+
+			binding := rems[1].Spec.Current.Object
+			Expect(rems[1].Name).To(Equal("test-rem-1"))
+			Expect(IsValidatingAdmissionPolicyBinding(binding)).To(BeTrue())
+			Expect(binding.GetName()).To(Equal("no-privileged-pods"))
+			policyName, _, _ := unstructured.NestedString(binding.Object, "spec", "policyName")
+			Expect(policyName).To(Equal("no-privileged-pods"))
+			actions, _, _ := unstructured.NestedStringSlice(binding.Object, "spec", "validationActions")
+			Expect(actions).To(ConsistOf("Deny"))
+		})
+
+		It("Should keep the bindings of the content", func() {
+			fix := policy + `---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: no-privileged-pods-warn
+spec:
+  policyName: no-privileged-pods
+  validationActions: ["Warn"]
+`
+			rems, err := remediationsFromString(scheme.Scheme, "test-rem", "test-ns", fix, nil)
+			Expect(err).To(BeNil())
+			Expect(rems).To(HaveLen(2))
+			Expect(rems[1].Spec.Current.Object.GetName()).To(Equal("no-privileged-pods-warn"))
+		})
+	})
+
 	Describe("Testing for correct content parsing", func() {
 		defer GinkgoRecover()
 		Describe("Searching and testing all XCCDF files", func() {
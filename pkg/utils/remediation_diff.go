@@ -188,6 +188,8 @@ func reconcileInconsistentResult(inconsistent []*ParseResultContextItem) *ParseR
 			Remediations: deepCopyRemediations(inconsistent[0].Remediations),
 		},
 	}
+	// The check ends up neither consistent nor NOT-APPLICABLE
+	pr.CheckResult.NotApplicableReason = nil
 
 	isDifferent, diffMsg := differsExceptStatus(inconsistent)
 	if isDifferent {
@@ -304,7 +306,9 @@ func diffChecks(old, new *compv1alpha1.ComplianceCheckResult) bool {
 
 	// should we be more picky and just compare what can be set with the remediations? e.g. OSImageURL can't
 	// be set with a remediation..
-	return cmp.Equal(old, new)
+	// The evidence of a failing check differs between nodes, e.g. by the
+	// names of the files that were collected, without the result differing
+	return cmp.Equal(old, new, cmpopts.IgnoreFields(compv1alpha1.ComplianceCheckResult{}, "Evidence"))
 }
 
 // returns true if the remediations are the same, false if they differ
@@ -165,6 +165,15 @@ var _ = Describe("Testing reconciling differing parse results", func() {
 			Expect(prCtx.inconsistent).To(HaveLen(0))
 			Expect(prCtx.consistent["checkid_1"].sources).To(ConsistOf("source1", "source2", "source3"))
 		})
+
+		It("Doesn't tell results apart by their evidence", func() {
+			list1[1].CheckResult.Evidence = "Collected on node source1"
+			list2[1].CheckResult.Evidence = "Collected on node source2"
+			prCtx.AddResults("source1", list1)
+			prCtx.AddResults("source2", list2)
+			Expect(prCtx.consistent).To(HaveLen(3))
+			Expect(prCtx.inconsistent).To(HaveLen(0))
+		})
 	})
 
 	Context("Handling inconsistent results", func() {
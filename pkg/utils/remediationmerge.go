@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MergeRemediationFields merges the fields of the object of a remediation,
+// src, into dst. Lists are concatenated, leaving out the entries that are
+// already there, where entries with the same path or name, like files or
+// systemd units, are considered the same.
+func MergeRemediationFields(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		dstValue, ok := dst[key]
+		if !ok {
+			dst[key] = runtime.DeepCopyJSONValue(srcValue)
+			continue
+		}
+		switch typedDst := dstValue.(type) {
+		case map[string]interface{}:
+			if typedSrc, ok := srcValue.(map[string]interface{}); ok {
+				MergeRemediationFields(typedDst, typedSrc)
+			}
+		case []interface{}:
+			if typedSrc, ok := srcValue.([]interface{}); ok {
+				dst[key] = mergeRemediationLists(typedDst, typedSrc)
+			}
+		}
+	}
+}
+
+func mergeRemediationLists(dst, src []interface{}) []interface{} {
+	for _, srcItem := range src {
+		found := false
+		for _, dstItem := range dst {
+			if sameRemediationListItem(dstItem, srcItem) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, runtime.DeepCopyJSONValue(srcItem))
+		}
+	}
+	return dst
+}
+
+func sameRemediationListItem(a, b interface{}) bool {
+	aMap, aIsMap := a.(map[string]interface{})
+	bMap, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		for _, key := range []string{"path", "name"} {
+			if aKey, ok := aMap[key]; ok {
+				return reflect.DeepEqual(aKey, bMap[key])
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -10,6 +10,16 @@ import (
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ManagedKubeletConfigPrefix prefixes the name of the KubeletConfig that the
+// operator manages for a pool that has no custom KubeletConfig
+const ManagedKubeletConfigPrefix = "compliance-operator-kubelet-"
+
+// GetManagedKubeletConfigName returns the name of the KubeletConfig that the
+// operator manages for the pool
+func GetManagedKubeletConfigName(poolName string) string {
+	return ManagedKubeletConfigPrefix + poolName
+}
+
 func IsKind(obj *unstructured.Unstructured, kind string) bool {
 	if obj == nil {
 		return false
@@ -29,6 +39,64 @@ func IsKubeletConfig(obj *unstructured.Unstructured) bool {
 	return IsKind(obj, "KubeletConfig")
 }
 
+// The files the Machine Config Operator updates without rebooting the nodes
+var machineConfigNoRebootFiles = map[string]bool{
+	"/etc/kubernetes/kubelet-ca.crt":  true,
+	"/var/lib/kubelet/config.json":    true,
+	"/etc/containers/registries.conf": true,
+}
+
+// The attributes of a MachineConfig that the Machine Config Operator always
+// rolls out by rebooting the nodes
+var machineConfigRebootFields = []string{"kernelArguments", "kernelType", "extensions", "fips", "osImageURL"}
+
+// RemediationRequiresReboot tells whether applying the object of a
+// remediation reboots the nodes of its pool. MachineConfigs reboot the nodes
+// unless they only change SSH keys or files the Machine Config Operator
+// applies live. KubeletConfigs and ContainerRuntimeConfigs are rendered into
+// MachineConfigs that change files which require a reboot.
+func RemediationRequiresReboot(obj *unstructured.Unstructured) bool {
+	if IsKubeletConfig(obj) || IsKind(obj, "ContainerRuntimeConfig") {
+		return true
+	}
+	if !IsMachineConfig(obj) {
+		return false
+	}
+	for _, field := range machineConfigRebootFields {
+		if value, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "spec", field); found && !isEmptyValue(value) {
+			return true
+		}
+	}
+	for _, fields := range [][]string{{"systemd", "units"}, {"storage", "directories"}, {"storage", "links"}} {
+		entries, _, _ := unstructured.NestedSlice(obj.Object, append([]string{"spec", "config"}, fields...)...)
+		if len(entries) > 0 {
+			return true
+		}
+	}
+	files, _, _ := unstructured.NestedSlice(obj.Object, "spec", "config", "storage", "files")
+	for _, file := range files {
+		path, _ := file.(map[string]interface{})["path"].(string)
+		if !machineConfigNoRebootFiles[path] {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
 func HaveOutdatedRemediations(client runtimeclient.Client) (error, bool) {
 	remList := &compv1alpha1.ComplianceRemediationList{}
 	listOpts := runtimeclient.ListOptions{
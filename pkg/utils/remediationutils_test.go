@@ -0,0 +1,64 @@
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Remediation reboot impact", func() {
+	newObject := func(apiVersion, kind string, spec map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"spec":       spec,
+		}}
+	}
+	newMC := func(spec map[string]interface{}) *unstructured.Unstructured {
+		return newObject("machineconfiguration.openshift.io/v1", "MachineConfig", spec)
+	}
+	files := func(paths ...string) map[string]interface{} {
+		fileList := []interface{}{}
+		for _, path := range paths {
+			fileList = append(fileList, map[string]interface{}{"path": path})
+		}
+		return map[string]interface{}{"storage": map[string]interface{}{"files": fileList}}
+	}
+
+	It("requires a reboot for MachineConfigs that change files", func() {
+		mc := newMC(map[string]interface{}{"config": files("/etc/sysctl.d/75-compliance.conf")})
+		Expect(RemediationRequiresReboot(mc)).To(BeTrue())
+	})
+
+	It("requires a reboot for MachineConfigs that set units", func() {
+		mc := newMC(map[string]interface{}{"config": map[string]interface{}{
+			"systemd": map[string]interface{}{"units": []interface{}{map[string]interface{}{"name": "auditd.service"}}},
+		}})
+		Expect(RemediationRequiresReboot(mc)).To(BeTrue())
+	})
+
+	It("requires a reboot for MachineConfigs that set kernel arguments or FIPS", func() {
+		Expect(RemediationRequiresReboot(newMC(map[string]interface{}{"kernelArguments": []interface{}{"audit=1"}}))).To(BeTrue())
+		Expect(RemediationRequiresReboot(newMC(map[string]interface{}{"fips": true}))).To(BeTrue())
+	})
+
+	It("doesn't require a reboot for files the Machine Config Operator applies live", func() {
+		mc := newMC(map[string]interface{}{
+			"config":          files("/etc/containers/registries.conf", "/var/lib/kubelet/config.json"),
+			"kernelArguments": []interface{}{},
+			"fips":            false,
+		})
+		Expect(RemediationRequiresReboot(mc)).To(BeFalse())
+	})
+
+	It("requires a reboot for KubeletConfigs", func() {
+		kc := newObject("machineconfiguration.openshift.io/v1", "KubeletConfig", map[string]interface{}{})
+		Expect(RemediationRequiresReboot(kc)).To(BeTrue())
+	})
+
+	It("doesn't require a reboot for other objects", func() {
+		cm := newObject("v1", "ConfigMap", nil)
+		Expect(RemediationRequiresReboot(cm)).To(BeFalse())
+		Expect(RemediationRequiresReboot(nil)).To(BeFalse())
+	})
+})
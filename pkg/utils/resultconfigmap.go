@@ -3,6 +3,8 @@ package utils
 import (
 	"encoding/base64"
 	"io"
+	"sort"
+	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -66,3 +68,129 @@ func GetResultConfigMap(owner metav1.Object, configMapName, filename, nodeName s
 		},
 	}
 }
+
+// InputHashesKey is the key of the ConfigMaps that holds the JSON-encoded
+// hashes of the inputs of the rules evaluated in a scan
+const InputHashesKey = "input-hashes"
+
+// GetInputHashesConfigMapName gets the name of the configmap that keeps the
+// hashes of the inputs of the rules from the last run of an incremental scan
+func GetInputHashesConfigMapName(scanName string) string {
+	return DNSLengthName("input-hashes-", "%s-input-hashes", scanName)
+}
+
+// CustomRuleResultsKey is the key of the result ConfigMaps of platform scans
+// that holds the JSON-encoded results of the custom rules of the scan
+const CustomRuleResultsKey = "custom-rule-results"
+
+// CustomRuleResult is the result of evaluating a custom rule
+type CustomRuleResult struct {
+	// The name of the CustomRule
+	Name         string                                     `json:"name"`
+	Status       compv1alpha1.ComplianceCheckStatus         `json:"status"`
+	Severity     compv1alpha1.ComplianceCheckResultSeverity `json:"severity"`
+	Description  string                                     `json:"description,omitempty"`
+	Instructions string                                     `json:"instructions,omitempty"`
+	// Why the rule couldn't be evaluated, for ERROR results
+	Message string `json:"message,omitempty"`
+}
+
+// ResultDiffKey is the key of the ConfigMap that holds the JSON-encoded diff
+// of the results of a scan against the results of its previous run
+const ResultDiffKey = "result-diff"
+
+// GetResultDiffConfigMapName gets the name of the configmap that keeps the
+// diff of the results of the last run of a scan against the previous one
+func GetResultDiffConfigMapName(scanName string) string {
+	return DNSLengthName("result-diff-", "%s-result-diff", scanName)
+}
+
+// ResultCountsKey is the key of the ConfigMap that holds the JSON-encoded
+// amounts of checks of a scan by their result and severity
+const ResultCountsKey = "result-counts"
+
+// GetResultCountsConfigMapName gets the name of the configmap that keeps the
+// amounts of checks of the last run of a scan by their result and severity
+func GetResultCountsConfigMapName(scanName string) string {
+	return DNSLengthName("result-counts-", "%s-result-counts", scanName)
+}
+
+// MaxResultSnapshots is the amount of runs of a scan whose results are kept
+// to compare them with ComplianceCheckResultDiffs
+const MaxResultSnapshots = 10
+
+// GetResultSnapshotsConfigMapName gets the name of the configmap that keeps
+// the results of the checks of the last runs of a scan, by the index of the
+// run
+func GetResultSnapshotsConfigMapName(scanName string) string {
+	return DNSLengthName("result-snapshots-", "%s-result-snapshots", scanName)
+}
+
+// GetResultSnapshotKey gets the key of the result snapshots ConfigMap that
+// holds the JSON-encoded results of the checks of a run of a scan
+func GetResultSnapshotKey(index int64) string {
+	return strconv.FormatInt(index, 10)
+}
+
+// AddResultSnapshot adds the snapshot of the results of a run of a scan to
+// the data of its result snapshots ConfigMap, dropping the ones of the
+// oldest runs past MaxResultSnapshots
+func AddResultSnapshot(data map[string]string, index int64, snapshot string) map[string]string {
+	if data == nil {
+		data = make(map[string]string)
+	}
+	data[GetResultSnapshotKey(index)] = snapshot
+
+	indexes := make([]int64, 0, len(data))
+	for key := range data {
+		keyIndex, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			delete(data, key)
+			continue
+		}
+		indexes = append(indexes, keyIndex)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] > indexes[j] })
+	for i := MaxResultSnapshots; i < len(indexes); i++ {
+		delete(data, GetResultSnapshotKey(indexes[i]))
+	}
+	return data
+}
+
+// ScannerOutputKey is the key of the ConfigMap that holds the output of the
+// scanner of a debug scan
+const ScannerOutputKey = "scanner-output"
+
+// GetScannerOutputConfigMapName gets the name of the configmap that keeps the
+// output of the scanner for the given result configmap of a debug scan
+func GetScannerOutputConfigMapName(resultConfigMapName string) string {
+	return DNSLengthName("scanner-output-", "%s-scanner-output", resultConfigMapName)
+}
+
+// GetScannerOutputConfigMap returns a ConfigMap keeping the output of the
+// scanner of a debug scan. The ConfigMap is labeled with the scan, but not as
+// a result, so it's removed when the scan is re-run.
+func GetScannerOutputConfigMap(owner metav1.Object, configMapName, nodeName, output string) *corev1.ConfigMap {
+	annotations := map[string]string{}
+	if nodeName != "" {
+		annotations["openscap-scan-result/node"] = nodeName
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        configMapName,
+			Namespace:   common.GetComplianceOperatorNamespace(),
+			Annotations: annotations,
+			Labels: map[string]string{
+				compv1alpha1.ComplianceScanLabel: owner.GetName(),
+			},
+		},
+		Data: map[string]string{
+			ScannerOutputKey: output,
+		},
+	}
+}
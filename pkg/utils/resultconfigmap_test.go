@@ -0,0 +1,30 @@
+package utils_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ComplianceAsCode/compliance-operator/pkg/utils"
+)
+
+var _ = Describe("Result snapshots", func() {
+	It("keeps the snapshots of the latest runs", func() {
+		var data map[string]string
+		for i := int64(1); i <= utils.MaxResultSnapshots+2; i++ {
+			data = utils.AddResultSnapshot(data, i, fmt.Sprintf("run-%d", i))
+		}
+		Expect(data).To(HaveLen(utils.MaxResultSnapshots))
+		Expect(data).ToNot(HaveKey("1"))
+		Expect(data).ToNot(HaveKey("2"))
+		Expect(data).To(HaveKeyWithValue("3", "run-3"))
+		Expect(data).To(HaveKeyWithValue("12", "run-12"))
+	})
+
+	It("replaces the snapshot of a run that is stored again", func() {
+		data := utils.AddResultSnapshot(nil, 4, "first")
+		data = utils.AddResultSnapshot(data, 4, "second")
+		Expect(data).To(Equal(map[string]string{"4": "second"}))
+	})
+})
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"strings"
+	"time"
+	// The time zones of the schedules are validated against the embedded
+	// database, as the operator image might not ship one
+	_ "time/tzdata"
+)
+
+// ValidTimezone tells whether the CronJob controller accepts the time zone,
+// which needs to be an IANA name rather than the time zone of the host
+func ValidTimezone(tz string) bool {
+	if tz == "" {
+		return true
+	}
+	if strings.EqualFold(tz, "Local") {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	backoff "github.com/cenkalti/backoff/v4"
+)
+
+const (
+	splunkEventPath         = "/services/collector/event"
+	defaultSplunkBatchSize  = 100
+	defaultSplunkMaxRetries = 5
+)
+
+// SplunkHECConfig configures the Splunk HTTP Event Collector the results are
+// forwarded to
+type SplunkHECConfig struct {
+	// The URL of the collector, the events are posted to its event
+	// endpoint
+	URL   string
+	Token string
+	// The index, source and source type of the events, the defaults of
+	// the token apply if they're empty
+	Index      string
+	Source     string
+	SourceType string
+	// The maximum number of events posted in a request
+	BatchSize int
+}
+
+// SplunkEvent is an event posted to the HTTP Event Collector
+type SplunkEvent struct {
+	// The time of the event, in seconds since the epoch
+	Time       int64       `json:"time,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	SourceType string      `json:"sourcetype,omitempty"`
+	Index      string      `json:"index,omitempty"`
+	Event      interface{} `json:"event"`
+}
+
+// SplunkHECClient posts events to a Splunk HTTP Event Collector
+type SplunkHECClient struct {
+	config SplunkHECConfig
+	client *http.Client
+	// newBackOff returns the backoff the failed requests are retried with
+	newBackOff func() backoff.BackOff
+}
+
+// NewSplunkHECClient returns a client for the HTTP Event Collector, which
+// sends its requests through the given HTTP client
+func NewSplunkHECClient(config SplunkHECConfig, client *http.Client) (*SplunkHECClient, error) {
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("invalid HTTP Event Collector URL %s: %w", config.URL, err)
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("no HTTP Event Collector token given")
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultSplunkBatchSize
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &SplunkHECClient{
+		config: config,
+		client: client,
+		newBackOff: func() backoff.BackOff {
+			return backoff.WithMaxRetries(backoff.NewExponentialBackOff(), defaultSplunkMaxRetries)
+		},
+	}, nil
+}
+
+// SendEvents posts the events to the collector in batches of the configured
+// size, with the index, source and source type of the configuration unless
+// the events set their own. The requests that fail on the way, or that the
+// collector fails or throttles, are retried with an exponential backoff.
+func (c *SplunkHECClient) SendEvents(ctx context.Context, events []SplunkEvent) error {
+	for start := 0; start < len(events); start += c.config.BatchSize {
+		end := start + c.config.BatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		// The collector takes the events of a batch one after another,
+		// rather than as a JSON array
+		var body bytes.Buffer
+		encoder := json.NewEncoder(&body)
+		for _, event := range events[start:end] {
+			if event.Index == "" {
+				event.Index = c.config.Index
+			}
+			if event.Source == "" {
+				event.Source = c.config.Source
+			}
+			if event.SourceType == "" {
+				event.SourceType = c.config.SourceType
+			}
+			if err := encoder.Encode(event); err != nil {
+				return err
+			}
+		}
+		err := backoff.Retry(func() error {
+			return c.post(ctx, body.Bytes())
+		}, backoff.WithContext(c.newBackOff(), ctx))
+		if err != nil {
+			return fmt.Errorf("cannot send the events %d to %d of %d: %w", start+1, end, len(events), err)
+		}
+	}
+	return nil
+}
+
+func (c *SplunkHECClient) post(ctx context.Context, body []byte) error {
+	eventURL := strings.TrimSuffix(c.config.URL, "/") + splunkEventPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eventURL, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Authorization", "Splunk "+c.config.Token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	// The collector tells why it refused the events, e.g. an invalid token
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("the HTTP Event Collector responded with %s: %s", resp.Status, strings.TrimSpace(string(reason)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return err
+	}
+	return backoff.Permanent(err)
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Splunk HTTP Event Collector", func() {
+	var (
+		server   *httptest.Server
+		received []*http.Request
+		batches  [][]map[string]interface{}
+		statuses []int
+	)
+
+	newClient := func(config SplunkHECConfig) *SplunkHECClient {
+		config.URL = server.URL + "/"
+		config.Token = "hec-token"
+		client, err := NewSplunkHECClient(config, server.Client())
+		Expect(err).To(BeNil())
+		client.newBackOff = func() backoff.BackOff {
+			return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 2)
+		}
+		return client
+	}
+	newEvents := func(n int) []SplunkEvent {
+		events := make([]SplunkEvent, n)
+		for i := range events {
+			events[i] = SplunkEvent{Time: 1700000000, Event: map[string]int{"check": i}}
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		received = nil
+		batches = nil
+		statuses = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r)
+			if len(statuses) > 0 {
+				status := statuses[0]
+				statuses = statuses[1:]
+				w.WriteHeader(status)
+				w.Write([]byte(`{"text":"Server is busy","code":9}`))
+				return
+			}
+			var batch []map[string]interface{}
+			decoder := json.NewDecoder(r.Body)
+			for {
+				event := map[string]interface{}{}
+				if err := decoder.Decode(&event); err == io.EOF {
+					break
+				} else {
+					Expect(err).To(BeNil())
+				}
+				batch = append(batch, event)
+			}
+			batches = append(batches, batch)
+			w.Write([]byte(`{"text":"Success","code":0}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts the events in batches", func() {
+		client := newClient(SplunkHECConfig{Index: "compliance", SourceType: "_json", BatchSize: 2})
+		Expect(client.SendEvents(context.TODO(), newEvents(5))).To(Succeed())
+		Expect(received).To(HaveLen(3))
+		Expect(received[0].URL.Path).To(Equal("/services/collector/event"))
+		Expect(received[0].Header.Get("Authorization")).To(Equal("Splunk hec-token"))
+		Expect(batches[0]).To(HaveLen(2))
+		Expect(batches[2]).To(HaveLen(1))
+		Expect(batches[2][0]).To(HaveKeyWithValue("index", "compliance"))
+		Expect(batches[2][0]).To(HaveKeyWithValue("sourcetype", "_json"))
+		Expect(batches[2][0]).ToNot(HaveKey("source"))
+		Expect(batches[2][0]).To(HaveKeyWithValue("event", HaveKeyWithValue("check", BeNumerically("==", 4))))
+	})
+
+	It("retries the requests the collector can't take yet", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		client := newClient(SplunkHECConfig{})
+		Expect(client.SendEvents(context.TODO(), newEvents(1))).To(Succeed())
+		Expect(received).To(HaveLen(3))
+		Expect(batches).To(HaveLen(1))
+	})
+
+	It("gives up on the events the collector refuses", func() {
+		statuses = []int{http.StatusForbidden}
+		client := newClient(SplunkHECConfig{})
+		err := client.SendEvents(context.TODO(), newEvents(1))
+		Expect(err).To(MatchError(ContainSubstring("403 Forbidden")))
+		Expect(received).To(HaveLen(1))
+
+		statuses = []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}
+		err = client.SendEvents(context.TODO(), newEvents(1))
+		Expect(err).To(MatchError(ContainSubstring("cannot send the events 1 to 1 of 1")))
+		Expect(received).To(HaveLen(4))
+	})
+
+	It("needs a token", func() {
+		_, err := NewSplunkHECClient(SplunkHECConfig{URL: "https://splunk.example.com:8088"}, nil)
+		Expect(err).To(MatchError("no HTTP Event Collector token given"))
+	})
+})
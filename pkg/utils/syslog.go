@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+)
+
+const (
+	// The messages are sent with the log audit facility
+	syslogFacilityLogAudit = 13
+	syslogTimestampFormat  = "2006-01-02T15:04:05.000000Z07:00"
+	syslogDialTimeout      = 10 * time.Second
+	syslogWriteTimeout     = 30 * time.Second
+	defaultSyslogRetries   = 5
+)
+
+// SyslogSeverity is the severity of a syslog message, as in RFC 5424
+type SyslogSeverity int
+
+const (
+	SyslogWarning       SyslogSeverity = 4
+	SyslogNotice        SyslogSeverity = 5
+	SyslogInformational SyslogSeverity = 6
+)
+
+// SyslogConfig configures the syslog receiver the results are forwarded to
+type SyslogConfig struct {
+	// The host and port of the receiver
+	Address string
+	// The TLS configuration the receiver is connected to with, the messages
+	// are sent over plain TCP if it's nil
+	TLSConfig *tls.Config
+	// The host name and the application name the messages are sent with.
+	// The host name defaults to the one of the system.
+	Hostname string
+	AppName  string
+}
+
+// SyslogMessage is a message sent to a syslog receiver
+type SyslogMessage struct {
+	Time     time.Time
+	Severity SyslogSeverity
+	Message  string
+}
+
+// SyslogClient sends messages to a syslog receiver over a stream, framed by
+// their length as in RFC 5425 and RFC 6587
+type SyslogClient struct {
+	config SyslogConfig
+	dial   func(ctx context.Context) (net.Conn, error)
+	// newBackOff returns the backoff the failed sends are retried with
+	newBackOff func() backoff.BackOff
+}
+
+// NewSyslogClient returns a client for the syslog receiver
+func NewSyslogClient(config SyslogConfig) (*SyslogClient, error) {
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog receiver address %s: %w", config.Address, err)
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	c := &SyslogClient{
+		config: config,
+		newBackOff: func() backoff.BackOff {
+			return backoff.WithMaxRetries(backoff.NewExponentialBackOff(), defaultSyslogRetries)
+		},
+	}
+	c.dial = func(ctx context.Context) (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: syslogDialTimeout}
+		if config.TLSConfig == nil {
+			return dialer.DialContext(ctx, "tcp", config.Address)
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: config.TLSConfig}
+		return tlsDialer.DialContext(ctx, "tcp", config.Address)
+	}
+	return c, nil
+}
+
+// Send sends the messages to the receiver in order over a single
+// connection. If the connection fails on the way, the receiver is connected
+// to again with an exponential backoff, and the messages are sent on from
+// the one that failed. Receivers whose certificate can't be verified aren't
+// retried.
+func (c *SyslogClient) Send(ctx context.Context, messages []SyslogMessage) error {
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	sent := 0
+	err := backoff.Retry(func() error {
+		if conn == nil {
+			var err error
+			if conn, err = c.dial(ctx); err != nil {
+				var verifyErr *tls.CertificateVerificationError
+				if errors.As(err, &verifyErr) {
+					return backoff.Permanent(err)
+				}
+				return err
+			}
+		}
+		for ; sent < len(messages); sent++ {
+			conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+			if _, err := conn.Write(c.frame(messages[sent])); err != nil {
+				conn.Close()
+				conn = nil
+				return err
+			}
+		}
+		return nil
+	}, backoff.WithContext(c.newBackOff(), ctx))
+	if err != nil {
+		return fmt.Errorf("cannot send the messages %d to %d: %w", sent+1, len(messages), err)
+	}
+	return nil
+}
+
+// frame formats the message as in RFC 5424, without structured data, and
+// prefixes it with its length
+func (c *SyslogClient) frame(message SyslogMessage) []byte {
+	msg := fmt.Sprintf("<%d>1 %s %s %s - - - %s",
+		syslogFacilityLogAudit*8+int(message.Severity),
+		message.Time.UTC().Format(syslogTimestampFormat),
+		syslogHeaderField(c.config.Hostname),
+		syslogHeaderField(c.config.AppName),
+		message.Message)
+	return []byte(strconv.Itoa(len(msg)) + " " + msg)
+}
+
+// syslogHeaderField returns the field, or '-' if it's empty, without spaces
+func syslogHeaderField(field string) string {
+	if field == "" {
+		return "-"
+	}
+	return strings.ReplaceAll(field, " ", "_")
+}
+
+// SecurityEvent is an event that's formatted as a CEF or LEEF message
+type SecurityEvent struct {
+	// The vendor, product and version of the device that reports the event
+	Vendor  string
+	Product string
+	Version string
+	// The ID of the class of the event, e.g. the ID of a rule
+	ClassID string
+	Name    string
+	// The severity of the event, from 0 to 10
+	Severity int
+	// The key-value fields of the event, in order
+	Fields []SecurityEventField
+}
+
+// SecurityEventField is a field of a SecurityEvent
+type SecurityEventField struct {
+	Key   string
+	Value string
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\n", `\n`, "\r", `\r`)
+	// LEEF separates the fields with tabs and has no escapes for them
+	leefValueEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+// CEF formats the event as an ArcSight Common Event Format message
+func (e *SecurityEvent) CEF() string {
+	header := []string{"CEF:0"}
+	for _, field := range []string{e.Vendor, e.Product, e.Version, e.ClassID, e.Name, strconv.Itoa(e.Severity)} {
+		header = append(header, cefHeaderEscaper.Replace(field))
+	}
+	extension := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		extension = append(extension, field.Key+"="+cefExtensionEscaper.Replace(field.Value))
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(extension, " ")
+}
+
+// LEEF formats the event as a QRadar Log Event Extended Format 1.0 message,
+// with the name and the severity of the event in the 'cat' and 'sev' fields
+func (e *SecurityEvent) LEEF() string {
+	header := []string{"LEEF:1.0"}
+	for _, field := range []string{e.Vendor, e.Product, e.Version, e.ClassID} {
+		header = append(header, cefHeaderEscaper.Replace(field))
+	}
+	attributes := []string{
+		"cat=" + leefValueEscaper.Replace(e.Name),
+		"sev=" + strconv.Itoa(e.Severity),
+	}
+	for _, field := range e.Fields {
+		attributes = append(attributes, field.Key+"="+leefValueEscaper.Replace(field.Value))
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(attributes, "\t")
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// readSyslogFrames reads the messages of a connection, framed by their
+// length, until the connection is closed or fails
+func readSyslogFrames(conn net.Conn) []string {
+	var frames []string
+	reader := bufio.NewReader(conn)
+	for {
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return frames
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		Expect(err).To(BeNil())
+		frame := make([]byte, n)
+		_, err = io.ReadFull(reader, frame)
+		Expect(err).To(BeNil())
+		frames = append(frames, string(frame))
+	}
+}
+
+var _ = Describe("Syslog", func() {
+	event := &SecurityEvent{
+		Vendor:   "ComplianceAsCode",
+		Product:  "compliance-operator",
+		Version:  "1.5.0",
+		ClassID:  "xccdf_org.ssgproject.content_rule_audit_rules",
+		Name:     "Audit | the rules",
+		Severity: 7,
+		Fields: []SecurityEventField{
+			{Key: "outcome", Value: "FAIL"},
+			{Key: "msg", Value: "a=b\tc\nd"},
+		},
+	}
+
+	It("formats the events as CEF messages", func() {
+		Expect(event.CEF()).To(Equal(`CEF:0|ComplianceAsCode|compliance-operator|1.5.0|` +
+			`xccdf_org.ssgproject.content_rule_audit_rules|Audit \| the rules|7|outcome=FAIL msg=a\=b` + "\t" + `c\nd`))
+	})
+
+	It("formats the events as LEEF messages", func() {
+		Expect(event.LEEF()).To(Equal("LEEF:1.0|ComplianceAsCode|compliance-operator|1.5.0|" +
+			"xccdf_org.ssgproject.content_rule_audit_rules|cat=Audit | the rules\tsev=7\toutcome=FAIL\tmsg=a=b c d"))
+	})
+
+	Context("sending the messages", func() {
+		var (
+			listener net.Listener
+			received chan []string
+		)
+		messages := []SyslogMessage{
+			{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Severity: SyslogWarning, Message: "first"},
+			{Time: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC), Severity: SyslogInformational, Message: "second"},
+		}
+		serve := func() {
+			received = make(chan []string, 2)
+			go func() {
+				defer GinkgoRecover()
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					received <- readSyslogFrames(conn)
+					conn.Close()
+				}
+			}()
+		}
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		It("sends the messages over TCP", func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			serve()
+			client, err := NewSyslogClient(SyslogConfig{Address: listener.Addr().String(), Hostname: "operator-0", AppName: "compliance-operator"})
+			Expect(err).To(BeNil())
+			Expect(client.Send(context.TODO(), messages)).To(Succeed())
+			Eventually(received).Should(Receive(Equal([]string{
+				"<108>1 2024-01-02T03:04:05.000000Z operator-0 compliance-operator - - - first",
+				"<110>1 2024-01-02T03:04:06.000000Z operator-0 compliance-operator - - - second",
+			})))
+		})
+
+		It("sends the messages over TLS to receivers it can verify", func() {
+			caCert, caKey, err := ComplianceOperatorRootCA("syslog-ca", 1)
+			Expect(err).To(BeNil())
+			cert, key, err := NewServerCert(caCert, caKey, "127.0.0.1", 1)
+			Expect(err).To(BeNil())
+			serverCert, err := tls.X509KeyPair(cert, key)
+			Expect(err).To(BeNil())
+			listener, err = tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert}})
+			Expect(err).To(BeNil())
+			serve()
+
+			pool := x509.NewCertPool()
+			Expect(pool.AppendCertsFromPEM(caCert)).To(BeTrue())
+			client, err := NewSyslogClient(SyslogConfig{Address: listener.Addr().String(), TLSConfig: &tls.Config{RootCAs: pool}})
+			Expect(err).To(BeNil())
+			Expect(client.Send(context.TODO(), messages)).To(Succeed())
+			Eventually(received).Should(Receive(HaveLen(2)))
+
+			client, err = NewSyslogClient(SyslogConfig{Address: listener.Addr().String(), TLSConfig: &tls.Config{RootCAs: x509.NewCertPool()}})
+			Expect(err).To(BeNil())
+			client.newBackOff = func() backoff.BackOff {
+				return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 10)
+			}
+			err = client.Send(context.TODO(), messages)
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+		})
+
+		It("gives up on receivers it can't connect to", func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			address := listener.Addr().String()
+			listener.Close()
+			client, err := NewSyslogClient(SyslogConfig{Address: address})
+			Expect(err).To(BeNil())
+			client.newBackOff = func() backoff.BackOff {
+				return backoff.WithMaxRetries(&backoff.ZeroBackOff{}, 2)
+			}
+			Expect(client.Send(context.TODO(), messages)).To(MatchError(ContainSubstring("cannot send the messages 1 to 2")))
+		})
+	})
+})
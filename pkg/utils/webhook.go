@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	backoff "github.com/cenkalti/backoff/v4"
+)
+
+const (
+	defaultWebhookContentType    = "application/json"
+	defaultWebhookMaxRetries     = 5
+	defaultWebhookInitialBackoff = time.Second
+	defaultWebhookMaxBackoff     = 30 * time.Second
+)
+
+// WebhookConfig configures an HTTP endpoint that notifications are posted to
+type WebhookConfig struct {
+	URL         string
+	ContentType string
+	// The bearer token the requests are authenticated with, or else the
+	// basic authentication credentials, if any
+	Token    string
+	Username string
+	Password string
+	// How many times a failed request is retried, and how long to wait
+	// before the first retry and at most between two retries
+	MaxRetries     *int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// WebhookClient posts notifications to an HTTP endpoint
+type WebhookClient struct {
+	config WebhookConfig
+	client *http.Client
+	// newBackOff returns the backoff the failed requests are retried with
+	newBackOff func() backoff.BackOff
+}
+
+// NewWebhookClient returns a client for the endpoint, which sends its requests
+// through the given HTTP client
+func NewWebhookClient(config WebhookConfig, client *http.Client) (*WebhookClient, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL %s: %w", config.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL %s: the scheme must be http or https", config.URL)
+	}
+	if config.ContentType == "" {
+		config.ContentType = defaultWebhookContentType
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaultWebhookInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultWebhookMaxBackoff
+	}
+	retries := defaultWebhookMaxRetries
+	if config.MaxRetries != nil {
+		retries = *config.MaxRetries
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookClient{
+		config: config,
+		client: client,
+		newBackOff: func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.InitialInterval = config.InitialBackoff
+			b.MaxInterval = config.MaxBackoff
+			// The retries bound the calls rather than the time they
+			// take, the context bounds that
+			b.MaxElapsedTime = 0
+			return backoff.WithMaxRetries(b, uint64(retries))
+		},
+	}, nil
+}
+
+// Post posts the payload to the endpoint. The requests that fail on the way,
+// or that the endpoint fails or throttles, are retried with an exponential
+// backoff.
+func (c *WebhookClient) Post(ctx context.Context, payload []byte) error {
+	err := backoff.Retry(func() error {
+		return c.post(ctx, payload)
+	}, backoff.WithContext(c.newBackOff(), ctx))
+	if err != nil {
+		return fmt.Errorf("cannot call the webhook %s: %w", c.config.URL, err)
+	}
+	return nil
+}
+
+func (c *WebhookClient) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", c.config.ContentType)
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	} else if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	reason, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("the webhook responded with %s: %s", resp.Status, strings.TrimSpace(string(reason)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return err
+	}
+	return backoff.Permanent(err)
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhooks", func() {
+	var (
+		server   *httptest.Server
+		received []*http.Request
+		payloads []string
+		statuses []int
+	)
+
+	newClient := func(config WebhookConfig) *WebhookClient {
+		config.URL = server.URL + "/hooks/compliance"
+		config.InitialBackoff = time.Millisecond
+		config.MaxBackoff = time.Millisecond
+		client, err := NewWebhookClient(config, server.Client())
+		Expect(err).To(BeNil())
+		return client
+	}
+
+	BeforeEach(func() {
+		received = nil
+		payloads = nil
+		statuses = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r)
+			payload, err := io.ReadAll(r.Body)
+			Expect(err).To(BeNil())
+			payloads = append(payloads, string(payload))
+			if len(statuses) > 0 {
+				status := statuses[0]
+				statuses = statuses[1:]
+				w.WriteHeader(status)
+				w.Write([]byte("try again later"))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts the payload with a bearer token", func() {
+		client := newClient(WebhookConfig{Token: "webhook-token"})
+		Expect(client.Post(context.TODO(), []byte(`{"suite":"cis"}`))).To(Succeed())
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].URL.Path).To(Equal("/hooks/compliance"))
+		Expect(received[0].Header.Get("Authorization")).To(Equal("Bearer webhook-token"))
+		Expect(received[0].Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(payloads).To(Equal([]string{`{"suite":"cis"}`}))
+	})
+
+	It("posts the payload with basic authentication and its content type", func() {
+		client := newClient(WebhookConfig{Username: "operator", Password: "secret", ContentType: "text/plain"})
+		Expect(client.Post(context.TODO(), []byte("cis is NON-COMPLIANT"))).To(Succeed())
+		username, password, ok := received[0].BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("operator"))
+		Expect(password).To(Equal("secret"))
+		Expect(received[0].Header.Get("Content-Type")).To(Equal("text/plain"))
+	})
+
+	It("retries the requests the endpoint fails or throttles", func() {
+		statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		client := newClient(WebhookConfig{})
+		Expect(client.Post(context.TODO(), []byte("{}"))).To(Succeed())
+		Expect(received).To(HaveLen(3))
+	})
+
+	It("gives up once the retries are exhausted", func() {
+		statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}
+		retries := 1
+		client := newClient(WebhookConfig{MaxRetries: &retries})
+		err := client.Post(context.TODO(), []byte("{}"))
+		Expect(err).To(MatchError(ContainSubstring("502 Bad Gateway: try again later")))
+		Expect(received).To(HaveLen(2))
+	})
+
+	It("doesn't retry the requests the endpoint refuses", func() {
+		statuses = []int{http.StatusUnauthorized}
+		client := newClient(WebhookConfig{})
+		Expect(client.Post(context.TODO(), []byte("{}"))).To(MatchError(ContainSubstring("401 Unauthorized")))
+		Expect(received).To(HaveLen(1))
+	})
+
+	It("refuses URLs that aren't HTTP", func() {
+		_, err := NewWebhookClient(WebhookConfig{URL: "ftp://example.com"}, nil)
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,103 @@
+package xccdf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ImportedTailoring is an XCCDF tailoring file, e.g. one used with oscap,
+// being imported into a TailoredProfile
+type ImportedTailoring struct {
+	XMLName   xml.Name          `xml:"http://checklists.nist.gov/xccdf/1.2 Tailoring"`
+	Benchmark importedBenchmark `xml:"http://checklists.nist.gov/xccdf/1.2 benchmark"`
+	Profiles  []ImportedProfile `xml:"http://checklists.nist.gov/xccdf/1.2 Profile"`
+}
+
+type importedBenchmark struct {
+	Href string `xml:"href,attr"`
+}
+
+// ImportedProfile is a profile of an imported tailoring file
+type ImportedProfile struct {
+	ID      string `xml:"id,attr"`
+	Extends string `xml:"extends,attr"`
+	// There's one of each per language
+	Titles       []string            `xml:"http://checklists.nist.gov/xccdf/1.2 title"`
+	Descriptions []string            `xml:"http://checklists.nist.gov/xccdf/1.2 description"`
+	Selections   []ImportedSelection `xml:"http://checklists.nist.gov/xccdf/1.2 select"`
+	Values       []ImportedValue     `xml:"http://checklists.nist.gov/xccdf/1.2 set-value"`
+}
+
+// ImportedSelection selects or deselects a rule or group
+type ImportedSelection struct {
+	IDRef    string   `xml:"idref,attr"`
+	Selected bool     `xml:"selected,attr"`
+	Remarks  []string `xml:"http://checklists.nist.gov/xccdf/1.2 remark"`
+}
+
+// GetRemark returns the first remark of the selection, which is its
+// rationale, if it has one
+func (s *ImportedSelection) GetRemark() string {
+	return firstNonEmpty(s.Remarks)
+}
+
+// ImportedValue sets the value of a variable
+type ImportedValue struct {
+	IDRef string `xml:"idref,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ParseTailoring parses an XCCDF 1.2 tailoring file
+func ParseTailoring(data string) (*ImportedTailoring, error) {
+	tailoring := &ImportedTailoring{}
+	if err := xml.Unmarshal([]byte(data), tailoring); err != nil {
+		return nil, fmt.Errorf("couldn't parse the XCCDF 1.2 tailoring file: %w", err)
+	}
+	if len(tailoring.Profiles) == 0 {
+		return nil, fmt.Errorf("the tailoring file has no profiles")
+	}
+	return tailoring, nil
+}
+
+// GetBenchmarkHref returns the data stream file the tailoring file tailors
+func (t *ImportedTailoring) GetBenchmarkHref() string {
+	return t.Benchmark.Href
+}
+
+// GetProfile returns the profile of the tailoring file with the given ID, or
+// its only profile if the ID is empty
+func (t *ImportedTailoring) GetProfile(id string) (*ImportedProfile, error) {
+	if id == "" {
+		if len(t.Profiles) > 1 {
+			return nil, fmt.Errorf("the tailoring file has %d profiles, the ID of the one to import is needed", len(t.Profiles))
+		}
+		return &t.Profiles[0], nil
+	}
+	for i := range t.Profiles {
+		if t.Profiles[i].ID == id {
+			return &t.Profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the tailoring file has no profile %s", id)
+}
+
+// GetTitle returns the first title of the profile, if it has one
+func (p *ImportedProfile) GetTitle() string {
+	return firstNonEmpty(p.Titles)
+}
+
+// GetDescription returns the first description of the profile, if it has
+// one
+func (p *ImportedProfile) GetDescription() string {
+	return firstNonEmpty(p.Descriptions)
+}
+
+func firstNonEmpty(values []string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -70,6 +71,8 @@ type SelectElement struct {
 	XMLName  xml.Name `xml:"xccdf-1.2:select"`
 	IDRef    string   `xml:"idref,attr"`
 	Selected bool     `xml:"selected,attr"`
+	// The rationale of the selection
+	Remark string `xml:"xccdf-1.2:remark,omitempty"`
 }
 
 type SetValueElement struct {
@@ -133,10 +136,20 @@ func getTailoringID(tp *cmpv1alpha1.TailoredProfile) string {
 	return fmt.Sprintf("xccdf_%s_tailoring_%s", XCCDFNamespace, tp.Name)
 }
 
-func getSelectElementFromCRRule(rule *cmpv1alpha1.Rule, enable bool) SelectElement {
+// getTailoringVersion gets the version of the tailoring, which changes with
+// the spec of the TailoredProfile
+func getTailoringVersion(tp *cmpv1alpha1.TailoredProfile) string {
+	if tp.Generation == 0 {
+		return "1"
+	}
+	return strconv.FormatInt(tp.Generation, 10)
+}
+
+func getSelectElementFromCRRule(rule *cmpv1alpha1.Rule, selection cmpv1alpha1.RuleReferenceSpec, enable bool) SelectElement {
 	return SelectElement{
 		IDRef:    rule.ID,
 		Selected: enable,
+		Remark:   selection.Rationale,
 	}
 }
 
@@ -144,17 +157,17 @@ func getSelections(tp *cmpv1alpha1.TailoredProfile, rules map[string]*cmpv1alpha
 	selections := []SelectElement{}
 	for _, selection := range tp.Spec.EnableRules {
 		rule := rules[selection.Name]
-		selections = append(selections, getSelectElementFromCRRule(rule, true))
+		selections = append(selections, getSelectElementFromCRRule(rule, selection, true))
 	}
 
 	for _, selection := range tp.Spec.DisableRules {
 		rule := rules[selection.Name]
-		selections = append(selections, getSelectElementFromCRRule(rule, false))
+		selections = append(selections, getSelectElementFromCRRule(rule, selection, false))
 	}
 
 	for _, selection := range tp.Spec.ManualRules {
 		rule := rules[selection.Name]
-		selections = append(selections, getSelectElementFromCRRule(rule, true))
+		selections = append(selections, getSelectElementFromCRRule(rule, selection, true))
 	}
 	return selections
 }
@@ -198,14 +211,13 @@ func TailoredProfileToXML(tp *cmpv1alpha1.TailoredProfile, p *cmpv1alpha1.Profil
 		XMLNamespaceURI: XCCDFURI,
 		ID:              getTailoringID(tp),
 		Version: VersionElement{
-			Time: time.Now().Format(time.RFC3339),
-			// TODO(jaosorior): Establish a TailoredProfile versioning mechanism
-			Value: "1",
+			Time:  time.Now().Format(time.RFC3339),
+			Value: getTailoringVersion(tp),
 		},
 		Benchmark: BenchmarkElement{
 			// NOTE(jaosorior): Both this operator and the compliance-operator
 			// assume the content will be mounted on a "content/" directory
-			Href: filepath.Join("/content", pb.Spec.ContentFile),
+			Href: filepath.Join("/content", pb.GetContentFileOf(tp)),
 		},
 		Profile: ProfileElement{
 			ID:         GetXCCDFProfileID(tp),
@@ -111,3 +111,106 @@ var _ = Describe("Testing parse variables", func() {
 		})
 	})
 })
+
+var _ = Describe("Testing importing tailoring files", func() {
+	const tailoringFile = `<?xml version="1.0" encoding="UTF-8"?>
+<xccdf:Tailoring xmlns:xccdf="http://checklists.nist.gov/xccdf/1.2" id="xccdf_scap-workbench_tailoring_default">
+  <xccdf:benchmark href="/usr/share/xml/scap/ssg/content/ssg-ocp4-ds.xml"/>
+  <xccdf:version time="2023-01-01T00:00:00">1</xccdf:version>
+  <xccdf:Profile id="xccdf_org.ssgproject.content_profile_cis_customized" extends="xccdf_org.ssgproject.content_profile_cis">
+    <xccdf:title xml:lang="en-US" override="true">CIS customized</xccdf:title>
+    <xccdf:description xml:lang="en-US" override="true"> Our CIS </xccdf:description>
+    <xccdf:select idref="xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled" selected="true"/>
+    <xccdf:select idref="xccdf_org.ssgproject.content_rule_kubeadmin_removed" selected="0"/>
+    <xccdf:set-value idref="xccdf_org.ssgproject.content_value_var_openshift_audit_profile">WriteRequestBodies</xccdf:set-value>
+  </xccdf:Profile>
+  <xccdf:Profile id="xccdf_org.ssgproject.content_profile_moderate_customized">
+    <xccdf:title>Moderate customized</xccdf:title>
+  </xccdf:Profile>
+</xccdf:Tailoring>`
+
+	It("parses the profiles of the tailoring file", func() {
+		tailoring, err := ParseTailoring(tailoringFile)
+		Expect(err).To(BeNil())
+		Expect(tailoring.GetBenchmarkHref()).To(Equal("/usr/share/xml/scap/ssg/content/ssg-ocp4-ds.xml"))
+
+		profile, err := tailoring.GetProfile("xccdf_org.ssgproject.content_profile_cis_customized")
+		Expect(err).To(BeNil())
+		Expect(profile.Extends).To(Equal("xccdf_org.ssgproject.content_profile_cis"))
+		Expect(profile.GetTitle()).To(Equal("CIS customized"))
+		Expect(profile.GetDescription()).To(Equal("Our CIS"))
+		Expect(profile.Selections).To(Equal([]ImportedSelection{
+			{IDRef: "xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled", Selected: true},
+			{IDRef: "xccdf_org.ssgproject.content_rule_kubeadmin_removed", Selected: false},
+		}))
+		Expect(profile.Values).To(Equal([]ImportedValue{
+			{IDRef: "xccdf_org.ssgproject.content_value_var_openshift_audit_profile", Value: "WriteRequestBodies"},
+		}))
+	})
+
+	It("needs the ID of the profile if there are several", func() {
+		tailoring, err := ParseTailoring(tailoringFile)
+		Expect(err).To(BeNil())
+		_, err = tailoring.GetProfile("")
+		Expect(err).To(MatchError(ContainSubstring("has 2 profiles")))
+		_, err = tailoring.GetProfile("xccdf_org.ssgproject.content_profile_e8")
+		Expect(err).To(MatchError(ContainSubstring("has no profile")))
+	})
+
+	It("only parses XCCDF 1.2 tailoring files", func() {
+		_, err := ParseTailoring(`<Tailoring xmlns="http://checklists.nist.gov/xccdf/1.1"><Profile id="p"/></Tailoring>`)
+		Expect(err).ToNot(BeNil())
+		_, err = ParseTailoring(`<Tailoring xmlns="http://checklists.nist.gov/xccdf/1.2"/>`)
+		Expect(err).To(MatchError(ContainSubstring("has no profiles")))
+	})
+})
+
+var _ = Describe("Testing exporting tailoring files", func() {
+	It("exports a tailoring file that can be imported back", func() {
+		tp := &cmpv1alpha1.TailoredProfile{
+			ObjectMeta: v1.ObjectMeta{Name: "cis-customized", Generation: 3},
+			Spec: cmpv1alpha1.TailoredProfileSpec{
+				Title:       "CIS customized",
+				Description: "Our CIS",
+				EnableRules: []cmpv1alpha1.RuleReferenceSpec{
+					{Name: "ocp4-audit-log-forwarding-enabled", Rationale: "We forward the audit logs"},
+				},
+				DisableRules: []cmpv1alpha1.RuleReferenceSpec{
+					{Name: "ocp4-kubeadmin-removed", Rationale: "We break the glass with kubeadmin"},
+				},
+			},
+		}
+		p := &cmpv1alpha1.Profile{ProfilePayload: cmpv1alpha1.ProfilePayload{ID: "xccdf_org.ssgproject.content_profile_cis"}}
+		pb := &cmpv1alpha1.ProfileBundle{Spec: cmpv1alpha1.ProfileBundleSpec{ContentFile: "ssg-ocp4-ds.xml"}}
+		rules := map[string]*cmpv1alpha1.Rule{
+			"ocp4-audit-log-forwarding-enabled": {RulePayload: cmpv1alpha1.RulePayload{ID: "xccdf_org.ssgproject.content_rule_audit_log_forwarding_enabled"}},
+			"ocp4-kubeadmin-removed":            {RulePayload: cmpv1alpha1.RulePayload{ID: "xccdf_org.ssgproject.content_rule_kubeadmin_removed"}},
+		}
+		variables := []*cmpv1alpha1.Variable{
+			{VariablePayload: cmpv1alpha1.VariablePayload{ID: "xccdf_org.ssgproject.content_value_var_openshift_audit_profile", Value: "WriteRequestBodies"}},
+		}
+
+		exported, err := TailoredProfileToXML(tp, p, pb, rules, variables)
+		Expect(err).To(BeNil())
+		Expect(exported).To(ContainSubstring(`>3</xccdf-1.2:version>`))
+
+		tailoring, err := ParseTailoring(exported)
+		Expect(err).To(BeNil())
+		Expect(tailoring.GetBenchmarkHref()).To(Equal("/content/ssg-ocp4-ds.xml"))
+		profile, err := tailoring.GetProfile("")
+		Expect(err).To(BeNil())
+		Expect(profile.ID).To(Equal(GetXCCDFProfileID(tp)))
+		Expect(profile.Extends).To(Equal(p.ID))
+		Expect(profile.GetTitle()).To(Equal("CIS customized"))
+		Expect(profile.GetDescription()).To(Equal("Our CIS"))
+		Expect(profile.Selections).To(HaveLen(2))
+		Expect(profile.Selections[0].Selected).To(BeTrue())
+		Expect(profile.Selections[0].GetRemark()).To(Equal("We forward the audit logs"))
+		Expect(profile.Selections[1].IDRef).To(Equal("xccdf_org.ssgproject.content_rule_kubeadmin_removed"))
+		Expect(profile.Selections[1].Selected).To(BeFalse())
+		Expect(profile.Selections[1].GetRemark()).To(Equal("We break the glass with kubeadmin"))
+		Expect(profile.Values).To(Equal([]ImportedValue{
+			{IDRef: "xccdf_org.ssgproject.content_value_var_openshift_audit_profile", Value: "WriteRequestBodies"},
+		}))
+	})
+})